@@ -0,0 +1,190 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	metricscmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/metrics"
+	"github.com/urfave/cli/v3"
+)
+
+// Command is the top-level alerts command
+var Command = &cli.Command{
+	Name:  "alerts",
+	Usage: "Show Prometheus alerts and test where they are sent",
+	Description: `Shows the alerts Prometheus is raising, including the built-in Foundry rule
+pack installed with the prometheus component.
+
+Commands:
+  foundry alerts list             - Show firing alerts
+  foundry alerts test <receiver>  - Send a test alert to a receiver`,
+	Commands: []*cli.Command{
+		ListCommand,
+		TestReceiverCommand,
+	},
+}
+
+// ListCommand lists active alerts
+var ListCommand = &cli.Command{
+	Name:  "list",
+	Usage: "Show firing alerts",
+	Description: `Lists the alerts Prometheus is currently firing, most severe first.
+
+Examples:
+  foundry alerts list
+  foundry alerts list --pending
+  foundry alerts list --foundry --severity critical`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "namespace",
+			Aliases: []string{"n"},
+			Usage:   "Namespace where Prometheus is installed",
+			Value:   "monitoring",
+		},
+		&cli.BoolFlag{
+			Name:  "pending",
+			Usage: "Also show alerts whose condition holds but has not lasted long enough to fire",
+		},
+		&cli.BoolFlag{
+			Name:  "foundry",
+			Usage: "Only show alerts from the built-in Foundry rule pack",
+		},
+		&cli.StringFlag{
+			Name:  "severity",
+			Usage: "Only show alerts of this severity (critical, warning, info)",
+		},
+	},
+	Action: runList,
+}
+
+// alert is an active alert from the Prometheus alerts API
+type alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	ActiveAt    time.Time         `json:"activeAt"`
+	Value       string            `json:"value"`
+}
+
+func runList(ctx context.Context, cmd *cli.Command) error {
+	clientset, err := metricscmd.K8sClient()
+	if err != nil {
+		return err
+	}
+
+	alerts, err := fetchAlerts(ctx, clientset, cmd.String("namespace"))
+	if err != nil {
+		return err
+	}
+	alerts = filterAlerts(alerts, cmd.Bool("pending"), cmd.Bool("foundry"), cmd.String("severity"))
+
+	out := cmd.Root().Writer
+	if len(alerts) == 0 {
+		fmt.Fprintln(out, "No alerts firing")
+		return nil
+	}
+	printAlerts(out, alerts, time.Now())
+	return nil
+}
+
+// fetchAlerts reads the active alerts from the Prometheus in namespace
+// through the API server's service proxy, like metricscmd.PostAlerts
+func fetchAlerts(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]alert, error) {
+	name, port, err := metricscmd.FindPrometheus(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	body, err := clientset.CoreV1().Services(namespace).
+		ProxyGet("http", name, strconv.Itoa(int(port)), "/api/v1/alerts", nil).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Data   struct {
+			Alerts []alert `json:"alerts"`
+		} `json:"data"`
+		Error string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s", result.Error)
+	}
+	return result.Data.Alerts, nil
+}
+
+// severityRank orders severities, most severe first
+var severityRank = map[string]int{"critical": 0, "warning": 1, "info": 2}
+
+// filterAlerts keeps the alerts to show and sorts them by severity, then
+// by how long they have been active
+func filterAlerts(alerts []alert, pending, foundryOnly bool, severity string) []alert {
+	var kept []alert
+	for _, a := range alerts {
+		if a.State != "firing" && !pending {
+			continue
+		}
+		if foundryOnly && a.Labels["foundry_rule_pack"] == "" {
+			continue
+		}
+		if severity != "" && a.Labels["severity"] != severity {
+			continue
+		}
+		kept = append(kept, a)
+	}
+
+	rank := func(a alert) int {
+		if r, ok := severityRank[a.Labels["severity"]]; ok {
+			return r
+		}
+		return len(severityRank)
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if rank(kept[i]) != rank(kept[j]) {
+			return rank(kept[i]) < rank(kept[j])
+		}
+		return kept[i].ActiveAt.Before(kept[j].ActiveAt)
+	})
+	return kept
+}
+
+func printAlerts(out io.Writer, alerts []alert, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ALERT\tSEVERITY\tSTATE\tSINCE\tSUMMARY")
+	for _, a := range alerts {
+		summary := a.Annotations["summary"]
+		if summary == "" {
+			summary = a.Annotations["description"]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			a.Labels["alertname"], a.Labels["severity"], a.State, since(a.ActiveAt, now), summary)
+	}
+	w.Flush()
+}
+
+// since formats how long ago t was, e.g. "3h12m"
+func since(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours())/24)
+	}
+}
@@ -0,0 +1,116 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCommand(t *testing.T) {
+	require.NotNil(t, Command)
+	assert.Equal(t, "alerts", Command.Name)
+	require.Len(t, Command.Commands, 2)
+	assert.Equal(t, "list", Command.Commands[0].Name)
+	assert.Equal(t, "test", Command.Commands[1].Name)
+
+	var flags []string
+	for _, flag := range ListCommand.Flags {
+		flags = append(flags, flag.Names()[0])
+	}
+	assert.ElementsMatch(t, []string{"namespace", "pending", "foundry", "severity"}, flags)
+}
+
+func TestFetchAlerts(t *testing.T) {
+	service := func(name string, port int32) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "monitoring"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http-web", Port: port}}},
+		}
+	}
+	clientset := kubernetesfake.NewSimpleClientset(
+		service("kube-prometheus-stack-alertmanager", 9093),
+		service("kube-prometheus-stack-prometheus", 9090),
+	)
+	var proxied k8stesting.ProxyGetAction
+	clientset.PrependProxyReactor("services", func(action k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+		proxied = action.(k8stesting.ProxyGetAction)
+		return true, staticResponse(`{"status":"success","data":{"alerts":[
+			{"labels":{"alertname":"FoundryZotDown","severity":"critical"},"state":"firing","activeAt":"2026-10-18T10:00:00Z"}
+		]}}`), nil
+	})
+
+	alerts, err := fetchAlerts(context.Background(), clientset, "monitoring")
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "FoundryZotDown", alerts[0].Labels["alertname"])
+	assert.Equal(t, "kube-prometheus-stack-prometheus", proxied.GetName())
+	assert.Equal(t, "9090", proxied.GetPort())
+	assert.Equal(t, "/api/v1/alerts", proxied.GetPath())
+}
+
+// staticResponse is a proxied response with a fixed body
+type staticResponse string
+
+func (r staticResponse) DoRaw(context.Context) ([]byte, error) { return []byte(r), nil }
+
+func (r staticResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(r))), nil
+}
+
+func TestFilterAlerts(t *testing.T) {
+	now := time.Now()
+	alerts := []alert{
+		{Labels: map[string]string{"alertname": "Watchdog", "severity": "none"}, State: "firing", ActiveAt: now.Add(-time.Hour)},
+		{Labels: map[string]string{"alertname": "FoundryZotDown", "severity": "critical", "foundry_rule_pack": "v1"}, State: "firing", ActiveAt: now.Add(-time.Minute)},
+		{Labels: map[string]string{"alertname": "FoundryCertificateExpiringSoon", "severity": "warning", "foundry_rule_pack": "v1"}, State: "pending", ActiveAt: now},
+		{Labels: map[string]string{"alertname": "FoundryOpenBAOSealed", "severity": "critical", "foundry_rule_pack": "v1"}, State: "firing", ActiveAt: now.Add(-time.Hour)},
+	}
+
+	names := func(alerts []alert) []string {
+		var out []string
+		for _, a := range alerts {
+			out = append(out, a.Labels["alertname"])
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"FoundryOpenBAOSealed", "FoundryZotDown", "Watchdog"}, names(filterAlerts(alerts, false, false, "")))
+	assert.Equal(t, []string{"FoundryOpenBAOSealed", "FoundryZotDown", "FoundryCertificateExpiringSoon"}, names(filterAlerts(alerts, true, true, "")))
+	assert.Equal(t, []string{"FoundryCertificateExpiringSoon"}, names(filterAlerts(alerts, true, false, "warning")))
+}
+
+func TestPrintAlerts(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	printAlerts(&buf, []alert{{
+		Labels:      map[string]string{"alertname": "FoundryZotDown", "severity": "critical"},
+		Annotations: map[string]string{"summary": "Zot registry on 10.0.0.5:5000 is down"},
+		State:       "firing",
+		ActiveAt:    now.Add(-3*time.Hour - 12*time.Minute),
+	}}, now)
+
+	out := buf.String()
+	assert.Contains(t, out, "ALERT")
+	assert.Contains(t, out, "FoundryZotDown")
+	assert.Contains(t, out, "3h12m")
+	assert.Contains(t, out, "Zot registry on 10.0.0.5:5000 is down")
+}
+
+func TestSince(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, "30s", since(now.Add(-30*time.Second), now))
+	assert.Equal(t, "5m", since(now.Add(-5*time.Minute), now))
+	assert.Equal(t, "26h0m", since(now.Add(-26*time.Hour), now))
+	assert.Equal(t, "3d", since(now.Add(-72*time.Hour), now))
+}
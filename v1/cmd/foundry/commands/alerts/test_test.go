@@ -0,0 +1,45 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+)
+
+func TestTestAlert(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := testAlert("ops", now, 5*time.Minute)
+
+	assert.Equal(t, "FoundryTestAlert", a.Labels["alertname"])
+	assert.Equal(t, "ops", a.Labels["foundry_test_receiver"])
+	assert.Equal(t, "1772366400", a.Labels["test_id"])
+	assert.Equal(t, now, a.StartsAt)
+	assert.Equal(t, now.Add(5*time.Minute), a.EndsAt)
+	assert.Contains(t, a.Annotations["summary"], "ops")
+}
+
+func TestStackAlerting(t *testing.T) {
+	_, err := stackAlerting(&config.Config{})
+	assert.ErrorContains(t, err, "no receivers configured")
+
+	stackConfig := &config.Config{
+		Components: config.ComponentMap{
+			"prometheus": {Config: map[string]any{
+				"alerting": map[string]interface{}{
+					"receivers": []interface{}{
+						map[string]interface{}{"name": "phone", "type": "ntfy", "url": "https://ntfy.example.com", "topic": "alerts"},
+						map[string]interface{}{"name": "hook", "type": "webhook", "url": "https://hooks.example.com"},
+					},
+				},
+			}},
+		},
+	}
+	alerting, err := stackAlerting(stackConfig)
+	require.NoError(t, err)
+	assert.NotNil(t, alerting.Receiver("phone"))
+	assert.Equal(t, "hook, phone", receiverNames(alerting))
+}
@@ -53,6 +53,9 @@ type BackupInfo struct {
 	ExcludedNamespaces []string
 	Errors             int64
 	Warnings           int64
+	StorageLocation    string
+	// ScheduleName is the Velero schedule that made the backup, if any
+	ScheduleName string
 }
 
 // RestoreInfo contains information about a Velero restore
@@ -225,6 +228,25 @@ func (c *VeleroClient) CreateRestore(ctx context.Context, name, backupName strin
 	if opts.RestorePVs != nil {
 		spec["restorePVs"] = *opts.RestorePVs
 	}
+	if len(opts.NamespaceMapping) > 0 {
+		mapping := make(map[string]interface{}, len(opts.NamespaceMapping))
+		for from, to := range opts.NamespaceMapping {
+			mapping[from] = to
+		}
+		spec["namespaceMapping"] = mapping
+	}
+	if len(opts.ExcludedResources) > 0 {
+		spec["excludedResources"] = toInterfaceSlice(opts.ExcludedResources)
+	}
+	if opts.IncludeClusterResources != nil {
+		spec["includeClusterResources"] = *opts.IncludeClusterResources
+	}
+	if opts.ResourceModifier != "" {
+		spec["resourceModifier"] = map[string]interface{}{
+			"kind": "ConfigMap",
+			"name": opts.ResourceModifier,
+		}
+	}
 
 	// Create the restore resource
 	restore := &unstructured.Unstructured{
@@ -252,6 +274,14 @@ type RestoreOptions struct {
 	IncludedNamespaces []string
 	ExcludedNamespaces []string
 	RestorePVs         *bool
+	// NamespaceMapping restores namespaces under other names (original -> target)
+	NamespaceMapping map[string]string
+	// ExcludedResources are resource types left out, e.g. httproutes.gateway.networking.k8s.io
+	ExcludedResources       []string
+	IncludeClusterResources *bool
+	// ResourceModifier names a ConfigMap in the Velero namespace with rules
+	// that change resources as they are restored
+	ResourceModifier string
 }
 
 // ListRestores lists all Velero restores
@@ -408,6 +438,9 @@ func parseBackupInfo(u *unstructured.Unstructured) BackupInfo {
 	excludedNS, _, _ := unstructured.NestedStringSlice(u.Object, "spec", "excludedNamespaces")
 	info.ExcludedNamespaces = excludedNS
 
+	info.StorageLocation, _, _ = unstructured.NestedString(u.Object, "spec", "storageLocation")
+	info.ScheduleName = u.GetLabels()["velero.io/schedule-name"]
+
 	return info
 }
 
@@ -15,12 +15,14 @@ Typical workflow:
   1. foundry backup create               - Create a backup
   2. foundry backup list                 - View available backups
   3. foundry backup restore <name>       - Restore from a backup
-  4. foundry backup schedule             - Configure scheduled backups`,
+  4. foundry backup verify               - Check the newest backup restores
+  5. foundry backup schedule             - Configure scheduled backups`,
 	Commands: []*cli.Command{
 		CreateCommand,
 		LocalCommand,
 		ListCommand,
 		RestoreCommand,
+		VerifyCommand,
 		ScheduleCommand,
 		DeleteCommand,
 	},
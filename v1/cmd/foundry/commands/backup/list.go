@@ -16,7 +16,9 @@ var ListCommand = &cli.Command{
 	Usage: "List all cluster backups",
 	Description: `Lists all Velero backups in the cluster.
 
-Shows backup name, status, start time, and item counts.
+Shows backup name, status, start time, and item counts. When off-site
+replication is configured, also shows whether each backup has been
+replicated and how far replication is behind.
 
 Examples:
   foundry backup list              # List all backups
@@ -65,12 +67,21 @@ func runList(ctx context.Context, cmd *cli.Command) error {
 		return backups[i].StartTimestamp.After(*backups[j].StartTimestamp)
 	})
 
+	offsite, err := client.GetOffsiteStatus(ctx)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("BACKUPS")
 	fmt.Println(strings.Repeat("-", 90))
 	if len(backups) == 0 {
 		fmt.Println("No backups found")
 	} else {
-		fmt.Printf("%-30s %-15s %-20s %-10s %-10s\n", "NAME", "STATUS", "STARTED", "ITEMS", "ERRORS")
+		header := fmt.Sprintf("%-30s %-15s %-20s %-10s %-10s", "NAME", "STATUS", "STARTED", "ITEMS", "ERRORS")
+		if offsite != nil {
+			header += " OFFSITE"
+		}
+		fmt.Println(header)
 		for _, b := range backups {
 			startTime := "N/A"
 			if b.StartTimestamp != nil {
@@ -84,9 +95,17 @@ func runList(ctx context.Context, cmd *cli.Command) error {
 			if b.Errors > 0 {
 				errors = fmt.Sprintf("%d", b.Errors)
 			}
-			fmt.Printf("%-30s %-15s %-20s %-10s %-10s\n", truncate(b.Name, 30), b.Status, startTime, items, errors)
+			row := fmt.Sprintf("%-30s %-15s %-20s %-10s %-10s", truncate(b.Name, 30), b.Status, startTime, items, errors)
+			if offsite != nil {
+				row += " " + offsiteState(offsite, b)
+			}
+			fmt.Println(row)
 		}
 	}
+	if offsite != nil {
+		fmt.Println()
+		printOffsiteSummary(offsite, backups, time.Now())
+	}
 	fmt.Println()
 
 	// List restores if requested
@@ -56,18 +56,24 @@ How it works (all via foundry, fully torn down afterward):
 Requires the velero node-agent (set deploy_node_agent: true on the velero component
 and run 'foundry component install velero').
 
+List the local backups with 'foundry backup local list' and restore one with
+'foundry backup local restore <name>'. With components.velero.local_retention
+set, the backups it no longer keeps are pruned after each backup; see
+'foundry backup local prune'.
+
 Examples:
   foundry backup local
   foundry backup local my-full-backup --exclude-namespace kube-system`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{Name: "node", Usage: "Hostname of the cluster node to tunnel through (default: first control-plane)"},
-		&cli.IntFlag{Name: "port", Usage: "Port for the local S3 endpoint and node-side tunnel bind", Value: 33099},
-		&cli.StringSliceFlag{Name: "namespace", Aliases: []string{"n"}, Usage: "Only back up these namespaces (default: all). Useful for a quick validation run"},
-		&cli.StringSliceFlag{Name: "exclude-namespace", Usage: "Namespaces to exclude", Value: []string{"kube-system"}},
-		&cli.StringFlag{Name: "ttl", Usage: "Backup retention period", Value: "720h"},
-		&cli.DurationFlag{Name: "timeout", Usage: "Max time to wait for the backup", Value: 2 * time.Hour},
-		&cli.StringFlag{Name: "weed-binary", Usage: "Path to a 'weed' (SeaweedFS) binary (otherwise auto-detected/downloaded)"},
-		&cli.BoolFlag{Name: "keep", Usage: "Leave the tunnel/S3/BSL up after the backup (debug; sshd still auto-reverts)"},
+	Flags: append(localTargetFlags(2*time.Hour),
+		&cli.StringSliceFlag{Name: "namespace", Aliases: []string{"n"}, Usage: "Only back up these namespaces (default: all). Useful for a quick validation run", Local: true},
+		&cli.StringSliceFlag{Name: "exclude-namespace", Usage: "Namespaces to exclude", Value: []string{"kube-system"}, Local: true},
+		&cli.StringFlag{Name: "ttl", Usage: "Backup retention period", Value: "720h", Local: true},
+		&cli.BoolFlag{Name: "no-prune", Usage: "Don't apply local_retention after the backup", Local: true},
+	),
+	Commands: []*cli.Command{
+		LocalListCommand,
+		LocalRestoreCommand,
+		LocalPruneCommand,
 	},
 	Action: runLocalBackup,
 }
@@ -83,7 +89,51 @@ func runLocalBackup(ctx context.Context, cmd *cli.Command) error {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// Load stack config.
+	target, err := openLocalTarget(ctx, cmd, false)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	// 5) Run the File System Backup.
+	vc, err := NewVeleroClient()
+	if err != nil {
+		return err
+	}
+	fsTrue := true
+	opts := BackupOptions{
+		IncludedNamespaces:       cmd.StringSlice("namespace"),
+		ExcludedNamespaces:       cmd.StringSlice("exclude-namespace"),
+		TTL:                      cmd.String("ttl"),
+		DefaultVolumesToFsBackup: &fsTrue,
+		StorageLocation:          localBSLName,
+	}
+	fmt.Printf("Creating File System Backup %q (streaming PV data to this machine)...\n", name)
+	if err := vc.CreateBackup(ctx, name, opts); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	if err := waitForBackup(ctx, vc, name, cmd.Duration("timeout")); err != nil {
+		if terr := target.tunnel.Err(); terr != nil {
+			return fmt.Errorf("%w\n  tunnel error: %v", err, terr)
+		}
+		return err
+	}
+
+	fmt.Printf("\n✓ Backup %q stored locally at %s\n", name, target.dataDir)
+	if target.keep {
+		fmt.Println("⚠ --keep set: tunnel, local S3 and temp BSL left running; sshd will auto-revert via the dead-man timer.")
+		return nil
+	}
+	if cmd.Bool("no-prune") {
+		return nil
+	}
+	return pruneAfterLocalBackup(ctx, cmd, target)
+}
+
+// pruneAfterLocalBackup applies components.velero.local_retention, if the
+// stack config has it, once the local store is no longer in use. A failed
+// prune leaves the new backup in place, so it only warns.
+func pruneAfterLocalBackup(ctx context.Context, cmd *cli.Command, target *localTarget) error {
 	configPath, err := config.FindConfig(cmd.String("config"))
 	if err != nil {
 		return err
@@ -92,70 +142,145 @@ func runLocalBackup(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	retention, err := stackLocalRetention(cfg)
+	if err != nil || retention == nil {
+		return err
+	}
 	configDir, err := config.GetConfigDir()
 	if err != nil {
 		return err
 	}
+
+	target.Close()
+	fmt.Println("\nApplying local_retention...")
+	prune := &localPrune{
+		configDir:  configDir,
+		dataDir:    target.dataDir,
+		retention:  retention,
+		port:       cmd.Int("port"),
+		weedBinary: cmd.String("weed-binary"),
+	}
+	if err := prune.Run(ctx); err != nil {
+		fmt.Printf("⚠ Pruning the local backups failed: %v\n  Retry with: foundry backup local prune\n", err)
+	}
+	return nil
+}
+
+// localTarget is the local backup store while the cluster can reach it: the
+// local S3, sshd GatewayPorts on one node, the reverse tunnel and the
+// temporary foundry-local BackupStorageLocation.
+type localTarget struct {
+	dataDir string
+	tunnel  *reverseTunnel
+	keep    bool
+
+	// teardown runs in reverse order on Close
+	teardown []func()
+}
+
+func (t *localTarget) onClose(f func()) {
+	t.teardown = append(t.teardown, f)
+}
+
+// Close tears the target down in the reverse order it was set up
+func (t *localTarget) Close() {
+	for i := len(t.teardown) - 1; i >= 0; i-- {
+		t.teardown[i]()
+	}
+	t.teardown = nil
+}
+
+// openLocalTarget brings up the local backup store and points a temporary
+// Velero BackupStorageLocation at it through a reverse tunnel. A readOnly
+// target serves an existing store to restore from: no bucket is created and
+// Velero sees the location as ReadOnly. Uses the node, port, timeout,
+// weed-binary and keep flags.
+func openLocalTarget(ctx context.Context, cmd *cli.Command, readOnly bool) (target *localTarget, err error) {
+	// Load stack config.
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
 	kubeconfigPath := filepath.Join(configDir, "kubeconfig")
+	dataDir := localBackupsDir(configDir, cfg.Cluster.Name)
+	if readOnly {
+		if _, err := os.Stat(dataDir); err != nil {
+			return nil, fmt.Errorf("no local backups at %s", dataDir)
+		}
+	}
 
 	// Kubernetes clients.
 	restCfg, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
-		return fmt.Errorf("failed to build kube config: %w", err)
+		return nil, fmt.Errorf("failed to build kube config: %w", err)
 	}
 	clientset, err := kubernetes.NewForConfig(restCfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	dynClient, err := dynamic.NewForConfig(restCfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Precondition: node-agent must be deployed for File System Backup.
+	// Precondition: node-agent must be deployed to move volume data either way.
 	if _, err := clientset.AppsV1().DaemonSets(VeleroNamespace).Get(ctx, "node-agent", metav1.GetOptions{}); err != nil {
 		if apierrors.IsNotFound(err) {
-			return fmt.Errorf("velero node-agent is not deployed (required for volume data backup)\n\n" +
+			return nil, fmt.Errorf("velero node-agent is not deployed (required for volume data backup and restore)\n\n" +
 				"Enable it: set `deploy_node_agent: true` under the velero component in your stack config,\n" +
 				"then run: foundry component install velero")
 		}
-		return fmt.Errorf("failed to check node-agent: %w", err)
+		return nil, fmt.Errorf("failed to check node-agent: %w", err)
 	}
 
 	// Select the node to tunnel through.
 	node, err := selectTunnelNode(cfg, cmd.String("node"))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	port := cmd.Int("port")
 	bindAddr := fmt.Sprintf("%s:%d", node.Address, port)
 	localAddr := fmt.Sprintf("127.0.0.1:%d", port)
 	fmt.Printf("Tunnel node: %s (%s); local target: ~/.foundry/%s_backups\n", node.Hostname, node.Address, cfg.Cluster.Name)
 
+	target = &localTarget{dataDir: dataDir, keep: cmd.Bool("keep")}
+	defer func() {
+		if err != nil {
+			target.Close()
+		}
+	}()
+
 	// SSH to the node using foundry's stored key.
 	conn, err := connectToHost(configDir, cfg.Cluster.Name, node)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer conn.Close()
+	target.onClose(func() { conn.Close() })
 
 	// 1) Local SeaweedFS S3 target.
 	weedBin, err := ensureWeedBinary(cmd.String("weed-binary"), configDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	dataDir := filepath.Join(configDir, cfg.Cluster.Name+"_backups")
 	s3, err := newLocalS3(weedBin, dataDir, "velero", port)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	s3.readOnly = readOnly
 	fmt.Println("Starting local SeaweedFS S3...")
 	if err := s3.Start(ctx); err != nil {
-		return err
+		return nil, err
 	}
-	keep := cmd.Bool("keep")
-	if !keep {
-		defer s3.Stop()
+	if !target.keep {
+		target.onClose(s3.Stop)
 	}
 
 	// 2) Enable GatewayPorts on the node (auto-reverts after timeout + buffer).
@@ -164,18 +289,18 @@ func runLocalBackup(ctx context.Context, cmd *cli.Command) error {
 	fmt.Printf("Enabling sshd GatewayPorts on %s (auto-reverts in %s if anything goes wrong)...\n", node.Hostname, revertAfter)
 	gpVal, err := gp.Enable(revertAfter)
 	if err != nil {
-		return fmt.Errorf("failed to enable GatewayPorts: %w", err)
+		return nil, fmt.Errorf("failed to enable GatewayPorts: %w", err)
 	}
 	fmt.Printf("  node sshd: %s\n", gpVal)
-	if !keep {
-		defer func() {
+	if !target.keep {
+		target.onClose(func() {
 			fmt.Println("Reverting sshd GatewayPorts...")
 			if out, derr := gp.Disable(); derr != nil {
 				fmt.Printf("  ⚠ revert failed (dead-man timer will still revert it): %v\n", derr)
 			} else {
 				fmt.Printf("  node sshd: %s\n", out)
 			}
-		}()
+		})
 	}
 
 	// 3) Reverse tunnel node:port -> local S3.
@@ -184,16 +309,16 @@ func runLocalBackup(ctx context.Context, cmd *cli.Command) error {
 	// control connection's sshd child still has the old setting.
 	tunnelConn, err := connectToHost(configDir, cfg.Cluster.Name, node)
 	if err != nil {
-		return fmt.Errorf("failed to open tunnel connection: %w", err)
+		return nil, fmt.Errorf("failed to open tunnel connection: %w", err)
 	}
-	defer tunnelConn.Close()
+	target.onClose(func() { tunnelConn.Close() })
 	fmt.Printf("Opening reverse tunnel %s -> %s...\n", bindAddr, localAddr)
-	tunnel, err := startReverseTunnel(tunnelConn.Client(), bindAddr, localAddr)
+	target.tunnel, err = startReverseTunnel(tunnelConn.Client(), bindAddr, localAddr)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !keep {
-		defer tunnel.Close()
+	if !target.keep {
+		target.onClose(func() { target.tunnel.Close() })
 	}
 	// Confirm the tunnel bound the node's routable IP (not loopback).
 	if res, derr := conn.Exec(fmt.Sprintf("ss -ltn 2>/dev/null | grep ':%d ' || true", port)); derr == nil {
@@ -206,47 +331,27 @@ func runLocalBackup(ctx context.Context, cmd *cli.Command) error {
 	// CONNECT to a repo that no longer exists ("repository not initialized") instead
 	// of initializing a fresh one. Defensive here in case a prior teardown was killed.
 	cleanupLocalRepos(ctx, dynClient)
-	if err := createLocalBSL(ctx, clientset, dynClient, node.Address, port, s3.bucket, s3.accessKey, s3.secretKey); err != nil {
-		return err
+	accessMode := "ReadWrite"
+	if readOnly {
+		accessMode = "ReadOnly"
+	}
+	if err := createLocalBSL(ctx, clientset, dynClient, node.Address, port, s3.bucket, s3.accessKey, s3.secretKey, accessMode); err != nil {
+		return nil, err
 	}
-	if !keep {
-		defer deleteLocalBSL(context.Background(), clientset, dynClient)
+	if !target.keep {
+		target.onClose(func() { deleteLocalBSL(context.Background(), clientset, dynClient) })
 	}
 	fmt.Println("Waiting for Velero to validate the off-cluster backup location...")
 	if err := waitForBSLAvailable(ctx, dynClient, 150*time.Second); err != nil {
-		return fmt.Errorf("backup location did not become Available (tunnel/S3 issue): %w\n  tunnel err: %v", err, tunnel.Err())
+		return nil, fmt.Errorf("backup location did not become Available (tunnel/S3 issue): %w\n  tunnel err: %v", err, target.tunnel.Err())
 	}
 	fmt.Println("  ✓ Backup location Available")
+	return target, nil
+}
 
-	// 5) Run the File System Backup.
-	vc, err := NewVeleroClient()
-	if err != nil {
-		return err
-	}
-	fsTrue := true
-	opts := BackupOptions{
-		IncludedNamespaces:       cmd.StringSlice("namespace"),
-		ExcludedNamespaces:       cmd.StringSlice("exclude-namespace"),
-		TTL:                      cmd.String("ttl"),
-		DefaultVolumesToFsBackup: &fsTrue,
-		StorageLocation:          localBSLName,
-	}
-	fmt.Printf("Creating File System Backup %q (streaming PV data to this machine)...\n", name)
-	if err := vc.CreateBackup(ctx, name, opts); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
-	}
-	if err := waitForBackup(ctx, vc, name, cmd.Duration("timeout")); err != nil {
-		if terr := tunnel.Err(); terr != nil {
-			return fmt.Errorf("%w\n  tunnel error: %v", err, terr)
-		}
-		return err
-	}
-
-	fmt.Printf("\n✓ Backup %q stored locally at %s\n", name, dataDir)
-	if keep {
-		fmt.Println("⚠ --keep set: tunnel, local S3 and temp BSL left running; sshd will auto-revert via the dead-man timer.")
-	}
-	return nil
+// localBackupsDir is where the local backups of a stack are kept
+func localBackupsDir(configDir, clusterName string) string {
+	return filepath.Join(configDir, clusterName+"_backups")
 }
 
 // selectTunnelNode picks the node to tunnel through: an explicit --node, else the
@@ -317,8 +422,8 @@ func connectToHost(configDir, clusterName string, node *hostRef) (*ssh.Connectio
 }
 
 // createLocalBSL creates the credentials Secret and a temporary BackupStorageLocation
-// pointing at the tunnel endpoint on the node.
-func createLocalBSL(ctx context.Context, clientset kubernetes.Interface, dynClient dynamic.Interface, nodeIP string, port int, bucket, accessKey, secretKey string) error {
+// pointing at the tunnel endpoint on the node. accessMode is ReadWrite or ReadOnly.
+func createLocalBSL(ctx context.Context, clientset kubernetes.Interface, dynClient dynamic.Interface, nodeIP string, port int, bucket, accessKey, secretKey, accessMode string) error {
 	cloud := fmt.Sprintf("[default]\naws_access_key_id=%s\naws_secret_access_key=%s\n", accessKey, secretKey)
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{Name: localBSLSecret, Namespace: VeleroNamespace},
@@ -338,7 +443,7 @@ func createLocalBSL(ctx context.Context, clientset kubernetes.Interface, dynClie
 		"metadata":   map[string]interface{}{"name": localBSLName, "namespace": VeleroNamespace},
 		"spec": map[string]interface{}{
 			"provider":   "aws",
-			"accessMode": "ReadWrite",
+			"accessMode": accessMode,
 			"objectStorage": map[string]interface{}{
 				"bucket": bucket,
 			},
@@ -354,6 +459,11 @@ func createLocalBSL(ctx context.Context, clientset kubernetes.Interface, dynClie
 			},
 		},
 	}}
+	// A restore waits for Velero to sync the backups from the location, so
+	// sync often rather than the default once a minute
+	if accessMode == "ReadOnly" {
+		_ = unstructured.SetNestedField(bsl.Object, "10s", "spec", "backupSyncPeriod")
+	}
 	if _, err := dynClient.Resource(bslGVR).Namespace(VeleroNamespace).Create(ctx, bsl, metav1.CreateOptions{}); err != nil {
 		if !apierrors.IsAlreadyExists(err) {
 			return fmt.Errorf("failed to create BackupStorageLocation: %w", err)
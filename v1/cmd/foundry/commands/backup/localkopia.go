@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// pinnedKopiaVersion is the kopia release fetched when no `kopia` binary is
+// present. It matches the kopia library of the Velero release the velero
+// component installs, which wrote the repositories.
+const pinnedKopiaVersion = "0.17.0"
+
+const (
+	// repoCredentialsSecret holds the password Velero encrypts the kopia
+	// repositories with
+	repoCredentialsSecret = "velero-repo-credentials"
+	repoPasswordKey       = "repository-password"
+
+	// kopiaRepoUser is the user@host Velero connects to its repositories as;
+	// only it may run maintenance
+	kopiaRepoUser = "default"
+	kopiaRepoHost = "default"
+)
+
+// kopiaRepo is a connection to one of Velero's kopia repositories in the
+// local store. Velero keeps one per namespace, under kopia/<namespace>/.
+type kopiaRepo struct {
+	bin string
+	dir string
+	env []string
+}
+
+// connectKopiaRepo connects to the repository under prefix in the local
+// store. The connection's config and cache live in a temporary directory
+// Close removes.
+func connectKopiaRepo(ctx context.Context, bin string, s3 *localS3, prefix, password string) (*kopiaRepo, error) {
+	dir, err := os.MkdirTemp("", "foundry-kopia-")
+	if err != nil {
+		return nil, err
+	}
+	repo := &kopiaRepo{
+		bin: bin,
+		dir: dir,
+		env: append(os.Environ(),
+			"KOPIA_PASSWORD="+password,
+			"KOPIA_CHECK_FOR_UPDATES=false",
+			"AWS_ACCESS_KEY_ID="+s3.accessKey,
+			"AWS_SECRET_ACCESS_KEY="+s3.secretKey,
+		),
+	}
+	_, err = repo.run(ctx, "repository", "connect", "s3",
+		"--bucket="+s3.bucket,
+		"--prefix="+prefix,
+		fmt.Sprintf("--endpoint=%s:%d", s3.ip, s3.s3Port),
+		"--disable-tls",
+		"--override-username="+kopiaRepoUser,
+		"--override-hostname="+kopiaRepoHost,
+		"--cache-directory="+filepath.Join(dir, "cache"),
+	)
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("failed to open the kopia repository %s: %w", prefix, err)
+	}
+	return repo, nil
+}
+
+func (r *kopiaRepo) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, r.bin, append(args, "--config-file="+filepath.Join(r.dir, "repository.config"))...)
+	cmd.Env = r.env
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kopia %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// SnapshotIDs lists the IDs of the snapshots in the repository, which are
+// the snapshotIDs of Velero's PodVolumeBackups
+func (r *kopiaRepo) SnapshotIDs(ctx context.Context) ([]string, error) {
+	out, err := r.run(ctx, "snapshot", "list", "--all", "--json")
+	if err != nil {
+		return nil, err
+	}
+	var manifests []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &manifests); err != nil {
+		return nil, fmt.Errorf("failed to parse kopia snapshot list: %w", err)
+	}
+	ids := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
+// DeleteSnapshot deletes a snapshot; its contents stay until Maintain
+func (r *kopiaRepo) DeleteSnapshot(ctx context.Context, id string) error {
+	_, err := r.run(ctx, "snapshot", "delete", id, "--delete")
+	return err
+}
+
+// Maintain deletes the contents no snapshot refers to anymore. The usual
+// safety margin is for writers running concurrently, which the local
+// store, only up while foundry uses it, doesn't have.
+func (r *kopiaRepo) Maintain(ctx context.Context) error {
+	_, err := r.run(ctx, "maintenance", "run", "--full", "--safety=none")
+	return err
+}
+
+// Close removes the connection's config and cache
+func (r *kopiaRepo) Close() {
+	_ = os.RemoveAll(r.dir)
+}
+
+// ensureKopiaBinary returns a usable `kopia` path: an explicit override, then
+// a system `kopia`, then ~/.foundry/bin/kopia, otherwise it downloads the
+// pinned release for this OS/arch.
+func ensureKopiaBinary(override, configDir string) (string, error) {
+	if override != "" {
+		if _, err := os.Stat(override); err != nil {
+			return "", fmt.Errorf("--kopia-binary %q not found: %w", override, err)
+		}
+		return override, nil
+	}
+	if p, err := exec.LookPath("kopia"); err == nil {
+		return p, nil
+	}
+	binDir := filepath.Join(configDir, "bin")
+	local := filepath.Join(binDir, "kopia")
+	if fi, err := os.Stat(local); err == nil && fi.Mode()&0o111 != 0 {
+		return local, nil
+	}
+	goos := map[string]string{"darwin": "macOS"}[runtime.GOOS]
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	arch := map[string]string{"amd64": "x64"}[runtime.GOARCH]
+	if arch == "" {
+		arch = runtime.GOARCH
+	}
+	url := fmt.Sprintf("https://github.com/kopia/kopia/releases/download/v%[1]s/kopia-%[1]s-%[2]s-%[3]s.tar.gz", pinnedKopiaVersion, goos, arch)
+	fmt.Printf("Fetching kopia %s for %s/%s...\n", pinnedKopiaVersion, runtime.GOOS, runtime.GOARCH)
+	if err := downloadBinary(url, binDir, "kopia"); err != nil {
+		return "", fmt.Errorf("could not obtain a 'kopia' binary (install kopia or pass --kopia-binary): %w", err)
+	}
+	return local, nil
+}
+
+// clusterRepoPassword reads the kopia repository password from the
+// cluster's velero-repo-credentials Secret
+func clusterRepoPassword(ctx context.Context, configDir string) (string, error) {
+	restCfg, err := clientcmd.BuildConfigFromFlags("", filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		return "", fmt.Errorf("failed to build kube config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return "", err
+	}
+	secret, err := clientset.CoreV1().Secrets(VeleroNamespace).Get(ctx, repoCredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to read the %s Secret: %w", repoCredentialsSecret, err)
+	}
+	password := string(secret.Data[repoPasswordKey])
+	if password == "" {
+		return "", fmt.Errorf("the %s Secret has no %s", repoCredentialsSecret, repoPasswordKey)
+	}
+	return password, nil
+}
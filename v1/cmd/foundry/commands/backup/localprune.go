@@ -0,0 +1,559 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+)
+
+// LocalPruneCommand deletes the local backups the retention policy no
+// longer keeps
+var LocalPruneCommand = &cli.Command{
+	Name:  "prune",
+	Usage: "Delete the local backups the retention policy no longer keeps",
+	Description: `Applies the retention policy to the backups 'foundry backup local' stored
+under ~/.foundry/<stack>_backups, deleting whole backups: their Velero metadata
+and the volume data in the kopia repositories only they use.
+
+The policy is components.velero.local_retention in the stack config:
+
+  components:
+    velero:
+      local_retention:
+        keep_daily: 7      # the newest backup of each of the last 7 days
+        keep_weekly: 4     # ... of the last 4 weeks
+        keep_monthly: 6    # ... of the last 6 months
+        max_size: 200Gi    # then delete the oldest until the store fits
+
+Without it, 7 daily, 4 weekly and 6 monthly backups are kept and the size
+isn't capped. The flags override the policy. The newest completed backup is
+never deleted. When the policy is configured, 'foundry backup local' prunes
+after each backup.
+
+Deleting volume data needs the kopia repository password, which is read from
+the cluster's velero-repo-credentials Secret unless --repo-password is given.
+
+Examples:
+  foundry backup local prune --dry-run
+  foundry backup local prune
+  foundry backup local prune --keep-daily 3 --max-size 100Gi`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "dry-run", Usage: "Show what would be deleted without deleting anything"},
+		&cli.IntFlag{Name: "keep-daily", Usage: "Days to keep the newest backup of"},
+		&cli.IntFlag{Name: "keep-weekly", Usage: "Weeks to keep the newest backup of"},
+		&cli.IntFlag{Name: "keep-monthly", Usage: "Months to keep the newest backup of"},
+		&cli.StringFlag{Name: "max-size", Usage: "Size to shrink the local store to, e.g. 200Gi"},
+		&cli.StringFlag{Name: "repo-password", Usage: "kopia repository password (default: read from the cluster)"},
+		&cli.IntFlag{Name: "port", Usage: "Port for the local S3 endpoint", Value: 33099},
+		&cli.StringFlag{Name: "weed-binary", Usage: "Path to a 'weed' (SeaweedFS) binary (otherwise auto-detected/downloaded)"},
+		&cli.StringFlag{Name: "kopia-binary", Usage: "Path to a 'kopia' binary (otherwise auto-detected/downloaded)"},
+	},
+	Action: runLocalPrune,
+}
+
+// localPrune is a prune of the local store
+type localPrune struct {
+	configDir    string
+	dataDir      string
+	retention    *velero.LocalRetention
+	port         int
+	weedBinary   string
+	kopiaBinary  string
+	repoPassword string
+	dryRun       bool
+}
+
+// localBackup is a backup in the local store with what prune needs to know
+// about it
+type localBackup struct {
+	BackupInfo
+	// Size is the backup's metadata plus the volume data it backed up,
+	// before kopia's deduplication and compression
+	Size int64
+	// Snapshots are the kopia snapshot IDs of its volume data
+	Snapshots []string
+}
+
+// pruneDecision is what the policy does with a backup
+type pruneDecision struct {
+	Backup *localBackup
+	Keep   bool
+	// Reason is the rule that keeps or deletes the backup
+	Reason string
+}
+
+func runLocalPrune(ctx context.Context, cmd *cli.Command) error {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	dataDir := localBackupsDir(configDir, cfg.Cluster.Name)
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		fmt.Printf("No local backups found (%s does not exist)\n", dataDir)
+		return nil
+	}
+
+	retention, err := stackLocalRetention(cfg)
+	if err != nil {
+		return err
+	}
+	if retention == nil {
+		retention = velero.DefaultLocalRetention()
+	}
+	if cmd.IsSet("keep-daily") {
+		retention.KeepDaily = cmd.Int("keep-daily")
+	}
+	if cmd.IsSet("keep-weekly") {
+		retention.KeepWeekly = cmd.Int("keep-weekly")
+	}
+	if cmd.IsSet("keep-monthly") {
+		retention.KeepMonthly = cmd.Int("keep-monthly")
+	}
+	if cmd.IsSet("max-size") {
+		retention.MaxSize = cmd.String("max-size")
+	}
+	if err := retention.Validate(); err != nil {
+		return err
+	}
+
+	return (&localPrune{
+		configDir:    configDir,
+		dataDir:      dataDir,
+		retention:    retention,
+		port:         cmd.Int("port"),
+		weedBinary:   cmd.String("weed-binary"),
+		kopiaBinary:  cmd.String("kopia-binary"),
+		repoPassword: cmd.String("repo-password"),
+		dryRun:       cmd.Bool("dry-run"),
+	}).Run(ctx)
+}
+
+// stackLocalRetention returns components.velero.local_retention, or nil
+// when the stack config has none
+func stackLocalRetention(stackConfig *config.Config) (*velero.LocalRetention, error) {
+	compCfg, ok := stackConfig.Components["velero"]
+	if !ok || compCfg.Config["local_retention"] == nil {
+		return nil, nil
+	}
+	veleroCfg, err := velero.ParseConfig(component.ComponentConfig(compCfg.Config))
+	if err != nil {
+		return nil, fmt.Errorf("invalid velero config: %w", err)
+	}
+	return veleroCfg.LocalRetention, nil
+}
+
+// Run applies the policy. A size cap is enforced on estimated sizes, so
+// after deleting, the store is measured and the policy applied again until
+// the store fits or only the backups the policy protects are left.
+func (p *localPrune) Run(ctx context.Context) error {
+	maxBytes, err := p.retention.MaxSizeBytes()
+	if err != nil {
+		return err
+	}
+	weedBin, err := ensureWeedBinary(p.weedBinary, p.configDir)
+	if err != nil {
+		return err
+	}
+	s3, err := newLocalS3(weedBin, p.dataDir, "velero", p.port)
+	if err != nil {
+		return err
+	}
+	// The bucket exists already; prune never creates one
+	s3.readOnly = true
+	if err := s3.Start(ctx); err != nil {
+		return err
+	}
+	defer s3.Stop()
+
+	startSize, err := dirSize(p.dataDir)
+	if err != nil {
+		return err
+	}
+	storeSize := startSize
+	deleted := 0
+	for {
+		backups, err := readLocalBackups(ctx, s3.BucketURL())
+		if err != nil {
+			return err
+		}
+		decisions := planLocalPrune(backups, p.retention, maxBytes, storeSize, time.Now())
+		printPrunePlan(p.dataDir, decisions, storeSize, maxBytes)
+
+		var pruned, kept []*localBackup
+		var estimate int64
+		for _, d := range decisions {
+			if d.Keep {
+				kept = append(kept, d.Backup)
+			} else {
+				pruned = append(pruned, d.Backup)
+				estimate += d.Backup.Size
+			}
+		}
+		if len(pruned) == 0 {
+			break
+		}
+		if p.dryRun {
+			fmt.Printf("\nDry run: would delete %d backup(s), reclaiming up to %s\n", len(pruned), format.Bytes(estimate))
+			return nil
+		}
+
+		if err := p.deleteBackups(ctx, s3, pruned, kept); err != nil {
+			return err
+		}
+		deleted += len(pruned)
+		if storeSize, err = dirSize(p.dataDir); err != nil {
+			return err
+		}
+		if maxBytes == 0 || storeSize <= maxBytes {
+			break
+		}
+		fmt.Printf("\nThe store is still %s, over the %s cap; applying the policy again\n", format.Bytes(storeSize), format.Bytes(maxBytes))
+	}
+
+	if deleted == 0 {
+		fmt.Println("\nNothing to prune")
+	} else {
+		fmt.Printf("\n✓ Deleted %d backup(s), reclaimed %s (%s → %s)\n", deleted, format.Bytes(startSize-storeSize), format.Bytes(startSize), format.Bytes(storeSize))
+	}
+	if maxBytes > 0 && storeSize > maxBytes {
+		fmt.Printf("⚠ The store is %s, over the %s cap, but the newest completed backup is never deleted\n", format.Bytes(storeSize), format.Bytes(maxBytes))
+	}
+	return nil
+}
+
+// deleteBackups deletes the backups' metadata, then the kopia snapshots no
+// kept backup refers to, then compacts the store. Snapshots are collected
+// against what is kept rather than what is deleted, so the volume data of
+// an earlier interrupted prune is collected too.
+func (p *localPrune) deleteBackups(ctx context.Context, s3 *localS3, pruned, kept []*localBackup) error {
+	for _, b := range pruned {
+		fmt.Printf("Deleting %s...\n", b.Name)
+		if err := deleteFilerPath(ctx, s3.BucketURL()+"/backups/"+url.PathEscape(b.Name)); err != nil {
+			return fmt.Errorf("failed to delete backup %q: %w", b.Name, err)
+		}
+	}
+
+	repos, err := listFilerDirs(ctx, s3.BucketURL()+"/kopia/")
+	if err != nil {
+		return err
+	}
+	if len(repos) > 0 {
+		referenced := map[string]bool{}
+		for _, b := range kept {
+			for _, id := range b.Snapshots {
+				referenced[id] = true
+			}
+		}
+		if err := p.collectSnapshots(ctx, s3, repos, referenced); err != nil {
+			return err
+		}
+	}
+
+	// The filer frees the chunks of deleted objects in the background
+	fmt.Println("Compacting the local store...")
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(5 * time.Second):
+	}
+	return s3.Vacuum(ctx)
+}
+
+// collectSnapshots deletes the snapshots not in referenced from Velero's
+// kopia repositories and the contents only they used
+func (p *localPrune) collectSnapshots(ctx context.Context, s3 *localS3, repos []string, referenced map[string]bool) error {
+	kopiaBin, err := ensureKopiaBinary(p.kopiaBinary, p.configDir)
+	if err != nil {
+		return err
+	}
+	password := p.repoPassword
+	if password == "" {
+		if password, err = clusterRepoPassword(ctx, p.configDir); err != nil {
+			return fmt.Errorf("%w\n\nThe backups' metadata is deleted; their volume data stays until a prune can open the kopia repositories. Pass --repo-password if the cluster is unreachable.", err)
+		}
+	}
+
+	for _, namespace := range repos {
+		repo, err := connectKopiaRepo(ctx, kopiaBin, s3, "kopia/"+namespace+"/", password)
+		if err != nil {
+			return err
+		}
+		err = func() error {
+			defer repo.Close()
+			ids, err := repo.SnapshotIDs(ctx)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if referenced[id] {
+					continue
+				}
+				if err := repo.DeleteSnapshot(ctx, id); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Collecting unused volume data of %s...\n", namespace)
+			return repo.Maintain(ctx)
+		}()
+		if err != nil {
+			return fmt.Errorf("failed to prune the volume data of %s: %w", namespace, err)
+		}
+	}
+	return nil
+}
+
+// planLocalPrune decides which backups the policy keeps. Backups are given
+// newest first. The newest completed backup is always kept; the daily,
+// weekly and monthly rules each keep the newest completed backup of their
+// most recent periods; failed backups are never kept. Then, while the store
+// is estimated to exceed maxBytes, the oldest kept backups are deleted.
+func planLocalPrune(backups []localBackup, r *velero.LocalRetention, maxBytes, storeSize int64, now time.Time) []pruneDecision {
+	decisions := make([]pruneDecision, len(backups))
+	var good []int
+	for i := range backups {
+		decisions[i].Backup = &backups[i]
+		if backups[i].Status == "Completed" && backups[i].StartTimestamp != nil {
+			good = append(good, i)
+		} else {
+			decisions[i].Reason = "not completed"
+		}
+	}
+	if len(good) == 0 {
+		return decisions
+	}
+
+	keep := func(i int, reason string) {
+		if !decisions[i].Keep {
+			decisions[i].Keep = true
+			decisions[i].Reason = reason
+		}
+	}
+	keep(good[0], "latest")
+	rules := []struct {
+		reason string
+		count  int
+		period func(time.Time) string
+	}{
+		{"daily", r.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{"weekly", r.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"monthly", r.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, rule := range rules {
+		seen := map[string]bool{}
+		for _, i := range good {
+			period := rule.period(backups[i].StartTimestamp.In(now.Location()))
+			if seen[period] {
+				continue
+			}
+			if len(seen) == rule.count {
+				break
+			}
+			seen[period] = true
+			keep(i, rule.reason)
+		}
+	}
+	for i := range decisions {
+		if !decisions[i].Keep && decisions[i].Reason == "" {
+			decisions[i].Reason = "expired"
+		}
+	}
+
+	if maxBytes > 0 {
+		estimate := storeSize
+		for _, d := range decisions {
+			if !d.Keep {
+				estimate -= d.Backup.Size
+			}
+		}
+		for i := len(decisions) - 1; i >= 0 && estimate > maxBytes; i-- {
+			if !decisions[i].Keep || i == good[0] {
+				continue
+			}
+			decisions[i].Keep = false
+			decisions[i].Reason = "size cap"
+			estimate -= decisions[i].Backup.Size
+		}
+	}
+	return decisions
+}
+
+func printPrunePlan(dataDir string, decisions []pruneDecision, storeSize, maxBytes int64) {
+	capNote := ""
+	if maxBytes > 0 {
+		capNote = fmt.Sprintf(" of %s", format.Bytes(maxBytes))
+	}
+	fmt.Printf("LOCAL BACKUPS (%s, %s%s)\n", dataDir, format.Bytes(storeSize), capNote)
+	fmt.Println(strings.Repeat("-", 90))
+	if len(decisions) == 0 {
+		fmt.Println("No backups found")
+		return
+	}
+	fmt.Printf("%-30s %-15s %-20s %-10s %-20s\n", "NAME", "STATUS", "STARTED", "SIZE", "ACTION")
+	for _, d := range decisions {
+		startTime := "N/A"
+		if d.Backup.StartTimestamp != nil {
+			startTime = d.Backup.StartTimestamp.Local().Format("2006-01-02 15:04")
+		}
+		action := "keep (" + d.Reason + ")"
+		if !d.Keep {
+			action = "delete (" + d.Reason + ")"
+		}
+		fmt.Printf("%-30s %-15s %-20s %-10s %-20s\n", truncate(d.Backup.Name, 30), d.Backup.Status, startTime, format.Bytes(d.Backup.Size), action)
+	}
+}
+
+// readLocalBackups lists the local backups, newest first, with their sizes
+// and kopia snapshots
+func readLocalBackups(ctx context.Context, bucketURL string) ([]localBackup, error) {
+	infos, err := listLocalBackups(ctx, bucketURL)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]localBackup, 0, len(infos))
+	for _, info := range infos {
+		b := localBackup{BackupInfo: info}
+		dir := bucketURL + "/backups/" + url.PathEscape(info.Name)
+
+		var listing struct {
+			Entries []filerEntry `json:"Entries"`
+		}
+		if _, err := getFilerJSON(ctx, dir+"/?limit=1000", &listing); err != nil {
+			return nil, fmt.Errorf("failed to list backup %q: %w", info.Name, err)
+		}
+		for _, entry := range listing.Entries {
+			b.Size += entry.FileSize
+		}
+
+		volumes, err := readPodVolumeBackups(ctx, dir+"/"+url.PathEscape(info.Name)+"-podvolumebackups.json.gz")
+		if err != nil {
+			// Without them its volume data would look unused and be collected
+			return nil, fmt.Errorf("failed to read the volume backups of %q: %w", info.Name, err)
+		}
+		for _, v := range volumes {
+			b.Size += v.Status.Progress.BytesDone
+			if v.Status.SnapshotID != "" {
+				b.Snapshots = append(b.Snapshots, v.Status.SnapshotID)
+			}
+		}
+		backups = append(backups, b)
+	}
+	return backups, nil
+}
+
+// podVolumeBackup is the part of a Velero PodVolumeBackup prune reads
+type podVolumeBackup struct {
+	Status struct {
+		SnapshotID string `json:"snapshotID"`
+		Progress   struct {
+			BytesDone int64 `json:"bytesDone"`
+		} `json:"progress"`
+	} `json:"status"`
+}
+
+// readPodVolumeBackups reads a backup's gzipped list of PodVolumeBackups.
+// Backups without volume data have none.
+func readPodVolumeBackups(ctx context.Context, u string) ([]podVolumeBackup, error) {
+	body, found, err := getFiler(ctx, u)
+	if err != nil || !found {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, err
+	}
+	var volumes []podVolumeBackup
+	if err := utiljson.Unmarshal(data, &volumes); err != nil {
+		return nil, err
+	}
+	return volumes, nil
+}
+
+// listFilerDirs returns the names of the directories in a filer directory
+func listFilerDirs(ctx context.Context, u string) ([]string, error) {
+	var listing struct {
+		Entries []filerEntry `json:"Entries"`
+	}
+	if _, err := getFilerJSON(ctx, u+"?limit=1000", &listing); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", u, err)
+	}
+	var dirs []string
+	for _, entry := range listing.Entries {
+		if os.FileMode(entry.Mode).IsDir() {
+			dirs = append(dirs, path.Base(entry.FullPath))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// deleteFilerPath deletes a file or directory tree through the filer
+func deleteFilerPath(ctx context.Context, u string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u+"?recursive=true", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: HTTP %d: %s", u, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// dirSize is the size of the files under dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,170 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+)
+
+func pruneBackup(name, status string, start time.Time, size int64) localBackup {
+	return localBackup{BackupInfo: BackupInfo{Name: name, Status: status, StartTimestamp: &start}, Size: size}
+}
+
+func pruneActions(decisions []pruneDecision) map[string]string {
+	actions := map[string]string{}
+	for _, d := range decisions {
+		action := "delete"
+		if d.Keep {
+			action = "keep"
+		}
+		actions[d.Backup.Name] = action + " (" + d.Reason + ")"
+	}
+	return actions
+}
+
+func TestPlanLocalPrune_Retention(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	backups := []localBackup{
+		pruneBackup("today-failed", "PartiallyFailed", now, 1),
+		pruneBackup("today", "Completed", day(0).Add(-time.Hour), 1),
+		pruneBackup("today-earlier", "Completed", day(0).Add(-2*time.Hour), 1),
+		pruneBackup("yesterday", "Completed", day(1), 1),
+		pruneBackup("last-week", "Completed", day(7), 1),
+		pruneBackup("two-weeks", "Completed", day(14), 1),
+		pruneBackup("last-month", "Completed", day(30), 1),
+		pruneBackup("last-year", "Completed", day(365), 1),
+	}
+
+	decisions := planLocalPrune(backups, &velero.LocalRetention{KeepDaily: 2, KeepWeekly: 2, KeepMonthly: 2}, 0, 100, now)
+	assert.Equal(t, map[string]string{
+		"today-failed":  "delete (not completed)",
+		"today":         "keep (latest)",
+		"today-earlier": "delete (expired)",
+		"yesterday":     "keep (daily)",
+		"last-week":     "keep (weekly)",
+		"two-weeks":     "delete (expired)",
+		"last-month":    "keep (monthly)",
+		"last-year":     "delete (expired)",
+	}, pruneActions(decisions))
+}
+
+func TestPlanLocalPrune_KeepsLatestGoodBackup(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	backups := []localBackup{
+		pruneBackup("failed", "Failed", now, 10),
+		pruneBackup("good", "Completed", now.AddDate(-1, 0, 0), 50),
+	}
+
+	decisions := planLocalPrune(backups, &velero.LocalRetention{}, 1, 100, now)
+	assert.Equal(t, map[string]string{
+		"failed": "delete (not completed)",
+		"good":   "keep (latest)",
+	}, pruneActions(decisions), "neither the policy nor the size cap deletes the last good backup")
+}
+
+func TestPlanLocalPrune_SizeCap(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	backups := []localBackup{
+		pruneBackup("d0", "Completed", now, 30),
+		pruneBackup("d1", "Completed", now.AddDate(0, 0, -1), 30),
+		pruneBackup("d2", "Completed", now.AddDate(0, 0, -2), 30),
+		pruneBackup("d3", "Completed", now.AddDate(0, 0, -3), 30),
+	}
+
+	decisions := planLocalPrune(backups, &velero.LocalRetention{KeepDaily: 7}, 70, 125, now)
+	assert.Equal(t, map[string]string{
+		"d0": "keep (latest)",
+		"d1": "keep (daily)",
+		"d2": "delete (size cap)",
+		"d3": "delete (size cap)",
+	}, pruneActions(decisions), "the oldest go first until the estimate fits")
+}
+
+func gzipJSON(t *testing.T, v interface{}) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	require.NoError(t, json.NewEncoder(gz).Encode(v))
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestReadLocalBackups(t *testing.T) {
+	dir := uint32(os.ModeDir | 0o755)
+	files := map[string][]byte{
+		"/buckets/velero/backups/local-1/velero-backup.json": []byte(`{"metadata":{"name":"local-1"},"status":{"phase":"Completed","startTimestamp":"2025-01-01T12:00:00Z"}}`),
+		"/buckets/velero/backups/local-1/local-1-podvolumebackups.json.gz": gzipJSON(t, []map[string]interface{}{
+			{"status": map[string]interface{}{"snapshotID": "abc", "progress": map[string]interface{}{"bytesDone": 1000}}},
+			{"status": map[string]interface{}{"snapshotID": "def", "progress": map[string]interface{}{"bytesDone": 500}}},
+		}),
+	}
+	listings := map[string][]map[string]interface{}{
+		"/buckets/velero/backups/": {{"FullPath": "/buckets/velero/backups/local-1", "Mode": dir}},
+		"/buckets/velero/backups/local-1/": {
+			{"FullPath": "/buckets/velero/backups/local-1/velero-backup.json", "FileSize": 20},
+			{"FullPath": "/buckets/velero/backups/local-1/local-1.tar.gz", "FileSize": 30},
+		},
+		"/buckets/velero/kopia/": {
+			{"FullPath": "/buckets/velero/kopia/db", "Mode": dir},
+			{"FullPath": "/buckets/velero/kopia/app", "Mode": dir},
+			{"FullPath": "/buckets/velero/kopia/.lock", "Mode": 0o644},
+		},
+	}
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			assert.Equal(t, "true", r.URL.Query().Get("recursive"))
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if entries, ok := listings[r.URL.Path]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Entries": entries})
+			return
+		}
+		if body, ok := files[r.URL.Path]; ok {
+			_, _ = w.Write(body)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	bucketURL := server.URL + "/buckets/velero"
+
+	backups, err := readLocalBackups(ctx, bucketURL)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "local-1", backups[0].Name)
+	assert.Equal(t, int64(1550), backups[0].Size)
+	assert.Equal(t, []string{"abc", "def"}, backups[0].Snapshots)
+
+	repos, err := listFilerDirs(ctx, bucketURL+"/kopia/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "db"}, repos)
+
+	require.NoError(t, deleteFilerPath(ctx, bucketURL+"/backups/local-1"))
+	assert.Equal(t, []string{"/buckets/velero/backups/local-1"}, deleted)
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/a", make([]byte, 100), 0o600))
+	require.NoError(t, os.Mkdir(dir+"/sub", 0o700))
+	require.NoError(t, os.WriteFile(dir+"/sub/b", make([]byte, 50), 0o600))
+
+	size, err := dirSize(dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(150), size)
+}
@@ -0,0 +1,327 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+)
+
+// LocalListCommand lists the backups stored on this machine
+var LocalListCommand = &cli.Command{
+	Name:  "list",
+	Usage: "List the backups stored on this machine",
+	Description: `Lists the backups 'foundry backup local' stored under ~/.foundry/<stack>_backups.
+
+The local SeaweedFS store is started read-only just long enough to read the
+backups; the cluster is not contacted.
+
+Examples:
+  foundry backup local list`,
+	Flags: []cli.Flag{
+		&cli.IntFlag{Name: "port", Usage: "Port for the local S3 endpoint", Value: 33099},
+		&cli.StringFlag{Name: "weed-binary", Usage: "Path to a 'weed' (SeaweedFS) binary (otherwise auto-detected/downloaded)"},
+	},
+	Action: runLocalList,
+}
+
+// LocalRestoreCommand restores from a backup stored on this machine
+var LocalRestoreCommand = &cli.Command{
+	Name:      "restore",
+	Usage:     "Restore the cluster (incl. PersistentVolume data) from a backup on this machine",
+	ArgsUsage: "<backup-name>",
+	Description: `Restores namespaces and PersistentVolume contents from a backup that
+'foundry backup local' stored on this machine.
+
+It sets up the same path as the backup, read-only: the local SeaweedFS S3 serves
+the existing store, a reverse SSH tunnel through one node exposes it, and a
+temporary ReadOnly Velero BackupStorageLocation points at it. Velero syncs the
+backup from there, restores it with the node-agent pulling volume data through
+the tunnel, and everything is torn down afterward.
+
+Requires the velero node-agent (set deploy_node_agent: true on the velero component
+and run 'foundry component install velero'). Volume data is encrypted with the kopia
+password in the velero-repo-credentials Secret; on a rebuilt cluster, restore that
+Secret from the original cluster first.
+
+Examples:
+  foundry backup local list
+  foundry backup local restore local-20250101-120000
+  foundry backup local restore local-20250101-120000 --namespace app`,
+	Flags: append(localTargetFlags(time.Hour),
+		&cli.StringSliceFlag{Name: "namespace", Aliases: []string{"n"}, Usage: "Only restore these namespaces (default: all in the backup)"},
+		&cli.StringSliceFlag{Name: "exclude-namespace", Usage: "Namespaces to leave out of the restore"},
+		&cli.StringFlag{Name: "name", Usage: "Name for the restore operation (auto-generated if not specified)"},
+	),
+	Action: runLocalRestore,
+}
+
+// localTargetFlags are the flags openLocalTarget reads. They are local to the
+// command they are on, so the local subcommands don't inherit the backup's.
+func localTargetFlags(timeout time.Duration) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "node", Usage: "Hostname of the cluster node to tunnel through (default: first control-plane)", Local: true},
+		&cli.IntFlag{Name: "port", Usage: "Port for the local S3 endpoint and node-side tunnel bind", Value: 33099, Local: true},
+		&cli.DurationFlag{Name: "timeout", Usage: "Max time to wait for Velero", Value: timeout, Local: true},
+		&cli.StringFlag{Name: "weed-binary", Usage: "Path to a 'weed' (SeaweedFS) binary (otherwise auto-detected/downloaded)", Local: true},
+		&cli.BoolFlag{Name: "keep", Usage: "Leave the tunnel/S3/BSL up afterward (debug; sshd still auto-reverts)", Local: true},
+	}
+}
+
+func runLocalList(ctx context.Context, cmd *cli.Command) error {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	dataDir := localBackupsDir(configDir, cfg.Cluster.Name)
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		fmt.Printf("No local backups found (%s does not exist)\n", dataDir)
+		return nil
+	}
+
+	weedBin, err := ensureWeedBinary(cmd.String("weed-binary"), configDir)
+	if err != nil {
+		return err
+	}
+	s3, err := newLocalS3(weedBin, dataDir, "velero", cmd.Int("port"))
+	if err != nil {
+		return err
+	}
+	s3.readOnly = true
+	if err := s3.Start(ctx); err != nil {
+		return err
+	}
+	defer s3.Stop()
+
+	backups, err := listLocalBackups(ctx, s3.BucketURL())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("LOCAL BACKUPS (%s)\n", dataDir)
+	fmt.Println(strings.Repeat("-", 90))
+	if len(backups) == 0 {
+		fmt.Println("No backups found")
+		return nil
+	}
+	fmt.Printf("%-30s %-15s %-20s %-10s %-10s\n", "NAME", "STATUS", "STARTED", "ITEMS", "NAMESPACES")
+	for _, b := range backups {
+		startTime := "N/A"
+		if b.StartTimestamp != nil {
+			startTime = formatTime(*b.StartTimestamp)
+		}
+		namespaces := "all"
+		if len(b.IncludedNamespaces) > 0 {
+			namespaces = strings.Join(b.IncludedNamespaces, ",")
+		}
+		fmt.Printf("%-30s %-15s %-20s %-10d %-10s\n", truncate(b.Name, 30), b.Status, startTime, b.ItemsBackedUp, namespaces)
+	}
+	return nil
+}
+
+func runLocalRestore(ctx context.Context, cmd *cli.Command) error {
+	backupName := cmd.Args().Get(0)
+	if backupName == "" {
+		return fmt.Errorf("backup name is required\n\nUsage: foundry backup local restore <backup-name>\n\nTo see local backups, run:\n  foundry backup local list")
+	}
+
+	// On Ctrl-C/SIGTERM, cancel the context so the waits return and the
+	// teardown runs
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	target, err := openLocalTarget(ctx, cmd, true)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	vc, err := NewVeleroClient()
+	if err != nil {
+		return err
+	}
+
+	// Velero picks up the backups in a new location on its next sync
+	fmt.Printf("Waiting for Velero to sync backup %q from this machine...\n", backupName)
+	backup, err := waitForSyncedBackup(ctx, vc, backupName, localBSLName, 3*time.Minute)
+	if err != nil {
+		return err
+	}
+	if backup.Status != "Completed" {
+		return fmt.Errorf("backup %q is not in Completed state (current: %s)", backupName, backup.Status)
+	}
+
+	restoreName := cmd.String("name")
+	if restoreName == "" {
+		restoreName = fmt.Sprintf("restore-%s-%s", backupName, time.Now().Format("20060102-150405"))
+	}
+	restorePVs := true
+	opts := RestoreOptions{
+		IncludedNamespaces: cmd.StringSlice("namespace"),
+		ExcludedNamespaces: cmd.StringSlice("exclude-namespace"),
+		RestorePVs:         &restorePVs,
+	}
+	fmt.Printf("Creating restore %q from backup %q (pulling PV data from this machine)...\n", restoreName, backupName)
+	if err := vc.CreateRestore(ctx, restoreName, backupName, opts); err != nil {
+		return fmt.Errorf("failed to create restore: %w", err)
+	}
+	// The data path goes away on teardown, so always wait
+	if err := waitForRestore(ctx, vc, restoreName, cmd.Duration("timeout")); err != nil {
+		if terr := target.tunnel.Err(); terr != nil {
+			return fmt.Errorf("%w\n  tunnel error: %v", err, terr)
+		}
+		return err
+	}
+
+	if target.keep {
+		fmt.Println("⚠ --keep set: tunnel, local S3 and temp BSL left running; sshd will auto-revert via the dead-man timer.")
+	}
+	return nil
+}
+
+// waitForSyncedBackup waits for Velero to create the Backup from the
+// backup location. With a location, a Backup already in Velero only counts
+// if it is in that location; the backups "foundry backup local" leaves
+// behind are. A Backup elsewhere is an error right away, since Velero's sync
+// does not replace a Backup that already exists.
+func waitForSyncedBackup(ctx context.Context, client *VeleroClient, name, location string, timeout time.Duration) (*BackupInfo, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if backup, err := client.GetBackup(ctx, name); err == nil {
+			if location != "" && backup.StorageLocation != location {
+				return nil, fmt.Errorf("backup %q already exists in Velero in location %q, so Velero will not sync it from %s\n\nDelete the Backup resource, which leaves its data alone, and retry:\n  kubectl -n %s delete backups.velero.io %s",
+					name, backup.StorageLocation, location, client.namespace, name)
+			}
+			return backup, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("backup %q did not appear in Velero within %s\n\nTo see local backups, run:\n  foundry backup local list", name, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// filerEntry is an entry of a SeaweedFS filer directory listing
+type filerEntry struct {
+	FullPath string `json:"FullPath"`
+	Mode     uint32 `json:"Mode"`
+	FileSize int64  `json:"FileSize"`
+}
+
+// listLocalBackups reads the Velero backups in the bucket through the
+// SeaweedFS filer, newest first. Velero keeps each backup's Backup object in
+// backups/<name>/velero-backup.json.
+func listLocalBackups(ctx context.Context, bucketURL string) ([]BackupInfo, error) {
+	var backups []BackupInfo
+	lastFileName := ""
+	for {
+		var listing struct {
+			Entries               []filerEntry `json:"Entries"`
+			LastFileName          string       `json:"LastFileName"`
+			ShouldDisplayLoadMore bool         `json:"ShouldDisplayLoadMore"`
+		}
+		query := url.Values{"limit": {"1000"}, "lastFileName": {lastFileName}}
+		found, err := getFilerJSON(ctx, bucketURL+"/backups/?"+query.Encode(), &listing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list local backups: %w", err)
+		}
+		if !found {
+			return nil, nil
+		}
+
+		for _, entry := range listing.Entries {
+			if !os.FileMode(entry.Mode).IsDir() {
+				continue
+			}
+			name := path.Base(entry.FullPath)
+			var obj map[string]interface{}
+			found, err := getFilerJSON(ctx, bucketURL+"/backups/"+url.PathEscape(name)+"/velero-backup.json", &obj)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read backup %q: %w", name, err)
+			}
+			if found {
+				backups = append(backups, parseBackupInfo(&unstructured.Unstructured{Object: obj}))
+			}
+		}
+
+		if !listing.ShouldDisplayLoadMore || listing.LastFileName == "" {
+			break
+		}
+		lastFileName = listing.LastFileName
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].StartTimestamp == nil {
+			return false
+		}
+		if backups[j].StartTimestamp == nil {
+			return true
+		}
+		return backups[i].StartTimestamp.After(*backups[j].StartTimestamp)
+	})
+	return backups, nil
+}
+
+// getFilerJSON decodes a JSON response from the filer into v. It returns
+// false if the path doesn't exist.
+func getFilerJSON(ctx context.Context, u string, v interface{}) (bool, error) {
+	body, found, err := getFiler(ctx, u)
+	if err != nil || !found {
+		return found, err
+	}
+	// Decodes whole numbers as int64, as unstructured objects expect
+	return true, utiljson.Unmarshal(body, v)
+}
+
+// getFiler reads a file or listing from the filer. It returns false if the
+// path doesn't exist.
+func getFiler(ctx context.Context, u string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, false, err
+		}
+		return body, true, nil
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("%s: HTTP %d", u, resp.StatusCode)
+	}
+}
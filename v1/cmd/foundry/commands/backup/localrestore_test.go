@@ -0,0 +1,137 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeFiler serves a SeaweedFS filer's JSON listing of /buckets/velero/backups
+// and the velero-backup.json of each backup
+func fakeFiler(t *testing.T, backups map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		if r.URL.Path == "/buckets/velero/backups/" {
+			var entries []map[string]interface{}
+			for name := range backups {
+				entries = append(entries, map[string]interface{}{
+					"FullPath": "/buckets/velero/backups/" + name,
+					"Mode":     uint32(os.ModeDir | 0o755),
+				})
+			}
+			// A stray file next to the backup directories is skipped
+			entries = append(entries, map[string]interface{}{"FullPath": "/buckets/velero/backups/README", "Mode": 0o644})
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Entries": entries})
+			return
+		}
+		for name, body := range backups {
+			if r.URL.Path == "/buckets/velero/backups/"+name+"/velero-backup.json" {
+				_, _ = w.Write([]byte(body))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+}
+
+func TestListLocalBackups(t *testing.T) {
+	server := fakeFiler(t, map[string]string{
+		"local-old": `{"metadata":{"name":"local-old"},"spec":{"includedNamespaces":["app"]},
+			"status":{"phase":"Completed","startTimestamp":"2025-01-01T12:00:00Z","progress":{"itemsBackedUp":12}}}`,
+		"local-new": `{"metadata":{"name":"local-new"},"spec":{},
+			"status":{"phase":"PartiallyFailed","startTimestamp":"2025-02-01T12:00:00Z","errors":2}}`,
+	})
+	defer server.Close()
+
+	backups, err := listLocalBackups(context.Background(), server.URL+"/buckets/velero")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	assert.Equal(t, "local-new", backups[0].Name, "newest first")
+	assert.Equal(t, "PartiallyFailed", backups[0].Status)
+	assert.Equal(t, int64(2), backups[0].Errors)
+
+	assert.Equal(t, "local-old", backups[1].Name)
+	assert.Equal(t, "Completed", backups[1].Status)
+	assert.Equal(t, []string{"app"}, backups[1].IncludedNamespaces)
+	assert.Equal(t, int64(12), backups[1].ItemsBackedUp)
+}
+
+func TestListLocalBackups_EmptyStore(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	backups, err := listLocalBackups(context.Background(), server.URL+"/buckets/velero")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func syncedBackup(name, location string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Backup",
+		"metadata":   map[string]interface{}{"name": name, "namespace": VeleroNamespace},
+		"spec":       map[string]interface{}{"storageLocation": location},
+		"status":     map[string]interface{}{"phase": "Completed"},
+	}}
+}
+
+func TestWaitForSyncedBackup(t *testing.T) {
+	ctx := context.Background()
+
+	// A Backup "foundry backup local" left behind is in the same location
+	client := newTestClient(syncedBackup("nightly", localBSLName))
+	backup, err := waitForSyncedBackup(ctx, client, "nightly", localBSLName, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Completed", backup.Status)
+
+	// A Backup in another location fails without waiting out the timeout
+	client = newTestClient(syncedBackup("nightly", "default"))
+	started := time.Now()
+	_, err = waitForSyncedBackup(ctx, client, "nightly", localBSLName, time.Minute)
+	assert.ErrorContains(t, err, `already exists in Velero in location "default"`)
+	assert.Less(t, time.Since(started), time.Second)
+
+	client = newTestClient()
+	_, err = waitForSyncedBackup(ctx, client, "nightly", localBSLName, 0)
+	assert.ErrorContains(t, err, "did not appear")
+
+	client = newTestClient(syncedBackup("nightly", "default"))
+	_, err = waitForSyncedBackup(ctx, client, "nightly", "", 0)
+	assert.NoError(t, err, "without a location any Backup counts")
+}
+
+func TestLocalTarget_CloseReversesSetup(t *testing.T) {
+	var order []string
+	target := &localTarget{}
+	target.onClose(func() { order = append(order, "stop s3") })
+	target.onClose(func() { order = append(order, "revert sshd") })
+	target.onClose(func() { order = append(order, "delete bsl") })
+
+	target.Close()
+	target.Close() // a second Close is a no-op
+	assert.Equal(t, []string{"delete bsl", "revert sshd", "stop s3"}, order)
+}
+
+func TestLocalCommand_Subcommands(t *testing.T) {
+	var names []string
+	for _, sub := range LocalCommand.Commands {
+		names = append(names, sub.Name)
+	}
+	assert.Equal(t, []string{"list", "restore", "prune"}, names)
+
+	// The backup-only flags don't leak into the subcommands
+	for _, flag := range LocalCommand.Flags {
+		local, ok := flag.(interface{ IsLocal() bool })
+		require.True(t, ok)
+		assert.True(t, local.IsLocal(), "%s should be local", flag.Names()[0])
+	}
+}
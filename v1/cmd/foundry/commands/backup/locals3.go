@@ -35,6 +35,9 @@ type localS3 struct {
 	secretKey  string
 	bucket     string
 
+	// readOnly serves an existing store: the bucket is not created
+	readOnly bool
+
 	cmd      *exec.Cmd
 	s3Config string
 	logFile  *os.File
@@ -62,8 +65,15 @@ func newLocalS3(weedBin, dataDir, bucket string, s3Port int) (*localS3, error) {
 
 func (l *localS3) Endpoint() string { return fmt.Sprintf("http://%s:%d", l.ip, l.s3Port) }
 
+// BucketURL is the bucket's directory on the filer's HTTP API, which lists
+// and reads objects without S3 signing
+func (l *localS3) BucketURL() string {
+	return fmt.Sprintf("http://%s:%d/buckets/%s", l.ip, l.filerPort, l.bucket)
+}
+
 // Start writes the S3 identity config and launches `weed server -s3`, then waits
-// for the S3 port to accept connections and creates the bucket.
+// for the S3 port to accept connections and creates the bucket (or, for a
+// read-only store, waits for the filer).
 func (l *localS3) Start(ctx context.Context) error {
 	// S3 identity config granting our generated keys admin/read/write.
 	cfg := map[string]interface{}{
@@ -120,6 +130,15 @@ func (l *localS3) Start(ctx context.Context) error {
 		return fmt.Errorf("SeaweedFS S3 did not become ready (see %s): %w", logPath, err)
 	}
 
+	// An existing store only needs the filer, which serves the bucket's contents
+	if l.readOnly {
+		if err := waitForPort(ctx, l.ip, l.filerPort, 30*time.Second); err != nil {
+			l.Stop()
+			return fmt.Errorf("SeaweedFS filer did not become ready (see %s): %w", logPath, err)
+		}
+		return nil
+	}
+
 	// Create the bucket via `weed shell` (no S3 signing needed).
 	if err := l.createBucket(ctx); err != nil {
 		l.Stop()
@@ -146,6 +165,18 @@ func (l *localS3) createBucket(ctx context.Context) error {
 	return fmt.Errorf("failed to create bucket %q: %w", l.bucket, lastErr)
 }
 
+// Vacuum compacts the volumes, so the space of deleted objects is returned
+// to the filesystem rather than only marked free
+func (l *localS3) Vacuum(ctx context.Context) error {
+	master := fmt.Sprintf("%s:%d", l.ip, l.masterPort)
+	cmd := exec.CommandContext(ctx, l.weedBin, "shell", "-master="+master)
+	cmd.Stdin = stringsReader("lock\nvolume.vacuum -garbageThreshold 0.0001\nunlock\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to vacuum the local store: %v: %s", err, string(out))
+	}
+	return nil
+}
+
 // Stop terminates the weed process. The data under dataDir is preserved.
 func (l *localS3) Stop() {
 	if l.cmd != nil && l.cmd.Process != nil {
@@ -182,12 +213,17 @@ func ensureWeedBinary(override, configDir string) (string, error) {
 }
 
 func downloadWeed(binDir string) error {
-	if err := os.MkdirAll(binDir, 0o755); err != nil {
-		return err
-	}
 	url := fmt.Sprintf("https://github.com/seaweedfs/seaweedfs/releases/download/%s/%s_%s.tar.gz",
 		pinnedWeedVersion, runtime.GOOS, runtime.GOARCH)
 	fmt.Printf("Fetching SeaweedFS %s for %s/%s...\n", pinnedWeedVersion, runtime.GOOS, runtime.GOARCH)
+	return downloadBinary(url, binDir, "weed")
+}
+
+// downloadBinary extracts the named binary from a release tarball into binDir
+func downloadBinary(url, binDir, name string) error {
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return err
+	}
 	resp, err := http.Get(url)
 	if err != nil {
 		return err
@@ -210,10 +246,10 @@ func downloadWeed(binDir string) error {
 		if err != nil {
 			return err
 		}
-		if filepath.Base(hdr.Name) != "weed" {
+		if filepath.Base(hdr.Name) != name {
 			continue
 		}
-		out, err := os.OpenFile(filepath.Join(binDir, "weed"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+		out, err := os.OpenFile(filepath.Join(binDir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
 		if err != nil {
 			return err
 		}
@@ -223,7 +259,7 @@ func downloadWeed(binDir string) error {
 		}
 		return out.Close()
 	}
-	return fmt.Errorf("'weed' binary not found in release archive")
+	return fmt.Errorf("'%s' binary not found in release archive", name)
 }
 
 func randomHex(n int) string {
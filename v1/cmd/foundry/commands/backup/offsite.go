@@ -0,0 +1,212 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+)
+
+var (
+	cronJobGVR = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+	jobGVR     = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+)
+
+// OffsiteStatus is the state of off-site replication
+type OffsiteStatus struct {
+	Schedule string
+	// LastSync is when the newest successful replication started; every
+	// backup completed before it is off-site
+	LastSync *time.Time
+	// LastFailure is when the newest failed replication started, if it is
+	// newer than LastSync
+	LastFailure *time.Time
+}
+
+// GetOffsiteStatus reads the replication CronJob and its Jobs. It returns
+// nil when off-site replication isn't configured.
+func (c *VeleroClient) GetOffsiteStatus(ctx context.Context) (*OffsiteStatus, error) {
+	cronJob, err := c.dynamicClient.Resource(cronJobGVR).Namespace(c.namespace).Get(ctx, velero.OffsiteReplicationName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get off-site replication: %w", err)
+	}
+	status := &OffsiteStatus{}
+	status.Schedule, _, _ = unstructured.NestedString(cronJob.Object, "spec", "schedule")
+
+	jobs, err := c.dynamicClient.Resource(jobGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list off-site replication runs: %w", err)
+	}
+	for _, job := range jobs.Items {
+		if !ownedBy(&job, "CronJob", velero.OffsiteReplicationName) {
+			continue
+		}
+		started := nestedTime(&job, "status", "startTime")
+		if started == nil {
+			continue
+		}
+		succeeded, _, _ := unstructured.NestedInt64(job.Object, "status", "succeeded")
+		failed := jobFailed(&job)
+		switch {
+		case succeeded > 0 && (status.LastSync == nil || started.After(*status.LastSync)):
+			status.LastSync = started
+		case failed && (status.LastFailure == nil || started.After(*status.LastFailure)):
+			status.LastFailure = started
+		}
+	}
+	if status.LastSync != nil && status.LastFailure != nil && status.LastFailure.Before(*status.LastSync) {
+		status.LastFailure = nil
+	}
+	return status, nil
+}
+
+// Replicated reports whether a backup is in the off-site copy
+func (s *OffsiteStatus) Replicated(b BackupInfo) bool {
+	if b.StorageLocation == velero.OffsiteBSLName {
+		return true
+	}
+	return s.LastSync != nil && b.CompletionTime != nil && b.CompletionTime.Before(*s.LastSync)
+}
+
+// Lag returns how many completed backups are waiting to be replicated and
+// how long the oldest of them has waited
+func (s *OffsiteStatus) Lag(backups []BackupInfo, now time.Time) (int, time.Duration) {
+	waiting := 0
+	var lag time.Duration
+	for _, b := range backups {
+		if b.Status != "Completed" || b.CompletionTime == nil || b.StorageLocation == localBSLName || s.Replicated(b) {
+			continue
+		}
+		waiting++
+		if age := now.Sub(*b.CompletionTime); age > lag {
+			lag = age
+		}
+	}
+	return waiting, lag
+}
+
+// useOffsiteCopy makes a restore of the backup read the off-site copy. A
+// backup that only exists off-site already does; one that is also in the
+// primary location is moved to the offsite location. Velero's sync of
+// either location leaves a Backup in the other alone afterward.
+func useOffsiteCopy(ctx context.Context, client *VeleroClient, backupName string) error {
+	bsl, err := client.dynamicClient.Resource(bslGVR).Namespace(client.namespace).Get(ctx, velero.OffsiteBSLName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("off-site replication is not configured\n\nHint: Add an offsite section under components.velero and run: foundry component install velero")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the offsite location: %w", err)
+	}
+	if phase, _, _ := unstructured.NestedString(bsl.Object, "status", "phase"); phase != "Available" {
+		return fmt.Errorf("the offsite location is not available (phase: %q); check the velero-offsite-gateway deployment", phase)
+	}
+
+	backup, err := client.GetBackup(ctx, backupName)
+	if err != nil {
+		// Not in the cluster yet; Velero syncs it from the offsite location
+		fmt.Printf("Waiting for Velero to sync backup %q from the offsite location...\n", backupName)
+		backup, err = waitForSyncedBackup(ctx, client, backupName, "", 3*time.Minute)
+		if err != nil {
+			return err
+		}
+	}
+	if backup.StorageLocation == velero.OffsiteBSLName {
+		return nil
+	}
+
+	status, err := client.GetOffsiteStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status == nil || !status.Replicated(*backup) {
+		return fmt.Errorf("backup %q has not been replicated off-site yet", backupName)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{"velero.io/storage-location": velero.OffsiteBSLName},
+		},
+		"spec": map[string]interface{}{"storageLocation": velero.OffsiteBSLName},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.dynamicClient.Resource(backupGVR).Namespace(client.namespace).Patch(ctx, backupName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to move backup %q to the offsite location: %w", backupName, err)
+	}
+	fmt.Printf("Backup %q now reads from the offsite location\n", backupName)
+	return nil
+}
+
+func ownedBy(u *unstructured.Unstructured, kind, name string) bool {
+	for _, ref := range u.GetOwnerReferences() {
+		if ref.Kind == kind && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func jobFailed(job *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(job.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == "Failed" && cond["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+func nestedTime(u *unstructured.Unstructured, fields ...string) *time.Time {
+	s, ok, _ := unstructured.NestedString(u.Object, fields...)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// offsiteState is a backup's OFFSITE column in backup list
+func offsiteState(s *OffsiteStatus, b BackupInfo) string {
+	switch {
+	case b.StorageLocation == velero.OffsiteBSLName:
+		return "only"
+	case b.StorageLocation == localBSLName || b.Status != "Completed":
+		return "-"
+	case s.Replicated(b):
+		return "yes"
+	default:
+		return "pending"
+	}
+}
+
+// printOffsiteSummary prints the replication lag under the backup list
+func printOffsiteSummary(s *OffsiteStatus, backups []BackupInfo, now time.Time) {
+	lastSync := "never"
+	if s.LastSync != nil {
+		lastSync = formatTime(*s.LastSync)
+	}
+	fmt.Printf("Off-site replication (%s): last sync %s", s.Schedule, lastSync)
+	if waiting, lag := s.Lag(backups, now); waiting > 0 {
+		fmt.Printf(", %d backup(s) pending, lag %s\n", waiting, lag.Truncate(time.Minute))
+	} else {
+		fmt.Println(", up to date")
+	}
+	if s.LastFailure != nil {
+		fmt.Printf("  ⚠ Last replication failed %s; see: kubectl -n %s get jobs\n", formatTime(*s.LastFailure), VeleroNamespace)
+	}
+}
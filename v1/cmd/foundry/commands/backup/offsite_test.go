@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+)
+
+func replicationJob(name string, started time.Time, succeeded bool) *unstructured.Unstructured {
+	status := map[string]interface{}{"startTime": started.Format(time.RFC3339)}
+	if succeeded {
+		status["succeeded"] = int64(1)
+	} else {
+		status["conditions"] = []interface{}{map[string]interface{}{"type": "Failed", "status": "True"}}
+	}
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": name, "namespace": VeleroNamespace},
+		"status":     status,
+	}}
+	job.SetOwnerReferences([]metav1.OwnerReference{{Kind: "CronJob", Name: velero.OffsiteReplicationName}})
+	return job
+}
+
+func TestVeleroClient_GetOffsiteStatus(t *testing.T) {
+	ctx := context.Background()
+
+	status, err := newTestClient(replicationJob("other", time.Now(), true)).GetOffsiteStatus(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, status, "no CronJob means off-site replication isn't configured")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cronJob := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata":   map[string]interface{}{"name": velero.OffsiteReplicationName, "namespace": VeleroNamespace},
+		"spec":       map[string]interface{}{"schedule": velero.DefaultOffsiteSchedule},
+	}}
+	unrelated := replicationJob("unrelated", now, true)
+	unrelated.SetOwnerReferences(nil)
+	objects := []runtime.Object{
+		cronJob,
+		unrelated,
+		replicationJob("r1", now.Add(-3*time.Hour), true),
+		replicationJob("r2", now.Add(-2*time.Hour), true),
+		replicationJob("r3", now.Add(-time.Hour), false),
+	}
+
+	status, err = newTestClient(objects...).GetOffsiteStatus(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, velero.DefaultOffsiteSchedule, status.Schedule)
+	assert.Equal(t, now.Add(-2*time.Hour), *status.LastSync)
+	assert.Equal(t, now.Add(-time.Hour), *status.LastFailure)
+}
+
+func TestOffsiteStatus_Lag(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		ts := now.Add(-d)
+		return &ts
+	}
+	status := &OffsiteStatus{LastSync: ago(2 * time.Hour)}
+	backups := []BackupInfo{
+		{Name: "replicated", Status: "Completed", CompletionTime: ago(3 * time.Hour)},
+		{Name: "pending-old", Status: "Completed", CompletionTime: ago(90 * time.Minute)},
+		{Name: "pending-new", Status: "Completed", CompletionTime: ago(10 * time.Minute)},
+		{Name: "running", Status: "InProgress"},
+		{Name: "local", Status: "Completed", CompletionTime: ago(time.Minute), StorageLocation: localBSLName},
+		{Name: "offsite-only", Status: "Completed", CompletionTime: ago(time.Minute), StorageLocation: velero.OffsiteBSLName},
+	}
+
+	waiting, lag := status.Lag(backups, now)
+	assert.Equal(t, 2, waiting)
+	assert.Equal(t, 90*time.Minute, lag)
+
+	states := map[string]string{}
+	for _, b := range backups {
+		states[b.Name] = offsiteState(status, b)
+	}
+	assert.Equal(t, map[string]string{
+		"replicated":   "yes",
+		"pending-old":  "pending",
+		"pending-new":  "pending",
+		"running":      "-",
+		"local":        "-",
+		"offsite-only": "only",
+	}, states)
+
+	waiting, _ = (&OffsiteStatus{}).Lag(backups, now)
+	assert.Equal(t, 3, waiting, "nothing is replicated before the first sync")
+}
+
+func TestUseOffsiteCopy_NotConfigured(t *testing.T) {
+	err := useOffsiteCopy(context.Background(), newTestClient(), "b")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "off-site replication is not configured")
+}
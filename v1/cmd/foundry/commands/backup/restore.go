@@ -20,7 +20,12 @@ The backup name is required. You can see available backups with 'foundry backup
 Examples:
   foundry backup restore my-backup                    # Restore from backup
   foundry backup restore my-backup --namespace app   # Restore specific namespace
-  foundry backup restore my-backup --wait            # Wait for restore to complete`,
+  foundry backup restore my-backup --wait            # Wait for restore to complete
+  foundry backup restore my-backup --from-offsite    # Restore from the off-site copy
+
+--from-offsite reads the backup from the off-site replica instead of the
+primary store, e.g. after losing the SeaweedFS data. Backups that only exist
+off-site are synced into the cluster first.`,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:  "name",
@@ -50,6 +55,10 @@ Examples:
 			Usage: "Timeout when waiting for restore (used with --wait)",
 			Value: 30 * time.Minute,
 		},
+		&cli.BoolFlag{
+			Name:  "from-offsite",
+			Usage: "Restore from the off-site replica",
+		},
 	},
 	Action: runRestore,
 }
@@ -67,6 +76,12 @@ func runRestore(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	if cmd.Bool("from-offsite") {
+		if err := useOffsiteCopy(ctx, client, backupName); err != nil {
+			return err
+		}
+	}
+
 	// Verify backup exists
 	backup, err := client.GetBackup(ctx, backupName)
 	if err != nil {
@@ -0,0 +1,888 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+)
+
+// verifyLabel marks the throwaway namespaces of a verification; the value
+// is the verification's id
+const verifyLabel = "foundry.io/backup-verify"
+
+// systemNamespaces are never verified when a backup covers all namespaces
+var systemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease", VeleroNamespace}
+
+// infrastructureNamespaces hold Foundry's own components. They are skipped
+// when a backup covers all namespaces: a copy would start second
+// controllers, and the copy of Envoy would compete for the cluster VIP.
+// Components configured with another namespace are skipped as well.
+var infrastructureNamespaces = []string{
+	"projectcontour",
+	"foundry-system",
+	"longhorn-system",
+	"cert-manager",
+	"openbao",
+	"monitoring",
+	"grafana",
+	"loki",
+	"external-dns",
+	"seaweedfs",
+}
+
+// verifyExcludedResources are left out of verification restores. The
+// restored copies would otherwise take traffic for the live hostnames, issue
+// duplicate certificates, run scheduled jobs or fire duplicate alerts.
+var verifyExcludedResources = []string{
+	"ingresses.networking.k8s.io",
+	"gateways.gateway.networking.k8s.io",
+	"httproutes.gateway.networking.k8s.io",
+	"grpcroutes.gateway.networking.k8s.io",
+	"tlsroutes.gateway.networking.k8s.io",
+	"tcproutes.gateway.networking.k8s.io",
+	"certificates.cert-manager.io",
+	"cronjobs.batch",
+	"servicemonitors.monitoring.coreos.com",
+	"podmonitors.monitoring.coreos.com",
+	"probes.monitoring.coreos.com",
+	"prometheusrules.monitoring.coreos.com",
+}
+
+// verifyModifiersConfigMap holds the Velero resource modifier rules of
+// verification restores, in the velero namespace
+const verifyModifiersConfigMap = "foundry-backup-verify-modifiers"
+
+// verifyResourceModifiers keep restored Services off the live addresses.
+// Envoy's Service claims the cluster VIP through externalIPs, and a
+// LoadBalancer Service would get an address of its own, so the copies drop
+// their external addresses and become ClusterIP Services.
+const verifyResourceModifiers = `version: v1
+resourceModifierRules:
+- conditions:
+    groupResource: services
+  mergePatches:
+  - patchData: |
+      {"metadata": {"annotations": {"kube-vip.io/loadbalancerIPs": null}}, "spec": {"externalIPs": null, "loadBalancerIP": null}}
+- conditions:
+    groupResource: services
+    matches:
+    - path: /spec/type
+      value: LoadBalancer
+  mergePatches:
+  - patchData: |
+      {"spec": {"type": "ClusterIP", "externalTrafficPolicy": null, "healthCheckNodePort": null, "allocateLoadBalancerNodePorts": null, "loadBalancerClass": null, "loadBalancerSourceRanges": null}}
+`
+
+// VerifyCommand test-restores a backup into throwaway namespaces
+var VerifyCommand = &cli.Command{
+	Name:      "verify",
+	Usage:     "Check that a backup restores and its workloads come up",
+	ArgsUsage: "[backup-name]",
+	Description: `Restores a backup into throwaway copies of its namespaces, using Velero's
+namespace mapping (app is restored as app-verify-<id>), and checks the copies:
+
+  1. The restore must complete without errors.
+  2. Every Deployment, StatefulSet and DaemonSet must become ready, and every
+     PersistentVolumeClaim a pod uses must be bound.
+  3. The checks under components.velero.verify.checks run against the copies:
+     an HTTP request to a Service, or a command run in a pod like kubectl exec.
+
+The copies are deleted afterward and the result is recorded in the
+foundry-backup-verify ConfigMap in the velero namespace. Gateways, ingresses,
+routes, certificates, CronJobs and monitoring resources are not restored,
+and restored Services lose their external IPs and become ClusterIP, so the
+copies take no traffic and run no schedules.
+
+Without a backup name, the newest completed backup is verified. When the
+backup covers all namespaces, the namespaces it has that also exist in the
+cluster now are verified, except system namespaces and those of Foundry's
+own components (projectcontour, monitoring, ...). Name one with --namespace
+to verify it anyway.
+
+Set components.velero.verify.schedule (daily, weekly or a duration) and the
+manager verifies the newest backup on that schedule and alerts through
+Alertmanager when a verification fails.
+
+Examples:
+  foundry backup verify
+  foundry backup verify daily-backup-20250101020000 --namespace app
+  foundry backup verify --history`,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "namespace",
+			Aliases: []string{"n"},
+			Usage:   "Only verify these namespaces of the backup",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Max time for the restore, readiness and checks (default: verify.timeout or 30m)",
+		},
+		&cli.BoolFlag{
+			Name:  "keep",
+			Usage: "Leave the restored namespaces in place afterward (debug)",
+		},
+		&cli.BoolFlag{
+			Name:  "history",
+			Usage: "Show the recorded verification results instead of verifying",
+		},
+	},
+	Action: runVerify,
+}
+
+// VerifyResult is the outcome of one backup verification
+type VerifyResult struct {
+	ID         string            `json:"id"`
+	Backup     string            `json:"backup"`
+	Restore    string            `json:"restore,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	Duration   time.Duration     `json:"duration"`
+	Passed     bool              `json:"passed"`
+	Error      string            `json:"error,omitempty"`
+	Namespaces []NamespaceResult `json:"namespaces,omitempty"`
+}
+
+// NamespaceResult is the outcome for one restored namespace
+type NamespaceResult struct {
+	Namespace  string `json:"namespace"`
+	RestoredAs string `json:"restoredAs"`
+	Ready      bool   `json:"ready"`
+	// ReadyAfter is how long the workloads took to become ready after the
+	// restore completed
+	ReadyAfter time.Duration `json:"readyAfter,omitempty"`
+	// Pending are the workloads and claims that never became ready
+	Pending []string      `json:"pending,omitempty"`
+	Checks  []CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of one configured check
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration"`
+	Message  string        `json:"message,omitempty"`
+}
+
+// Failures lists why the verification failed
+func (r *VerifyResult) Failures() []string {
+	var failures []string
+	if r.Error != "" {
+		failures = append(failures, r.Error)
+	}
+	for _, ns := range r.Namespaces {
+		if !ns.Ready {
+			failures = append(failures, fmt.Sprintf("%s: not ready: %s", ns.Namespace, strings.Join(ns.Pending, ", ")))
+		}
+		for _, check := range ns.Checks {
+			if !check.Passed {
+				failures = append(failures, fmt.Sprintf("%s: check %s: %s", ns.Namespace, check.Name, check.Message))
+			}
+		}
+	}
+	return failures
+}
+
+func runVerify(ctx context.Context, cmd *cli.Command) error {
+	out := cmd.Root().Writer
+	if out == nil {
+		out = os.Stdout
+	}
+
+	v, err := newVerifier()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("history") {
+		history, err := loadVerifyHistory(ctx, v.clientset)
+		if err != nil {
+			return err
+		}
+		printVerifyHistory(out, history)
+		return nil
+	}
+
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return err
+	}
+	stackConfig, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	verifyCfg, err := stackVerifyConfig(stackConfig)
+	if err != nil {
+		return err
+	}
+	if verifyCfg == nil {
+		verifyCfg = &velero.VerifyConfig{}
+	}
+
+	timeout := cmd.Duration("timeout")
+	if timeout == 0 {
+		if timeout, err = verifyCfg.TimeoutDuration(); err != nil {
+			return err
+		}
+	}
+	namespaces := cmd.StringSlice("namespace")
+	if len(namespaces) == 0 {
+		namespaces = verifyCfg.Namespaces
+	}
+
+	backupName := cmd.Args().Get(0)
+	if backupName == "" {
+		backups, err := v.velero.ListBackups(ctx)
+		if err != nil {
+			return err
+		}
+		latest := latestVerifiableBackup(backups, "")
+		if latest == nil {
+			return fmt.Errorf("no completed backup to verify\n\nTo create one, run:\n  foundry backup create")
+		}
+		backupName = latest.Name
+	}
+
+	result := v.Verify(ctx, verifyRun{
+		Backup:         backupName,
+		Namespaces:     namespaces,
+		SkipNamespaces: verifySkipNamespaces(stackConfig),
+		Checks:         verifyCfg,
+		Timeout:        timeout,
+		Keep:           cmd.Bool("keep"),
+		Out:            out,
+	})
+	if err := recordVerifyResult(ctx, v.clientset, result); err != nil {
+		fmt.Fprintf(out, "⚠ Could not record the result: %v\n", err)
+	}
+	printVerifyResult(out, result)
+	if !result.Passed {
+		return fmt.Errorf("backup %q failed verification", backupName)
+	}
+	return nil
+}
+
+// stackVerifyConfig reads components.velero.verify from the stack config.
+// It returns nil when verification isn't configured.
+func stackVerifyConfig(stackConfig *config.Config) (*velero.VerifyConfig, error) {
+	compCfg, ok := stackConfig.Components["velero"]
+	if !ok || compCfg.Config["verify"] == nil {
+		return nil, nil
+	}
+	veleroCfg, err := velero.ParseConfig(component.ComponentConfig(compCfg.Config))
+	if err != nil {
+		return nil, fmt.Errorf("invalid velero config: %w", err)
+	}
+	return veleroCfg.Verify, nil
+}
+
+// verifySkipNamespaces returns the infrastructure namespaces a verification
+// of all namespaces skips, including those the stack config moves
+// components to
+func verifySkipNamespaces(stackConfig *config.Config) []string {
+	skip := append([]string{}, infrastructureNamespaces...)
+	for _, compCfg := range stackConfig.Components {
+		ns, _ := compCfg.Config["namespace"].(string)
+		if ns != "" && !matchesAny(ns, skip) {
+			skip = append(skip, ns)
+		}
+	}
+	return skip
+}
+
+// latestVerifiableBackup returns the newest completed backup, optionally
+// only among those a given Velero schedule made. Backups in the temporary
+// local location are skipped; that location is gone after the backup.
+func latestVerifiableBackup(backups []BackupInfo, schedule string) *BackupInfo {
+	var latest *BackupInfo
+	for i := range backups {
+		b := &backups[i]
+		if b.Status != "Completed" || b.StartTimestamp == nil || b.StorageLocation == localBSLName {
+			continue
+		}
+		if schedule != "" && b.ScheduleName != schedule {
+			continue
+		}
+		if latest == nil || b.StartTimestamp.After(*latest.StartTimestamp) {
+			latest = b
+		}
+	}
+	return latest
+}
+
+// verifier runs backup verifications against the cluster
+type verifier struct {
+	clientset  kubernetes.Interface
+	restConfig *rest.Config
+	velero     *VeleroClient
+}
+
+// newVerifier connects to the cluster with the stack's kubeconfig
+func newVerifier() (*verifier, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	kubeconfigPath := filepath.Join(configDir, "kubeconfig")
+	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("kubeconfig not found at %s\n\nHint: Run 'foundry cluster init' first", kubeconfigPath)
+	}
+	restCfg, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kube config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+	dynClient, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &verifier{
+		clientset:  clientset,
+		restConfig: restCfg,
+		velero:     &VeleroClient{dynamicClient: dynClient, namespace: VeleroNamespace},
+	}, nil
+}
+
+// verifyRun is what one verification restores and checks
+type verifyRun struct {
+	Backup string
+	// Namespaces limits the verification (default: all of the backup's)
+	Namespaces []string
+	// SkipNamespaces are left out when Namespaces is empty
+	SkipNamespaces []string
+	// Checks holds the per-namespace checks
+	Checks  *velero.VerifyConfig
+	Timeout time.Duration
+	Keep    bool
+	Out     io.Writer
+}
+
+// Verify restores the backup into throwaway namespaces, waits for the
+// workloads, runs the checks and cleans up. Failures are reported in the
+// result rather than returned.
+func (v *verifier) Verify(ctx context.Context, run verifyRun) *VerifyResult {
+	started := time.Now()
+	result := &VerifyResult{
+		ID:        strconv.FormatInt(started.Unix(), 36),
+		Backup:    run.Backup,
+		StartedAt: started,
+	}
+	defer func() {
+		result.Duration = time.Since(started).Round(time.Second)
+		result.Passed = len(result.Failures()) == 0
+	}()
+	fail := func(format string, args ...interface{}) *VerifyResult {
+		result.Error = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, run.Timeout)
+	defer cancel()
+
+	v.deleteStaleVerifyNamespaces(ctx, run.Out)
+
+	backup, err := v.velero.GetBackup(ctx, run.Backup)
+	if err != nil {
+		return fail("backup %q not found: %v", run.Backup, err)
+	}
+	if backup.Status != "Completed" {
+		return fail("backup %q is not in Completed state (current: %s)", run.Backup, backup.Status)
+	}
+
+	// Namespaces named explicitly are verified even if they are skipped by
+	// default
+	skip := run.SkipNamespaces
+	if len(run.Namespaces) > 0 {
+		skip = nil
+	}
+	namespaces, err := v.backupNamespaces(ctx, backup, skip)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if len(run.Namespaces) > 0 {
+		for _, ns := range run.Namespaces {
+			if !matchesAny(ns, namespaces) {
+				return fail("namespace %q is not in backup %q", ns, run.Backup)
+			}
+		}
+		namespaces = run.Namespaces
+	}
+	if len(namespaces) == 0 {
+		return fail("backup %q has no namespaces to verify", run.Backup)
+	}
+
+	mapping := make(map[string]string, len(namespaces))
+	for _, ns := range namespaces {
+		mapping[ns] = restoredNamespace(ns, result.ID)
+		result.Namespaces = append(result.Namespaces, NamespaceResult{Namespace: ns, RestoredAs: mapping[ns]})
+	}
+	if !run.Keep {
+		defer func() {
+			// The verification's context may be done by now
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			for _, ns := range mapping {
+				v.deleteNamespace(cleanupCtx, run.Out, ns)
+			}
+		}()
+	}
+
+	if err := v.applyVerifyModifiers(ctx); err != nil {
+		return fail("failed to apply the restore's resource modifiers: %v", err)
+	}
+	result.Restore = truncate(fmt.Sprintf("verify-%s-%s", run.Backup, result.ID), 253)
+	restorePVs := true
+	includeClusterResources := false
+	fmt.Fprintf(run.Out, "Restoring backup %q into %d throwaway namespace(s)...\n", run.Backup, len(namespaces))
+	err = v.velero.CreateRestore(ctx, result.Restore, run.Backup, RestoreOptions{
+		IncludedNamespaces:      namespaces,
+		NamespaceMapping:        mapping,
+		RestorePVs:              &restorePVs,
+		ExcludedResources:       verifyExcludedResources,
+		IncludeClusterResources: &includeClusterResources,
+		ResourceModifier:        verifyModifiersConfigMap,
+	})
+	if err != nil {
+		return fail("failed to create restore: %v", err)
+	}
+	restoreErr := waitForRestore(ctx, v.velero, result.Restore, remaining(ctx))
+	// The label lets a later verification find copies this one leaves
+	// behind if it is killed, and keeps them out of "all namespaces"
+	if err := v.labelVerifyNamespaces(ctx, result.ID, mapping); err != nil {
+		fmt.Fprintf(run.Out, "⚠ Could not label the restored namespaces: %v\n", err)
+	}
+	if restoreErr != nil {
+		return fail("restore %s: %v", result.Restore, restoreErr)
+	}
+
+	restored := time.Now()
+	fmt.Fprintln(run.Out, "Waiting for the restored workloads to become ready...")
+	for i := range result.Namespaces {
+		nsResult := &result.Namespaces[i]
+		pending, err := v.waitForNamespaceReady(ctx, nsResult.RestoredAs)
+		if err != nil {
+			pending = append(pending, err.Error())
+		}
+		nsResult.Ready = len(pending) == 0
+		nsResult.Pending = pending
+		if nsResult.Ready {
+			nsResult.ReadyAfter = time.Since(restored).Round(time.Second)
+		}
+	}
+
+	if run.Checks != nil {
+		for i := range result.Namespaces {
+			nsResult := &result.Namespaces[i]
+			for _, check := range run.Checks.ChecksFor(nsResult.Namespace) {
+				fmt.Fprintf(run.Out, "Running check %s in %s...\n", check.Name, nsResult.RestoredAs)
+				nsResult.Checks = append(nsResult.Checks, v.runCheck(ctx, nsResult.RestoredAs, check))
+			}
+		}
+	}
+	return result
+}
+
+// backupNamespaces returns the namespaces a backup covers. For a backup of
+// all namespaces, that is the namespaces in the cluster now, except the
+// system namespaces and those in skip.
+func (v *verifier) backupNamespaces(ctx context.Context, backup *BackupInfo, skip []string) ([]string, error) {
+	included := backup.IncludedNamespaces
+	if len(included) == 0 || (len(included) == 1 && included[0] == "*") {
+		list, err := v.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: "!" + verifyLabel})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		included = nil
+		for _, ns := range list.Items {
+			if !matchesAny(ns.Name, systemNamespaces) && !matchesAny(ns.Name, skip) {
+				included = append(included, ns.Name)
+			}
+		}
+	}
+
+	var namespaces []string
+	for _, ns := range included {
+		if !matchesAny(ns, backup.ExcludedNamespaces) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// matchesAny reports whether name matches one of the namespace patterns,
+// which may use Velero's glob wildcards
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// restoredNamespace names the throwaway copy of a namespace. Namespace names
+// are at most 63 characters, so long names are shortened.
+func restoredNamespace(namespace, id string) string {
+	suffix := "-verify-" + id
+	if len(namespace)+len(suffix) > 63 {
+		namespace = strings.TrimRight(namespace[:63-len(suffix)], "-")
+	}
+	return namespace + suffix
+}
+
+// applyVerifyModifiers creates or updates the ConfigMap with the resource
+// modifier rules of verification restores
+func (v *verifier) applyVerifyModifiers(ctx context.Context) error {
+	data := map[string]string{"rules.yaml": verifyResourceModifiers}
+	configMaps := v.clientset.CoreV1().ConfigMaps(VeleroNamespace)
+	cm, err := configMaps.Get(ctx, verifyModifiersConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      verifyModifiersConfigMap,
+				Namespace: VeleroNamespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "foundry"},
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// labelVerifyNamespaces labels the restored namespaces that exist
+func (v *verifier) labelVerifyNamespaces(ctx context.Context, id string, mapping map[string]string) error {
+	patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, verifyLabel, id))
+	for _, ns := range mapping {
+		_, err := v.clientset.CoreV1().Namespaces().Patch(ctx, ns, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStaleVerifyNamespaces deletes the copies that verifications which
+// were killed midway left behind. Copies younger than a day may belong to a
+// verification that is still running.
+func (v *verifier) deleteStaleVerifyNamespaces(ctx context.Context, out io.Writer) {
+	list, err := v.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: verifyLabel})
+	if err != nil {
+		fmt.Fprintf(out, "⚠ Could not list verification namespaces: %v\n", err)
+		return
+	}
+	for _, ns := range list.Items {
+		if time.Since(ns.CreationTimestamp.Time) > 24*time.Hour {
+			v.deleteNamespace(ctx, out, ns.Name)
+		}
+	}
+}
+
+// deleteNamespace deletes a throwaway namespace. Deletion finishes in the
+// background.
+func (v *verifier) deleteNamespace(ctx context.Context, out io.Writer, name string) {
+	err := v.clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		fmt.Fprintf(out, "⚠ Could not delete namespace %s: %v\n", name, err)
+	default:
+		fmt.Fprintf(out, "Deleted namespace %s\n", name)
+	}
+}
+
+// waitForNamespaceReady waits until the namespace's workloads are ready. It
+// returns what is still pending when the context ends.
+func (v *verifier) waitForNamespaceReady(ctx context.Context, namespace string) ([]string, error) {
+	for {
+		pending, err := v.namespacePending(ctx, namespace)
+		if err == nil && len(pending) == 0 {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return nil, err
+			}
+			return pending, nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// namespacePending lists the workloads and claims in a namespace that
+// aren't ready yet
+func (v *verifier) namespacePending(ctx context.Context, namespace string) ([]string, error) {
+	apps := v.clientset.AppsV1()
+	deployments, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	claims, err := v.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := v.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pendingWorkloads(deployments.Items, statefulSets.Items, daemonSets.Items, claims.Items, pods.Items), nil
+}
+
+// pendingWorkloads lists the workloads that don't have all their replicas
+// ready and the claims that pods use but aren't bound
+func pendingWorkloads(deployments []appsv1.Deployment, statefulSets []appsv1.StatefulSet, daemonSets []appsv1.DaemonSet, claims []corev1.PersistentVolumeClaim, pods []corev1.Pod) []string {
+	var pending []string
+	for _, d := range deployments {
+		if want := replicas(d.Spec.Replicas); d.Status.AvailableReplicas < want {
+			pending = append(pending, fmt.Sprintf("deployment/%s (%d/%d)", d.Name, d.Status.AvailableReplicas, want))
+		}
+	}
+	for _, s := range statefulSets {
+		if want := replicas(s.Spec.Replicas); s.Status.ReadyReplicas < want {
+			pending = append(pending, fmt.Sprintf("statefulset/%s (%d/%d)", s.Name, s.Status.ReadyReplicas, want))
+		}
+	}
+	for _, d := range daemonSets {
+		if d.Status.NumberReady < d.Status.DesiredNumberScheduled {
+			pending = append(pending, fmt.Sprintf("daemonset/%s (%d/%d)", d.Name, d.Status.NumberReady, d.Status.DesiredNumberScheduled))
+		}
+	}
+
+	// Unused claims may wait for a consumer forever, so only claims that
+	// pods mount have to be bound
+	used := map[string]bool{}
+	for _, pod := range pods {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil {
+				used[vol.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+	}
+	for _, c := range claims {
+		if used[c.Name] && c.Status.Phase != corev1.ClaimBound {
+			pending = append(pending, fmt.Sprintf("pvc/%s (%s)", c.Name, c.Status.Phase))
+		}
+	}
+	return pending
+}
+
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+// runCheck runs one check against a restored namespace, retrying a few
+// times so a service that is ready but still warming up gets a chance
+func (v *verifier) runCheck(ctx context.Context, namespace string, check velero.VerifyCheck) CheckResult {
+	started := time.Now()
+	result := CheckResult{Name: check.Name}
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(10 * time.Second):
+			}
+		}
+		if check.HTTP != nil {
+			err = v.httpCheck(ctx, namespace, check.HTTP)
+		} else {
+			err = v.execCheck(ctx, namespace, check.Exec)
+		}
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	result.Duration = time.Since(started).Round(time.Millisecond)
+	result.Passed = err == nil
+	if err != nil {
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// httpCheck requests the path through the API server's service proxy, so
+// it works from outside the cluster network
+func (v *verifier) httpCheck(ctx context.Context, namespace string, check *velero.HTTPCheck) error {
+	var status int
+	res := v.clientset.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("services").
+		Name(fmt.Sprintf("%s:%d", check.Service, check.Port)).
+		SubResource("proxy").
+		Suffix(check.Path).
+		Do(ctx).
+		StatusCode(&status)
+	if status == 0 {
+		return fmt.Errorf("no response from %s:%d: %v", check.Service, check.Port, res.Error())
+	}
+	return checkStatus(status, check.ExpectStatus)
+}
+
+// checkStatus passes the expected status, or any 2xx when none is set
+func checkStatus(status, expect int) error {
+	if expect != 0 {
+		if status != expect {
+			return fmt.Errorf("HTTP %d, expected %d", status, expect)
+		}
+		return nil
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("HTTP %d", status)
+	}
+	return nil
+}
+
+// execCheck runs the command in a ready pod matching the selector
+func (v *verifier) execCheck(ctx context.Context, namespace string, check *velero.ExecCheck) error {
+	pods, err := v.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: check.Selector})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	pod := readyPod(pods.Items)
+	if pod == nil {
+		return fmt.Errorf("no ready pod matches %s", check.Selector)
+	}
+	container := check.Container
+	if container == "" {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	req := v.clientset.CoreV1().RESTClient().Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   check.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(v.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &output, Stderr: &output}); err != nil {
+		if msg := strings.TrimSpace(output.String()); msg != "" {
+			return fmt.Errorf("%v: %s", err, truncate(msg, 200))
+		}
+		return err
+	}
+	return nil
+}
+
+// readyPod returns the first running pod whose containers are all ready
+func readyPod(pods []corev1.Pod) *corev1.Pod {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				return pod
+			}
+		}
+	}
+	return nil
+}
+
+// remaining is the time left until the context's deadline
+func remaining(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return velero.DefaultVerifyTimeout
+}
+
+// printVerifyResult prints the outcome of one verification
+func printVerifyResult(out io.Writer, r *VerifyResult) {
+	status := "PASSED"
+	if !r.Passed {
+		status = "FAILED"
+	}
+	fmt.Fprintf(out, "\nVerification of backup %q: %s (%s)\n", r.Backup, status, r.Duration)
+	if len(r.Namespaces) > 0 {
+		fmt.Fprintln(out, strings.Repeat("-", 80))
+		fmt.Fprintf(out, "%-25s %-35s %-10s %-10s\n", "NAMESPACE", "RESTORED AS", "READY", "CHECKS")
+		for _, ns := range r.Namespaces {
+			ready := "no"
+			if ns.Ready {
+				ready = ns.ReadyAfter.String()
+			}
+			passed := 0
+			for _, check := range ns.Checks {
+				if check.Passed {
+					passed++
+				}
+			}
+			fmt.Fprintf(out, "%-25s %-35s %-10s %d/%d\n", truncate(ns.Namespace, 25), truncate(ns.RestoredAs, 35), ready, passed, len(ns.Checks))
+		}
+	}
+	for _, failure := range r.Failures() {
+		fmt.Fprintf(out, "  ✗ %s\n", failure)
+	}
+}
+
+// printVerifyHistory prints the recorded verifications, newest first
+func printVerifyHistory(out io.Writer, history []VerifyResult) {
+	fmt.Fprintln(out, "BACKUP VERIFICATIONS")
+	fmt.Fprintln(out, strings.Repeat("-", 90))
+	if len(history) == 0 {
+		fmt.Fprintln(out, "No verifications recorded")
+		return
+	}
+	fmt.Fprintf(out, "%-20s %-40s %-8s %-10s\n", "STARTED", "BACKUP", "RESULT", "DURATION")
+	for _, r := range history {
+		status := "passed"
+		if !r.Passed {
+			status = "failed"
+		}
+		fmt.Fprintf(out, "%-20s %-40s %-8s %-10s\n", formatTime(r.StartedAt), truncate(r.Backup, 40), status, r.Duration)
+	}
+}
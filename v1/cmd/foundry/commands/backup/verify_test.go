@@ -0,0 +1,286 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+)
+
+func TestRestoredNamespace(t *testing.T) {
+	assert.Equal(t, "app-verify-abc123", restoredNamespace("app", "abc123"))
+
+	long := restoredNamespace(strings.Repeat("a", 50)+"-b-cdef", "abc123")
+	assert.LessOrEqual(t, len(long), 63)
+	assert.True(t, strings.HasSuffix(long, "-verify-abc123"))
+	assert.NotContains(t, long, "--")
+}
+
+func TestLatestVerifiableBackup(t *testing.T) {
+	at := func(day int) *time.Time {
+		ts := time.Date(2025, 1, day, 2, 0, 0, 0, time.UTC)
+		return &ts
+	}
+	backups := []BackupInfo{
+		{Name: "daily-1", Status: "Completed", StartTimestamp: at(1), ScheduleName: "daily-backup"},
+		{Name: "daily-2", Status: "Completed", StartTimestamp: at(2), ScheduleName: "daily-backup"},
+		{Name: "manual", Status: "Completed", StartTimestamp: at(3)},
+		{Name: "local", Status: "Completed", StartTimestamp: at(4), StorageLocation: localBSLName},
+		{Name: "broken", Status: "PartiallyFailed", StartTimestamp: at(5)},
+	}
+
+	assert.Equal(t, "manual", latestVerifiableBackup(backups, "").Name)
+	assert.Equal(t, "daily-2", latestVerifiableBackup(backups, "daily-backup").Name)
+	assert.Nil(t, latestVerifiableBackup(backups, "weekly"))
+}
+
+func TestPendingWorkloads(t *testing.T) {
+	two := int32(2)
+	deployments := []appsv1.Deployment{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web"}, Spec: appsv1.DeploymentSpec{Replicas: &two}, Status: appsv1.DeploymentStatus{AvailableReplicas: 1}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "api"}, Status: appsv1.DeploymentStatus{AvailableReplicas: 1}},
+	}
+	statefulSets := []appsv1.StatefulSet{
+		{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Status: appsv1.StatefulSetStatus{ReadyReplicas: 0}},
+	}
+	daemonSets := []appsv1.DaemonSet{
+		{ObjectMeta: metav1.ObjectMeta{Name: "agent"}, Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3}},
+	}
+	claims := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "data-db-0"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unused"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+	}
+	pods := []corev1.Pod{{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+		Name:         "data",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"}},
+	}}}}}
+
+	pending := pendingWorkloads(deployments, statefulSets, daemonSets, claims, pods)
+	assert.Equal(t, []string{"deployment/web (1/2)", "statefulset/db (0/1)", "pvc/data-db-0 (Pending)"}, pending)
+}
+
+func TestCheckStatus(t *testing.T) {
+	assert.NoError(t, checkStatus(204, 0))
+	assert.EqualError(t, checkStatus(503, 0), "HTTP 503")
+	assert.NoError(t, checkStatus(401, 401))
+	assert.EqualError(t, checkStatus(200, 401), "HTTP 200, expected 401")
+}
+
+func TestBackupNamespaces(t *testing.T) {
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	v := &verifier{clientset: fake.NewSimpleClientset(
+		namespace("app", nil),
+		namespace("blog", nil),
+		namespace("kube-system", nil),
+		namespace("velero", nil),
+		namespace("projectcontour", nil),
+		namespace("monitoring", nil),
+		namespace("app-verify-abc", map[string]string{verifyLabel: "abc"}),
+	)}
+	ctx := context.Background()
+
+	all, err := v.backupNamespaces(ctx, &BackupInfo{ExcludedNamespaces: []string{"bl*"}}, infrastructureNamespaces)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app"}, all)
+
+	unskipped, err := v.backupNamespaces(ctx, &BackupInfo{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "blog", "monitoring", "projectcontour"}, unskipped)
+
+	listed, err := v.backupNamespaces(ctx, &BackupInfo{IncludedNamespaces: []string{"gone", "app"}}, infrastructureNamespaces)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "gone"}, listed)
+}
+
+func TestVerifySkipNamespaces(t *testing.T) {
+	stackConfig := &config.Config{Components: config.ComponentMap{
+		"contour": {Config: map[string]any{"namespace": "edge"}},
+		"grafana": {Config: map[string]any{"namespace": "grafana"}},
+		"velero":  {Config: map[string]any{}},
+	}}
+	skip := verifySkipNamespaces(stackConfig)
+	assert.Contains(t, skip, "projectcontour")
+	assert.Contains(t, skip, "edge")
+	assert.Len(t, skip, len(infrastructureNamespaces)+1)
+}
+
+// TestVerifyResourceModifiers_ReleaseVIP applies the modifier rules to the
+// Envoy Service as Foundry installs it and to a LoadBalancer Service: the
+// restored copies must not claim the live VIP or any other external address.
+func TestVerifyResourceModifiers_ReleaseVIP(t *testing.T) {
+	var modifiers struct {
+		Rules []struct {
+			Conditions struct {
+				GroupResource string `json:"groupResource"`
+				Matches       []struct {
+					Path  string `json:"path"`
+					Value string `json:"value"`
+				} `json:"matches"`
+			} `json:"conditions"`
+			MergePatches []struct {
+				PatchData string `json:"patchData"`
+			} `json:"mergePatches"`
+		} `json:"resourceModifierRules"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(verifyResourceModifiers), &modifiers))
+	require.NotEmpty(t, modifiers.Rules)
+
+	// restore applies the rules the way Velero does
+	restore := func(service string) map[string]interface{} {
+		doc := []byte(service)
+		for _, rule := range modifiers.Rules {
+			require.Equal(t, "services", rule.Conditions.GroupResource)
+			var obj map[string]interface{}
+			require.NoError(t, json.Unmarshal(doc, &obj))
+			matched := true
+			for _, match := range rule.Conditions.Matches {
+				path := strings.Split(strings.TrimPrefix(match.Path, "/"), "/")
+				value, _, _ := unstructured.NestedString(obj, path...)
+				matched = matched && value == match.Value
+			}
+			if !matched {
+				continue
+			}
+			for _, patch := range rule.MergePatches {
+				var err error
+				doc, err = jsonpatch.MergePatch(doc, []byte(patch.PatchData))
+				require.NoError(t, err)
+			}
+		}
+		var obj map[string]interface{}
+		require.NoError(t, json.Unmarshal(doc, &obj))
+		return obj
+	}
+
+	envoy := restore(`{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "contour-envoy"},
+		"spec": {"type": "ClusterIP", "externalIPs": ["10.0.0.100"], "ports": [{"name": "https", "port": 443}]}}`)
+	_, found, _ := unstructured.NestedFieldNoCopy(envoy, "spec", "externalIPs")
+	assert.False(t, found, "the restored Envoy Service still claims the VIP")
+	ports, _, _ := unstructured.NestedSlice(envoy, "spec", "ports")
+	assert.Len(t, ports, 1)
+
+	lb := restore(`{"apiVersion": "v1", "kind": "Service",
+		"metadata": {"name": "web", "annotations": {"kube-vip.io/loadbalancerIPs": "10.0.0.100", "team": "web"}},
+		"spec": {"type": "LoadBalancer", "loadBalancerIP": "10.0.0.100", "externalTrafficPolicy": "Local"}}`)
+	serviceType, _, _ := unstructured.NestedString(lb, "spec", "type")
+	assert.Equal(t, "ClusterIP", serviceType)
+	for _, field := range []string{"loadBalancerIP", "externalTrafficPolicy"} {
+		_, found, _ := unstructured.NestedFieldNoCopy(lb, "spec", field)
+		assert.False(t, found, field)
+	}
+	annotations, _, _ := unstructured.NestedStringMap(lb, "metadata", "annotations")
+	assert.Equal(t, map[string]string{"team": "web"}, annotations)
+
+	internal := restore(`{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "db"},
+		"spec": {"type": "ClusterIP", "ports": [{"port": 5432}]}}`)
+	serviceType, _, _ = unstructured.NestedString(internal, "spec", "type")
+	assert.Equal(t, "ClusterIP", serviceType)
+}
+
+func TestApplyVerifyModifiers(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: verifyModifiersConfigMap, Namespace: VeleroNamespace},
+		Data:       map[string]string{"rules.yaml": "version: v1\n"},
+	})
+	v := &verifier{clientset: clientset}
+	require.NoError(t, v.applyVerifyModifiers(context.Background()))
+
+	cm, err := clientset.CoreV1().ConfigMaps(VeleroNamespace).Get(context.Background(), verifyModifiersConfigMap, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"rules.yaml": verifyResourceModifiers}, cm.Data)
+}
+
+func TestVerifyResult_Failures(t *testing.T) {
+	result := &VerifyResult{Namespaces: []NamespaceResult{
+		{Namespace: "app", Ready: true, Checks: []CheckResult{
+			{Name: "web", Passed: true},
+			{Name: "db", Message: "exit code 2"},
+		}},
+		{Namespace: "blog", Pending: []string{"deployment/blog (0/1)"}},
+	}}
+	assert.Equal(t, []string{
+		"app: check db: exit code 2",
+		"blog: not ready: deployment/blog (0/1)",
+	}, result.Failures())
+}
+
+func TestVerifyHistory_RecordAndLoad(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ctx := context.Background()
+
+	history, err := loadVerifyHistory(ctx, clientset)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < verifyHistoryLength+2; i++ {
+		result := &VerifyResult{Backup: "b", StartedAt: start.Add(time.Duration(i) * time.Hour), Passed: i%2 == 0}
+		require.NoError(t, recordVerifyResult(ctx, clientset, result))
+	}
+
+	history, err = loadVerifyHistory(ctx, clientset)
+	require.NoError(t, err)
+	require.Len(t, history, verifyHistoryLength)
+	assert.Equal(t, start.Add(time.Duration(verifyHistoryLength+1)*time.Hour), history[0].StartedAt, "newest first")
+	assert.False(t, history[0].Passed)
+
+	assert.False(t, verifyDue(history, 7*24*time.Hour, history[0].StartedAt.Add(time.Hour)))
+	assert.True(t, verifyDue(history, 7*24*time.Hour, history[0].StartedAt.Add(7*24*time.Hour)))
+	assert.True(t, verifyDue(nil, 7*24*time.Hour, start))
+}
+
+func TestVerifyAlert(t *testing.T) {
+	now := time.Date(2025, 1, 8, 3, 0, 0, 0, time.UTC)
+	failed := &VerifyResult{Backup: "daily-1", StartedAt: now.Add(-10 * time.Minute), Error: "restore failed"}
+
+	alert := verifyAlert(failed, 7*24*time.Hour, now)
+	assert.Equal(t, verifyAlertName, alert.Labels["alertname"])
+	assert.NotContains(t, alert.Labels, "backup", "the labels stay the same across runs so a pass resolves the alert")
+	assert.Equal(t, now.Add(7*24*time.Hour+2*time.Hour), alert.EndsAt)
+	assert.Equal(t, "restore failed", alert.Annotations["description"])
+
+	passed := &VerifyResult{Backup: "daily-2", StartedAt: now, Passed: true}
+	assert.Equal(t, now, verifyAlert(passed, 7*24*time.Hour, now).EndsAt)
+}
+
+func TestVeleroClient_CreateRestoreWithNamespaceMapping(t *testing.T) {
+	client := newTestClient()
+	ctx := context.Background()
+	includeClusterResources := false
+
+	err := client.CreateRestore(ctx, "verify-r", "b", RestoreOptions{
+		IncludedNamespaces:      []string{"app"},
+		NamespaceMapping:        map[string]string{"app": "app-verify-x"},
+		ExcludedResources:       []string{"cronjobs.batch"},
+		IncludeClusterResources: &includeClusterResources,
+		ResourceModifier:        verifyModifiersConfigMap,
+	})
+	require.NoError(t, err)
+
+	restore, err := client.dynamicClient.Resource(restoreGVR).Namespace(VeleroNamespace).Get(ctx, "verify-r", metav1.GetOptions{})
+	require.NoError(t, err)
+	mapping, _, _ := unstructured.NestedStringMap(restore.Object, "spec", "namespaceMapping")
+	assert.Equal(t, map[string]string{"app": "app-verify-x"}, mapping)
+	excluded, _, _ := unstructured.NestedStringSlice(restore.Object, "spec", "excludedResources")
+	assert.Equal(t, []string{"cronjobs.batch"}, excluded)
+	include, found, _ := unstructured.NestedBool(restore.Object, "spec", "includeClusterResources")
+	assert.True(t, found)
+	assert.False(t, include)
+	modifier, _, _ := unstructured.NestedStringMap(restore.Object, "spec", "resourceModifier")
+	assert.Equal(t, map[string]string{"kind": "ConfigMap", "name": verifyModifiersConfigMap}, modifier)
+}
@@ -0,0 +1,234 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	metricscmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/metrics"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+)
+
+const (
+	// verifyResultsConfigMap holds the recorded verification results in
+	// the velero namespace
+	verifyResultsConfigMap = "foundry-backup-verify"
+	verifyResultsKey       = "results.json"
+
+	// verifyHistoryLength is how many results are kept
+	verifyHistoryLength = 20
+
+	// verifyAlertName is the alert the manager raises when a scheduled
+	// verification fails
+	verifyAlertName = "FoundryBackupVerificationFailed"
+
+	// monitoringNamespace is where Alertmanager runs
+	monitoringNamespace = "monitoring"
+)
+
+// ScheduleVerify verifies the newest backup whenever components.velero.verify
+// .schedule says one is due, until ctx is done. The manager runs it. The
+// stack config is re-read each time, so schedule changes apply without a
+// restart; the recorded results say when the last verification ran.
+func ScheduleVerify(ctx context.Context, configPath string, out io.Writer) {
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := verifyIfDue(ctx, configPath, out, time.Now()); err != nil {
+			fmt.Fprintf(out, "backup verification: %v\n", err)
+		}
+		timer.Reset(time.Hour)
+	}
+}
+
+// verifyIfDue runs a verification if the schedule says one is due
+func verifyIfDue(ctx context.Context, configPath string, out io.Writer, now time.Time) error {
+	stackConfig, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	verifyCfg, err := stackVerifyConfig(stackConfig)
+	if err != nil || verifyCfg == nil {
+		return err
+	}
+	interval, err := verifyCfg.Interval()
+	if err != nil || interval == 0 {
+		return err
+	}
+	timeout, err := verifyCfg.TimeoutDuration()
+	if err != nil {
+		return err
+	}
+
+	v, err := newVerifier()
+	if err != nil {
+		return err
+	}
+	history, err := loadVerifyHistory(ctx, v.clientset)
+	if err != nil {
+		return err
+	}
+	if !verifyDue(history, interval, now) {
+		return nil
+	}
+
+	var result *VerifyResult
+	backups, err := v.velero.ListBackups(ctx)
+	if err != nil {
+		return err
+	}
+	if latest := latestVerifiableBackup(backups, verifyCfg.BackupSchedule); latest != nil {
+		fmt.Fprintf(out, "backup verification: verifying %s\n", latest.Name)
+		result = v.Verify(ctx, verifyRun{
+			Backup:         latest.Name,
+			Namespaces:     verifyCfg.Namespaces,
+			SkipNamespaces: verifySkipNamespaces(stackConfig),
+			Checks:         verifyCfg,
+			Timeout:        timeout,
+			Out:            out,
+		})
+	} else {
+		// Having nothing to verify is as bad as a failed verification
+		result = &VerifyResult{
+			ID:        strconv.FormatInt(now.Unix(), 36),
+			StartedAt: now,
+			Error:     "no completed backup to verify",
+		}
+		if verifyCfg.BackupSchedule != "" {
+			result.Error = fmt.Sprintf("no completed backup from schedule %q to verify", verifyCfg.BackupSchedule)
+		}
+	}
+	if ctx.Err() != nil {
+		// The manager is shutting down; that says nothing about the backup
+		return nil
+	}
+
+	if err := recordVerifyResult(ctx, v.clientset, result); err != nil {
+		fmt.Fprintf(out, "backup verification: could not record the result: %v\n", err)
+	}
+	printVerifyResult(out, result)
+
+	// Resolve the alert once a verification passes again
+	previousFailed := len(history) > 0 && !history[0].Passed
+	if !result.Passed || previousFailed {
+		service, port, err := metricscmd.FindAlertmanager(ctx, v.clientset, monitoringNamespace)
+		if err != nil {
+			return fmt.Errorf("could not alert: %w", err)
+		}
+		alert := verifyAlert(result, interval, time.Now())
+		if err := metricscmd.PostAlerts(ctx, v.clientset.CoreV1().RESTClient(), monitoringNamespace, fmt.Sprintf("%s:%d", service, port), []metricscmd.PostableAlert{alert}); err != nil {
+			return fmt.Errorf("could not alert: %w", err)
+		}
+	}
+	return nil
+}
+
+// verifyDue reports whether the newest recorded verification is at least
+// an interval old
+func verifyDue(history []VerifyResult, interval time.Duration, now time.Time) bool {
+	return len(history) == 0 || now.Sub(history[0].StartedAt) >= interval
+}
+
+// loadVerifyHistory reads the recorded verification results, newest first
+func loadVerifyHistory(ctx context.Context, clientset kubernetes.Interface) ([]VerifyResult, error) {
+	cm, err := clientset.CoreV1().ConfigMaps(VeleroNamespace).Get(ctx, verifyResultsConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification results: %w", err)
+	}
+	var history []VerifyResult
+	if data := cm.Data[verifyResultsKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &history); err != nil {
+			return nil, fmt.Errorf("failed to parse verification results: %w", err)
+		}
+	}
+	return history, nil
+}
+
+// recordVerifyResult adds a result to the recorded ones, dropping the
+// oldest beyond verifyHistoryLength
+func recordVerifyResult(ctx context.Context, clientset kubernetes.Interface, result *VerifyResult) error {
+	history, err := loadVerifyHistory(ctx, clientset)
+	if err != nil {
+		return err
+	}
+	history = append([]VerifyResult{*result}, history...)
+	if len(history) > verifyHistoryLength {
+		history = history[:verifyHistoryLength]
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	configMaps := clientset.CoreV1().ConfigMaps(VeleroNamespace)
+	cm, err := configMaps.Get(ctx, verifyResultsConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      verifyResultsConfigMap,
+				Namespace: VeleroNamespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "foundry"},
+			},
+			Data: map[string]string{verifyResultsKey: string(data)},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[verifyResultsKey] = string(data)
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// verifyAlert is the alert for a scheduled verification. A failed one fires
+// until a little after the next verification is due, so it stays up if the
+// manager is down; a passed one is already resolved.
+func verifyAlert(result *VerifyResult, interval time.Duration, now time.Time) metricscmd.PostableAlert {
+	endsAt := now
+	if !result.Passed {
+		endsAt = now.Add(interval + 2*time.Hour)
+	}
+	summary := fmt.Sprintf("Backup %s failed restore verification", result.Backup)
+	if result.Backup == "" {
+		summary = "No backup could be verified"
+	}
+	description := "The latest backup restored and passed its checks."
+	if failures := result.Failures(); len(failures) > 0 {
+		description = strings.Join(failures, "\n")
+	}
+	return metricscmd.PostableAlert{
+		Labels: map[string]string{
+			"alertname": verifyAlertName,
+			"severity":  "warning",
+			"namespace": VeleroNamespace,
+		},
+		Annotations: map[string]string{
+			"summary":     summary,
+			"description": description,
+			"runbook":     "Run 'foundry backup verify --keep' to restore the backup into throwaway namespaces and inspect them.",
+		},
+		StartsAt: result.StartedAt,
+		EndsAt:   endsAt,
+	}
+}
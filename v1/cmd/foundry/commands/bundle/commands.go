@@ -0,0 +1,105 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	stackcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/stack"
+	"github.com/catalystcommunity/foundry/v1/internal/bundle"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/lockfile"
+	"github.com/urfave/cli/v3"
+)
+
+// Command is the top-level bundle command
+var Command = &cli.Command{
+	Name:  "bundle",
+	Usage: "Build offline installation bundles",
+	Description: `An offline bundle holds everything 'foundry stack install' downloads: the
+k3s binary and airgap images, every Helm chart at its pinned version, the
+container runtime tools, the Gateway API manifest and every container image.
+
+Build a bundle on a machine with internet access, copy it next to the
+stack config, and install with it:
+
+  foundry bundle create --output foundry-bundle.tar.gz
+  foundry stack install --bundle foundry-bundle.tar.gz
+
+Commands:
+  foundry bundle create    - Download everything the stack config installs`,
+	Commands: []*cli.Command{
+		createCommand,
+	},
+}
+
+var createCommand = &cli.Command{
+	Name:  "create",
+	Usage: "Download everything the stack config installs into one archive",
+	Description: `Reads the stack config to find the k3s version, the charts and chart values
+each component installs, and the images of the host services. Charts are
+rendered with those values to list the images they run, and every image is
+pulled for linux/amd64. When the stack has a lockfile, the locked chart
+versions and image digests are bundled.
+
+Images that are not found by rendering charts (for example ones your own
+workloads need) can be added with --image.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Path of the bundle to write",
+			Value:   "foundry-bundle.tar.gz",
+		},
+		&cli.StringSliceFlag{
+			Name:  "image",
+			Usage: "Extra image to include (can be specified multiple times)",
+		},
+	},
+	Action: runCreate,
+}
+
+func runCreate(ctx context.Context, cmd *cli.Command) error {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to find config: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	plan, err := stackcmd.ArtifactPlan(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+	for _, ref := range cmd.StringSlice("image") {
+		plan.Images = append(plan.Images, bundle.Image{Component: "extra", Ref: ref})
+	}
+
+	// A locked stack bundles exactly what it will install
+	lock, err := lockfile.LoadForConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		lock.Pin(plan)
+	}
+
+	out := cmd.Root().Writer
+	fmt.Fprintf(out, "Creating offline bundle for cluster %s...\n", cfg.Cluster.Name)
+	manifest, err := bundle.Create(ctx, plan, cmd.String("output"), out)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n✓ Bundle written to %s\n", cmd.String("output"))
+	fmt.Fprintf(out, "  k3s:    %s\n", manifest.K3s.Version)
+	fmt.Fprintf(out, "  Charts: %d\n", len(manifest.Charts))
+	fmt.Fprintf(out, "  Images: %d\n", len(manifest.Images))
+	fmt.Fprintf(out, "\nInstall with: foundry stack install --bundle %s\n", cmd.String("output"))
+	return nil
+}
@@ -0,0 +1,214 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/catalystcommunity/foundry/v1/internal/capacity"
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// NewCapacityCommand creates the cluster capacity command
+func NewCapacityCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "capacity",
+		Usage: "Show requested, allocatable and used resources per node and namespace",
+		Description: `Report CPU and memory requests, limits and usage against each node's
+allocatable resources, the same per namespace, and the size and usage of
+every PersistentVolumeClaim. Usage comes from Prometheus; without it only
+requests and limits are shown.
+
+With --fit, also report which nodes could schedule a pod with the given
+requests, and how many of them.
+
+Examples:
+  foundry cluster capacity
+  foundry cluster capacity --fit cpu=2,mem=4Gi
+  foundry cluster capacity --json`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "fit",
+				Usage: "Pod requests to place, e.g. cpu=2,mem=4Gi",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output the report as JSON",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runCapacity(ctx, cmd.String("fit"), cmd.Bool("json"))
+		},
+	}
+}
+
+// runCapacity collects and prints the capacity report
+func runCapacity(ctx context.Context, fit string, asJSON bool) error {
+	var shape *capacity.Shape
+	if fit != "" {
+		parsed, err := capacity.ParseShape(fit)
+		if err != nil {
+			return fmt.Errorf("invalid --fit: %w", err)
+		}
+		shape = &parsed
+	}
+
+	// Create OpenBAO resolver to get kubeconfig
+	resolver, err := secrets.NewOpenBAOResolver("", "")
+	if err != nil {
+		return fmt.Errorf("failed to create OpenBAO resolver: %w", err)
+	}
+
+	// Create K8s client from kubeconfig in OpenBAO
+	client, err := k8s.NewClientFromOpenBAO(ctx, resolver, "foundry-core/k3s/kubeconfig", "value")
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	report, err := capacity.Collect(ctx, client)
+	if err != nil {
+		return err
+	}
+	if shape != nil {
+		report.Fit = capacity.Fit(report.Nodes, *shape)
+	}
+
+	if asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	displayCapacity(os.Stdout, report)
+	return nil
+}
+
+// displayCapacity prints the capacity report as tables
+func displayCapacity(out io.Writer, report *capacity.Report) {
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(out, "Warning: %s\n", warning)
+	}
+	if len(report.Warnings) > 0 {
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintln(out, "Nodes")
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tPODS\tCPU REQUESTED\tCPU USED\tMEMORY REQUESTED\tMEMORY USED\tDISK")
+	for _, node := range report.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			node.Name,
+			nodeState(node),
+			node.Pods,
+			ofAllocatable(node.CPU.Requested, node.CPU.Allocatable, formatCPU),
+			usedOf(node.CPU.Used, node.CPU.Allocatable, formatCPU),
+			ofAllocatable(node.Memory.Requested, node.Memory.Allocatable, format.Bytes),
+			usedOf(node.Memory.Used, node.Memory.Allocatable, format.Bytes),
+			formatDisk(node.Disk),
+		)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Namespaces")
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tPODS\tCPU REQ\tCPU LIMIT\tCPU USED\tMEM REQ\tMEM LIMIT\tMEM USED")
+	for _, ns := range report.Namespaces {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			ns.Name,
+			ns.Pods,
+			formatCPU(ns.CPU.Requested),
+			formatCPU(ns.CPU.Limits),
+			usedOf(ns.CPU.Used, 0, formatCPU),
+			format.Bytes(ns.Memory.Requested),
+			format.Bytes(ns.Memory.Limits),
+			usedOf(ns.Memory.Used, 0, format.Bytes),
+		)
+	}
+	w.Flush()
+
+	if len(report.Volumes) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Volumes")
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAMESPACE\tCLAIM\tSTORAGE CLASS\tUSED / SIZE")
+		for _, volume := range report.Volumes {
+			storageClass := volume.StorageClass
+			if storageClass == "" {
+				storageClass = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", volume.Namespace, volume.Name, storageClass, usedOf(volume.UsedBytes, volume.SizeBytes, format.Bytes))
+		}
+		w.Flush()
+	}
+
+	if report.Fit != nil {
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Fit for %s\n", report.Fit.Shape)
+		fits := 0
+		for _, node := range report.Fit.Nodes {
+			if node.Fits {
+				fits++
+				fmt.Fprintf(out, "  ✓ %s: room for %d\n", node.Node, node.Count)
+			} else {
+				fmt.Fprintf(out, "  ✗ %s: %s\n", node.Node, node.Reason)
+			}
+		}
+		fmt.Fprintf(out, "%d of %d node(s) can schedule the pod\n", fits, len(report.Fit.Nodes))
+	}
+}
+
+func nodeState(node capacity.Node) string {
+	switch {
+	case !node.Ready:
+		return "NotReady"
+	case !node.Schedulable:
+		return "Cordoned"
+	default:
+		return "Ready"
+	}
+}
+
+// ofAllocatable renders "requested / allocatable (percent)"
+func ofAllocatable(value, allocatable int64, format func(int64) string) string {
+	if allocatable <= 0 {
+		return format(value)
+	}
+	return fmt.Sprintf("%s / %s (%d%%)", format(value), format(allocatable), value*100/allocatable)
+}
+
+// usedOf renders a usage, with its share of the total when there is one,
+// or "-" when it is unknown
+func usedOf(used *int64, total int64, format func(int64) string) string {
+	if used == nil {
+		if total > 0 {
+			return "- / " + format(total)
+		}
+		return "-"
+	}
+	if total <= 0 {
+		return format(*used)
+	}
+	return ofAllocatable(*used, total, format)
+}
+
+func formatDisk(disk *capacity.Disk) string {
+	if disk == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", ofAllocatable(disk.UsedBytes, disk.SizeBytes, format.Bytes), disk.Source)
+}
+
+// formatCPU renders millicores as cores
+func formatCPU(millicores int64) string {
+	return fmt.Sprintf("%.2f", float64(millicores)/1000)
+}
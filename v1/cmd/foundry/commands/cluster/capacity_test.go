@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/capacity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCapacityCommand(t *testing.T) {
+	cmd := NewCapacityCommand()
+
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "capacity", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestRunCapacityRejectsInvalidShape(t *testing.T) {
+	err := runCapacity(context.Background(), "gpu=1", false)
+	assert.ErrorContains(t, err, "invalid --fit")
+}
+
+func TestDisplayCapacity(t *testing.T) {
+	cpuUsed, memUsed, volumeUsed := int64(500), int64(1<<30), int64(2<<30)
+	report := &capacity.Report{
+		Nodes: []capacity.Node{
+			{
+				Name: "node-a", Ready: true, Schedulable: true, Pods: 12,
+				CPU:    capacity.Resources{Allocatable: 4000, Requested: 1000, Used: &cpuUsed},
+				Memory: capacity.Resources{Allocatable: 8 << 30, Requested: 2 << 30, Used: &memUsed},
+				Disk:   &capacity.Disk{Source: "longhorn", UsedBytes: 25 << 30, SizeBytes: 100 << 30},
+			},
+			{
+				Name: "node-b", Ready: true, Pods: 0,
+				CPU:    capacity.Resources{Allocatable: 2000},
+				Memory: capacity.Resources{Allocatable: 4 << 30},
+			},
+		},
+		Namespaces: []capacity.Namespace{
+			{Name: "apps", Pods: 12, CPU: capacity.Resources{Requested: 1000, Limits: 2000, Used: &cpuUsed}, Memory: capacity.Resources{Requested: 2 << 30}},
+		},
+		Volumes: []capacity.Volume{
+			{Namespace: "apps", Name: "data", StorageClass: "longhorn", SizeBytes: 10 << 30, UsedBytes: &volumeUsed},
+			{Namespace: "apps", Name: "scratch", SizeBytes: 1 << 30},
+		},
+		Fit: &capacity.FitReport{
+			Shape: capacity.Shape{CPU: 2000, Memory: 4 << 30},
+			Nodes: []capacity.NodeFit{
+				{Node: "node-a", Fits: true, Count: 1},
+				{Node: "node-b", Reason: "node is cordoned"},
+			},
+		},
+		Warnings: []string{"Cannot read usage from Prometheus: timeout"},
+	}
+
+	var out bytes.Buffer
+	displayCapacity(&out, report)
+	text := out.String()
+
+	assert.Contains(t, text, "Warning: Cannot read usage from Prometheus: timeout")
+	assert.Contains(t, text, "1.00 / 4.00 (25%)")
+	assert.Contains(t, text, "0.50 / 4.00 (12%)")
+	assert.Contains(t, text, "2.0 GiB / 8.0 GiB (25%)")
+	assert.Contains(t, text, "25.0 GiB / 100.0 GiB (25%) (longhorn)")
+	assert.Contains(t, text, "Cordoned")
+	assert.Contains(t, text, "2.0 GiB / 10.0 GiB (20%)")
+	assert.Contains(t, text, "- / 1.0 GiB")
+	assert.Contains(t, text, "Fit for cpu=2,mem=4Gi")
+	assert.Contains(t, text, "✓ node-a: room for 1")
+	assert.Contains(t, text, "✗ node-b: node is cordoned")
+	assert.Contains(t, text, "1 of 2 node(s) can schedule the pod")
+}
@@ -13,6 +13,7 @@ func Commands() *cli.Command {
 			initCommand(),
 			nodeCommands(),
 			NewStatusCommand(),
+			NewCapacityCommand(),
 		},
 	}
 }
@@ -9,8 +9,11 @@ import (
 
 	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
 	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
 	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/lockfile"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
 	"github.com/catalystcommunity/foundry/v1/internal/setup"
 	"github.com/catalystcommunity/foundry/v1/internal/ssh"
 	"github.com/urfave/cli/v3"
@@ -98,7 +101,15 @@ func runClusterInit(ctx context.Context, cmd *cli.Command) error {
 
 	// Initialize the cluster
 	fmt.Println("Initializing Kubernetes cluster...")
-	if err := InitializeCluster(ctx, cfg); err != nil {
+	lock, err := lockfile.LoadForConfig(configPath)
+	if err != nil {
+		return err
+	}
+	var imageDigests map[string]string
+	if lock != nil {
+		imageDigests = lock.ImageDigests()
+	}
+	if err := InitializeCluster(ctx, cfg, imageDigests); err != nil {
 		return fmt.Errorf("cluster initialization failed: %w", err)
 	}
 
@@ -200,7 +211,9 @@ func connectToHost(hostname string) (*ssh.Connection, error) {
 
 // InitializeCluster initializes a Kubernetes cluster with the given configuration.
 // This function is exported so it can be called from other commands like stack install.
-func InitializeCluster(ctx context.Context, cfg *config.Config) error {
+// InitializeCluster installs K3s on every cluster host. imageDigests pins the
+// kube-vip images to the digests in the stack lockfile and may be nil.
+func InitializeCluster(ctx context.Context, cfg *config.Config, imageDigests map[string]string) error {
 	// Step 1: Load OpenBAO credentials
 	fmt.Println("Loading OpenBAO credentials...")
 
@@ -284,12 +297,16 @@ func InitializeCluster(ctx context.Context, cfg *config.Config) error {
 		},
 		DisableComponents: []string{"traefik", "servicelb"},
 		AllowCGNATVIP:     cfg.Cluster.AllowCGNATVIP,
+		ImageDigests:      imageDigests,
 	}
 
 	// Parse additional registries and etcd args from component config
 	if k3sCompCfg, exists := cfg.Components["k3s"]; exists {
 		k3sConfig.AdditionalRegistries = k3s.ParseAdditionalRegistries(k3sCompCfg.Config)
 
+		// Offline installs stage k3s on every node before the cluster is initialized
+		k3sConfig.Airgap, _ = k3sCompCfg.Config["airgap"].(bool)
+
 		// Parse etcd_args for tuning (especially important for virtualized environments)
 		if etcdArgsRaw, ok := k3sCompCfg.Config["etcd_args"]; ok {
 			if etcdArgs, ok := etcdArgsRaw.([]interface{}); ok {
@@ -303,8 +320,11 @@ func InitializeCluster(ctx context.Context, cfg *config.Config) error {
 	}
 
 	// Add registry config if Zot is configured
-	if zotAddr, err := cfg.GetPrimaryZotAddress(); err == nil {
-		k3sConfig.RegistryConfig = k3s.GenerateRegistriesConfig(zotAddr, k3sConfig.AdditionalRegistries)
+	if _, err := cfg.GetPrimaryZotAddress(); err == nil {
+		k3sConfig.RegistryConfig, err = registriesConfig(ctx, cfg, openbaoClient, k3sConfig.AdditionalRegistries)
+		if err != nil {
+			return fmt.Errorf("failed to build registry config: %w", err)
+		}
 	}
 
 	// Install control plane
@@ -346,6 +366,7 @@ func InitializeCluster(ctx context.Context, cfg *config.Config) error {
 			RegistryConfig:    k3sConfig.RegistryConfig,
 			EtcdArgs:          k3sConfig.EtcdArgs,
 			AllowCGNATVIP:     k3sConfig.AllowCGNATVIP,
+			Airgap:            k3sConfig.Airgap,
 		}
 
 		// Join control plane
@@ -381,6 +402,7 @@ func InitializeCluster(ctx context.Context, cfg *config.Config) error {
 			ServerURL:      serverURL,
 			AgentToken:     tokens.AgentToken,
 			RegistryConfig: k3sConfig.RegistryConfig,
+			Airgap:         k3sConfig.Airgap,
 		}
 
 		// Join worker
@@ -431,6 +453,25 @@ func InitializeCluster(ctx context.Context, cfg *config.Config) error {
 	return nil
 }
 
+// registriesConfig renders registries.yaml for joining nodes, with the
+// cluster's registry account when Zot requires authentication. Nodes start on
+// plain HTTP; a registry sync moves them to HTTPS once Zot has a certificate.
+func registriesConfig(ctx context.Context, cfg *config.Config, store registry.SecretStore, additional []k3s.AdditionalRegistry) (string, error) {
+	var users []zot.User
+	if registry.AuthEnabled(cfg) {
+		var err error
+		users, err = registry.LoadUsers(ctx, store)
+		if err != nil {
+			return "", err
+		}
+	}
+	reg, err := registry.NodeRegistry(cfg, users, false, nil)
+	if err != nil {
+		return "", err
+	}
+	return k3s.GenerateZotRegistriesConfig(reg, additional), nil
+}
+
 // exportKubeconfigToLocalFilesystem exports the kubeconfig from OpenBAO to ~/.foundry/kubeconfig
 func exportKubeconfigToLocalFilesystem(ctx context.Context, openbaoClient *openbao.Client) error {
 	// Load kubeconfig from OpenBAO
@@ -363,7 +363,7 @@ func TestInitializeCluster_HostNotFound(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	err := InitializeCluster(ctx, cfg)
+	err := InitializeCluster(ctx, cfg, nil)
 
 	// Error should occur - either when loading OpenBAO keys, connecting to OpenBAO, or connecting to host
 	assert.Error(t, err)
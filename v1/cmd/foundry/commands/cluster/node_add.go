@@ -7,11 +7,15 @@ import (
 	"strings"
 	"time"
 
+	registrycmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/registry"
+	"github.com/catalystcommunity/foundry/v1/internal/bundle"
 	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
 	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/component/statushelpers"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
 	"github.com/catalystcommunity/foundry/v1/internal/host"
 	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
 	"github.com/catalystcommunity/foundry/v1/internal/secrets"
 	"github.com/urfave/cli/v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,6 +42,10 @@ func NewNodeAddCommand() *cli.Command {
 				Name:  "labels",
 				Usage: "Node labels in key=value format (can be specified multiple times)",
 			},
+			&cli.StringFlag{
+				Name:  "bundle",
+				Usage: "Offline bundle to install k3s from (required when the stack was installed from one)",
+			},
 		},
 		Action: runNodeAdd,
 	}
@@ -96,7 +104,7 @@ func runNodeAdd(ctx context.Context, cmd *cli.Command) error {
 
 	// Add node to cluster
 	fmt.Printf("Adding node %s to cluster %s...\n", hostname, cfg.Cluster.Name)
-	if err := addNodeToCluster(ctx, hostname, nodeRole, cfg); err != nil {
+	if err := addNodeToCluster(ctx, hostname, nodeRole, cfg, cmd.String("bundle")); err != nil {
 		return fmt.Errorf("failed to add node: %w", err)
 	}
 
@@ -220,7 +228,7 @@ func printNodeAddPlan(hostname string, role *k3s.DeterminedRole, cfg *config.Con
 }
 
 // addNodeToCluster performs the actual node addition
-func addNodeToCluster(ctx context.Context, hostname string, nodeRole *k3s.DeterminedRole, cfg *config.Config) error {
+func addNodeToCluster(ctx context.Context, hostname string, nodeRole *k3s.DeterminedRole, cfg *config.Config, bundlePath string) error {
 	// Step 1: Get OpenBAO client
 	fmt.Println("Connecting to OpenBAO...")
 	openbaoAddr, err := cfg.GetPrimaryOpenBAOURL()
@@ -273,11 +281,38 @@ func addNodeToCluster(ctx context.Context, hostname string, nodeRole *k3s.Determ
 	// Parse additional registries from component config
 	if k3sCompCfg, exists := cfg.Components["k3s"]; exists {
 		k3sConfig.AdditionalRegistries = k3s.ParseAdditionalRegistries(k3sCompCfg.Config)
+		k3sConfig.Airgap, _ = k3sCompCfg.Config["airgap"].(bool)
+	}
+
+	// An offline cluster cannot download k3s, so stage it from the bundle
+	if k3sConfig.Airgap {
+		if bundlePath == "" {
+			return fmt.Errorf("the cluster was installed from an offline bundle; pass --bundle to install k3s on %s", hostname)
+		}
+		b, err := bundle.Open(bundlePath)
+		if err != nil {
+			return err
+		}
+		defer b.Close()
+		fmt.Printf("Staging k3s %s from the offline bundle...\n", b.Manifest.K3s.Version)
+		if err := b.StageK3s(conn); err != nil {
+			return fmt.Errorf("failed to stage k3s: %w", err)
+		}
 	}
 
-	// Add registry config if Zot is configured
-	if zotAddr, err := cfg.GetPrimaryZotAddress(); err == nil {
-		k3sConfig.RegistryConfig = k3s.GenerateRegistriesConfig(zotAddr, k3sConfig.AdditionalRegistries)
+	// Add registry config if Zot is configured. Unlike at cluster init, Zot
+	// may already serve HTTPS, so the node gets the same access as the others.
+	if _, err := cfg.GetPrimaryZotAddress(); err == nil {
+		reg, caPEM, err := registrycmd.NodeRegistry(ctx, cfg, configDir)
+		if err != nil {
+			return fmt.Errorf("failed to build registry config: %w", err)
+		}
+		if len(caPEM) > 0 {
+			if err := registry.InstallCA(&statushelpers.SSHExecutorAdapter{Conn: conn}, caPEM); err != nil {
+				return err
+			}
+		}
+		k3sConfig.RegistryConfig = k3s.GenerateZotRegistriesConfig(reg, k3sConfig.AdditionalRegistries)
 	}
 
 	// Step 5: Join node based on role
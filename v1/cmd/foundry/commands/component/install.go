@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 
 	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/alloy"
+	"github.com/catalystcommunity/foundry/v1/internal/component/blackbox"
 	"github.com/catalystcommunity/foundry/v1/internal/component/certmanager"
 	"github.com/catalystcommunity/foundry/v1/internal/component/contour"
 	"github.com/catalystcommunity/foundry/v1/internal/component/dns"
@@ -17,18 +19,23 @@ import (
 	"github.com/catalystcommunity/foundry/v1/internal/component/gatewayapi"
 	"github.com/catalystcommunity/foundry/v1/internal/component/gatewaycontroller"
 	"github.com/catalystcommunity/foundry/v1/internal/component/grafana"
+	"github.com/catalystcommunity/foundry/v1/internal/component/hostlogs"
 	"github.com/catalystcommunity/foundry/v1/internal/component/loki"
 	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
 	"github.com/catalystcommunity/foundry/v1/internal/component/openbaoinjector"
 	"github.com/catalystcommunity/foundry/v1/internal/component/prometheus"
 	"github.com/catalystcommunity/foundry/v1/internal/component/seaweedfs"
 	componentStorage "github.com/catalystcommunity/foundry/v1/internal/component/storage"
+	"github.com/catalystcommunity/foundry/v1/internal/component/tempo"
 	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
 	"github.com/catalystcommunity/foundry/v1/internal/dashboards"
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
 	"github.com/catalystcommunity/foundry/v1/internal/helm"
 	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
 	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/lockfile"
 	"github.com/catalystcommunity/foundry/v1/internal/secrets"
 	"github.com/catalystcommunity/foundry/v1/internal/ssh"
 	"github.com/urfave/cli/v3"
@@ -87,7 +94,7 @@ Examples:
 		// Storage-specific flags
 		&cli.StringFlag{
 			Name:  "backend",
-			Usage: "Storage backend: local-path, nfs, longhorn (for storage component)",
+			Usage: "Storage backend: local-path, nfs, longhorn, truenas (for storage component)",
 			Value: "local-path",
 		},
 		&cli.StringFlag{
@@ -112,9 +119,12 @@ var k8sComponents = map[string]bool{
 	"seaweedfs":          true,
 	"prometheus":         true,
 	"loki":               true,
+	"tempo":              true,
+	"alloy":              true,
 	"grafana":            true,
 	"external-dns":       true,
 	"velero":             true,
+	"blackbox-exporter":  true,
 	"openbao-injector":   true,
 }
 
@@ -167,10 +177,148 @@ func runInstall(ctx context.Context, cmd *cli.Command) error {
 		return installK8sComponent(ctx, cmd, name, stackConfig, dryRun, version)
 	}
 
+	// node_exporter and the log agent run on every host
+	if name == "node-exporter" || name == "host-logs" {
+		return installOnHosts(ctx, cmd, comp, stackConfig, dryRun, version)
+	}
+
 	// These components run directly on infrastructure hosts.
 	return installSSHComponent(ctx, cmd, name, stackConfig, dryRun, version)
 }
 
+// installOnHosts installs a component that runs on every host in the stack
+// config and records it, so later installs know it is there
+func installOnHosts(ctx context.Context, cmd *cli.Command, comp component.Component, stackConfig *config.Config, dryRun bool, version string) error {
+	name := comp.Name()
+	if dryRun {
+		fmt.Printf("\nWould install %s on %d hosts\n", name, len(stackConfig.Hosts))
+		fmt.Println("\nNote: This is a dry-run. No changes will be made.")
+		return nil
+	}
+
+	lock, err := loadLock(cmd)
+	if err != nil {
+		return err
+	}
+
+	defaults := component.ComponentConfig{}
+	if name == "host-logs" {
+		if defaults, err = hostLogsSettings(ctx, stackConfig); err != nil {
+			return err
+		}
+	}
+
+	compCfg := stackConfig.Components[name]
+	if compCfg.Config == nil {
+		compCfg.Config = map[string]any{}
+	}
+
+	for _, h := range stackConfig.Hosts {
+		fmt.Printf("Installing %s on %s (%s)...\n", name, h.Hostname, h.Address)
+		conn, err := connectToHost(h, stackConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", h.Hostname, err)
+		}
+
+		cfg := component.ComponentConfig{}
+		for k, v := range defaults {
+			cfg[k] = v
+		}
+		for k, v := range compCfg.Config {
+			if k != "installed" {
+				cfg[k] = v
+			}
+		}
+		if name == "host-logs" {
+			cfg["labels"] = hostLogLabels(h, cfg)
+		}
+		cfg["host"] = &sshExecutorAdapter{conn: conn}
+		if version != "" {
+			cfg["version"] = version
+		}
+		if lock != nil {
+			cfg["image_digests"] = lock.ImageDigests()
+		}
+
+		err = comp.Install(ctx, cfg)
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("failed to install %s on %s: %w", name, h.Hostname, err)
+		}
+		fmt.Printf("✓ %s\n", h.Hostname)
+	}
+
+	if stackConfig.Components == nil {
+		stackConfig.Components = make(config.ComponentMap)
+	}
+	compCfg.Config["installed"] = true
+	stackConfig.Components[name] = compCfg
+
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to find config path: %w", err)
+	}
+	if err := config.Save(stackConfig, configPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if name == "node-exporter" {
+		fmt.Println("\n✓ node-exporter installed; run 'foundry component install prometheus' to scrape it")
+	} else {
+		fmt.Printf("\n✓ %s installed on %d hosts\n", name, len(stackConfig.Hosts))
+	}
+	return nil
+}
+
+// hostLogsSettings returns the settings every host's log agent shares,
+// including the internal CA that signed Loki's ingress certificate
+func hostLogsSettings(ctx context.Context, stackConfig *config.Config) (component.ComponentConfig, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	ca, err := hosttls.LoadClusterCA(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := hostlogs.StackSettings(stackConfig)
+	settings["ca_cert"] = string(ca.CertPEM())
+	return settings, nil
+}
+
+// hostLogLabels merges a host's host and role labels into the labels
+// configured under components.host-logs
+func hostLogLabels(h *host.Host, cfg component.ComponentConfig) map[string]interface{} {
+	labels := map[string]interface{}{}
+	if configured, ok := cfg.GetMap("labels"); ok {
+		for k, v := range configured {
+			labels[k] = v
+		}
+	}
+	for k, v := range hostlogs.HostLabels(h) {
+		labels[k] = v
+	}
+	return labels
+}
+
+// loadLock reads the stack lockfile next to the config, if there is one
+func loadLock(cmd *cli.Command) (*lockfile.File, error) {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config: %w", err)
+	}
+	return lockfile.LoadForConfig(configPath)
+}
+
 // installK8sComponent installs a Kubernetes-based component using kubeconfig
 func installK8sComponent(ctx context.Context, cmd *cli.Command, name string, stackConfig *config.Config, dryRun bool, version string) error {
 	fmt.Printf("Installing Kubernetes component: %s\n", name)
@@ -202,6 +350,13 @@ func installK8sComponent(ctx context.Context, cmd *cli.Command, name string, sta
 	if err != nil {
 		return fmt.Errorf("failed to create helm client: %w", err)
 	}
+	lock, err := loadLock(cmd)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		helmClient.UseLock(lock.HelmLock())
+	}
 
 	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
 	if err != nil {
@@ -245,15 +400,56 @@ func installK8sComponent(ctx context.Context, cmd *cli.Command, name string, sta
 				"path":   nfsPath,
 			}
 		}
+		if backend == string(componentStorage.BackendTrueNAS) {
+			if stackConfig.Storage == nil || stackConfig.Storage.TrueNAS == nil {
+				return fmt.Errorf("truenas backend requires storage.truenas (api_url, pool) in the stack config")
+			}
+			resolver, resCtx, rerr := buildSecretResolver(stackConfig)
+			if rerr != nil {
+				return fmt.Errorf("failed to build secret resolver: %w", rerr)
+			}
+			resolved, err := secrets.ResolveRefs(componentStorage.TrueNASComponentConfig(stackConfig.Storage.TrueNAS), resolver, resCtx)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the TrueNAS API key: %w\n\nHint: Store api_key at %s in OpenBAO", err, componentStorage.TrueNASSecretPath)
+			}
+			cfg["truenas"] = resolved
+		}
 		componentWithClients = componentStorage.NewComponent(helmClient, k8sClient)
 	case "seaweedfs":
 		componentWithClients = seaweedfs.NewComponent(helmClient, k8sClient)
 	case "prometheus":
 		// Auto-populate external targets for infrastructure services
-		externalTargets := buildExternalTargetsFromStackConfig(stackConfig)
+		externalTargets := buildExternalTargetsFromStackConfig(ctx, stackConfig)
 		if len(externalTargets) > 0 {
 			cfg["external_targets"] = externalTargets
 		}
+		// Built-in alert rules, the hosts they watch and where alerts go
+		if promCfg, ok := stackConfig.Components["prometheus"]; ok && promCfg.Config != nil {
+			for _, key := range []string{"alert_rules_enabled", "alert_rules", "alertmanager_enabled", "alerting"} {
+				if v, ok := promCfg.Config[key]; ok {
+					cfg[key] = v
+				}
+			}
+		}
+		// Resolve ${secret:...} references in the Alertmanager receivers into a
+		// copy, so the tokens reach Alertmanager but never the stack config
+		if alerting, ok := cfg["alerting"]; ok {
+			resolver, resCtx, rerr := buildSecretResolver(stackConfig)
+			if rerr != nil {
+				return fmt.Errorf("failed to set up secret resolver for alerting: %w", rerr)
+			}
+			resolved, err := secrets.ResolveRefs(alerting, resolver, resCtx)
+			if err != nil {
+				return fmt.Errorf("failed to resolve alerting secrets: %w", err)
+			}
+			cfg["alerting"] = resolved
+		}
+		if addr, err := stackConfig.GetPrimaryZotAddress(); err == nil {
+			cfg["zot_host"] = addr
+		}
+		if addrs := stackConfig.GetHostAddresses(host.RoleClusterControlPlane); len(addrs) > 0 {
+			cfg["etcd_endpoints"] = addrs
+		}
 		componentWithClients = prometheus.NewComponent(helmClient, k8sClient)
 	case "loki":
 		// Get SeaweedFS credentials (from stack config, falling back to a k8s secret)
@@ -267,6 +463,45 @@ func installK8sComponent(ctx context.Context, cmd *cli.Command, name string, sta
 		cfg["s3_bucket"] = "loki"
 		cfg["s3_region"] = "us-east-1"
 		componentWithClients = loki.NewComponent(helmClient, k8sClient)
+	case "tempo":
+		// Honor components.tempo from the stack config (gateway_enabled, retention, …)
+		if comp, ok := stackConfig.Components["tempo"]; ok {
+			for k, v := range comp.Config {
+				if _, present := cfg[k]; !present {
+					cfg[k] = v
+				}
+			}
+		}
+		seaweedfsKey, seaweedfsSecret, err := getSeaweedFSCredentials(stackConfig, k8sClient)
+		if err != nil {
+			return fmt.Errorf("failed to get SeaweedFS credentials: %w", err)
+		}
+		cfg["s3_endpoint"] = "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333"
+		cfg["s3_access_key"] = seaweedfsKey
+		cfg["s3_secret_key"] = seaweedfsSecret
+		cfg["s3_bucket"] = "tempo"
+		cfg["s3_region"] = "us-east-1"
+		componentWithClients = tempo.NewComponent(helmClient, k8sClient)
+	case "alloy":
+		// Honor components.alloy from the stack config (otlp_enabled, events_enabled, …)
+		if comp, ok := stackConfig.Components["alloy"]; ok {
+			for k, v := range comp.Config {
+				if _, present := cfg[k]; !present {
+					cfg[k] = v
+				}
+			}
+		}
+		// Forward to the backends that are installed
+		if !hasNonEmptyString(cfg, "loki_url") {
+			cfg["loki_url"] = "http://loki-gateway.monitoring.svc.cluster.local:80/loki/api/v1/push"
+		}
+		if !hasNonEmptyString(cfg, "prometheus_remote_write_url") && isDependencyInstalled("prometheus", stackConfig) {
+			cfg["prometheus_remote_write_url"] = "http://kube-prometheus-stack-prometheus.monitoring.svc.cluster.local:9090/api/v1/write"
+		}
+		if !hasNonEmptyString(cfg, "tempo_endpoint") && isDependencyInstalled("tempo", stackConfig) {
+			cfg["tempo_endpoint"] = "tempo.monitoring.svc.cluster.local:4317"
+		}
+		componentWithClients = alloy.NewComponent(helmClient, k8sClient)
 	case "grafana":
 		// Honor the grafana settings from the stack config (values, ingress,
 		// alerting contact points, etc.) so this path doesn't fall back to bare
@@ -285,6 +520,9 @@ func installK8sComponent(ctx context.Context, cmd *cli.Command, name string, sta
 		if !hasNonEmptyString(cfg, "loki_url") {
 			cfg["loki_url"] = "http://loki-gateway.monitoring.svc.cluster.local:80"
 		}
+		if !hasNonEmptyString(cfg, "tempo_url") && isDependencyInstalled("tempo", stackConfig) {
+			cfg["tempo_url"] = "http://tempo.monitoring.svc.cluster.local:3200"
+		}
 		// Resolve ${secret:...} references in alerting contact points (e.g. an
 		// Opsgenie API key stored in OpenBAO) so the real value reaches Grafana and
 		// is never persisted in the stack config.
@@ -348,7 +586,44 @@ func installK8sComponent(ctx context.Context, cmd *cli.Command, name string, sta
 			cfg["s3_access_key"] = seaweedfsKey
 			cfg["s3_secret_key"] = seaweedfsSecret
 		}
+		// Off-site credentials default to OpenBAO; resolve a copy so they
+		// never land in the stack config
+		if offsite, ok := cfg["offsite"].(map[string]interface{}); ok {
+			withRefs := make(map[string]interface{}, len(offsite))
+			for k, v := range offsite {
+				withRefs[k] = v
+			}
+			velero.DefaultOffsiteRefs(withRefs)
+			resolver, resCtx, rerr := buildSecretResolver(stackConfig)
+			if rerr != nil {
+				return fmt.Errorf("failed to build secret resolver: %w", rerr)
+			}
+			resolved, err := secrets.ResolveRefs(withRefs, resolver, resCtx)
+			if err != nil {
+				return fmt.Errorf("failed to resolve off-site backup credentials: %w\n\nHint: Store access_key, secret_key and encryption_password at %s in OpenBAO", err, velero.OffsiteSecretPath)
+			}
+			cfg["offsite"] = resolved
+		}
 		componentWithClients = velero.NewComponent(helmClient, k8sClient)
+	case "blackbox-exporter":
+		// Honor components.blackbox-exporter from the stack config (interval, targets, …)
+		if comp, ok := stackConfig.Components["blackbox-exporter"]; ok {
+			for k, v := range comp.Config {
+				if _, present := cfg[k]; !present {
+					cfg[k] = v
+				}
+			}
+		}
+		// Probe the service links the stack config declares as well as the
+		// routes discovered in the cluster
+		if _, present := cfg["service_links"]; !present {
+			links := map[string]string{}
+			for _, link := range discovery.ConfiguredLinks(stackConfig) {
+				links[link.URL] = link.Name
+			}
+			cfg["service_links"] = links
+		}
+		componentWithClients = blackbox.NewComponent(helmClient, k8sClient)
 	case "openbao-injector":
 		// Inject the OpenBao address so the webhook knows where to reach it
 		url, err := stackConfig.GetPrimaryOpenBAOURL()
@@ -473,6 +748,23 @@ func getSeaweedFSCredentials(stackConfig *config.Config, k8sClient *k8s.Client)
 		if swfs, ok := stackConfig.Components["seaweedfs"]; ok {
 			accessKey, _ := swfs.Config["access_key"].(string)
 			secretKey, _ := swfs.Config["secret_key"].(string)
+			// The stack install stores the keys in OpenBAO and leaves
+			// references to them in the config.
+			if secrets.IsSecretRef(accessKey) || secrets.IsSecretRef(secretKey) {
+				resolver, resCtx, err := buildSecretResolver(stackConfig)
+				if err != nil {
+					return "", "", err
+				}
+				resolved, err := secrets.ResolveRefs(map[string]interface{}{
+					"access_key": accessKey,
+					"secret_key": secretKey,
+				}, resolver, resCtx)
+				if err != nil {
+					return "", "", fmt.Errorf("failed to resolve SeaweedFS S3 credentials: %w", err)
+				}
+				keys := resolved.(map[string]interface{})
+				accessKey, secretKey = keys["access_key"].(string), keys["secret_key"].(string)
+			}
 			if accessKey != "" && secretKey != "" {
 				return accessKey, secretKey, nil
 			}
@@ -607,6 +899,15 @@ func installSSHComponent(ctx context.Context, cmd *cli.Command, name string, sta
 		cfg["version"] = version
 	}
 
+	// Pin images to the stack lockfile
+	lock, err := loadLock(cmd)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		cfg["image_digests"] = lock.ImageDigests()
+	}
+
 	// Add SSH connection to config (components extract this)
 	// We pass both the raw connection and the executor adapter
 	cfg["host"] = executor
@@ -639,6 +940,17 @@ func installSSHComponent(ctx context.Context, cmd *cli.Command, name string, sta
 		}
 		cfg["api_key"] = apiKey
 
+		// Prometheus scrapes /metrics with this password
+		client, err := createOpenBAOClient(stackConfig)
+		if err != nil {
+			return fmt.Errorf("failed to setup DNS metrics password: %w", err)
+		}
+		password, err := client.EnsureSecretValue(ctx, openbao.MetricsSecretMount, openbao.MetricsSecretPath, "powerdns_password", generateDNSAPIKey)
+		if err != nil {
+			return fmt.Errorf("failed to setup DNS metrics password: %w", err)
+		}
+		cfg["metrics_password"] = password
+
 		// Pass all zones as local zones for Recursor forwarding
 		// This includes primary_domain + kubernetes_zones + infrastructure_zones (deduplicated)
 		localZones := []string{}
@@ -1151,8 +1463,8 @@ func isDependencyInstalled(dep string, cfg *config.Config) bool {
 	case "k3s", "kubernetes":
 		return cfg.SetupState.K8sInstalled
 	// K8s-based components - check via Helm release status
-	case "storage", "seaweedfs", "prometheus", "loki", "grafana", "external-dns", "velero",
-		"gateway-api", "contour", "cert-manager":
+	case "storage", "seaweedfs", "prometheus", "loki", "tempo", "alloy", "grafana", "external-dns", "velero",
+		"gateway-api", "contour", "cert-manager", "blackbox-exporter":
 		// First check if K3s is installed
 		if !cfg.SetupState.K8sInstalled {
 			return false
@@ -1189,16 +1501,19 @@ func isHelmReleaseInstalled(componentName string, cfg *config.Config) bool {
 		name      string
 		namespace string
 	}{
-		"storage":      {"local-path-provisioner", "kube-system"},
-		"seaweedfs":    {"seaweedfs", "seaweedfs"},
-		"prometheus":   {"kube-prometheus-stack", "monitoring"},
-		"loki":         {"loki", "loki"},
-		"grafana":      {"grafana", "grafana"},
-		"external-dns": {"external-dns", "external-dns"},
-		"velero":       {"velero", "velero"},
-		"gateway-api":  {"gateway-api", "gateway-system"},
-		"contour":      {"contour", "projectcontour"},
-		"cert-manager": {"cert-manager", "cert-manager"},
+		"storage":           {"local-path-provisioner", "kube-system"},
+		"seaweedfs":         {"seaweedfs", "seaweedfs"},
+		"prometheus":        {"kube-prometheus-stack", "monitoring"},
+		"loki":              {"loki", "loki"},
+		"tempo":             {"tempo", "monitoring"},
+		"alloy":             {"alloy", "monitoring"},
+		"blackbox-exporter": {"blackbox-exporter", "monitoring"},
+		"grafana":           {"grafana", "grafana"},
+		"external-dns":      {"external-dns", "external-dns"},
+		"velero":            {"velero", "velero"},
+		"gateway-api":       {"gateway-api", "gateway-system"},
+		"contour":           {"contour", "projectcontour"},
+		"cert-manager":      {"cert-manager", "cert-manager"},
 	}
 
 	info, ok := releaseInfo[componentName]
@@ -1240,58 +1555,36 @@ func isHelmReleaseInstalled(componentName string, cfg *config.Config) bool {
 }
 
 // buildExternalTargetsFromStackConfig creates Prometheus external targets for
-// installed infrastructure services (OpenBAO, Zot, PowerDNS) based on stack config
-func buildExternalTargetsFromStackConfig(stackConfig *config.Config) []prometheus.ExternalTarget {
-	var targets []prometheus.ExternalTarget
-
-	// Check if stack config and setup state are available
-	if stackConfig == nil || stackConfig.SetupState == nil {
+// the hosts and their services (node_exporter, OpenBAO, Zot, PowerDNS) based on
+// stack config, after any typed by hand in components.prometheus
+func buildExternalTargetsFromStackConfig(ctx context.Context, stackConfig *config.Config) []interface{} {
+	var targets []interface{}
+	if stackConfig == nil {
 		return targets
 	}
 
-	// OpenBAO metrics at /v1/sys/metrics?format=prometheus
-	if stackConfig.SetupState.OpenBAOInstalled {
-		if addr, err := stackConfig.GetOpenBAOClientAddr(); err == nil {
-			targets = append(targets, prometheus.ExternalTarget{
-				Name:        "openbao",
-				Targets:     []string{addr},
-				MetricsPath: "/v1/sys/metrics",
-				Params: map[string][]string{
-					"format": {"prometheus"},
-				},
-			})
+	if promCfg, ok := stackConfig.Components["prometheus"]; ok && promCfg.Config != nil {
+		if typed, ok := promCfg.Config["external_targets"].([]interface{}); ok {
+			targets = append(targets, typed...)
 		}
 	}
 
-	// Zot registry metrics at /metrics on port 5000
-	if stackConfig.SetupState.ZotInstalled {
-		if addr, err := stackConfig.GetPrimaryZotAddress(); err == nil {
-			targets = append(targets, prometheus.ExternalTarget{
-				Name:        "zot",
-				Targets:     []string{fmt.Sprintf("%s:5000", addr)},
-				MetricsPath: "/metrics",
-			})
+	var creds prometheus.HostCredentials
+	if stackConfig.SetupState != nil && stackConfig.SetupState.OpenBAOInitialized {
+		if client, err := createOpenBAOClient(stackConfig); err != nil {
+			fmt.Printf("  ⚠ Host service metrics will be scraped without credentials: %v\n", err)
+		} else {
+			if creds.OpenBAOToken, err = client.EnsureMetricsToken(ctx); err != nil {
+				fmt.Printf("  ⚠ OpenBAO metrics token not available: %v\n", err)
+			}
+			if data, err := client.ReadSecretV2(ctx, openbao.MetricsSecretMount, openbao.MetricsSecretPath); err == nil {
+				creds.PowerDNSPassword, _ = data["powerdns_password"].(string)
+			}
 		}
 	}
 
-	// PowerDNS metrics (native Prometheus since v4.3.0+/v4.4.0+)
-	// Auth server on port 8081, Recursor on port 8082
-	if stackConfig.SetupState.DNSInstalled {
-		if addr, err := stackConfig.GetPrimaryDNSAddress(); err == nil {
-			// PowerDNS Authoritative Server
-			targets = append(targets, prometheus.ExternalTarget{
-				Name:        "powerdns-auth",
-				Targets:     []string{fmt.Sprintf("%s:8081", addr)},
-				MetricsPath: "/metrics",
-			})
-			// PowerDNS Recursor
-			targets = append(targets, prometheus.ExternalTarget{
-				Name:        "powerdns-recursor",
-				Targets:     []string{fmt.Sprintf("%s:8082", addr)},
-				MetricsPath: "/metrics",
-			})
-		}
+	for _, target := range prometheus.HostTargets(stackConfig, creds) {
+		targets = append(targets, target)
 	}
-
 	return targets
 }
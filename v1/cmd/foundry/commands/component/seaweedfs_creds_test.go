@@ -29,6 +29,28 @@ func TestGetSeaweedFSCredentials_FromStackConfig(t *testing.T) {
 	assert.Equal(t, "SECRET_TEST", secret)
 }
 
+// TestGetSeaweedFSCredentials_ResolvesRefs verifies that the references the
+// stack install leaves in place of the keys are resolved.
+func TestGetSeaweedFSCredentials_ResolvesRefs(t *testing.T) {
+	t.Setenv("FOUNDRY_SECRET_SEAWEEDFS_ACCESS_KEY", "AKIA_FROM_OPENBAO")
+	t.Setenv("FOUNDRY_SECRET_SEAWEEDFS_SECRET_KEY", "SECRET_FROM_OPENBAO")
+	cfg := &config.Config{
+		Components: config.ComponentMap{
+			"seaweedfs": config.ComponentConfig{
+				Config: map[string]any{
+					"access_key": "${secret:seaweedfs:access_key}",
+					"secret_key": "${secret:seaweedfs:secret_key}",
+				},
+			},
+		},
+	}
+
+	key, secret, err := getSeaweedFSCredentials(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "AKIA_FROM_OPENBAO", key)
+	assert.Equal(t, "SECRET_FROM_OPENBAO", secret)
+}
+
 // TestGetSeaweedFSCredentials_MissingErrors verifies the error path when neither
 // the stack config nor a k8s secret can supply credentials.
 func TestGetSeaweedFSCredentials_MissingErrors(t *testing.T) {
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/catalystcommunity/foundry/v1/internal/component"
 	"github.com/catalystcommunity/foundry/v1/internal/component/statushelpers"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
 	"github.com/catalystcommunity/foundry/v1/internal/systemd"
 	"github.com/urfave/cli/v3"
 )
@@ -194,6 +196,7 @@ func CheckOpenBAOStatus(ctx context.Context, cfg *config.Config) (*component.Com
 	} else {
 		message = fmt.Sprintf("service state: %s, sub-state: %s", svcStatus.ActiveState, svcStatus.SubState)
 	}
+	message, healthy = appendCertificateStatus(cfg, executor, "openbao", message, healthy)
 
 	return &component.ComponentStatus{
 		Installed: true,
@@ -300,12 +303,13 @@ func CheckDNSStatus(ctx context.Context, cfg *config.Config) (*component.Compone
 		allHealthy = false
 		messages = append(messages, "recursor: not installed")
 	}
+	message := strings.Join(messages, ", ")
 
 	return &component.ComponentStatus{
 		Installed: authStatus.Loaded || recursorStatus.Loaded,
 		Version:   "",
 		Healthy:   allHealthy,
-		Message:   strings.Join(messages, ", "),
+		Message:   message,
 	}, nil
 }
 
@@ -374,7 +378,8 @@ func CheckZotStatus(ctx context.Context, cfg *config.Config) (*component.Compone
 	message := "service running"
 
 	if healthy {
-		result, err := conn.Exec("curl -s http://localhost:5000/v2/")
+		// /v2/ answers 401 when auth is enabled, which still shows the API is up
+		result, err := conn.Exec("curl -s -o /dev/null http://localhost:5000/v2/ || curl -sk -o /dev/null https://localhost:5000/v2/")
 		if err == nil && result.ExitCode == 0 {
 			message = "healthy (API responding)"
 		} else {
@@ -384,6 +389,7 @@ func CheckZotStatus(ctx context.Context, cfg *config.Config) (*component.Compone
 	} else {
 		message = fmt.Sprintf("service state: %s, sub-state: %s", svcStatus.ActiveState, svcStatus.SubState)
 	}
+	message, healthy = appendCertificateStatus(cfg, executor, "zot", message, healthy)
 
 	return &component.ComponentStatus{
 		Installed: true,
@@ -393,6 +399,28 @@ func CheckZotStatus(ctx context.Context, cfg *config.Config) (*component.Compone
 	}, nil
 }
 
+// appendCertificateStatus adds the expiry of a Foundry-managed host service
+// certificate to a status message. Expired certificates mark the service
+// unhealthy. Stacks without host_tls are left unchanged.
+func appendCertificateStatus(cfg *config.Config, executor hosttls.Executor, name, message string, healthy bool) (string, bool) {
+	if cfg.HostTLS == nil {
+		return message, healthy
+	}
+	svc, ok := hosttls.ServiceByName(name)
+	if !ok {
+		return message, healthy
+	}
+	notAfter, err := hosttls.ReadExpiry(executor, svc)
+	if err != nil {
+		return message + ", certificate: not installed", healthy
+	}
+	remaining := time.Until(notAfter)
+	if remaining <= 0 {
+		return message + fmt.Sprintf(", certificate: expired %s", notAfter.Format("2006-01-02")), false
+	}
+	return message + fmt.Sprintf(", certificate: expires in %dd", int(remaining.Hours()/24)), healthy
+}
+
 // CheckK3sStatus checks the K3s component status
 func CheckK3sStatus(ctx context.Context, cfg *config.Config) (*component.ComponentStatus, error) {
 	// Get first cluster host
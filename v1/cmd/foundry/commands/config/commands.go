@@ -11,5 +11,6 @@ var Command = &cli.Command{
 		ValidateCommand,
 		ShowCommand,
 		ListCommand,
+		ScanSecretsCommand,
 	},
 }
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/urfave/cli/v3"
+)
+
+// secretsMount is the OpenBAO mount ${secret:...} references resolve from
+const secretsMount = "foundry-core"
+
+// ScanSecretsCommand moves secrets found in a config file into OpenBAO
+var ScanSecretsCommand = &cli.Command{
+	Name:      "scan-secrets",
+	Usage:     "Move secrets in the config file to OpenBAO",
+	ArgsUsage: "[config-file]",
+	Description: `Finds values that look like secrets - fields named like one (password,
+secret_key, api_key, token, ...) and long random strings - stores them in
+OpenBAO's foundry-core mount and rewrites the config with ${secret:path:key}
+references to them. A value found in several places is stored once.
+
+A key already stored in OpenBAO with a different value is left alone, and
+the fields holding it are not rewritten.
+
+The old values stay in copies of the config, such as backups and git
+history, so rotate them after migrating.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "List what would be migrated without changing anything",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "Skip confirmation prompt",
+		},
+	},
+	Action: runScanSecrets,
+}
+
+// secretStore is the part of the OpenBAO client the migration uses
+type secretStore interface {
+	ReadSecretV2(ctx context.Context, mount, path string) (map[string]interface{}, error)
+	WriteSecretV2(ctx context.Context, mount, path string, data map[string]interface{}) error
+}
+
+func runScanSecrets(ctx context.Context, cmd *cli.Command) error {
+	configPath := cmd.String("config")
+	if cmd.Args().Len() > 0 {
+		configPath = cmd.Args().First()
+	}
+
+	if configPath == "" {
+		path, err := config.FindConfig("stack")
+		if err != nil {
+			return fmt.Errorf("no config file specified and no default found: %w", err)
+		}
+		configPath = path
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	findings, err := config.ScanSecrets(cfg)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		fmt.Printf("✓ No secrets found in %s\n", configPath)
+		return nil
+	}
+
+	fmt.Printf("Found %d secret(s) in %s:\n", len(findings), configPath)
+	for _, f := range findings {
+		fmt.Printf("  %s (%s)\n    -> %s\n", f.Field, f.Reason, f.Ref())
+	}
+	fmt.Println()
+
+	if cmd.Bool("dry-run") {
+		fmt.Println("Dry run: nothing was changed")
+		return nil
+	}
+
+	if !cmd.Bool("yes") {
+		fmt.Printf("The values will be stored in OpenBAO and replaced by the references above in %s.\n", configPath)
+		fmt.Print("Type 'yes' to migrate: ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "yes" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	client, err := openBAOClient(cfg)
+	if err != nil {
+		return err
+	}
+	refs, conflicts, err := migrateSecrets(ctx, client, findings)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		fmt.Printf("  ⚠ %s\n", conflict)
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("no secrets were migrated")
+	}
+
+	if err := config.ReplaceSecrets(cfg, refs); err != nil {
+		return err
+	}
+	if err := config.Save(cfg, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Moved %d secret(s) to OpenBAO and updated %s\n", len(refs), configPath)
+	fmt.Println("  Copies of the config made before now, such as backups and git history,")
+	fmt.Println("  still hold the values; rotate them.")
+	return nil
+}
+
+// migrateSecrets stores the findings' values in OpenBAO, keeping whatever
+// else is stored at their paths. It returns the references for the fields
+// that were migrated, by field, and a note for each value that was not
+// because OpenBAO holds a different one under its key.
+func migrateSecrets(ctx context.Context, store secretStore, findings []config.SecretFinding) (map[string]string, []string, error) {
+	byPath := map[string][]config.SecretFinding{}
+	for _, f := range findings {
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	refs := map[string]string{}
+	var conflicts []string
+	for _, path := range paths {
+		data, err := store.ReadSecretV2(ctx, secretsMount, path)
+		if err != nil && !openbao.IsSecretNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to read %s/%s from OpenBAO: %w", secretsMount, path, err)
+		}
+		updated := map[string]interface{}{}
+		for k, v := range data {
+			updated[k] = v
+		}
+
+		changed := false
+		var migrated []config.SecretFinding
+		for _, f := range byPath[path] {
+			if existing, ok := updated[f.Key]; ok {
+				if existing != f.Value {
+					conflicts = append(conflicts, fmt.Sprintf("%s not migrated: %s/%s already holds a different %s", f.Field, secretsMount, path, f.Key))
+					continue
+				}
+			} else {
+				updated[f.Key] = f.Value
+				changed = true
+			}
+			migrated = append(migrated, f)
+		}
+
+		if changed {
+			if err := store.WriteSecretV2(ctx, secretsMount, path, updated); err != nil {
+				return nil, nil, fmt.Errorf("failed to write %s/%s to OpenBAO: %w", secretsMount, path, err)
+			}
+		}
+		for _, f := range migrated {
+			refs[f.Field] = f.Ref()
+		}
+	}
+	return refs, conflicts, nil
+}
+
+func openBAOClient(cfg *config.Config) (*openbao.Client, error) {
+	addr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenBAO address: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenBAO keys (has OpenBAO been initialized?): %w", err)
+	}
+	return openbao.NewClient(addr, keyMaterial.RootToken), nil
+}
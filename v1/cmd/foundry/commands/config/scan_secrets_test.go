@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretStore keeps secrets by mount/path, failing reads like OpenBAO
+// does for paths with nothing stored
+type fakeSecretStore struct {
+	secrets map[string]map[string]interface{}
+	writes  int
+}
+
+func (f *fakeSecretStore) ReadSecretV2(_ context.Context, mount, path string) (map[string]interface{}, error) {
+	data, ok := f.secrets[mount+"/"+path]
+	if !ok {
+		return nil, fmt.Errorf("unexpected status code 404: {\"errors\":[]}")
+	}
+	return data, nil
+}
+
+func (f *fakeSecretStore) WriteSecretV2(_ context.Context, mount, path string, data map[string]interface{}) error {
+	f.secrets[mount+"/"+path] = data
+	f.writes++
+	return nil
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	store := &fakeSecretStore{secrets: map[string]map[string]interface{}{
+		"foundry-core/zot":     {"docker_hub_username": "foundry", "docker_hub_password": "stored-elsewhere"},
+		"foundry-core/truenas": {"api_key": "1-abcdefghij"},
+	}}
+	findings := []config.SecretFinding{
+		{Field: "components.loki.s3_access_key", Value: "AKIA123", Path: "seaweedfs", Key: "access_key"},
+		{Field: "components.seaweedfs.access_key", Value: "AKIA123", Path: "seaweedfs", Key: "access_key"},
+		{Field: "components.seaweedfs.secret_key", Value: "SECRET456", Path: "seaweedfs", Key: "secret_key"},
+		{Field: "components.zot.docker_hub_password", Value: "typed-in-config", Path: "zot", Key: "docker_hub_password"},
+		{Field: "storage.truenas.api_key", Value: "1-abcdefghij", Path: "truenas", Key: "api_key"},
+	}
+
+	refs, conflicts, err := migrateSecrets(context.Background(), store, findings)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"components.loki.s3_access_key":   "${secret:seaweedfs:access_key}",
+		"components.seaweedfs.access_key": "${secret:seaweedfs:access_key}",
+		"components.seaweedfs.secret_key": "${secret:seaweedfs:secret_key}",
+		"storage.truenas.api_key":         "${secret:truenas:api_key}",
+	}, refs)
+	require.Len(t, conflicts, 1)
+	assert.Contains(t, conflicts[0], "components.zot.docker_hub_password not migrated")
+
+	assert.Equal(t, map[string]interface{}{"access_key": "AKIA123", "secret_key": "SECRET456"}, store.secrets["foundry-core/seaweedfs"])
+	assert.Equal(t, "stored-elsewhere", store.secrets["foundry-core/zot"]["docker_hub_password"])
+	assert.Equal(t, 1, store.writes, "paths already holding the values are not rewritten")
+}
+
+func TestMigrateSecrets_ReadError(t *testing.T) {
+	store := &failingSecretStore{}
+	_, _, err := migrateSecrets(context.Background(), store, []config.SecretFinding{
+		{Field: "dns.api_key", Value: "abc", Path: "dns", Key: "api_key"},
+	})
+	assert.ErrorContains(t, err, "failed to read foundry-core/dns")
+}
+
+// failingSecretStore cannot reach OpenBAO
+type failingSecretStore struct{}
+
+func (failingSecretStore) ReadSecretV2(context.Context, string, string) (map[string]interface{}, error) {
+	return nil, fmt.Errorf("failed to read secret: connection refused")
+}
+
+func (failingSecretStore) WriteSecretV2(context.Context, string, string, map[string]interface{}) error {
+	return fmt.Errorf("failed to write secret: connection refused")
+}
@@ -29,12 +29,16 @@ If no Ingress exists, the command uses the Grafana service address.
 Examples:
   foundry dashboard              # Open Grafana dashboard
   foundry dashboard open         # Same as above
-  foundry dashboard url          # Just print the URL`,
+  foundry dashboard url          # Just print the URL
+  foundry dashboard export       # Save dashboards edited in Grafana to disk
+  foundry dashboard diff         # Compare Grafana with the saved dashboards`,
 	Commands: []*cli.Command{
 		OpenCommand,
 		URLCommand,
 		SyncCommand,
 		ListCommand,
+		ExportCommand,
+		DiffCommand,
 	},
 	Flags:  dashboardFlags(),
 	Action: runOpen,
@@ -17,14 +17,16 @@ func TestCommand(t *testing.T) {
 		t.Errorf("Command.Name = %q, want dashboard", Command.Name)
 	}
 
-	if len(Command.Commands) != 4 {
-		t.Errorf("Command.Commands count = %d, want 4", len(Command.Commands))
+	if len(Command.Commands) != 6 {
+		t.Errorf("Command.Commands count = %d, want 6", len(Command.Commands))
 	}
 
 	foundOpen := false
 	foundURL := false
 	foundSync := false
 	foundList := false
+	foundExport := false
+	foundDiff := false
 	for _, cmd := range Command.Commands {
 		switch cmd.Name {
 		case "open":
@@ -35,6 +37,10 @@ func TestCommand(t *testing.T) {
 			foundSync = true
 		case "list":
 			foundList = true
+		case "export":
+			foundExport = true
+		case "diff":
+			foundDiff = true
 		}
 	}
 
@@ -50,6 +56,12 @@ func TestCommand(t *testing.T) {
 	if !foundList {
 		t.Error("Should have 'list' subcommand")
 	}
+	if !foundExport {
+		t.Error("Should have 'export' subcommand")
+	}
+	if !foundDiff {
+		t.Error("Should have 'diff' subcommand")
+	}
 
 	if !hasFlag(Command, "namespace") {
 		t.Error("Command should have --namespace flag")
@@ -0,0 +1,315 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/urfave/cli/v3"
+
+	grafanacmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/grafana"
+	"github.com/catalystcommunity/foundry/v1/internal/dashboards"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+)
+
+// ExportCommand pulls dashboards from the live Grafana into the dashboards directory.
+var ExportCommand = &cli.Command{
+	Name:  "export",
+	Usage: "Save dashboards from the live Grafana into the stack dashboards directory",
+	Description: `Downloads dashboards over the Grafana HTTP API, as the admin user that
+'foundry grafana credentials' shows, and writes them into
+~/.foundry/<stack>_dashboards/ so that 'foundry dashboard sync' keeps them.
+
+Dashboards built or edited in the Grafana UI live only in Grafana's database
+and are lost when it is reset. Exporting them makes the dashboards directory
+the source of truth again.
+
+Grafana's id and version fields are stripped and keys are sorted, so exports
+of an unchanged dashboard produce identical files. A dashboard whose uid is
+already in a file is written over that file; others get <slug>.json. Bundled
+defaults are skipped: sync refreshes them, so save edits to a default under a
+new uid in Grafana ("Save as") and export that. Without --folder or --uid,
+dashboards provisioned by charts, such as kube-prometheus-stack's, are
+skipped too unless a file already has their uid.
+
+Examples:
+  foundry dashboard export                       # every dashboard
+  foundry dashboard export --folder Apps         # one folder
+  foundry dashboard export --uid my-api --uid db # specific dashboards
+  foundry dashboard export --dry-run             # show what would be written`,
+	Flags: append(grafanaAPIFlags(),
+		&cli.BoolFlag{
+			Name:    "dry-run",
+			Aliases: []string{"d"},
+			Usage:   "Show what would be written without writing files",
+		},
+	),
+	Action: runExport,
+}
+
+// DiffCommand compares the live dashboards with the dashboards directory.
+var DiffCommand = &cli.Command{
+	Name:  "diff",
+	Usage: "Show differences between the live Grafana and the stack dashboards directory",
+	Description: `Compares each dashboard in Grafana with the file that has the same uid,
+after stripping id and version and sorting keys, and prints a unified diff
+from the file to the live copy. Dashboards that exist on only one side are
+listed too.
+
+Examples:
+  foundry dashboard diff
+  foundry dashboard diff --uid foundry-host-services
+  foundry dashboard diff --folder Apps`,
+	Flags:  grafanaAPIFlags(),
+	Action: runDiff,
+}
+
+func grafanaAPIFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "namespace",
+			Aliases: []string{"n"},
+			Usage:   "Namespace where Grafana is installed",
+			Value:   "monitoring",
+		},
+		&cli.StringFlag{
+			Name:  "dir",
+			Usage: "Override the dashboards directory (default ~/.foundry/<stack>_dashboards)",
+		},
+		&cli.StringFlag{
+			Name:  "folder",
+			Usage: "Only dashboards in this Grafana folder (\"General\" for the root folder)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "uid",
+			Usage: "Only the dashboard with this uid (repeatable)",
+		},
+		&cli.StringFlag{
+			Name:  "grafana-url",
+			Usage: "Grafana URL to use instead of the one found from the cluster",
+		},
+	}
+}
+
+func runExport(ctx context.Context, cmd *cli.Command) error {
+	dir, err := resolveDashboardsDir(cmd)
+	if err != nil {
+		return err
+	}
+	// Seed first so the bundled defaults' uids are known
+	if _, err := dashboards.SeedDefaults(dir); err != nil {
+		return fmt.Errorf("failed to seed default dashboards: %w", err)
+	}
+	files, err := dashboards.Collect(dir)
+	if err != nil {
+		return err
+	}
+
+	client, err := grafanaAPIClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	live, err := selectLive(ctx, client, cmd)
+	if err != nil {
+		return err
+	}
+
+	filtered := cmd.String("folder") != "" || len(cmd.StringSlice("uid")) > 0
+	dryRun := cmd.Bool("dry-run")
+	var written, unchanged, skipped int
+	for _, s := range live {
+		d, err := client.Dashboard(ctx, s.UID)
+		if err != nil {
+			return err
+		}
+		path, managed := dashboards.ExportPath(dir, files, d)
+		if managed {
+			fmt.Printf("  ⚠ skipping %q (%s): bundled default, save it under a new uid to keep changes\n", d.Title, d.UID)
+			skipped++
+			continue
+		}
+		if !filtered && dashboards.ProvisionedElsewhere(files, d) {
+			fmt.Printf("  - skipping %q (%s): provisioned outside the dashboards directory, export it with --uid\n", d.Title, d.UID)
+			skipped++
+			continue
+		}
+
+		data, err := dashboards.Normalize(d.Data)
+		if err != nil {
+			return fmt.Errorf("dashboard %s: %w", d.UID, err)
+		}
+		if existing, err := os.ReadFile(path); err == nil {
+			if current, err := dashboards.Normalize(existing); err == nil && bytes.Equal(current, data) {
+				unchanged++
+				continue
+			}
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		if dryRun {
+			fmt.Printf("  would write %s  (%q, %s)\n", rel, d.Title, d.UID)
+			written++
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("  ✓ %s  (%q, %s)\n", rel, d.Title, d.UID)
+		written++
+	}
+
+	verb := "Exported"
+	if dryRun {
+		verb = "Would export"
+	}
+	fmt.Printf("\n%s %d dashboard(s) to %s: %d unchanged, %d skipped\n", verb, written, dir, unchanged, skipped)
+	if written > 0 && !dryRun {
+		fmt.Println("Run 'foundry dashboard sync' to install them as ConfigMaps so Grafana reloads them after a restart.")
+	}
+	return nil
+}
+
+func runDiff(ctx context.Context, cmd *cli.Command) error {
+	dir, err := resolveDashboardsDir(cmd)
+	if err != nil {
+		return err
+	}
+	files, err := dashboards.Collect(dir)
+	if err != nil {
+		return err
+	}
+
+	client, err := grafanaAPIClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	live, err := selectLive(ctx, client, cmd)
+	if err != nil {
+		return err
+	}
+
+	onDisk := dashboards.ByUID(files)
+	seen := make(map[string]bool, len(live))
+	var differ, same int
+	var onlyLive []string
+	for _, s := range live {
+		seen[s.UID] = true
+		f, ok := onDisk[s.UID]
+		if !ok {
+			onlyLive = append(onlyLive, fmt.Sprintf("%s  (%q in %s)", s.UID, s.Title, s.Folder()))
+			continue
+		}
+		d, err := client.Dashboard(ctx, s.UID)
+		if err != nil {
+			return err
+		}
+		name := f.FileName
+		if f.Source == "default" {
+			name = filepath.Join(dashboards.DefaultsSubdir, f.FileName)
+		}
+		diff, err := dashboards.Diff(name, f.Data, d.Data)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			same++
+			continue
+		}
+		differ++
+		fmt.Print(diff)
+		fmt.Println()
+	}
+
+	// Files can't be matched to a folder, so only report them when all
+	// dashboards were compared
+	var onlyDisk []string
+	if cmd.String("folder") == "" && len(cmd.StringSlice("uid")) == 0 {
+		for uid, f := range onDisk {
+			if !seen[uid] {
+				onlyDisk = append(onlyDisk, fmt.Sprintf("%s  (%s, %s)", uid, f.FileName, f.Source))
+			}
+		}
+		sort.Strings(onlyDisk)
+	}
+
+	if len(onlyLive) > 0 {
+		fmt.Println("Only in Grafana (export them with 'foundry dashboard export'):")
+		for _, line := range onlyLive {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	if len(onlyDisk) > 0 {
+		fmt.Println("Only on disk (install them with 'foundry dashboard sync'):")
+		for _, line := range onlyDisk {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	fmt.Printf("\n%d identical, %d different, %d only in Grafana, %d only on disk\n", same, differ, len(onlyLive), len(onlyDisk))
+	return nil
+}
+
+// selectLive lists the live dashboards matching --folder and --uid.
+func selectLive(ctx context.Context, client *dashboards.Client, cmd *cli.Command) ([]dashboards.Summary, error) {
+	list, err := client.Search(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uids := cmd.StringSlice("uid")
+	selected := dashboards.Select(list, cmd.String("folder"), uids)
+	if len(selected) == 0 && (cmd.String("folder") != "" || len(uids) > 0) {
+		return nil, fmt.Errorf("no dashboards in Grafana match the --folder/--uid filters")
+	}
+	return selected, nil
+}
+
+// grafanaAPIClient connects to Grafana as the admin user. The ingress
+// certificate is trusted through the system roots or the cluster CA.
+func grafanaAPIClient(ctx context.Context, cmd *cli.Command) (*dashboards.Client, error) {
+	username, password, err := grafanacmd.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Grafana credentials: %w", err)
+	}
+
+	clientset, err := getK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	grafanaURL := cmd.String("grafana-url")
+	if grafanaURL == "" {
+		grafanaURL, err = getGrafanaURL(ctx, cmd.String("namespace"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	secret, err := clientset.CoreV1().Secrets(hosttls.ClusterCANamespace).Get(ctx, hosttls.ClusterCASecret, metav1.GetOptions{})
+	if err == nil {
+		pool.AppendCertsFromPEM(secret.Data[corev1.TLSCertKey])
+	}
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}
+
+	return dashboards.NewClient(grafanaURL, username, password, httpClient), nil
+}
@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -45,6 +46,7 @@ var ControllerCommand = &cli.Command{
 		&cli.DurationFlag{Name: "interval", Value: 15 * time.Second, Usage: "resync interval for the watch loop"},
 		&cli.BoolFlag{Name: "once", Usage: "run a single reconcile pass and exit"},
 		&cli.StringFlag{Name: "kubeconfig", Usage: "path to kubeconfig (default: in-cluster when running as a pod, else ~/.foundry/kubeconfig)"},
+		&cli.StringFlag{Name: "metrics-addr", Usage: "address to serve Prometheus metrics on (e.g. :9090); empty disables them", Sources: cli.EnvVars("FOUNDRY_GATEWAY_METRICS_ADDR")},
 	},
 	Action: runController,
 }
@@ -62,8 +64,10 @@ func runController(ctx context.Context, cmd *cli.Command) error {
 		NetworkPolicy:    cmd.String("network-policy"),
 	}
 
+	metrics := &gateway.Metrics{}
 	reconcileOnce := func(ctx context.Context) error {
 		result, err := gateway.Reconcile(ctx, k8sClient.DynamicClient(), k8sClient.Clientset(), opts)
+		metrics.Observe(result, err)
 		if err != nil {
 			return err
 		}
@@ -81,6 +85,19 @@ func runController(ctx context.Context, cmd *cli.Command) error {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	if addr := cmd.String("metrics-addr"); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "  ⚠ metrics server failed: %v\n", err)
+			}
+		}()
+		defer server.Close()
+		fmt.Printf("Serving metrics on %s/metrics\n", addr)
+	}
+
 	interval := cmd.Duration("interval")
 	fmt.Printf("Gateway controller watching routes for Gateway %s/%s (resync %s)\n",
 		opts.GatewayNamespace, opts.GatewayName, interval)
@@ -73,7 +73,7 @@ Examples:
 }
 
 func runPassword(ctx context.Context, cmd *cli.Command) error {
-	_, password, err := getGrafanaCredentials(ctx)
+	_, password, err := Credentials(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get Grafana password: %w\n\nHint: Ensure Grafana is installed with 'foundry component install grafana'", err)
 	}
@@ -83,7 +83,7 @@ func runPassword(ctx context.Context, cmd *cli.Command) error {
 }
 
 func runCredentials(ctx context.Context, cmd *cli.Command) error {
-	username, password, err := getGrafanaCredentials(ctx)
+	username, password, err := Credentials(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get Grafana credentials: %w\n\nHint: Ensure Grafana is installed with 'foundry component install grafana'", err)
 	}
@@ -106,8 +106,8 @@ func runCredentials(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
-// getGrafanaCredentials retrieves Grafana credentials from OpenBAO or Kubernetes secrets
-func getGrafanaCredentials(ctx context.Context) (username, password string, err error) {
+// Credentials retrieves Grafana credentials from OpenBAO or Kubernetes secrets
+func Credentials(ctx context.Context) (username, password string, err error) {
 	// First, try OpenBAO
 	openBAOClient, err := getOpenBAOClient()
 	if err == nil {
@@ -13,6 +13,8 @@ import (
 	"strings"
 	"time"
 
+	backupcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/backup"
+	hostcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/host"
 	stackcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/stack"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
 	"github.com/catalystcommunity/foundry/v1/internal/discovery"
@@ -220,7 +222,8 @@ func runServe(ctx context.Context, cmd *cli.Command) error {
 	}
 	auth := webui.NewAuthStore()
 	auth.AddToken("manager-admin", token, 365*24*time.Hour)
-	server, err := newServer(resolveConfigPath(cmd.String("config")), auth, "external")
+	configPath := resolveConfigPath(cmd.String("config"))
+	server, err := newServer(configPath, auth, "external")
 	if err != nil {
 		return err
 	}
@@ -230,6 +233,10 @@ func runServe(ctx context.Context, cmd *cli.Command) error {
 	}
 	defer listener.Close()
 	fmt.Printf("Foundry manager listening on %s\n", listener.Addr())
+	go backupcmd.ScheduleVerify(ctx, configPath, os.Stdout)
+	go hostcmd.ScheduleTLSRenewal(ctx, configPath, os.Stdout)
+	go stackcmd.ScheduleMetricsTokenRenewal(ctx, configPath, os.Stdout)
+	go stackcmd.ScheduleProbeSync(ctx, configPath, os.Stdout)
 	return serve(ctx, listener, server.Handler())
 }
 
@@ -12,5 +12,6 @@ var Command = &cli.Command{
 		ConfigureCommand,
 		SyncKeysCommand,
 		MigrateKeysCommand,
+		TLSCommand,
 	},
 }
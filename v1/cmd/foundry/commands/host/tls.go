@@ -0,0 +1,246 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	registrycmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/registry"
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/component/statushelpers"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
+	"github.com/urfave/cli/v3"
+)
+
+// TLSCommand manages TLS certificates for services running on hosts
+var TLSCommand = &cli.Command{
+	Name:  "tls",
+	Usage: "Manage TLS certificates for host services (OpenBAO, Zot)",
+	Description: `Issues and renews certificates for the services Foundry runs directly on
+infrastructure hosts, as configured in the host_tls section of the stack config.
+
+With issuer "internal-ca" certificates are signed by the cluster's foundry-ca
+(created by cert-manager), and the Foundry manager reissues them when they
+enter their renewal window. With issuer "acme" each host obtains and renews
+its own certificates using DNS-01 challenges against the stack's PowerDNS.`,
+	Commands: []*cli.Command{
+		tlsIssueCommand,
+		tlsStatusCommand,
+	},
+}
+
+var tlsIssueCommand = &cli.Command{
+	Name:      "issue",
+	Usage:     "Issue or renew host service certificates",
+	ArgsUsage: "[openbao|zot...]",
+	Description: `Issues certificates for the given host services (all installed services
+by default), installs them, reloads the services and sets up renewal.
+
+Examples:
+  foundry host tls issue
+  foundry host tls issue openbao zot`,
+	Action: runTLSIssue,
+}
+
+var tlsStatusCommand = &cli.Command{
+	Name:   "status",
+	Usage:  "Show certificate expiry for host services",
+	Action: runTLSStatus,
+}
+
+func runTLSIssue(ctx context.Context, cmd *cli.Command) error {
+	cfg, err := loadTLSConfig(cmd)
+	if err != nil {
+		return err
+	}
+	opts, err := hosttls.OptionsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	targets, err := hosttls.Targets(cfg, cmd.Args().Slice())
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(cmd.Root().Writer, "No installed host services to issue certificates for.")
+		return nil
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	input := hosttls.ApplyInput{Options: opts}
+	switch opts.Issuer {
+	case config.HostTLSIssuerInternalCA:
+		input.CA, err = loadClusterCA(ctx, configDir)
+		if err != nil {
+			return err
+		}
+	case config.HostTLSIssuerACME:
+		dnsAddr, err := cfg.GetPrimaryDNSAddress()
+		if err != nil {
+			return fmt.Errorf("ACME DNS-01 requires the dns role: %w", err)
+		}
+		input.DNSAPIURL = fmt.Sprintf("http://%s:8081", dnsAddr)
+		input.DNSAPIKey, err = readDNSAPIKey(ctx, cfg, configDir)
+		if err != nil {
+			return fmt.Errorf("failed to read PowerDNS API key from OpenBAO: %w", err)
+		}
+	}
+
+	syncRegistry := false
+	for _, target := range targets {
+		fmt.Fprintf(cmd.Root().Writer, "Issuing certificates on %s (%s)...\n", target.Host.Hostname, serviceNames(target.Services))
+		conn, err := statushelpers.ConnectToHost(target.Host, configDir, cfg.Cluster.Name)
+		if err != nil {
+			return err
+		}
+		input.Target = target
+		err = hosttls.Apply(&statushelpers.SSHExecutorAdapter{Conn: conn}, input)
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", target.Host.Hostname, err)
+		}
+		for _, svc := range target.Services {
+			fmt.Fprintf(cmd.Root().Writer, "  ✓ %s\n", svc.FQDN(opts.Domain))
+			if svc.Name == "zot" && registry.TLSEnabled(cfg) {
+				syncRegistry = true
+			}
+		}
+	}
+
+	// Zot only serves HTTPS once its configuration is re-rendered, and nodes
+	// need registries.yaml pointed at it
+	if syncRegistry {
+		fmt.Fprintln(cmd.Root().Writer, "Switching registry to HTTPS...")
+		return registrycmd.Sync(ctx, cfg, configDir, cmd.Root().Writer)
+	}
+	return nil
+}
+
+func runTLSStatus(ctx context.Context, cmd *cli.Command) error {
+	cfg, err := loadTLSConfig(cmd)
+	if err != nil {
+		return err
+	}
+	targets, err := hosttls.Targets(cfg, nil)
+	if err != nil {
+		return err
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	renewBefore := hosttls.DefaultRenewBefore
+	if opts, err := hosttls.OptionsFromConfig(cfg); err == nil {
+		renewBefore = opts.RenewBefore
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "SERVICE\tHOST\tEXPIRES\tSTATUS")
+	for _, target := range targets {
+		conn, err := statushelpers.ConnectToHost(target.Host, configDir, cfg.Cluster.Name)
+		if err != nil {
+			for _, svc := range target.Services {
+				fmt.Fprintf(w, "%s\t%s\t-\tunreachable\n", svc.Name, target.Host.Hostname)
+			}
+			continue
+		}
+		executor := &statushelpers.SSHExecutorAdapter{Conn: conn}
+		for _, svc := range target.Services {
+			notAfter, err := hosttls.ReadExpiry(executor, svc)
+			if err != nil {
+				fmt.Fprintf(w, "%s\t%s\t-\tnot installed\n", svc.Name, target.Host.Hostname)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", svc.Name, target.Host.Hostname, notAfter.Format("2006-01-02"), expiryStatus(notAfter, renewBefore))
+		}
+		conn.Close()
+	}
+	return nil
+}
+
+func expiryStatus(notAfter time.Time, renewBefore time.Duration) string {
+	remaining := time.Until(notAfter)
+	switch {
+	case remaining <= 0:
+		return "expired"
+	case remaining <= renewBefore:
+		return fmt.Sprintf("renew due (%dd left)", int(remaining.Hours()/24))
+	default:
+		return fmt.Sprintf("ok (%dd left)", int(remaining.Hours()/24))
+	}
+}
+
+func serviceNames(services []hosttls.Service) string {
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func loadTLSConfig(cmd *cli.Command) (*config.Config, error) {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// readDNSAPIKey reads the PowerDNS API key stored in OpenBAO by stack install
+func readDNSAPIKey(ctx context.Context, cfg *config.Config, configDir string) (string, error) {
+	openBAOAddr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return "", err
+	}
+	keysData, err := os.ReadFile(filepath.Join(configDir, "openbao-keys", cfg.Cluster.Name, "keys.json"))
+	if err != nil {
+		return "", err
+	}
+	var keys struct {
+		RootToken string `json:"root_token"`
+	}
+	if err := json.Unmarshal(keysData, &keys); err != nil {
+		return "", err
+	}
+
+	secretData, err := openbao.NewClient(openBAOAddr, keys.RootToken).ReadSecretV2(ctx, "foundry-core", "dns")
+	if err != nil {
+		return "", err
+	}
+	if apiKey, ok := secretData["api_key"].(string); ok {
+		return apiKey, nil
+	}
+	return "", fmt.Errorf("api_key not found in secret")
+}
+
+// loadClusterCA loads the internal CA from the cluster the config directory's
+// kubeconfig points at
+func loadClusterCA(ctx context.Context, configDir string) (*hosttls.CA, error) {
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig (the internal CA lives in the cluster): %w", err)
+	}
+	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	return hosttls.LoadClusterCA(ctx, k8sClient)
+}
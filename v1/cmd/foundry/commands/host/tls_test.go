@@ -0,0 +1,29 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSCommandStructure(t *testing.T) {
+	require.Len(t, TLSCommand.Commands, 2)
+	assert.Equal(t, "issue", TLSCommand.Commands[0].Name)
+	assert.Equal(t, "status", TLSCommand.Commands[1].Name)
+	assert.Contains(t, Command.Commands, TLSCommand)
+}
+
+func TestExpiryStatus(t *testing.T) {
+	renewBefore := 30 * 24 * time.Hour
+	assert.Equal(t, "expired", expiryStatus(time.Now().Add(-time.Hour), renewBefore))
+	assert.Equal(t, "renew due (9d left)", expiryStatus(time.Now().Add(10*24*time.Hour-time.Minute), renewBefore))
+	assert.Equal(t, "ok (59d left)", expiryStatus(time.Now().Add(60*24*time.Hour-time.Minute), renewBefore))
+}
+
+func TestServiceNames(t *testing.T) {
+	assert.Equal(t, "openbao, zot", serviceNames(hosttls.DefaultServices()))
+	assert.Equal(t, "", serviceNames(nil))
+}
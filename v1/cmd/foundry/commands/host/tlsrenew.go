@@ -0,0 +1,89 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/statushelpers"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+)
+
+// tlsRenewInterval is how often the manager checks host certificates
+const tlsRenewInterval = 12 * time.Hour
+
+// ScheduleTLSRenewal reissues host service certificates signed by the
+// internal CA once they enter their renewal window, until ctx is done. The
+// manager runs it: the CA key stays in the cluster, so hosts cannot renew
+// these certificates themselves. The stack config is re-read each time.
+func ScheduleTLSRenewal(ctx context.Context, configPath string, out io.Writer) {
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := renewHostCertificates(ctx, configPath, out, time.Now()); err != nil {
+			fmt.Fprintf(out, "host TLS renewal: %v\n", err)
+		}
+		timer.Reset(tlsRenewInterval)
+	}
+}
+
+// renewHostCertificates reissues the internal-CA certificates on every host
+// that are missing or due for renewal
+func renewHostCertificates(ctx context.Context, configPath string, out io.Writer, now time.Time) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.HostTLS == nil {
+		return nil
+	}
+	opts, err := hosttls.OptionsFromConfig(cfg)
+	if err != nil || opts.Issuer != config.HostTLSIssuerInternalCA {
+		return err
+	}
+	targets, err := hosttls.Targets(cfg, nil)
+	if err != nil || len(targets) == 0 {
+		return err
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	ca, err := loadClusterCA(ctx, configDir)
+	if err != nil {
+		return err
+	}
+
+	input := hosttls.ApplyInput{Options: opts, CA: ca}
+	var failed []string
+	for _, target := range targets {
+		conn, err := statushelpers.ConnectToHost(target.Host, configDir, cfg.Cluster.Name)
+		if err != nil {
+			fmt.Fprintf(out, "host TLS renewal: %s: %v\n", target.Host.Hostname, err)
+			failed = append(failed, target.Host.Hostname)
+			continue
+		}
+		input.Target = target
+		renewed, err := hosttls.RenewDue(&statushelpers.SSHExecutorAdapter{Conn: conn}, input, now)
+		conn.Close()
+		for _, svc := range renewed {
+			fmt.Fprintf(out, "host TLS renewal: renewed %s on %s\n", svc.FQDN(opts.Domain), target.Host.Hostname)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "host TLS renewal: %s: %v\n", target.Host.Hostname, err)
+			failed = append(failed, target.Host.Hostname)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed on %d host(s)", len(failed))
+	}
+	return nil
+}
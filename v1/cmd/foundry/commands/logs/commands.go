@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/urfave/cli/v3"
@@ -23,7 +24,10 @@ var Command = &cli.Command{
 	ArgsUsage: "[pod-name]",
 	Description: `View logs from Kubernetes pods.
 
-Use Grafana Explore to query historical logs in Loki.
+These come from the Kubernetes API, so only running pods (and the previous
+instance of their containers) have logs. Use 'foundry logs query' and
+'foundry logs tail' to search Loki, which also keeps the logs of deleted
+pods and of the hosts.
 
 Examples:
   foundry logs grafana-0                    # View logs from pod
@@ -31,7 +35,13 @@ Examples:
   foundry logs -l app=grafana               # View logs by label
   foundry logs grafana-0 -f                 # Follow/stream logs
   foundry logs grafana-0 --tail 100         # Last 100 lines
-  foundry logs grafana-0 --previous         # Previous container logs`,
+  foundry logs grafana-0 --previous         # Previous container logs
+  foundry logs query -n monitoring --since 2h '|= "error"'
+  foundry logs tail '{host="node1", unit="k3s.service"}'`,
+	Commands: []*cli.Command{
+		QueryCommand,
+		TailCommand,
+	},
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:    "namespace",
@@ -258,6 +268,21 @@ func parseSimpleDuration(s string) (duration, error) {
 
 // getK8sClient creates a Kubernetes client from kubeconfig
 func getK8sClient() (*kubernetes.Clientset, error) {
+	config, err := getRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return clientset, nil
+}
+
+// getRESTConfig loads the API server config from the foundry kubeconfig
+func getRESTConfig() (*rest.Config, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
@@ -273,10 +298,5 @@ func getK8sClient() (*kubernetes.Clientset, error) {
 		return nil, fmt.Errorf("failed to build config from kubeconfig: %w", err)
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
-	}
-
-	return clientset, nil
+	return config, nil
 }
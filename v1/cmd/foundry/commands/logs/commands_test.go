@@ -2,6 +2,8 @@ package logs
 
 import (
 	"testing"
+
+	"github.com/urfave/cli/v3"
 )
 
 func TestCommand(t *testing.T) {
@@ -157,3 +159,27 @@ func TestDefaultNamespace(t *testing.T) {
 	}
 	t.Error("namespace flag not found")
 }
+
+func TestSubcommands(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sub := range Command.Commands {
+		names[sub.Name] = true
+	}
+	for _, expected := range []string{"query", "tail"} {
+		if !names[expected] {
+			t.Errorf("Missing subcommand: %s", expected)
+		}
+	}
+
+	for _, sub := range []*cli.Command{QueryCommand, TailCommand} {
+		flagMap := make(map[string]bool)
+		for _, flag := range sub.Flags {
+			flagMap[flag.Names()[0]] = true
+		}
+		for _, expected := range []string{"namespace", "selector", "container", "host", "unit", "since", "limit", "output", "loki-url"} {
+			if !flagMap[expected] {
+				t.Errorf("%s: missing flag --%s", sub.Name, expected)
+			}
+		}
+	}
+}
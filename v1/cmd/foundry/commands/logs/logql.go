@@ -0,0 +1,213 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// streamFilter holds the shorthand flags that narrow a LogQL query
+type streamFilter struct {
+	Namespace string
+	Selector  string // Kubernetes label selector, e.g. "app=grafana,tier!=db"
+	Container string
+	Host      string
+	Unit      string
+}
+
+// buildLogQL combines a LogQL query with the shorthand flags. The flags'
+// matchers are added to the query's first stream selector; a query that is
+// only a pipeline (e.g. `|= "error"`) gets a selector built from the flags.
+func buildLogQL(query string, filter streamFilter) (string, error) {
+	matchers, err := filter.matchers()
+	if err != nil {
+		return "", err
+	}
+	query = strings.TrimSpace(query)
+
+	if query == "" || strings.HasPrefix(query, "|") {
+		if len(matchers) == 0 {
+			return "", fmt.Errorf("a LogQL query or at least one of --namespace, --selector, --container, --host or --unit is required")
+		}
+		selector := "{" + strings.Join(matchers, ", ") + "}"
+		if query == "" {
+			return selector, nil
+		}
+		return selector + " " + query, nil
+	}
+
+	if len(matchers) == 0 {
+		return query, nil
+	}
+
+	open, end := findStreamSelector(query)
+	if open < 0 {
+		return "", fmt.Errorf("query has no stream selector to add --namespace, --selector, --container, --host or --unit to")
+	}
+	existing := strings.TrimSpace(query[open+1 : end])
+	combined := strings.Join(matchers, ", ")
+	if existing != "" {
+		combined = existing + ", " + combined
+	}
+	return query[:open+1] + combined + query[end:], nil
+}
+
+// findStreamSelector returns the positions of the braces of the first
+// stream selector in a LogQL query, skipping quoted strings
+func findStreamSelector(query string) (int, int) {
+	open := -1
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '{' && open < 0:
+			open = i
+		case c == '}' && open >= 0:
+			return open, i
+		}
+	}
+	return -1, -1
+}
+
+// matchers returns the LogQL label matchers for the flags that are set
+func (f streamFilter) matchers() ([]string, error) {
+	var matchers []string
+	if f.Namespace != "" {
+		matchers = append(matchers, "namespace="+strconv.Quote(f.Namespace))
+	}
+	if f.Selector != "" {
+		selector, err := selectorMatchers(f.Selector)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, selector...)
+	}
+	if f.Container != "" {
+		matchers = append(matchers, "container="+strconv.Quote(f.Container))
+	}
+	if f.Host != "" {
+		matchers = append(matchers, "host="+strconv.Quote(f.Host))
+	}
+	if f.Unit != "" {
+		unit := f.Unit
+		if !strings.Contains(unit, ".") {
+			unit += ".service"
+		}
+		matchers = append(matchers, "unit="+strconv.Quote(unit))
+	}
+	return matchers, nil
+}
+
+// setRequirement matches "key in (a, b)" and "key notin (a, b)"
+var setRequirement = regexp.MustCompile(`^([^\s!=]+)\s+(in|notin)\s+\(([^)]*)\)$`)
+
+// podLabels maps the Kubernetes pod labels the log collector keeps to the
+// Loki labels it stores them as
+var podLabels = map[string]string{
+	"app.kubernetes.io/name":      "app",
+	"app.kubernetes.io/instance":  "instance",
+	"app.kubernetes.io/component": "component",
+}
+
+// selectorMatchers translates a Kubernetes label selector into LogQL
+// matchers. The well-known pod labels map to the labels the collector
+// stores them as; other names are sanitized (e.g. example.com/tier becomes
+// example_com_tier). Existence requirements have no LogQL equivalent.
+func selectorMatchers(selector string) ([]string, error) {
+	var matchers []string
+	for _, req := range splitSelector(selector) {
+		if m := setRequirement.FindStringSubmatch(req); m != nil {
+			var values []string
+			for _, v := range strings.Split(m[3], ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, regexp.QuoteMeta(v))
+				}
+			}
+			op := "=~"
+			if m[2] == "notin" {
+				op = "!~"
+			}
+			matchers = append(matchers, lokiLabelName(m[1])+op+strconv.Quote(strings.Join(values, "|")))
+			continue
+		}
+
+		var key, op, value string
+		switch {
+		case strings.Contains(req, "!="):
+			key, value, _ = strings.Cut(req, "!=")
+			op = "!="
+		case strings.Contains(req, "=="):
+			key, value, _ = strings.Cut(req, "==")
+			op = "="
+		case strings.Contains(req, "="):
+			key, value, _ = strings.Cut(req, "=")
+			op = "="
+		default:
+			return nil, fmt.Errorf("selector requirement %q has no LogQL equivalent (use key=value, key!=value, key in (...) or key notin (...))", req)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid selector requirement %q", req)
+		}
+		matchers = append(matchers, lokiLabelName(key)+op+strconv.Quote(strings.TrimSpace(value)))
+	}
+	return matchers, nil
+}
+
+// splitSelector splits a label selector on the commas that separate
+// requirements, not those inside "in (...)" value sets
+func splitSelector(selector string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(selector[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(selector[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// lokiLabelName returns the Loki label a Kubernetes pod label is stored as
+func lokiLabelName(name string) string {
+	if label, ok := podLabels[name]; ok {
+		return label
+	}
+	return sanitizeLabelName(name)
+}
+
+// sanitizeLabelName replaces the characters Loki doesn't allow in label
+// names with underscores
+func sanitizeLabelName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
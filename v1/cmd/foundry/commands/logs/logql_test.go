@@ -0,0 +1,133 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLogQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		filter streamFilter
+		want   string
+	}{
+		{
+			name:  "query without flags",
+			query: `{namespace="apps"} |= "error"`,
+			want:  `{namespace="apps"} |= "error"`,
+		},
+		{
+			name:   "flags only",
+			filter: streamFilter{Namespace: "monitoring", Selector: "app=grafana"},
+			want:   `{namespace="monitoring", app="grafana"}`,
+		},
+		{
+			name:   "pipeline only",
+			query:  `|= "error"`,
+			filter: streamFilter{Namespace: "apps"},
+			want:   `{namespace="apps"} |= "error"`,
+		},
+		{
+			name:   "flags added to the selector",
+			query:  `{job="apps/api"} |= "timeout"`,
+			filter: streamFilter{Container: "api"},
+			want:   `{job="apps/api", container="api"} |= "timeout"`,
+		},
+		{
+			name:   "flags added to the selector of a metric query",
+			query:  `sum(count_over_time({job=~".+"}[5m]))`,
+			filter: streamFilter{Namespace: "apps"},
+			want:   `sum(count_over_time({job=~".+", namespace="apps"}[5m]))`,
+		},
+		{
+			name:   "braces in a quoted string are skipped",
+			query:  `{app="api"} |= "{" `,
+			filter: streamFilter{Namespace: "apps"},
+			want:   `{app="api", namespace="apps"} |= "{"`,
+		},
+		{
+			name:   "empty selector",
+			query:  `{}`,
+			filter: streamFilter{Namespace: "apps"},
+			want:   `{namespace="apps"}`,
+		},
+		{
+			name:   "host and unit",
+			filter: streamFilter{Host: "node1", Unit: "k3s"},
+			want:   `{host="node1", unit="k3s.service"}`,
+		},
+		{
+			name:   "unit with a suffix is kept",
+			filter: streamFilter{Unit: "foundry-zot.timer"},
+			want:   `{unit="foundry-zot.timer"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildLogQL(tt.query, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildLogQL_Errors(t *testing.T) {
+	_, err := buildLogQL("", streamFilter{})
+	assert.ErrorContains(t, err, "required")
+
+	_, err = buildLogQL(`|= "error"`, streamFilter{})
+	assert.ErrorContains(t, err, "required")
+
+	_, err = buildLogQL(`vector(1)`, streamFilter{Namespace: "apps"})
+	assert.ErrorContains(t, err, "no stream selector")
+
+	_, err = buildLogQL("", streamFilter{Selector: "app"})
+	assert.ErrorContains(t, err, "no LogQL equivalent")
+}
+
+func TestSelectorMatchers(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"app=grafana", []string{`app="grafana"`}},
+		{"app==grafana", []string{`app="grafana"`}},
+		{"tier!=db", []string{`tier!="db"`}},
+		{"app.kubernetes.io/name=loki", []string{`app="loki"`}},
+		{"app.kubernetes.io/instance=loki", []string{`instance="loki"`}},
+		{"example.com/tier=web", []string{`example_com_tier="web"`}},
+		{"env in (prod, staging)", []string{`env=~"prod|staging"`}},
+		{"env notin (dev)", []string{`env!~"dev"`}},
+		{"app in (a.b), tier=web", []string{`app=~"a\\.b"`, `tier="web"`}},
+		{"app=api, tier != db ", []string{`app="api"`, `tier!="db"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := selectorMatchers(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelectorMatchers_Existence(t *testing.T) {
+	_, err := selectorMatchers("app")
+	assert.Error(t, err)
+
+	_, err = selectorMatchers("!app")
+	assert.Error(t, err)
+
+	_, err = selectorMatchers("=value")
+	assert.Error(t, err)
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	assert.Equal(t, "example_com_tier", sanitizeLabelName("example.com/tier"))
+	assert.Equal(t, "_app", sanitizeLabelName("1app"))
+	assert.Equal(t, "app_name", sanitizeLabelName("app-name"))
+}
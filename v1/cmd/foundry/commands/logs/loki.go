@@ -0,0 +1,288 @@
+package logs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+)
+
+// lokiGatewayService is the Loki gateway the API server proxy reaches
+const lokiGatewayService = "loki-gateway:80"
+
+// lokiClient talks to Loki's query and tail APIs
+type lokiClient struct {
+	// baseURL is Loki's root, e.g. https://loki.example.com
+	baseURL string
+	http    *http.Client
+	ws      *websocket.Dialer
+	// header is sent with the tail handshake (API server credentials)
+	header http.Header
+}
+
+// logEntry is one log line and the labels of its stream
+type logEntry struct {
+	Time   time.Time
+	Labels map[string]string
+	Line   string
+}
+
+// lokiStream is a stream in a query or tail response
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// queryResponse is the response of /loki/api/v1/query_range
+type queryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// tailResponse is one message of the /loki/api/v1/tail websocket
+type tailResponse struct {
+	Streams        []lokiStream `json:"streams"`
+	DroppedEntries []struct {
+		Labels    map[string]string `json:"labels"`
+		Timestamp string            `json:"timestamp"`
+	} `json:"dropped_entries"`
+}
+
+// newLokiClient picks how to reach Loki: --loki-url if set, otherwise the
+// Loki gateway ingress on the cluster VIP when the stack config has one,
+// otherwise the gateway service through the Kubernetes API server proxy.
+func newLokiClient(ctx context.Context, lokiURL, configFlag, namespace string) (*lokiClient, error) {
+	if lokiURL != "" {
+		if _, err := url.Parse(lokiURL); err != nil {
+			return nil, fmt.Errorf("invalid --loki-url: %w", err)
+		}
+		return &lokiClient{
+			baseURL: strings.TrimSuffix(lokiURL, "/"),
+			http:    http.DefaultClient,
+			ws:      websocket.DefaultDialer,
+		}, nil
+	}
+
+	restConfig, err := getRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if stackConfig := loadStackConfig(configFlag); stackConfig != nil &&
+		stackConfig.Cluster.PrimaryDomain != "" && stackConfig.Cluster.VIP != "" {
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+		caPEM, err := clusterCACert(ctx, clientset)
+		if err != nil {
+			return nil, err
+		}
+		return ingressClient(stackConfig.Cluster.PrimaryDomain, stackConfig.Cluster.VIP, caPEM)
+	}
+
+	return proxyClient(restConfig, namespace)
+}
+
+// ingressClient reaches Loki at https://loki.<domain>, connecting to the
+// cluster VIP directly so the hostname needn't resolve, and trusting the
+// internal CA that signed the ingress certificate
+func ingressClient(domain, vip string, caPEM []byte) (*lokiClient, error) {
+	// Keep the system roots for ingresses with publicly trusted certificates
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("cluster CA certificate is not valid PEM")
+	}
+	tlsConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	lokiHost := "loki." + domain
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil && host == lokiHost {
+			addr = net.JoinHostPort(vip, port)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	return &lokiClient{
+		baseURL: "https://" + lokiHost,
+		http: &http.Client{Transport: &http.Transport{
+			DialContext:     dial,
+			TLSClientConfig: tlsConfig,
+		}},
+		ws: &websocket.Dialer{
+			NetDialContext:   dial,
+			TLSClientConfig:  tlsConfig,
+			HandshakeTimeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// proxyClient reaches the Loki gateway service through the Kubernetes API
+// server's service proxy, which needs nothing beyond the kubeconfig
+func proxyClient(restConfig *rest.Config, namespace string) (*lokiClient, error) {
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API server client: %w", err)
+	}
+	tlsConfig, err := rest.TLSConfigFor(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API server TLS config: %w", err)
+	}
+	header := http.Header{}
+	if restConfig.BearerToken != "" {
+		header.Set("Authorization", "Bearer "+restConfig.BearerToken)
+	}
+
+	return &lokiClient{
+		baseURL: fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s/proxy",
+			strings.TrimSuffix(restConfig.Host, "/"), namespace, lokiGatewayService),
+		http: httpClient,
+		ws: &websocket.Dialer{
+			TLSClientConfig:  tlsConfig,
+			HandshakeTimeout: 10 * time.Second,
+		},
+		header: header,
+	}, nil
+}
+
+// queryRange runs a LogQL query over [start, end]
+func (c *lokiClient) queryRange(ctx context.Context, query string, start, end time.Time, limit int) (*queryResponse, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("direction", "backward")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/loki/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Loki: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query failed (%s): %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result queryResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query failed: status %q", result.Status)
+	}
+	return &result, nil
+}
+
+// tail streams the lines matching query from start until ctx is done,
+// calling handle for each batch
+func (c *lokiClient) tail(ctx context.Context, query string, start time.Time, limit int, handle func([]logEntry, int) error) error {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+
+	tailURL := strings.Replace(c.baseURL, "http", "ws", 1) + "/loki/api/v1/tail?" + params.Encode()
+	conn, resp, err := c.ws.DialContext(ctx, tailURL, c.header)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("failed to start tail (%s): %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+		return fmt.Errorf("failed to start tail: %w", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		var msg tailResponse
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("tail stopped: %w", err)
+		}
+		if err := handle(streamEntries(msg.Streams), len(msg.DroppedEntries)); err != nil {
+			return err
+		}
+	}
+}
+
+// streamEntries flattens streams into entries, oldest first
+func streamEntries(streams []lokiStream) []logEntry {
+	var entries []logEntry
+	for _, s := range streams {
+		for _, v := range s.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, logEntry{Time: time.Unix(0, ns), Labels: s.Stream, Line: v[1]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries
+}
+
+// clusterCACert reads the internal CA certificate that signs the ingress
+// certificates
+func clusterCACert(ctx context.Context, clientset kubernetes.Interface) ([]byte, error) {
+	secret, err := clientset.CoreV1().Secrets(hosttls.ClusterCANamespace).Get(ctx, hosttls.ClusterCASecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cluster CA %s/%s (use --loki-url to reach Loki another way): %w",
+			hosttls.ClusterCANamespace, hosttls.ClusterCASecret, err)
+	}
+	return secret.Data[corev1.TLSCertKey], nil
+}
+
+// loadStackConfig loads the stack config, or returns nil if there is none
+func loadStackConfig(configFlag string) *config.Config {
+	configPath, err := config.FindConfig(configFlag)
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
@@ -0,0 +1,110 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestLokiClient_QueryRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/query_range", r.URL.Path)
+		assert.Equal(t, `{namespace="apps"}`, r.URL.Query().Get("query"))
+		assert.Equal(t, "50", r.URL.Query().Get("limit"))
+		assert.Equal(t, "backward", r.URL.Query().Get("direction"))
+		assert.Equal(t, "1000000000", r.URL.Query().Get("start"))
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"pod":"a"},"values":[["3000000000","third"],["1000000000","first"]]},
+			{"stream":{"pod":"b"},"values":[["2000000000","second"]]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client, err := newLokiClient(context.Background(), server.URL+"/", "", "")
+	require.NoError(t, err)
+
+	result, err := client.queryRange(context.Background(), `{namespace="apps"}`, time.Unix(1, 0), time.Unix(10, 0), 50)
+	require.NoError(t, err)
+	assert.Equal(t, "streams", result.Data.ResultType)
+
+	var streams []lokiStream
+	require.NoError(t, json.Unmarshal(result.Data.Result, &streams))
+	entries := streamEntries(streams)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"first", "second", "third"}, []string{entries[0].Line, entries[1].Line, entries[2].Line})
+	assert.Equal(t, "b", entries[1].Labels["pod"])
+}
+
+func TestLokiClient_QueryRangeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "parse error at line 1, col 2: syntax error", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := newLokiClient(context.Background(), server.URL, "", "")
+	require.NoError(t, err)
+
+	_, err = client.queryRange(context.Background(), "{", time.Unix(1, 0), time.Unix(10, 0), 10)
+	assert.ErrorContains(t, err, "syntax error")
+}
+
+func TestLokiClient_Tail(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/tail", r.URL.Path)
+		assert.Equal(t, `{host="node1"}`, r.URL.Query().Get("query"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		conn.WriteJSON(map[string]any{
+			"streams": []map[string]any{
+				{"stream": map[string]string{"host": "node1"}, "values": [][2]string{{"1000000000", "hello"}}},
+			},
+			"dropped_entries": []map[string]any{{"labels": map[string]string{"host": "node1"}, "timestamp": "1"}},
+		})
+		// Hold the connection open until the client goes away
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	client, err := newLokiClient(context.Background(), server.URL, "", "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lines []string
+	var dropped int
+	err = client.tail(ctx, `{host="node1"}`, time.Now(), 10, func(entries []logEntry, n int) error {
+		for _, e := range entries {
+			lines = append(lines, e.Line)
+		}
+		dropped += n
+		cancel()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, lines)
+	assert.Equal(t, 1, dropped)
+}
+
+func TestProxyClient(t *testing.T) {
+	client, err := proxyClient(&rest.Config{Host: "https://10.0.0.10:6443/", BearerToken: "token"}, "monitoring")
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.10:6443/api/v1/namespaces/monitoring/services/loki-gateway:80/proxy", client.baseURL)
+	assert.Equal(t, "Bearer token", client.header.Get("Authorization"))
+}
+
+func TestIngressClient(t *testing.T) {
+	_, err := ingressClient("example.com", "10.0.0.100", []byte("not a certificate"))
+	assert.ErrorContains(t, err, "not valid PEM")
+}
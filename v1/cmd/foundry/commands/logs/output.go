@@ -0,0 +1,177 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats for query and tail
+const (
+	formatText = "text"
+	formatRaw  = "raw"
+	formatJSON = "json"
+)
+
+const (
+	ansiReset = "\033[0m"
+	ansiDim   = "\033[2m"
+)
+
+// sourceColors are cycled through so each stream keeps one colour
+var sourceColors = []string{"\033[36m", "\033[33m", "\033[32m", "\033[35m", "\033[34m", "\033[91m"}
+
+// printer writes log entries and metric samples in one output format
+type printer struct {
+	out    io.Writer
+	format string
+	color  bool
+}
+
+// newPrinter validates the output format. Colour is only used for text
+// written to a terminal, and never when NO_COLOR is set.
+func newPrinter(out io.Writer, format string, noColor bool) (*printer, error) {
+	switch format {
+	case formatText, formatRaw, formatJSON:
+	default:
+		return nil, fmt.Errorf("invalid --output %q (use text, raw or json)", format)
+	}
+
+	color := false
+	if f, ok := out.(*os.File); ok && format == formatText && !noColor && os.Getenv("NO_COLOR") == "" {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			color = true
+		}
+	}
+	return &printer{out: out, format: format, color: color}, nil
+}
+
+// entry prints one log line
+func (p *printer) entry(e logEntry) error {
+	var err error
+	switch p.format {
+	case formatRaw:
+		_, err = fmt.Fprintln(p.out, e.Line)
+	case formatJSON:
+		var data []byte
+		data, err = json.Marshal(struct {
+			Timestamp string            `json:"timestamp"`
+			Labels    map[string]string `json:"labels"`
+			Line      string            `json:"line"`
+		}{e.Time.UTC().Format(time.RFC3339Nano), e.Labels, e.Line})
+		if err == nil {
+			_, err = fmt.Fprintln(p.out, string(data))
+		}
+	default:
+		timestamp := e.Time.Local().Format("2006-01-02T15:04:05.000Z07:00")
+		source := streamSource(e.Labels)
+		if p.color {
+			timestamp = ansiDim + timestamp + ansiReset
+			source = sourceColor(source) + source + ansiReset
+		}
+		_, err = fmt.Fprintf(p.out, "%s %s %s\n", timestamp, source, e.Line)
+	}
+	return err
+}
+
+// metricSeries is a series in a matrix or vector result
+type metricSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][2]any          `json:"values"`
+	Value  [2]any            `json:"value"`
+}
+
+// samples prints the result of a metric query (e.g. count_over_time)
+func (p *printer) samples(resultType string, result json.RawMessage) error {
+	var series []metricSeries
+	if err := json.Unmarshal(result, &series); err != nil {
+		return fmt.Errorf("failed to parse %s result: %w", resultType, err)
+	}
+
+	for _, s := range series {
+		points := s.Values
+		if resultType == "vector" {
+			points = [][2]any{s.Value}
+		}
+		for _, point := range points {
+			ts, _ := point[0].(float64)
+			value, _ := point[1].(string)
+			when := time.Unix(0, int64(ts*float64(time.Second)))
+
+			var err error
+			switch p.format {
+			case formatRaw:
+				_, err = fmt.Fprintln(p.out, value)
+			case formatJSON:
+				var data []byte
+				data, err = json.Marshal(struct {
+					Timestamp string            `json:"timestamp"`
+					Labels    map[string]string `json:"labels"`
+					Value     string            `json:"value"`
+				}{when.UTC().Format(time.RFC3339Nano), s.Metric, value})
+				if err == nil {
+					_, err = fmt.Fprintln(p.out, string(data))
+				}
+			default:
+				timestamp := when.Local().Format(time.RFC3339)
+				labels := formatLabels(s.Metric)
+				if p.color {
+					timestamp = ansiDim + timestamp + ansiReset
+					labels = sourceColor(labels) + labels + ansiReset
+				}
+				_, err = fmt.Fprintf(p.out, "%s %s %s\n", timestamp, labels, value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// streamSource names where a line came from: namespace/pod[container] for
+// pod logs, host and unit for host logs, otherwise the stream's labels
+func streamSource(labels map[string]string) string {
+	if labels["namespace"] != "" && labels["pod"] != "" {
+		source := labels["namespace"] + "/" + labels["pod"]
+		if labels["container"] != "" {
+			source += "[" + labels["container"] + "]"
+		}
+		return source
+	}
+	if labels["host"] != "" {
+		if labels["unit"] != "" {
+			return labels["host"] + " " + labels["unit"]
+		}
+		return labels["host"]
+	}
+	return formatLabels(labels)
+}
+
+// formatLabels renders labels as a LogQL stream selector
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strconv.Quote(labels[k]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// sourceColor picks a stable colour for a stream source
+func sourceColor(source string) string {
+	h := fnv.New32a()
+	h.Write([]byte(source))
+	return sourceColors[h.Sum32()%uint32(len(sourceColors))]
+}
@@ -0,0 +1,97 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPrinter(t *testing.T) {
+	var buf bytes.Buffer
+
+	p, err := newPrinter(&buf, formatText, false)
+	require.NoError(t, err)
+	assert.False(t, p.color, "color is only used on a terminal")
+
+	_, err = newPrinter(&buf, "yaml", false)
+	assert.ErrorContains(t, err, "invalid --output")
+}
+
+func TestPrinterEntry(t *testing.T) {
+	entry := logEntry{
+		Time:   time.Date(2026, 3, 1, 12, 0, 0, 500000000, time.UTC),
+		Labels: map[string]string{"namespace": "monitoring", "pod": "grafana-0", "container": "grafana"},
+		Line:   "level=error msg=failed",
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, formatRaw, false)
+		require.NoError(t, err)
+		require.NoError(t, p.entry(entry))
+		assert.Equal(t, "level=error msg=failed\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, formatJSON, false)
+		require.NoError(t, err)
+		require.NoError(t, p.entry(entry))
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, "2026-03-01T12:00:00.5Z", got["timestamp"])
+		assert.Equal(t, "level=error msg=failed", got["line"])
+		assert.Equal(t, "grafana-0", got["labels"].(map[string]any)["pod"])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, formatText, false)
+		require.NoError(t, err)
+		require.NoError(t, p.entry(entry))
+		assert.Contains(t, buf.String(), " monitoring/grafana-0[grafana] level=error msg=failed\n")
+		assert.NotContains(t, buf.String(), "\033[")
+	})
+
+	t.Run("text with color", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := &printer{out: &buf, format: formatText, color: true}
+		require.NoError(t, p.entry(entry))
+		assert.Contains(t, buf.String(), sourceColor("monitoring/grafana-0[grafana]")+"monitoring/grafana-0[grafana]"+ansiReset)
+	})
+}
+
+func TestPrinterSamples(t *testing.T) {
+	matrix := json.RawMessage(`[{"metric":{"namespace":"apps"},"values":[[1772366400,"3"],[1772366460,"5"]]}]`)
+	vector := json.RawMessage(`[{"metric":{"namespace":"apps"},"value":[1772366400.5,"7"]}]`)
+
+	var buf bytes.Buffer
+	p, err := newPrinter(&buf, formatRaw, false)
+	require.NoError(t, err)
+	require.NoError(t, p.samples("matrix", matrix))
+	assert.Equal(t, "3\n5\n", buf.String())
+
+	buf.Reset()
+	p, err = newPrinter(&buf, formatJSON, false)
+	require.NoError(t, err)
+	require.NoError(t, p.samples("vector", vector))
+	assert.JSONEq(t, `{"timestamp":"2026-03-01T12:00:00.5Z","labels":{"namespace":"apps"},"value":"7"}`, buf.String())
+
+	buf.Reset()
+	p, err = newPrinter(&buf, formatText, false)
+	require.NoError(t, err)
+	require.NoError(t, p.samples("vector", vector))
+	assert.Contains(t, buf.String(), ` {namespace="apps"} 7`)
+}
+
+func TestStreamSource(t *testing.T) {
+	assert.Equal(t, "apps/api-7d9f", streamSource(map[string]string{"namespace": "apps", "pod": "api-7d9f"}))
+	assert.Equal(t, "node1 k3s.service", streamSource(map[string]string{"host": "node1", "unit": "k3s.service", "role": "cluster-control-plane"}))
+	assert.Equal(t, "node1", streamSource(map[string]string{"host": "node1"}))
+	assert.Equal(t, `{app="x", job="y"}`, streamSource(map[string]string{"job": "y", "app": "x"}))
+}
@@ -0,0 +1,226 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// QueryCommand searches the logs stored in Loki
+var QueryCommand = &cli.Command{
+	Name:      "query",
+	Usage:     "Search logs in Loki with LogQL",
+	ArgsUsage: "[logql]",
+	Description: `Search the logs stored in Loki, including logs of deleted and
+rescheduled pods and the systemd journal of every host.
+
+The shorthand flags add matchers to the query's stream selector, so the
+query can be only a filter, or omitted entirely.
+
+Examples:
+  foundry logs query '{namespace="monitoring"} |= "error"'
+  foundry logs query -n monitoring -l app=grafana --since 2h
+  foundry logs query --host node1 --unit k3s '|~ "(?i)timeout"'
+  foundry logs query 'sum by (namespace)(count_over_time({job=~".+"}[5m]))'
+  foundry logs query -n apps --limit 1000 -o json`,
+	Flags: append(lokiFlags(),
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "How far back to search (e.g., 30m, 2h, 7d)",
+			Value: "1h",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of lines to return (the newest are kept)",
+			Value: 100,
+		},
+	),
+	Action: runQuery,
+}
+
+// TailCommand follows new log lines as Loki receives them
+var TailCommand = &cli.Command{
+	Name:      "tail",
+	Usage:     "Follow logs from Loki with LogQL",
+	ArgsUsage: "[logql]",
+	Description: `Follow log lines as Loki receives them, across every pod and host
+the query matches. Press Ctrl+C to stop.
+
+Examples:
+  foundry logs tail '{namespace="apps"} |= "error"'
+  foundry logs tail -l app.kubernetes.io/name=grafana
+  foundry logs tail --host node1 --since 10m`,
+	Flags: append(lokiFlags(),
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Also show lines from this far back before following (e.g., 10m)",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of earlier lines to show with --since",
+			Value: 100,
+		},
+	),
+	Action: runTail,
+}
+
+// lokiFlags are the flags shared by query and tail
+func lokiFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "namespace",
+			Aliases: []string{"n"},
+			Usage:   "Only logs from pods in this namespace",
+		},
+		&cli.StringFlag{
+			Name:    "selector",
+			Aliases: []string{"l"},
+			Usage:   "Only logs from pods matching this label selector (e.g., app=grafana)",
+		},
+		&cli.StringFlag{
+			Name:    "container",
+			Aliases: []string{"c"},
+			Usage:   "Only logs from this container",
+		},
+		&cli.StringFlag{
+			Name:  "host",
+			Usage: "Only journal logs from this host",
+		},
+		&cli.StringFlag{
+			Name:  "unit",
+			Usage: "Only journal logs from this systemd unit (e.g., k3s)",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Output format: text, raw or json",
+			Value:   formatText,
+		},
+		&cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable colored output",
+		},
+		&cli.StringFlag{
+			Name:  "loki-url",
+			Usage: "Loki URL to use instead of the gateway ingress (e.g., http://localhost:3100)",
+		},
+		&cli.StringFlag{
+			Name:  "loki-namespace",
+			Usage: "Namespace of the Loki gateway, when reached through the API server",
+			Value: "monitoring",
+		},
+	}
+}
+
+// lokiQuery builds the LogQL query from the argument and shorthand flags
+func lokiQuery(cmd *cli.Command) (string, error) {
+	return buildLogQL(strings.Join(cmd.Args().Slice(), " "), streamFilter{
+		Namespace: cmd.String("namespace"),
+		Selector:  cmd.String("selector"),
+		Container: cmd.String("container"),
+		Host:      cmd.String("host"),
+		Unit:      cmd.String("unit"),
+	})
+}
+
+func runQuery(ctx context.Context, cmd *cli.Command) error {
+	query, err := lokiQuery(cmd)
+	if err != nil {
+		return err
+	}
+	since, err := parseDuration(cmd.String("since"))
+	if err != nil {
+		return fmt.Errorf("invalid --since value: %w", err)
+	}
+	limit := int(cmd.Int("limit"))
+	if limit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	p, err := newPrinter(os.Stdout, cmd.String("output"), cmd.Bool("no-color"))
+	if err != nil {
+		return err
+	}
+
+	client, err := newLokiClient(ctx, cmd.String("loki-url"), cmd.String("config"), cmd.String("loki-namespace"))
+	if err != nil {
+		return err
+	}
+
+	end := time.Now()
+	start := end.Add(-time.Duration(since.seconds) * time.Second)
+	result, err := client.queryRange(ctx, query, start, end, limit)
+	if err != nil {
+		return err
+	}
+
+	return printResult(p, result)
+}
+
+// printResult prints the lines of a log query oldest first, or the samples
+// of a metric query
+func printResult(p *printer, result *queryResponse) error {
+	if result.Data.ResultType != "streams" {
+		return p.samples(result.Data.ResultType, result.Data.Result)
+	}
+
+	var streams []lokiStream
+	if err := json.Unmarshal(result.Data.Result, &streams); err != nil {
+		return fmt.Errorf("failed to parse streams: %w", err)
+	}
+	entries := streamEntries(streams)
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "No log lines found")
+		return nil
+	}
+	for _, e := range entries {
+		if err := p.entry(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runTail(ctx context.Context, cmd *cli.Command) error {
+	query, err := lokiQuery(cmd)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if s := cmd.String("since"); s != "" {
+		since, err := parseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid --since value: %w", err)
+		}
+		start = start.Add(-time.Duration(since.seconds) * time.Second)
+	}
+	p, err := newPrinter(os.Stdout, cmd.String("output"), cmd.Bool("no-color"))
+	if err != nil {
+		return err
+	}
+
+	client, err := newLokiClient(ctx, cmd.String("loki-url"), cmd.String("config"), cmd.String("loki-namespace"))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	return client.tail(ctx, query, start, int(cmd.Int("limit")), func(entries []logEntry, dropped int) error {
+		for _, e := range entries {
+			if err := p.entry(e); err != nil {
+				return err
+			}
+		}
+		if dropped > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: Loki dropped %d lines (the tail could not keep up)\n", dropped)
+		}
+		return nil
+	})
+}
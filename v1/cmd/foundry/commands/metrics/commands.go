@@ -12,8 +12,10 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/urfave/cli/v3"
@@ -325,17 +327,28 @@ func runTargets(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
-// getPrometheusURL retrieves the Prometheus URL from the cluster
+// getPrometheusURL finds the Prometheus service in namespace and returns its
+// in-cluster URL
 func getPrometheusURL(ctx context.Context, namespace string) (string, error) {
-	client, err := getK8sClient()
+	client, err := K8sClient()
 	if err != nil {
 		return "", err
 	}
 
+	name, port, err := FindPrometheus(ctx, client, namespace)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", name, namespace, port), nil
+}
+
+// FindPrometheus returns the name and web port of the Prometheus service in
+// namespace
+func FindPrometheus(ctx context.Context, client kubernetes.Interface, namespace string) (string, int32, error) {
 	// Try to find Prometheus service
 	services, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to list services: %w", err)
+		return "", 0, fmt.Errorf("failed to list services: %w", err)
 	}
 
 	// Look for Prometheus service (various naming conventions)
@@ -346,23 +359,94 @@ func getPrometheusURL(ctx context.Context, namespace string) (string, error) {
 		"kube-prometheus-stack-prometheus",
 	}
 
+	// Known names first, so services such as kube-prometheus-stack-alertmanager
+	// don't win on a partial match
+	for _, name := range prometheusNames {
+		for _, svc := range services.Items {
+			if svc.Name == name {
+				return svc.Name, prometheusPort(svc), nil
+			}
+		}
+	}
 	for _, svc := range services.Items {
-		for _, name := range prometheusNames {
-			if svc.Name == name || strings.Contains(svc.Name, "prometheus") {
-				// Found Prometheus
-				port := int32(9090)
-				for _, p := range svc.Spec.Ports {
-					if p.Name == "http" || p.Name == "web" || p.Port == 9090 {
-						port = p.Port
-						break
-					}
+		if strings.Contains(svc.Name, "prometheus") {
+			return svc.Name, prometheusPort(svc), nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("Prometheus not found in namespace %q\n\nHint: Install Prometheus with: foundry component install prometheus", namespace)
+}
+
+// prometheusPort returns the web port of a Prometheus service
+func prometheusPort(svc corev1.Service) int32 {
+	for _, p := range svc.Spec.Ports {
+		if p.Name == "http" || p.Name == "web" || p.Port == 9090 {
+			return p.Port
+		}
+	}
+	return 9090
+}
+
+// FindAlertmanager returns the name and web port of the Alertmanager
+// service in namespace
+func FindAlertmanager(ctx context.Context, client kubernetes.Interface, namespace string) (string, int32, error) {
+	services, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	// Prefer the chart's service over the operator's headless one
+	for _, name := range []string{"kube-prometheus-stack-alertmanager", "alertmanager-operated"} {
+		for _, svc := range services.Items {
+			if svc.Name != name {
+				continue
+			}
+			port := int32(9093)
+			for _, p := range svc.Spec.Ports {
+				if p.Name == "http-web" || p.Name == "web" || p.Port == 9093 {
+					port = p.Port
+					break
 				}
-				return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", svc.Name, svc.Namespace, port), nil
 			}
+			return svc.Name, port, nil
 		}
 	}
 
-	return "", fmt.Errorf("Prometheus not found in namespace %q\n\nHint: Install Prometheus with: foundry component install prometheus", namespace)
+	return "", 0, fmt.Errorf("Alertmanager not found in namespace %q\n\nHint: Set alertmanager_enabled under components.prometheus and run: foundry component install prometheus", namespace)
+}
+
+// PostableAlert is an alert as the Alertmanager v2 API accepts it
+type PostableAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// PostAlerts sends alerts to the Alertmanager v2 API of service ("name:port",
+// see FindAlertmanager) through the API server's service proxy, which is
+// reachable from outside the cluster
+func PostAlerts(ctx context.Context, client rest.Interface, namespace, service string, alerts []PostableAlert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	response, err := client.Post().
+		Namespace(namespace).
+		Resource("services").
+		Name(service).
+		SubResource("proxy").
+		Suffix("api/v2/alerts").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		DoRaw(ctx)
+	if err != nil {
+		if msg := strings.TrimSpace(string(response)); msg != "" {
+			return fmt.Errorf("Alertmanager rejected the alert: %s", msg)
+		}
+		return fmt.Errorf("failed to reach Alertmanager: %w", err)
+	}
+	return nil
 }
 
 // prometheusResponse represents a Prometheus API response
@@ -436,8 +520,8 @@ func formatLabels(labels map[string]string) string {
 	return fmt.Sprintf("{%s}", strings.Join(parts, ", "))
 }
 
-// getK8sClient creates a Kubernetes client from kubeconfig
-func getK8sClient() (*kubernetes.Clientset, error) {
+// K8sClient creates a Kubernetes client from kubeconfig
+func K8sClient() (*kubernetes.Clientset, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
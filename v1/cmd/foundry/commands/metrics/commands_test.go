@@ -1,10 +1,18 @@
 package metrics
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/urfave/cli/v3"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 func TestCommand(t *testing.T) {
@@ -309,3 +317,50 @@ func TestNamespaceAlias(t *testing.T) {
 	}
 	t.Error("namespace flag not found")
 }
+
+func TestPostAlerts(t *testing.T) {
+	var received []PostableAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		want := "/api/v1/namespaces/monitoring/services/kube-prometheus-stack-alertmanager:9093/proxy/api/v2/alerts"
+		if r.URL.Path != want {
+			t.Errorf("path = %s, want %s", r.URL.Path, want)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode alerts: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	alert := PostableAlert{
+		Labels:   map[string]string{"alertname": "FoundryTestAlert"},
+		StartsAt: time.Now(),
+		EndsAt:   time.Now().Add(time.Minute),
+	}
+	if err := PostAlerts(context.Background(), restClient(t, server.URL), "monitoring", "kube-prometheus-stack-alertmanager:9093", []PostableAlert{alert}); err != nil {
+		t.Fatalf("PostAlerts() error = %v", err)
+	}
+	if len(received) != 1 || received[0].Labels["alertname"] != "FoundryTestAlert" {
+		t.Errorf("received = %+v, want the alert", received)
+	}
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid alert", http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+	err := PostAlerts(context.Background(), restClient(t, rejecting.URL), "monitoring", "kube-prometheus-stack-alertmanager:9093", []PostableAlert{alert})
+	if err == nil || !strings.Contains(err.Error(), "invalid alert") {
+		t.Errorf("PostAlerts() error = %v, want the rejection", err)
+	}
+}
+
+// restClient is a core API client for a test API server
+func restClient(t *testing.T, host string) rest.Interface {
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: host})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientset.CoreV1().RESTClient()
+}
@@ -0,0 +1,206 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/component/statushelpers"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
+	"github.com/urfave/cli/v3"
+)
+
+// Command is the top-level registry command
+var Command = &cli.Command{
+	Name:  "registry",
+	Usage: "Manage the Zot container registry",
+	Description: `Registry management commands for the Zot registry Foundry runs on the
+zot host.
+
+With authentication enabled (components.zot.auth.type: basic) registry
+accounts are stored in OpenBAO and rendered into Zot's htpasswd file. Every
+change re-renders the Zot configuration and, when the cluster's own account
+changes, the registries.yaml on each K3s node.
+
+Retention policies in components.zot.retention are rendered into Zot's
+garbage collection settings. Run 'foundry registry apply' after changing
+them (or auth, tls or access_control) on an installed registry.
+
+Commands:
+  foundry registry repos                      - List repositories with size and last push
+  foundry registry tags <repo>                - List tags with digest, size and push date
+  foundry registry delete <repo>:<tag>        - Delete a tagged image
+  foundry registry usage                      - Storage per repository against the data disk
+  foundry registry apply                      - Re-render the Zot configuration
+  foundry registry user list                  - List registry accounts
+  foundry registry user add <name>            - Create an account
+  foundry registry user remove <name>         - Delete an account
+  foundry registry user rotate <name>         - Generate a new password`,
+	Commands: []*cli.Command{
+		reposCommand,
+		tagsCommand,
+		deleteCommand,
+		usageCommand,
+		applyCommand,
+		UserCommand,
+	},
+}
+
+var applyCommand = &cli.Command{
+	Name:  "apply",
+	Usage: "Re-render the Zot configuration from the stack config",
+	Description: `Renders components.zot settings (auth, tls, access_control, retention)
+into the Zot configuration on the zot host, restarts Zot, and updates
+registries.yaml on cluster nodes when their access changed.`,
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		cfg, configDir, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if cfg.SetupState == nil || !cfg.SetupState.ZotInstalled {
+			return fmt.Errorf("zot is not installed")
+		}
+		return Sync(ctx, cfg, configDir, cmd.Root().Writer)
+	},
+}
+
+// Sync re-renders the Zot configuration from the accounts in OpenBAO and
+// updates registries.yaml on cluster nodes. Used after account changes and
+// after Zot gets a certificate.
+func Sync(ctx context.Context, cfg *config.Config, configDir string, out io.Writer) error {
+	store, err := openBAOClient(cfg, configDir)
+	if err != nil {
+		return err
+	}
+	users, err := registry.LoadUsers(ctx, store)
+	if err != nil {
+		return err
+	}
+	upstream, err := registry.LoadUpstreamCredentials(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	input := registry.SyncInput{
+		Config:   cfg,
+		Users:    users,
+		Upstream: upstream,
+		Out:      out,
+		Connect: func(h *host.Host) (registry.Executor, func(), error) {
+			conn, err := statushelpers.ConnectToHost(h, configDir, cfg.Cluster.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &statushelpers.SSHExecutorAdapter{Conn: conn}, func() { conn.Close() }, nil
+		},
+	}
+
+	// Nodes need the internal CA to trust a Zot certificate it signed
+	if registry.TLSEnabled(cfg) && cfg.HostTLS != nil && cfg.HostTLS.Issuer == config.HostTLSIssuerInternalCA &&
+		cfg.SetupState != nil && cfg.SetupState.K8sInstalled {
+		input.CA, err = loadClusterCA(ctx, configDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return registry.Sync(input)
+}
+
+// NodeRegistry returns how a node joining the cluster should reach Zot, and
+// the CA to install on it when Zot serves a certificate from the internal CA
+func NodeRegistry(ctx context.Context, cfg *config.Config, configDir string) (k3s.ZotRegistry, []byte, error) {
+	var users []zot.User
+	if registry.AuthEnabled(cfg) {
+		store, err := openBAOClient(cfg, configDir)
+		if err != nil {
+			return k3s.ZotRegistry{}, nil, err
+		}
+		users, err = registry.LoadUsers(ctx, store)
+		if err != nil {
+			return k3s.ZotRegistry{}, nil, err
+		}
+	}
+
+	tlsServing := false
+	if registry.TLSEnabled(cfg) {
+		zotHost, err := cfg.GetPrimaryZotHost()
+		if err != nil {
+			return k3s.ZotRegistry{}, nil, err
+		}
+		conn, err := statushelpers.ConnectToHost(zotHost, configDir, cfg.Cluster.Name)
+		if err != nil {
+			return k3s.ZotRegistry{}, nil, err
+		}
+		parsed, err := registry.ZotConfig(cfg, nil, nil)
+		if err == nil {
+			tlsServing = zot.HasTLSCertificate(&statushelpers.SSHExecutorAdapter{Conn: conn}, parsed.ConfigDir)
+		}
+		conn.Close()
+	}
+
+	var caPEM []byte
+	if tlsServing && cfg.HostTLS != nil && cfg.HostTLS.Issuer == config.HostTLSIssuerInternalCA {
+		var err error
+		caPEM, err = loadClusterCA(ctx, configDir)
+		if err != nil {
+			return k3s.ZotRegistry{}, nil, err
+		}
+	}
+
+	reg, err := registry.NodeRegistry(cfg, users, tlsServing, caPEM)
+	return reg, caPEM, err
+}
+
+func loadClusterCA(ctx context.Context, configDir string) ([]byte, error) {
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig (the internal CA lives in the cluster): %w", err)
+	}
+	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	ca, err := hosttls.LoadClusterCA(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	return ca.CertPEM(), nil
+}
+
+func openBAOClient(cfg *config.Config, configDir string) (*openbao.Client, error) {
+	addr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenBAO address: %w", err)
+	}
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenBAO keys (has OpenBAO been initialized?): %w", err)
+	}
+	return openbao.NewClient(addr, keyMaterial.RootToken), nil
+}
+
+func loadConfig(cmd *cli.Command) (*config.Config, string, error) {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find config: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return cfg, configDir, nil
+}
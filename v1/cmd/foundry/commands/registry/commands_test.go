@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryCommandRegistration(t *testing.T) {
+	assert.Equal(t, "registry", Command.Name)
+	topLevel := map[string]bool{}
+	for _, cmd := range Command.Commands {
+		topLevel[cmd.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"repos": true, "tags": true, "delete": true, "usage": true, "apply": true, "user": true}, topLevel)
+
+	names := map[string]bool{}
+	for _, cmd := range UserCommand.Commands {
+		names[cmd.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"list": true, "add": true, "remove": true, "rotate": true}, names)
+}
+
+func TestAddUser(t *testing.T) {
+	users, added, err := addUser(nil, "ci", zot.RolePush, zot.KindRobot)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.NotNil(t, added)
+	assert.Equal(t, "ci", added.Name)
+	assert.NotEmpty(t, added.Password)
+
+	_, _, err = addUser(users, "ci", zot.RolePush, zot.KindRobot)
+	require.Error(t, err)
+
+	_, _, err = addUser(users, "bob", "owner", zot.KindHuman)
+	require.Error(t, err)
+}
+
+func TestRemoveUser(t *testing.T) {
+	users, _, err := zot.EnsureDefaultUsers(nil)
+	require.NoError(t, err)
+	users, _, err = addUser(users, "ci", zot.RolePush, zot.KindRobot)
+	require.NoError(t, err)
+
+	remaining, err := removeUser(users, "ci")
+	require.NoError(t, err)
+	assert.Len(t, remaining, 2)
+
+	_, err = removeUser(remaining, "ci")
+	require.Error(t, err)
+	_, err = removeUser(remaining, zot.ClusterUser)
+	require.Error(t, err)
+}
+
+func TestRotateUser(t *testing.T) {
+	users, _, err := zot.EnsureDefaultUsers(nil)
+	require.NoError(t, err)
+	before, _ := zot.FindUser(users, zot.ClusterUser)
+
+	users, rotated, err := rotateUser(users, zot.ClusterUser)
+	require.NoError(t, err)
+	assert.NotEqual(t, before.Password, rotated.Password)
+	after, _ := zot.FindUser(users, zot.ClusterUser)
+	assert.Equal(t, rotated.Password, after.Password)
+
+	_, _, err = rotateUser(users, "ghost")
+	require.Error(t, err)
+}
+
+func TestRequireAuth(t *testing.T) {
+	cfg := &config.Config{SetupState: &setup.SetupState{ZotInstalled: true}}
+	require.Error(t, requireAuth(cfg))
+
+	cfg.Components = config.ComponentMap{"zot": config.ComponentConfig{Config: map[string]any{
+		"auth": map[string]any{"type": "basic"},
+	}}}
+	require.NoError(t, requireAuth(cfg))
+
+	cfg.SetupState.ZotInstalled = false
+	require.Error(t, requireAuth(cfg))
+}
+
+func TestShortDigest(t *testing.T) {
+	assert.Equal(t, "0123456789ab", shortDigest("sha256:0123456789abcdef"))
+	assert.Equal(t, "-", shortDigest(""))
+	assert.Equal(t, "-", percentOf(5, 0))
+	assert.Equal(t, "25.0%", percentOf(1, 4))
+}
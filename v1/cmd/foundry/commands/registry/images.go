@@ -0,0 +1,260 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/statushelpers"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
+	"github.com/urfave/cli/v3"
+)
+
+var reposCommand = &cli.Command{
+	Name:   "repos",
+	Usage:  "List repositories with their size and last push",
+	Action: runRepos,
+}
+
+var tagsCommand = &cli.Command{
+	Name:      "tags",
+	Usage:     "List the tags in a repository",
+	ArgsUsage: "<repo>",
+	Action:    runTags,
+}
+
+var deleteCommand = &cli.Command{
+	Name:      "delete",
+	Usage:     "Delete a tagged image",
+	ArgsUsage: "<repo>:<tag>",
+	Description: `Deletes the manifest the tag points to. Other tags pointing at the same
+manifest go with it. Zot reclaims the unreferenced layers on its next
+garbage collection run.
+
+Example:
+  foundry registry delete apps/api:v1.2.0`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "yes",
+			Usage: "Skip confirmation prompt",
+		},
+	},
+	Action: runDelete,
+}
+
+var usageCommand = &cli.Command{
+	Name:   "usage",
+	Usage:  "Show storage used per repository against the Zot data disk",
+	Action: runUsage,
+}
+
+func runRepos(ctx context.Context, cmd *cli.Command) error {
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	client, err := NewClient(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+	repos, err := client.Repositories(ctx)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		fmt.Fprintln(cmd.Root().Writer, "No repositories.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "REPOSITORY\tSIZE\tLAST PUSHED")
+	for _, r := range repos {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, format.Bytes(r.Size), formatTime(r.LastUpdated))
+	}
+	return nil
+}
+
+func runTags(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry registry tags <repo>")
+	}
+	repo := cmd.Args().First()
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	client, err := NewClient(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+	tags, err := client.Tags(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		fmt.Fprintf(cmd.Root().Writer, "No tags in %s.\n", repo)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "TAG\tDIGEST\tSIZE\tPUSHED")
+	for _, t := range tags {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, shortDigest(t.Digest), format.Bytes(t.Size), formatTime(t.LastUpdated))
+	}
+	return nil
+}
+
+func runDelete(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry registry delete <repo>:<tag>")
+	}
+	repo, tag, err := registry.ParseReference(cmd.Args().First())
+	if err != nil {
+		return err
+	}
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	client, err := NewClient(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("yes") {
+		fmt.Printf("Delete %s:%s and every tag sharing its manifest? (yes/no): ", repo, tag)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "yes" {
+			fmt.Println("Deletion cancelled")
+			return nil
+		}
+	}
+
+	digest, err := client.DeleteTag(ctx, repo, tag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "✓ Deleted %s:%s (%s)\n", repo, tag, digest)
+	return nil
+}
+
+func runUsage(ctx context.Context, cmd *cli.Command) error {
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	client, err := NewClient(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+	repos, err := client.Repositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := registry.ZotConfig(cfg, nil, nil)
+	if err != nil {
+		return err
+	}
+	zotHost, err := cfg.GetPrimaryZotHost()
+	if err != nil {
+		return err
+	}
+	conn, err := statushelpers.ConnectToHost(zotHost, configDir, cfg.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	disk, err := registry.ZotDiskUsage(&statushelpers.SSHExecutorAdapter{Conn: conn}, parsed.Config.DataDir)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.Root().Writer
+	var total int64
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tSIZE\tDISK")
+	for _, r := range repos {
+		total += r.Size
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, format.Bytes(r.Size), percentOf(r.Size, disk.Size))
+	}
+	fmt.Fprintf(w, "TOTAL\t%s\t%s\n", format.Bytes(total), percentOf(total, disk.Size))
+	w.Flush()
+
+	fmt.Fprintf(out, "\nZot data disk (%s on %s): %s used of %s (%s), %s free\n",
+		parsed.Config.DataDir, zotHost.Hostname, format.Bytes(disk.Used), format.Bytes(disk.Size),
+		percentOf(disk.Used, disk.Size), format.Bytes(disk.Available))
+	return nil
+}
+
+// NewClient connects to Zot the way cluster nodes do: over HTTPS once it
+// serves a certificate, as the admin account when authentication is on
+func NewClient(ctx context.Context, cfg *config.Config, configDir string) (*registry.Client, error) {
+	if cfg.SetupState == nil || !cfg.SetupState.ZotInstalled {
+		return nil, fmt.Errorf("zot is not installed")
+	}
+	node, caPEM, err := NodeRegistry(ctx, cfg, configDir)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := registry.ZotConfig(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var username, password string
+	if registry.AuthEnabled(cfg) {
+		store, err := openBAOClient(cfg, configDir)
+		if err != nil {
+			return nil, err
+		}
+		users, err := registry.LoadUsers(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		admin, ok := zot.FindUser(users, zot.AdminUser)
+		if !ok {
+			return nil, fmt.Errorf("registry account %q not found in OpenBAO", zot.AdminUser)
+		}
+		username, password = admin.Name, admin.Password
+	}
+
+	scheme := "http"
+	if node.TLS {
+		scheme = "https"
+	}
+	return registry.NewClient(fmt.Sprintf("%s://%s:%d", scheme, node.Address, parsed.Config.Port), username, password, caPEM)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	if digest == "" {
+		return "-"
+	}
+	return digest
+}
+
+func percentOf(n, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
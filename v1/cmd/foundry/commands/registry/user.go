@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
+	"github.com/urfave/cli/v3"
+)
+
+// UserCommand manages registry accounts
+var UserCommand = &cli.Command{
+	Name:  "user",
+	Usage: "Manage registry accounts",
+	Description: `Manage Zot registry accounts for people and robots (CI, the cluster).
+
+Roles apply to every repository:
+  read   - pull images
+  push   - pull and push images
+  admin  - pull, push and delete images
+
+Per-repository grants go in components.zot.access_control. Passwords are
+generated, stored in OpenBAO and printed once.`,
+	Commands: []*cli.Command{
+		userListCommand,
+		userAddCommand,
+		userRemoveCommand,
+		userRotateCommand,
+	},
+}
+
+var userListCommand = &cli.Command{
+	Name:   "list",
+	Usage:  "List registry accounts",
+	Action: runUserList,
+}
+
+var userAddCommand = &cli.Command{
+	Name:      "add",
+	Usage:     "Create a registry account",
+	ArgsUsage: "<name>",
+	Description: `Creates an account with a generated password and prints the password.
+
+Examples:
+  foundry registry user add alice --role push
+  foundry registry user add ci --role push --robot`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "role",
+			Usage: "Account role: read, push or admin",
+			Value: zot.RoleRead,
+		},
+		&cli.BoolFlag{
+			Name:  "robot",
+			Usage: "Machine account (CI, automation) rather than a person",
+		},
+	},
+	Action: runUserAdd,
+}
+
+var userRemoveCommand = &cli.Command{
+	Name:      "remove",
+	Usage:     "Delete a registry account",
+	ArgsUsage: "<name>",
+	Action:    runUserRemove,
+}
+
+var userRotateCommand = &cli.Command{
+	Name:      "rotate",
+	Usage:     "Generate a new password for a registry account",
+	ArgsUsage: "<name>",
+	Description: `Replaces the account's password and prints the new one. Rotating the
+cluster account (k3s) also updates registries.yaml on every node.`,
+	Action: runUserRotate,
+}
+
+func runUserList(ctx context.Context, cmd *cli.Command) error {
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	store, err := openBAOClient(cfg, configDir)
+	if err != nil {
+		return err
+	}
+	users, err := registry.LoadUsers(ctx, store)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		fmt.Fprintln(cmd.Root().Writer, "No registry accounts.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tKIND\tROLE")
+	for _, u := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", u.Name, u.Kind, u.Role)
+	}
+	return nil
+}
+
+func runUserAdd(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry registry user add <name>")
+	}
+	kind := zot.KindHuman
+	if cmd.Bool("robot") {
+		kind = zot.KindRobot
+	}
+	return changeUsers(ctx, cmd, func(users []zot.User) ([]zot.User, *zot.User, error) {
+		return addUser(users, cmd.Args().First(), cmd.String("role"), kind)
+	})
+}
+
+func runUserRemove(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry registry user remove <name>")
+	}
+	return changeUsers(ctx, cmd, func(users []zot.User) ([]zot.User, *zot.User, error) {
+		users, err := removeUser(users, cmd.Args().First())
+		return users, nil, err
+	})
+}
+
+func runUserRotate(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry registry user rotate <name>")
+	}
+	return changeUsers(ctx, cmd, func(users []zot.User) ([]zot.User, *zot.User, error) {
+		return rotateUser(users, cmd.Args().First())
+	})
+}
+
+// changeUsers applies a change to the accounts in OpenBAO, prints the
+// affected account's password if there is one, and syncs the registry
+func changeUsers(ctx context.Context, cmd *cli.Command, change func([]zot.User) ([]zot.User, *zot.User, error)) error {
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if err := requireAuth(cfg); err != nil {
+		return err
+	}
+	store, err := openBAOClient(cfg, configDir)
+	if err != nil {
+		return err
+	}
+	users, err := registry.LoadUsers(ctx, store)
+	if err != nil {
+		return err
+	}
+	// Normally created by stack install
+	users, _, err = zot.EnsureDefaultUsers(users)
+	if err != nil {
+		return err
+	}
+
+	users, changed, err := change(users)
+	if err != nil {
+		return err
+	}
+	// Catch access_control entries naming a removed account before storing
+	parsed, err := registry.ZotConfig(cfg, users, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := zot.GenerateConfig(parsed.Config, nil, parsed.Users...); err != nil {
+		return err
+	}
+	if err := registry.SaveUsers(ctx, store, users); err != nil {
+		return err
+	}
+
+	out := cmd.Root().Writer
+	if changed != nil {
+		fmt.Fprintf(out, "Account %s (%s, %s)\n", changed.Name, changed.Kind, changed.Role)
+		fmt.Fprintf(out, "  Password: %s\n", changed.Password)
+		fmt.Fprintln(out, "  The password is stored in OpenBAO and will not be shown again.")
+	}
+
+	fmt.Fprintln(out, "Applying registry configuration...")
+	return Sync(ctx, cfg, configDir, out)
+}
+
+func requireAuth(cfg *config.Config) error {
+	if !registry.AuthEnabled(cfg) {
+		return fmt.Errorf("registry authentication is not enabled (set components.zot.auth.type to \"basic\" and run 'foundry stack install')")
+	}
+	if cfg.SetupState == nil || !cfg.SetupState.ZotInstalled {
+		return fmt.Errorf("zot is not installed")
+	}
+	return nil
+}
+
+func addUser(users []zot.User, name, role, kind string) ([]zot.User, *zot.User, error) {
+	if _, ok := zot.FindUser(users, name); ok {
+		return nil, nil, fmt.Errorf("registry account %q already exists (use 'foundry registry user rotate' for a new password)", name)
+	}
+	password, err := zot.GeneratePassword()
+	if err != nil {
+		return nil, nil, err
+	}
+	user := zot.User{Name: name, Password: password, Role: role, Kind: kind}
+	if err := user.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return append(users, user), &user, nil
+}
+
+func removeUser(users []zot.User, name string) ([]zot.User, error) {
+	if name == zot.AdminUser || name == zot.ClusterUser {
+		return nil, fmt.Errorf("%q is managed by Foundry and cannot be removed (use rotate instead)", name)
+	}
+	remaining := make([]zot.User, 0, len(users))
+	for _, u := range users {
+		if u.Name != name {
+			remaining = append(remaining, u)
+		}
+	}
+	if len(remaining) == len(users) {
+		return nil, fmt.Errorf("registry account %q not found", name)
+	}
+	return remaining, nil
+}
+
+func rotateUser(users []zot.User, name string) ([]zot.User, *zot.User, error) {
+	for i := range users {
+		if users[i].Name != name {
+			continue
+		}
+		password, err := zot.GeneratePassword()
+		if err != nil {
+			return nil, nil, err
+		}
+		users[i].Password = password
+		user := users[i]
+		return users, &user, nil
+	}
+	return nil, nil, fmt.Errorf("registry account %q not found", name)
+}
@@ -0,0 +1,187 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+	"github.com/catalystcommunity/foundry/v1/internal/objectstore"
+	"github.com/urfave/cli/v3"
+)
+
+// BucketCommand manages buckets
+var BucketCommand = &cli.Command{
+	Name:  "bucket",
+	Usage: "Manage buckets",
+	Commands: []*cli.Command{
+		bucketListCommand,
+		bucketCreateCommand,
+		bucketDeleteCommand,
+		bucketUsageCommand,
+	},
+}
+
+var bucketListCommand = &cli.Command{
+	Name:   "list",
+	Usage:  "List buckets and the applications with access to them",
+	Action: runBucketList,
+}
+
+var bucketCreateCommand = &cli.Command{
+	Name:      "create",
+	Usage:     "Create a bucket",
+	ArgsUsage: "<name>",
+	Action:    runBucketCreate,
+}
+
+var bucketDeleteCommand = &cli.Command{
+	Name:      "delete",
+	Usage:     "Delete a bucket",
+	ArgsUsage: "<name>",
+	Description: `Deletes an empty bucket, or with --force a bucket and every object in it.
+A bucket an application has a credential for is not deleted; revoke the
+credential, or reissue it without the bucket, first.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "Delete the bucket's objects too",
+		},
+	},
+	Action: runBucketDelete,
+}
+
+var bucketUsageCommand = &cli.Command{
+	Name:      "usage",
+	Usage:     "Show the storage used per bucket",
+	ArgsUsage: "[name]",
+	Description: `Sums the volumes of each bucket's collection as reported by the SeaweedFS
+master. Replicas are counted once; deleted data still awaiting compaction
+is not counted.`,
+	Action: runBucketUsage,
+}
+
+func runBucketList(ctx context.Context, cmd *cli.Command) error {
+	m, err := newManager(cmd, false)
+	if err != nil {
+		return err
+	}
+	buckets, err := m.Buckets(ctx)
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		fmt.Fprintln(cmd.Root().Writer, "No buckets.")
+		return nil
+	}
+	identities, err := m.Identities(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tCREATED\tACCESS")
+	for _, b := range buckets {
+		created := "-"
+		if !b.Created.IsZero() {
+			created = b.Created.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", b.Name, created, bucketAccess(identities, b.Name))
+	}
+	return nil
+}
+
+// bucketAccess lists the applications with access to a bucket, as
+// "app(access)"
+func bucketAccess(identities []objectstore.Identity, bucket string) string {
+	var access []string
+	for _, identity := range identities {
+		if identity.Name == objectstore.AdminIdentity {
+			continue
+		}
+		if level := identity.Access(bucket); level != "" {
+			access = append(access, fmt.Sprintf("%s(%s)", identity.Name, level))
+		}
+	}
+	if len(access) == 0 {
+		return "-"
+	}
+	return strings.Join(access, ", ")
+}
+
+func runBucketCreate(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry s3 bucket create <name>")
+	}
+	name := cmd.Args().First()
+	m, err := newManager(cmd, false)
+	if err != nil {
+		return err
+	}
+	if err := m.CreateBucket(ctx, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "Created bucket %s\n", name)
+	return nil
+}
+
+func runBucketDelete(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry s3 bucket delete <name> [--force]")
+	}
+	name := cmd.Args().First()
+	m, err := newManager(cmd, false)
+	if err != nil {
+		return err
+	}
+	if err := m.DeleteBucket(ctx, name, cmd.Bool("force")); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "Deleted bucket %s\n", name)
+	return nil
+}
+
+func runBucketUsage(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() > 1 {
+		return fmt.Errorf("usage: foundry s3 bucket usage [name]")
+	}
+	m, err := newManager(cmd, false)
+	if err != nil {
+		return err
+	}
+	usage, err := m.Usage(ctx)
+	if err != nil {
+		return err
+	}
+	if name := cmd.Args().First(); name != "" {
+		var matched []objectstore.BucketUsage
+		for _, u := range usage {
+			if u.Name == name {
+				matched = append(matched, u)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("bucket %s not found", name)
+		}
+		usage = matched
+	}
+	if len(usage) == 0 {
+		fmt.Fprintln(cmd.Root().Writer, "No buckets.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tSIZE\tFILES")
+	var total int64
+	for _, u := range usage {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", u.Name, format.Bytes(u.Size), u.Files)
+		total += u.Size
+	}
+	if len(usage) > 1 {
+		fmt.Fprintf(w, "TOTAL\t%s\t\n", format.Bytes(total))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package s3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/objectstore"
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// s3Region is the region SeaweedFS' S3 gateway answers for
+const s3Region = "us-east-1"
+
+// Command is the top-level s3 command
+var Command = &cli.Command{
+	Name:  "s3",
+	Usage: "Manage SeaweedFS buckets and application credentials",
+	Description: `Object storage commands for the stack's SeaweedFS.
+
+Foundry's own components (Loki, Tempo, Velero, Longhorn backups) share the
+credential stored in OpenBAO at foundry-core/seaweedfs. Applications get their own: 'credentials
+issue' creates an S3 identity limited to the buckets it names and stores the
+keys in OpenBAO, where the secret injector can hand them to pods.
+
+Issuing the first credential turns on S3 authentication. The shared
+credential is kept as the foundry-admin identity, so Foundry's components
+keep working.
+
+Commands:
+  foundry s3 bucket list                      - List buckets and who has access
+  foundry s3 bucket create <name>             - Create a bucket
+  foundry s3 bucket delete <name>             - Delete a bucket
+  foundry s3 bucket usage [name]              - Storage used per bucket
+  foundry s3 credentials list                 - List application credentials
+  foundry s3 credentials issue <app>          - Issue or rotate an application's credential
+  foundry s3 credentials revoke <app>         - Revoke an application's credential`,
+	Commands: []*cli.Command{
+		BucketCommand,
+		CredentialsCommand,
+	},
+}
+
+// newManager connects to SeaweedFS through the cluster's API server, with
+// OpenBAO as the store for issued credentials when withSecrets is set
+func newManager(cmd *cli.Command, withSecrets bool) (*objectstore.Manager, error) {
+	cfg, configDir, err := loadConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	swfs, ok := cfg.Components["seaweedfs"]
+	if !ok {
+		return nil, fmt.Errorf("seaweedfs is not configured (run 'foundry stack install')")
+	}
+	namespace, _ := swfs.Config["namespace"].(string)
+	if namespace == "" {
+		namespace = objectstore.DefaultNamespace
+	}
+
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	m := &objectstore.Manager{
+		API:      objectstore.NewProxyAPI(k8sClient.Clientset(), namespace),
+		Endpoint: fmt.Sprintf("http://seaweedfs-s3.%s.svc.cluster.local:8333", namespace),
+		Region:   s3Region,
+	}
+	m.Admin.AccessKey, m.Admin.SecretKey, err = adminCredential(cfg, configDir, swfs.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if withSecrets {
+		m.Secrets, err = openBAOClient(cfg, configDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// adminCredential returns the shared credential from components.seaweedfs,
+// resolving the references to OpenBAO it holds since the stack install moved
+// it there
+func adminCredential(cfg *config.Config, configDir string, swfs map[string]interface{}) (accessKey, secretKey string, err error) {
+	accessKey, _ = swfs["access_key"].(string)
+	secretKey, _ = swfs["secret_key"].(string)
+	if !secrets.IsSecretRef(accessKey) && !secrets.IsSecretRef(secretKey) {
+		return accessKey, secretKey, nil
+	}
+
+	resolvers := []secrets.Resolver{secrets.NewEnvResolver()}
+	if addr, err := cfg.GetPrimaryOpenBAOURL(); err == nil {
+		if keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name); err == nil {
+			if resolver, err := secrets.NewOpenBAOResolverWithMount(addr, keyMaterial.RootToken, "foundry-core"); err == nil {
+				resolvers = append(resolvers, resolver)
+			}
+		}
+	}
+	resolved, err := secrets.ResolveRefs(map[string]interface{}{
+		"access_key": accessKey,
+		"secret_key": secretKey,
+	}, secrets.NewChainResolver(resolvers...), &secrets.ResolutionContext{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve the SeaweedFS credentials: %w", err)
+	}
+	keys := resolved.(map[string]interface{})
+	return keys["access_key"].(string), keys["secret_key"].(string), nil
+}
+
+func openBAOClient(cfg *config.Config, configDir string) (*openbao.Client, error) {
+	addr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenBAO address: %w", err)
+	}
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenBAO keys (has OpenBAO been initialized?): %w", err)
+	}
+	return openbao.NewClient(addr, keyMaterial.RootToken), nil
+}
+
+func loadConfig(cmd *cli.Command) (*config.Config, string, error) {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find config: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return cfg, configDir, nil
+}
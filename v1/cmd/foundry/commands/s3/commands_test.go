@@ -0,0 +1,36 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func TestS3CommandRegistration(t *testing.T) {
+	assert.Equal(t, "s3", Command.Name)
+
+	names := func(cmds []*cli.Command) map[string]bool {
+		m := map[string]bool{}
+		for _, cmd := range cmds {
+			m[cmd.Name] = true
+		}
+		return m
+	}
+	assert.Equal(t, map[string]bool{"bucket": true, "credentials": true}, names(Command.Commands))
+	assert.Equal(t, map[string]bool{"list": true, "create": true, "delete": true, "usage": true}, names(BucketCommand.Commands))
+	assert.Equal(t, map[string]bool{"list": true, "issue": true, "revoke": true}, names(CredentialsCommand.Commands))
+}
+
+func TestBucketAccess(t *testing.T) {
+	identities := []objectstore.Identity{
+		{Name: objectstore.AdminIdentity, Actions: []string{objectstore.ActionAdmin}},
+		{Name: "gallery", Actions: []string{"Read:uploads", "List:uploads", "Write:uploads"}},
+		{Name: "reports", Actions: []string{"Read:uploads", "List:uploads", "Read:archive"}},
+	}
+
+	assert.Equal(t, "gallery(read-write), reports(read)", bucketAccess(identities, "uploads"))
+	assert.Equal(t, "reports(read)", bucketAccess(identities, "archive"))
+	assert.Equal(t, "-", bucketAccess(identities, "loki"), "the shared identity is not listed")
+}
@@ -0,0 +1,159 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/catalystcommunity/foundry/v1/internal/objectstore"
+	"github.com/urfave/cli/v3"
+)
+
+// CredentialsCommand manages application credentials
+var CredentialsCommand = &cli.Command{
+	Name:  "credentials",
+	Usage: "Manage per-application S3 credentials",
+	Description: `Each application gets its own S3 identity, limited to the buckets it is
+issued for. The keys are stored in OpenBAO at foundry-core/apps/<app>/s3
+together with the endpoint, region and buckets, with a policy s3-<app> that
+reads them. Add the policy to the application's role in
+components.openbao-injector.k8s_auth_roles to have the secret injector
+deliver them.`,
+	Commands: []*cli.Command{
+		credentialsListCommand,
+		credentialsIssueCommand,
+		credentialsRevokeCommand,
+	},
+}
+
+var credentialsListCommand = &cli.Command{
+	Name:   "list",
+	Usage:  "List applications with S3 credentials",
+	Action: runCredentialsList,
+}
+
+var credentialsIssueCommand = &cli.Command{
+	Name:      "issue",
+	Usage:     "Issue or rotate an application's S3 credential",
+	ArgsUsage: "<app>",
+	Description: `Creates an S3 identity for the application with access to the given
+buckets and prints its keys once. Issuing again for the same application
+rotates its keys, and replaces its access with the buckets given; the old
+keys stop working at once.
+
+Examples:
+  foundry s3 credentials issue gallery --bucket uploads --read --write
+  foundry s3 credentials issue reports --bucket uploads --bucket archive --read`,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "bucket",
+			Usage: "Bucket to grant access to (repeatable)",
+		},
+		&cli.BoolFlag{
+			Name:  "read",
+			Usage: "Allow reading and listing objects",
+		},
+		&cli.BoolFlag{
+			Name:  "write",
+			Usage: "Allow writing, deleting and tagging objects",
+		},
+	},
+	Action: runCredentialsIssue,
+}
+
+var credentialsRevokeCommand = &cli.Command{
+	Name:      "revoke",
+	Usage:     "Revoke an application's S3 credential",
+	ArgsUsage: "<app>",
+	Description: `Removes the application's S3 identity, so its keys stop working, and
+deletes the keys and their policy from OpenBAO.`,
+	Action: runCredentialsRevoke,
+}
+
+func runCredentialsList(ctx context.Context, cmd *cli.Command) error {
+	m, err := newManager(cmd, false)
+	if err != nil {
+		return err
+	}
+	identities, err := m.Identities(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+	listed := false
+	for _, identity := range identities {
+		if identity.Name == objectstore.AdminIdentity {
+			continue
+		}
+		if !listed {
+			fmt.Fprintln(w, "APP\tACCESS KEY\tBUCKETS")
+			listed = true
+		}
+		accessKey := "-"
+		if len(identity.Credentials) > 0 {
+			accessKey = identity.Credentials[0].AccessKey
+		}
+		var buckets []string
+		for _, bucket := range identity.Buckets() {
+			buckets = append(buckets, fmt.Sprintf("%s(%s)", bucket, identity.Access(bucket)))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", identity.Name, accessKey, strings.Join(buckets, ", "))
+	}
+	if !listed {
+		fmt.Fprintln(w, "No application credentials.")
+	}
+	return nil
+}
+
+func runCredentialsIssue(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry s3 credentials issue <app> --bucket <name> [--read] [--write]")
+	}
+	m, err := newManager(cmd, true)
+	if err != nil {
+		return err
+	}
+	issued, err := m.Issue(ctx, cmd.Args().First(), cmd.StringSlice("bucket"), cmd.Bool("read"), cmd.Bool("write"))
+	if issued != nil {
+		printIssued(cmd.Root().Writer, issued)
+	}
+	return err
+}
+
+// printIssued shows a new credential and how a pod gets it from OpenBAO
+func printIssued(out io.Writer, issued *objectstore.Issued) {
+	if issued.Rotated {
+		fmt.Fprintf(out, "Rotated the S3 credential of %s; its previous keys no longer work.\n", issued.App)
+	} else {
+		fmt.Fprintf(out, "Issued an S3 credential to %s.\n", issued.App)
+	}
+	fmt.Fprintf(out, "  Buckets:    %s\n", strings.Join(issued.Buckets, ", "))
+	fmt.Fprintf(out, "  Access key: %s\n", issued.AccessKey)
+	fmt.Fprintf(out, "  Secret key: %s\n", issued.SecretKey)
+	fmt.Fprintln(out, "  The secret key will not be shown again.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Stored in OpenBAO at %s/%s, readable with policy %s.\n", objectstore.SecretMount, issued.SecretPath, issued.Policy)
+	fmt.Fprintln(out, "To inject it into pods, add the policy to the application's role in")
+	fmt.Fprintln(out, "components.openbao-injector.k8s_auth_roles and annotate the pod template:")
+	fmt.Fprintf(out, "  vault.hashicorp.com/agent-inject-secret-s3: %s/data/%s\n", objectstore.SecretMount, issued.SecretPath)
+}
+
+func runCredentialsRevoke(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: foundry s3 credentials revoke <app>")
+	}
+	app := cmd.Args().First()
+	m, err := newManager(cmd, true)
+	if err != nil {
+		return err
+	}
+	if err := m.Revoke(ctx, app); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "Revoked the S3 credential of %s\n", app)
+	return nil
+}
@@ -0,0 +1,317 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	registrycmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/registry"
+	"github.com/catalystcommunity/foundry/v1/internal/bundle"
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/alloy"
+	"github.com/catalystcommunity/foundry/v1/internal/component/blackbox"
+	"github.com/catalystcommunity/foundry/v1/internal/component/certmanager"
+	"github.com/catalystcommunity/foundry/v1/internal/component/contour"
+	"github.com/catalystcommunity/foundry/v1/internal/component/dns"
+	"github.com/catalystcommunity/foundry/v1/internal/component/externaldns"
+	"github.com/catalystcommunity/foundry/v1/internal/component/gatewayapi"
+	"github.com/catalystcommunity/foundry/v1/internal/component/gatewaycontroller"
+	"github.com/catalystcommunity/foundry/v1/internal/component/grafana"
+	"github.com/catalystcommunity/foundry/v1/internal/component/hostlogs"
+	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
+	"github.com/catalystcommunity/foundry/v1/internal/component/loki"
+	"github.com/catalystcommunity/foundry/v1/internal/component/nodeexporter"
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/component/prometheus"
+	"github.com/catalystcommunity/foundry/v1/internal/component/seaweedfs"
+	"github.com/catalystcommunity/foundry/v1/internal/component/storage"
+	"github.com/catalystcommunity/foundry/v1/internal/component/tempo"
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/lockfile"
+	"github.com/catalystcommunity/foundry/v1/internal/ssh"
+)
+
+// activeBundle is the offline bundle the current install reads from. It is
+// nil for online installs.
+var activeBundle *bundle.Bundle
+
+// activeLock is the stack lockfile the current install pins charts and
+// images to. It is nil when the stack has no lockfile.
+var activeLock *lockfile.File
+
+// bundleCharts maps each Kubernetes component with Helm charts to the charts
+// it installs for a given component config
+var bundleCharts = map[string]func(component.ComponentConfig) ([]helm.ChartSource, error){
+	"storage": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := storage.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return storage.Charts(c), nil
+	},
+	"prometheus": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := prometheus.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return prometheus.Charts(c), nil
+	},
+	"contour": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := contour.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return contour.Charts(c), nil
+	},
+	"cert-manager": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := certmanager.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return certmanager.Charts(c), nil
+	},
+	"seaweedfs": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := seaweedfs.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return seaweedfs.Charts(c), nil
+	},
+	"external-dns": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := externaldns.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return externaldns.Charts(c), nil
+	},
+	"loki": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := loki.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return loki.Charts(c), nil
+	},
+	"alloy": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := alloy.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return alloy.Charts(c)
+	},
+	"tempo": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := tempo.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return tempo.Charts(c), nil
+	},
+	"grafana": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := grafana.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return grafana.Charts(c), nil
+	},
+	"velero": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := velero.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return velero.Charts(c), nil
+	},
+	"blackbox-exporter": func(cc component.ComponentConfig) ([]helm.ChartSource, error) {
+		c, err := blackbox.ParseConfig(cc)
+		if err != nil {
+			return nil, err
+		}
+		return blackbox.Charts(c), nil
+	},
+}
+
+// ArtifactPlan lists every chart, with the values it is installed with, and
+// every image the stack described by cfg installs. Offline bundles and the
+// stack lockfile are built from it.
+func ArtifactPlan(ctx context.Context, cfg *config.Config, configDir string) (*bundle.Plan, error) {
+	plan := &bundle.Plan{}
+
+	if k3sCfg, ok := cfg.Components["k3s"]; ok {
+		if version, ok := k3sCfg.Config["version"].(string); ok && version != "" {
+			plan.K3sVersion = version
+		} else if k3sCfg.Version != nil {
+			plan.K3sVersion = *k3sCfg.Version
+		}
+	}
+
+	// Credentials do not change which images a chart runs, so planning
+	// does not read them from OpenBAO
+	planOpts := componentConfigOptions{planning: true}
+
+	gatewayCompCfg, err := k8sComponentConfig(ctx, cfg, configDir, "gateway-api", planOpts)
+	if err != nil {
+		return nil, err
+	}
+	gatewayCfg, err := gatewayapi.ParseConfig(gatewayCompCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gateway-api config: %w", err)
+	}
+	plan.GatewayAPIVersion = gatewayCfg.Version
+
+	for _, name := range []string{"storage", "prometheus", "contour", "cert-manager", "seaweedfs", "external-dns", "loki", "tempo", "alloy", "grafana", "velero", "blackbox-exporter"} {
+		compCfg, err := k8sComponentConfig(ctx, cfg, configDir, name, planOpts)
+		if err != nil {
+			return nil, err
+		}
+		charts, err := bundleCharts[name](compCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s config: %w", name, err)
+		}
+		for _, chart := range charts {
+			plan.Charts = append(plan.Charts, bundle.PlannedChart{Component: name, ChartSource: chart})
+		}
+	}
+
+	if componentEnabled(cfg, "gateway-controller") {
+		controllerCfg, err := gatewaycontroller.ParseConfig(buildGatewayControllerConfig(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway-controller config: %w", err)
+		}
+		images, err := gatewaycontroller.Images(controllerCfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range images {
+			plan.Images = append(plan.Images, bundle.Image{Component: "gateway-controller", Ref: ref})
+		}
+	}
+
+	// Host services run the versions the stack config sets, or their defaults
+	openbaoVersion := openbao.DefaultConfig().Version
+	if version := hostImageVersion(cfg, "openbao"); version != "" {
+		openbaoVersion = version
+	}
+	zotVersion := zot.DefaultConfig().Version
+	if version := hostImageVersion(cfg, "zot"); version != "" {
+		zotVersion = version
+	}
+	nodeExporterVersion := nodeexporter.DefaultConfig().Version
+	if version := hostImageVersion(cfg, "node-exporter"); version != "" {
+		nodeExporterVersion = version
+	}
+	hostLogsVersion := hostlogs.DefaultConfig().Version
+	if version := hostImageVersion(cfg, "host-logs"); version != "" {
+		hostLogsVersion = version
+	}
+	authImage, recursorImage := dns.Images(hostImageVersion(cfg, "dns"))
+	plan.Images = append(plan.Images,
+		bundle.Image{Component: "openbao", Ref: openbao.Image(openbaoVersion)},
+		bundle.Image{Component: "dns", Ref: authImage},
+		bundle.Image{Component: "dns", Ref: recursorImage},
+		bundle.Image{Component: "zot", Ref: zot.Image(zotVersion)},
+		bundle.Image{Component: "node-exporter", Ref: nodeexporter.Image(nodeExporterVersion)},
+		bundle.Image{Component: "host-logs", Ref: hostlogs.Image(hostLogsVersion)},
+		bundle.Image{Component: "k3s", Ref: k3s.KubeVIPImage},
+		bundle.Image{Component: "k3s", Ref: k3s.KubeVIPCloudProviderImage},
+	)
+
+	return plan, nil
+}
+
+// hostImageVersionKeys are the config keys the host services read their
+// image version from
+var hostImageVersionKeys = map[string]string{
+	"openbao":       "version",
+	"zot":           "version",
+	"dns":           "image_tag",
+	"node-exporter": "version",
+	"host-logs":     "version",
+}
+
+// hostImageVersion is the image version the stack config sets for a host
+// service, from its config or its version field, or "" for the default
+func hostImageVersion(cfg *config.Config, name string) string {
+	compCfg, ok := cfg.Components[name]
+	if !ok {
+		return ""
+	}
+	if version, ok := compCfg.Config[hostImageVersionKeys[name]].(string); ok && version != "" {
+		return version
+	}
+	if compCfg.Version != nil {
+		return *compCfg.Version
+	}
+	return ""
+}
+
+// loadBundleImages loads the bundled images of a host component into the
+// host's container runtime, so the component never pulls them
+func loadBundleImages(ctx context.Context, conn *ssh.Connection, componentName string) error {
+	if activeBundle == nil {
+		return nil
+	}
+	fmt.Printf("  Loading %s images from bundle...\n", componentName)
+	rt := container.NewDockerRuntime(&sshExecutorAdapter{conn: conn})
+	if err := activeBundle.LoadImages(ctx, conn, rt, componentName); err != nil {
+		return fmt.Errorf("failed to load bundle images: %w", err)
+	}
+	return nil
+}
+
+// prepareBundleCluster readies an offline cluster install: k3s is staged on
+// every node, every bundled image is pushed to Zot, and the nodes are told
+// to pull each bundled registry through Zot
+func prepareBundleCluster(ctx context.Context, cfg *config.Config) error {
+	if activeBundle == nil {
+		return nil
+	}
+
+	for _, h := range cfg.GetClusterHosts() {
+		fmt.Printf("  Staging k3s %s on %s...\n", activeBundle.Manifest.K3s.Version, h.Hostname)
+		conn, err := connectToHost(h, cfg.Cluster.Name)
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", h.Hostname, err)
+		}
+		err = activeBundle.StageK3s(conn)
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("failed to stage k3s on %s: %w", h.Hostname, err)
+		}
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	client, err := registrycmd.NewClient(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+	fmt.Println("  Pushing bundle images to Zot...")
+	if err := activeBundle.PushImages(ctx, client, os.Stdout); err != nil {
+		return fmt.Errorf("failed to push bundle images: %w", err)
+	}
+
+	k3sCfg := cfg.Components["k3s"]
+	if k3sCfg.Config == nil {
+		k3sCfg.Config = map[string]any{}
+	}
+	k3sCfg.Config["airgap"] = true
+	var mirrors []interface{}
+	for _, registry := range activeBundle.Manifest.Registries() {
+		// Zot already mirrors these
+		if registry != "docker.io" && registry != "ghcr.io" {
+			mirrors = append(mirrors, registry)
+		}
+	}
+	if len(mirrors) > 0 {
+		k3sCfg.Config["mirror_registries"] = mirrors
+	}
+	if cfg.Components == nil {
+		cfg.Components = map[string]config.ComponentConfig{}
+	}
+	cfg.Components["k3s"] = k3sCfg
+	return nil
+}
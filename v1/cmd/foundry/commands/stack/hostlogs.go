@@ -0,0 +1,56 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/hostlogs"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+// installHostLogAgents installs the journal log agent on every host in the
+// stack. The agents push to Loki's gateway ingress on the cluster VIP and
+// trust the internal CA that signed its certificate.
+func installHostLogAgents(ctx context.Context, cfg *config.Config, comp component.Component) error {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	ca, err := hosttls.LoadClusterCA(ctx, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	defaults := hostlogs.StackSettings(cfg)
+	defaults["ca_cert"] = string(ca.CertPEM())
+	return installOnHosts(ctx, cfg, comp, defaults, addHostLogLabels)
+}
+
+// addHostLogLabels adds a host's host and role labels to the labels
+// configured under components.host-logs
+func addHostLogLabels(h *host.Host, componentConfig component.ComponentConfig) {
+	labels := map[string]interface{}{}
+	if configured, ok := componentConfig.GetMap("labels"); ok {
+		for k, v := range configured {
+			labels[k] = v
+		}
+	}
+	for k, v := range hostlogs.HostLabels(h) {
+		labels[k] = v
+	}
+	componentConfig["labels"] = labels
+}
@@ -0,0 +1,156 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	"github.com/catalystcommunity/foundry/v1/internal/component/prometheus"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+)
+
+// metricsOpenBAOClient creates a root OpenBAO client for the metrics
+// credentials
+func metricsOpenBAOClient(cfg *config.Config, configDir string) (*openbao.Client, error) {
+	openBAOAddr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return nil, err
+	}
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenBAO keys: %w", err)
+	}
+	return openbao.NewClient(openBAOAddr, keyMaterial.RootToken), nil
+}
+
+// ensureDNSMetricsPassword generates and stores the PowerDNS webserver
+// password that protects /metrics, if it doesn't exist
+func ensureDNSMetricsPassword(ctx context.Context, cfg *config.Config, configDir string) (string, error) {
+	client, err := metricsOpenBAOClient(cfg, configDir)
+	if err != nil {
+		return "", err
+	}
+	return client.EnsureSecretValue(ctx, openbao.MetricsSecretMount, openbao.MetricsSecretPath, "powerdns_password", generateDNSAPIKey)
+}
+
+// hostMetricsCredentials loads the credentials Prometheus scrapes the host
+// services with. The OpenBAO metrics token is created or renewed; the
+// PowerDNS password only exists once DNS has been installed with it.
+func hostMetricsCredentials(ctx context.Context, cfg *config.Config, configDir string) prometheus.HostCredentials {
+	var creds prometheus.HostCredentials
+	if cfg.SetupState == nil || !cfg.SetupState.OpenBAOInitialized {
+		return creds
+	}
+
+	client, err := metricsOpenBAOClient(cfg, configDir)
+	if err != nil {
+		fmt.Printf("  ⚠ Host service metrics will be scraped without credentials: %v\n", err)
+		return creds
+	}
+	if creds.OpenBAOToken, err = client.EnsureMetricsToken(ctx); err != nil {
+		fmt.Printf("  ⚠ OpenBAO metrics token not available: %v\n", err)
+	}
+	if data, err := client.ReadSecretV2(ctx, openbao.MetricsSecretMount, openbao.MetricsSecretPath); err == nil {
+		creds.PowerDNSPassword, _ = data["powerdns_password"].(string)
+	}
+	return creds
+}
+
+// ScheduleMetricsTokenRenewal renews the OpenBAO metrics token daily, well
+// within its period, until ctx is done. The manager runs it so Prometheus
+// keeps scraping OpenBAO between prometheus upgrades.
+func ScheduleMetricsTokenRenewal(ctx context.Context, configPath string, out io.Writer) {
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := renewMetricsToken(ctx, configPath); err != nil {
+			fmt.Fprintf(out, "metrics token renewal: %v (run 'foundry component install prometheus' to replace the token)\n", err)
+		}
+		timer.Reset(24 * time.Hour)
+	}
+}
+
+// renewMetricsToken renews the stored OpenBAO metrics token, if any
+func renewMetricsToken(ctx context.Context, configPath string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.SetupState == nil || !cfg.SetupState.OpenBAOInitialized {
+		return nil
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	client, err := metricsOpenBAOClient(cfg, configDir)
+	if err != nil {
+		return err
+	}
+	return client.RenewMetricsToken(ctx)
+}
+
+// installNodeExporters installs node_exporter on every host in the stack,
+// with the settings from components.node-exporter
+func installNodeExporters(ctx context.Context, cfg *config.Config, comp component.Component) error {
+	return installOnHosts(ctx, cfg, comp, nil, nil)
+}
+
+// installOnHosts installs a component that runs on every host in the stack.
+// Each host's config is defaults (may be nil) overlaid with the settings
+// from components.<name>; forHost, if set, then adds that host's settings.
+func installOnHosts(ctx context.Context, cfg *config.Config, comp component.Component, defaults component.ComponentConfig, forHost func(*host.Host, component.ComponentConfig)) error {
+	name := comp.Name()
+	for _, h := range cfg.Hosts {
+		fmt.Printf("  %s (%s)...\n", h.Hostname, h.Address)
+		conn, err := connectToHost(h, cfg.Cluster.Name)
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", h.Hostname, err)
+		}
+
+		componentConfig := component.ComponentConfig{}
+		for k, v := range defaults {
+			componentConfig[k] = v
+		}
+		if compCfg, ok := cfg.Components[name]; ok {
+			for k, v := range compCfg.Config {
+				if k != "installed" {
+					componentConfig[k] = v
+				}
+			}
+		}
+		if key, ok := hostImageVersionKeys[name]; ok {
+			if version := hostImageVersion(cfg, name); version != "" {
+				componentConfig[key] = version
+			}
+		}
+		if forHost != nil {
+			forHost(h, componentConfig)
+		}
+		componentConfig["host"] = &sshExecutorAdapter{conn: conn}
+		if activeLock != nil {
+			componentConfig["image_digests"] = activeLock.ImageDigests()
+		}
+
+		err = loadBundleImages(ctx, conn, name)
+		if err == nil {
+			err = comp.Install(ctx, componentConfig)
+		}
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", h.Hostname, err)
+		}
+		fmt.Printf("  ✓ %s installed on %s\n", name, h.Hostname)
+	}
+	return nil
+}
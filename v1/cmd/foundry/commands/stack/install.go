@@ -16,7 +16,11 @@ import (
 	"gopkg.in/yaml.v3"
 
 	clustercommands "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/cluster"
+	registrycmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/registry"
+	"github.com/catalystcommunity/foundry/v1/internal/bundle"
 	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/alloy"
+	"github.com/catalystcommunity/foundry/v1/internal/component/blackbox"
 	"github.com/catalystcommunity/foundry/v1/internal/component/certmanager"
 	"github.com/catalystcommunity/foundry/v1/internal/component/contour"
 	"github.com/catalystcommunity/foundry/v1/internal/component/dns"
@@ -29,14 +33,21 @@ import (
 	"github.com/catalystcommunity/foundry/v1/internal/component/prometheus"
 	"github.com/catalystcommunity/foundry/v1/internal/component/seaweedfs"
 	"github.com/catalystcommunity/foundry/v1/internal/component/storage"
+	"github.com/catalystcommunity/foundry/v1/internal/component/tempo"
 	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
 	"github.com/catalystcommunity/foundry/v1/internal/container"
 	"github.com/catalystcommunity/foundry/v1/internal/dashboards"
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
 	"github.com/catalystcommunity/foundry/v1/internal/helm"
 	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/hosttls"
 	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+	"github.com/catalystcommunity/foundry/v1/internal/lockfile"
 	"github.com/catalystcommunity/foundry/v1/internal/manager"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
 	"github.com/catalystcommunity/foundry/v1/internal/setup"
 	"github.com/catalystcommunity/foundry/v1/internal/ssh"
 	"github.com/catalystcommunity/foundry/v1/internal/sudo"
@@ -60,7 +71,7 @@ func checkAllComponentsInstalled(ctx context.Context, state *setup.SetupState) b
 	}
 
 	// Kubernetes components expose status through the component registry.
-	// Order: gateway-api, storage, prometheus, contour, cert-manager, seaweedfs, external-dns, loki, grafana, velero
+	// Order: gateway-api, storage, prometheus, contour, cert-manager, seaweedfs, external-dns, loki, tempo, alloy, grafana, velero, blackbox-exporter
 	kubernetesComponents := []string{
 		"gateway-api",
 		"storage",
@@ -70,8 +81,11 @@ func checkAllComponentsInstalled(ctx context.Context, state *setup.SetupState) b
 		"seaweedfs",
 		"external-dns",
 		"loki",
+		"tempo",
+		"alloy",
 		"grafana",
 		"velero",
+		"blackbox-exporter",
 	}
 
 	for _, name := range kubernetesComponents {
@@ -156,6 +170,10 @@ Examples:
 			Name:  "upgrade",
 			Usage: "Upgrade already-installed K8s components with current configuration",
 		},
+		&cli.StringFlag{
+			Name:  "bundle",
+			Usage: "Install offline from a bundle created with 'foundry bundle create'",
+		},
 	},
 	Action: runStackInstall,
 }
@@ -193,6 +211,7 @@ func runStackInstall(ctx context.Context, cmd *cli.Command) error {
 		Yes:            cmd.Bool("yes"),
 		NonInteractive: cmd.Bool("non-interactive"),
 		Upgrade:        cmd.Bool("upgrade"),
+		Bundle:         cmd.String("bundle"),
 	})
 }
 
@@ -209,6 +228,8 @@ type InstallOptions struct {
 	Yes            bool
 	NonInteractive bool
 	Upgrade        bool
+	// Bundle is an offline bundle to install from instead of the internet
+	Bundle string
 }
 
 // RunInstall runs the stack installation without depending on a CLI command.
@@ -260,6 +281,33 @@ func RunInstall(ctx context.Context, options InstallOptions) error {
 		return printStackPlan(cfg, nextStep)
 	}
 
+	lock, err := lockfile.LoadForConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		activeLock = lock
+		defer func() { activeLock = nil }()
+		fmt.Printf("Using lockfile: %s\n", lockfile.Path(configPath))
+	}
+
+	if options.Bundle != "" {
+		if cfg.Management != nil {
+			return fmt.Errorf("offline bundles cannot be used with an external manager")
+		}
+		b, err := bundle.Open(options.Bundle)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			b.Close()
+			activeBundle = nil
+		}()
+		activeBundle = b
+		fmt.Printf("Installing from bundle: %s (k3s %s, %d charts, %d images)\n",
+			options.Bundle, b.Manifest.K3s.Version, len(b.Manifest.Charts), len(b.Manifest.Images))
+	}
+
 	// Step 3: Execute installation phases
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("  FOUNDRY STACK INSTALLATION")
@@ -792,6 +840,9 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 	// Ensure DNS config exists with defaults
 	ensureDefaultDNSConfig(cfg)
 
+	// Secrets resolved or generated by this run, kept out of the saved config
+	secretRefs := installSecrets{}
+
 	// Helper function to check component status via registry
 	checkComponentStatus := func(name string) bool {
 		comp := component.Get(name)
@@ -805,6 +856,13 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 		return status.Installed
 	}
 
+	// Helper function to check components installed on hosts, which the
+	// stack config tracks
+	checkTrackedComponent := func(name string) bool {
+		installed, _ := cfg.Components[name].Config["installed"].(bool)
+		return installed
+	}
+
 	// Helper function to track component in config
 	trackComponent := func(name string) {
 		if cfg.Components == nil {
@@ -861,6 +919,17 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 				trackComponent("zot")
 			},
 		},
+		// node_exporter runs on every host, so the infrastructure hosts get
+		// host metrics as well as the cluster nodes
+		{
+			name: "node-exporter",
+			checkFunc: func(s *setup.SetupState) bool {
+				return checkTrackedComponent("node-exporter")
+			},
+			setFunc: func(s *setup.SetupState) {
+				trackComponent("node-exporter")
+			},
+		},
 		{
 			name: "k3s",
 			checkFunc: func(s *setup.SetupState) bool {
@@ -958,6 +1027,36 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 				trackComponent("loki")
 			},
 		},
+		{
+			name: "tempo",
+			checkFunc: func(s *setup.SetupState) bool {
+				return checkComponentStatus("tempo")
+			},
+			setFunc: func(s *setup.SetupState) {
+				trackComponent("tempo")
+			},
+		},
+		// Alloy collects pod logs, events and OTLP for Loki, Prometheus and
+		// Tempo, replacing Promtail
+		{
+			name: "alloy",
+			checkFunc: func(s *setup.SetupState) bool {
+				return checkComponentStatus("alloy")
+			},
+			setFunc: func(s *setup.SetupState) {
+				trackComponent("alloy")
+			},
+		},
+		// The journal log agent runs on every host and pushes to Loki
+		{
+			name: "host-logs",
+			checkFunc: func(s *setup.SetupState) bool {
+				return checkTrackedComponent("host-logs")
+			},
+			setFunc: func(s *setup.SetupState) {
+				trackComponent("host-logs")
+			},
+		},
 		{
 			name: "grafana",
 			checkFunc: func(s *setup.SetupState) bool {
@@ -976,16 +1075,30 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 				trackComponent("velero")
 			},
 		},
+		// Probes come last so they cover the routes every earlier component
+		// exposed
+		{
+			name: "blackbox-exporter",
+			checkFunc: func(s *setup.SetupState) bool {
+				return checkComponentStatus("blackbox-exporter")
+			},
+			setFunc: func(s *setup.SetupState) {
+				trackComponent("blackbox-exporter")
+			},
+		},
 	}
 
 	// K8s components that can be upgraded with --upgrade flag
 	k8sComponents := map[string]bool{
 		"gateway-api": true, "contour": true, "cert-manager": true, "storage": true,
 		"seaweedfs": true, "prometheus": true, "external-dns": true,
-		"loki": true, "grafana": true, "velero": true,
-		"gateway-controller": true,
+		"loki": true, "tempo": true, "alloy": true, "grafana": true, "velero": true,
+		"gateway-controller": true, "blackbox-exporter": true,
 	}
 
+	// Components that run on every host
+	hostComponents := map[string]bool{"node-exporter": true, "host-logs": true}
+
 	for i, comp := range components {
 		// Opt-in components only install when explicitly enabled in the stack
 		// config (e.g. components.gateway-controller.enabled: true).
@@ -1000,7 +1113,9 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 		isK8sComponent := k8sComponents[comp.name]
 
 		if isInstalled {
-			if upgrade && isK8sComponent {
+			// Per-host components are reinstalled on upgrade to reach hosts
+			// added since
+			if upgrade && (isK8sComponent || hostComponents[comp.name]) {
 				fmt.Printf("\n[%d/%d] Upgrading %s...\n", i+1, len(components), comp.name)
 			} else {
 				fmt.Printf("\n[%d/%d] %s: ✓ Already installed (skipping)\n", i+1, len(components), comp.name)
@@ -1028,7 +1143,7 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 		}
 
 		// Install/upgrade the component
-		if err := installSingleComponent(ctx, cfg, comp.name); err != nil {
+		if err := installSingleComponent(ctx, cfg, comp.name, secretRefs); err != nil {
 			return fmt.Errorf("%s installation failed: %w", comp.name, err)
 		}
 
@@ -1064,8 +1179,68 @@ func installComponents(ctx context.Context, cfg *config.Config, configPath strin
 	return nil
 }
 
+// componentConfigOptions says how k8sComponentConfig builds a config
+type componentConfigOptions struct {
+	// planning builds the config only to see which images it runs, so no
+	// credentials are read or generated
+	planning bool
+	// secretRefs records the secrets resolved or generated for the config
+	secretRefs installSecrets
+}
+
+// k8sComponentConfig builds the config a Kubernetes component is installed
+// with from the stack config
+func k8sComponentConfig(ctx context.Context, cfg *config.Config, configDir, componentName string, opts componentConfigOptions) (component.ComponentConfig, error) {
+	switch componentName {
+	case "contour":
+		// Pass cluster VIP and domain to Contour for LoadBalancer and Gateway configuration
+		componentConfig := component.ComponentConfig{}
+		if cfg.Cluster.VIP != "" {
+			componentConfig["cluster_vip"] = cfg.Cluster.VIP
+		}
+		if cfg.Cluster.PrimaryDomain != "" {
+			componentConfig["gateway_domain"] = cfg.Cluster.PrimaryDomain
+		}
+		return componentConfig, nil
+	case "external-dns":
+		// Pass DNS provider config to external-dns
+		return buildExternalDNSConfig(ctx, cfg, configDir, opts), nil
+	case "storage":
+		// Pass storage backend config
+		return buildStorageConfig(ctx, cfg, opts), nil
+	case "seaweedfs":
+		return buildSeaweedFSConfig(ctx, cfg, configDir, opts), nil
+	case "prometheus":
+		// Prometheus scrapes the hosts with credentials from OpenBAO
+		return buildPrometheusConfig(ctx, cfg, configDir, opts)
+	case "loki":
+		// Loki needs SeaweedFS connection info
+		return buildLokiConfig(cfg, opts), nil
+	case "tempo":
+		// Tempo stores traces in SeaweedFS too
+		return buildTempoConfig(cfg, opts), nil
+	case "alloy":
+		// The collector pipeline points at the stack's Loki, Prometheus and Tempo
+		return buildAlloyConfig(cfg), nil
+	case "grafana":
+		// Grafana needs Prometheus, Loki and Tempo endpoints
+		return buildGrafanaConfig(cfg, opts), nil
+	case "velero":
+		// Velero needs SeaweedFS connection info
+		return buildVeleroConfig(cfg, opts), nil
+	case "blackbox-exporter":
+		// The stack's own service links are probed next to the discovered routes
+		return buildBlackboxConfig(cfg), nil
+	case "gateway-controller":
+		// Pass gateway-controller config (image, gateway/envoy targets, interval, …)
+		// straight from the stack config's components.gateway-controller block.
+		return buildGatewayControllerConfig(cfg), nil
+	}
+	return component.ComponentConfig{}, nil
+}
+
 // installK8sComponent installs a Kubernetes component using the cluster kubeconfig
-func installK8sComponent(ctx context.Context, cfg *config.Config, componentName string, comp component.Component) error {
+func installK8sComponent(ctx context.Context, cfg *config.Config, componentName string, comp component.Component, secretRefs installSecrets) error {
 	fmt.Printf("  Installing %s to Kubernetes cluster...\n", componentName)
 
 	// Get kubeconfig path
@@ -1086,6 +1261,12 @@ func installK8sComponent(ctx context.Context, cfg *config.Config, componentName
 	if err != nil {
 		return fmt.Errorf("failed to create helm client: %w", err)
 	}
+	if activeBundle != nil {
+		helmClient.UseLocalCharts(activeBundle.LocalCharts())
+	}
+	if activeLock != nil {
+		helmClient.UseLock(activeLock.HelmLock())
+	}
 
 	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
 	if err != nil {
@@ -1117,16 +1298,33 @@ func installK8sComponent(ctx context.Context, cfg *config.Config, componentName
 		componentWithClients = prometheus.NewComponent(helmClient, k8sClient)
 	case "loki":
 		componentWithClients = loki.NewComponent(helmClient, k8sClient)
+	case "tempo":
+		componentWithClients = tempo.NewComponent(helmClient, k8sClient)
+	case "alloy":
+		componentWithClients = alloy.NewComponent(helmClient, k8sClient)
 	case "grafana":
 		componentWithClients = grafana.NewComponent(helmClient, k8sClient)
 	case "velero":
 		componentWithClients = velero.NewComponent(helmClient, k8sClient)
+	case "blackbox-exporter":
+		componentWithClients = blackbox.NewComponent(helmClient, k8sClient)
 	default:
 		return fmt.Errorf("unknown kubernetes component: %s", componentName)
 	}
 
+	// Loki, Tempo and Velero share SeaweedFS' credential, so it is never
+	// replaced by one generated in its place
+	if componentName == "seaweedfs" {
+		if _, _, err := ensureSeaweedFSCredentials(ctx, cfg, configDir, secretRefs); err != nil {
+			return fmt.Errorf("failed to set up the SeaweedFS credentials: %w", err)
+		}
+	}
+
 	// Create component config with cluster-specific values
-	componentConfig := component.ComponentConfig{}
+	componentConfig, err := k8sComponentConfig(ctx, cfg, configDir, componentName, componentConfigOptions{secretRefs: secretRefs})
+	if err != nil {
+		return err
+	}
 
 	// Pass helm and k8s clients to components that need them via ComponentConfig
 	if componentName == "cert-manager" {
@@ -1134,55 +1332,17 @@ func installK8sComponent(ctx context.Context, cfg *config.Config, componentName
 		componentConfig["k8s_client"] = k8sClient
 	}
 
-	// Pass cluster VIP and domain to Contour for LoadBalancer and Gateway configuration
-	if componentName == "contour" {
-		if cfg.Cluster.VIP != "" {
-			componentConfig["cluster_vip"] = cfg.Cluster.VIP
+	// The Gateway API manifest comes from the bundle when installing offline
+	if componentName == "gateway-api" && activeBundle != nil {
+		gatewayCfg, err := gatewayapi.ParseConfig(componentConfig)
+		if err != nil {
+			return err
 		}
-		if cfg.Cluster.PrimaryDomain != "" {
-			componentConfig["gateway_domain"] = cfg.Cluster.PrimaryDomain
+		path, ok := activeBundle.File(gatewayapi.ManifestURL(gatewayCfg.Version))
+		if !ok {
+			return fmt.Errorf("bundle has no Gateway API %s manifest", gatewayCfg.Version)
 		}
-	}
-
-	// Pass DNS provider config to external-dns
-	if componentName == "external-dns" {
-		componentConfig = buildExternalDNSConfig(ctx, cfg, configDir)
-	}
-
-	// Pass storage backend config
-	if componentName == "storage" {
-		componentConfig = buildStorageConfig(ctx, cfg)
-	}
-
-	// Pass SeaweedFS config
-	if componentName == "seaweedfs" {
-		componentConfig = buildSeaweedFSConfig(cfg)
-	}
-
-	// Pass Prometheus config
-	if componentName == "prometheus" {
-		componentConfig = buildPrometheusConfig(cfg)
-	}
-
-	// Pass Loki config (needs SeaweedFS connection info)
-	if componentName == "loki" {
-		componentConfig = buildLokiConfig(cfg)
-	}
-
-	// Pass Grafana config (needs Prometheus and Loki endpoints)
-	if componentName == "grafana" {
-		componentConfig = buildGrafanaConfig(cfg)
-	}
-
-	// Pass Velero config (needs SeaweedFS connection info)
-	if componentName == "velero" {
-		componentConfig = buildVeleroConfig(cfg)
-	}
-
-	// Pass gateway-controller config (image, gateway/envoy targets, interval, …)
-	// straight from the stack config's components.gateway-controller block.
-	if componentName == "gateway-controller" {
-		componentConfig = buildGatewayControllerConfig(cfg)
+		componentConfig["manifest_path"] = path
 	}
 
 	// Install the component
@@ -1192,7 +1352,7 @@ func installK8sComponent(ctx context.Context, cfg *config.Config, componentName
 
 	// Save component config (including values) back to stack config
 	// This allows users to see and customize the Helm values
-	saveComponentConfig(cfg, componentName, componentConfig)
+	saveComponentConfig(cfg, componentName, componentConfig, secretRefs)
 
 	fmt.Printf("  ✓ %s installed successfully\n", componentName)
 
@@ -1220,6 +1380,80 @@ func installK8sComponent(ctx context.Context, cfg *config.Config, componentName
 		}
 	}
 
+	// Once cert-manager has created the internal CA, host services (OpenBAO,
+	// Zot) can get certificates if host_tls is configured. Non-fatal.
+	if componentName == "cert-manager" && cfg.HostTLS != nil {
+		if err := issueHostCertificates(ctx, cfg, configDir, k8sClient); err != nil {
+			fmt.Printf("  ⚠ Host service certificates not issued (run 'foundry host tls issue'): %v\n", err)
+		} else if registry.TLSEnabled(cfg) && cfg.SetupState.ZotInstalled {
+			// Zot and the nodes' registries.yaml switch to HTTPS now that
+			// Zot has a certificate
+			if err := registrycmd.Sync(ctx, cfg, configDir, os.Stdout); err != nil {
+				fmt.Printf("  ⚠ Registry not switched to HTTPS (run 'foundry host tls issue zot'): %v\n", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// issueHostCertificates issues and installs TLS certificates for the host
+// services configured under host_tls, and sets up their renewal timers
+func issueHostCertificates(ctx context.Context, cfg *config.Config, configDir string, k8sClient *k8s.Client) error {
+	opts, err := hosttls.OptionsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	targets, err := hosttls.Targets(cfg, nil)
+	if err != nil || len(targets) == 0 {
+		return err
+	}
+
+	input := hosttls.ApplyInput{Options: opts}
+	switch opts.Issuer {
+	case config.HostTLSIssuerInternalCA:
+		// cert-manager signs the CA certificate asynchronously after install
+		for attempt := 0; ; attempt++ {
+			input.CA, err = hosttls.LoadClusterCA(ctx, k8sClient)
+			if err == nil {
+				break
+			}
+			if attempt == 11 {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
+		}
+	case config.HostTLSIssuerACME:
+		dnsAddr, err := cfg.GetPrimaryDNSAddress()
+		if err != nil {
+			return fmt.Errorf("ACME DNS-01 requires the dns role: %w", err)
+		}
+		input.DNSAPIURL = fmt.Sprintf("http://%s:8081", dnsAddr)
+		input.DNSAPIKey, err = getDNSAPIKeyFromOpenBAO(ctx, cfg, configDir)
+		if err != nil {
+			return fmt.Errorf("failed to read PowerDNS API key: %w", err)
+		}
+	}
+
+	for _, target := range targets {
+		conn, err := connectToHost(target.Host, cfg.Cluster.Name)
+		if err != nil {
+			return err
+		}
+		input.Target = target
+		err = hosttls.Apply(&sshExecutorAdapter{conn: conn}, input)
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", target.Host.Hostname, err)
+		}
+		for _, svc := range target.Services {
+			fmt.Printf("  ✓ TLS certificate installed for %s\n", svc.FQDN(opts.Domain))
+		}
+	}
 	return nil
 }
 
@@ -1227,6 +1461,9 @@ func installK8sComponent(ctx context.Context, cfg *config.Config, componentName
 // after Prometheus has been installed and the CRD is available
 func upgradeStorageWithServiceMonitor(ctx context.Context, cfg *config.Config, helmClient *helm.Client, k8sClient *k8s.Client) error {
 	// Only upgrade if storage backend is Longhorn
+	if cfg.Storage != nil && cfg.Storage.Backend != "" && cfg.Storage.Backend != "longhorn" {
+		return nil
+	}
 	if cfg.Storage == nil || cfg.Storage.Backend != "longhorn" {
 		// Check component config too
 		if cfg.Components != nil {
@@ -1252,7 +1489,7 @@ func upgradeStorageWithServiceMonitor(ctx context.Context, cfg *config.Config, h
 	storageComp := storage.NewComponent(helmClient, k8sClient)
 
 	// Build config with ServiceMonitor enabled
-	componentConfig := buildStorageConfig(ctx, cfg)
+	componentConfig := buildStorageConfig(ctx, cfg, componentConfigOptions{})
 	// Ensure ServiceMonitor is enabled for the upgrade
 	if longhornCfg, ok := componentConfig["longhorn"].(map[string]interface{}); ok {
 		longhornCfg["service_monitor_enabled"] = true
@@ -1268,7 +1505,7 @@ func upgradeStorageWithServiceMonitor(ctx context.Context, cfg *config.Config, h
 }
 
 // buildExternalDNSConfig creates config for external-dns component
-func buildExternalDNSConfig(ctx context.Context, cfg *config.Config, configDir string) component.ComponentConfig {
+func buildExternalDNSConfig(ctx context.Context, cfg *config.Config, configDir string, opts componentConfigOptions) component.ComponentConfig {
 	// Build PowerDNS config
 	pdnsConfig := map[string]interface{}{}
 	var pdnsAPIURL string
@@ -1281,11 +1518,12 @@ func buildExternalDNSConfig(ctx context.Context, cfg *config.Config, configDir s
 	}
 
 	// Get DNS API key from OpenBAO
-	if cfg.SetupState != nil && cfg.SetupState.OpenBAOInitialized {
+	if !opts.planning && cfg.SetupState != nil && cfg.SetupState.OpenBAOInitialized {
 		apiKey, err := getDNSAPIKeyFromOpenBAO(ctx, cfg, configDir)
 		if err == nil && apiKey != "" {
 			pdnsAPIKey = apiKey
 			pdnsConfig["api_key"] = apiKey
+			opts.secretRefs.remember(apiKey, "${secret:dns:api_key}")
 		}
 	}
 
@@ -1344,7 +1582,7 @@ resources:
 	}
 
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "external-dns"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "external-dns", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
@@ -1392,7 +1630,7 @@ func getDNSAPIKeyFromOpenBAO(ctx context.Context, cfg *config.Config, configDir
 }
 
 // buildStorageConfig creates config for storage component
-func buildStorageConfig(ctx context.Context, cfg *config.Config) component.ComponentConfig {
+func buildStorageConfig(ctx context.Context, cfg *config.Config, opts componentConfigOptions) component.ComponentConfig {
 	// Calculate optimal replica count based on configured cluster nodes
 	replicaCount := calculateOptimalReplicaCount(cfg)
 	ingressHost := fmt.Sprintf("longhorn.%s", cfg.Cluster.PrimaryDomain)
@@ -1448,8 +1686,44 @@ storageClass:
 `)
 	}
 
+	// TrueNAS provisions through democratic-csi, with the API key from OpenBAO
+	if cfg.Storage != nil && cfg.Storage.Backend == "truenas" {
+		componentConfig["backend"] = "truenas"
+		delete(componentConfig, "longhorn")
+		defaultValues = map[string]interface{}{}
+		driver := storage.TrueNASDriverNFS
+		if cfg.Storage.TrueNAS != nil {
+			if len(cfg.Storage.TrueNAS.Drivers) > 0 {
+				driver = cfg.Storage.TrueNAS.Drivers[0]
+			}
+			componentConfig["truenas"] = resolveTrueNASSecrets(cfg, storage.TrueNASComponentConfig(cfg.Storage.TrueNAS), opts.secretRefs)
+		}
+		componentConfig["storage_class_name"] = storage.TrueNASStorageClass(driver)
+	}
+
+	// Snapshot and backup schedules, with the backup target's credentials
+	// from OpenBAO
+	compCfg, exists := cfg.Components["storage"]
+	if _, longhorn := componentConfig["longhorn"]; longhorn && exists && compCfg.Config != nil {
+		if userLonghorn, ok := compCfg.Config["longhorn"].(map[string]interface{}); ok {
+			if jobs, ok := userLonghorn["recurring_jobs"]; ok {
+				longhornConfig["recurring_jobs"] = jobs
+			}
+			if backup, ok := userLonghorn["backup"].(map[string]interface{}); ok {
+				longhornConfig["backup"] = resolveLonghornBackupSecrets(ctx, cfg, backup, opts.secretRefs)
+			}
+		}
+	}
+
+	// smartctl_exporter runs alongside any backend
+	if exists && compCfg.Config != nil {
+		if exporter, ok := compCfg.Config["smart_exporter"].(map[string]interface{}); ok {
+			componentConfig["smart_exporter"] = exporter
+		}
+	}
+
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "storage"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "storage", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
@@ -1479,55 +1753,85 @@ const (
 	seaweedfsRegion   = "us-east-1"
 )
 
-// getOrCreateSeaweedFSCredentials retrieves SeaweedFS credentials from config or generates new ones
-func getOrCreateSeaweedFSCredentials(cfg *config.Config) (accessKey, secretKey string) {
-	// Check if credentials already exist in config
-	if cfg.Components != nil {
-		if seaweedfsCfg, exists := cfg.Components["seaweedfs"]; exists {
-			if seaweedfsCfg.Config != nil {
-				if key, ok := seaweedfsCfg.Config["access_key"].(string); ok && key != "" {
-					if secret, ok := seaweedfsCfg.Config["secret_key"].(string); ok && secret != "" {
-						return key, secret
-					}
-				}
-			}
+// ensureSeaweedFSCredentials returns the stack's shared S3 credential. It is
+// stored in OpenBAO and components.seaweedfs holds references to it; keys
+// typed into the config, or left there by an older release, are moved to
+// OpenBAO, and new ones are generated on first install.
+func ensureSeaweedFSCredentials(ctx context.Context, cfg *config.Config, configDir string, secretRefs installSecrets) (accessKey, secretKey string, err error) {
+	seaweedfsCfg := cfg.Components["seaweedfs"]
+	accessKey, _ = seaweedfsCfg.Config["access_key"].(string)
+	secretKey, _ = seaweedfsCfg.Config["secret_key"].(string)
+
+	if isSecretRef(accessKey) || isSecretRef(secretKey) {
+		resolved, err := resolveConfigSecrets(cfg, configDir, map[string]interface{}{
+			"access_key": accessKey,
+			"secret_key": secretKey,
+		}, secretRefs)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve the SeaweedFS credentials: %w", err)
 		}
+		keys := resolved.(map[string]interface{})
+		return keys["access_key"].(string), keys["secret_key"].(string), nil
 	}
 
-	// Generate new random credentials
-	keyBytes := make([]byte, 32)
-	secretBytes := make([]byte, 32)
-	if _, err := rand.Read(keyBytes); err != nil {
-		accessKey = fmt.Sprintf("foundry-key-%d", time.Now().UnixNano())
-	} else {
-		accessKey = hex.EncodeToString(keyBytes)
+	openBAOAddr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return "", "", err
 	}
-	if _, err := rand.Read(secretBytes); err != nil {
-		secretKey = fmt.Sprintf("foundry-secret-%d", time.Now().UnixNano())
-	} else {
-		secretKey = hex.EncodeToString(secretBytes)
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load OpenBAO keys: %w", err)
 	}
-	return accessKey, secretKey
-}
+	client := openbao.NewClient(openBAOAddr, keyMaterial.RootToken)
 
-// buildSeaweedFSConfig creates config for SeaweedFS component
-func buildSeaweedFSConfig(cfg *config.Config) component.ComponentConfig {
-	accessKey, secretKey := getOrCreateSeaweedFSCredentials(cfg)
+	// Keys in the config are the ones SeaweedFS runs with, so they are kept
+	inConfig := accessKey != "" && secretKey != ""
+	keep := func(value string) func() (string, error) {
+		return func() (string, error) {
+			if inConfig {
+				return value, nil
+			}
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return "", fmt.Errorf("failed to generate SeaweedFS key: %w", err)
+			}
+			return hex.EncodeToString(key), nil
+		}
+	}
+	if accessKey, err = client.EnsureSecretValue(ctx, "foundry-core", seaweedfs.CredentialsSecretPath, "access_key", keep(accessKey)); err != nil {
+		return "", "", err
+	}
+	if secretKey, err = client.EnsureSecretValue(ctx, "foundry-core", seaweedfs.CredentialsSecretPath, "secret_key", keep(secretKey)); err != nil {
+		return "", "", err
+	}
+	if inConfig {
+		fmt.Println("  ✓ SeaweedFS credentials moved from the stack config to OpenBAO")
+	}
 
-	// Store credentials in config for other components to use
 	if cfg.Components == nil {
 		cfg.Components = make(config.ComponentMap)
 	}
-	if _, exists := cfg.Components["seaweedfs"]; !exists {
-		cfg.Components["seaweedfs"] = config.ComponentConfig{Config: make(map[string]any)}
-	}
-	seaweedfsCfg := cfg.Components["seaweedfs"]
 	if seaweedfsCfg.Config == nil {
 		seaweedfsCfg.Config = make(map[string]any)
 	}
-	seaweedfsCfg.Config["access_key"] = accessKey
-	seaweedfsCfg.Config["secret_key"] = secretKey
+	seaweedfsCfg.Config["access_key"] = seaweedfs.AccessKeyRef
+	seaweedfsCfg.Config["secret_key"] = seaweedfs.SecretKeyRef
 	cfg.Components["seaweedfs"] = seaweedfsCfg
+	secretRefs.remember(accessKey, seaweedfs.AccessKeyRef)
+	secretRefs.remember(secretKey, seaweedfs.SecretKeyRef)
+	return accessKey, secretKey, nil
+}
+
+// buildSeaweedFSConfig creates config for SeaweedFS component. Planning
+// leaves the credentials out.
+func buildSeaweedFSConfig(ctx context.Context, cfg *config.Config, configDir string, opts componentConfigOptions) component.ComponentConfig {
+	var accessKey, secretKey string
+	if !opts.planning {
+		var err error
+		if accessKey, secretKey, err = ensureSeaweedFSCredentials(ctx, cfg, configDir, opts.secretRefs); err != nil {
+			fmt.Printf("  ⚠ SeaweedFS credentials not available: %v\n", err)
+		}
+	}
 
 	ingressHostFiler := fmt.Sprintf("seaweedfs.%s", cfg.Cluster.PrimaryDomain)
 	ingressHostS3 := fmt.Sprintf("s3.%s", cfg.Cluster.PrimaryDomain)
@@ -1577,16 +1881,19 @@ s3:
 		"namespace":          "seaweedfs",
 		"storage_size":       "50Gi",
 		"storage_class":      "longhorn",
-		"access_key":         accessKey,
-		"secret_key":         secretKey,
-		"buckets":            []string{"loki", "velero"},
+		"buckets":            seaweedfsBuckets(cfg),
 		"ingress_enabled":    true,
 		"ingress_host_filer": ingressHostFiler,
 		"ingress_host_s3":    ingressHostS3,
 	}
 
+	if accessKey != "" && secretKey != "" {
+		componentConfig["access_key"] = accessKey
+		componentConfig["secret_key"] = secretKey
+	}
+
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "seaweedfs"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "seaweedfs", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
@@ -1595,8 +1902,37 @@ s3:
 	return componentConfig
 }
 
-// getSeaweedFSCredentials retrieves SeaweedFS credentials from config
-func getSeaweedFSCredentials(cfg *config.Config) (accessKey, secretKey string) {
+// seaweedfsBuckets lists the buckets SeaweedFS creates: those of the
+// logging, tracing and backup components, and Longhorn's backup bucket when
+// Longhorn backs up to SeaweedFS
+func seaweedfsBuckets(cfg *config.Config) []string {
+	buckets := []string{"loki", "tempo", "velero"}
+	if cfg.Storage != nil && cfg.Storage.Backend != "" && cfg.Storage.Backend != "longhorn" {
+		return buckets
+	}
+	compCfg, exists := cfg.Components["storage"]
+	if !exists || compCfg.Config == nil {
+		return buckets
+	}
+	userLonghorn, _ := compCfg.Config["longhorn"].(map[string]interface{})
+	backup, ok := userLonghorn["backup"].(map[string]interface{})
+	if !ok {
+		return buckets
+	}
+	if endpoint, _ := backup["endpoint"].(string); endpoint != "" && endpoint != seaweedfsEndpoint {
+		return buckets
+	}
+	bucket, _ := backup["bucket"].(string)
+	if bucket == "" {
+		bucket = storage.DefaultLonghornBackupBucket
+	}
+	return append(buckets, bucket)
+}
+
+// getSeaweedFSCredentials retrieves SeaweedFS credentials from config,
+// resolving the references to OpenBAO SeaweedFS' install leaves there.
+// Planning does not read them.
+func getSeaweedFSCredentials(cfg *config.Config, opts componentConfigOptions) (accessKey, secretKey string) {
 	accessKey = ""
 	secretKey = ""
 
@@ -1613,11 +1949,30 @@ func getSeaweedFSCredentials(cfg *config.Config) (accessKey, secretKey string) {
 		}
 	}
 
-	return accessKey, secretKey
+	if !isSecretRef(accessKey) && !isSecretRef(secretKey) {
+		return accessKey, secretKey
+	}
+	if opts.planning {
+		return "", ""
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", ""
+	}
+	resolved, err := resolveConfigSecrets(cfg, configDir, map[string]interface{}{
+		"access_key": accessKey,
+		"secret_key": secretKey,
+	}, opts.secretRefs)
+	if err != nil {
+		fmt.Printf("  ⚠ SeaweedFS credentials not resolved: %v\n", err)
+		return "", ""
+	}
+	keys := resolved.(map[string]interface{})
+	return keys["access_key"].(string), keys["secret_key"].(string)
 }
 
 // buildPrometheusConfig creates config for Prometheus component
-func buildPrometheusConfig(cfg *config.Config) component.ComponentConfig {
+func buildPrometheusConfig(ctx context.Context, cfg *config.Config, configDir string, opts componentConfigOptions) (component.ComponentConfig, error) {
 	ingressHost := fmt.Sprintf("prometheus.%s", cfg.Cluster.PrimaryDomain)
 
 	// Defaults that can be overridden from components.prometheus in config YAML
@@ -1720,19 +2075,107 @@ prometheusOperator:
 		"ingress_host":    ingressHost,
 	}
 
+	// Built-in alert rules, the hosts they watch and where alerts go
+	if compCfg, exists := cfg.Components["prometheus"]; exists && compCfg.Config != nil {
+		for _, key := range []string{"alert_rules_enabled", "alert_rules", "alertmanager_enabled"} {
+			if v, ok := compCfg.Config[key]; ok {
+				componentConfig[key] = v
+			}
+		}
+		// Planning reads no credentials
+		if alerting, ok := compCfg.Config["alerting"]; ok && !opts.planning {
+			resolved, err := resolveConfigSecrets(cfg, configDir, alerting, opts.secretRefs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve alerting secrets: %w", err)
+			}
+			componentConfig["alerting"] = resolved
+		}
+	}
+	if addr, err := cfg.GetPrimaryZotAddress(); err == nil {
+		componentConfig["zot_host"] = addr
+	}
+	if addrs := cfg.GetHostAddresses(host.RoleClusterControlPlane); len(addrs) > 0 {
+		componentConfig["etcd_endpoints"] = addrs
+	}
+
+	// Scrape targets for the hosts and their services, after any typed by hand
+	var externalTargets []interface{}
+	if compCfg, exists := cfg.Components["prometheus"]; exists && compCfg.Config != nil {
+		if targets, ok := compCfg.Config["external_targets"].([]interface{}); ok {
+			externalTargets = append(externalTargets, targets...)
+		}
+	}
+	var hostCreds prometheus.HostCredentials
+	if !opts.planning {
+		hostCreds = hostMetricsCredentials(ctx, cfg, configDir)
+	}
+	for _, target := range prometheus.HostTargets(cfg, hostCreds) {
+		externalTargets = append(externalTargets, target)
+	}
+	if len(externalTargets) > 0 {
+		componentConfig["external_targets"] = externalTargets
+	}
+
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "prometheus"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "prometheus", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
 	}
 
-	return componentConfig
+	return componentConfig, nil
+}
+
+// resolveConfigSecrets resolves the ${secret:...} references in a section of
+// a component's config from the environment or OpenBAO. The result is a
+// copy, so the secrets never end up in the stack config.
+func resolveConfigSecrets(cfg *config.Config, configDir string, section interface{}, secretRefs installSecrets) (interface{}, error) {
+	resolvers := []secrets.Resolver{secrets.NewEnvResolver()}
+	if openBAOAddr, err := cfg.GetPrimaryOpenBAOURL(); err == nil {
+		if keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name); err == nil {
+			if resolver, err := secrets.NewOpenBAOResolverWithMount(openBAOAddr, keyMaterial.RootToken, "foundry-core"); err == nil {
+				resolvers = append(resolvers, resolver)
+			}
+		}
+	}
+	resolver := recordingResolver{secrets.NewChainResolver(resolvers...), secretRefs}
+	return secrets.ResolveRefs(section, resolver, &secrets.ResolutionContext{})
+}
+
+// installSecrets maps the secrets resolved or generated during one install
+// run to the references that stand in for them, so saveComponentConfig can
+// put the references back before a component's config is saved
+type installSecrets map[string]string
+
+// minInstallSecretLen keeps short values, such as a username stored next to
+// a password, from being swapped for references wherever they appear
+const minInstallSecretLen = 8
+
+// remember records a secret and its reference. A nil map, as used when
+// planning, records nothing.
+func (s installSecrets) remember(value, ref string) {
+	if s != nil && len(value) >= minInstallSecretLen {
+		s[value] = ref
+	}
+}
+
+// recordingResolver remembers every secret it resolves
+type recordingResolver struct {
+	secrets.Resolver
+	secretRefs installSecrets
+}
+
+func (r recordingResolver) Resolve(ctx *secrets.ResolutionContext, ref secrets.SecretRef) (string, error) {
+	value, err := r.Resolver.Resolve(ctx, ref)
+	if err == nil {
+		r.secretRefs.remember(value, ref.String())
+	}
+	return value, err
 }
 
 // buildLokiConfig creates config for Loki component
-func buildLokiConfig(cfg *config.Config) component.ComponentConfig {
-	accessKey, secretKey := getSeaweedFSCredentials(cfg)
+func buildLokiConfig(cfg *config.Config, opts componentConfigOptions) component.ComponentConfig {
+	accessKey, secretKey := getSeaweedFSCredentials(cfg, opts)
 	ingressHost := fmt.Sprintf("loki.%s", cfg.Cluster.PrimaryDomain)
 
 	// Default Helm values for Loki (YAML format for readability)
@@ -1803,13 +2246,13 @@ backend:
 		"s3_access_key":    accessKey,
 		"s3_secret_key":    secretKey,
 		"s3_region":        seaweedfsRegion,
-		"promtail_enabled": true,
+		"promtail_enabled": false, // Alloy collects pod logs
 		"ingress_enabled":  true,
 		"ingress_host":     ingressHost,
 	}
 
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "loki"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "loki", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
@@ -1818,11 +2261,81 @@ backend:
 	return componentConfig
 }
 
+// buildTempoConfig creates config for Tempo component. Settings such as
+// gateway_enabled and retention_days come from components.tempo in the stack
+// config; storage always points at SeaweedFS.
+func buildTempoConfig(cfg *config.Config, opts componentConfigOptions) component.ComponentConfig {
+	accessKey, secretKey := getSeaweedFSCredentials(cfg, opts)
+
+	componentConfig := component.ComponentConfig{}
+	if tc, ok := cfg.Components["tempo"]; ok {
+		for k, v := range tc.Config {
+			componentConfig[k] = v
+		}
+	}
+
+	componentConfig["namespace"] = "monitoring"
+	componentConfig["storage_backend"] = "s3"
+	componentConfig["s3_endpoint"] = seaweedfsEndpoint
+	componentConfig["s3_bucket"] = "tempo"
+	componentConfig["s3_access_key"] = accessKey
+	componentConfig["s3_secret_key"] = secretKey
+	componentConfig["s3_region"] = seaweedfsRegion
+	if _, ok := componentConfig["storage_class"]; !ok {
+		componentConfig["storage_class"] = "longhorn"
+	}
+
+	return componentConfig
+}
+
+// buildAlloyConfig creates config for the Alloy collector. Settings such as
+// otlp_enabled and events_enabled come from components.alloy in the stack
+// config; the destinations are the stack's Loki, Prometheus and Tempo.
+func buildAlloyConfig(cfg *config.Config) component.ComponentConfig {
+	componentConfig := component.ComponentConfig{}
+	if ac, ok := cfg.Components["alloy"]; ok {
+		for k, v := range ac.Config {
+			componentConfig[k] = v
+		}
+	}
+
+	componentConfig["namespace"] = "monitoring"
+	componentConfig["loki_url"] = "http://loki-gateway.monitoring.svc.cluster.local:80/loki/api/v1/push"
+	componentConfig["prometheus_remote_write_url"] = "http://kube-prometheus-stack-prometheus.monitoring.svc.cluster.local:9090/api/v1/write"
+	componentConfig["tempo_endpoint"] = "tempo.monitoring.svc.cluster.local:4317"
+	// Promtail was installed next to Loki
+	componentConfig["promtail_namespace"] = "monitoring"
+
+	return componentConfig
+}
+
+// buildBlackboxConfig creates config for blackbox-exporter. Settings such as
+// interval and targets come from components.blackbox-exporter in the stack
+// config; the service links it declares are probed too.
+func buildBlackboxConfig(cfg *config.Config) component.ComponentConfig {
+	componentConfig := component.ComponentConfig{}
+	if bc, ok := cfg.Components["blackbox-exporter"]; ok {
+		for k, v := range bc.Config {
+			componentConfig[k] = v
+		}
+	}
+
+	componentConfig["namespace"] = "monitoring"
+	links := map[string]string{}
+	for _, link := range discovery.ConfiguredLinks(cfg) {
+		links[link.URL] = link.Name
+	}
+	componentConfig["service_links"] = links
+
+	return componentConfig
+}
+
 // buildGrafanaConfig creates config for Grafana component
-func buildGrafanaConfig(cfg *config.Config) component.ComponentConfig {
+func buildGrafanaConfig(cfg *config.Config, opts componentConfigOptions) component.ComponentConfig {
 	ingressHost := fmt.Sprintf("grafana.%s", cfg.Cluster.PrimaryDomain)
 	prometheusURL := "http://kube-prometheus-stack-prometheus.monitoring.svc.cluster.local:9090"
 	lokiURL := "http://loki-gateway.monitoring.svc.cluster.local:80"
+	tempoURL := "http://tempo.monitoring.svc.cluster.local:3200"
 
 	// Default Helm values for Grafana (YAML format for readability)
 	defaultValuesYAML := fmt.Sprintf(`
@@ -1884,12 +2397,13 @@ dashboardProviders:
 		"storage_class":   "longhorn",
 		"prometheus_url":  prometheusURL,
 		"loki_url":        lokiURL,
+		"tempo_url":       tempoURL,
 		"ingress_enabled": true,
 		"ingress_host":    ingressHost,
 	}
 
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "grafana"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "grafana", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
@@ -1899,8 +2413,8 @@ dashboardProviders:
 }
 
 // buildVeleroConfig creates config for Velero component
-func buildVeleroConfig(cfg *config.Config) component.ComponentConfig {
-	accessKey, secretKey := getSeaweedFSCredentials(cfg)
+func buildVeleroConfig(cfg *config.Config, opts componentConfigOptions) component.ComponentConfig {
+	accessKey, secretKey := getSeaweedFSCredentials(cfg, opts)
 
 	// Default Helm values for Velero (YAML format for readability)
 	// Note: credentials.secretContents.cloud uses INI format, handled separately
@@ -1963,8 +2477,15 @@ schedules:
 		"schedule_name": "daily-backup",
 	}
 
+	// Off-site replication, with its credentials from OpenBAO
+	if compCfg, exists := cfg.Components["velero"]; exists && compCfg.Config != nil {
+		if offsite, ok := compCfg.Config["offsite"].(map[string]interface{}); ok {
+			componentConfig["offsite"] = resolveOffsiteSecrets(cfg, offsite, opts.secretRefs)
+		}
+	}
+
 	// Merge user-provided values over defaults (user values take precedence)
-	if userValues := getUserValuesFromConfig(cfg, "velero"); userValues != nil {
+	if userValues := getUserValuesFromConfig(cfg, "velero", opts); userValues != nil {
 		componentConfig["values"] = mergeValues(defaultValues, userValues)
 	} else {
 		componentConfig["values"] = defaultValues
@@ -1973,8 +2494,105 @@ schedules:
 	return componentConfig
 }
 
+// resolveOffsiteSecrets resolves the off-site credentials, which default to
+// OpenBAO. Unresolved references are left in place for the velero config's
+// validation to report.
+func resolveOffsiteSecrets(cfg *config.Config, offsite map[string]interface{}, secretRefs installSecrets) interface{} {
+	withRefs := make(map[string]interface{}, len(offsite))
+	for k, v := range offsite {
+		withRefs[k] = v
+	}
+	velero.DefaultOffsiteRefs(withRefs)
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return withRefs
+	}
+	resolved, err := resolveConfigSecrets(cfg, configDir, withRefs, secretRefs)
+	if err != nil {
+		fmt.Printf("  ⚠ Off-site backup credentials not resolved: %v\n", err)
+		return withRefs
+	}
+	return resolved
+}
+
+// resolveTrueNASSecrets resolves the TrueNAS API key, which defaults to
+// OpenBAO. An unresolved reference is left in place for the storage config's
+// validation to report.
+func resolveTrueNASSecrets(cfg *config.Config, section map[string]interface{}, secretRefs installSecrets) interface{} {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return section
+	}
+	resolved, err := resolveConfigSecrets(cfg, configDir, section, secretRefs)
+	if err != nil {
+		fmt.Printf("  ⚠ TrueNAS API key not resolved: %v\n", err)
+		return section
+	}
+	return resolved
+}
+
+// resolveLonghornBackupSecrets fills in Longhorn's backup target, which
+// defaults to a bucket on the stack's SeaweedFS with credentials from
+// OpenBAO. Unresolved references are left in place for the storage config's
+// validation to report.
+func resolveLonghornBackupSecrets(ctx context.Context, cfg *config.Config, backup map[string]interface{}, secretRefs installSecrets) interface{} {
+	withRefs := make(map[string]interface{}, len(backup))
+	for k, v := range backup {
+		withRefs[k] = v
+	}
+	if s, _ := withRefs["endpoint"].(string); s == "" {
+		withRefs["endpoint"] = seaweedfsEndpoint
+	}
+	storage.DefaultLonghornBackupRefs(withRefs)
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return withRefs
+	}
+	if withRefs["endpoint"] == seaweedfsEndpoint {
+		if err := seedLonghornBackupSecret(ctx, cfg, configDir, secretRefs); err != nil {
+			fmt.Printf("  ⚠ Could not store the Longhorn backup credentials in OpenBAO: %v\n", err)
+		}
+	}
+	resolved, err := resolveConfigSecrets(cfg, configDir, withRefs, secretRefs)
+	if err != nil {
+		fmt.Printf("  ⚠ Longhorn backup credentials not resolved: %v\n", err)
+		return withRefs
+	}
+	return resolved
+}
+
+// seedLonghornBackupSecret stores the SeaweedFS credentials at the Longhorn
+// backup path in OpenBAO unless something is stored there already. Storage
+// is installed before SeaweedFS, so the credentials may be created here;
+// SeaweedFS picks them up from OpenBAO when it is installed.
+func seedLonghornBackupSecret(ctx context.Context, cfg *config.Config, configDir string, secretRefs installSecrets) error {
+	openBAOAddr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return err
+	}
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenBAO keys: %w", err)
+	}
+	client := openbao.NewClient(openBAOAddr, keyMaterial.RootToken)
+	if existing, err := client.ReadSecretV2(ctx, "foundry-core", storage.LonghornBackupSecretPath); err == nil && existing["access_key"] != nil {
+		return nil
+	}
+
+	accessKey, secretKey, err := ensureSeaweedFSCredentials(ctx, cfg, configDir, secretRefs)
+	if err != nil {
+		return err
+	}
+	return client.WriteSecretV2(ctx, "foundry-core", storage.LonghornBackupSecretPath, map[string]interface{}{
+		"access_key": accessKey,
+		"secret_key": secretKey,
+	})
+}
+
 // installSingleComponent installs a single component with proper configuration
-func installSingleComponent(ctx context.Context, cfg *config.Config, componentName string) error {
+func installSingleComponent(ctx context.Context, cfg *config.Config, componentName string, secretRefs installSecrets) error {
 	// Get component from registry
 	comp := component.Get(componentName)
 	if comp == nil {
@@ -1997,11 +2615,24 @@ func installSingleComponent(ctx context.Context, cfg *config.Config, componentNa
 		"seaweedfs":          true,
 		"prometheus":         true,
 		"loki":               true,
+		"tempo":              true,
+		"alloy":              true,
 		"grafana":            true,
 		"velero":             true,
+		"blackbox-exporter":  true,
 	}
 	if k8sComponents[componentName] {
-		return installK8sComponent(ctx, cfg, componentName, comp)
+		return installK8sComponent(ctx, cfg, componentName, comp, secretRefs)
+	}
+
+	// node_exporter is installed on every host rather than one target host
+	if componentName == "node-exporter" {
+		return installNodeExporters(ctx, cfg, comp)
+	}
+
+	// The log agent is installed on every host, once Loki is up
+	if componentName == "host-logs" {
+		return installHostLogAgents(ctx, cfg, comp)
 	}
 
 	// Get target host for this component
@@ -2030,6 +2661,13 @@ func installSingleComponent(ctx context.Context, cfg *config.Config, componentNa
 		return fmt.Errorf("failed to build component config: %w", err)
 	}
 
+	if err := loadBundleImages(ctx, conn, componentName); err != nil {
+		return err
+	}
+	if activeLock != nil {
+		componentConfig["image_digests"] = activeLock.ImageDigests()
+	}
+
 	// Install the component
 	fmt.Printf("  Installing %s...\n", componentName)
 	if err := comp.Install(ctx, componentConfig); err != nil {
@@ -2082,6 +2720,11 @@ func buildComponentConfig(ctx context.Context, cfg *config.Config, componentName
 		"cluster_name": cfg.Cluster.Name,
 		"keys_dir":     filepath.Join(configDir, "openbao-keys"),
 	}
+	if key, ok := hostImageVersionKeys[componentName]; ok {
+		if version := hostImageVersion(cfg, componentName); version != "" {
+			compCfg[key] = version
+		}
+	}
 
 	// Component-specific configuration
 	switch componentName {
@@ -2099,6 +2742,13 @@ func buildComponentConfig(ctx context.Context, cfg *config.Config, componentName
 				return nil, fmt.Errorf("failed to setup DNS API key: %w", err)
 			}
 			compCfg["api_key"] = apiKey
+
+			// Prometheus scrapes /metrics with this password
+			password, err := ensureDNSMetricsPassword(ctx, cfg, configDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to setup DNS metrics password: %w", err)
+			}
+			compCfg["metrics_password"] = password
 		}
 
 		// Pass all zones as local zones for Recursor forwarding
@@ -2142,6 +2792,21 @@ func buildComponentConfig(ctx context.Context, cfg *config.Config, componentName
 				compCfg["docker_hub_password"] = password
 			}
 		}
+
+		// auth, tls and access_control are rendered by Foundry
+		for key, value := range registry.ManagedSettings(cfg) {
+			compCfg[key] = value
+		}
+		if registry.AuthEnabled(cfg) {
+			if !cfg.SetupState.OpenBAOInitialized {
+				return nil, fmt.Errorf("zot authentication requires an initialized OpenBAO to store registry accounts")
+			}
+			users, err := ensureRegistryUsers(ctx, cfg, configDir)
+			if err != nil {
+				return nil, err
+			}
+			compCfg["users"] = users
+		}
 	}
 
 	return compCfg, nil
@@ -2149,11 +2814,19 @@ func buildComponentConfig(ctx context.Context, cfg *config.Config, componentName
 
 // installK3sCluster initializes the K3s cluster using cluster init logic
 func installK3sCluster(ctx context.Context, cfg *config.Config) error {
+	if err := prepareBundleCluster(ctx, cfg); err != nil {
+		return fmt.Errorf("offline bundle preparation failed: %w", err)
+	}
+
 	fmt.Println("  Initializing K3s cluster...")
 
 	// Call the exported cluster initialization function
 	// This handles: tokens, control plane, nodes, VIP, kubeconfig
-	if err := clustercommands.InitializeCluster(ctx, cfg); err != nil {
+	var imageDigests map[string]string
+	if activeLock != nil {
+		imageDigests = activeLock.ImageDigests()
+	}
+	if err := clustercommands.InitializeCluster(ctx, cfg, imageDigests); err != nil {
 		return fmt.Errorf("cluster initialization failed: %w", err)
 	}
 
@@ -2579,6 +3252,36 @@ func generateDNSAPIKey() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// ensureRegistryUsers loads the Zot accounts from OpenBAO, creating the
+// admin and cluster accounts on first install
+func ensureRegistryUsers(ctx context.Context, cfg *config.Config, configDir string) ([]zot.User, error) {
+	openBAOAddr, err := cfg.GetPrimaryOpenBAOURL()
+	if err != nil {
+		return nil, err
+	}
+	keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenBAO keys: %w", err)
+	}
+	store := openbao.NewClient(openBAOAddr, keyMaterial.RootToken)
+
+	users, err := registry.LoadUsers(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	users, created, err := zot.EnsureDefaultUsers(users)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		if err := registry.SaveUsers(ctx, store, users); err != nil {
+			return nil, err
+		}
+		fmt.Println("  ✓ Registry accounts created in OpenBAO (see 'foundry registry user list')")
+	}
+	return users, nil
+}
+
 // getZotDockerHubCredentials retrieves or stores Docker Hub credentials for Zot
 // This enables authenticated pulls to avoid Docker Hub rate limiting
 func getZotDockerHubCredentials(cfg *config.Config, configDir string) (username, password string, err error) {
@@ -2895,6 +3598,14 @@ func configureHost(conn *ssh.Connection, h *config.Host, nonInteractive bool, ca
 		return err
 	}
 
+	// Offline installs stage the runtime tools the container runtime install
+	// would otherwise download
+	if activeBundle != nil {
+		if err := activeBundle.StageTools(conn); err != nil {
+			return fmt.Errorf("failed to stage bundle tools: %w", err)
+		}
+	}
+
 	// Step 2: Update package lists and fix any broken packages
 	fmt.Println("    Updating package lists...")
 	result, err := conn.Exec("sudo apt-get update -qq")
@@ -2913,15 +3624,16 @@ func configureHost(conn *ssh.Connection, h *config.Host, nonInteractive bool, ca
 	}
 
 	// Step 3: Install common tools
-	// Includes open-iscsi for Longhorn storage support
+	// Includes open-iscsi and nfs-common for the Longhorn, NFS and TrueNAS
+	// storage backends
 	fmt.Println("    Installing common tools...")
-	result, err = conn.Exec("sudo apt-get install -y curl git vim htop open-iscsi")
+	result, err = conn.Exec("sudo apt-get install -y curl git vim htop open-iscsi nfs-common")
 	if err != nil || result.ExitCode != 0 {
-		// If full install fails, try just curl and open-iscsi (required for storage)
+		// If full install fails, try just curl and the storage clients (required for storage)
 		fmt.Printf("    ⚠ Some tools failed to install, retrying with essentials only...\n")
-		result, err = conn.Exec("sudo apt-get install -y curl open-iscsi")
+		result, err = conn.Exec("sudo apt-get install -y curl open-iscsi nfs-common")
 		if err != nil || result.ExitCode != 0 {
-			return fmt.Errorf("failed to install curl/open-iscsi (required): %s", result.Stderr)
+			return fmt.Errorf("failed to install curl/open-iscsi/nfs-common (required): %s", result.Stderr)
 		}
 		fmt.Println("    ✓ Essential tools installed (other tools skipped)")
 	} else {
@@ -3076,8 +3788,10 @@ func parseYAMLValues(yamlStr string) map[string]interface{} {
 	return result
 }
 
-// getUserValuesFromConfig extracts user-provided Helm values from stack config
-func getUserValuesFromConfig(cfg *config.Config, componentName string) map[string]interface{} {
+// getUserValuesFromConfig extracts user-provided Helm values from stack
+// config, with their ${secret:...} references resolved. Planning leaves the
+// references in place.
+func getUserValuesFromConfig(cfg *config.Config, componentName string, opts componentConfigOptions) map[string]interface{} {
 	if cfg.Components == nil {
 		return nil
 	}
@@ -3087,11 +3801,45 @@ func getUserValuesFromConfig(cfg *config.Config, componentName string) map[strin
 		return nil
 	}
 
-	if values, ok := compCfg.Config["values"].(map[string]interface{}); ok {
+	values, ok := compCfg.Config["values"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if opts.planning || !hasSecretRefs(values) {
+		return values
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return values
+	}
+	resolved, err := resolveConfigSecrets(cfg, configDir, values, opts.secretRefs)
+	if err != nil {
+		fmt.Printf("  ⚠ %s values not resolved: %v\n", componentName, err)
 		return values
 	}
+	return resolved.(map[string]interface{})
+}
 
-	return nil
+// hasSecretRefs reports whether a nested config value holds a ${secret:...}
+// reference
+func hasSecretRefs(v interface{}) bool {
+	switch t := v.(type) {
+	case string:
+		return isSecretRef(t)
+	case map[string]interface{}:
+		for _, val := range t {
+			if hasSecretRefs(val) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			if hasSecretRefs(item) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // mergeValues deep merges userValues over defaults
@@ -3120,7 +3868,7 @@ func mergeValues(defaults, userValues map[string]interface{}) map[string]interfa
 }
 
 // saveComponentConfig saves the component config (including values) back to stack config
-func saveComponentConfig(cfg *config.Config, componentName string, componentConfig component.ComponentConfig) {
+func saveComponentConfig(cfg *config.Config, componentName string, componentConfig component.ComponentConfig, secretRefs installSecrets) {
 	if cfg.Components == nil {
 		cfg.Components = make(config.ComponentMap)
 	}
@@ -3141,12 +3889,22 @@ func saveComponentConfig(cfg *config.Config, componentName string, componentConf
 		"ssh_conn":       true,
 		"cluster_vip":    true, // Runtime derived from cluster config
 		"gateway_domain": true, // Runtime derived from cluster config
+		"manifest_path":  true, // Runtime path inside an offline bundle
+		// Derived from the hosts, with credentials from OpenBAO; targets
+		// typed by hand stay in the saved config
+		"external_targets": true,
 	}
 
-	// Copy component config settings
+	// Copy component config settings, with references in place of the
+	// secrets that were resolved or generated for the install
 	for k, v := range componentConfig {
-		if !internalFields[k] {
+		if internalFields[k] {
+			continue
+		}
+		if v, keep := secrets.UnresolveRefs(v, secretRefs); keep {
 			compCfg.Config[k] = v
+		} else {
+			delete(compCfg.Config, k)
 		}
 	}
 
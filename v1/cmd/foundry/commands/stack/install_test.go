@@ -7,6 +7,10 @@ import (
 	"testing"
 
 	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/alloy"
+	"github.com/catalystcommunity/foundry/v1/internal/component/blackbox"
+	"github.com/catalystcommunity/foundry/v1/internal/component/storage"
+	"github.com/catalystcommunity/foundry/v1/internal/component/tempo"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
 	"github.com/catalystcommunity/foundry/v1/internal/host"
 	"github.com/catalystcommunity/foundry/v1/internal/setup"
@@ -590,3 +594,196 @@ func TestBuildGatewayControllerConfig_FlowsStackKeys(t *testing.T) {
 	interval, _ := cc.GetString("interval")
 	assert.Equal(t, "30s", interval)
 }
+
+func TestBuildTempoConfig_FlowsStackKeys(t *testing.T) {
+	cfg := &config.Config{Components: config.ComponentMap{
+		"tempo": config.ComponentConfig{Config: map[string]any{
+			"gateway_enabled": true,
+			"retention_days":  30,
+			"s3_bucket":       "ignored",
+		}},
+	}}
+
+	tempoCfg, err := tempo.ParseConfig(buildTempoConfig(cfg, componentConfigOptions{}))
+	require.NoError(t, err)
+	assert.True(t, tempoCfg.GatewayEnabled)
+	assert.Equal(t, 30, tempoCfg.RetentionDays)
+	assert.Equal(t, "monitoring", tempoCfg.Namespace)
+	assert.Equal(t, "tempo", tempoCfg.S3Bucket, "storage always points at the SeaweedFS tempo bucket")
+	assert.Equal(t, seaweedfsEndpoint, tempoCfg.S3Endpoint)
+}
+
+func TestBuildAlloyConfig_PointsAtStackBackends(t *testing.T) {
+	cfg := &config.Config{Components: config.ComponentMap{
+		"alloy": config.ComponentConfig{Config: map[string]any{
+			"otlp_enabled":   true,
+			"events_enabled": false,
+		}},
+	}}
+
+	alloyCfg, err := alloy.ParseConfig(buildAlloyConfig(cfg))
+	require.NoError(t, err)
+	assert.True(t, alloyCfg.OTLPEnabled)
+	assert.False(t, alloyCfg.EventsEnabled)
+	assert.True(t, alloyCfg.PodLogsEnabled)
+	assert.Equal(t, "http://loki-gateway.monitoring.svc.cluster.local:80/loki/api/v1/push", alloyCfg.LokiURL)
+	assert.Equal(t, "http://kube-prometheus-stack-prometheus.monitoring.svc.cluster.local:9090/api/v1/write", alloyCfg.PrometheusRemoteWriteURL)
+	assert.Equal(t, "tempo.monitoring.svc.cluster.local:4317", alloyCfg.TempoEndpoint)
+	assert.Equal(t, "monitoring", alloyCfg.PromtailNamespace)
+}
+
+func TestBuildBlackboxConfig_ProbesConfiguredLinks(t *testing.T) {
+	cfg := &config.Config{
+		Hosts: []*host.Host{{Hostname: "zot", Address: "192.0.2.12", Roles: []string{host.RoleZot}}},
+		Components: config.ComponentMap{
+			"zot": config.ComponentConfig{},
+			"grafana": config.ComponentConfig{Config: map[string]any{
+				"ingress_enabled": true,
+				"ingress_host":    "grafana.example.test",
+			}},
+			"blackbox-exporter": config.ComponentConfig{Config: map[string]any{
+				"interval": "30s",
+				"targets":  []any{"192.0.2.20:22"},
+			}},
+		},
+	}
+
+	blackboxCfg, err := blackbox.ParseConfig(buildBlackboxConfig(cfg))
+	require.NoError(t, err)
+	assert.Equal(t, "30s", blackboxCfg.Interval)
+	assert.Equal(t, []string{"192.0.2.20:22"}, blackboxCfg.Targets)
+	assert.Equal(t, map[string]string{
+		"https://grafana.example.test": "Grafana",
+		"http://192.0.2.12:5000":       "Zot registry",
+	}, blackboxCfg.ServiceLinks)
+}
+
+func TestBuildStorageConfig_TrueNAS(t *testing.T) {
+	t.Setenv("FOUNDRY_SECRET_TRUENAS_API_KEY", "1-from-env")
+	cfg := createTestConfig(t)
+	pool := "tank"
+	cfg.Storage = &config.StorageConfig{
+		Backend: "truenas",
+		TrueNAS: &config.TrueNASConfig{APIURL: "https://nas.local", Pool: &pool, Drivers: []string{"iscsi", "nfs"}},
+	}
+
+	componentConfig := buildStorageConfig(context.Background(), cfg, componentConfigOptions{})
+	assert.Equal(t, "truenas", componentConfig["backend"])
+	assert.Equal(t, "truenas-iscsi", componentConfig["storage_class_name"])
+	assert.NotContains(t, componentConfig, "longhorn")
+
+	truenas, ok := componentConfig["truenas"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "1-from-env", truenas["api_key"], "the API key defaults to the truenas secret")
+	assert.Equal(t, "tank", truenas["pool"])
+}
+
+func TestBuildStorageConfig_LonghornBackup(t *testing.T) {
+	t.Setenv("FOUNDRY_SECRET_LONGHORN_BACKUP_ACCESS_KEY", "AKID")
+	t.Setenv("FOUNDRY_SECRET_LONGHORN_BACKUP_SECRET_KEY", "from-env")
+	cfg := createTestConfig(t)
+	cfg.Components["storage"] = config.ComponentConfig{Config: map[string]any{
+		"longhorn": map[string]any{
+			"recurring_jobs": []any{map[string]any{"name": "nightly", "task": "backup", "cron": "0 2 * * *", "retain": 7}},
+			"backup":         map[string]any{"endpoint": "https://s3.example.test", "bucket": "volumes"},
+		},
+	}}
+
+	storageCfg, err := storage.ParseConfig(buildStorageConfig(context.Background(), cfg, componentConfigOptions{}))
+	require.NoError(t, err)
+	require.NoError(t, storageCfg.Validate())
+	require.Len(t, storageCfg.Longhorn.RecurringJobs, 1)
+	assert.Equal(t, "https://s3.example.test", storageCfg.Longhorn.Backup.Endpoint)
+	assert.Equal(t, "AKID", storageCfg.Longhorn.Backup.AccessKey)
+	assert.Equal(t, "from-env", storageCfg.Longhorn.Backup.SecretKey)
+
+	assert.Equal(t, []string{"loki", "tempo", "velero"}, seaweedfsBuckets(cfg), "an external backup target needs no SeaweedFS bucket")
+	cfg.Components["storage"].Config["longhorn"].(map[string]any)["backup"] = map[string]any{}
+	assert.Equal(t, []string{"loki", "tempo", "velero", "longhorn"}, seaweedfsBuckets(cfg))
+}
+
+func TestBuildStorageConfig_SmartExporter(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Components["storage"] = config.ComponentConfig{Config: map[string]any{
+		"smart_exporter": map[string]any{"enabled": true},
+	}}
+
+	storageCfg, err := storage.ParseConfig(buildStorageConfig(context.Background(), cfg, componentConfigOptions{}))
+	require.NoError(t, err)
+	require.NotNil(t, storageCfg.SmartExporter)
+	assert.True(t, storageCfg.SmartExporter.Enabled)
+	assert.Equal(t, storage.DefaultSmartExporterNamespace, storageCfg.SmartExporter.Namespace)
+}
+
+func TestSaveComponentConfig_KeepsSecretsOut(t *testing.T) {
+	t.Setenv("FOUNDRY_SECRET_SEAWEEDFS_ACCESS_KEY", "access-from-openbao")
+	t.Setenv("FOUNDRY_SECRET_SEAWEEDFS_SECRET_KEY", "secret-from-openbao")
+	cfg := createTestConfig(t)
+	cfg.Components["seaweedfs"] = config.ComponentConfig{Config: map[string]any{
+		"access_key": "${secret:seaweedfs:access_key}",
+		"secret_key": "${secret:seaweedfs:secret_key}",
+	}}
+	cfg.Components["velero"] = config.ComponentConfig{Config: map[string]any{
+		"s3_access_key": "access-from-openbao",
+	}}
+
+	secretRefs := installSecrets{}
+	componentConfig := buildVeleroConfig(cfg, componentConfigOptions{secretRefs: secretRefs})
+	assert.Equal(t, "access-from-openbao", componentConfig["s3_access_key"], "the install gets the credential itself")
+
+	saveComponentConfig(cfg, "velero", componentConfig, secretRefs)
+	saved := cfg.Components["velero"].Config
+	assert.Equal(t, "${secret:seaweedfs:access_key}", saved["s3_access_key"])
+	assert.Equal(t, "${secret:seaweedfs:secret_key}", saved["s3_secret_key"])
+	credentials := saved["values"].(map[string]interface{})["credentials"].(map[string]interface{})
+	assert.Equal(t, true, credentials["useSecret"])
+	assert.NotContains(t, credentials, "secretContents", "values holding a secret are regenerated rather than saved")
+}
+
+func TestGetSeaweedFSCredentials_PlanningSkipsOpenBAO(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Components["seaweedfs"] = config.ComponentConfig{Config: map[string]any{
+		"access_key": "${secret:seaweedfs:access_key}",
+		"secret_key": "${secret:seaweedfs:secret_key}",
+	}}
+
+	accessKey, secretKey := getSeaweedFSCredentials(cfg, componentConfigOptions{planning: true})
+	assert.Empty(t, accessKey)
+	assert.Empty(t, secretKey)
+}
+
+func TestBuildPrometheusConfig_UnresolvedAlertingSecret(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Components["prometheus"] = config.ComponentConfig{Config: map[string]any{
+		"alerting": map[string]any{
+			"slack": map[string]any{"webhook_url": "${secret:alertmanager:slack_webhook}"},
+		},
+	}}
+
+	_, err := buildPrometheusConfig(context.Background(), cfg, t.TempDir(), componentConfigOptions{})
+	assert.ErrorContains(t, err, "failed to resolve alerting secrets")
+
+	// Planning a bundle reads no credentials
+	_, err = buildPrometheusConfig(context.Background(), cfg, t.TempDir(), componentConfigOptions{planning: true})
+	assert.NoError(t, err)
+}
+
+func TestHostImageVersion(t *testing.T) {
+	pinned := "2.1.0"
+	cfg := &config.Config{Components: config.ComponentMap{
+		"openbao":       config.ComponentConfig{Version: &pinned},
+		"zot":           config.ComponentConfig{Version: &pinned, Config: map[string]any{"version": "v2.1.5"}},
+		"dns":           config.ComponentConfig{Config: map[string]any{"image_tag": "4.9"}},
+		"node-exporter": config.ComponentConfig{Version: &pinned},
+		"host-logs":     config.ComponentConfig{Config: map[string]any{"version": "3.4.1"}},
+	}}
+
+	assert.Equal(t, "2.1.0", hostImageVersion(cfg, "openbao"))
+	assert.Equal(t, "v2.1.5", hostImageVersion(cfg, "zot"), "the config's version wins over the version field")
+	assert.Equal(t, "4.9", hostImageVersion(cfg, "dns"))
+	assert.Equal(t, "2.1.0", hostImageVersion(cfg, "node-exporter"))
+	assert.Equal(t, "3.4.1", hostImageVersion(cfg, "host-logs"))
+
+	delete(cfg.Components, "openbao")
+	assert.Empty(t, hostImageVersion(cfg, "openbao"))
+}
@@ -0,0 +1,134 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/lockfile"
+	"github.com/urfave/cli/v3"
+)
+
+// LockCommand pins every chart and image the stack installs
+var LockCommand = &cli.Command{
+	Name:      "lock",
+	Usage:     "Pin every chart version and image digest the stack installs",
+	ArgsUsage: "[component]",
+	Description: `Resolves every Helm chart the stack installs to an exact version and archive
+digest, and every container image (from rendered charts, host services and
+kube-vip) to the digest of its linux/amd64 manifest. The result is written to
+<stack>.lock.yaml next to the config.
+
+While a lockfile exists, stack and component installs and upgrades install
+exactly the locked chart versions, reject chart archives whose digest
+differs, and run every image by its locked digest. Images missing from the
+lockfile are an error.
+
+Running lock again adds entries for charts and images the stack started
+using and drops unused ones, but never moves a locked entry. Use --update to
+move entries to what the stack config resolves to now, for every component
+or the one named.
+
+Examples:
+  foundry stack lock
+  foundry stack lock --update --dry-run
+  foundry stack lock --update grafana`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "update",
+			Usage: "Move locked entries to the versions and digests resolved now",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Show what would change without writing the lockfile",
+		},
+	},
+	Action: runStackLock,
+}
+
+func runStackLock(ctx context.Context, cmd *cli.Command) error {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to find config: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	updateAll := cmd.Bool("update")
+	component := cmd.Args().First()
+	if component != "" && !updateAll {
+		return fmt.Errorf("a component can only be given with --update")
+	}
+	update := func(name string) bool {
+		return updateAll && (component == "" || name == component)
+	}
+
+	locked, err := lockfile.LoadForConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	plan, err := ArtifactPlan(ctx, cfg, configDir)
+	if err != nil {
+		return err
+	}
+	// Charts that keep their locked version are rendered at that version,
+	// so the images recorded for them are the ones they run
+	if locked != nil {
+		versions := map[string]string{}
+		for _, c := range locked.Charts {
+			versions[c.Component+" "+c.Chart] = c.Version
+		}
+		for i, c := range plan.Charts {
+			if version, ok := versions[c.Component+" "+c.Chart]; ok && !update(c.Component) {
+				plan.Charts[i].Version = version
+			}
+		}
+	}
+
+	fmt.Println("Resolving charts and images...")
+	resolved, err := lockfile.Resolve(ctx, plan, os.Stdout)
+	if err != nil {
+		return err
+	}
+	merged := lockfile.Merge(locked, resolved, update)
+
+	changes := lockfile.Diff(locked, merged)
+	if len(changes) == 0 {
+		fmt.Println("\n✓ Lockfile is up to date")
+		return nil
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tNAME\tFROM\tTO")
+	for _, c := range changes {
+		from, to := c.From, c.To
+		if from == "" {
+			from = "(new)"
+		}
+		if to == "" {
+			to = "(removed)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Component, c.Name, from, to)
+	}
+	w.Flush()
+
+	if cmd.Bool("dry-run") {
+		fmt.Println("\nDry run: lockfile not written")
+		return nil
+	}
+	path := lockfile.Path(configPath)
+	if err := merged.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("\n✓ Lockfile written to %s (%d charts, %d images)\n", path, len(merged.Charts), len(merged.Images))
+	return nil
+}
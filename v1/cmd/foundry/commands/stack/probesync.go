@@ -0,0 +1,79 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/blackbox"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+// probeSyncInterval is how often the manager brings the blackbox Probes in
+// line with the routes exposed in the cluster
+const probeSyncInterval = 10 * time.Minute
+
+// ScheduleProbeSync re-syncs the blackbox-exporter Probes with the cluster's
+// routes and service links until ctx is done, so routes added or removed
+// after install are probed or dropped. The manager runs it. The stack config
+// is re-read each time.
+func ScheduleProbeSync(ctx context.Context, configPath string, out io.Writer) {
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := syncBlackboxProbes(ctx, configPath); err != nil {
+			fmt.Fprintf(out, "probe sync: %v\n", err)
+		}
+		timer.Reset(probeSyncInterval)
+	}
+}
+
+// syncBlackboxProbes syncs the Probes if blackbox-exporter is installed
+func syncBlackboxProbes(ctx context.Context, configPath string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	helmClient, err := helm.NewClient(kubeconfigBytes, "default")
+	if err != nil {
+		return fmt.Errorf("failed to create helm client: %w", err)
+	}
+	defer helmClient.Close()
+	status, err := blackbox.NewComponent(helmClient, nil).Status(ctx)
+	if err != nil || !status.Installed {
+		return err
+	}
+
+	k8sClient, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	blackboxCfg, err := blackbox.ParseConfig(buildBlackboxConfig(cfg))
+	if err != nil {
+		return err
+	}
+	_, err = blackbox.SyncProbes(ctx, k8sClient, blackboxCfg)
+	return err
+}
@@ -3,13 +3,24 @@ package stack
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/component"
 	internalComponent "github.com/catalystcommunity/foundry/v1/internal/component"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
 	"github.com/urfave/cli/v3"
 )
 
+// certWarningWindow flags probed certificates expiring soon, matching the
+// default of the probe_certificate_expiry alert rule
+const certWarningWindow = 14 * 24 * time.Hour
+
 // StatusCommand handles the 'foundry stack status' command
 var StatusCommand = &cli.Command{
 	Name:   "status",
@@ -90,9 +101,93 @@ func runStackStatus(ctx context.Context, cmd *cli.Command) error {
 	fmt.Println()
 	displayOverallHealth(health)
 
+	// Probe results, when blackbox-exporter is installed
+	probes, err := loadProbeResults(ctx)
+	if err != nil {
+		fmt.Printf("\nProbe results unavailable: %v\n", err)
+	} else if len(probes) > 0 {
+		fmt.Println()
+		displayProbeResults(os.Stdout, probes, time.Now())
+	}
+
 	return nil
 }
 
+// loadProbeResults reads the blackbox probe results from the cluster's
+// Prometheus. A stack without a cluster has none.
+func loadProbeResults(ctx context.Context) ([]discovery.ProbeResult, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	kubeconfigBytes, err := os.ReadFile(filepath.Join(configDir, "kubeconfig"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	client, err := k8s.NewClientFromKubeconfig(kubeconfigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return discovery.ProbeResults(ctx, client.Clientset())
+}
+
+// displayProbeResults shows the latest probe of every target, failing
+// probes first
+func displayProbeResults(out io.Writer, results []discovery.ProbeResult, now time.Time) {
+	sorted := append([]discovery.ProbeResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return !sorted[i].Up && sorted[j].Up })
+
+	fmt.Fprintln(out, "Probes:")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  %-30s %-8s %-14s %s\n", "NAME", "RESULT", "CERT EXPIRES", "TARGET")
+	fmt.Fprintf(out, "  %-30s %-8s %-14s %s\n", "────", "──────", "────────────", "──────")
+
+	failing := 0
+	for _, probe := range sorted {
+		symbol := "✓"
+		result := "up"
+		if !probe.Up {
+			symbol = "✗"
+			result = "down"
+			failing++
+		}
+
+		expires := "-"
+		if probe.CertExpiresAt != nil {
+			left := probe.CertExpiresAt.Sub(now)
+			switch {
+			case left <= 0:
+				expires = "expired"
+			case left < 48*time.Hour:
+				expires = fmt.Sprintf("in %dh", int(left.Hours()))
+			default:
+				expires = fmt.Sprintf("in %dd", int(left.Hours()/24))
+			}
+			if probe.Up && left < certWarningWindow {
+				symbol = "⚠"
+			}
+		}
+
+		name := probe.Name
+		if len(name) > 28 {
+			name = name[:25] + "..."
+		}
+		fmt.Fprintf(out, "  %s %-28s %-8s %-14s %s\n", symbol, name, result, expires, probe.Target)
+	}
+
+	fmt.Fprintln(out)
+	if failing > 0 {
+		fmt.Fprintf(out, "  Status: ✗ %d of %d probe(s) failing\n", failing, len(sorted))
+	} else {
+		fmt.Fprintf(out, "  Status: ✓ All %d probes succeeding\n", len(sorted))
+	}
+}
+
 // displayStatusTable shows a formatted table of component statuses
 func displayStatusTable(order []string, statuses map[string]*internalComponent.ComponentStatus) {
 	fmt.Println("Stack Component Status:")
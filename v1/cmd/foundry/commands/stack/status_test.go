@@ -1,12 +1,17 @@
 package stack
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockComponentForStatus is a mock component for status testing
@@ -440,3 +445,27 @@ func TestComponentStatusQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayProbeResults(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	soon := now.Add(3 * 24 * time.Hour)
+	later := now.Add(90 * 24 * time.Hour)
+	results := []discovery.ProbeResult{
+		{Name: "Grafana", Target: "https://grafana.example.test", Up: true, CertExpiresAt: &later},
+		{Name: "OpenBAO", Target: "https://192.0.2.11:8200", Up: true, CertExpiresAt: &soon},
+		{Name: "monitoring/tempo-otlp-grpc", Target: "192.0.2.10:4317", Up: false},
+	}
+
+	var out bytes.Buffer
+	displayProbeResults(&out, results, now)
+	lines := strings.Split(out.String(), "\n")
+
+	require.GreaterOrEqual(t, len(lines), 7)
+	assert.Contains(t, lines[4], "✗ monitoring/tempo-otlp-grpc", "failing probes come first")
+	assert.Contains(t, lines[4], "down")
+	assert.Contains(t, lines[5], "✓ Grafana")
+	assert.Contains(t, lines[5], "in 90d")
+	assert.Contains(t, lines[6], "⚠ OpenBAO", "certificates close to expiry are flagged")
+	assert.Contains(t, lines[6], "in 3d")
+	assert.Contains(t, out.String(), "1 of 3 probe(s) failing")
+}
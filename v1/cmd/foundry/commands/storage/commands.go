@@ -14,15 +14,24 @@ in your Kubernetes cluster.
 Storage Management:
   foundry storage list        - Show configured storage backends
   foundry storage add-disk    - Add raw disks to nodes for Longhorn storage
+  foundry storage disks       - Check disk health (SMART) and filesystem usage
 
 PVC Management:
   foundry storage provision   - Create a new PVC
   foundry storage pvc list    - List PVCs
-  foundry storage pvc delete  - Delete a PVC`,
+  foundry storage pvc delete  - Delete a PVC
+  foundry storage pvc migrate - Move a PVC to another storage class or node
+
+Snapshots (Longhorn):
+  foundry storage snapshot list    - List a PVC's snapshots
+  foundry storage snapshot create  - Take a snapshot of a PVC
+  foundry storage snapshot restore - Revert a PVC to a snapshot`,
 	Commands: []*cli.Command{
 		ListCommand,
 		ProvisionCommand,
 		PVCCommand,
 		AddDiskCommand,
+		DisksCommand,
+		SnapshotCommand,
 	},
 }
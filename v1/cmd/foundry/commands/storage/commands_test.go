@@ -9,10 +9,10 @@ import (
 func TestStorageCommand(t *testing.T) {
 	assert.NotNil(t, Command, "Command should not be nil")
 	assert.Equal(t, "storage", Command.Name)
-	assert.Len(t, Command.Commands, 4, "Should have 4 subcommands")
+	assert.Len(t, Command.Commands, 6, "Should have 6 subcommands")
 
 	// Verify subcommands exist
-	var foundList, foundProvision, foundPVC, foundAddDisk bool
+	var foundList, foundProvision, foundPVC, foundAddDisk, foundDisks, foundSnapshot bool
 	for _, cmd := range Command.Commands {
 		switch cmd.Name {
 		case "list":
@@ -23,6 +23,10 @@ func TestStorageCommand(t *testing.T) {
 			foundPVC = true
 		case "add-disk":
 			foundAddDisk = true
+		case "disks":
+			foundDisks = true
+		case "snapshot":
+			foundSnapshot = true
 		}
 	}
 
@@ -30,6 +34,8 @@ func TestStorageCommand(t *testing.T) {
 	assert.True(t, foundProvision, "Should have provision command")
 	assert.True(t, foundPVC, "Should have pvc command")
 	assert.True(t, foundAddDisk, "Should have add-disk command")
+	assert.True(t, foundDisks, "Should have disks command")
+	assert.True(t, foundSnapshot, "Should have snapshot command")
 }
 
 func TestListCommand(t *testing.T) {
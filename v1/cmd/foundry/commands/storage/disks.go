@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/diskhealth"
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+)
+
+// DisksCommand reports the SMART health and usage of the cluster hosts' disks
+var DisksCommand = &cli.Command{
+	Name:  "disks",
+	Usage: "Check disk health (SMART) and filesystem usage on cluster hosts",
+	Description: `Connects to every cluster host over SSH and reads each disk's SMART data
+with smartctl (from the smartmontools package) and filesystem usage with df.
+
+Disks are graded:
+  failing  - SMART reports the disk failing, a pre-fail attribute is at or
+             below its threshold, or an NVMe drive raised a critical warning
+  warning  - early signs of failure: reallocated, pending or uncorrectable
+             sectors, NVMe media errors, or 90% of rated endurance used
+  unknown  - smartctl could not read the disk
+
+Filesystems above --usage-warning or --usage-critical percent are flagged.
+
+With --cordon-failing, Longhorn disks on a failing disk stop accepting new
+replicas (allowScheduling: false). Evict the replicas already there from the
+Longhorn UI once the volumes have healthy replicas elsewhere.
+
+The command exits with an error when any disk or filesystem needs attention.
+
+Examples:
+  foundry storage disks
+  foundry storage disks --host node1 --usage-warning 70
+  foundry storage disks --cordon-failing`,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "host",
+			Aliases: []string{"H"},
+			Usage:   "Only check these hosts (default: all cluster hosts)",
+		},
+		&cli.FloatFlag{
+			Name:  "usage-warning",
+			Usage: "Filesystem usage percent to warn at",
+			Value: diskhealth.DefaultUsageWarning,
+		},
+		&cli.FloatFlag{
+			Name:  "usage-critical",
+			Usage: "Filesystem usage percent to flag as critical",
+			Value: diskhealth.DefaultUsageCritical,
+		},
+		&cli.BoolFlag{
+			Name:  "cordon-failing",
+			Usage: "Disable Longhorn scheduling on disks that are failing",
+		},
+	},
+	Action: runDisks,
+}
+
+func runDisks(ctx context.Context, cmd *cli.Command) error {
+	configPath, err := config.FindConfig(cmd.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to find config: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	loader := config.NewHostConfigLoader(configPath)
+	host.SetDefaultRegistry(host.NewConfigRegistry(configPath, loader))
+
+	thresholds := diskhealth.Thresholds{
+		UsageWarning:  cmd.Float("usage-warning"),
+		UsageCritical: cmd.Float("usage-critical"),
+	}
+	if thresholds.UsageWarning > thresholds.UsageCritical {
+		return fmt.Errorf("--usage-warning (%g) must not be above --usage-critical (%g)", thresholds.UsageWarning, thresholds.UsageCritical)
+	}
+
+	hosts, err := diskHosts(cfg, cmd.StringSlice("host"))
+	if err != nil {
+		return err
+	}
+
+	var reports []*diskhealth.HostReport
+	for _, h := range hosts {
+		fmt.Printf("Checking %s...\n", h.Hostname)
+		reports = append(reports, collectDiskHealth(h.Hostname, thresholds))
+	}
+	fmt.Println()
+
+	attention := 0
+	for _, report := range reports {
+		printDiskReport(os.Stdout, report)
+		if report.Health() >= diskhealth.HealthWarning {
+			attention++
+		}
+	}
+
+	if cmd.Bool("cordon-failing") {
+		if err := cordonFailingDisks(ctx, reports); err != nil {
+			return err
+		}
+	}
+
+	if attention > 0 {
+		return fmt.Errorf("%d host(s) have disks or filesystems that need attention", attention)
+	}
+	fmt.Println("All disks are healthy")
+	return nil
+}
+
+// diskHosts returns the cluster hosts to check, limited to the named ones
+func diskHosts(cfg *config.Config, names []string) ([]*host.Host, error) {
+	clusterHosts := cfg.GetClusterHosts()
+	if len(clusterHosts) == 0 {
+		return nil, fmt.Errorf("no cluster nodes found in configuration")
+	}
+	if len(names) == 0 {
+		return clusterHosts, nil
+	}
+	byName := make(map[string]*host.Host, len(clusterHosts))
+	for _, h := range clusterHosts {
+		byName[h.Hostname] = h
+	}
+	hosts := make([]*host.Host, 0, len(names))
+	for _, name := range names {
+		h, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("host %s is not a cluster node", name)
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// collectDiskHealth gathers one host's report; a failed connection is
+// recorded in the report
+func collectDiskHealth(hostname string, thresholds diskhealth.Thresholds) *diskhealth.HostReport {
+	conn, err := connectToHostForDisk(hostname)
+	if err != nil {
+		return &diskhealth.HostReport{Host: hostname, Errors: []string{err.Error()}}
+	}
+	defer conn.Close()
+	return diskhealth.Collect(conn, hostname, thresholds)
+}
+
+// printDiskReport shows one host's disks and filesystems
+func printDiskReport(w io.Writer, report *diskhealth.HostReport) {
+	fmt.Fprintf(w, "%s: %s\n", report.Host, report.Health())
+	for _, msg := range report.Errors {
+		fmt.Fprintf(w, "  ⚠ %s\n", msg)
+	}
+
+	if len(report.Disks) > 0 {
+		fmt.Fprintf(w, "  %-14s %-32s %-10s %-6s %-9s %s\n", "DISK", "MODEL", "SIZE", "TEMP", "POWER-ON", "HEALTH")
+		for _, d := range report.Disks {
+			temp := "-"
+			if d.Temperature > 0 {
+				temp = fmt.Sprintf("%d°C", d.Temperature)
+			}
+			fmt.Fprintf(w, "  %-14s %-32s %-10s %-6s %-9s %s\n",
+				d.Device, truncate(orDefault(d.Model, "-"), 32), format.Bytes(d.Capacity), temp, fmt.Sprintf("%dh", d.PowerOnHours), d.Health)
+			for _, problem := range d.Problems {
+				fmt.Fprintf(w, "      %s\n", problem)
+			}
+		}
+	}
+
+	if len(report.Filesystems) > 0 {
+		fmt.Fprintf(w, "  %-14s %-32s %-10s %-6s %-9s %s\n", "FILESYSTEM", "MOUNTPOINT", "SIZE", "USED", "DISK", "HEALTH")
+		for _, fs := range report.Filesystems {
+			fmt.Fprintf(w, "  %-14s %-32s %-10s %-6s %-9s %s\n",
+				truncate(fs.Source, 14), truncate(fs.Mountpoint, 32), format.Bytes(fs.Size), fmt.Sprintf("%.0f%%", fs.UsedPercent()), orDefault(fs.Disk, "-"), fs.Health)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// truncate shortens s to n characters
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
+
+// cordonFailingDisks disables Longhorn scheduling on the Longhorn disks that
+// sit on a failing disk. Longhorn node names are the host names.
+func cordonFailingDisks(ctx context.Context, reports []*diskhealth.HostReport) error {
+	client, err := getLonghornClient()
+	if err != nil {
+		return err
+	}
+	cordoned := 0
+	for _, report := range reports {
+		if report.Health() != diskhealth.HealthFailing {
+			continue
+		}
+		disks, err := client.NodeDisks(ctx, report.Host)
+		if err != nil {
+			fmt.Printf("⚠ %v\n", err)
+			continue
+		}
+		for _, disk := range disks {
+			device := report.DiskForPath(disk.Path)
+			if device == nil || device.Health != diskhealth.HealthFailing || !disk.AllowScheduling {
+				continue
+			}
+			if err := client.DisableDiskScheduling(ctx, report.Host, disk.Name); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Disabled Longhorn scheduling on %s/%s (%s on failing %s)\n", report.Host, disk.Name, disk.Path, device.Device)
+			cordoned++
+		}
+	}
+	if cordoned > 0 {
+		fmt.Println("\nEvict the replicas on these disks from the Longhorn UI once their volumes are healthy elsewhere.")
+	} else {
+		fmt.Println("No Longhorn disks needed cordoning")
+	}
+	fmt.Println()
+	return nil
+}
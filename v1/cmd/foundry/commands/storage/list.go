@@ -3,11 +3,22 @@ package storage
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"time"
 
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
+	componentStorage "github.com/catalystcommunity/foundry/v1/internal/component/storage"
 	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
 	"github.com/urfave/cli/v3"
 )
 
+// lowPoolFreePercent is the free space below which storage list warns that
+// the TrueNAS pool is running out
+const lowPoolFreePercent = 10
+
 // ListCommand lists configured storage backends
 var ListCommand = &cli.Command{
 	Name:  "list",
@@ -15,8 +26,11 @@ var ListCommand = &cli.Command{
 	Description: `Show all configured storage backends.
 
 This command displays:
-  - Storage backend type (local-path, nfs, longhorn)
-  - Configuration status`,
+  - Storage backend type (local-path, nfs, longhorn, truenas)
+  - Configuration status
+
+For the truenas backend it also connects to the TrueNAS API with the key
+from OpenBAO and checks that the pool is healthy and has free capacity.`,
 	Action: runList,
 }
 
@@ -38,7 +52,7 @@ func runList(ctx context.Context, cmd *cli.Command) error {
 		fmt.Println("\nDefault backend: local-path (K3s bundled)")
 		fmt.Println("\nTo configure a different storage backend, set storage.backend in your config:")
 		fmt.Println("  storage:")
-		fmt.Println("    backend: longhorn  # or: local-path, nfs")
+		fmt.Println("    backend: longhorn  # or: local-path, nfs, truenas")
 		return nil
 	}
 
@@ -56,6 +70,9 @@ func runList(ctx context.Context, cmd *cli.Command) error {
 	case "nfs":
 		fmt.Println("  Type: NFS subdir provisioner")
 		fmt.Println("  Storage Class: nfs-client")
+	case "truenas":
+		fmt.Println("  Type: TrueNAS via democratic-csi")
+		return listTrueNAS(ctx, cfg)
 	default:
 		fmt.Printf("  Type: Custom (%s)\n", cfg.Storage.Backend)
 	}
@@ -63,3 +80,74 @@ func runList(ctx context.Context, cmd *cli.Command) error {
 	fmt.Println()
 	return nil
 }
+
+// listTrueNAS shows the TrueNAS StorageClasses and checks the server behind
+// them: the API must accept the key and the pool must be healthy
+func listTrueNAS(ctx context.Context, cfg *config.Config) error {
+	truenas, err := resolveTrueNAS(cfg)
+	if err != nil {
+		fmt.Println()
+		return err
+	}
+
+	for _, driver := range truenas.Drivers {
+		fmt.Printf("  Storage Class: %s (with VolumeSnapshotClass)\n", componentStorage.TrueNASStorageClass(driver))
+	}
+	fmt.Printf("  API: %s\n", truenas.APIURL)
+	fmt.Printf("  Dataset: %s\n", truenas.Dataset())
+	fmt.Println()
+
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	check, err := componentStorage.CheckTrueNAS(checkCtx, truenas)
+	if check != nil {
+		fmt.Printf("  ✓ Connected to %s\n", check.Version)
+	}
+	if err != nil {
+		fmt.Println()
+		return fmt.Errorf("TrueNAS check failed: %w", err)
+	}
+
+	pool := check.Pool
+	fmt.Printf("  ✓ Pool %s: %s, %s free of %s (%.0f%%)\n",
+		pool.Name, pool.Status, format.Bytes(pool.Free), format.Bytes(pool.Size), pool.FreePercent())
+	if pool.FreePercent() < lowPoolFreePercent {
+		fmt.Printf("  ⚠ Pool %s is below %d%% free; ZFS slows down and new volumes may fail to provision\n",
+			pool.Name, lowPoolFreePercent)
+	}
+	fmt.Println()
+	return nil
+}
+
+// resolveTrueNAS reads storage.truenas the way the storage component does,
+// with the API key resolved from OpenBAO
+func resolveTrueNAS(cfg *config.Config) (*componentStorage.TrueNASConfig, error) {
+	if cfg.Storage.TrueNAS == nil {
+		return nil, fmt.Errorf("storage.truenas (api_url, pool) is not configured")
+	}
+
+	resolvers := []secrets.Resolver{secrets.NewEnvResolver()}
+	if openBAOAddr, err := cfg.GetPrimaryOpenBAOURL(); err == nil {
+		if configDir, err := config.GetConfigDir(); err == nil {
+			if keyMaterial, err := openbao.LoadKeyMaterial(filepath.Join(configDir, "openbao-keys"), cfg.Cluster.Name); err == nil {
+				if resolver, err := secrets.NewOpenBAOResolverWithMount(openBAOAddr, keyMaterial.RootToken, "foundry-core"); err == nil {
+					resolvers = append(resolvers, resolver)
+				}
+			}
+		}
+	}
+	section, err := secrets.ResolveRefs(componentStorage.TrueNASComponentConfig(cfg.Storage.TrueNAS),
+		secrets.NewChainResolver(resolvers...), &secrets.ResolutionContext{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the TrueNAS API key: %w\n\nHint: Store api_key at %s in OpenBAO", err, componentStorage.TrueNASSecretPath)
+	}
+
+	storageCfg, err := componentStorage.ParseConfig(component.ComponentConfig{
+		"backend": string(componentStorage.BackendTrueNAS),
+		"truenas": section,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storageCfg.TrueNAS, nil
+}
@@ -141,6 +141,7 @@ func TestStorageBackendTypes(t *testing.T) {
 		{name: "longhorn", backend: "longhorn"},
 		{name: "local-path", backend: "local-path"},
 		{name: "nfs", backend: "nfs"},
+		{name: "truenas", backend: "truenas"},
 	}
 
 	for _, tt := range tests {
@@ -170,3 +171,25 @@ func TestStorageBackendTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTrueNAS(t *testing.T) {
+	t.Setenv("FOUNDRY_SECRET_TRUENAS_API_KEY", "1-from-env")
+	pool := "tank"
+	cfg := &config.Config{
+		Cluster: config.ClusterConfig{Name: "test"},
+		Storage: &config.StorageConfig{
+			Backend: "truenas",
+			TrueNAS: &config.TrueNASConfig{APIURL: "https://nas.local", Pool: &pool},
+		},
+	}
+
+	truenas, err := resolveTrueNAS(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "1-from-env", truenas.APIKey)
+	assert.Equal(t, []string{"nfs", "iscsi"}, truenas.Drivers)
+	assert.Equal(t, "nas.local", truenas.NFSServer)
+
+	cfg.Storage.TrueNAS = nil
+	_, err = resolveTrueNAS(cfg)
+	assert.ErrorContains(t, err, "storage.truenas")
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/catalystcommunity/foundry/v1/internal/pvcmigrate"
+)
+
+// PVCMigrateCommand moves a PVC's data to another storage class or node
+var PVCMigrateCommand = &cli.Command{
+	Name:      "migrate",
+	Usage:     "Move a PVC's data to another storage class, size or node",
+	ArgsUsage: "<namespace>/<name>",
+	Description: `Moves the data of a Persistent Volume Claim onto a new volume and swaps it
+in under the same claim name, so the workloads using it need no changes.
+
+The migration:
+  1. Sets the current volume's reclaim policy to Retain.
+  2. Scales the Deployments and StatefulSets that mount the claim to zero.
+  3. Creates <name>-migrate in the target class and copies the data into it
+     with an rsync Job.
+  4. Deletes both claims and recreates the claim with the same name, bound
+     to the new volume.
+  5. Scales the workloads back up.
+
+The old volume is kept (Released) afterward; delete it once the workloads
+are verified. If the copy fails, the original claim is untouched and the
+workloads are scaled back up.
+
+The migration refuses claims mounted by pods it can't scale down (bare pods,
+DaemonSets, Jobs) and workloads managed by a HorizontalPodAutoscaler. Use
+--dry-run to see the plan without changing anything.
+
+With --node, the new volume is placed on that node, for node-local classes
+such as local-path. When the old volume is pinned to another node, its data
+is pulled over the network from an rsync daemon on that node.
+
+Examples:
+  foundry storage pvc migrate app/data --to-class longhorn --dry-run
+  foundry storage pvc migrate app/data --to-class nfs --size 100Gi
+  foundry storage pvc migrate app/data --node worker-2`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "to-class",
+			Usage: "StorageClass for the new volume (default: the claim's current class)",
+		},
+		&cli.StringFlag{
+			Name:    "size",
+			Aliases: []string{"s"},
+			Usage:   "Size of the new volume (default: the current size)",
+		},
+		&cli.StringFlag{
+			Name:  "node",
+			Usage: "Node to place the new volume on, for node-local storage classes",
+		},
+		&cli.StringFlag{
+			Name:  "image",
+			Usage: "Image with rsync used for the copy",
+			Value: pvcmigrate.DefaultImage,
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Show the migration plan without changing anything",
+		},
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"f"},
+			Usage:   "Skip confirmation prompt",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Max time for the whole migration",
+			Value: 2 * time.Hour,
+		},
+	},
+	Action: runPVCMigrate,
+}
+
+func runPVCMigrate(ctx context.Context, cmd *cli.Command) error {
+	ref := cmd.Args().Get(0)
+	if ref == "" {
+		return fmt.Errorf("PVC is required\n\nUsage: foundry storage pvc migrate <namespace>/<name> --to-class <class>")
+	}
+	namespace, name, err := pvcmigrate.ParseClaimRef(ref)
+	if err != nil {
+		return err
+	}
+	if cmd.String("to-class") == "" && cmd.String("size") == "" && cmd.String("node") == "" {
+		return fmt.Errorf("nothing to migrate\n\nPass --to-class, --size or --node")
+	}
+
+	client, err := getK8sClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := pvcmigrate.NewPlan(ctx, client, pvcmigrate.Options{
+		Namespace: namespace,
+		Claim:     name,
+		ToClass:   cmd.String("to-class"),
+		Size:      cmd.String("size"),
+		Node:      cmd.String("node"),
+		Image:     cmd.String("image"),
+	})
+	if err != nil {
+		return err
+	}
+
+	printMigratePlan(os.Stdout, plan)
+	if cmd.Bool("dry-run") {
+		fmt.Println("\nDry run: nothing was changed")
+		return nil
+	}
+
+	if !cmd.Bool("force") {
+		fmt.Printf("\nThe workloads above will be down until the copy finishes.\n")
+		fmt.Print("Type 'yes' to migrate: ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "yes" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+	fmt.Println()
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.Duration("timeout"))
+	defer cancel()
+	migrator := &pvcmigrate.Migrator{Client: client, Out: os.Stdout}
+	return migrator.Run(ctx, plan)
+}
+
+// printMigratePlan shows what a migration will do
+func printMigratePlan(w io.Writer, plan *pvcmigrate.Plan) {
+	fmt.Fprintf(w, "Migration plan for PVC %s/%s\n\n", plan.Namespace, plan.Claim)
+	fmt.Fprintf(w, "  Volume:        %s\n", plan.Volume)
+	fmt.Fprintf(w, "  Storage class: %s -> %s\n", orDefault(plan.FromClass, "<none>"), plan.ToClass)
+	fmt.Fprintf(w, "  Size:          %s -> %s\n", plan.Size.String(), plan.NewSize.String())
+	if plan.SourceNode != "" || plan.Node != "" {
+		fmt.Fprintf(w, "  Node:          %s -> %s\n", orDefault(plan.SourceNode, "<any>"), orDefault(plan.Node, "<scheduler>"))
+	}
+	copyMode := "one Job mounting both volumes"
+	if plan.Remote() {
+		copyMode = fmt.Sprintf("rsync daemon on %s, pulled by a Job on %s", plan.SourceNode, plan.Node)
+	}
+	fmt.Fprintf(w, "  Staging PVC:   %s\n", plan.StagingClaim)
+	fmt.Fprintf(w, "  Copy:          %s (%s)\n", copyMode, plan.Image)
+
+	if len(plan.Workloads) == 0 {
+		fmt.Fprintln(w, "\nNo running workloads mount the claim.")
+	} else {
+		fmt.Fprintln(w, "\nScaled to zero during the copy:")
+		for _, workload := range plan.Workloads {
+			fmt.Fprintf(w, "  %-40s %d replicas\n", workload.String(), workload.Replicas)
+		}
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Fprintf(w, "\n⚠ %s\n", warning)
+	}
+}
+
+// orDefault returns s, or def when s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
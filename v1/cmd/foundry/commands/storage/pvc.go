@@ -19,10 +19,12 @@ var PVCCommand = &cli.Command{
 
 Examples:
   foundry storage pvc list              # List all PVCs
-  foundry storage pvc list -n app       # List PVCs in namespace`,
+  foundry storage pvc list -n app       # List PVCs in namespace
+  foundry storage pvc migrate app/data --to-class longhorn  # Move to another class`,
 	Commands: []*cli.Command{
 		PVCListCommand,
 		PVCDeleteCommand,
+		PVCMigrateCommand,
 	},
 }
 
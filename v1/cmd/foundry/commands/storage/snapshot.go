@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/catalystcommunity/foundry/v1/internal/format"
+	"github.com/catalystcommunity/foundry/v1/internal/longhorn"
+	"github.com/catalystcommunity/foundry/v1/internal/pvcmigrate"
+)
+
+// SnapshotCommand is the parent command for Longhorn volume snapshots
+var SnapshotCommand = &cli.Command{
+	Name:  "snapshot",
+	Usage: "Manage Longhorn snapshots of PVCs",
+	Description: `Point-in-time snapshots of Longhorn volumes, for quick rollback without a
+full Velero restore. Snapshots live on the volume's replicas; they are not
+backups and are lost with the volume.
+
+Scheduled snapshots and backups are declared as recurring_jobs under
+components.storage.config.longhorn in the stack config.
+
+Examples:
+  foundry storage snapshot list app/data
+  foundry storage snapshot create app/data --name before-upgrade
+  foundry storage snapshot restore app/data before-upgrade`,
+	Commands: []*cli.Command{
+		SnapshotListCommand,
+		SnapshotCreateCommand,
+		SnapshotRestoreCommand,
+	},
+}
+
+// SnapshotListCommand lists a PVC's snapshots
+var SnapshotListCommand = &cli.Command{
+	Name:      "list",
+	Usage:     "List the snapshots of a PVC",
+	ArgsUsage: "<namespace>/<name>",
+	Action:    runSnapshotList,
+}
+
+// SnapshotCreateCommand takes a snapshot of a PVC
+var SnapshotCreateCommand = &cli.Command{
+	Name:      "create",
+	Usage:     "Take a snapshot of a PVC",
+	ArgsUsage: "<namespace>/<name>",
+	Description: `Takes a snapshot of the PVC's Longhorn volume and waits until it is ready.
+The volume must be attached, that is, mounted by a running pod.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Snapshot name (default: the volume name and the current time)",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Max time to wait for the snapshot",
+			Value: 5 * time.Minute,
+		},
+	},
+	Action: runSnapshotCreate,
+}
+
+// SnapshotRestoreCommand reverts a PVC to a snapshot
+var SnapshotRestoreCommand = &cli.Command{
+	Name:      "restore",
+	Usage:     "Revert a PVC to one of its snapshots",
+	ArgsUsage: "<namespace>/<name> <snapshot>",
+	Description: `Reverts the PVC's Longhorn volume to a snapshot, in place.
+
+The Deployments and StatefulSets that mount the claim are scaled to zero,
+the volume is attached in maintenance mode and reverted, and the workloads
+are scaled back up. Everything written after the snapshot was taken is lost.
+
+The restore refuses claims mounted by pods it can't scale down (bare pods,
+DaemonSets, Jobs) and workloads managed by a HorizontalPodAutoscaler.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"f"},
+			Usage:   "Skip confirmation prompt",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Max time for the whole restore",
+			Value: 15 * time.Minute,
+		},
+	},
+	Action: runSnapshotRestore,
+}
+
+// getLonghornClient creates a Longhorn snapshot client from kubeconfig
+func getLonghornClient() (*longhorn.Client, error) {
+	config, err := getRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := getK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return &longhorn.Client{
+		Kube:    clientset,
+		Dynamic: dynamicClient,
+		API:     longhorn.NewProxyAPI(clientset, longhorn.DefaultNamespace),
+		Out:     os.Stdout,
+	}, nil
+}
+
+// snapshotClaim parses the claim argument and returns its Longhorn volume
+func snapshotClaim(ctx context.Context, cmd *cli.Command, client *longhorn.Client) (namespace, name, volume string, err error) {
+	ref := cmd.Args().Get(0)
+	if ref == "" {
+		return "", "", "", fmt.Errorf("PVC is required\n\nUsage: foundry storage snapshot %s <namespace>/<name>", cmd.Name)
+	}
+	namespace, name, err = pvcmigrate.ParseClaimRef(ref)
+	if err != nil {
+		return "", "", "", err
+	}
+	volume, err = client.VolumeForClaim(ctx, namespace, name)
+	return namespace, name, volume, err
+}
+
+func runSnapshotList(ctx context.Context, cmd *cli.Command) error {
+	client, err := getLonghornClient()
+	if err != nil {
+		return err
+	}
+	namespace, name, volume, err := snapshotClaim(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := client.Snapshots(ctx, volume)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Printf("No snapshots of PVC %s/%s (volume %s)\n", namespace, name, volume)
+		return nil
+	}
+
+	fmt.Printf("%-50s %-20s %-10s %-10s %s\n", "NAME", "CREATED", "SIZE", "READY", "CREATED BY")
+	fmt.Println(strings.Repeat("-", 105))
+	for _, s := range snapshots {
+		createdBy := "longhorn"
+		if s.UserCreated {
+			createdBy = "user"
+		}
+		if job := s.Labels["RecurringJob"]; job != "" {
+			createdBy = "recurring job " + job
+		}
+		fmt.Printf("%-50s %-20s %-10s %-10t %s\n", s.Name, s.Created.Local().Format("2006-01-02 15:04:05"), format.Bytes(s.Size), s.Ready, createdBy)
+	}
+	return nil
+}
+
+func runSnapshotCreate(ctx context.Context, cmd *cli.Command) error {
+	client, err := getLonghornClient()
+	if err != nil {
+		return err
+	}
+	namespace, name, volume, err := snapshotClaim(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.Duration("timeout"))
+	defer cancel()
+	fmt.Printf("Taking a snapshot of PVC %s/%s (volume %s)...\n", namespace, name, volume)
+	snapshot, err := client.CreateSnapshot(ctx, volume, cmd.String("name"), nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Snapshot %s created\n", snapshot.Name)
+	fmt.Printf("\nRevert to it with: foundry storage snapshot restore %s/%s %s\n", namespace, name, snapshot.Name)
+	return nil
+}
+
+func runSnapshotRestore(ctx context.Context, cmd *cli.Command) error {
+	snapshot := cmd.Args().Get(1)
+	if cmd.Args().Get(0) == "" || snapshot == "" {
+		return fmt.Errorf("PVC and snapshot are required\n\nUsage: foundry storage snapshot restore <namespace>/<name> <snapshot>")
+	}
+	client, err := getLonghornClient()
+	if err != nil {
+		return err
+	}
+	namespace, name, volume, err := snapshotClaim(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("force") {
+		fmt.Printf("PVC %s/%s (volume %s) will be reverted to snapshot %s.\n", namespace, name, volume, snapshot)
+		fmt.Println("Its workloads will be down during the revert, and data written since the snapshot is lost.")
+		fmt.Print("Type 'yes' to restore: ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "yes" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+	fmt.Println()
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.Duration("timeout"))
+	defer cancel()
+	return client.Restore(ctx, namespace, name, snapshot)
+}
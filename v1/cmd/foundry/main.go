@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 
+	alertscmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/alerts"
 	backupcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/backup"
+	bundlecmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/bundle"
 	clustercmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/cluster"
 	componentcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/component"
 	configcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/config"
@@ -19,6 +21,8 @@ import (
 	metricscmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/metrics"
 	networkcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/network"
 	openbaocmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/openbao"
+	registrycmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/registry"
+	s3cmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/s3"
 	stackcmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/stack"
 	storagecmd "github.com/catalystcommunity/foundry/v1/cmd/foundry/commands/storage"
 	"github.com/catalystcommunity/foundry/v1/cmd/foundry/registry"
@@ -65,7 +69,9 @@ func main() {
 			return ctx, nil
 		},
 		Commands: []*cli.Command{
+			alertscmd.Command,
 			backupcmd.Command,
+			bundlecmd.Command,
 			clustercmd.Commands(),
 			componentcmd.Command,
 			configcmd.Command,
@@ -79,6 +85,8 @@ func main() {
 			metricscmd.Command,
 			networkcmd.Command,
 			openbaocmd.Command,
+			registrycmd.Command,
+			s3cmd.Command,
 			stackcmd.Command,
 			storagecmd.Command,
 			guicmd.ServeCommand,
@@ -2,6 +2,8 @@ package registry
 
 import (
 	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/alloy"
+	"github.com/catalystcommunity/foundry/v1/internal/component/blackbox"
 	"github.com/catalystcommunity/foundry/v1/internal/component/certmanager"
 	"github.com/catalystcommunity/foundry/v1/internal/component/contour"
 	"github.com/catalystcommunity/foundry/v1/internal/component/dns"
@@ -9,13 +11,16 @@ import (
 	"github.com/catalystcommunity/foundry/v1/internal/component/gatewayapi"
 	"github.com/catalystcommunity/foundry/v1/internal/component/gatewaycontroller"
 	"github.com/catalystcommunity/foundry/v1/internal/component/grafana"
+	"github.com/catalystcommunity/foundry/v1/internal/component/hostlogs"
 	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
 	"github.com/catalystcommunity/foundry/v1/internal/component/loki"
+	"github.com/catalystcommunity/foundry/v1/internal/component/nodeexporter"
 	"github.com/catalystcommunity/foundry/v1/internal/component/openbao"
 	"github.com/catalystcommunity/foundry/v1/internal/component/openbaoinjector"
 	"github.com/catalystcommunity/foundry/v1/internal/component/prometheus"
 	"github.com/catalystcommunity/foundry/v1/internal/component/seaweedfs"
 	"github.com/catalystcommunity/foundry/v1/internal/component/storage"
+	"github.com/catalystcommunity/foundry/v1/internal/component/tempo"
 	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
 	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
 )
@@ -40,6 +45,12 @@ func InitComponents() error {
 		return err
 	}
 
+	// Register node-exporter - no dependencies beyond the container runtime
+	// every host gets; installed on each host for host metrics
+	if err := component.Register(&nodeexporter.Component{}); err != nil {
+		return err
+	}
+
 	// Register K3s - depends on OpenBAO, DNS, and Zot
 	if err := component.Register(&k3s.Component{}); err != nil {
 		return err
@@ -109,6 +120,24 @@ func InitComponents() error {
 		return err
 	}
 
+	// Register Tempo - depends on storage and seaweedfs for trace storage
+	// Tempo receives OTLP traces and serves them to Grafana
+	tempoComp := tempo.NewComponent(nil, nil)
+	if err := component.Register(tempoComp); err != nil {
+		return err
+	}
+
+	// Register Alloy - depends on loki; collects pod logs, events and OTLP
+	alloyComp := alloy.NewComponent(nil, nil)
+	if err := component.Register(alloyComp); err != nil {
+		return err
+	}
+
+	// Register host-logs - depends on loki; ships each host's journal to it
+	if err := component.Register(&hostlogs.Component{}); err != nil {
+		return err
+	}
+
 	// Register Grafana - depends on prometheus and loki for data sources
 	// Grafana provides unified observability dashboards
 	grafanaComp := grafana.NewComponent(nil, nil)
@@ -130,5 +159,12 @@ func InitComponents() error {
 		return err
 	}
 
+	// Register blackbox-exporter - depends on prometheus, which runs its Probes
+	// blackbox-exporter probes every exposed route and service link
+	blackboxComp := blackbox.NewComponent(nil, nil)
+	if err := component.Register(blackboxComp); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -26,6 +26,7 @@ func TestInitComponents(t *testing.T) {
 		"openbao",
 		"dns",
 		"zot",
+		"node-exporter",
 		"k3s",
 		"gateway-api",
 		"contour",
@@ -35,9 +36,13 @@ func TestInitComponents(t *testing.T) {
 		"seaweedfs",
 		"prometheus",
 		"loki",
+		"tempo",
+		"alloy",
+		"host-logs",
 		"grafana",
 		"external-dns",
 		"velero",
+		"blackbox-exporter",
 		"openbao-injector",
 	}
 	for _, name := range expectedComponents {
@@ -67,6 +72,7 @@ func TestInitComponents_ComponentNames(t *testing.T) {
 		{name: "openbao"},
 		{name: "dns"},
 		{name: "zot"},
+		{name: "node-exporter"},
 		{name: "k3s"},
 		{name: "gateway-api"},
 		{name: "contour"},
@@ -76,9 +82,13 @@ func TestInitComponents_ComponentNames(t *testing.T) {
 		{name: "seaweedfs"},
 		{name: "prometheus"},
 		{name: "loki"},
+		{name: "tempo"},
+		{name: "alloy"},
+		{name: "host-logs"},
 		{name: "grafana"},
 		{name: "external-dns"},
 		{name: "velero"},
+		{name: "blackbox-exporter"},
 		{name: "openbao-injector"},
 	}
 
@@ -119,6 +129,10 @@ func TestInitComponents_Dependencies(t *testing.T) {
 			name:         "zot",
 			dependencies: []string{"openbao", "dns"},
 		},
+		{
+			name:         "node-exporter",
+			dependencies: []string{},
+		},
 		{
 			name:         "k3s",
 			dependencies: []string{"openbao", "dns", "zot"},
@@ -155,6 +169,18 @@ func TestInitComponents_Dependencies(t *testing.T) {
 			name:         "loki",
 			dependencies: []string{"storage", "seaweedfs"},
 		},
+		{
+			name:         "tempo",
+			dependencies: []string{"storage", "seaweedfs"},
+		},
+		{
+			name:         "alloy",
+			dependencies: []string{"loki"},
+		},
+		{
+			name:         "host-logs",
+			dependencies: []string{"loki"},
+		},
 		{
 			name:         "grafana",
 			dependencies: []string{"prometheus", "loki"},
@@ -167,6 +193,10 @@ func TestInitComponents_Dependencies(t *testing.T) {
 			name:         "velero",
 			dependencies: []string{"seaweedfs"},
 		},
+		{
+			name:         "blackbox-exporter",
+			dependencies: []string{"prometheus"},
+		},
 		{
 			name:         "openbao-injector",
 			dependencies: []string{"openbao", "k3s"},
@@ -0,0 +1,273 @@
+// Package bundle builds and opens offline installation bundles. A bundle is a
+// gzipped tar holding everything a stack install would otherwise download:
+// the k3s binary, install script and airgap images, every Helm chart at its
+// pinned version, the runtime tools and manifests Foundry fetches over HTTP,
+// and every container image in an OCI image layout.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+const (
+	// FormatVersion is bumped whenever the bundle layout changes
+	FormatVersion = 1
+
+	// ManifestFile is the bundle's table of contents
+	ManifestFile = "manifest.json"
+
+	// Arch is the only architecture bundles are built for. The container
+	// runtime tools Foundry installs are amd64 builds.
+	Arch = "amd64"
+
+	imagesDir = "images"
+)
+
+// Manifest describes the contents of a bundle. Paths are relative to the
+// bundle root.
+type Manifest struct {
+	Format    int       `json:"format"`
+	CreatedAt time.Time `json:"created_at"`
+	Arch      string    `json:"arch"`
+	K3s       K3s       `json:"k3s"`
+	Charts    []Chart   `json:"charts"`
+	Images    []Image   `json:"images"`
+	Files     []File    `json:"files"`
+}
+
+// K3s is the k3s release carried by a bundle
+type K3s struct {
+	Version string `json:"version"`
+	Script  string `json:"script"`
+	Binary  string `json:"binary"`
+	Images  string `json:"images"`
+}
+
+// Chart is a Helm chart archive. Ref is the chart reference components
+// install, such as "grafana/grafana" or an oci:// URL.
+type Chart struct {
+	Component string `json:"component"`
+	Ref       string `json:"ref"`
+	RepoURL   string `json:"repo_url,omitempty"`
+	Version   string `json:"version"`
+	Path      string `json:"path"`
+}
+
+// Image is a container image in the bundle's OCI layout. Ref is the fully
+// qualified reference the image is tagged with in the layout.
+type Image struct {
+	Component string `json:"component"`
+	Ref       string `json:"ref"`
+}
+
+// File is a download Foundry would otherwise fetch from URL. HostPath is set
+// when the file is staged on every host rather than read locally.
+type File struct {
+	URL      string `json:"url"`
+	Path     string `json:"path"`
+	HostPath string `json:"host_path,omitempty"`
+}
+
+// Bundle is an extracted bundle
+type Bundle struct {
+	Dir      string
+	Manifest *Manifest
+}
+
+// Open extracts the bundle at path into a temporary directory. Call Close to
+// remove it.
+func Open(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	dir, err := os.MkdirTemp("", "foundry-bundle-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	if err := extract(f, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to extract bundle %s: %w", path, err)
+	}
+
+	b := &Bundle{Dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("%s is not a Foundry bundle: %w", path, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if manifest.Format != FormatVersion {
+		b.Close()
+		return nil, fmt.Errorf("unsupported bundle format %d (this Foundry reads format %d)", manifest.Format, FormatVersion)
+	}
+	b.Manifest = &manifest
+	return b, nil
+}
+
+// Close removes the extracted bundle
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.Dir)
+}
+
+// Path returns the absolute path of a bundle-relative path
+func (b *Bundle) Path(rel string) string {
+	return filepath.Join(b.Dir, filepath.FromSlash(rel))
+}
+
+// LocalCharts maps every bundled chart reference to its archive, ready for
+// helm.Client.UseLocalCharts
+func (b *Bundle) LocalCharts() map[string]helm.LocalChart {
+	charts := make(map[string]helm.LocalChart, len(b.Manifest.Charts))
+	for _, c := range b.Manifest.Charts {
+		charts[c.Ref] = helm.LocalChart{Version: c.Version, Path: b.Path(c.Path)}
+	}
+	return charts
+}
+
+// File returns the local path of the file downloaded from url
+func (b *Bundle) File(url string) (string, bool) {
+	for _, f := range b.Manifest.Files {
+		if f.URL == url {
+			return b.Path(f.Path), true
+		}
+	}
+	return "", false
+}
+
+// images opens the bundle's OCI image layout
+func (b *Bundle) images() (*oci.Store, error) {
+	store, err := oci.New(b.Path(imagesDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle images: %w", err)
+	}
+	return store, nil
+}
+
+// extract unpacks a gzipped tar into dir
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path %q in archive", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archive packs dir into a gzipped tar at path
+func archive(dir, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	err = tarDir(gz, dir)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeTar packs dir into a plain tar at path
+func writeTar(dir, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = tarDir(f, dir)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// tarDir writes the contents of dir to w as a tar stream
+func tarDir(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,211 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// writeBundle builds a small bundle on disk and returns its path
+func writeBundle(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	work := t.TempDir()
+
+	files := map[string]string{
+		"k3s/install.sh":                      "#!/bin/sh\n",
+		"k3s/k3s":                             "k3s-binary",
+		"k3s/k3s-airgap-images-amd64.tar.zst": "airgap-images",
+		"charts/grafana-8.8.2.tgz":            "chart",
+		"files/cni-plugins.tgz":               "cni",
+		"files/gateway-api-v1.3.0.yaml":       "kind: CustomResourceDefinition",
+	}
+	for name, content := range files {
+		path := filepath.Join(work, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	store, err := oci.New(filepath.Join(work, imagesDir))
+	require.NoError(t, err)
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.example", oras.PackManifestOptions{})
+	require.NoError(t, err)
+	for _, ref := range []string{"quay.io/openbao/openbao:2.0.0", "docker.io/grafana/grafana:11.4.0"} {
+		require.NoError(t, store.Tag(ctx, desc, ref))
+	}
+
+	manifest := Manifest{
+		Format: FormatVersion,
+		Arch:   Arch,
+		K3s: K3s{
+			Version: "v1.33.5+k3s1",
+			Script:  "k3s/install.sh",
+			Binary:  "k3s/k3s",
+			Images:  "k3s/k3s-airgap-images-amd64.tar.zst",
+		},
+		Charts: []Chart{{Component: "grafana", Ref: "grafana/grafana", Version: "8.8.2", Path: "charts/grafana-8.8.2.tgz"}},
+		Images: []Image{
+			{Component: "openbao", Ref: "quay.io/openbao/openbao:2.0.0"},
+			{Component: "grafana", Ref: "docker.io/grafana/grafana:11.4.0"},
+		},
+		Files: []File{
+			{URL: "https://example.com/cni-plugins.tgz", Path: "files/cni-plugins.tgz", HostPath: "/var/lib/foundry/bundle/tools/cni-plugins.tgz"},
+			{URL: "https://example.com/standard-install.yaml", Path: "files/gateway-api-v1.3.0.yaml"},
+		},
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(work, ManifestFile), data, 0644))
+
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, archive(work, path))
+	return path
+}
+
+type upload struct {
+	mode    os.FileMode
+	content []byte
+}
+
+type fakeUploader map[string]upload
+
+func (f fakeUploader) Upload(r io.Reader, path string, mode os.FileMode) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f[path] = upload{mode: mode, content: data}
+	return nil
+}
+
+type loadRecorder struct {
+	container.Runtime
+	loaded []string
+}
+
+func (l *loadRecorder) Load(archivePath string) error {
+	l.loaded = append(l.loaded, archivePath)
+	return nil
+}
+
+func TestOpen(t *testing.T) {
+	b, err := Open(writeBundle(t))
+	require.NoError(t, err)
+	defer b.Close()
+
+	assert.Equal(t, "v1.33.5+k3s1", b.Manifest.K3s.Version)
+	assert.Equal(t, []string{"docker.io", "quay.io"}, b.Manifest.Registries())
+
+	charts := b.LocalCharts()
+	require.Contains(t, charts, "grafana/grafana")
+	assert.Equal(t, "8.8.2", charts["grafana/grafana"].Version)
+	assert.FileExists(t, charts["grafana/grafana"].Path)
+
+	path, ok := b.File("https://example.com/standard-install.yaml")
+	require.True(t, ok)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: CustomResourceDefinition", string(data))
+
+	_, ok = b.File("https://example.com/missing")
+	assert.False(t, ok)
+
+	dir := b.Dir
+	require.NoError(t, b.Close())
+	assert.NoDirExists(t, dir)
+}
+
+func TestOpen_RejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-bundle.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("plain text"), 0644))
+
+	_, err := Open(path)
+	assert.Error(t, err)
+}
+
+func TestStage(t *testing.T) {
+	b, err := Open(writeBundle(t))
+	require.NoError(t, err)
+	defer b.Close()
+
+	t.Run("k3s", func(t *testing.T) {
+		u := fakeUploader{}
+		require.NoError(t, b.StageK3s(u))
+		assert.Equal(t, upload{mode: 0755, content: []byte("#!/bin/sh\n")}, u["/var/lib/foundry/bundle/k3s/install.sh"])
+		assert.Equal(t, upload{mode: 0755, content: []byte("k3s-binary")}, u["/usr/local/bin/k3s"])
+		assert.Equal(t, "airgap-images", string(u["/var/lib/rancher/k3s/agent/images/k3s-airgap-images-amd64.tar.zst"].content))
+	})
+
+	t.Run("tools", func(t *testing.T) {
+		u := fakeUploader{}
+		require.NoError(t, b.StageTools(u))
+		assert.Len(t, u, 1)
+		assert.Equal(t, "cni", string(u["/var/lib/foundry/bundle/tools/cni-plugins.tgz"].content))
+	})
+
+	t.Run("host images", func(t *testing.T) {
+		u := fakeUploader{}
+		rt := &loadRecorder{}
+		require.NoError(t, b.LoadImages(context.Background(), u, rt, "openbao"))
+
+		remote := "/var/lib/foundry/bundle/images/quay.io_openbao_openbao_2.0.0.tar"
+		assert.Equal(t, []string{remote}, rt.loaded)
+		require.Contains(t, u, remote)
+
+		// The archive is an OCI layout naming the image for containerd
+		tr := tar.NewReader(bytes.NewReader(u[remote].content))
+		var index ocispec.Index
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			if hdr.Name == ocispec.ImageIndexFile {
+				require.NoError(t, json.NewDecoder(tr).Decode(&index))
+			}
+		}
+		require.Len(t, index.Manifests, 1)
+		assert.Equal(t, "quay.io/openbao/openbao:2.0.0", index.Manifests[0].Annotations["io.containerd.image.name"])
+	})
+}
+
+func TestNormalizeImage(t *testing.T) {
+	tests := map[string]string{
+		"busybox":                            "docker.io/library/busybox:latest",
+		"busybox:1.36":                       "docker.io/library/busybox:1.36",
+		"grafana/grafana:11.4.0":             "docker.io/grafana/grafana:11.4.0",
+		"quay.io/prometheus/prometheus:v3.1": "quay.io/prometheus/prometheus:v3.1",
+	}
+	for in, want := range tests {
+		got, err := NormalizeImage(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := NormalizeImage("Not A Reference")
+	assert.Error(t, err)
+}
+
+func TestSplitImage(t *testing.T) {
+	registry, repo, object, err := SplitImage("quay.io/prometheus/prometheus:v3.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"quay.io", "prometheus/prometheus", "v3.1.0"}, []string{registry, repo, object})
+
+	digest := "sha256:" + string(bytes.Repeat([]byte("a"), 64))
+	registry, repo, object, err = SplitImage("busybox:1.36@" + digest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker.io", "library/busybox", digest}, []string{registry, repo, object})
+}
@@ -0,0 +1,215 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/gatewayapi"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+const (
+	// DefaultK3sVersion is bundled when the stack config does not pin one
+	DefaultK3sVersion = "v1.33.5+k3s1"
+
+	k3sScriptURL  = "https://get.k3s.io"
+	k3sReleaseURL = "https://github.com/k3s-io/k3s/releases/download"
+)
+
+// Plan is what a bundle is built from
+type Plan struct {
+	K3sVersion        string
+	GatewayAPIVersion string
+	Charts            []PlannedChart
+	// Images are the images known without rendering a chart: host services,
+	// kube-vip and anything requested explicitly
+	Images []Image
+	// ImageDigests pins images, by normalized reference, to the digests in
+	// the stack lockfile
+	ImageDigests map[string]string
+}
+
+// PlannedChart is a chart one component installs
+type PlannedChart struct {
+	Component string
+	helm.ChartSource
+}
+
+// Create downloads everything in plan and writes the bundle to output.
+// Images referenced by the planned charts are found by rendering them.
+func Create(ctx context.Context, plan *Plan, output string, out io.Writer) (*Manifest, error) {
+	work, err := os.MkdirTemp("", "foundry-bundle-create-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	k3sVersion := plan.K3sVersion
+	if k3sVersion == "" {
+		k3sVersion = DefaultK3sVersion
+	}
+	manifest := &Manifest{
+		Format:    FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Arch:      Arch,
+		K3s: K3s{
+			Version: k3sVersion,
+			Script:  "k3s/install.sh",
+			Binary:  "k3s/k3s",
+			Images:  fmt.Sprintf("k3s/k3s-airgap-images-%s.tar.zst", Arch),
+		},
+	}
+
+	// k3s
+	fmt.Fprintf(out, "  Downloading k3s %s...\n", k3sVersion)
+	release := k3sReleaseURL + "/" + url.PathEscape(k3sVersion)
+	k3sFiles := []File{
+		{URL: k3sScriptURL, Path: manifest.K3s.Script},
+		{URL: release + "/k3s", Path: manifest.K3s.Binary},
+		{URL: release + "/" + filepath.Base(manifest.K3s.Images), Path: manifest.K3s.Images},
+	}
+	for _, f := range k3sFiles {
+		if err := download(ctx, f.URL, filepath.Join(work, f.Path)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Files fetched over HTTP during install
+	files := []File{
+		{
+			URL:      container.CNIPluginsURL,
+			Path:     "files/" + filepath.Base(container.CNIPluginsURL),
+			HostPath: container.StagedToolPath(container.CNIPluginsURL),
+		},
+		{
+			URL:      container.NerdctlURL,
+			Path:     "files/" + filepath.Base(container.NerdctlURL),
+			HostPath: container.StagedToolPath(container.NerdctlURL),
+		},
+	}
+	if plan.GatewayAPIVersion != "" {
+		files = append(files, File{
+			URL:  gatewayapi.ManifestURL(plan.GatewayAPIVersion),
+			Path: "files/gateway-api-" + plan.GatewayAPIVersion + ".yaml",
+		})
+	}
+	for _, f := range files {
+		fmt.Fprintf(out, "  Downloading %s...\n", f.URL)
+		if err := download(ctx, f.URL, filepath.Join(work, f.Path)); err != nil {
+			return nil, err
+		}
+	}
+	manifest.Files = files
+
+	// Charts, and the images they run
+	images := map[string]string{}
+	addImage := func(component, ref string) error {
+		normalized, err := NormalizeImage(ref)
+		if err != nil {
+			return err
+		}
+		if _, ok := images[normalized]; !ok {
+			images[normalized] = component
+		}
+		return nil
+	}
+	for _, img := range plan.Images {
+		if err := addImage(img.Component, img.Ref); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range plan.Charts {
+		fmt.Fprintf(out, "  Pulling chart %s %s...\n", c.Chart, c.Version)
+		path, err := helm.PullChart(ctx, c.RepoURL, c.Chart, c.Version, filepath.Join(work, "charts"))
+		if err != nil {
+			return nil, err
+		}
+		refs, err := helm.ChartImages(path, c.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list images of %s: %w", c.Chart, err)
+		}
+		for _, ref := range refs {
+			if err := addImage(c.Component, ref); err != nil {
+				return nil, err
+			}
+		}
+		manifest.Charts = append(manifest.Charts, Chart{
+			Component: c.Component,
+			Ref:       c.Chart,
+			RepoURL:   c.RepoURL,
+			Version:   c.Version,
+			Path:      "charts/" + filepath.Base(path),
+		})
+	}
+
+	// Images
+	store, err := oci.New(filepath.Join(work, imagesDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image layout: %w", err)
+	}
+	refs := make([]string, 0, len(images))
+	for ref := range images {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		fmt.Fprintf(out, "  Pulling image %s...\n", ref)
+		if err := pullImage(ctx, store, ref, plan.ImageDigests[ref]); err != nil {
+			return nil, err
+		}
+		manifest.Images = append(manifest.Images, Image{Component: images[ref], Ref: ref})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(work, ManifestFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+
+	fmt.Fprintf(out, "  Writing %s...\n", output)
+	if err := archive(work, output); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// download fetches src into dest
+func download(ctx context.Context, src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: status %d", src, resp.StatusCode)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download %s: %w", src, err)
+	}
+	return f.Close()
+}
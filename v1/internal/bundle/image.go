@@ -0,0 +1,121 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// NormalizeImage returns the fully qualified form of an image reference, so
+// "busybox:1.36" becomes "docker.io/library/busybox:1.36"
+func NormalizeImage(ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	return reference.TagNameOnly(named).String(), nil
+}
+
+// SplitImage splits a normalized image reference into its registry,
+// repository path and tag (or digest, when the reference pins one)
+func SplitImage(ref string) (registry, repo, object string, err error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	named = reference.TagNameOnly(named)
+	switch r := named.(type) {
+	case reference.Digested:
+		object = r.Digest().String()
+	case reference.Tagged:
+		object = r.Tag()
+	}
+	return reference.Domain(named), reference.Path(named), object, nil
+}
+
+// Registries returns the registries the bundled images come from
+func (m *Manifest) Registries() []string {
+	seen := map[string]bool{}
+	var registries []string
+	for _, img := range m.Images {
+		registry, _, _, err := SplitImage(img.Ref)
+		if err != nil || seen[registry] {
+			continue
+		}
+		seen[registry] = true
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// ResolveImage returns the digest of the linux/amd64 manifest an image
+// reference points at. References that already name a digest resolve to it.
+func ResolveImage(ctx context.Context, ref string) (string, error) {
+	src, object, err := remoteRepository(ref)
+	if err != nil {
+		return "", err
+	}
+	if _, err := digest.Parse(object); err == nil {
+		return object, nil
+	}
+	desc, err := oras.Resolve(ctx, src, object, oras.ResolveOptions{TargetPlatform: targetPlatform})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// targetPlatform is the platform multi-platform images are narrowed to
+var targetPlatform = &ocispec.Platform{OS: "linux", Architecture: Arch}
+
+// pullImage copies an image from its registry into the layout, tagged with
+// its normalized reference. Multi-platform images are narrowed to Arch. A
+// non-empty pin fetches that digest instead of the reference's tag.
+func pullImage(ctx context.Context, store *oci.Store, ref, pin string) error {
+	src, object, err := remoteRepository(ref)
+	if err != nil {
+		return err
+	}
+	if pin != "" {
+		object = pin
+	}
+
+	opts := oras.DefaultCopyOptions
+	opts.WithTargetPlatform(targetPlatform)
+	if _, err := oras.Copy(ctx, src, object, store, ref, opts); err != nil {
+		return fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+	return nil
+}
+
+// remoteRepository returns the upstream repository of an image reference
+// and the tag or digest to fetch from it
+func remoteRepository(ref string) (*remote.Repository, string, error) {
+	registry, repo, object, err := SplitImage(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	// Docker Hub serves its API from a different host than its name
+	if registry == "docker.io" {
+		registry = "registry-1.docker.io"
+	}
+	src, err := remote.NewRepository(registry + "/" + repo)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	src.Client = &auth.Client{
+		Client: retry.DefaultClient,
+		Cache:  auth.NewCache(),
+	}
+	return src, object, nil
+}
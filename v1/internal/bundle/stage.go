@@ -0,0 +1,171 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/catalystcommunity/foundry/v1/internal/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// hostImagesDir is where image archives are staged before they are loaded
+// into a host's container runtime
+const hostImagesDir = "/var/lib/foundry/bundle/images"
+
+// Uploader copies a file onto a host. *ssh.Connection implements it.
+type Uploader interface {
+	Upload(r io.Reader, path string, mode os.FileMode) error
+}
+
+// StageK3s puts the k3s install script, binary and airgap images where an
+// airgap k3s install expects them
+func (b *Bundle) StageK3s(u Uploader) error {
+	k := b.Manifest.K3s
+	if err := b.upload(u, k.Script, k3s.AirgapScriptPath, 0755); err != nil {
+		return err
+	}
+	if err := b.upload(u, k.Binary, k3s.AirgapBinaryPath, 0755); err != nil {
+		return err
+	}
+	return b.upload(u, k.Images, k3s.AirgapImagesDir+"/"+filepath.Base(k.Images), 0644)
+}
+
+// StageTools puts the container runtime tools on a host, where the runtime
+// install picks them up instead of downloading them
+func (b *Bundle) StageTools(u Uploader) error {
+	for _, f := range b.Manifest.Files {
+		if f.HostPath == "" {
+			continue
+		}
+		if err := b.upload(u, f.Path, f.HostPath, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadImages loads the bundled images of a host component (openbao, dns,
+// zot) into the host's container runtime
+func (b *Bundle) LoadImages(ctx context.Context, u Uploader, rt container.Runtime, component string) error {
+	store, err := b.images()
+	if err != nil {
+		return err
+	}
+	for _, img := range b.Manifest.Images {
+		if img.Component != component {
+			continue
+		}
+		archive, err := exportImage(ctx, store, img.Ref)
+		if err != nil {
+			return err
+		}
+		remote := hostImagesDir + "/" + strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(img.Ref) + ".tar"
+		err = uploadFile(u, archive, remote, 0644)
+		os.Remove(archive)
+		if err != nil {
+			return err
+		}
+		if err := rt.Load(remote); err != nil {
+			return fmt.Errorf("failed to load %s: %w", img.Ref, err)
+		}
+	}
+	return nil
+}
+
+// PushImages pushes every bundled image into the registry under its
+// repository path, so cluster nodes pulling through the registry mirror
+// find it there
+func (b *Bundle) PushImages(ctx context.Context, client *registry.Client, out io.Writer) error {
+	store, err := b.images()
+	if err != nil {
+		return err
+	}
+	for _, img := range b.Manifest.Images {
+		_, repo, object, err := SplitImage(img.Ref)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "    Pushing %s...\n", img.Ref)
+		if err := client.Push(ctx, store, img.Ref, repo, object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) upload(u Uploader, rel, remote string, mode os.FileMode) error {
+	return uploadFile(u, b.Path(rel), remote, mode)
+}
+
+func uploadFile(u Uploader, local, remote string, mode os.FileMode) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", local, err)
+	}
+	defer f.Close()
+	if err := u.Upload(f, remote, mode); err != nil {
+		return err
+	}
+	return nil
+}
+
+// exportImage writes one image from the layout to an OCI archive that
+// docker, podman and nerdctl can all load, and returns its path
+func exportImage(ctx context.Context, store *oci.Store, ref string) (string, error) {
+	dir, err := os.MkdirTemp("", "foundry-image-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dst, err := oci.New(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to create image layout: %w", err)
+	}
+	if _, err := oras.Copy(ctx, store, ref, dst, ref, oras.DefaultCopyOptions); err != nil {
+		return "", fmt.Errorf("failed to export %s: %w", ref, err)
+	}
+
+	// containerd-based loaders name the image from this annotation
+	indexPath := filepath.Join(dir, ocispec.ImageIndexFile)
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image index: %w", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return "", fmt.Errorf("failed to parse image index: %w", err)
+	}
+	for i := range index.Manifests {
+		if index.Manifests[i].Annotations == nil {
+			index.Manifests[i].Annotations = map[string]string{}
+		}
+		index.Manifests[i].Annotations["io.containerd.image.name"] = ref
+	}
+	if data, err = json.Marshal(index); err != nil {
+		return "", fmt.Errorf("failed to marshal image index: %w", err)
+	}
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write image index: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "foundry-image-*.tar")
+	if err != nil {
+		return "", fmt.Errorf("failed to create image archive: %w", err)
+	}
+	f.Close()
+	if err := writeTar(dir, f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write image archive: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,251 @@
+// Package capacity reports how much of the cluster's CPU, memory and storage
+// is requested, limited and actually used, per node and per namespace, and
+// which nodes still have room for a given pod shape.
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+// Client is the Kubernetes access the capacity report needs
+type Client interface {
+	GetNodes(ctx context.Context) ([]*k8s.Node, error)
+	GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error)
+	Clientset() kubernetes.Interface
+}
+
+// Resources compares one resource on a node or in a namespace. CPU is in
+// millicores and memory in bytes.
+type Resources struct {
+	// Allocatable is what the scheduler can hand out; it is zero for
+	// namespaces
+	Allocatable int64 `json:"allocatable,omitempty"`
+	Requested   int64 `json:"requested"`
+	Limits      int64 `json:"limits"`
+	// Used is the current usage from Prometheus, or nil when Prometheus
+	// could not be read
+	Used *int64 `json:"used,omitempty"`
+}
+
+// Free is the allocatable amount not yet requested
+func (r Resources) Free() int64 {
+	return r.Allocatable - r.Requested
+}
+
+// Disk is the storage usage of a node
+type Disk struct {
+	// Source is "longhorn" for Longhorn's node storage or "filesystem" for
+	// the node's root filesystem, where local-path volumes live
+	Source    string `json:"source"`
+	UsedBytes int64  `json:"used_bytes"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// Node is the capacity of one node
+type Node struct {
+	Name        string    `json:"name"`
+	Ready       bool      `json:"ready"`
+	Schedulable bool      `json:"schedulable"`
+	Pods        int       `json:"pods"`
+	CPU         Resources `json:"cpu"`
+	Memory      Resources `json:"memory"`
+	Disk        *Disk     `json:"disk,omitempty"`
+}
+
+// Namespace is what the pods of one namespace request and use
+type Namespace struct {
+	Name   string    `json:"name"`
+	Pods   int       `json:"pods"`
+	CPU    Resources `json:"cpu"`
+	Memory Resources `json:"memory"`
+}
+
+// Volume is the size and usage of one PersistentVolumeClaim
+type Volume struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	StorageClass string `json:"storage_class,omitempty"`
+	SizeBytes    int64  `json:"size_bytes"`
+	// UsedBytes is nil when no usage is reported for the volume
+	UsedBytes *int64 `json:"used_bytes,omitempty"`
+}
+
+// Report is the capacity of the whole cluster
+type Report struct {
+	Nodes      []Node      `json:"nodes"`
+	Namespaces []Namespace `json:"namespaces"`
+	Volumes    []Volume    `json:"volumes"`
+	Fit        *FitReport  `json:"fit,omitempty"`
+	// UsageAvailable is false when Prometheus could not be read, so only
+	// requests and limits are known
+	UsageAvailable bool     `json:"usage_available"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// Collect builds the report from the cluster's nodes, pods and volumes and
+// the usage Prometheus has recorded. Missing usage is a warning, not an
+// error.
+func Collect(ctx context.Context, client Client) (*Report, error) {
+	nodes, err := client.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	pods, err := client.GetPods(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods: %w", err)
+	}
+	pvcs, err := client.Clientset().CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumeClaims: %w", err)
+	}
+
+	var warnings []string
+	usage, err := QueryUsage(ctx, client.Clientset())
+	switch {
+	case err != nil:
+		warnings = append(warnings, fmt.Sprintf("Cannot read usage from Prometheus: %v", err))
+	case usage == nil:
+		warnings = append(warnings, "Prometheus is not installed; only requests and limits are shown")
+	}
+
+	report := Build(nodes, pods, pvcs.Items, usage)
+	report.Warnings = warnings
+	return report, nil
+}
+
+// Build combines nodes, pods, volume claims and usage into a report. A nil
+// usage leaves every Used field empty.
+func Build(nodes []*k8s.Node, pods []*k8s.Pod, pvcs []corev1.PersistentVolumeClaim, usage *Usage) *Report {
+	report := &Report{UsageAvailable: usage != nil}
+
+	byNode := map[string]*Node{}
+	for _, n := range nodes {
+		node := &Node{
+			Name:        n.Name,
+			Ready:       n.Ready,
+			Schedulable: !n.Unschedulable,
+			CPU:         Resources{Allocatable: quantityMilli(n.AllocatableCPU)},
+			Memory:      Resources{Allocatable: quantityValue(n.AllocatableMemory)},
+		}
+		if usage != nil {
+			node.CPU.Used = new(int64)
+			node.Memory.Used = new(int64)
+			if disk, ok := usage.NodeDisk[n.Name]; ok {
+				node.Disk = &disk
+			} else if disk, ok := usage.NodeDisk[n.InternalIP]; ok {
+				node.Disk = &disk
+			}
+		}
+		byNode[n.Name] = node
+	}
+
+	byNamespace := map[string]*Namespace{}
+	for _, pod := range pods {
+		// Finished pods no longer hold their requests
+		if pod.Phase == corev1.PodSucceeded || pod.Phase == corev1.PodFailed {
+			continue
+		}
+		ns, ok := byNamespace[pod.Namespace]
+		if !ok {
+			ns = &Namespace{Name: pod.Namespace}
+			if usage != nil {
+				ns.CPU.Used = new(int64)
+				ns.Memory.Used = new(int64)
+			}
+			byNamespace[pod.Namespace] = ns
+		}
+		addPod(&ns.CPU, &ns.Memory, pod, usage)
+		ns.Pods++
+
+		if node, ok := byNode[pod.NodeName]; ok {
+			addPod(&node.CPU, &node.Memory, pod, usage)
+			node.Pods++
+		}
+	}
+
+	for _, n := range nodes {
+		report.Nodes = append(report.Nodes, *byNode[n.Name])
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Name < report.Nodes[j].Name })
+
+	for _, ns := range byNamespace {
+		report.Namespaces = append(report.Namespaces, *ns)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool { return report.Namespaces[i].Name < report.Namespaces[j].Name })
+
+	for _, pvc := range pvcs {
+		report.Volumes = append(report.Volumes, volumeFromClaim(pvc, usage))
+	}
+	sort.Slice(report.Volumes, func(i, j int) bool {
+		if report.Volumes[i].Namespace != report.Volumes[j].Namespace {
+			return report.Volumes[i].Namespace < report.Volumes[j].Namespace
+		}
+		return report.Volumes[i].Name < report.Volumes[j].Name
+	})
+
+	return report
+}
+
+func addPod(cpu, memory *Resources, pod *k8s.Pod, usage *Usage) {
+	cpu.Requested += pod.Requests.Cpu().MilliValue()
+	cpu.Limits += pod.Limits.Cpu().MilliValue()
+	memory.Requested += pod.Requests.Memory().Value()
+	memory.Limits += pod.Limits.Memory().Value()
+	if usage != nil {
+		key := pod.Namespace + "/" + pod.Name
+		*cpu.Used += usage.PodCPU[key]
+		*memory.Used += usage.PodMemory[key]
+	}
+}
+
+func volumeFromClaim(pvc corev1.PersistentVolumeClaim, usage *Usage) Volume {
+	volume := Volume{Namespace: pvc.Namespace, Name: pvc.Name}
+	if pvc.Spec.StorageClassName != nil {
+		volume.StorageClass = *pvc.Spec.StorageClassName
+	}
+	// The bound capacity can be larger than the request
+	if size, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		volume.SizeBytes = size.Value()
+	} else if size, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		volume.SizeBytes = size.Value()
+	}
+	if usage != nil {
+		if used, ok := usage.VolumeUsed[pvc.Namespace+"/"+pvc.Name]; ok {
+			volume.UsedBytes = &used
+		}
+	}
+	return volume
+}
+
+// quantityMilli parses a node's allocatable CPU into millicores
+func quantityMilli(q *string) int64 {
+	if q == nil {
+		return 0
+	}
+	parsed, err := resource.ParseQuantity(*q)
+	if err != nil {
+		return 0
+	}
+	return parsed.MilliValue()
+}
+
+// quantityValue parses a node's allocatable memory into bytes
+func quantityValue(q *string) int64 {
+	if q == nil {
+		return 0
+	}
+	parsed, err := resource.ParseQuantity(*q)
+	if err != nil {
+		return 0
+	}
+	return parsed.Value()
+}
@@ -0,0 +1,161 @@
+package capacity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+func strPtr(s string) *string { return &s }
+
+func resources(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func testNodes() []*k8s.Node {
+	return []*k8s.Node{
+		{Name: "node-b", Ready: true, InternalIP: "10.0.0.2", AllocatableCPU: strPtr("2"), AllocatableMemory: strPtr("4Gi")},
+		{Name: "node-a", Ready: true, InternalIP: "10.0.0.1", AllocatableCPU: strPtr("4"), AllocatableMemory: strPtr("8Gi")},
+	}
+}
+
+func testPods() []*k8s.Pod {
+	return []*k8s.Pod{
+		{Name: "web-1", Namespace: "apps", NodeName: "node-a", Phase: corev1.PodRunning, Requests: resources("500m", "1Gi"), Limits: resources("1", "2Gi")},
+		{Name: "web-2", Namespace: "apps", NodeName: "node-b", Phase: corev1.PodRunning, Requests: resources("500m", "1Gi"), Limits: resources("1", "2Gi")},
+		{Name: "prometheus-0", Namespace: "monitoring", NodeName: "node-a", Phase: corev1.PodRunning, Requests: resources("1", "2Gi")},
+		{Name: "backup-job", Namespace: "apps", NodeName: "node-b", Phase: corev1.PodSucceeded, Requests: resources("2", "4Gi")},
+		{Name: "web-3", Namespace: "apps", Phase: corev1.PodPending, Requests: resources("500m", "1Gi")},
+	}
+}
+
+func testClaims() []corev1.PersistentVolumeClaim {
+	className := "longhorn"
+	return []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "apps"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &className,
+				Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "apps"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+			},
+		},
+	}
+}
+
+func TestBuildWithoutUsage(t *testing.T) {
+	report := Build(testNodes(), testPods(), testClaims(), nil)
+
+	assert.False(t, report.UsageAvailable)
+	require.Len(t, report.Nodes, 2)
+
+	nodeA := report.Nodes[0]
+	assert.Equal(t, "node-a", nodeA.Name)
+	assert.Equal(t, 2, nodeA.Pods)
+	assert.Equal(t, int64(4000), nodeA.CPU.Allocatable)
+	assert.Equal(t, int64(1500), nodeA.CPU.Requested)
+	assert.Equal(t, int64(1000), nodeA.CPU.Limits)
+	assert.Equal(t, int64(3<<30), nodeA.Memory.Requested)
+	assert.Nil(t, nodeA.CPU.Used)
+	assert.Nil(t, nodeA.Disk)
+
+	// The finished job no longer counts against node-b
+	nodeB := report.Nodes[1]
+	assert.Equal(t, 1, nodeB.Pods)
+	assert.Equal(t, int64(500), nodeB.CPU.Requested)
+
+	require.Len(t, report.Namespaces, 2)
+	apps := report.Namespaces[0]
+	assert.Equal(t, "apps", apps.Name)
+	// The pending pod counts for its namespace but has no node yet
+	assert.Equal(t, 3, apps.Pods)
+	assert.Equal(t, int64(1500), apps.CPU.Requested)
+	assert.Equal(t, int64(0), apps.CPU.Allocatable)
+
+	require.Len(t, report.Volumes, 2)
+	assert.Equal(t, "data", report.Volumes[0].Name)
+	assert.Equal(t, "longhorn", report.Volumes[0].StorageClass)
+	assert.Equal(t, int64(10<<30), report.Volumes[0].SizeBytes)
+	assert.Equal(t, int64(1<<30), report.Volumes[1].SizeBytes)
+	assert.Nil(t, report.Volumes[0].UsedBytes)
+}
+
+func TestBuildWithUsage(t *testing.T) {
+	usage := &Usage{
+		PodCPU:     map[string]int64{"apps/web-1": 200, "monitoring/prometheus-0": 300, "apps/web-2": 100},
+		PodMemory:  map[string]int64{"apps/web-1": 512 << 20, "monitoring/prometheus-0": 1 << 30},
+		VolumeUsed: map[string]int64{"apps/data": 3 << 30},
+		NodeDisk: map[string]Disk{
+			"node-a":   {Source: "longhorn", UsedBytes: 20 << 30, SizeBytes: 100 << 30},
+			"10.0.0.2": {Source: "filesystem", UsedBytes: 5 << 30, SizeBytes: 50 << 30},
+		},
+	}
+	report := Build(testNodes(), testPods(), testClaims(), usage)
+
+	assert.True(t, report.UsageAvailable)
+	nodeA, nodeB := report.Nodes[0], report.Nodes[1]
+	require.NotNil(t, nodeA.CPU.Used)
+	assert.Equal(t, int64(500), *nodeA.CPU.Used)
+	assert.Equal(t, int64(1536<<20), *nodeA.Memory.Used)
+	require.NotNil(t, nodeA.Disk)
+	assert.Equal(t, "longhorn", nodeA.Disk.Source)
+	// Node exporter instances are matched on the node's address
+	require.NotNil(t, nodeB.Disk)
+	assert.Equal(t, "filesystem", nodeB.Disk.Source)
+	assert.Equal(t, int64(0), *nodeB.Memory.Used)
+
+	assert.Equal(t, int64(300), *report.Namespaces[0].CPU.Used)
+
+	require.NotNil(t, report.Volumes[0].UsedBytes)
+	assert.Equal(t, int64(3<<30), *report.Volumes[0].UsedBytes)
+	assert.Nil(t, report.Volumes[1].UsedBytes)
+}
+
+// fakeClient serves nodes and pods from memory
+type fakeClient struct {
+	nodes []*k8s.Node
+	pods  []*k8s.Pod
+	kube  kubernetes.Interface
+}
+
+func (c *fakeClient) GetNodes(context.Context) ([]*k8s.Node, error) { return c.nodes, nil }
+
+func (c *fakeClient) GetPods(context.Context, string) ([]*k8s.Pod, error) { return c.pods, nil }
+
+func (c *fakeClient) Clientset() kubernetes.Interface { return c.kube }
+
+func TestCollectWithoutPrometheus(t *testing.T) {
+	claims := testClaims()
+	client := &fakeClient{
+		nodes: testNodes(),
+		pods:  testPods(),
+		kube:  kubernetesfake.NewSimpleClientset(&claims[0], &claims[1]),
+	}
+
+	report, err := Collect(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.False(t, report.UsageAvailable)
+	assert.Len(t, report.Nodes, 2)
+	assert.Len(t, report.Volumes, 2)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "Prometheus is not installed")
+}
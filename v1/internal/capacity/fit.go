@@ -0,0 +1,127 @@
+package capacity
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Shape is the resource requests of a pod to place. CPU is in millicores and
+// memory in bytes; zero means the pod requests none.
+type Shape struct {
+	CPU    int64 `json:"cpu"`
+	Memory int64 `json:"memory"`
+}
+
+// String renders the shape the way ParseShape reads it
+func (s Shape) String() string {
+	var parts []string
+	if s.CPU > 0 {
+		parts = append(parts, "cpu="+resource.NewMilliQuantity(s.CPU, resource.DecimalSI).String())
+	}
+	if s.Memory > 0 {
+		parts = append(parts, "mem="+resource.NewQuantity(s.Memory, resource.BinarySI).String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseShape reads a pod shape such as "cpu=2,mem=4Gi". Quantities use
+// Kubernetes notation; "memory" is accepted for "mem".
+func ParseShape(value string) (Shape, error) {
+	var shape Shape
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(part, "=")
+		if !ok {
+			return Shape{}, fmt.Errorf("invalid pod shape entry %q: expected key=quantity", part)
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(raw))
+		if err != nil {
+			return Shape{}, fmt.Errorf("invalid quantity for %s: %w", key, err)
+		}
+		if quantity.Sign() < 0 {
+			return Shape{}, fmt.Errorf("%s must not be negative", key)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "cpu":
+			shape.CPU = quantity.MilliValue()
+		case "mem", "memory":
+			shape.Memory = quantity.Value()
+		default:
+			return Shape{}, fmt.Errorf("unknown resource %q: use cpu or mem", key)
+		}
+	}
+	if shape.CPU == 0 && shape.Memory == 0 {
+		return Shape{}, fmt.Errorf("pod shape must request cpu or mem")
+	}
+	return shape, nil
+}
+
+// NodeFit says whether, and how many times, a shape fits on a node
+type NodeFit struct {
+	Node  string `json:"node"`
+	Fits  bool   `json:"fits"`
+	Count int64  `json:"count"`
+	// Reason explains why the shape does not fit
+	Reason string `json:"reason,omitempty"`
+}
+
+// FitReport is where a pod shape can be scheduled
+type FitReport struct {
+	Shape Shape     `json:"shape"`
+	Nodes []NodeFit `json:"nodes"`
+}
+
+// Fit checks a shape against each node's unrequested allocatable resources,
+// which is what the scheduler compares; current usage does not matter to it.
+// Taints and affinity are not considered.
+func Fit(nodes []Node, shape Shape) *FitReport {
+	report := &FitReport{Shape: shape, Nodes: make([]NodeFit, 0, len(nodes))}
+	for _, node := range nodes {
+		fit := NodeFit{Node: node.Name}
+		switch {
+		case !node.Ready:
+			fit.Reason = "node is not ready"
+		case !node.Schedulable:
+			fit.Reason = "node is cordoned"
+		default:
+			fit.Count, fit.Reason = fitCount(node, shape)
+		}
+		fit.Fits = fit.Count > 0
+		report.Nodes = append(report.Nodes, fit)
+	}
+	return report
+}
+
+// fitCount is how many pods of the shape fit in the node's free requests
+func fitCount(node Node, shape Shape) (int64, string) {
+	count := int64(-1)
+	var short []string
+	check := func(name string, free, want int64, format func(int64) string) {
+		if want == 0 {
+			return
+		}
+		free = max(free, 0)
+		if free < want {
+			short = append(short, fmt.Sprintf("%s: %s free, %s needed", name, format(free), format(want)))
+		}
+		n := free / want
+		if count < 0 || n < count {
+			count = n
+		}
+	}
+	check("cpu", node.CPU.Free(), shape.CPU, func(v int64) string {
+		return resource.NewMilliQuantity(v, resource.DecimalSI).String()
+	})
+	check("mem", node.Memory.Free(), shape.Memory, func(v int64) string {
+		return resource.NewQuantity(v, resource.BinarySI).String()
+	})
+	if count < 0 {
+		count = 0
+	}
+	return count, strings.Join(short, "; ")
+}
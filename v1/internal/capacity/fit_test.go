@@ -0,0 +1,78 @@
+package capacity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShape(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Shape
+		wantErr string
+	}{
+		{name: "cpu and memory", value: "cpu=2,mem=4Gi", want: Shape{CPU: 2000, Memory: 4 << 30}},
+		{name: "millicores and memory alias", value: "cpu=500m, memory=512Mi", want: Shape{CPU: 500, Memory: 512 << 20}},
+		{name: "cpu only", value: "cpu=1", want: Shape{CPU: 1000}},
+		{name: "missing quantity", value: "cpu", wantErr: "expected key=quantity"},
+		{name: "bad quantity", value: "mem=lots", wantErr: "invalid quantity for mem"},
+		{name: "unknown resource", value: "gpu=1", wantErr: "unknown resource"},
+		{name: "negative", value: "cpu=-1", wantErr: "must not be negative"},
+		{name: "empty", value: "", wantErr: "must request cpu or mem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shape, err := ParseShape(tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, shape)
+		})
+	}
+}
+
+func TestShapeString(t *testing.T) {
+	assert.Equal(t, "cpu=2,mem=4Gi", Shape{CPU: 2000, Memory: 4 << 30}.String())
+	assert.Equal(t, "cpu=500m", Shape{CPU: 500}.String())
+}
+
+func TestFit(t *testing.T) {
+	nodes := []Node{
+		{Name: "big", Ready: true, Schedulable: true,
+			CPU:    Resources{Allocatable: 8000, Requested: 1000},
+			Memory: Resources{Allocatable: 16 << 30, Requested: 2 << 30}},
+		{Name: "tight", Ready: true, Schedulable: true,
+			CPU:    Resources{Allocatable: 2000, Requested: 500},
+			Memory: Resources{Allocatable: 4 << 30, Requested: 3 << 30}},
+		{Name: "overcommitted", Ready: true, Schedulable: true,
+			CPU:    Resources{Allocatable: 1000, Requested: 1500},
+			Memory: Resources{Allocatable: 4 << 30}},
+		{Name: "cordoned", Ready: true, CPU: Resources{Allocatable: 8000}, Memory: Resources{Allocatable: 16 << 30}},
+		{Name: "down", Schedulable: true, CPU: Resources{Allocatable: 8000}, Memory: Resources{Allocatable: 16 << 30}},
+	}
+
+	report := Fit(nodes, Shape{CPU: 2000, Memory: 4 << 30})
+	require.Len(t, report.Nodes, 5)
+
+	assert.True(t, report.Nodes[0].Fits)
+	assert.Equal(t, int64(3), report.Nodes[0].Count)
+	assert.Empty(t, report.Nodes[0].Reason)
+
+	assert.False(t, report.Nodes[1].Fits)
+	assert.Equal(t, "cpu: 1500m free, 2 needed; mem: 1Gi free, 4Gi needed", report.Nodes[1].Reason)
+
+	assert.False(t, report.Nodes[2].Fits)
+	assert.Equal(t, "cpu: 0 free, 2 needed", report.Nodes[2].Reason)
+
+	assert.Equal(t, "node is cordoned", report.Nodes[3].Reason)
+	assert.Equal(t, "node is not ready", report.Nodes[4].Reason)
+
+	// A shape without memory is limited by CPU alone
+	cpuOnly := Fit(nodes[:1], Shape{CPU: 3000})
+	assert.Equal(t, int64(2), cpuOnly.Nodes[0].Count)
+}
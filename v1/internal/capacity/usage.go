@@ -0,0 +1,141 @@
+package capacity
+
+import (
+	"context"
+	"net"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
+)
+
+// Usage queries. Pod usage comes from cAdvisor and is attributed to nodes
+// through the pods' placement, so no node label is needed on the series.
+const (
+	podCPUQuery    = `sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{container!="",image!=""}[5m]))`
+	podMemoryQuery = `sum by (namespace, pod) (container_memory_working_set_bytes{container!="",image!=""})`
+
+	// kubelet reports usage for CSI volumes such as Longhorn's; Longhorn's
+	// own metric covers volumes kubelet has no stats for
+	volumeUsedQuery   = `max by (namespace, persistentvolumeclaim) (kubelet_volume_stats_used_bytes)`
+	longhornUsedQuery = `max by (pvc_namespace, pvc) (longhorn_volume_actual_size_bytes{pvc!=""})`
+
+	// local-path volumes are directories on the node's root filesystem
+	filesystemSizeQuery  = `max by (instance) (node_filesystem_size_bytes{mountpoint="/",fstype!~"tmpfs|overlay|squashfs"})`
+	filesystemAvailQuery = `max by (instance) (node_filesystem_avail_bytes{mountpoint="/",fstype!~"tmpfs|overlay|squashfs"})`
+	longhornNodeUsed     = `longhorn_node_storage_usage_bytes`
+	longhornNodeSize     = `longhorn_node_storage_capacity_bytes`
+)
+
+// Usage is what Prometheus reports the cluster actually uses
+type Usage struct {
+	// PodCPU is each pod's CPU usage in millicores, keyed by namespace/pod
+	PodCPU map[string]int64
+	// PodMemory is each pod's working set in bytes, keyed by namespace/pod
+	PodMemory map[string]int64
+	// VolumeUsed is the bytes used on each volume, keyed by namespace/claim
+	VolumeUsed map[string]int64
+	// NodeDisk is each node's storage, keyed by node name or address
+	NodeDisk map[string]Disk
+}
+
+// QueryUsage reads current usage from the stack's Prometheus. It returns
+// nil, without an error, when Prometheus is not installed.
+func QueryUsage(ctx context.Context, kube kubernetes.Interface) (*Usage, error) {
+	if installed, err := discovery.PrometheusInstalled(ctx, kube); err != nil || !installed {
+		return nil, err
+	}
+
+	results := map[string][]discovery.PrometheusSample{}
+	for _, query := range []string{
+		podCPUQuery, podMemoryQuery,
+		volumeUsedQuery, longhornUsedQuery,
+		filesystemSizeQuery, filesystemAvailQuery,
+		longhornNodeUsed, longhornNodeSize,
+	} {
+		samples, err := discovery.QueryPrometheus(ctx, kube, query)
+		if err != nil {
+			return nil, err
+		}
+		results[query] = samples
+	}
+
+	usage := &Usage{
+		PodCPU:     map[string]int64{},
+		PodMemory:  map[string]int64{},
+		VolumeUsed: map[string]int64{},
+		NodeDisk:   map[string]Disk{},
+	}
+	for _, sample := range results[podCPUQuery] {
+		if value, ok := sample.Float(); ok {
+			usage.PodCPU[sample.Metric["namespace"]+"/"+sample.Metric["pod"]] = int64(value * 1000)
+		}
+	}
+	for _, sample := range results[podMemoryQuery] {
+		if value, ok := sample.Float(); ok {
+			usage.PodMemory[sample.Metric["namespace"]+"/"+sample.Metric["pod"]] = int64(value)
+		}
+	}
+	for _, sample := range results[longhornUsedQuery] {
+		if value, ok := sample.Float(); ok {
+			usage.VolumeUsed[sample.Metric["pvc_namespace"]+"/"+sample.Metric["pvc"]] = int64(value)
+		}
+	}
+	// kubelet's figure is the filesystem's view and wins over Longhorn's
+	// block-level size
+	for _, sample := range results[volumeUsedQuery] {
+		if value, ok := sample.Float(); ok {
+			usage.VolumeUsed[sample.Metric["namespace"]+"/"+sample.Metric["persistentvolumeclaim"]] = int64(value)
+		}
+	}
+
+	avail := map[string]float64{}
+	for _, sample := range results[filesystemAvailQuery] {
+		if value, ok := sample.Float(); ok {
+			avail[sample.Metric["instance"]] = value
+		}
+	}
+	for _, sample := range results[filesystemSizeQuery] {
+		size, ok := sample.Float()
+		free, hasFree := avail[sample.Metric["instance"]]
+		if !ok || !hasFree {
+			continue
+		}
+		usage.NodeDisk[instanceHost(sample.Metric["instance"])] = Disk{
+			Source:    "filesystem",
+			UsedBytes: int64(size - free),
+			SizeBytes: int64(size),
+		}
+	}
+
+	// Longhorn's view of a node replaces the root filesystem: it is where
+	// Longhorn volumes take their space from
+	longhornUsed := map[string]float64{}
+	for _, sample := range results[longhornNodeUsed] {
+		if value, ok := sample.Float(); ok {
+			longhornUsed[sample.Metric["node"]] = value
+		}
+	}
+	for _, sample := range results[longhornNodeSize] {
+		size, ok := sample.Float()
+		used, hasUsed := longhornUsed[sample.Metric["node"]]
+		if !ok || !hasUsed {
+			continue
+		}
+		usage.NodeDisk[sample.Metric["node"]] = Disk{
+			Source:    "longhorn",
+			UsedBytes: int64(used),
+			SizeBytes: int64(size),
+		}
+	}
+
+	return usage, nil
+}
+
+// instanceHost strips the port from a scrape target's instance label
+func instanceHost(instance string) string {
+	if host, _, err := net.SplitHostPort(instance); err == nil {
+		return host
+	}
+	return instance
+}
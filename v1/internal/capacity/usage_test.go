@@ -0,0 +1,79 @@
+package capacity
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestQueryUsageWithoutPrometheus(t *testing.T) {
+	usage, err := QueryUsage(context.Background(), kubernetesfake.NewSimpleClientset())
+	require.NoError(t, err)
+	assert.Nil(t, usage)
+}
+
+func TestQueryUsage(t *testing.T) {
+	responses := map[string]string{
+		podCPUQuery:     `[{"metric":{"namespace":"apps","pod":"web-1"},"value":[1700000000,"0.25"]}]`,
+		podMemoryQuery:  `[{"metric":{"namespace":"apps","pod":"web-1"},"value":[1700000000,"1073741824"]}]`,
+		volumeUsedQuery: `[{"metric":{"namespace":"apps","persistentvolumeclaim":"data"},"value":[1700000000,"2048"]}]`,
+		longhornUsedQuery: `[
+			{"metric":{"pvc_namespace":"apps","pvc":"data"},"value":[1700000000,"4096"]},
+			{"metric":{"pvc_namespace":"apps","pvc":"cache"},"value":[1700000000,"1024"]}
+		]`,
+		filesystemSizeQuery:  `[{"metric":{"instance":"10.0.0.1:9100"},"value":[1700000000,"1000"]},{"metric":{"instance":"node-b:9100"},"value":[1700000000,"1000"]}]`,
+		filesystemAvailQuery: `[{"metric":{"instance":"10.0.0.1:9100"},"value":[1700000000,"400"]},{"metric":{"instance":"node-b:9100"},"value":[1700000000,"900"]}]`,
+		longhornNodeUsed:     `[{"metric":{"node":"node-b"},"value":[1700000000,"50"]}]`,
+		longhornNodeSize:     `[{"metric":{"node":"node-b"},"value":[1700000000,"500"]}]`,
+	}
+	kube := kubernetesfake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "kube-prometheus-stack-prometheus", Namespace: "monitoring"}})
+	kube.PrependProxyReactor("services", func(action k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+		query := action.(k8stesting.ProxyGetAction).GetParams()["query"]
+		result, ok := responses[query]
+		if !ok {
+			result = "[]"
+		}
+		return true, staticResponse(`{"status":"success","data":{"result":` + result + `}}`), nil
+	})
+
+	usage, err := QueryUsage(context.Background(), kube)
+	require.NoError(t, err)
+	require.NotNil(t, usage)
+
+	assert.Equal(t, int64(250), usage.PodCPU["apps/web-1"])
+	assert.Equal(t, int64(1<<30), usage.PodMemory["apps/web-1"])
+	// kubelet's figure wins; Longhorn fills in the volumes kubelet misses
+	assert.Equal(t, int64(2048), usage.VolumeUsed["apps/data"])
+	assert.Equal(t, int64(1024), usage.VolumeUsed["apps/cache"])
+
+	assert.Equal(t, Disk{Source: "filesystem", UsedBytes: 600, SizeBytes: 1000}, usage.NodeDisk["10.0.0.1"])
+	assert.Equal(t, Disk{Source: "longhorn", UsedBytes: 50, SizeBytes: 500}, usage.NodeDisk["node-b"])
+}
+
+func TestQueryUsageReportsErrors(t *testing.T) {
+	kube := kubernetesfake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "kube-prometheus-stack-prometheus", Namespace: "monitoring"}})
+	kube.PrependProxyReactor("services", func(k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+		return true, staticResponse(`{"status":"error","error":"query timed out"}`), nil
+	})
+
+	_, err := QueryUsage(context.Background(), kube)
+	assert.ErrorContains(t, err, "query timed out")
+}
+
+// staticResponse is a proxied response with a fixed body
+type staticResponse string
+
+func (r staticResponse) DoRaw(context.Context) ([]byte, error) { return []byte(r), nil }
+
+func (r staticResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(r))), nil
+}
@@ -0,0 +1,239 @@
+package alloy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// pipelineTemplate is the Grafana Alloy configuration template. One
+// template covers both releases: the node collector tails pod logs and
+// receives OTLP, the single-replica release watches events.
+const pipelineTemplate = `// Grafana Alloy cluster collector
+// Generated by Foundry
+{{- if .PodLogs}}
+
+// Pods on this node; every Alloy pod tails its own node's containers
+discovery.kubernetes "pods" {
+  role = "pod"
+
+  selectors {
+    role  = "pod"
+    field = "spec.nodeName=" + sys.env("NODE_NAME")
+  }
+}
+
+// The same labels Promtail set, so queries and dashboards keep working
+discovery.relabel "pods" {
+  targets = discovery.kubernetes.pods.targets
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_label_app_kubernetes_io_name", "__meta_kubernetes_pod_label_app", "__meta_kubernetes_pod_name"]
+    separator     = ";"
+    regex         = "^;*([^;]+)(;.*)?$"
+    target_label  = "app"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_label_app_kubernetes_io_instance", "__meta_kubernetes_pod_label_release"]
+    separator     = ";"
+    regex         = "^;*([^;]+)(;.*)?$"
+    target_label  = "instance"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_label_app_kubernetes_io_component", "__meta_kubernetes_pod_label_component"]
+    separator     = ";"
+    regex         = "^;*([^;]+)(;.*)?$"
+    target_label  = "component"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_node_name"]
+    target_label  = "node_name"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_namespace"]
+    target_label  = "namespace"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_name"]
+    target_label  = "pod"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_container_name"]
+    target_label  = "container"
+  }
+
+  rule {
+    source_labels = ["namespace", "app"]
+    separator     = "/"
+    target_label  = "job"
+  }
+
+  rule {
+    source_labels = ["__meta_kubernetes_pod_uid", "__meta_kubernetes_pod_container_name"]
+    separator     = "/"
+    target_label  = "__path__"
+    replacement   = "/var/log/pods/*$1/*.log"
+  }
+}
+
+local.file_match "pods" {
+  path_targets = discovery.relabel.pods.output
+}
+
+loki.source.file "pods" {
+  targets    = local.file_match.pods.targets
+  forward_to = [loki.process.pods.receiver]
+}
+
+// Strip the container runtime's timestamp and stream prefix
+loki.process "pods" {
+  stage.cri {}
+
+  forward_to = [loki.write.default.receiver]
+}
+{{- end}}
+{{- if .Events}}
+
+loki.source.kubernetes_events "events" {
+  job_name   = "integrations/kubernetes/eventhandler"
+  log_format = "logfmt"
+  forward_to = [loki.write.default.receiver]
+}
+{{- end}}
+{{- if .OTLP}}
+
+otelcol.receiver.otlp "default" {
+  grpc {
+    endpoint = "0.0.0.0:{{.GRPCPort}}"
+  }
+
+  http {
+    endpoint = "0.0.0.0:{{.HTTPPort}}"
+  }
+
+  output {
+{{- if .RemoteWriteURL}}
+    metrics = [otelcol.processor.batch.default.input]
+{{- end}}
+{{- if .LokiURL}}
+    logs    = [otelcol.processor.batch.default.input]
+{{- end}}
+{{- if .TempoEndpoint}}
+    traces  = [otelcol.processor.batch.default.input]
+{{- end}}
+  }
+}
+
+otelcol.processor.batch "default" {
+  output {
+{{- if .RemoteWriteURL}}
+    metrics = [otelcol.exporter.prometheus.default.input]
+{{- end}}
+{{- if .LokiURL}}
+    logs    = [otelcol.exporter.loki.default.input]
+{{- end}}
+{{- if .TempoEndpoint}}
+    traces  = [otelcol.exporter.otlp.tempo.input]
+{{- end}}
+  }
+}
+{{- if .RemoteWriteURL}}
+
+otelcol.exporter.prometheus "default" {
+  forward_to = [prometheus.remote_write.default.receiver]
+}
+
+prometheus.remote_write "default" {
+  endpoint {
+    url = {{quote .RemoteWriteURL}}
+  }
+}
+{{- end}}
+{{- if .LokiURL}}
+
+otelcol.exporter.loki "default" {
+  forward_to = [loki.write.default.receiver]
+}
+{{- end}}
+{{- if .TempoEndpoint}}
+
+otelcol.exporter.otlp "tempo" {
+  client {
+    endpoint = {{quote .TempoEndpoint}}
+
+    tls {
+      insecure = true
+    }
+  }
+}
+{{- end}}
+{{- end}}
+{{- if .WriteLoki}}
+
+loki.write "default" {
+  endpoint {
+    url = {{quote .LokiURL}}
+  }
+}
+{{- end}}
+`
+
+// pipeline selects the parts of the template one release runs
+type pipeline struct {
+	PodLogs bool
+	Events  bool
+	OTLP    bool
+}
+
+// GenerateConfig generates the Alloy configuration for the node collector:
+// pod logs and the OTLP receivers
+func GenerateConfig(cfg *Config) (string, error) {
+	return generate(cfg, pipeline{PodLogs: cfg.PodLogsEnabled, OTLP: cfg.OTLPEnabled})
+}
+
+// GenerateEventsConfig generates the Alloy configuration for the
+// single-replica events collector
+func GenerateEventsConfig(cfg *Config) (string, error) {
+	return generate(cfg, pipeline{Events: true})
+}
+
+func generate(cfg *Config, p pipeline) (string, error) {
+	tmpl, err := template.New("alloy").Funcs(template.FuncMap{
+		"quote": strconv.Quote,
+	}).Parse(pipelineTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := struct {
+		pipeline
+		LokiURL        string
+		RemoteWriteURL string
+		TempoEndpoint  string
+		GRPCPort       int
+		HTTPPort       int
+		WriteLoki      bool
+	}{
+		pipeline:       p,
+		LokiURL:        cfg.LokiURL,
+		RemoteWriteURL: cfg.PrometheusRemoteWriteURL,
+		TempoEndpoint:  cfg.TempoEndpoint,
+		GRPCPort:       OTLPGRPCPort,
+		HTTPPort:       OTLPHTTPPort,
+		WriteLoki:      cfg.LokiURL != "" && (p.PodLogs || p.Events || p.OTLP),
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
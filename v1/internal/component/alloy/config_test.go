@@ -0,0 +1,74 @@
+package alloy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfig_PodLogs(t *testing.T) {
+	content, err := GenerateConfig(DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Contains(t, content, `field = "spec.nodeName=" + sys.env("NODE_NAME")`)
+	assert.Contains(t, content, `replacement   = "/var/log/pods/*$1/*.log"`)
+	assert.Contains(t, content, `target_label  = "app"`)
+	assert.Contains(t, content, `target_label  = "job"`)
+	assert.Contains(t, content, "stage.cri {}")
+	assert.Contains(t, content, `url = "http://loki-gateway.monitoring.svc.cluster.local:80/loki/api/v1/push"`)
+
+	// Events come from the single-replica release, OTLP is off by default
+	assert.NotContains(t, content, "kubernetes_events")
+	assert.NotContains(t, content, "otelcol")
+}
+
+func TestGenerateConfig_OTLP(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.OTLPEnabled = true
+	cfg.PrometheusRemoteWriteURL = "http://prometheus:9090/api/v1/write"
+	cfg.TempoEndpoint = "tempo.monitoring.svc.cluster.local:4317"
+
+	content, err := GenerateConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Contains(t, content, `endpoint = "0.0.0.0:4317"`)
+	assert.Contains(t, content, `endpoint = "0.0.0.0:4318"`)
+	assert.Contains(t, content, "metrics = [otelcol.exporter.prometheus.default.input]")
+	assert.Contains(t, content, "logs    = [otelcol.exporter.loki.default.input]")
+	assert.Contains(t, content, "traces  = [otelcol.exporter.otlp.tempo.input]")
+	assert.Contains(t, content, `url = "http://prometheus:9090/api/v1/write"`)
+	assert.Contains(t, content, `endpoint = "tempo.monitoring.svc.cluster.local:4317"`)
+}
+
+func TestGenerateConfig_OTLPTracesOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PodLogsEnabled = false
+	cfg.OTLPEnabled = true
+	cfg.LokiURL = ""
+	cfg.TempoEndpoint = "tempo:4317"
+
+	content, err := GenerateConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Contains(t, content, "traces  = [otelcol.processor.batch.default.input]")
+	assert.NotContains(t, content, "metrics =")
+	assert.NotContains(t, content, "logs    =")
+	assert.NotContains(t, content, "loki.write")
+	assert.NotContains(t, content, "prometheus.remote_write")
+	assert.NotContains(t, content, "discovery.kubernetes")
+}
+
+func TestGenerateEventsConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.OTLPEnabled = true
+	cfg.TempoEndpoint = "tempo:4317"
+
+	content, err := GenerateEventsConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Contains(t, content, `loki.source.kubernetes_events "events"`)
+	assert.Contains(t, content, `loki.write "default"`)
+	assert.NotContains(t, content, "discovery.kubernetes")
+	assert.NotContains(t, content, "otelcol", "only the node collector receives OTLP")
+}
@@ -0,0 +1,305 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+const (
+	alloyRepoName = "grafana"
+	alloyRepoURL  = "https://grafana.github.io/helm-charts"
+	alloyChart    = "grafana/alloy"
+
+	// releaseName is the node collector, a DaemonSet
+	releaseName = "alloy"
+
+	// eventsReleaseName is the events collector, a single-replica Deployment
+	// so each event is shipped once
+	eventsReleaseName = "alloy-events"
+
+	// promtailReleaseName is the release Alloy replaces
+	promtailReleaseName = "promtail"
+)
+
+// Install installs the Alloy collectors using Helm and, once the node
+// collector is healthy, removes Promtail
+func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cfg *Config) error {
+	if helmClient == nil {
+		return fmt.Errorf("helm client cannot be nil")
+	}
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.PromtailNamespace == "" {
+		cfg.PromtailNamespace = cfg.Namespace
+	}
+
+	fmt.Println("  Installing Alloy...")
+
+	// Add Helm repository
+	if err := helmClient.AddRepo(ctx, helm.RepoAddOptions{
+		Name:        alloyRepoName,
+		URL:         alloyRepoURL,
+		ForceUpdate: true,
+	}); err != nil {
+		return fmt.Errorf("failed to add helm repository: %w", err)
+	}
+
+	serviceMonitor := serviceMonitorCRDAvailable(ctx, k8sClient)
+	releases, _ := helmClient.List(ctx, cfg.Namespace)
+
+	if cfg.PodLogsEnabled || cfg.OTLPEnabled {
+		values, err := buildHelmValues(cfg)
+		if err != nil {
+			return err
+		}
+		values["serviceMonitor"].(map[string]interface{})["enabled"] = serviceMonitor
+		// Wait for every node's collector to be ready: that is the signal
+		// that Promtail can go
+		if err := deploy(ctx, helmClient, releases, cfg.Namespace, releaseName, cfg.Version, values); err != nil {
+			return err
+		}
+	} else if err := remove(ctx, helmClient, releases, cfg.Namespace, releaseName); err != nil {
+		return err
+	}
+
+	if cfg.EventsEnabled {
+		values, err := buildEventsHelmValues(cfg)
+		if err != nil {
+			return err
+		}
+		values["serviceMonitor"].(map[string]interface{})["enabled"] = serviceMonitor
+		if err := deploy(ctx, helmClient, releases, cfg.Namespace, eventsReleaseName, cfg.Version, values); err != nil {
+			return err
+		}
+	} else if err := remove(ctx, helmClient, releases, cfg.Namespace, eventsReleaseName); err != nil {
+		return err
+	}
+
+	fmt.Println("  Alloy installed successfully")
+	if cfg.OTLPEnabled {
+		fmt.Printf("  OTLP gRPC endpoint: %s\n", cfg.GetOTLPGRPCEndpoint())
+		fmt.Printf("  OTLP HTTP endpoint: %s\n", cfg.GetOTLPHTTPEndpoint())
+	}
+
+	// Only drop Promtail once Alloy ships pod logs in its place
+	if cfg.PodLogsEnabled && cfg.RemovePromtail {
+		return removePromtail(ctx, helmClient, cfg.PromtailNamespace)
+	}
+
+	return nil
+}
+
+// deploy upgrades a release in place or installs it, waiting for its pods
+func deploy(ctx context.Context, helmClient HelmClient, releases []helm.Release, namespace, name, version string, values map[string]interface{}) error {
+	for _, rel := range releases {
+		if rel.Name != name {
+			continue
+		}
+		fmt.Printf("  Upgrading %s (current status: %s)...\n", name, rel.Status)
+		if err := helmClient.Upgrade(ctx, helm.UpgradeOptions{
+			ReleaseName: name,
+			Namespace:   namespace,
+			Chart:       alloyChart,
+			Version:     version,
+			Values:      values,
+			Wait:        true,
+			Timeout:     5 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to upgrade %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := helmClient.Install(ctx, helm.InstallOptions{
+		ReleaseName:     name,
+		Namespace:       namespace,
+		Chart:           alloyChart,
+		Version:         version,
+		Values:          values,
+		CreateNamespace: true,
+		Wait:            true,
+		Timeout:         5 * time.Minute,
+	}); err != nil {
+		return fmt.Errorf("failed to install %s: %w", name, err)
+	}
+	return nil
+}
+
+// remove uninstalls a release that a previous config enabled
+func remove(ctx context.Context, helmClient HelmClient, releases []helm.Release, namespace, name string) error {
+	for _, rel := range releases {
+		if rel.Name == name {
+			fmt.Printf("  Removing %s (disabled in config)...\n", name)
+			if err := helmClient.Uninstall(ctx, helm.UninstallOptions{
+				ReleaseName: name,
+				Namespace:   namespace,
+			}); err != nil {
+				return fmt.Errorf("failed to uninstall %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// removePromtail uninstalls the Promtail release left by an older Loki
+// install
+func removePromtail(ctx context.Context, helmClient HelmClient, namespace string) error {
+	releases, err := helmClient.List(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to list releases in %s: %w", namespace, err)
+	}
+	for _, rel := range releases {
+		if rel.Name != promtailReleaseName {
+			continue
+		}
+		fmt.Println("  Alloy is collecting pod logs; removing Promtail...")
+		if err := helmClient.Uninstall(ctx, helm.UninstallOptions{
+			ReleaseName: promtailReleaseName,
+			Namespace:   namespace,
+		}); err != nil {
+			return fmt.Errorf("failed to uninstall promtail: %w", err)
+		}
+		fmt.Println("  Promtail removed")
+	}
+	return nil
+}
+
+func serviceMonitorCRDAvailable(ctx context.Context, k8sClient K8sClient) bool {
+	if k8sClient == nil {
+		return false
+	}
+	exists, err := k8sClient.ServiceMonitorCRDExists(ctx)
+	if err != nil {
+		fmt.Printf("  Warning: could not check for the ServiceMonitor CRD: %v\n", err)
+		return false
+	}
+	return exists
+}
+
+// Charts returns the Helm charts Install uses for the Alloy collectors
+func Charts(cfg *Config) ([]helm.ChartSource, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	var charts []helm.ChartSource
+	if cfg.PodLogsEnabled || cfg.OTLPEnabled {
+		values, err := buildHelmValues(cfg)
+		if err != nil {
+			return nil, err
+		}
+		charts = append(charts, helm.ChartSource{
+			RepoName: alloyRepoName,
+			RepoURL:  alloyRepoURL,
+			Chart:    alloyChart,
+			Version:  cfg.Version,
+			Values:   values,
+		})
+	}
+	if cfg.EventsEnabled {
+		values, err := buildEventsHelmValues(cfg)
+		if err != nil {
+			return nil, err
+		}
+		charts = append(charts, helm.ChartSource{
+			RepoName: alloyRepoName,
+			RepoURL:  alloyRepoURL,
+			Chart:    alloyChart,
+			Version:  cfg.Version,
+			Values:   values,
+		})
+	}
+	return charts, nil
+}
+
+// buildHelmValues constructs Helm values for the node collector DaemonSet
+func buildHelmValues(cfg *Config) (map[string]interface{}, error) {
+	content, err := GenerateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+
+	// Start with user-provided values
+	for k, v := range cfg.Values {
+		values[k] = v
+	}
+
+	alloy := map[string]interface{}{
+		"configMap": map[string]interface{}{
+			"create":  true,
+			"content": content,
+		},
+		// The pod discovery selects this node's pods by name
+		"extraEnv": []map[string]interface{}{
+			{
+				"name": "NODE_NAME",
+				"valueFrom": map[string]interface{}{
+					"fieldRef": map[string]interface{}{
+						"fieldPath": "spec.nodeName",
+					},
+				},
+			},
+		},
+		"mounts": map[string]interface{}{
+			"varlog": cfg.PodLogsEnabled,
+		},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"cpu":    "50m",
+				"memory": "128Mi",
+			},
+		},
+	}
+	if cfg.OTLPEnabled {
+		alloy["extraPorts"] = []map[string]interface{}{
+			{"name": "otlp-grpc", "port": OTLPGRPCPort, "targetPort": OTLPGRPCPort, "protocol": "TCP"},
+			{"name": "otlp-http", "port": OTLPHTTPPort, "targetPort": OTLPHTTPPort, "protocol": "TCP"},
+		}
+	}
+	values["alloy"] = alloy
+
+	values["controller"] = map[string]interface{}{
+		"type": "daemonset",
+	}
+
+	values["serviceMonitor"] = map[string]interface{}{
+		"enabled": true,
+	}
+
+	return values, nil
+}
+
+// buildEventsHelmValues constructs Helm values for the events collector
+func buildEventsHelmValues(cfg *Config) (map[string]interface{}, error) {
+	content, err := GenerateEventsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"alloy": map[string]interface{}{
+			"configMap": map[string]interface{}{
+				"create":  true,
+				"content": content,
+			},
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{
+					"cpu":    "10m",
+					"memory": "64Mi",
+				},
+			},
+		},
+		"controller": map[string]interface{}{
+			"type":     "deployment",
+			"replicas": 1,
+		},
+		"serviceMonitor": map[string]interface{}{
+			"enabled": true,
+		},
+	}, nil
+}
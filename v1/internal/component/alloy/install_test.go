@@ -0,0 +1,130 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+func TestInstall_Success(t *testing.T) {
+	helmClient := &mockHelmClient{}
+
+	err := Install(context.Background(), helmClient, &mockK8sClient{serviceMonitorCRDExists: true}, DefaultConfig())
+	require.NoError(t, err)
+
+	require.Len(t, helmClient.chartsInstalled, 2)
+	node := helmClient.chartsInstalled[0]
+	assert.Equal(t, releaseName, node.ReleaseName)
+	assert.Equal(t, alloyChart, node.Chart)
+	assert.Equal(t, "monitoring", node.Namespace)
+	assert.True(t, node.Wait, "the node collector must be ready before Promtail goes")
+	assert.Equal(t, "daemonset", node.Values["controller"].(map[string]interface{})["type"])
+	assert.Equal(t, true, node.Values["serviceMonitor"].(map[string]interface{})["enabled"])
+
+	events := helmClient.chartsInstalled[1]
+	assert.Equal(t, eventsReleaseName, events.ReleaseName)
+	controller := events.Values["controller"].(map[string]interface{})
+	assert.Equal(t, "deployment", controller["type"])
+	assert.Equal(t, 1, controller["replicas"])
+
+	assert.Empty(t, helmClient.uninstallCalls, "no Promtail to remove")
+}
+
+func TestInstall_RemovesPromtail(t *testing.T) {
+	helmClient := &mockHelmClient{
+		listReleases: []helm.Release{
+			{Name: "loki", Namespace: "monitoring", Status: "deployed"},
+			{Name: promtailReleaseName, Namespace: "monitoring", Status: "deployed"},
+		},
+	}
+
+	require.NoError(t, Install(context.Background(), helmClient, nil, DefaultConfig()))
+	require.Len(t, helmClient.uninstallCalls, 1)
+	assert.Equal(t, promtailReleaseName, helmClient.uninstallCalls[0].ReleaseName)
+	assert.Equal(t, "monitoring", helmClient.uninstallCalls[0].Namespace)
+}
+
+func TestInstall_KeepsPromtailWhenAlloyFails(t *testing.T) {
+	helmClient := &mockHelmClient{
+		installErr:   errors.New("timed out waiting for the condition"),
+		listReleases: []helm.Release{{Name: promtailReleaseName, Namespace: "monitoring", Status: "deployed"}},
+	}
+
+	err := Install(context.Background(), helmClient, nil, DefaultConfig())
+	assert.ErrorContains(t, err, "failed to install alloy")
+	assert.Empty(t, helmClient.uninstallCalls)
+}
+
+func TestInstall_KeepsPromtailWhenDisabled(t *testing.T) {
+	helmClient := &mockHelmClient{
+		listReleases: []helm.Release{{Name: promtailReleaseName, Namespace: "monitoring", Status: "deployed"}},
+	}
+	cfg := DefaultConfig()
+	cfg.RemovePromtail = false
+
+	require.NoError(t, Install(context.Background(), helmClient, nil, cfg))
+	assert.Empty(t, helmClient.uninstallCalls)
+}
+
+func TestInstall_UpgradesAndRemovesDisabledRelease(t *testing.T) {
+	helmClient := &mockHelmClient{
+		listReleases: []helm.Release{
+			{Name: releaseName, Namespace: "monitoring", Status: "deployed"},
+			{Name: eventsReleaseName, Namespace: "monitoring", Status: "deployed"},
+		},
+	}
+	cfg := DefaultConfig()
+	cfg.EventsEnabled = false
+
+	require.NoError(t, Install(context.Background(), helmClient, nil, cfg))
+	assert.Empty(t, helmClient.chartsInstalled)
+	require.Len(t, helmClient.upgradeCalls, 1)
+	assert.Equal(t, releaseName, helmClient.upgradeCalls[0].ReleaseName)
+	require.Len(t, helmClient.uninstallCalls, 1)
+	assert.Equal(t, eventsReleaseName, helmClient.uninstallCalls[0].ReleaseName)
+}
+
+func TestInstall_NilHelmClient(t *testing.T) {
+	err := Install(context.Background(), nil, nil, DefaultConfig())
+	assert.ErrorContains(t, err, "helm client cannot be nil")
+}
+
+func TestBuildHelmValues(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Values = map[string]interface{}{"image": map[string]interface{}{"tag": "v1.5.1"}}
+
+	values, err := buildHelmValues(cfg)
+	require.NoError(t, err)
+
+	alloy := values["alloy"].(map[string]interface{})
+	assert.Equal(t, true, alloy["mounts"].(map[string]interface{})["varlog"])
+	assert.Contains(t, alloy["configMap"].(map[string]interface{})["content"], `loki.source.file "pods"`)
+	assert.NotContains(t, alloy, "extraPorts")
+	assert.NotNil(t, values["image"])
+
+	cfg.OTLPEnabled = true
+	values, err = buildHelmValues(cfg)
+	require.NoError(t, err)
+	ports := values["alloy"].(map[string]interface{})["extraPorts"].([]map[string]interface{})
+	require.Len(t, ports, 2)
+	assert.Equal(t, OTLPGRPCPort, ports[0]["port"])
+	assert.Equal(t, OTLPHTTPPort, ports[1]["port"])
+}
+
+func TestCharts(t *testing.T) {
+	charts, err := Charts(nil)
+	require.NoError(t, err)
+	assert.Len(t, charts, 2)
+
+	cfg := DefaultConfig()
+	cfg.EventsEnabled = false
+	charts, err = Charts(cfg)
+	require.NoError(t, err)
+	require.Len(t, charts, 1)
+	assert.Equal(t, alloyChart, charts[0].Chart)
+}
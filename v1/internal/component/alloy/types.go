@@ -0,0 +1,258 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+const (
+	// OTLPGRPCPort is the OTLP gRPC receiver port on the alloy Service
+	OTLPGRPCPort = 4317
+
+	// OTLPHTTPPort is the OTLP HTTP receiver port on the alloy Service
+	OTLPHTTPPort = 4318
+)
+
+// Config holds Alloy collector configuration
+type Config struct {
+	// Version is the Helm chart version to install
+	Version string `json:"version" yaml:"version"`
+
+	// Namespace for the Alloy releases
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// LokiURL is the Loki push URL that logs and events are sent to
+	LokiURL string `json:"loki_url" yaml:"loki_url"`
+
+	// PrometheusRemoteWriteURL receives OTLP metrics (empty to drop them)
+	PrometheusRemoteWriteURL string `json:"prometheus_remote_write_url" yaml:"prometheus_remote_write_url"`
+
+	// TempoEndpoint is Tempo's OTLP gRPC host:port for OTLP traces (empty to
+	// drop them)
+	TempoEndpoint string `json:"tempo_endpoint" yaml:"tempo_endpoint"`
+
+	// PodLogsEnabled collects container logs on every node
+	PodLogsEnabled bool `json:"pod_logs_enabled" yaml:"pod_logs_enabled"`
+
+	// EventsEnabled collects Kubernetes events, from a single-replica release
+	EventsEnabled bool `json:"events_enabled" yaml:"events_enabled"`
+
+	// OTLPEnabled opens OTLP receivers on the alloy Service and routes logs,
+	// metrics and traces to Loki, Prometheus and Tempo
+	OTLPEnabled bool `json:"otlp_enabled" yaml:"otlp_enabled"`
+
+	// RemovePromtail uninstalls the Promtail release once Alloy is healthy
+	RemovePromtail bool `json:"remove_promtail" yaml:"remove_promtail"`
+
+	// PromtailNamespace is where the Promtail release is looked for
+	// (defaults to Namespace)
+	PromtailNamespace string `json:"promtail_namespace" yaml:"promtail_namespace"`
+
+	// Values allows passing additional Helm values to the node collector
+	Values map[string]interface{} `json:"values" yaml:",inline"`
+}
+
+// HelmClient defines the Helm operations needed for Alloy component
+type HelmClient interface {
+	AddRepo(ctx context.Context, opts helm.RepoAddOptions) error
+	Install(ctx context.Context, opts helm.InstallOptions) error
+	Upgrade(ctx context.Context, opts helm.UpgradeOptions) error
+	Uninstall(ctx context.Context, opts helm.UninstallOptions) error
+	List(ctx context.Context, namespace string) ([]helm.Release, error)
+}
+
+// K8sClient defines the Kubernetes operations needed for Alloy component
+type K8sClient interface {
+	GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error)
+	ServiceMonitorCRDExists(ctx context.Context) (bool, error)
+}
+
+// Component implements the component.Component interface for Alloy
+type Component struct {
+	helmClient HelmClient
+	k8sClient  K8sClient
+}
+
+// NewComponent creates a new Alloy component instance
+func NewComponent(helmClient HelmClient, k8sClient K8sClient) *Component {
+	return &Component{
+		helmClient: helmClient,
+		k8sClient:  k8sClient,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return "alloy"
+}
+
+// Install installs Alloy
+func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig) error {
+	config, err := ParseConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	return Install(ctx, c.helmClient, c.k8sClient, config)
+}
+
+// Upgrade upgrades Alloy
+func (c *Component) Upgrade(ctx context.Context, cfg component.ComponentConfig) error {
+	return fmt.Errorf("upgrade not yet implemented")
+}
+
+// Status returns the current status of the Alloy collectors
+func (c *Component) Status(ctx context.Context) (*component.ComponentStatus, error) {
+	if c.helmClient == nil {
+		return &component.ComponentStatus{
+			Installed: false,
+			Healthy:   false,
+			Message:   "helm client not initialized",
+		}, nil
+	}
+
+	releases, err := c.helmClient.List(ctx, DefaultConfig().Namespace)
+	if err != nil {
+		return &component.ComponentStatus{
+			Installed: false,
+			Healthy:   false,
+			Message:   fmt.Sprintf("failed to list releases: %v", err),
+		}, nil
+	}
+
+	for _, rel := range releases {
+		if rel.Name == releaseName || rel.Name == eventsReleaseName {
+			healthy := rel.Status == "deployed"
+			return &component.ComponentStatus{
+				Installed: true,
+				Version:   rel.AppVersion,
+				Healthy:   healthy,
+				Message:   fmt.Sprintf("release status: %s", rel.Status),
+			}, nil
+		}
+	}
+
+	return &component.ComponentStatus{
+		Installed: false,
+		Healthy:   false,
+		Message:   "alloy release not found",
+	}, nil
+}
+
+// Uninstall removes Alloy
+func (c *Component) Uninstall(ctx context.Context) error {
+	return fmt.Errorf("uninstall not yet implemented")
+}
+
+// Dependencies returns the list of components that Alloy depends on
+func (c *Component) Dependencies() []string {
+	return []string{"loki"} // Prometheus and Tempo are used when present
+}
+
+// DefaultConfig returns a Config with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Version:                  "0.10.1", // alloy Helm chart version
+		Namespace:                "monitoring",
+		LokiURL:                  "http://loki-gateway.monitoring.svc.cluster.local:80/loki/api/v1/push",
+		PrometheusRemoteWriteURL: "",
+		TempoEndpoint:            "",
+		PodLogsEnabled:           true,
+		EventsEnabled:            true,
+		OTLPEnabled:              false,
+		RemovePromtail:           true,
+		PromtailNamespace:        "",
+		Values:                   make(map[string]interface{}),
+	}
+}
+
+// ParseConfig parses a ComponentConfig into an Alloy Config
+func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
+	config := DefaultConfig()
+
+	if version, ok := cfg.GetString("version"); ok {
+		config.Version = version
+	}
+
+	if namespace, ok := cfg.GetString("namespace"); ok {
+		config.Namespace = namespace
+	}
+
+	if lokiURL, ok := cfg.GetString("loki_url"); ok {
+		config.LokiURL = lokiURL
+	}
+
+	if remoteWriteURL, ok := cfg.GetString("prometheus_remote_write_url"); ok {
+		config.PrometheusRemoteWriteURL = remoteWriteURL
+	}
+
+	if tempoEndpoint, ok := cfg.GetString("tempo_endpoint"); ok {
+		config.TempoEndpoint = tempoEndpoint
+	}
+
+	if podLogsEnabled, ok := cfg.GetBool("pod_logs_enabled"); ok {
+		config.PodLogsEnabled = podLogsEnabled
+	}
+
+	if eventsEnabled, ok := cfg.GetBool("events_enabled"); ok {
+		config.EventsEnabled = eventsEnabled
+	}
+
+	if otlpEnabled, ok := cfg.GetBool("otlp_enabled"); ok {
+		config.OTLPEnabled = otlpEnabled
+	}
+
+	if removePromtail, ok := cfg.GetBool("remove_promtail"); ok {
+		config.RemovePromtail = removePromtail
+	}
+
+	if promtailNamespace, ok := cfg.GetString("promtail_namespace"); ok {
+		config.PromtailNamespace = promtailNamespace
+	}
+
+	if values, ok := cfg.GetMap("values"); ok {
+		config.Values = values
+	}
+
+	if config.PromtailNamespace == "" {
+		config.PromtailNamespace = config.Namespace
+	}
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate validates the Alloy configuration
+func (c *Config) Validate() error {
+	if !c.PodLogsEnabled && !c.EventsEnabled && !c.OTLPEnabled {
+		return fmt.Errorf("at least one of pod_logs_enabled, events_enabled or otlp_enabled must be set")
+	}
+
+	if (c.PodLogsEnabled || c.EventsEnabled) && c.LokiURL == "" {
+		return fmt.Errorf("loki_url is required to collect pod logs or events")
+	}
+
+	if c.OTLPEnabled && c.LokiURL == "" && c.PrometheusRemoteWriteURL == "" && c.TempoEndpoint == "" {
+		return fmt.Errorf("otlp_enabled needs at least one of loki_url, prometheus_remote_write_url or tempo_endpoint")
+	}
+
+	return nil
+}
+
+// GetOTLPGRPCEndpoint returns the in-cluster OTLP gRPC address (host:port)
+func (c *Config) GetOTLPGRPCEndpoint() string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local:%d", releaseName, c.Namespace, OTLPGRPCPort)
+}
+
+// GetOTLPHTTPEndpoint returns the in-cluster OTLP HTTP URL
+func (c *Config) GetOTLPHTTPEndpoint() string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", releaseName, c.Namespace, OTLPHTTPPort)
+}
@@ -0,0 +1,161 @@
+package alloy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	assert.Equal(t, "monitoring", cfg.Namespace)
+	assert.True(t, cfg.PodLogsEnabled)
+	assert.True(t, cfg.EventsEnabled)
+	assert.False(t, cfg.OTLPEnabled)
+	assert.True(t, cfg.RemovePromtail)
+	assert.Empty(t, cfg.PrometheusRemoteWriteURL)
+	assert.Empty(t, cfg.TempoEndpoint)
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "monitoring", cfg.PromtailNamespace, "Promtail is looked for next to Alloy by default")
+
+	cfg, err = ParseConfig(component.ComponentConfig{
+		"namespace":                   "collectors",
+		"loki_url":                    "http://loki:3100/loki/api/v1/push",
+		"prometheus_remote_write_url": "http://prometheus:9090/api/v1/write",
+		"tempo_endpoint":              "tempo:4317",
+		"events_enabled":              false,
+		"otlp_enabled":                true,
+		"remove_promtail":             false,
+		"promtail_namespace":          "loki",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "collectors", cfg.Namespace)
+	assert.Equal(t, "http://loki:3100/loki/api/v1/push", cfg.LokiURL)
+	assert.Equal(t, "http://prometheus:9090/api/v1/write", cfg.PrometheusRemoteWriteURL)
+	assert.Equal(t, "tempo:4317", cfg.TempoEndpoint)
+	assert.False(t, cfg.EventsEnabled)
+	assert.True(t, cfg.OTLPEnabled)
+	assert.False(t, cfg.RemovePromtail)
+	assert.Equal(t, "loki", cfg.PromtailNamespace)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "nothing collected", modify: func(c *Config) {
+			c.PodLogsEnabled = false
+			c.EventsEnabled = false
+		}, wantErr: "at least one of"},
+		{name: "logs without loki", modify: func(c *Config) { c.LokiURL = "" }, wantErr: "loki_url is required"},
+		{name: "otlp without destinations", modify: func(c *Config) {
+			c.PodLogsEnabled = false
+			c.EventsEnabled = false
+			c.OTLPEnabled = true
+			c.LokiURL = ""
+		}, wantErr: "otlp_enabled needs"},
+		{name: "otlp traces only", modify: func(c *Config) {
+			c.PodLogsEnabled = false
+			c.EventsEnabled = false
+			c.OTLPEnabled = true
+			c.LokiURL = ""
+			c.TempoEndpoint = "tempo:4317"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComponent(t *testing.T) {
+	comp := NewComponent(nil, nil)
+	assert.Equal(t, "alloy", comp.Name())
+	assert.Equal(t, []string{"loki"}, comp.Dependencies())
+
+	status, err := comp.Status(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+
+	status, err = NewComponent(&mockHelmClient{
+		listReleases: []helm.Release{{Name: "alloy", Status: "deployed", AppVersion: "v1.5.1"}},
+	}, nil).Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "v1.5.1", status.Version)
+}
+
+// mockHelmClient is a mock implementation of HelmClient for testing
+type mockHelmClient struct {
+	installErr      error
+	listReleases    []helm.Release
+	chartsInstalled []helm.InstallOptions
+	upgradeCalls    []helm.UpgradeOptions
+	uninstallCalls  []helm.UninstallOptions
+}
+
+func (m *mockHelmClient) AddRepo(ctx context.Context, opts helm.RepoAddOptions) error {
+	return nil
+}
+
+func (m *mockHelmClient) Install(ctx context.Context, opts helm.InstallOptions) error {
+	m.chartsInstalled = append(m.chartsInstalled, opts)
+	return m.installErr
+}
+
+func (m *mockHelmClient) Upgrade(ctx context.Context, opts helm.UpgradeOptions) error {
+	m.upgradeCalls = append(m.upgradeCalls, opts)
+	return nil
+}
+
+func (m *mockHelmClient) List(ctx context.Context, namespace string) ([]helm.Release, error) {
+	var out []helm.Release
+	for _, rel := range m.listReleases {
+		if rel.Namespace == "" || rel.Namespace == namespace {
+			out = append(out, rel)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockHelmClient) Uninstall(ctx context.Context, opts helm.UninstallOptions) error {
+	m.uninstallCalls = append(m.uninstallCalls, opts)
+	return nil
+}
+
+// mockK8sClient is a mock implementation of K8sClient for testing
+type mockK8sClient struct {
+	serviceMonitorCRDExists bool
+}
+
+func (m *mockK8sClient) GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error) {
+	return nil, nil
+}
+
+func (m *mockK8sClient) ServiceMonitorCRDExists(ctx context.Context) (bool, error) {
+	return m.serviceMonitorCRDExists, nil
+}
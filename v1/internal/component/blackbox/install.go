@@ -0,0 +1,192 @@
+package blackbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+const (
+	blackboxRepoName = "prometheus-community"
+	blackboxRepoURL  = "https://prometheus-community.github.io/helm-charts"
+	blackboxChart    = "prometheus-community/prometheus-blackbox-exporter"
+	releaseName      = "blackbox-exporter"
+
+	// proberPort is the blackbox-exporter Service port
+	proberPort = 9115
+)
+
+// Install installs blackbox-exporter using Helm, then creates a Probe for
+// every discovered route and service link
+func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cfg *Config) error {
+	if helmClient == nil {
+		return fmt.Errorf("helm client cannot be nil")
+	}
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	fmt.Println("  Installing blackbox-exporter...")
+
+	// Add Helm repository
+	if err := helmClient.AddRepo(ctx, helm.RepoAddOptions{
+		Name:        blackboxRepoName,
+		URL:         blackboxRepoURL,
+		ForceUpdate: true,
+	}); err != nil {
+		return fmt.Errorf("failed to add helm repository: %w", err)
+	}
+
+	values := buildHelmValues(cfg)
+	values["serviceMonitor"].(map[string]interface{})["selfMonitor"].(map[string]interface{})["enabled"] = serviceMonitorCRDAvailable(ctx, k8sClient)
+
+	// Check if release already exists
+	releases, err := helmClient.List(ctx, cfg.Namespace)
+	var existingRelease *helm.Release
+	if err == nil {
+		for i := range releases {
+			if releases[i].Name == releaseName {
+				existingRelease = &releases[i]
+				break
+			}
+		}
+	}
+
+	if existingRelease != nil {
+		fmt.Printf("  Upgrading blackbox-exporter (current status: %s)...\n", existingRelease.Status)
+		if err := helmClient.Upgrade(ctx, helm.UpgradeOptions{
+			ReleaseName: releaseName,
+			Namespace:   cfg.Namespace,
+			Chart:       blackboxChart,
+			Version:     cfg.Version,
+			Values:      values,
+			Wait:        true,
+			Timeout:     2 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to upgrade blackbox-exporter: %w", err)
+		}
+	} else {
+		if err := helmClient.Install(ctx, helm.InstallOptions{
+			ReleaseName:     releaseName,
+			Namespace:       cfg.Namespace,
+			Chart:           blackboxChart,
+			Version:         cfg.Version,
+			Values:          values,
+			CreateNamespace: true,
+			Wait:            true,
+			Timeout:         2 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to install blackbox-exporter: %w", err)
+		}
+	}
+
+	if k8sClient != nil {
+		targets, err := SyncProbes(ctx, k8sClient, cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  Probing %d target(s) every %s\n", len(targets), cfg.Interval)
+	}
+
+	fmt.Println("  blackbox-exporter installed successfully")
+	return nil
+}
+
+func serviceMonitorCRDAvailable(ctx context.Context, k8sClient K8sClient) bool {
+	if k8sClient == nil {
+		return false
+	}
+	exists, err := k8sClient.ServiceMonitorCRDExists(ctx)
+	if err != nil {
+		fmt.Printf("  Warning: could not check for the ServiceMonitor CRD: %v\n", err)
+		return false
+	}
+	return exists
+}
+
+// Charts returns the Helm charts Install uses for blackbox-exporter
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: blackboxRepoName,
+		RepoURL:  blackboxRepoURL,
+		Chart:    blackboxChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
+// buildHelmValues constructs Helm values for blackbox-exporter
+func buildHelmValues(cfg *Config) map[string]interface{} {
+	values := make(map[string]interface{})
+
+	// Start with user-provided values
+	for k, v := range cfg.Values {
+		values[k] = v
+	}
+
+	// Keep the Service name short; Probes point at it
+	values["fullnameOverride"] = releaseName
+
+	values["config"] = map[string]interface{}{
+		"modules": modules(),
+	}
+
+	// Targets are probed through Probe resources, not the chart's
+	// ServiceMonitor targets; only the exporter's own metrics are scraped
+	values["serviceMonitor"] = map[string]interface{}{
+		"enabled": false,
+		"selfMonitor": map[string]interface{}{
+			"enabled": true,
+		},
+	}
+
+	values["resources"] = map[string]interface{}{
+		"requests": map[string]interface{}{
+			"cpu":    "10m",
+			"memory": "32Mi",
+		},
+	}
+
+	return values
+}
+
+// modules are the blackbox-exporter modules the Probes use. Stack services
+// present certificates from the cluster's internal CA, so verification is
+// skipped: the probes check availability and expiry, not trust.
+func modules() map[string]interface{} {
+	tlsConfig := map[string]interface{}{
+		"insecure_skip_verify": true,
+	}
+	return map[string]interface{}{
+		ModuleHTTP: map[string]interface{}{
+			"prober":  "http",
+			"timeout": "5s",
+			"http": map[string]interface{}{
+				"follow_redirects":      true,
+				"preferred_ip_protocol": "ip4",
+				"tls_config":            tlsConfig,
+			},
+		},
+		ModuleTLS: map[string]interface{}{
+			"prober":  "tcp",
+			"timeout": "5s",
+			"tcp": map[string]interface{}{
+				"tls":                   true,
+				"preferred_ip_protocol": "ip4",
+				"tls_config":            tlsConfig,
+			},
+		},
+		ModuleTCP: map[string]interface{}{
+			"prober":  "tcp",
+			"timeout": "5s",
+			"tcp": map[string]interface{}{
+				"preferred_ip_protocol": "ip4",
+			},
+		},
+	}
+}
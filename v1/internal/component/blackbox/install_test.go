@@ -0,0 +1,152 @@
+package blackbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+func TestInstall_Success(t *testing.T) {
+	helmClient := &mockHelmClient{}
+	k8sClient := newMockK8sClient()
+	cfg := DefaultConfig()
+	cfg.ServiceLinks = map[string]string{"http://192.0.2.11:8200": "OpenBAO"}
+
+	require.NoError(t, Install(context.Background(), helmClient, k8sClient, cfg))
+
+	require.Len(t, helmClient.chartsInstalled, 1)
+	install := helmClient.chartsInstalled[0]
+	assert.Equal(t, releaseName, install.ReleaseName)
+	assert.Equal(t, blackboxChart, install.Chart)
+	assert.Equal(t, "monitoring", install.Namespace)
+	assert.Equal(t, releaseName, install.Values["fullnameOverride"])
+	selfMonitor := install.Values["serviceMonitor"].(map[string]interface{})["selfMonitor"].(map[string]interface{})
+	assert.Equal(t, false, selfMonitor["enabled"], "no ServiceMonitor without the CRD")
+
+	require.Len(t, k8sClient.manifests, 1)
+	assert.Contains(t, k8sClient.manifests[0], "kind: Probe")
+	assert.Contains(t, k8sClient.manifests[0], "- http://192.0.2.11:8200")
+}
+
+func TestInstall_RemovesStaleProbes(t *testing.T) {
+	stale := probeObject("probe-old-target-00000000")
+	kept := probeObject(probeName(Target{Module: ModuleHTTP, Address: "http://192.0.2.11:8200"}))
+	k8sClient := newMockK8sClient(stale, kept)
+	cfg := DefaultConfig()
+	cfg.ServiceLinks = map[string]string{"http://192.0.2.11:8200": "OpenBAO"}
+
+	require.NoError(t, Install(context.Background(), &mockHelmClient{
+		listReleases: []helm.Release{{Name: releaseName, Status: "deployed"}},
+	}, k8sClient, cfg))
+
+	probes, err := k8sClient.dynamicClient.Resource(probeGVR).Namespace("monitoring").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, probes.Items, 1)
+	assert.Equal(t, kept.GetName(), probes.Items[0].GetName())
+}
+
+func TestSyncProbes_KeepsProbesWhenDiscoveryWarns(t *testing.T) {
+	stale := probeObject("probe-old-target-00000000")
+	k8sClient := newMockK8sClient(stale)
+	k8sClient.clientset.(*kubernetesfake.Clientset).PrependReactor("list", "ingresses", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	cfg := DefaultConfig()
+	cfg.ServiceLinks = map[string]string{"http://192.0.2.11:8200": "OpenBAO"}
+
+	targets, err := SyncProbes(context.Background(), k8sClient, cfg)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Len(t, k8sClient.manifests, 1)
+
+	probes, err := k8sClient.dynamicClient.Resource(probeGVR).Namespace("monitoring").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, probes.Items, 1)
+	assert.Equal(t, stale.GetName(), probes.Items[0].GetName())
+}
+
+func TestSyncProbes_UpdatesExistingProbes(t *testing.T) {
+	target := Target{Name: "OpenBAO", Source: "service-link", Module: ModuleHTTP, Address: "http://192.0.2.11:8200"}
+	existing := probeObject(probeName(target))
+	existing.Object["spec"] = map[string]interface{}{
+		"interval": "1m",
+		"module":   ModuleHTTP,
+		"prober":   map[string]interface{}{"url": "old-prober:9115"},
+	}
+	k8sClient := newMockK8sClient(existing)
+	cfg := DefaultConfig()
+	cfg.Interval = "15s"
+	target.Name = "Vault"
+
+	require.NoError(t, syncProbes(context.Background(), k8sClient, cfg, []Target{target}, true))
+
+	probe, err := k8sClient.dynamicClient.Resource(probeGVR).Namespace("monitoring").Get(context.Background(), existing.GetName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	interval, _, _ := unstructured.NestedString(probe.Object, "spec", "interval")
+	assert.Equal(t, "15s", interval)
+	prober, _, _ := unstructured.NestedString(probe.Object, "spec", "prober", "url")
+	assert.Equal(t, cfg.GetProberAddress(), prober)
+	labels, _, _ := unstructured.NestedStringMap(probe.Object, "spec", "targets", "staticConfig", "labels")
+	assert.Equal(t, "Vault", labels[discovery.ProbeNameLabel])
+}
+
+func TestInstall_HelmFailure(t *testing.T) {
+	k8sClient := newMockK8sClient()
+	err := Install(context.Background(), &mockHelmClient{installErr: errors.New("boom")}, k8sClient, DefaultConfig())
+	assert.ErrorContains(t, err, "failed to install blackbox-exporter")
+	assert.Empty(t, k8sClient.manifests)
+}
+
+func TestInstall_NilHelmClient(t *testing.T) {
+	err := Install(context.Background(), nil, nil, DefaultConfig())
+	assert.ErrorContains(t, err, "helm client cannot be nil")
+}
+
+func TestBuildHelmValues(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Values = map[string]interface{}{"replicas": 2}
+
+	values := buildHelmValues(cfg)
+
+	assert.Equal(t, 2, values["replicas"])
+	modules := values["config"].(map[string]interface{})["modules"].(map[string]interface{})
+	assert.Contains(t, modules, ModuleHTTP)
+	assert.Contains(t, modules, ModuleTLS)
+	assert.Contains(t, modules, ModuleTCP)
+	tcp := modules[ModuleTLS].(map[string]interface{})["tcp"].(map[string]interface{})
+	assert.Equal(t, true, tcp["tls"])
+	assert.Equal(t, false, values["serviceMonitor"].(map[string]interface{})["enabled"])
+}
+
+func TestCharts(t *testing.T) {
+	charts := Charts(nil)
+	require.Len(t, charts, 1)
+	assert.Equal(t, blackboxChart, charts[0].Chart)
+	assert.Equal(t, DefaultConfig().Version, charts[0].Version)
+}
+
+func probeObject(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "Probe",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "monitoring",
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name":       "blackbox-exporter",
+				"app.kubernetes.io/managed-by": "foundry",
+			},
+		},
+	}}
+}
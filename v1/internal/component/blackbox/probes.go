@@ -0,0 +1,309 @@
+package blackbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
+)
+
+// Modules a target can be probed with
+const (
+	// ModuleHTTP requests a URL and expects a 2xx response
+	ModuleHTTP = "http_2xx"
+
+	// ModuleTLS opens a TLS connection to host:port
+	ModuleTLS = "tls_connect"
+
+	// ModuleTCP opens a TCP connection to host:port
+	ModuleTCP = "tcp_connect"
+)
+
+// probeSelector selects the Probes this component manages
+const probeSelector = "app.kubernetes.io/name=blackbox-exporter,app.kubernetes.io/managed-by=foundry"
+
+var probeGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "probes"}
+
+// Target is one endpoint a Probe checks
+type Target struct {
+	// Name is shown in probe results and alerts
+	Name string
+
+	// Source is where the target was found: a route kind, Ingress or
+	// configuration
+	Source string
+
+	// Module is the blackbox-exporter module that probes it
+	Module string
+
+	// Address is the URL or host:port that is probed
+	Address string
+}
+
+// Targets turns the discovered routes and service links, the stack's
+// service links and the configured extra targets into probe targets
+func Targets(snapshot discovery.Snapshot, cfg *Config) []Target {
+	var targets []Target
+	seen := map[string]bool{}
+	add := func(t Target) {
+		key := t.Module + "|" + t.Address
+		if t.Address == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		targets = append(targets, t)
+	}
+
+	for _, target := range cfg.Targets {
+		target = strings.TrimSpace(target)
+		if strings.Contains(target, "://") {
+			add(Target{Name: target, Source: "configuration", Module: ModuleHTTP, Address: target})
+		} else {
+			add(Target{Name: target, Source: "configuration", Module: ModuleTCP, Address: target})
+		}
+	}
+
+	if cfg.ProbeServiceLinks {
+		links := make([]string, 0, len(cfg.ServiceLinks))
+		for link := range cfg.ServiceLinks {
+			links = append(links, link)
+		}
+		sort.Strings(links)
+		for _, link := range links {
+			add(Target{Name: cfg.ServiceLinks[link], Source: "configuration", Module: ModuleHTTP, Address: link})
+		}
+		for _, link := range snapshot.Services {
+			add(Target{Name: link.Name, Source: link.Source, Module: ModuleHTTP, Address: link.URL})
+		}
+	}
+
+	if cfg.ProbeRoutes {
+		for _, gateway := range snapshot.Gateways {
+			for _, route := range gateway.Routes {
+				for _, t := range routeTargets(gateway, route) {
+					add(t)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Name != targets[j].Name {
+			return targets[i].Name < targets[j].Name
+		}
+		return targets[i].Address < targets[j].Address
+	})
+	return targets
+}
+
+// routeTargets probes a route the way clients reach it: HTTP routes by URL,
+// TLS routes by hostname and TCP routes on the Gateway address
+func routeTargets(gateway discovery.GatewayExposure, route discovery.Route) []Target {
+	name := route.Namespace + "/" + route.Name
+	var targets []Target
+	switch route.Kind {
+	case "HTTPRoute":
+		for _, u := range route.URLs {
+			if !strings.Contains(u, "*") {
+				targets = append(targets, Target{Name: name, Source: route.Kind, Module: ModuleHTTP, Address: u})
+			}
+		}
+	case "GRPCRoute", "TLSRoute":
+		for _, hostname := range route.Hostnames {
+			if strings.Contains(hostname, "*") {
+				continue
+			}
+			for _, port := range route.Ports {
+				module := ModuleTLS
+				if listenerProtocol(gateway, port) == "HTTP" {
+					module = ModuleTCP
+				}
+				targets = append(targets, Target{Name: name, Source: route.Kind, Module: module, Address: hostPort(hostname, port)})
+			}
+		}
+	case "TCPRoute":
+		if len(gateway.Addresses) == 0 {
+			return nil
+		}
+		for _, port := range route.Ports {
+			targets = append(targets, Target{Name: name, Source: route.Kind, Module: ModuleTCP, Address: hostPort(gateway.Addresses[0], port)})
+		}
+	}
+	return targets
+}
+
+func listenerProtocol(gateway discovery.GatewayExposure, port int64) string {
+	for _, listener := range gateway.Listeners {
+		if listener.Port == port {
+			return listener.Protocol
+		}
+	}
+	return ""
+}
+
+func hostPort(host string, port int64) string {
+	return net.JoinHostPort(host, strconv.FormatInt(port, 10))
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// probeName is a readable, stable Probe name for a target
+func probeName(t Target) string {
+	sum := sha256.Sum256([]byte(t.Module + "|" + t.Address))
+	slug := t.Address
+	if u, err := url.Parse(t.Address); err == nil && u.Host != "" {
+		slug = u.Host + u.Path
+	}
+	slug = strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(slug), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	return "probe-" + slug + "-" + hex.EncodeToString(sum[:4])
+}
+
+// probesManifest renders a Probe per target
+func probesManifest(cfg *Config, targets []Target) (string, error) {
+	docs := make([]string, 0, len(targets))
+	for _, t := range targets {
+		out, err := yaml.Marshal(probeResource(cfg, t))
+		if err != nil {
+			return "", fmt.Errorf("failed to render Probe for %s: %w", t.Address, err)
+		}
+		docs = append(docs, string(out))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// probeResource builds the Probe for a target. The probe labels are copied
+// onto every series, so results and alerts can name the target.
+func probeResource(cfg *Config, t Target) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "Probe",
+		"metadata": map[string]interface{}{
+			"name":      probeName(t),
+			"namespace": cfg.Namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name":       "blackbox-exporter",
+				"app.kubernetes.io/managed-by": "foundry",
+			},
+		},
+		"spec": map[string]interface{}{
+			"interval": cfg.Interval,
+			"module":   t.Module,
+			"prober": map[string]interface{}{
+				"url": cfg.GetProberAddress(),
+			},
+			"targets": map[string]interface{}{
+				"staticConfig": map[string]interface{}{
+					"static": []string{t.Address},
+					"labels": map[string]interface{}{
+						discovery.ProbeNameLabel:   t.Name,
+						discovery.ProbeSourceLabel: t.Source,
+						discovery.ProbeModuleLabel: t.Module,
+					},
+				},
+			},
+		},
+	}
+}
+
+// SyncProbes discovers the cluster's routes and service links and applies a
+// Probe for each target, returning the targets. The Probes of targets that
+// are gone are deleted, unless discovery reported warnings: routes it could
+// not read would otherwise look removed.
+func SyncProbes(ctx context.Context, k8sClient K8sClient, cfg *Config) ([]Target, error) {
+	snapshot := discovery.InspectCluster(ctx, k8sClient.Clientset(), k8sClient.DynamicClient())
+	for _, warning := range snapshot.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+	targets := Targets(snapshot, cfg)
+	if err := syncProbes(ctx, k8sClient, cfg, targets, len(snapshot.Warnings) == 0); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// updateProbes merge-patches the Probes of the targets. ApplyManifest leaves
+// existing Probes alone, so this is what brings a changed interval, prober
+// address or target name to them.
+func updateProbes(ctx context.Context, dyn dynamic.Interface, cfg *Config, targets []Target) error {
+	for _, t := range targets {
+		patch, err := json.Marshal(map[string]interface{}{"spec": probeResource(cfg, t)["spec"]})
+		if err != nil {
+			return err
+		}
+		// A Probe deleted since it was applied is recreated on the next sync
+		name := probeName(t)
+		_, err = dyn.Resource(probeGVR).Namespace(cfg.Namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to update Probe %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// syncProbes applies a Probe per target and, when prune is set, deletes the
+// Probes of targets that are gone
+func syncProbes(ctx context.Context, k8sClient K8sClient, cfg *Config, targets []Target, prune bool) error {
+	if len(targets) > 0 {
+		manifest, err := probesManifest(cfg, targets)
+		if err != nil {
+			return err
+		}
+		if err := k8sClient.ApplyManifest(ctx, manifest); err != nil {
+			return fmt.Errorf("failed to apply Probes: %w", err)
+		}
+	}
+
+	dyn := k8sClient.DynamicClient()
+	if dyn == nil {
+		return nil
+	}
+	if err := updateProbes(ctx, dyn, cfg, targets); err != nil {
+		return err
+	}
+	if !prune {
+		fmt.Println("  Keeping existing Probes until discovery completes without warnings")
+		return nil
+	}
+	wanted := map[string]bool{}
+	for _, t := range targets {
+		wanted[probeName(t)] = true
+	}
+	existing, err := dyn.Resource(probeGVR).Namespace(cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: probeSelector})
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list Probes: %w", err)
+	}
+	for _, probe := range existing.Items {
+		if wanted[probe.GetName()] {
+			continue
+		}
+		err := dyn.Resource(probeGVR).Namespace(cfg.Namespace).Delete(ctx, probe.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to remove Probe %s: %w", probe.GetName(), err)
+		}
+		fmt.Printf("  Removed Probe %s (target no longer exposed)\n", probe.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package blackbox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/catalystcommunity/foundry/v1/internal/discovery"
+)
+
+func testSnapshot() discovery.Snapshot {
+	return discovery.Snapshot{
+		ClusterAvailable: true,
+		Services: []discovery.ServiceLink{
+			{Name: "Grafana", URL: "https://grafana.example.test", Source: "Ingress", Namespace: "grafana"},
+		},
+		Gateways: []discovery.GatewayExposure{{
+			Namespace: "projectcontour",
+			Name:      "contour",
+			Addresses: []string{"192.0.2.10"},
+			Listeners: []discovery.Listener{
+				{Name: "http", Protocol: "HTTP", Port: 80},
+				{Name: "https", Protocol: "HTTPS", Port: 443},
+				{Name: "tls", Protocol: "TLS", Port: 8443},
+				{Name: "otlp-grpc", Protocol: "TCP", Port: 4317},
+			},
+			Routes: []discovery.Route{
+				{Kind: "HTTPRoute", Namespace: "grafana", Name: "grafana", Hostnames: []string{"grafana.example.test"}, URLs: []string{"https://grafana.example.test"}, Ports: []int64{80, 443}},
+				{Kind: "HTTPRoute", Namespace: "apps", Name: "catch-all", Hostnames: []string{"*.example.test"}, URLs: []string{"https://*.example.test"}},
+				{Kind: "GRPCRoute", Namespace: "apps", Name: "api", Hostnames: []string{"api.example.test"}, Ports: []int64{443}},
+				{Kind: "TLSRoute", Namespace: "apps", Name: "mqtt", Hostnames: []string{"mqtt.example.test"}, Ports: []int64{8443}},
+				{Kind: "TCPRoute", Namespace: "monitoring", Name: "tempo-otlp-grpc", Ports: []int64{4317}},
+			},
+		}},
+	}
+}
+
+func TestTargets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServiceLinks = map[string]string{"http://192.0.2.11:8200": "OpenBAO"}
+	cfg.Targets = []string{"192.0.2.20:22"}
+
+	targets := Targets(testSnapshot(), cfg)
+
+	assert.Equal(t, []Target{
+		{Name: "192.0.2.20:22", Source: "configuration", Module: ModuleTCP, Address: "192.0.2.20:22"},
+		{Name: "Grafana", Source: "Ingress", Module: ModuleHTTP, Address: "https://grafana.example.test"},
+		{Name: "OpenBAO", Source: "configuration", Module: ModuleHTTP, Address: "http://192.0.2.11:8200"},
+		{Name: "apps/api", Source: "GRPCRoute", Module: ModuleTLS, Address: "api.example.test:443"},
+		{Name: "apps/mqtt", Source: "TLSRoute", Module: ModuleTLS, Address: "mqtt.example.test:8443"},
+		{Name: "monitoring/tempo-otlp-grpc", Source: "TCPRoute", Module: ModuleTCP, Address: "192.0.2.10:4317"},
+	}, targets, "the HTTPRoute duplicates the Ingress link and wildcard hosts are skipped")
+}
+
+func TestTargets_Disabled(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ProbeRoutes = false
+	cfg.ProbeServiceLinks = false
+	cfg.ServiceLinks = map[string]string{"http://192.0.2.11:8200": "OpenBAO"}
+
+	assert.Empty(t, Targets(testSnapshot(), cfg))
+}
+
+func TestProbeName(t *testing.T) {
+	name := probeName(Target{Module: ModuleHTTP, Address: "https://grafana.example.test/login"})
+	assert.True(t, strings.HasPrefix(name, "probe-grafana-example-test-login-"), name)
+	assert.NotEqual(t, name, probeName(Target{Module: ModuleTLS, Address: "https://grafana.example.test/login"}))
+
+	long := probeName(Target{Module: ModuleTCP, Address: strings.Repeat("a", 100) + ".example.test:443"})
+	assert.LessOrEqual(t, len(long), 63)
+}
+
+func TestProbesManifest(t *testing.T) {
+	targets := []Target{
+		{Name: "Grafana", Source: "Ingress", Module: ModuleHTTP, Address: "https://grafana.example.test"},
+		{Name: "monitoring/tempo-otlp-grpc", Source: "TCPRoute", Module: ModuleTCP, Address: "192.0.2.10:4317"},
+	}
+
+	manifest, err := probesManifest(DefaultConfig(), targets)
+	require.NoError(t, err)
+
+	docs := strings.Split(manifest, "---\n")
+	require.Len(t, docs, 2)
+	var probe map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &probe))
+	assert.Equal(t, "Probe", probe["kind"])
+	spec := probe["spec"].(map[string]interface{})
+	assert.Equal(t, ModuleTCP, spec["module"])
+	assert.Equal(t, "60s", spec["interval"])
+	assert.Equal(t, "blackbox-exporter.monitoring.svc:9115", spec["prober"].(map[string]interface{})["url"])
+	static := spec["targets"].(map[string]interface{})["staticConfig"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"192.0.2.10:4317"}, static["static"])
+	assert.Equal(t, map[string]interface{}{
+		"probe_name":   "monitoring/tempo-otlp-grpc",
+		"probe_source": "TCPRoute",
+		"probe_module": ModuleTCP,
+	}, static["labels"])
+}
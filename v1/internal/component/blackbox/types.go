@@ -0,0 +1,239 @@
+package blackbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+// Config holds blackbox-exporter configuration
+type Config struct {
+	// Version is the Helm chart version to install
+	Version string `json:"version" yaml:"version"`
+
+	// Namespace for blackbox-exporter and its Probe resources
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Interval is how often each target is probed (e.g. "60s")
+	Interval string `json:"interval" yaml:"interval"`
+
+	// ProbeRoutes probes every HTTPRoute, GRPCRoute, TLSRoute and TCPRoute
+	// attached to a Gateway
+	ProbeRoutes bool `json:"probe_routes" yaml:"probe_routes"`
+
+	// ProbeServiceLinks probes Ingress hosts and the service links from the
+	// stack config
+	ProbeServiceLinks bool `json:"probe_service_links" yaml:"probe_service_links"`
+
+	// ServiceLinks maps the URL of each service link in the stack config to
+	// its display name. Foundry fills it in from the stack config.
+	ServiceLinks map[string]string `json:"service_links" yaml:"service_links"`
+
+	// Targets are extra URLs (probed over HTTP) or host:port addresses
+	// (probed with a TCP connect) to watch
+	Targets []string `json:"targets" yaml:"targets"`
+
+	// Values allows passing additional Helm values
+	Values map[string]interface{} `json:"values" yaml:",inline"`
+}
+
+// HelmClient defines the Helm operations needed for blackbox-exporter component
+type HelmClient interface {
+	AddRepo(ctx context.Context, opts helm.RepoAddOptions) error
+	Install(ctx context.Context, opts helm.InstallOptions) error
+	Upgrade(ctx context.Context, opts helm.UpgradeOptions) error
+	List(ctx context.Context, namespace string) ([]helm.Release, error)
+}
+
+// K8sClient defines the Kubernetes operations needed for blackbox-exporter
+// component
+type K8sClient interface {
+	GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error)
+	ServiceMonitorCRDExists(ctx context.Context) (bool, error)
+	ApplyManifest(ctx context.Context, manifest string) error
+	Clientset() kubernetes.Interface
+	DynamicClient() dynamic.Interface
+}
+
+// Component implements the component.Component interface for blackbox-exporter
+type Component struct {
+	helmClient HelmClient
+	k8sClient  K8sClient
+}
+
+// NewComponent creates a new blackbox-exporter component instance
+func NewComponent(helmClient HelmClient, k8sClient K8sClient) *Component {
+	return &Component{
+		helmClient: helmClient,
+		k8sClient:  k8sClient,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return "blackbox-exporter"
+}
+
+// Install installs blackbox-exporter and probes the discovered routes
+func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig) error {
+	config, err := ParseConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	return Install(ctx, c.helmClient, c.k8sClient, config)
+}
+
+// Upgrade upgrades blackbox-exporter
+func (c *Component) Upgrade(ctx context.Context, cfg component.ComponentConfig) error {
+	return fmt.Errorf("upgrade not yet implemented")
+}
+
+// Status returns the current status of blackbox-exporter
+func (c *Component) Status(ctx context.Context) (*component.ComponentStatus, error) {
+	if c.helmClient == nil {
+		return &component.ComponentStatus{
+			Installed: false,
+			Healthy:   false,
+			Message:   "helm client not initialized",
+		}, nil
+	}
+
+	releases, err := c.helmClient.List(ctx, DefaultConfig().Namespace)
+	if err != nil {
+		return &component.ComponentStatus{
+			Installed: false,
+			Healthy:   false,
+			Message:   fmt.Sprintf("failed to list releases: %v", err),
+		}, nil
+	}
+
+	for _, rel := range releases {
+		if rel.Name == releaseName {
+			healthy := rel.Status == "deployed"
+			return &component.ComponentStatus{
+				Installed: true,
+				Version:   rel.AppVersion,
+				Healthy:   healthy,
+				Message:   fmt.Sprintf("release status: %s", rel.Status),
+			}, nil
+		}
+	}
+
+	return &component.ComponentStatus{
+		Installed: false,
+		Healthy:   false,
+		Message:   "blackbox-exporter release not found",
+	}, nil
+}
+
+// Uninstall removes blackbox-exporter
+func (c *Component) Uninstall(ctx context.Context) error {
+	return fmt.Errorf("uninstall not yet implemented")
+}
+
+// Dependencies returns the list of components that blackbox-exporter depends on
+func (c *Component) Dependencies() []string {
+	return []string{"prometheus"} // Prometheus runs the Probes
+}
+
+// DefaultConfig returns a Config with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Version:           "9.1.0", // prometheus-blackbox-exporter Helm chart version
+		Namespace:         "monitoring",
+		Interval:          "60s",
+		ProbeRoutes:       true,
+		ProbeServiceLinks: true,
+		ServiceLinks:      map[string]string{},
+		Targets:           nil,
+		Values:            make(map[string]interface{}),
+	}
+}
+
+// ParseConfig parses a ComponentConfig into a blackbox-exporter Config
+func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
+	config := DefaultConfig()
+
+	if version, ok := cfg.GetString("version"); ok {
+		config.Version = version
+	}
+
+	if namespace, ok := cfg.GetString("namespace"); ok {
+		config.Namespace = namespace
+	}
+
+	if interval, ok := cfg.GetString("interval"); ok {
+		config.Interval = interval
+	}
+
+	if probeRoutes, ok := cfg.GetBool("probe_routes"); ok {
+		config.ProbeRoutes = probeRoutes
+	}
+
+	if probeServiceLinks, ok := cfg.GetBool("probe_service_links"); ok {
+		config.ProbeServiceLinks = probeServiceLinks
+	}
+
+	switch links := cfg["service_links"].(type) {
+	case map[string]string:
+		config.ServiceLinks = links
+	case map[string]interface{}:
+		for url, name := range links {
+			if s, ok := name.(string); ok {
+				config.ServiceLinks[url] = s
+			}
+		}
+	}
+
+	if targets, ok := cfg.GetStringSlice("targets"); ok {
+		config.Targets = targets
+	}
+
+	if values, ok := cfg.GetMap("values"); ok {
+		config.Values = values
+	}
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate validates the blackbox-exporter configuration
+func (c *Config) Validate() error {
+	if c.Namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid interval %q: %w", c.Interval, err)
+	}
+	if interval < 10*time.Second {
+		return fmt.Errorf("interval must be at least 10s")
+	}
+
+	for _, target := range c.Targets {
+		if strings.TrimSpace(target) == "" {
+			return fmt.Errorf("targets must not contain empty entries")
+		}
+	}
+
+	return nil
+}
+
+// GetProberAddress returns the in-cluster address Prometheus sends probes to
+func (c *Config) GetProberAddress() string {
+	return fmt.Sprintf("%s.%s.svc:%d", releaseName, c.Namespace, proberPort)
+}
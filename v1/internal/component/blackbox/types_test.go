@@ -0,0 +1,164 @@
+package blackbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	assert.Equal(t, "monitoring", cfg.Namespace)
+	assert.Equal(t, "60s", cfg.Interval)
+	assert.True(t, cfg.ProbeRoutes)
+	assert.True(t, cfg.ProbeServiceLinks)
+	assert.Empty(t, cfg.Targets)
+	assert.Equal(t, "blackbox-exporter.monitoring.svc:9115", cfg.GetProberAddress())
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{
+		"interval":            "30s",
+		"probe_routes":        false,
+		"probe_service_links": false,
+		"service_links":       map[string]interface{}{"http://192.0.2.11:8200": "OpenBAO"},
+		"targets":             []interface{}{"https://status.example.com", "192.0.2.20:22"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "30s", cfg.Interval)
+	assert.False(t, cfg.ProbeRoutes)
+	assert.False(t, cfg.ProbeServiceLinks)
+	assert.Equal(t, map[string]string{"http://192.0.2.11:8200": "OpenBAO"}, cfg.ServiceLinks)
+	assert.Equal(t, []string{"https://status.example.com", "192.0.2.20:22"}, cfg.Targets)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "no namespace", modify: func(c *Config) { c.Namespace = "" }, wantErr: "namespace is required"},
+		{name: "bad interval", modify: func(c *Config) { c.Interval = "often" }, wantErr: "invalid interval"},
+		{name: "interval too short", modify: func(c *Config) { c.Interval = "1s" }, wantErr: "at least 10s"},
+		{name: "empty target", modify: func(c *Config) { c.Targets = []string{" "} }, wantErr: "empty entries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComponent(t *testing.T) {
+	comp := NewComponent(nil, nil)
+	assert.Equal(t, "blackbox-exporter", comp.Name())
+	assert.Equal(t, []string{"prometheus"}, comp.Dependencies())
+
+	status, err := comp.Status(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+
+	status, err = NewComponent(&mockHelmClient{
+		listReleases: []helm.Release{{Name: releaseName, Status: "deployed", AppVersion: "v0.25.0"}},
+	}, nil).Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "v0.25.0", status.Version)
+}
+
+// mockHelmClient is a mock implementation of HelmClient for testing
+type mockHelmClient struct {
+	installErr      error
+	listReleases    []helm.Release
+	chartsInstalled []helm.InstallOptions
+	upgradeCalls    []helm.UpgradeOptions
+}
+
+func (m *mockHelmClient) AddRepo(ctx context.Context, opts helm.RepoAddOptions) error {
+	return nil
+}
+
+func (m *mockHelmClient) Install(ctx context.Context, opts helm.InstallOptions) error {
+	m.chartsInstalled = append(m.chartsInstalled, opts)
+	return m.installErr
+}
+
+func (m *mockHelmClient) Upgrade(ctx context.Context, opts helm.UpgradeOptions) error {
+	m.upgradeCalls = append(m.upgradeCalls, opts)
+	return nil
+}
+
+func (m *mockHelmClient) List(ctx context.Context, namespace string) ([]helm.Release, error) {
+	return m.listReleases, nil
+}
+
+// mockK8sClient is a mock implementation of K8sClient for testing
+type mockK8sClient struct {
+	serviceMonitorCRDExists bool
+	manifests               []string
+	clientset               kubernetes.Interface
+	dynamicClient           *dynamicfake.FakeDynamicClient
+}
+
+// newMockK8sClient returns a client whose cluster holds the given Gateway
+// API and Probe objects
+func newMockK8sClient(objects ...runtime.Object) *mockK8sClient {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}:        "GatewayList",
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}:      "HTTPRouteList",
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "grpcroutes"}:      "GRPCRouteList",
+		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}: "TLSRouteList",
+		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tcproutes"}: "TCPRouteList",
+		probeGVR: "ProbeList",
+	}
+	return &mockK8sClient{
+		clientset:     kubernetesfake.NewSimpleClientset(),
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...),
+	}
+}
+
+func (m *mockK8sClient) GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error) {
+	return nil, nil
+}
+
+func (m *mockK8sClient) ServiceMonitorCRDExists(ctx context.Context) (bool, error) {
+	return m.serviceMonitorCRDExists, nil
+}
+
+func (m *mockK8sClient) ApplyManifest(ctx context.Context, manifest string) error {
+	m.manifests = append(m.manifests, manifest)
+	return nil
+}
+
+func (m *mockK8sClient) Clientset() kubernetes.Interface {
+	return m.clientset
+}
+
+func (m *mockK8sClient) DynamicClient() dynamic.Interface {
+	return m.dynamicClient
+}
@@ -39,6 +39,41 @@ type K8sClient interface {
 	ServiceMonitorCRDExists(ctx context.Context) (bool, error)
 }
 
+// Charts returns the Helm charts Install uses for cert-manager
+func Charts(cfg *Config) []helm.ChartSource {
+	// NewComponent fills in the defaults Install relies on
+	cfg = NewComponent(cfg).config
+	return []helm.ChartSource{{
+		RepoName: DefaultRepoName,
+		RepoURL:  DefaultRepoURL,
+		Chart:    DefaultChartName,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
+// buildHelmValues constructs Helm values for cert-manager installation
+func buildHelmValues(cfg *Config) map[string]interface{} {
+	values := map[string]interface{}{
+		"installCRDs": cfg.InstallCRDs,
+		"global": map[string]interface{}{
+			"leaderElection": map[string]interface{}{
+				"namespace": cfg.Namespace,
+			},
+		},
+	}
+
+	// Only enable ServiceMonitor if configured (requires CRD from Prometheus Operator)
+	if cfg.ServiceMonitorEnabled {
+		values["prometheus"] = map[string]interface{}{
+			"servicemonitor": map[string]interface{}{
+				"enabled": true,
+			},
+		}
+	}
+	return values
+}
+
 // Install installs cert-manager via Helm
 func Install(ctx context.Context, cfg *Config, componentCfg component.ComponentConfig) error {
 	// Get Helm and K8s clients from component config
@@ -79,23 +114,7 @@ func Install(ctx context.Context, cfg *Config, componentCfg component.ComponentC
 	}
 
 	// Prepare Helm values
-	values := map[string]interface{}{
-		"installCRDs": cfg.InstallCRDs,
-		"global": map[string]interface{}{
-			"leaderElection": map[string]interface{}{
-				"namespace": cfg.Namespace,
-			},
-		},
-	}
-
-	// Only enable ServiceMonitor if configured (requires CRD from Prometheus Operator)
-	if cfg.ServiceMonitorEnabled {
-		values["prometheus"] = map[string]interface{}{
-			"servicemonitor": map[string]interface{}{
-				"enabled": true,
-			},
-		}
-	}
+	values := buildHelmValues(cfg)
 
 	// Check if release already exists
 	var releaseExists bool
@@ -167,6 +167,20 @@ func patchContourForGatewayAPI(ctx context.Context, k8sClient K8sClient, namespa
 	return nil
 }
 
+// Charts returns the Helm charts Install uses for Contour
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: contourRepoName,
+		RepoURL:  contourRepoURL,
+		Chart:    contourChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
 // buildHelmValues constructs the Helm values for official Contour chart installation
 // See: https://projectcontour.github.io/helm-charts/
 func buildHelmValues(cfg *Config) map[string]interface{} {
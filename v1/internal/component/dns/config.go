@@ -23,6 +23,10 @@ webserver=yes
 webserver-address=0.0.0.0
 webserver-port=8081
 webserver-allow-from=0.0.0.0/0
+{{- if .MetricsPassword}}
+# Prometheus scrapes /metrics with this password (any username)
+webserver-password={{.MetricsPassword}}
+{{- end}}
 
 # DNS Listener Configuration
 # Listen on localhost only - only the recursor should answer external queries
@@ -98,6 +102,9 @@ recursor:
 webservice:
   webserver: true
   api_key: {{.APIKey}}
+{{- if .MetricsPassword}}
+  password: {{.MetricsPassword}}
+{{- end}}
   address: 0.0.0.0
   port: 8082
   allow_from:
@@ -148,13 +155,15 @@ func GenerateRecursorConfig(cfg *Config) (string, error) {
 
 	// Create template data with forwarders and local zones as lists for YAML format
 	data := struct {
-		APIKey         string
-		ForwardersList []string
-		LocalZonesList []string
+		APIKey          string
+		MetricsPassword string
+		ForwardersList  []string
+		LocalZonesList  []string
 	}{
-		APIKey:         cfg.APIKey,
-		ForwardersList: cfg.Forwarders,
-		LocalZonesList: cfg.LocalZones,
+		APIKey:          cfg.APIKey,
+		MetricsPassword: cfg.MetricsPassword,
+		ForwardersList:  cfg.Forwarders,
+		LocalZonesList:  cfg.LocalZones,
 	}
 
 	tmpl, err := template.New("pdns-recursor").Parse(recursorConfigTemplate)
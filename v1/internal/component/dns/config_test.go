@@ -284,3 +284,27 @@ func TestGenerateRecursorConfigFormat(t *testing.T) {
 
 	assert.Greater(t, configLines, 0, "should have at least one config line")
 }
+
+func TestGenerateConfig_MetricsPassword(t *testing.T) {
+	cfg := &Config{
+		APIKey:     "secret-key",
+		Backend:    "gsqlite3",
+		DataDir:    "/var/lib/powerdns",
+		Forwarders: []string{"8.8.8.8"},
+	}
+
+	auth, err := GenerateAuthConfig(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, auth, "webserver-password")
+	recursor, err := GenerateRecursorConfig(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, recursor, "password:")
+
+	cfg.MetricsPassword = "metrics-pw"
+	auth, err = GenerateAuthConfig(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, auth, "\nwebserver-password=metrics-pw\n")
+	recursor, err = GenerateRecursorConfig(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, recursor, "  api_key: secret-key\n  password: metrics-pw\n  address: 0.0.0.0\n")
+}
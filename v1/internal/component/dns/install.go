@@ -76,13 +76,9 @@ func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig)
 	}
 
 	// Pull container images
-	// Use image tag from config, or default to "latest"
-	imageTag := dnsConfig.ImageTag
-	if imageTag == "" || imageTag == "49" {
-		imageTag = defaultImageTag
-	}
-	authImage := fmt.Sprintf("%s/%s:%s", defaultImageRegistry, defaultAuthImage, imageTag)
-	recursorImage := fmt.Sprintf("%s/%s:%s", defaultImageRegistry, defaultRecursorImage, imageTag)
+	authImage, recursorImage := Images(dnsConfig.ImageTag)
+	authImage = container.PinImage(authImage, dnsConfig.ImageDigests)
+	recursorImage = container.PinImage(recursorImage, dnsConfig.ImageDigests)
 
 	if err := runtime.Pull(authImage); err != nil {
 		return fmt.Errorf("failed to pull auth image: %w", err)
@@ -129,11 +125,19 @@ func configFromComponentConfig(cfg component.ComponentConfig) (*Config, *ssh.Con
 		dnsConfig.ImageTag = tag
 	}
 
+	// Extract image digests pinned by the stack lockfile
+	dnsConfig.ImageDigests = container.ParseImageDigests(cfg["image_digests"])
+
 	// Extract APIKey
 	if key, ok := cfg["api_key"].(string); ok {
 		dnsConfig.APIKey = key
 	}
 
+	// Extract the /metrics password
+	if password, ok := cfg["metrics_password"].(string); ok {
+		dnsConfig.MetricsPassword = password
+	}
+
 	// Extract Forwarders
 	if fwds, ok := cfg["forwarders"].([]string); ok && len(fwds) > 0 {
 		dnsConfig.Forwarders = fwds
@@ -239,6 +243,17 @@ func writeRemoteFile(conn *ssh.Connection, path, content string) error {
 	return nil
 }
 
+// Images returns the PowerDNS authoritative and recursor images for an image
+// tag from the config. An empty tag (or the legacy "49") means "latest".
+func Images(imageTag string) (authImage, recursorImage string) {
+	if imageTag == "" || imageTag == "49" {
+		imageTag = defaultImageTag
+	}
+	authImage = fmt.Sprintf("%s/%s:%s", defaultImageRegistry, defaultAuthImage, imageTag)
+	recursorImage = fmt.Sprintf("%s/%s:%s", defaultImageRegistry, defaultRecursorImage, imageTag)
+	return authImage, recursorImage
+}
+
 // createSystemdServices creates systemd service files for PowerDNS.
 func createSystemdServices(conn *ssh.Connection, cfg *Config, authImage, recursorImage string) error {
 	adapter := &sshExecutorAdapter{conn: conn}
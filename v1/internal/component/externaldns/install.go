@@ -109,6 +109,20 @@ func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cf
 	return nil
 }
 
+// Charts returns the Helm charts Install uses for External-DNS
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: externalDNSRepoName,
+		RepoURL:  externalDNSRepoURL,
+		Chart:    externalDNSChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
 // buildHelmValues constructs Helm values for External-DNS installation
 func buildHelmValues(cfg *Config) map[string]interface{} {
 	values := make(map[string]interface{})
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -69,10 +70,18 @@ func Install(ctx context.Context, k8sClient *k8s.Client, cfg *Config) error {
 	}
 
 	// Download the Gateway API CRDs manifest (experimental for Contour compatibility)
-	manifestURL := fmt.Sprintf("%s/%s/%s", GatewayAPIReleaseURL, cfg.Version, ExperimentalInstallFile)
-	manifest, err := downloadManifest(ctx, manifestURL)
-	if err != nil {
-		return fmt.Errorf("failed to download Gateway API manifest: %w", err)
+	var manifest string
+	if cfg.ManifestPath != "" {
+		data, err := os.ReadFile(cfg.ManifestPath)
+		if err != nil {
+			return fmt.Errorf("failed to read Gateway API manifest: %w", err)
+		}
+		manifest = string(data)
+	} else {
+		manifest, err = downloadManifest(ctx, ManifestURL(cfg.Version))
+		if err != nil {
+			return fmt.Errorf("failed to download Gateway API manifest: %w", err)
+		}
 	}
 
 	// Apply the manifest
@@ -88,6 +97,11 @@ func Install(ctx context.Context, k8sClient *k8s.Client, cfg *Config) error {
 	return nil
 }
 
+// ManifestURL returns the release manifest URL for a Gateway API version
+func ManifestURL(version string) string {
+	return fmt.Sprintf("%s/%s/%s", GatewayAPIReleaseURL, version, ExperimentalInstallFile)
+}
+
 // CheckCRDsInstalled checks if Gateway API CRDs are installed and returns the version
 func CheckCRDsInstalled(ctx context.Context, k8sClient *k8s.Client) (bool, string, error) {
 	dynamicClient := k8sClient.DynamicClient()
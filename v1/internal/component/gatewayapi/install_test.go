@@ -37,6 +37,16 @@ func TestParseConfig(t *testing.T) {
 				Version: "v1.2.0",
 			},
 		},
+		{
+			name: "local manifest",
+			cfg: component.ComponentConfig{
+				"manifest_path": "/tmp/bundle/manifests/experimental-install.yaml",
+			},
+			expected: &Config{
+				Version:      "v1.3.0",
+				ManifestPath: "/tmp/bundle/manifests/experimental-install.yaml",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -44,6 +54,7 @@ func TestParseConfig(t *testing.T) {
 			config, err := ParseConfig(tt.cfg)
 			require.NoError(t, err)
 			assert.Equal(t, tt.expected.Version, config.Version)
+			assert.Equal(t, tt.expected.ManifestPath, config.ManifestPath)
 		})
 	}
 }
@@ -100,4 +111,5 @@ func TestGatewayAPIReleaseURL(t *testing.T) {
 	actualURL := GatewayAPIReleaseURL + "/" + cfg.Version + "/" + ExperimentalInstallFile
 
 	assert.Equal(t, expectedURL, actualURL)
+	assert.Equal(t, expectedURL, ManifestURL(cfg.Version))
 }
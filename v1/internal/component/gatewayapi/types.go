@@ -97,6 +97,10 @@ func (c *Component) Dependencies() []string {
 type Config struct {
 	// Version is the Gateway API version to install (e.g., "v1.3.0")
 	Version string `json:"version" yaml:"version"`
+
+	// ManifestPath is a local copy of the release manifest. Offline bundle
+	// installs set it so the manifest is not downloaded.
+	ManifestPath string `json:"manifest_path,omitempty" yaml:"manifest_path,omitempty"`
 }
 
 // DefaultConfig returns a Config with sensible defaults
@@ -114,5 +118,9 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 		config.Version = version
 	}
 
+	if manifestPath, ok := cfg.GetString("manifest_path"); ok {
+		config.ManifestPath = manifestPath
+	}
+
 	return config, nil
 }
@@ -77,6 +77,19 @@ func Install(ctx context.Context, helmClient HelmClient, cfg *Config) error {
 	return nil
 }
 
+// Images returns the container images the embedded chart runs with cfg
+func Images(cfg *Config) ([]string, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	chartPath, cleanup, err := extractChart()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract embedded chart: %w", err)
+	}
+	defer cleanup()
+	return helm.ChartImages(chartPath, buildHelmValues(cfg))
+}
+
 // extractChart writes the embedded chart to a temp directory and returns the
 // path to the chart root plus a cleanup function.
 func extractChart() (string, func(), error) {
@@ -55,6 +55,8 @@ func TestEmbeddedChartLoads(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "foundry-gateway-controller", ch.Metadata.Name)
 	assert.NotEmpty(t, ch.Templates, "chart should carry its templates")
+	assert.Equal(t, true, ch.Values["metrics"].(map[string]interface{})["enabled"],
+		"reconcile metrics back the gateway controller alert rule")
 }
 
 func TestInstall_FreshInstall(t *testing.T) {
@@ -119,6 +121,15 @@ func TestInstall_ImageTagOverride(t *testing.T) {
 	assert.Equal(t, uint64(3), mock.installOpts.Values["replicaCount"])
 }
 
+func TestImages(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ImageTag = "1.2.3"
+
+	images, err := Images(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"containers.catalystsquad.com/public/catalystcommunity/foundry:1.2.3"}, images)
+}
+
 func TestExtractChart_CleansUp(t *testing.T) {
 	chartPath, cleanup, err := extractChart()
 	require.NoError(t, err)
@@ -121,6 +121,20 @@ func serviceMonitorCRDAvailable(ctx context.Context, k8sClient K8sClient) bool {
 	return exists
 }
 
+// Charts returns the Helm charts Install uses for Grafana
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: grafanaRepoName,
+		RepoURL:  grafanaRepoURL,
+		Chart:    grafanaChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
 // buildHelmValues constructs Helm values for Grafana installation
 func buildHelmValues(cfg *Config) map[string]interface{} {
 	values := make(map[string]interface{})
@@ -274,6 +288,13 @@ func buildHelmValues(cfg *Config) map[string]interface{} {
 	return values
 }
 
+// Datasource uids, fixed so the datasources can link to each other
+const (
+	prometheusDatasourceUID = "prometheus"
+	lokiDatasourceUID       = "loki"
+	tempoDatasourceUID      = "tempo"
+)
+
 // buildDatasources creates the datasources configuration
 func buildDatasources(cfg *Config) map[string]interface{} {
 	datasources := []map[string]interface{}{}
@@ -283,6 +304,7 @@ func buildDatasources(cfg *Config) map[string]interface{} {
 		datasources = append(datasources, map[string]interface{}{
 			"name":      "Prometheus",
 			"type":      "prometheus",
+			"uid":       prometheusDatasourceUID,
 			"url":       cfg.PrometheusURL,
 			"access":    "proxy",
 			"isDefault": true,
@@ -292,15 +314,67 @@ func buildDatasources(cfg *Config) map[string]interface{} {
 
 	// Loki data source
 	if cfg.LokiURL != "" {
+		jsonData := map[string]interface{}{
+			"maxLines": 1000,
+		}
+		// Turn trace ids in log lines into links to the trace
+		if cfg.TempoURL != "" {
+			jsonData["derivedFields"] = []map[string]interface{}{
+				{
+					"name":          "TraceID",
+					"matcherRegex":  `(?:trace_?id|traceI[Dd])[=:"\s]+(\w+)`,
+					"url":           "${__value.raw}",
+					"datasourceUid": tempoDatasourceUID,
+				},
+			}
+		}
 		datasources = append(datasources, map[string]interface{}{
 			"name":     "Loki",
 			"type":     "loki",
+			"uid":      lokiDatasourceUID,
 			"url":      cfg.LokiURL,
 			"access":   "proxy",
 			"editable": true,
-			"jsonData": map[string]interface{}{
-				"maxLines": 1000,
+			"jsonData": jsonData,
+		})
+	}
+
+	// Tempo data source
+	if cfg.TempoURL != "" {
+		jsonData := map[string]interface{}{
+			"nodeGraph": map[string]interface{}{
+				"enabled": true,
 			},
+		}
+		if cfg.LokiURL != "" {
+			// Jump from a span to the logs of the pod that emitted it
+			jsonData["tracesToLogsV2"] = map[string]interface{}{
+				"datasourceUid":      lokiDatasourceUID,
+				"filterByTraceID":    true,
+				"spanStartTimeShift": "-5m",
+				"spanEndTimeShift":   "5m",
+				"tags": []map[string]interface{}{
+					{"key": "k8s.namespace.name", "value": "namespace"},
+					{"key": "k8s.pod.name", "value": "pod"},
+				},
+			}
+			jsonData["lokiSearch"] = map[string]interface{}{
+				"datasourceUid": lokiDatasourceUID,
+			}
+		}
+		if cfg.PrometheusURL != "" {
+			jsonData["serviceMap"] = map[string]interface{}{
+				"datasourceUid": prometheusDatasourceUID,
+			}
+		}
+		datasources = append(datasources, map[string]interface{}{
+			"name":     "Tempo",
+			"type":     "tempo",
+			"uid":      tempoDatasourceUID,
+			"url":      cfg.TempoURL,
+			"access":   "proxy",
+			"editable": true,
+			"jsonData": jsonData,
 		})
 	}
 
@@ -2,6 +2,7 @@ package grafana
 
 import (
 	"context"
+	"regexp"
 	"testing"
 	"time"
 
@@ -323,6 +324,7 @@ func TestBuildDatasources_Default(t *testing.T) {
 	assert.Equal(t, "Loki", dsList[1]["name"])
 	assert.Equal(t, "loki", dsList[1]["type"])
 	assert.Equal(t, cfg.LokiURL, dsList[1]["url"])
+	assert.NotContains(t, dsList[1]["jsonData"], "derivedFields", "no trace links without Tempo")
 }
 
 func TestBuildDatasources_OnlyPrometheus(t *testing.T) {
@@ -377,6 +379,46 @@ func TestBuildDatasources_NoDatasources(t *testing.T) {
 	assert.Len(t, dsList, 0)
 }
 
+func TestBuildDatasources_WithTempo(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TempoURL = "http://tempo.monitoring.svc.cluster.local:3200"
+
+	dsYaml := buildDatasources(cfg)["datasources.yaml"].(map[string]interface{})
+	dsList := dsYaml["datasources"].([]map[string]interface{})
+	require.Len(t, dsList, 3)
+
+	tempo := dsList[2]
+	assert.Equal(t, "Tempo", tempo["name"])
+	assert.Equal(t, "tempo", tempo["type"])
+	assert.Equal(t, "tempo", tempo["uid"])
+	assert.Equal(t, cfg.TempoURL, tempo["url"])
+
+	jsonData := tempo["jsonData"].(map[string]interface{})
+	tracesToLogs := jsonData["tracesToLogsV2"].(map[string]interface{})
+	assert.Equal(t, dsList[1]["uid"], tracesToLogs["datasourceUid"], "trace-to-logs points at the Loki datasource")
+	assert.Equal(t, true, tracesToLogs["filterByTraceID"])
+	assert.Equal(t, dsList[0]["uid"], jsonData["serviceMap"].(map[string]interface{})["datasourceUid"])
+
+	// Loki links trace ids back to Tempo
+	derived := dsList[1]["jsonData"].(map[string]interface{})["derivedFields"].([]map[string]interface{})
+	require.Len(t, derived, 1)
+	assert.Equal(t, "tempo", derived[0]["datasourceUid"])
+	re := regexp.MustCompile(derived[0]["matcherRegex"].(string))
+	assert.Equal(t, "4bf92f3577b34da6", re.FindStringSubmatch(`level=info traceID=4bf92f3577b34da6 msg="done"`)[1])
+	assert.Equal(t, "abc123", re.FindStringSubmatch(`{"trace_id":"abc123"}`)[1])
+}
+
+func TestBuildDatasources_TempoWithoutLoki(t *testing.T) {
+	cfg := &Config{TempoURL: "http://tempo:3200"}
+
+	dsYaml := buildDatasources(cfg)["datasources.yaml"].(map[string]interface{})
+	dsList := dsYaml["datasources"].([]map[string]interface{})
+	require.Len(t, dsList, 1)
+	jsonData := dsList[0]["jsonData"].(map[string]interface{})
+	assert.NotContains(t, jsonData, "tracesToLogsV2")
+	assert.NotContains(t, jsonData, "serviceMap")
+}
+
 func TestVerifyInstallation_Success(t *testing.T) {
 	k8sClient := &mockK8sClient{
 		pods: []*k8s.Pod{
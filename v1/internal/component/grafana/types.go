@@ -35,6 +35,9 @@ type Config struct {
 	// LokiURL is the Loki data source URL
 	LokiURL string `json:"loki_url" yaml:"loki_url"`
 
+	// TempoURL is the Tempo data source URL (empty to leave out tracing)
+	TempoURL string `json:"tempo_url" yaml:"tempo_url"`
+
 	// IngressEnabled enables Ingress for Grafana
 	IngressEnabled bool `json:"ingress_enabled" yaml:"ingress_enabled"`
 
@@ -223,6 +226,10 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 		config.LokiURL = lokiURL
 	}
 
+	if tempoURL, ok := cfg.GetString("tempo_url"); ok {
+		config.TempoURL = tempoURL
+	}
+
 	if ingressEnabled, ok := cfg.GetBool("ingress_enabled"); ok {
 		config.IngressEnabled = ingressEnabled
 	}
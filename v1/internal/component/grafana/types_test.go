@@ -22,6 +22,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "5Gi", config.StorageSize)
 	assert.Equal(t, "http://kube-prometheus-stack-prometheus.monitoring.svc.cluster.local:9090", config.PrometheusURL)
 	assert.Equal(t, "http://loki-gateway.loki.svc.cluster.local:80", config.LokiURL)
+	assert.Empty(t, config.TempoURL)
 	assert.False(t, config.IngressEnabled)
 	assert.True(t, config.DefaultDashboardsEnabled)
 	assert.True(t, config.SidecarEnabled)
@@ -49,6 +50,7 @@ func TestParseConfig_CustomValues(t *testing.T) {
 		"storage_size":               "20Gi",
 		"prometheus_url":             "http://custom-prometheus:9090",
 		"loki_url":                   "http://custom-loki:3100",
+		"tempo_url":                  "http://custom-tempo:3200",
 		"ingress_enabled":            true,
 		"ingress_host":               "grafana.example.com",
 		"default_dashboards_enabled": false,
@@ -66,6 +68,7 @@ func TestParseConfig_CustomValues(t *testing.T) {
 	assert.Equal(t, "20Gi", config.StorageSize)
 	assert.Equal(t, "http://custom-prometheus:9090", config.PrometheusURL)
 	assert.Equal(t, "http://custom-loki:3100", config.LokiURL)
+	assert.Equal(t, "http://custom-tempo:3200", config.TempoURL)
 	assert.True(t, config.IngressEnabled)
 	assert.Equal(t, "grafana.example.com", config.IngressHost)
 	assert.False(t, config.DefaultDashboardsEnabled)
@@ -0,0 +1,108 @@
+package hostlogs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// alloyConfigTemplate is the Grafana Alloy configuration template. It reads
+// the host's systemd journal and pushes it to Loki.
+const alloyConfigTemplate = `// Grafana Alloy host log agent
+// Generated by Foundry
+
+// Journal fields become labels: the systemd unit, the syslog identifier
+// and the priority (info, warning, err, ...)
+loki.relabel "journal" {
+  forward_to = []
+
+  rule {
+    source_labels = ["__journal__systemd_unit"]
+    target_label  = "unit"
+  }
+
+  rule {
+    source_labels = ["__journal_syslog_identifier"]
+    target_label  = "identifier"
+  }
+
+  rule {
+    source_labels = ["__journal_priority_keyword"]
+    target_label  = "level"
+  }
+}
+
+loki.source.journal "host" {
+  max_age       = {{quote .MaxAge}}
+  relabel_rules = loki.relabel.journal.rules
+  labels        = {
+{{- range .Labels}}
+    {{.Key}} = {{quote .Value}},
+{{- end}}
+  }
+  forward_to    = [loki.write.default.receiver]
+}
+
+loki.write "default" {
+  endpoint {
+    url = {{quote .LokiURL}}
+{{- if .CAFile}}
+
+    tls_config {
+      ca_file = {{quote .CAFile}}
+    }
+{{- end}}
+  }
+}
+`
+
+// label is one static stream label, in a stable order
+type label struct {
+	Key   string
+	Value string
+}
+
+// GenerateConfig generates the Alloy configuration for a host
+func GenerateConfig(cfg *Config) (string, error) {
+	tmpl, err := template.New("alloy").Funcs(template.FuncMap{
+		"quote": strconv.Quote,
+	}).Parse(alloyConfigTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// job identifies the agent's streams; the configured labels may not
+	// override it
+	labels := []label{{Key: "job", Value: "systemd-journal"}}
+	keys := make([]string, 0, len(cfg.Labels))
+	for key := range cfg.Labels {
+		if key != "job" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		labels = append(labels, label{Key: key, Value: cfg.Labels[key]})
+	}
+
+	data := struct {
+		*Config
+		Labels []label
+		CAFile string
+	}{
+		Config: cfg,
+		Labels: labels,
+	}
+	if cfg.CACert != "" {
+		data.CAFile = containerConfigDir + "/ca.crt"
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
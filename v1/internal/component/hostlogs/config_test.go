@@ -0,0 +1,35 @@
+package hostlogs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfig(t *testing.T) {
+	content, err := GenerateConfig(testConfig())
+	require.NoError(t, err)
+
+	assert.Contains(t, content, `loki.source.journal "host"`)
+	assert.Contains(t, content, `max_age       = "12h"`)
+	assert.Contains(t, content, `source_labels = ["__journal__systemd_unit"]`)
+	assert.Contains(t, content, `target_label  = "unit"`)
+	assert.Contains(t, content, "    job = \"systemd-journal\",\n    host = \"infra1\",\n    role = \"openbao,dns\",\n")
+	assert.Contains(t, content, `url = "https://loki.example.com/loki/api/v1/push"`)
+	assert.Contains(t, content, `ca_file = "/etc/alloy/ca.crt"`)
+}
+
+func TestGenerateConfig_NoCA(t *testing.T) {
+	cfg := testConfig()
+	cfg.CACert = ""
+	cfg.Labels = map[string]string{"job": "mine", "host": `we"ird`}
+
+	content, err := GenerateConfig(cfg)
+	require.NoError(t, err)
+
+	assert.NotContains(t, content, "tls_config")
+	assert.Contains(t, content, `job = "systemd-journal"`)
+	assert.NotContains(t, content, `"mine"`)
+	assert.Contains(t, content, `host = "we\"ird"`)
+}
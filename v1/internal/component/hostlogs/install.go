@@ -0,0 +1,181 @@
+package hostlogs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/catalystcommunity/foundry/v1/internal/systemd"
+)
+
+const (
+	// serviceName is the systemd unit and container name
+	serviceName = "foundry-host-logs"
+
+	// configDir holds config.alloy and the CA certificate on the host
+	configDir = "/etc/foundry-host-logs"
+
+	// dataDir keeps the agent's journal position across restarts, so
+	// lines are not pushed twice
+	dataDir = "/var/lib/foundry-host-logs"
+
+	// containerConfigDir is where configDir is mounted in the container
+	containerConfigDir = "/etc/alloy"
+)
+
+// journalDirs are the persistent and volatile journal locations. Only the
+// ones present on a host are mounted.
+var journalDirs = []string{"/var/log/journal", "/run/log/journal"}
+
+// Install installs the log agent as a containerized systemd service
+func Install(conn container.SSHExecutor, runtime container.Runtime, cfg *Config) error {
+	imageName := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
+	if err := runtime.Pull(imageName); err != nil {
+		return fmt.Errorf("pull container image: %w", err)
+	}
+
+	if err := writeConfigFiles(conn, cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	if err := createSystemdService(conn, runtime, cfg); err != nil {
+		return fmt.Errorf("create systemd service: %w", err)
+	}
+
+	if err := systemd.EnableService(conn, serviceName); err != nil {
+		return fmt.Errorf("enable service: %w", err)
+	}
+
+	// Restart rather than start, so a reinstall picks up the new config
+	if err := systemd.RestartService(conn, serviceName); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+
+	status, err := systemd.GetServiceStatus(conn, serviceName)
+	if err != nil {
+		return fmt.Errorf("get service status: %w", err)
+	}
+
+	if !status.Active || !status.Running {
+		return fmt.Errorf("service failed to start: %s", status.SubState)
+	}
+
+	return nil
+}
+
+// writeConfigFiles writes config.alloy and, when set, the CA certificate
+// to the host
+func writeConfigFiles(conn container.SSHExecutor, cfg *Config) error {
+	configContent, err := GenerateConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	cmd := fmt.Sprintf("sudo mkdir -p %s %s && sudo chmod 755 %s", configDir, dataDir, configDir)
+	if _, err := conn.Execute(cmd); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	files := map[string]string{"config.alloy": configContent}
+	if cfg.CACert != "" {
+		files["ca.crt"] = strings.TrimSpace(cfg.CACert)
+	}
+	for _, name := range []string{"config.alloy", "ca.crt"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		writeCmd := fmt.Sprintf("sudo tee %s/%s > /dev/null << 'EOF'\n%s\nEOF", configDir, name, content)
+		if _, err := conn.Execute(writeCmd); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// createSystemdService creates the systemd service unit for the log agent
+func createSystemdService(conn container.SSHExecutor, runtime container.Runtime, cfg *Config) error {
+	runtimePath, err := detectRuntimePath(conn, runtime.Name())
+	if err != nil {
+		return fmt.Errorf("detect runtime path: %w", err)
+	}
+
+	unit := systemd.ContainerUnitFile(
+		serviceName,
+		"Foundry host log agent (Grafana Alloy)",
+		buildExecStart(runtimePath, cfg, existingJournalDirs(conn)),
+	)
+	// Cleanup makes service restarts idempotent.
+	unit.ExecStartPre = fmt.Sprintf("-%s rm -f %s", runtimePath, serviceName)
+	unit.ExecStopPost = fmt.Sprintf("-%s rm -f %s", runtimePath, serviceName)
+	unit.TimeoutStopSec = 30
+
+	if err := systemd.CreateService(conn, serviceName, unit); err != nil {
+		return fmt.Errorf("create systemd service: %w", err)
+	}
+
+	return nil
+}
+
+// existingJournalDirs returns the journal directories present on the host
+func existingJournalDirs(conn container.SSHExecutor) []string {
+	var dirs []string
+	for _, dir := range journalDirs {
+		if _, err := conn.Execute(fmt.Sprintf("test -d %s", dir)); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// detectRuntimePath finds the actual path to the container runtime executable
+func detectRuntimePath(conn container.SSHExecutor, runtimeType string) (string, error) {
+	output, err := conn.Execute(fmt.Sprintf("which %s", runtimeType))
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s: %w", runtimeType, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// buildExecStart builds the ExecStart command for the systemd service
+func buildExecStart(runtimePath string, cfg *Config, journals []string) string {
+	image := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
+
+	parts := []string{
+		fmt.Sprintf("%s run", runtimePath),
+		fmt.Sprintf("--name %s", serviceName),
+		"--security-opt apparmor=unconfined",
+	}
+	// Resolve the Loki hostname to the cluster VIP, so shipping logs doesn't
+	// depend on the host's resolver (or on PowerDNS being up)
+	if cfg.GatewayAddress != "" {
+		if u, err := url.Parse(cfg.LokiURL); err == nil {
+			parts = append(parts, fmt.Sprintf("--add-host %s:%s", u.Hostname(), cfg.GatewayAddress))
+		}
+	}
+	parts = append(parts,
+		fmt.Sprintf("-v %s:%s:ro", configDir, containerConfigDir),
+		fmt.Sprintf("-v %s:/var/lib/alloy/data", dataDir),
+		// The journal reader needs the machine ID to find the host's journal
+		"-v /etc/machine-id:/etc/machine-id:ro",
+	)
+	for _, dir := range journals {
+		parts = append(parts, fmt.Sprintf("-v %s:%s:ro", dir, dir))
+	}
+	parts = append(parts,
+		image,
+		"run",
+		containerConfigDir+"/config.alloy",
+		"--storage.path=/var/lib/alloy/data",
+		"--server.http.listen-addr=127.0.0.1:12345",
+	)
+
+	return strings.Join(parts, " ")
+}
+
+// Image returns the Grafana Alloy container image for a version
+func Image(version string) string {
+	return fmt.Sprintf("docker.io/grafana/alloy:%s", version)
+}
@@ -0,0 +1,174 @@
+package hostlogs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockExecutor implements container.SSHExecutor for testing
+type mockExecutor struct {
+	commands []string
+	errors   map[string]error
+	status   string
+}
+
+func newMockExecutor() *mockExecutor {
+	return &mockExecutor{
+		errors: make(map[string]error),
+		status: "LoadState=loaded\nActiveState=active\nSubState=running",
+	}
+}
+
+func (m *mockExecutor) Execute(cmd string) (string, error) {
+	m.commands = append(m.commands, cmd)
+	for errorCmd, err := range m.errors {
+		if strings.Contains(cmd, errorCmd) {
+			return "", err
+		}
+	}
+	switch {
+	case strings.HasPrefix(cmd, "which "):
+		return "/usr/bin/" + strings.TrimPrefix(cmd, "which "), nil
+	case strings.Contains(cmd, "systemctl show"):
+		return m.status, nil
+	}
+	return "", nil
+}
+
+func (m *mockExecutor) command(pattern string) string {
+	for _, cmd := range m.commands {
+		if strings.Contains(cmd, pattern) {
+			return cmd
+		}
+	}
+	return ""
+}
+
+// mockRuntime implements container.Runtime for testing
+type mockRuntime struct {
+	pulledImages []string
+	pullError    error
+}
+
+func (m *mockRuntime) Name() string { return "docker" }
+
+func (m *mockRuntime) Pull(image string) error {
+	if m.pullError != nil {
+		return m.pullError
+	}
+	m.pulledImages = append(m.pulledImages, image)
+	return nil
+}
+
+func (m *mockRuntime) Load(archivePath string) error { return nil }
+
+func (m *mockRuntime) Run(config container.RunConfig) (string, error) { return "id", nil }
+
+func (m *mockRuntime) Stop(containerID string, timeout time.Duration) error { return nil }
+
+func (m *mockRuntime) Remove(containerID string, force bool) error { return nil }
+
+func (m *mockRuntime) Inspect(containerID string) (*container.ContainerInfo, error) {
+	return &container.ContainerInfo{ID: containerID, State: "running"}, nil
+}
+
+func (m *mockRuntime) List(all bool) ([]container.ContainerInfo, error) { return nil, nil }
+
+func (m *mockRuntime) IsAvailable() bool { return true }
+
+func testConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.LokiURL = "https://loki.example.com/loki/api/v1/push"
+	cfg.GatewayAddress = "10.0.0.100"
+	cfg.CACert = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	cfg.Labels = map[string]string{"host": "infra1", "role": "openbao,dns"}
+	return cfg
+}
+
+func TestInstall_Success(t *testing.T) {
+	executor := newMockExecutor()
+	runtime := &mockRuntime{}
+
+	err := Install(executor, runtime, testConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"docker.io/grafana/alloy:v1.5.1"}, runtime.pulledImages)
+
+	config := executor.command("sudo tee /etc/foundry-host-logs/config.alloy")
+	require.NotEmpty(t, config)
+	assert.Contains(t, config, `url = "https://loki.example.com/loki/api/v1/push"`)
+	assert.Contains(t, config, `ca_file = "/etc/alloy/ca.crt"`)
+	assert.Contains(t, executor.command("sudo tee /etc/foundry-host-logs/ca.crt"), "BEGIN CERTIFICATE")
+
+	unit := executor.command("sudo tee /etc/systemd/system/foundry-host-logs.service")
+	require.NotEmpty(t, unit)
+	assert.Contains(t, unit, "/usr/bin/docker run --name foundry-host-logs")
+	assert.Contains(t, unit, "--add-host loki.example.com:10.0.0.100")
+	assert.Contains(t, unit, "-v /etc/foundry-host-logs:/etc/alloy:ro")
+	assert.Contains(t, unit, "-v /var/log/journal:/var/log/journal:ro")
+	assert.Contains(t, unit, "-v /run/log/journal:/run/log/journal:ro")
+	assert.Contains(t, unit, "run /etc/alloy/config.alloy --storage.path=/var/lib/alloy/data")
+	assert.Contains(t, unit, "ExecStartPre=-/usr/bin/docker rm -f foundry-host-logs")
+
+	assert.NotEmpty(t, executor.command("sudo systemctl enable foundry-host-logs"))
+	assert.NotEmpty(t, executor.command("sudo systemctl restart foundry-host-logs"))
+}
+
+func TestInstall_VolatileJournalOnly(t *testing.T) {
+	executor := newMockExecutor()
+	executor.errors["test -d /var/log/journal"] = fmt.Errorf("exit status 1")
+	cfg := testConfig()
+	cfg.CACert = ""
+	cfg.GatewayAddress = ""
+
+	require.NoError(t, Install(executor, &mockRuntime{}, cfg))
+
+	unit := executor.command("foundry-host-logs.service")
+	assert.NotContains(t, unit, "/var/log/journal")
+	assert.Contains(t, unit, "-v /run/log/journal:/run/log/journal:ro")
+	assert.NotContains(t, unit, "--add-host")
+	assert.Empty(t, executor.command("ca.crt"))
+}
+
+func TestInstall_PinnedImage(t *testing.T) {
+	executor := newMockExecutor()
+	runtime := &mockRuntime{}
+	cfg := testConfig()
+	cfg.ImageDigests = map[string]string{
+		"docker.io/grafana/alloy:v1.5.1": "sha256:abc",
+	}
+
+	require.NoError(t, Install(executor, runtime, cfg))
+	assert.Equal(t, []string{"docker.io/grafana/alloy:v1.5.1@sha256:abc"}, runtime.pulledImages)
+	assert.Contains(t, executor.command("foundry-host-logs.service"), "alloy:v1.5.1@sha256:abc")
+}
+
+func TestInstall_Errors(t *testing.T) {
+	t.Run("pull", func(t *testing.T) {
+		err := Install(newMockExecutor(), &mockRuntime{pullError: fmt.Errorf("no network")}, testConfig())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pull container image")
+	})
+
+	t.Run("not running", func(t *testing.T) {
+		executor := newMockExecutor()
+		executor.status = "LoadState=loaded\nActiveState=failed\nSubState=failed"
+		err := Install(executor, &mockRuntime{}, testConfig())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "service failed to start")
+	})
+}
+
+func TestComponent_Install_RequiresHost(t *testing.T) {
+	err := NewComponent(nil).Install(context.Background(), component.ComponentConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SSH connection not provided")
+}
@@ -0,0 +1,16 @@
+// Package hostlogs contains generated types.
+//
+// Code generated by csilgen; DO NOT EDIT.
+package hostlogs
+
+// Config represents a structured data type
+type Config struct {
+	Version          string            `json:"version" yaml:"version"`
+	LokiURL          string            `json:"loki_url" yaml:"loki_url"`
+	GatewayAddress   string            `json:"gateway_address,omitempty" yaml:"gateway_address,omitempty"`
+	CACert           string            `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	MaxAge           string            `json:"max_age" yaml:"max_age"`
+	ContainerRuntime string            `json:"container_runtime" yaml:"container_runtime"`
+	ImageDigests     map[string]string `json:"image_digests,omitempty" yaml:"image_digests,omitempty"`
+}
@@ -0,0 +1,193 @@
+package hostlogs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+)
+
+// Component implements the component.Component interface for the host log
+// agent
+type Component struct {
+	conn container.SSHExecutor
+}
+
+// NewComponent creates a new host-logs component instance
+func NewComponent(conn container.SSHExecutor) *Component {
+	return &Component{
+		conn: conn,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return "host-logs"
+}
+
+// Install installs the log agent on one host as a containerized systemd
+// service. The stack install runs it once per host.
+func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig) error {
+	conn, ok := cfg["host"].(container.SSHExecutor)
+	if !ok {
+		return fmt.Errorf("SSH connection not provided in config\n\nThis is a bug - the install command should provide a connection")
+	}
+	c.conn = conn
+
+	config, err := ParseConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	var runtime container.Runtime
+	if config.ContainerRuntime == "podman" {
+		runtime = container.NewPodmanRuntime(conn)
+	} else {
+		runtime = container.NewDockerRuntime(conn)
+	}
+
+	if !runtime.IsAvailable() {
+		return fmt.Errorf("%s runtime is not available on the host", runtime.Name())
+	}
+
+	return Install(conn, runtime, config)
+}
+
+// Upgrade reinstalls the log agent; the config and service unit are
+// rewritten
+func (c *Component) Upgrade(ctx context.Context, cfg component.ComponentConfig) error {
+	return c.Install(ctx, cfg)
+}
+
+// Status returns the current status of the log agent
+func (c *Component) Status(ctx context.Context) (*component.ComponentStatus, error) {
+	// The agent runs on every host, so there is no single status to report;
+	// each host's logs show up under job="systemd-journal" in Loki
+	return &component.ComponentStatus{
+		Installed: false,
+		Version:   "",
+		Healthy:   false,
+		Message:   "see the Host services dashboard in Grafana",
+	}, nil
+}
+
+// Uninstall removes the log agent
+func (c *Component) Uninstall(ctx context.Context) error {
+	return fmt.Errorf("uninstall not yet implemented")
+}
+
+// Dependencies returns the list of components that the log agent depends on
+func (c *Component) Dependencies() []string {
+	return []string{"loki"}
+}
+
+// DefaultConfig returns a Config with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Version:          "v1.5.1",
+		MaxAge:           "12h",
+		ContainerRuntime: "docker",
+	}
+}
+
+// ParseConfig parses a ComponentConfig into a host-logs Config
+func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
+	config := DefaultConfig()
+
+	if version, ok := cfg["version"].(string); ok && version != "" {
+		config.Version = version
+	}
+
+	if lokiURL, ok := cfg["loki_url"].(string); ok {
+		config.LokiURL = lokiURL
+	}
+
+	if address, ok := cfg["gateway_address"].(string); ok {
+		config.GatewayAddress = address
+	}
+
+	if caCert, ok := cfg["ca_cert"].(string); ok {
+		config.CACert = caCert
+	}
+
+	config.Labels = parseLabels(cfg["labels"])
+
+	if maxAge, ok := cfg["max_age"].(string); ok && maxAge != "" {
+		config.MaxAge = maxAge
+	}
+
+	if runtime, ok := cfg["container_runtime"].(string); ok && runtime != "" {
+		config.ContainerRuntime = runtime
+	}
+
+	config.ImageDigests = container.ParseImageDigests(cfg["image_digests"])
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate validates the host-logs configuration
+func (c *Config) Validate() error {
+	if c.LokiURL == "" {
+		return fmt.Errorf("loki_url is required")
+	}
+	u, err := url.Parse(c.LokiURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid loki_url %q", c.LokiURL)
+	}
+	if _, err := time.ParseDuration(c.MaxAge); err != nil {
+		return fmt.Errorf("invalid max_age %q: %w", c.MaxAge, err)
+	}
+	for key := range c.Labels {
+		if !labelNamePattern.MatchString(key) {
+			return fmt.Errorf("invalid label name %q", key)
+		}
+	}
+	return nil
+}
+
+// labelNamePattern matches valid Loki label names
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// StackSettings returns the settings every host's agent shares: Loki's
+// push URL on the gateway ingress and the cluster VIP it is served on
+func StackSettings(cfg *config.Config) component.ComponentConfig {
+	return component.ComponentConfig{
+		"loki_url":        fmt.Sprintf("https://loki.%s/loki/api/v1/push", cfg.Cluster.PrimaryDomain),
+		"gateway_address": cfg.Cluster.VIP,
+	}
+}
+
+// HostLabels returns the labels a host's log lines carry, matching the
+// labels of its metrics targets
+func HostLabels(h *host.Host) map[string]string {
+	return map[string]string{
+		"host": h.Hostname,
+		"role": h.RoleLabel(),
+	}
+}
+
+func parseLabels(raw interface{}) map[string]string {
+	switch v := raw.(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		labels := make(map[string]string, len(v))
+		for key, value := range v {
+			if s, ok := value.(string); ok {
+				labels[key] = s
+			}
+		}
+		return labels
+	}
+	return nil
+}
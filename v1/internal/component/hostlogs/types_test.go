@@ -0,0 +1,57 @@
+package hostlogs
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_Name(t *testing.T) {
+	assert.Equal(t, "host-logs", NewComponent(nil).Name())
+	assert.Equal(t, []string{"loki"}, NewComponent(nil).Dependencies())
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{
+		"loki_url":          "https://loki.example.com/loki/api/v1/push",
+		"gateway_address":   "10.0.0.100",
+		"labels":            map[string]interface{}{"host": "infra1", "role": "dns"},
+		"max_age":           "1h",
+		"container_runtime": "podman",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.5.1", cfg.Version)
+	assert.Equal(t, "10.0.0.100", cfg.GatewayAddress)
+	assert.Equal(t, map[string]string{"host": "infra1", "role": "dns"}, cfg.Labels)
+	assert.Equal(t, "1h", cfg.MaxAge)
+	assert.Equal(t, "podman", cfg.ContainerRuntime)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	tests := map[string]component.ComponentConfig{
+		"missing loki_url": {},
+		"bad loki_url":     {"loki_url": "loki:3100"},
+		"bad max_age":      {"loki_url": "http://loki", "max_age": "a while"},
+		"bad label":        {"loki_url": "http://loki", "labels": map[string]string{"host-name": "x"}},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseConfig(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStackSettings(t *testing.T) {
+	cfg := &config.Config{Cluster: config.ClusterConfig{PrimaryDomain: "example.com", VIP: "10.0.0.100"}}
+	settings := StackSettings(cfg)
+	assert.Equal(t, "https://loki.example.com/loki/api/v1/push", settings["loki_url"])
+	assert.Equal(t, "10.0.0.100", settings["gateway_address"])
+
+	labels := HostLabels(&host.Host{Hostname: "infra1", Roles: []string{host.RoleDNS, host.RoleOpenBAO}})
+	assert.Equal(t, map[string]string{"host": "infra1", "role": "openbao,dns"}, labels)
+}
@@ -0,0 +1,15 @@
+package k3s
+
+// Offline installation. `foundry stack install --bundle` stages the K3s
+// install script, the k3s binary and the airgap image archive on every
+// cluster node before the cluster is initialized. With Config.Airgap set the
+// install script runs from AirgapScriptPath with INSTALL_K3S_SKIP_DOWNLOAD,
+// so it uses the staged binary and K3s imports the staged images on start.
+const (
+	// AirgapScriptPath is where the K3s install script is staged
+	AirgapScriptPath = "/var/lib/foundry/bundle/k3s/install.sh"
+	// AirgapBinaryPath is where the install script expects the k3s binary
+	AirgapBinaryPath = "/usr/local/bin/k3s"
+	// AirgapImagesDir is where K3s looks for image archives to import
+	AirgapImagesDir = "/var/lib/rancher/k3s/agent/images"
+)
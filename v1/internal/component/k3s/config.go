@@ -14,6 +14,10 @@ const (
 	// RegistriesConfigPath is the location of the registries.yaml file
 	RegistriesConfigPath = "/etc/rancher/k3s/registries.yaml"
 
+	// RegistryCAPath is where the CA that signed the Zot certificate is
+	// written on nodes when Zot serves TLS from the internal CA
+	RegistryCAPath = "/etc/rancher/k3s/foundry-registry-ca.crt"
+
 	// OpenBAO paths for K3s configuration (relative to mount point)
 	KubeconfigOpenBAOPath = "k3s/kubeconfig"
 )
@@ -24,6 +28,13 @@ const (
 // This configures K3s to use Zot as a pull-through cache for container registries,
 // and merges any additional user-defined registry entries.
 func GenerateRegistriesYAML(zotURL string, insecure bool, additional []AdditionalRegistry) string {
+	out, _ := yaml.Marshal(buildRegistryConfig(zotURL, insecure, additional))
+	return string(out)
+}
+
+// buildRegistryConfig builds the registries.yaml structure shared by the
+// generators
+func buildRegistryConfig(zotURL string, insecure bool, additional []AdditionalRegistry) RegistryConfig {
 	rc := RegistryConfig{
 		Mirrors: RegistryMirrorMap{
 			"docker.io": RegistryMirror{Endpoint: []string{zotURL}},
@@ -82,17 +93,78 @@ func GenerateRegistriesYAML(zotURL string, insecure bool, additional []Additiona
 		}
 	}
 
-	out, _ := yaml.Marshal(rc)
-	return string(out)
+	return rc
 }
 
 // GenerateRegistriesConfig generates registries.yaml content for Zot registry
 // This is a convenience wrapper around GenerateRegistriesYAML that assumes
 // insecure connections (common for local development)
 func GenerateRegistriesConfig(zotAddr string, additional []AdditionalRegistry) string {
-	// Format the Zot address as a URL with port
-	zotURL := fmt.Sprintf("http://%s:5000", zotAddr)
-	return GenerateRegistriesYAML(zotURL, true, additional)
+	return GenerateZotRegistriesConfig(ZotRegistry{Address: zotAddr}, additional)
+}
+
+// ZotRegistry describes how nodes reach the Foundry Zot registry
+type ZotRegistry struct {
+	Address string
+	Port    int // defaults to 5000
+	// TLS switches the mirror endpoint to https. CAFile is set when the
+	// certificate is not signed by a publicly trusted CA.
+	TLS    bool
+	CAFile string
+	// Username and Password are the cluster's robot account when Zot
+	// requires authentication
+	Username string
+	Password string
+	// Mirrors are upstream registries beyond docker.io and ghcr.io whose
+	// images Zot serves, such as the quay.io images an offline bundle pushes
+	Mirrors []string
+}
+
+// GenerateZotRegistriesConfig generates registries.yaml content for Zot,
+// including credentials and TLS trust when the registry requires them
+func GenerateZotRegistriesConfig(zot ZotRegistry, additional []AdditionalRegistry) string {
+	port := zot.Port
+	if port == 0 {
+		port = 5000
+	}
+	scheme := "http"
+	if zot.TLS {
+		scheme = "https"
+	}
+	zotURL := fmt.Sprintf("%s://%s:%d", scheme, zot.Address, port)
+
+	insecure := !zot.TLS && zot.Username == ""
+	rc := buildRegistryConfig(zotURL, insecure, additional)
+	for _, mirror := range zot.Mirrors {
+		// User-defined additional registries take precedence
+		if _, exists := rc.Mirrors[mirror]; !exists {
+			rc.Mirrors[mirror] = RegistryMirror{Endpoint: []string{zotURL}}
+		}
+	}
+	if insecure {
+		out, _ := yaml.Marshal(rc)
+		return string(out)
+	}
+
+	// K3s matches configs by registry host, so the entry is keyed by host:port
+	auth := RegistryAuth{}
+	if zot.TLS {
+		if zot.CAFile != "" {
+			caFile := zot.CAFile
+			auth.Tls = &RegistryTLSConfig{CaFile: &caFile}
+		}
+	} else {
+		insecureTrue := true
+		auth.Tls = &RegistryTLSConfig{InsecureSkipVerify: &insecureTrue}
+	}
+	if zot.Username != "" {
+		username, password := zot.Username, zot.Password
+		auth.Auth = &RegistryAuthConfig{Username: &username, Password: &password}
+	}
+	rc.Configs[fmt.Sprintf("%s:%d", zot.Address, port)] = auth
+
+	out, _ := yaml.Marshal(rc)
+	return string(out)
 }
 
 // GenerateK3sServerFlags generates the command-line flags for K3s server installation
@@ -136,6 +208,10 @@ func GenerateK3sServerFlags(cfg *Config) []string {
 		}
 	}
 
+	// Serve embedded etcd metrics on port 2381 of the node address, so
+	// Prometheus can alert on etcd health
+	flags = append(flags, "--etcd-expose-metrics")
+
 	// Etcd args (for tuning etcd performance in virtualized environments)
 	// These are passed as --etcd-arg=<arg> to K3s
 	if len(cfg.EtcdArgs) > 0 {
@@ -151,6 +227,9 @@ func GenerateK3sServerFlags(cfg *Config) []string {
 func GenerateK3sInstallCommand(cfg *Config) string {
 	// Base installation command
 	baseCmd := "curl -sfL https://get.k3s.io | sh -s - server"
+	if cfg.Airgap {
+		baseCmd = fmt.Sprintf("INSTALL_K3S_SKIP_DOWNLOAD=true sh %s server", AirgapScriptPath)
+	}
 
 	// Add flags
 	flags := GenerateK3sServerFlags(cfg)
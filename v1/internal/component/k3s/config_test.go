@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestGenerateRegistriesYAML(t *testing.T) {
@@ -109,6 +111,60 @@ func TestGenerateRegistriesConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateZotRegistriesConfig(t *testing.T) {
+	t.Run("tls with credentials", func(t *testing.T) {
+		got := GenerateZotRegistriesConfig(ZotRegistry{
+			Address:  "10.0.0.11",
+			TLS:      true,
+			CAFile:   RegistryCAPath,
+			Username: "k3s",
+			Password: "secret",
+		}, nil)
+
+		var rc RegistryConfig
+		require.NoError(t, yaml.Unmarshal([]byte(got), &rc))
+		assert.Equal(t, []string{"https://10.0.0.11:5000"}, rc.Mirrors["docker.io"].Endpoint)
+		auth, ok := rc.Configs["10.0.0.11:5000"]
+		require.True(t, ok)
+		require.NotNil(t, auth.Auth)
+		assert.Equal(t, "k3s", *auth.Auth.Username)
+		assert.Equal(t, "secret", *auth.Auth.Password)
+		require.NotNil(t, auth.Tls)
+		assert.Equal(t, RegistryCAPath, *auth.Tls.CaFile)
+		assert.Nil(t, auth.Tls.InsecureSkipVerify)
+	})
+
+	t.Run("http with credentials", func(t *testing.T) {
+		got := GenerateZotRegistriesConfig(ZotRegistry{Address: "10.0.0.11", Username: "k3s", Password: "secret"}, nil)
+
+		var rc RegistryConfig
+		require.NoError(t, yaml.Unmarshal([]byte(got), &rc))
+		assert.Equal(t, []string{"http://10.0.0.11:5000"}, rc.Mirrors["ghcr.io"].Endpoint)
+		auth := rc.Configs["10.0.0.11:5000"]
+		require.NotNil(t, auth.Auth)
+		require.NotNil(t, auth.Tls)
+		assert.True(t, *auth.Tls.InsecureSkipVerify)
+	})
+
+	t.Run("plain matches legacy output", func(t *testing.T) {
+		assert.Equal(t, GenerateRegistriesConfig("10.0.0.11", nil), GenerateZotRegistriesConfig(ZotRegistry{Address: "10.0.0.11"}, nil))
+		assert.Equal(t, GenerateRegistriesYAML("http://10.0.0.11:5000", true, nil), GenerateZotRegistriesConfig(ZotRegistry{Address: "10.0.0.11"}, nil))
+	})
+
+	t.Run("extra mirrors point at zot", func(t *testing.T) {
+		got := GenerateZotRegistriesConfig(ZotRegistry{
+			Address: "10.0.0.11",
+			Mirrors: []string{"quay.io", "registry.k8s.io"},
+		}, []AdditionalRegistry{{Name: "registry.k8s.io", Endpoint: strPtr("https://mirror.example.com")}})
+
+		var rc RegistryConfig
+		require.NoError(t, yaml.Unmarshal([]byte(got), &rc))
+		assert.Equal(t, []string{"http://10.0.0.11:5000"}, rc.Mirrors["quay.io"].Endpoint)
+		assert.Equal(t, []string{"https://mirror.example.com"}, rc.Mirrors["registry.k8s.io"].Endpoint)
+		assert.Equal(t, []string{"http://10.0.0.11:5000"}, rc.Mirrors["docker.io"].Endpoint)
+	})
+}
+
 func strPtr(s string) *string { return &s }
 func boolPtr(b bool) *bool    { return &b }
 
@@ -318,6 +374,7 @@ func TestGenerateK3sServerFlags(t *testing.T) {
 				"--tls-san 192.168.1.100",
 				"--disable=traefik",
 				"--disable=servicelb",
+				"--etcd-expose-metrics",
 			},
 		},
 		{
@@ -380,6 +437,27 @@ func TestGenerateK3sInstallCommand(t *testing.T) {
 	assert.Contains(t, got, "--disable=traefik")
 }
 
+func TestGenerateK3sInstallCommand_Airgap(t *testing.T) {
+	cfg := &Config{
+		VIP:          "192.168.1.100",
+		ClusterInit:  true,
+		ClusterToken: "test-token",
+		Airgap:       true,
+	}
+
+	got := GenerateK3sInstallCommand(cfg)
+	assert.True(t, strings.HasPrefix(got, "INSTALL_K3S_SKIP_DOWNLOAD=true sh "+AirgapScriptPath+" server "))
+	assert.NotContains(t, got, "get.k3s.io")
+	assert.Contains(t, got, "--cluster-init")
+}
+
+func TestParseMirrorRegistries(t *testing.T) {
+	assert.Nil(t, ParseMirrorRegistries(map[string]any{}))
+	assert.Equal(t, []string{"quay.io", "registry.k8s.io"}, ParseMirrorRegistries(map[string]any{
+		"mirror_registries": []interface{}{"quay.io", "", "registry.k8s.io"},
+	}))
+}
+
 func TestGenerateResolvConfContent(t *testing.T) {
 	tests := []struct {
 		name          string
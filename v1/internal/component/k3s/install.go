@@ -297,11 +297,12 @@ func setupKubeVIP(ctx context.Context, executor SSHExecutor, cfg *Config) error
 		VIP:           cfg.VIP,
 		Interface:     cfg.Interface,
 		AllowCGNATVIP: cfg.AllowCGNATVIP,
+		ImageDigests:  cfg.ImageDigests,
 	}
 
 	// Generate kube-vip manifests
 	rbacManifest := GenerateKubeVIPRBACManifest()
-	cloudProviderManifest := GenerateKubeVIPCloudProviderManifest()
+	cloudProviderManifest := GenerateKubeVIPCloudProviderManifest(cfg.ImageDigests)
 	configMapManifest, err := GenerateKubeVIPConfigMap(fmt.Sprintf("%s/32", cfg.VIP))
 	if err != nil {
 		return fmt.Errorf("failed to generate kube-vip configmap: %w", err)
@@ -19,6 +19,9 @@ type Config struct {
 	AdditionalRegistries []AdditionalRegistry `json:"additional_registries,omitempty" yaml:"additional_registries,omitempty"`
 	EtcdArgs             []string             `json:"etcd_args,omitempty" yaml:"etcd_args,omitempty"`
 	AllowCGNATVIP        *bool                `json:"allow_cgnat_vip,omitempty" yaml:"allow_cgnat_vip,omitempty"`
+	MirrorRegistries     []string             `json:"mirror_registries,omitempty" yaml:"mirror_registries,omitempty"`
+	Airgap               bool                 `json:"airgap" yaml:"airgap"`
+	ImageDigests         map[string]string    `json:"image_digests,omitempty" yaml:"image_digests,omitempty"`
 }
 
 // AdditionalRegistry represents a structured data type
@@ -62,7 +65,8 @@ type RegistryAuthConfig struct {
 
 // RegistryTLSConfig represents a structured data type
 type RegistryTLSConfig struct {
-	InsecureSkipVerify *bool `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+	InsecureSkipVerify *bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+	CaFile             *string `json:"ca_file,omitempty" yaml:"ca_file,omitempty"`
 }
 
 // K3sClusterToken represents a structured data type
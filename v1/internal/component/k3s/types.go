@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
 )
 
 // Component implements the component.Component interface for K3s
@@ -119,6 +120,19 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 		config.EtcdArgs = etcdArgs
 	}
 
+	// Mirror registries (further upstreams served by Zot)
+	if mirrors, ok := cfg.GetStringSlice("mirror_registries"); ok {
+		config.MirrorRegistries = mirrors
+	}
+
+	// Image digests pinned by the stack lockfile
+	config.ImageDigests = container.ParseImageDigests(cfg["image_digests"])
+
+	// Airgap (install from artifacts staged by an offline bundle)
+	if airgap, ok := cfg.GetBool("airgap"); ok {
+		config.Airgap = airgap
+	}
+
 	// Additional registries
 	if raw, ok := cfg.Get("additional_registries"); ok {
 		if registries, ok := raw.([]interface{}); ok {
@@ -193,6 +207,23 @@ func ParseAdditionalRegistries(raw map[string]any) []AdditionalRegistry {
 	return result
 }
 
+// ParseMirrorRegistries returns the mirror_registries entry of a raw config
+// map: upstream registries, beyond docker.io and ghcr.io, whose images nodes
+// pull through Zot
+func ParseMirrorRegistries(raw map[string]any) []string {
+	val, ok := raw["mirror_registries"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var result []string
+	for _, entry := range val {
+		if name, ok := entry.(string); ok && name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
 // Validate validates the K3s configuration
 func (c *Config) Validate() error {
 	if c.VIP == "" {
@@ -5,6 +5,7 @@ import (
 	"net"
 	"strings"
 
+	"github.com/catalystcommunity/foundry/v1/internal/container"
 	"github.com/catalystcommunity/foundry/v1/internal/network"
 	"github.com/catalystcommunity/foundry/v1/internal/ssh"
 )
@@ -16,6 +17,8 @@ type VIPConfig struct {
 	// AllowCGNATVIP is *bool (not bool) because it's optional in CSIL-generated Config.
 	// Pointer allows nil (not set) vs false (explicitly disabled). Defaults to false if nil.
 	AllowCGNATVIP *bool
+	// ImageDigests pins the kube-vip image to the digest in the stack lockfile
+	ImageDigests map[string]string
 }
 
 // SSHExecutor is an interface for executing SSH commands
@@ -114,6 +117,12 @@ func DetermineVIPConfig(vip string, conn network.SSHExecutor, allowCGNAT bool) (
 	return cfg, nil
 }
 
+// kube-vip images deployed with the control plane
+const (
+	KubeVIPImage              = "ghcr.io/kube-vip/kube-vip:v0.6.4"
+	KubeVIPCloudProviderImage = "ghcr.io/kube-vip/kube-vip-cloud-provider:v0.0.4"
+)
+
 // GenerateKubeVIPManifest generates the kube-vip DaemonSet manifest YAML
 // This manifest is deployed to the cluster to enable VIP functionality
 func GenerateKubeVIPManifest(cfg *VIPConfig) (string, error) {
@@ -157,7 +166,7 @@ spec:
     spec:
       containers:
       - name: kube-vip
-        image: ghcr.io/kube-vip/kube-vip:v0.6.4
+        image: %s
         imagePullPolicy: IfNotPresent
         args:
         - manager
@@ -215,7 +224,7 @@ spec:
         hostPath:
           path: /etc/rancher/k3s/k3s.yaml
           type: FileOrCreate
-`, cfg.Interface, cfg.VIP)
+`, container.PinImage(KubeVIPImage, cfg.ImageDigests), cfg.Interface, cfg.VIP)
 
 	return manifest, nil
 }
@@ -262,8 +271,9 @@ subjects:
 }
 
 // GenerateKubeVIPCloudProviderManifest generates the cloud provider manifest for kube-vip
-// This enables LoadBalancer service support
-func GenerateKubeVIPCloudProviderManifest() string {
+// This enables LoadBalancer service support. imageDigests pins the image to
+// the digest in the stack lockfile.
+func GenerateKubeVIPCloudProviderManifest(imageDigests map[string]string) string {
 	return `apiVersion: v1
 kind: ServiceAccount
 metadata:
@@ -324,7 +334,7 @@ spec:
     spec:
       containers:
       - name: kube-vip-cloud-provider
-        image: ghcr.io/kube-vip/kube-vip-cloud-provider:v0.0.4
+        image: ` + container.PinImage(KubeVIPCloudProviderImage, imageDigests) + `
         imagePullPolicy: IfNotPresent
         command:
         - /kube-vip-cloud-provider
@@ -402,7 +402,7 @@ func TestGenerateKubeVIPRBACManifest(t *testing.T) {
 
 // TestGenerateKubeVIPCloudProviderManifest tests cloud provider manifest generation
 func TestGenerateKubeVIPCloudProviderManifest(t *testing.T) {
-	manifest := GenerateKubeVIPCloudProviderManifest()
+	manifest := GenerateKubeVIPCloudProviderManifest(nil)
 
 	assert.NotEmpty(t, manifest)
 	assert.Contains(t, manifest, "kind: ServiceAccount")
@@ -411,6 +411,10 @@ func TestGenerateKubeVIPCloudProviderManifest(t *testing.T) {
 	assert.Contains(t, manifest, "ghcr.io/kube-vip/kube-vip-cloud-provider")
 	assert.Contains(t, manifest, "kind: ClusterRole")
 	assert.Contains(t, manifest, "kind: ClusterRoleBinding")
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	pinned := GenerateKubeVIPCloudProviderManifest(map[string]string{KubeVIPCloudProviderImage: digest})
+	assert.Contains(t, pinned, "image: "+KubeVIPCloudProviderImage+"@"+digest)
 }
 
 // TestGenerateKubeVIPConfigMap tests ConfigMap generation
@@ -725,7 +729,7 @@ func TestVIPConfigIntegration(t *testing.T) {
 	rbacManifest := GenerateKubeVIPRBACManifest()
 	assert.NotEmpty(t, rbacManifest)
 
-	cpManifest := GenerateKubeVIPCloudProviderManifest()
+	cpManifest := GenerateKubeVIPCloudProviderManifest(nil)
 	assert.NotEmpty(t, cpManifest)
 
 	cmManifest, err := GenerateKubeVIPConfigMap("192.168.1.100/32")
@@ -85,6 +85,9 @@ func JoinWorker(ctx context.Context, executor SSHExecutor, serverURL string, tok
 
 	// Step 3: Install K3s in agent mode
 	installCmd := generateK3sAgentInstallCommand(serverURL, tokens.AgentToken)
+	if cfg.Airgap {
+		installCmd = generateK3sAgentAirgapInstallCommand(serverURL, tokens.AgentToken)
+	}
 	result, err := executor.Exec(installCmd)
 	if err != nil {
 		return fmt.Errorf("failed to execute K3s agent install command: %w", err)
@@ -113,6 +116,12 @@ func generateK3sAgentInstallCommand(serverURL string, agentToken string) string
 	return fmt.Sprintf("curl -sfL https://get.k3s.io | K3S_URL=%s K3S_TOKEN=%s sh -", serverURL, agentToken)
 }
 
+// generateK3sAgentAirgapInstallCommand runs the staged install script
+// against the staged k3s binary instead of downloading either
+func generateK3sAgentAirgapInstallCommand(serverURL string, agentToken string) string {
+	return fmt.Sprintf("INSTALL_K3S_SKIP_DOWNLOAD=true K3S_URL=%s K3S_TOKEN=%s sh %s", serverURL, agentToken, AirgapScriptPath)
+}
+
 // waitForK3sAgentReady waits for K3s agent to be ready
 // Agent nodes don't have kubectl, so we check the service status instead
 func waitForK3sAgentReady(executor SSHExecutor, retryCfg RetryConfig) error {
@@ -302,6 +302,11 @@ func TestGenerateK3sAgentInstallCommand(t *testing.T) {
 	}
 }
 
+func TestGenerateK3sAgentAirgapInstallCommand(t *testing.T) {
+	got := generateK3sAgentAirgapInstallCommand("https://10.0.0.1:6443", "agent-token")
+	assert.Equal(t, "INSTALL_K3S_SKIP_DOWNLOAD=true K3S_URL=https://10.0.0.1:6443 K3S_TOKEN=agent-token sh /var/lib/foundry/bundle/k3s/install.sh", got)
+}
+
 func TestWaitForK3sAgentReady(t *testing.T) {
 	// Use fast retry config for tests
 	fastRetryCfg := RetryConfig{
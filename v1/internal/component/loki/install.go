@@ -14,6 +14,9 @@ const (
 	lokiChart     = "grafana/loki"
 	promtailChart = "grafana/promtail"
 	releaseName   = "loki"
+
+	// promtailVersion is the Promtail chart version compatible with Loki
+	promtailVersion = "6.16.6"
 )
 
 // Install installs Loki using Helm
@@ -147,7 +150,7 @@ func installPromtail(ctx context.Context, helmClient HelmClient, k8sClient K8sCl
 		ReleaseName:     "promtail",
 		Namespace:       cfg.Namespace,
 		Chart:           promtailChart,
-		Version:         "6.16.6", // Compatible Promtail version
+		Version:         promtailVersion,
 		Values:          promtailValues,
 		CreateNamespace: false, // Already created by Loki
 		Wait:            true,
@@ -172,6 +175,31 @@ func serviceMonitorCRDAvailable(ctx context.Context, k8sClient K8sClient) bool {
 	return exists
 }
 
+// Charts returns the Helm charts Install uses for Loki and, when enabled,
+// Promtail
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	charts := []helm.ChartSource{{
+		RepoName: lokiRepoName,
+		RepoURL:  lokiRepoURL,
+		Chart:    lokiChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+	if cfg.PromtailEnabled {
+		charts = append(charts, helm.ChartSource{
+			RepoName: lokiRepoName,
+			RepoURL:  lokiRepoURL,
+			Chart:    promtailChart,
+			Version:  promtailVersion,
+			Values:   buildPromtailValues(cfg),
+		})
+	}
+	return charts
+}
+
 // buildHelmValues constructs Helm values for Loki installation
 func buildHelmValues(cfg *Config) map[string]interface{} {
 	values := make(map[string]interface{})
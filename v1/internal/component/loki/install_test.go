@@ -65,6 +65,7 @@ func TestInstall_WithServiceMonitorCRD(t *testing.T) {
 	helmClient := &mockHelmClient{}
 	k8sClient := &mockK8sClient{serviceMonitorCRDExists: true}
 	cfg := DefaultConfig()
+	cfg.PromtailEnabled = true
 
 	err := Install(context.Background(), helmClient, k8sClient, cfg)
 	require.NoError(t, err)
@@ -146,8 +147,8 @@ func TestInstall_NilConfig(t *testing.T) {
 	err := Install(context.Background(), helmClient, k8sClient, nil)
 	require.NoError(t, err)
 
-	// Verify installation happened with defaults (Loki + Promtail)
-	require.Len(t, helmClient.chartsInstalled, 2)
+	// Verify installation happened with defaults (Loki only; Alloy collects logs)
+	require.Len(t, helmClient.chartsInstalled, 1)
 	assert.Equal(t, releaseName, helmClient.chartsInstalled[0].ReleaseName)
 }
 
@@ -50,7 +50,9 @@ type Config struct {
 	S3SecretKey string `json:"s3_secret_key" yaml:"s3_secret_key"`
 	S3Region    string `json:"s3_region" yaml:"s3_region"`
 
-	// PromtailEnabled enables Promtail for log collection
+	// PromtailEnabled enables Promtail for log collection. Promtail is
+	// deprecated upstream; the alloy component replaces it and removes the
+	// release.
 	PromtailEnabled bool `json:"promtail_enabled" yaml:"promtail_enabled"`
 
 	// GrafanaAgentEnabled uses Grafana Agent instead of Promtail
@@ -178,7 +180,7 @@ func DefaultConfig() *Config {
 		S3Endpoint:          "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333",
 		S3Bucket:            "loki",
 		S3Region:            "us-east-1",
-		PromtailEnabled:     true,
+		PromtailEnabled:     false, // Superseded by the alloy component
 		GrafanaAgentEnabled: false,
 		IngressEnabled:      false,
 		Values:              make(map[string]interface{}),
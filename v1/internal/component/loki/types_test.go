@@ -24,7 +24,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333", config.S3Endpoint)
 	assert.Equal(t, "loki", config.S3Bucket)
 	assert.Equal(t, "us-east-1", config.S3Region)
-	assert.True(t, config.PromtailEnabled)
+	assert.False(t, config.PromtailEnabled)
 	assert.False(t, config.GrafanaAgentEnabled)
 	assert.False(t, config.IngressEnabled)
 	assert.NotNil(t, config.Values)
@@ -0,0 +1,93 @@
+package nodeexporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/catalystcommunity/foundry/v1/internal/systemd"
+)
+
+// serviceName is the systemd unit and container name
+const serviceName = "foundry-node-exporter"
+
+// Install installs node_exporter as a containerized systemd service
+func Install(conn container.SSHExecutor, runtime container.Runtime, cfg *Config) error {
+	imageName := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
+	if err := runtime.Pull(imageName); err != nil {
+		return fmt.Errorf("pull container image: %w", err)
+	}
+
+	if err := createSystemdService(conn, runtime, cfg); err != nil {
+		return fmt.Errorf("create systemd service: %w", err)
+	}
+
+	if err := systemd.EnableService(conn, serviceName); err != nil {
+		return fmt.Errorf("enable service: %w", err)
+	}
+
+	// Restart rather than start, so a reinstall picks up a new image or port
+	if err := systemd.RestartService(conn, serviceName); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+
+	status, err := systemd.GetServiceStatus(conn, serviceName)
+	if err != nil {
+		return fmt.Errorf("get service status: %w", err)
+	}
+
+	if !status.Active || !status.Running {
+		return fmt.Errorf("service failed to start: %s", status.SubState)
+	}
+
+	return nil
+}
+
+// createSystemdService creates the systemd service unit for node_exporter
+func createSystemdService(conn container.SSHExecutor, runtime container.Runtime, cfg *Config) error {
+	runtimePath, err := detectRuntimePath(conn, runtime.Name())
+	if err != nil {
+		return fmt.Errorf("detect runtime path: %w", err)
+	}
+
+	imageName := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
+
+	unit := systemd.ContainerUnitFile(
+		serviceName,
+		"Foundry Prometheus node_exporter",
+		buildExecStart(runtimePath, imageName, int(cfg.Port)),
+	)
+	// Cleanup makes service restarts idempotent.
+	unit.ExecStartPre = fmt.Sprintf("-%s rm -f %s", runtimePath, serviceName)
+	unit.ExecStopPost = fmt.Sprintf("-%s rm -f %s", runtimePath, serviceName)
+	unit.TimeoutStopSec = 30
+
+	if err := systemd.CreateService(conn, serviceName, unit); err != nil {
+		return fmt.Errorf("create systemd service: %w", err)
+	}
+
+	return nil
+}
+
+// detectRuntimePath finds the actual path to the container runtime executable
+func detectRuntimePath(conn container.SSHExecutor, runtimeType string) (string, error) {
+	output, err := conn.Execute(fmt.Sprintf("which %s", runtimeType))
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s: %w", runtimeType, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// buildExecStart builds the ExecStart command for the systemd service
+func buildExecStart(runtimePath, image string, port int) string {
+	// Host network and PID namespaces so the exporter sees the host's
+	// interfaces and processes; the root filesystem is mounted read-only
+	// for the filesystem collectors
+	return fmt.Sprintf("%s run --name %s --security-opt apparmor=unconfined --net host --pid host -v /:/host:ro,rslave %s --path.rootfs=/host --web.listen-address=:%d",
+		runtimePath, serviceName, image, port)
+}
+
+// Image returns the node_exporter container image for a version
+func Image(version string) string {
+	return fmt.Sprintf("quay.io/prometheus/node-exporter:%s", version)
+}
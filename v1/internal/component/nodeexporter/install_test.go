@@ -0,0 +1,142 @@
+package nodeexporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockExecutor implements container.SSHExecutor for testing
+type mockExecutor struct {
+	commands []string
+	errors   map[string]error
+	status   string
+}
+
+func newMockExecutor() *mockExecutor {
+	return &mockExecutor{
+		errors: make(map[string]error),
+		status: "LoadState=loaded\nActiveState=active\nSubState=running",
+	}
+}
+
+func (m *mockExecutor) Execute(cmd string) (string, error) {
+	m.commands = append(m.commands, cmd)
+	for errorCmd, err := range m.errors {
+		if strings.Contains(cmd, errorCmd) {
+			return "", err
+		}
+	}
+	switch {
+	case strings.HasPrefix(cmd, "which "):
+		return "/usr/bin/" + strings.TrimPrefix(cmd, "which "), nil
+	case strings.Contains(cmd, "systemctl show"):
+		return m.status, nil
+	}
+	return "", nil
+}
+
+func (m *mockExecutor) command(pattern string) string {
+	for _, cmd := range m.commands {
+		if strings.Contains(cmd, pattern) {
+			return cmd
+		}
+	}
+	return ""
+}
+
+// mockRuntime implements container.Runtime for testing
+type mockRuntime struct {
+	pulledImages []string
+	pullError    error
+}
+
+func (m *mockRuntime) Name() string { return "docker" }
+
+func (m *mockRuntime) Pull(image string) error {
+	if m.pullError != nil {
+		return m.pullError
+	}
+	m.pulledImages = append(m.pulledImages, image)
+	return nil
+}
+
+func (m *mockRuntime) Load(archivePath string) error { return nil }
+
+func (m *mockRuntime) Run(config container.RunConfig) (string, error) { return "id", nil }
+
+func (m *mockRuntime) Stop(containerID string, timeout time.Duration) error { return nil }
+
+func (m *mockRuntime) Remove(containerID string, force bool) error { return nil }
+
+func (m *mockRuntime) Inspect(containerID string) (*container.ContainerInfo, error) {
+	return &container.ContainerInfo{ID: containerID, State: "running"}, nil
+}
+
+func (m *mockRuntime) List(all bool) ([]container.ContainerInfo, error) { return nil, nil }
+
+func (m *mockRuntime) IsAvailable() bool { return true }
+
+func TestInstall_Success(t *testing.T) {
+	executor := newMockExecutor()
+	runtime := &mockRuntime{}
+
+	err := Install(executor, runtime, DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"quay.io/prometheus/node-exporter:v1.8.2"}, runtime.pulledImages)
+
+	unit := executor.command("sudo tee /etc/systemd/system/foundry-node-exporter.service")
+	require.NotEmpty(t, unit)
+	assert.Contains(t, unit, "Description=Foundry Prometheus node_exporter")
+	assert.Contains(t, unit, "/usr/bin/docker run --name foundry-node-exporter")
+	assert.Contains(t, unit, "--net host --pid host -v /:/host:ro,rslave")
+	assert.Contains(t, unit, "--path.rootfs=/host --web.listen-address=:9110")
+	assert.Contains(t, unit, "ExecStartPre=-/usr/bin/docker rm -f foundry-node-exporter")
+	assert.Contains(t, unit, "ExecStopPost=-/usr/bin/docker rm -f foundry-node-exporter")
+
+	assert.NotEmpty(t, executor.command("sudo systemctl enable foundry-node-exporter"))
+	assert.NotEmpty(t, executor.command("sudo systemctl restart foundry-node-exporter"))
+}
+
+func TestInstall_PinnedImage(t *testing.T) {
+	executor := newMockExecutor()
+	runtime := &mockRuntime{}
+	cfg := DefaultConfig()
+	cfg.ImageDigests = map[string]string{
+		"quay.io/prometheus/node-exporter:v1.8.2": "sha256:abc",
+	}
+
+	require.NoError(t, Install(executor, runtime, cfg))
+	assert.Equal(t, []string{"quay.io/prometheus/node-exporter:v1.8.2@sha256:abc"}, runtime.pulledImages)
+	assert.Contains(t, executor.command("foundry-node-exporter.service"), "node-exporter:v1.8.2@sha256:abc")
+}
+
+func TestInstall_Errors(t *testing.T) {
+	t.Run("pull", func(t *testing.T) {
+		err := Install(newMockExecutor(), &mockRuntime{pullError: fmt.Errorf("no network")}, DefaultConfig())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pull container image")
+	})
+
+	t.Run("not running", func(t *testing.T) {
+		executor := newMockExecutor()
+		executor.status = "LoadState=loaded\nActiveState=failed\nSubState=failed"
+		err := Install(executor, &mockRuntime{}, DefaultConfig())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "service failed to start")
+	})
+}
+
+func TestComponent_Install_RequiresHost(t *testing.T) {
+	err := NewComponent(nil).Install(context.Background(), component.ComponentConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SSH connection not provided")
+}
@@ -0,0 +1,12 @@
+// Package nodeexporter contains generated types.
+//
+// Code generated by csilgen; DO NOT EDIT.
+package nodeexporter
+
+// Config represents a structured data type
+type Config struct {
+	Version          string            `json:"version" yaml:"version"`
+	Port             int64             `json:"port" yaml:"port"`
+	ContainerRuntime string            `json:"container_runtime" yaml:"container_runtime"`
+	ImageDigests     map[string]string `json:"image_digests,omitempty" yaml:"image_digests,omitempty"`
+}
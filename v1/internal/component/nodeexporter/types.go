@@ -0,0 +1,122 @@
+package nodeexporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/container"
+)
+
+// DefaultPort is the port node_exporter listens on. Cluster nodes already
+// run the kube-prometheus-stack node-exporter DaemonSet on 9100.
+const DefaultPort = 9110
+
+// Component implements the component.Component interface for node_exporter
+type Component struct {
+	conn container.SSHExecutor
+}
+
+// NewComponent creates a new node-exporter component instance
+func NewComponent(conn container.SSHExecutor) *Component {
+	return &Component{
+		conn: conn,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return "node-exporter"
+}
+
+// Install installs node_exporter on one host as a containerized systemd
+// service. The stack install runs it once per host.
+func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig) error {
+	conn, ok := cfg["host"].(container.SSHExecutor)
+	if !ok {
+		return fmt.Errorf("SSH connection not provided in config\n\nThis is a bug - the install command should provide a connection")
+	}
+	c.conn = conn
+
+	config, err := ParseConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	var runtime container.Runtime
+	if config.ContainerRuntime == "podman" {
+		runtime = container.NewPodmanRuntime(conn)
+	} else {
+		runtime = container.NewDockerRuntime(conn)
+	}
+
+	if !runtime.IsAvailable() {
+		return fmt.Errorf("%s runtime is not available on the host", runtime.Name())
+	}
+
+	return Install(conn, runtime, config)
+}
+
+// Upgrade reinstalls node_exporter; the service unit is rewritten with the
+// new image
+func (c *Component) Upgrade(ctx context.Context, cfg component.ComponentConfig) error {
+	return c.Install(ctx, cfg)
+}
+
+// Status returns the current status of node_exporter
+func (c *Component) Status(ctx context.Context) (*component.ComponentStatus, error) {
+	// node_exporter runs on every host, so there is no single status to
+	// report; Prometheus shows each host's target under the node job
+	return &component.ComponentStatus{
+		Installed: false,
+		Version:   "",
+		Healthy:   false,
+		Message:   "see the node job in 'foundry metrics targets'",
+	}, nil
+}
+
+// Uninstall removes node_exporter
+func (c *Component) Uninstall(ctx context.Context) error {
+	return fmt.Errorf("uninstall not yet implemented")
+}
+
+// Dependencies returns the list of components that node_exporter depends on
+func (c *Component) Dependencies() []string {
+	return []string{} // Only needs the container runtime every host gets
+}
+
+// DefaultConfig returns a Config with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Version:          "v1.8.2",
+		Port:             DefaultPort,
+		ContainerRuntime: "docker",
+	}
+}
+
+// ParseConfig parses a ComponentConfig into a node-exporter Config
+func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
+	config := DefaultConfig()
+
+	if version, ok := cfg["version"].(string); ok && version != "" {
+		config.Version = version
+	}
+
+	if port, ok := cfg["port"].(int); ok {
+		config.Port = int64(port)
+	} else if portFloat, ok := cfg["port"].(float64); ok {
+		config.Port = int64(portFloat)
+	}
+
+	if runtime, ok := cfg["container_runtime"].(string); ok && runtime != "" {
+		config.ContainerRuntime = runtime
+	}
+
+	config.ImageDigests = container.ParseImageDigests(cfg["image_digests"])
+
+	if config.Port < 1 || config.Port > 65535 {
+		return nil, fmt.Errorf("invalid port %d", config.Port)
+	}
+
+	return config, nil
+}
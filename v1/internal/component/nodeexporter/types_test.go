@@ -0,0 +1,36 @@
+package nodeexporter
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_Name(t *testing.T) {
+	assert.Equal(t, "node-exporter", NewComponent(nil).Name())
+	assert.Empty(t, NewComponent(nil).Dependencies())
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg)
+	assert.Equal(t, int64(9110), cfg.Port)
+
+	cfg, err = ParseConfig(component.ComponentConfig{
+		"version":           "v1.9.0",
+		"port":              float64(9200),
+		"container_runtime": "podman",
+		"image_digests":     map[string]interface{}{"quay.io/prometheus/node-exporter:v1.9.0": "sha256:abc"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.9.0", cfg.Version)
+	assert.Equal(t, int64(9200), cfg.Port)
+	assert.Equal(t, "podman", cfg.ContainerRuntime)
+	assert.Equal(t, "sha256:abc", cfg.ImageDigests["quay.io/prometheus/node-exporter:v1.9.0"])
+
+	_, err = ParseConfig(component.ComponentConfig{"port": 70000})
+	assert.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -217,6 +218,12 @@ func (c *Client) ReadSecretV2(ctx context.Context, mount, path string) (map[stri
 	return result.Data.Data, nil
 }
 
+// IsSecretNotFound reports whether a ReadSecretV2 error means nothing is
+// stored at the path, rather than that OpenBAO could not be read
+func IsSecretNotFound(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "secret not found") || strings.Contains(err.Error(), "status code 404"))
+}
+
 // WriteSecretV2 writes a secret to the KV v2 secrets engine
 func (c *Client) WriteSecretV2(ctx context.Context, mount, path string, data map[string]interface{}) error {
 	apiPath := fmt.Sprintf("/v1/%s/data/%s", mount, path)
@@ -304,3 +311,29 @@ func (c *Client) WriteRole(ctx context.Context, authPath, roleName string, data
 
 	return readResponse(resp, nil)
 }
+
+// WritePolicy creates or replaces an ACL policy
+func (c *Client) WritePolicy(ctx context.Context, name, policy string) error {
+	apiPath := fmt.Sprintf("/v1/sys/policies/acl/%s", name)
+
+	resp, err := c.doRequest(ctx, "PUT", apiPath, map[string]interface{}{
+		"policy": policy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write policy %s: %w", name, err)
+	}
+
+	return readResponse(resp, nil)
+}
+
+// DeletePolicy removes an ACL policy
+func (c *Client) DeletePolicy(ctx context.Context, name string) error {
+	apiPath := fmt.Sprintf("/v1/sys/policies/acl/%s", name)
+
+	resp, err := c.doRequest(ctx, "DELETE", apiPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete policy %s: %w", name, err)
+	}
+
+	return readResponse(resp, nil)
+}
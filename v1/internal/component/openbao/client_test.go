@@ -348,6 +348,7 @@ func TestClient_ReadSecretV2(t *testing.T) {
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "unexpected status code 404")
+		assert.True(t, IsSecretNotFound(err))
 	})
 
 	t.Run("empty data", func(t *testing.T) {
@@ -365,6 +366,7 @@ func TestClient_ReadSecretV2(t *testing.T) {
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "secret not found at path")
+		assert.True(t, IsSecretNotFound(err))
 	})
 }
 
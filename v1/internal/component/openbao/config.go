@@ -16,16 +16,23 @@ storage "file" {
 listener "tcp" {
   address     = "{{ .Address }}"
   tls_disable = 1
-  # Enable unauthenticated metrics access for Prometheus scraping
-  telemetry {
-    unauthenticated_metrics_access = true
-  }
 }
+{{- if .TLSAddress }}
+
+# HTTPS listener; certificates are managed by 'foundry host tls' and
+# reloaded on SIGHUP
+listener "tcp" {
+  address       = "{{ .TLSAddress }}"
+  tls_cert_file = "/vault/config/tls/tls.crt"
+  tls_key_file  = "/vault/config/tls/tls.key"
+}
+{{- end }}
 
 api_addr = "http://{{ .Address }}"
 
 # Telemetry configuration for Prometheus metrics
-# Metrics are available at /v1/sys/metrics?format=prometheus
+# Metrics are available at /v1/sys/metrics?format=prometheus to tokens with
+# the foundry-metrics policy
 telemetry {
   disable_hostname = true
   prometheus_retention_time = "60s"
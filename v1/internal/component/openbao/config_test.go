@@ -50,8 +50,38 @@ func TestGenerateConfig(t *testing.T) {
 			},
 			wantNotContain: []string{
 				"0.0.0.0:8200",
+				"tls_cert_file",
 			},
 		},
+		{
+			name: "tls listener",
+			cfg: &Config{
+				Version:          "2.0.0",
+				DataPath:         "/var/lib/openbao",
+				ConfigPath:       "/etc/openbao",
+				Address:          "0.0.0.0:8200",
+				ContainerRuntime: "docker",
+				TLSAddress:       stringPtr("0.0.0.0:8443"),
+			},
+			wantContains: []string{
+				`address     = "0.0.0.0:8200"`,
+				`address       = "0.0.0.0:8443"`,
+				`tls_cert_file = "/vault/config/tls/tls.crt"`,
+				`tls_key_file  = "/vault/config/tls/tls.key"`,
+			},
+		},
+		{
+			name: "tls listener same as address",
+			cfg: &Config{
+				Version:          "2.0.0",
+				DataPath:         "/var/lib/openbao",
+				ConfigPath:       "/etc/openbao",
+				Address:          "0.0.0.0:8200",
+				ContainerRuntime: "docker",
+				TLSAddress:       stringPtr("0.0.0.0:8200"),
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid config",
 			cfg: &Config{
@@ -98,4 +128,9 @@ func TestGenerateConfig_ValidHCL(t *testing.T) {
 	assert.True(t, strings.Contains(result, "storage"), "should have storage block")
 	assert.True(t, strings.Contains(result, "listener"), "should have listener block")
 	assert.True(t, strings.Contains(result, "telemetry"), "should have telemetry block for Prometheus metrics")
+	assert.NotContains(t, result, "unauthenticated_metrics_access", "metrics should require the foundry-metrics token")
+}
+
+func stringPtr(s string) *string {
+	return &s
 }
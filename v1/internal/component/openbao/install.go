@@ -48,13 +48,22 @@ func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig)
 		return fmt.Errorf("failed to create directories: %w", err)
 	}
 
+	// OpenBAO refuses to start with a TLS listener whose certificate is
+	// missing, so only enable it once 'foundry host tls issue' has run
+	if openbaoCfg.TLSAddress != nil {
+		if _, err := conn.Execute(fmt.Sprintf("sudo test -f %s/tls/tls.crt", openbaoCfg.ConfigPath)); err != nil {
+			fmt.Printf("  ⚠ No certificate in %s/tls yet; HTTPS listener disabled until 'foundry host tls issue openbao' and a reinstall\n", openbaoCfg.ConfigPath)
+			openbaoCfg.TLSAddress = nil
+		}
+	}
+
 	// Generate and write OpenBAO config file
 	if err := writeConfigFile(conn, openbaoCfg); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	// Pull OpenBAO container image
-	image := fmt.Sprintf("quay.io/openbao/openbao:%s", openbaoCfg.Version)
+	image := container.PinImage(Image(openbaoCfg.Version), openbaoCfg.ImageDigests)
 	if err := runtime.Pull(image); err != nil {
 		return fmt.Errorf("failed to pull image: %w", err)
 	}
@@ -134,6 +143,10 @@ func parseConfig(cfg component.ComponentConfig) (*Config, error) {
 	if runtime, ok := cfg["container_runtime"].(string); ok {
 		openbaoCfg.ContainerRuntime = runtime
 	}
+	if tlsAddress, ok := cfg["tls_address"].(string); ok && tlsAddress != "" {
+		openbaoCfg.TLSAddress = &tlsAddress
+	}
+	openbaoCfg.ImageDigests = container.ParseImageDigests(cfg["image_digests"])
 
 	return openbaoCfg, nil
 }
@@ -212,9 +225,14 @@ func createSystemdService(conn container.SSHExecutor, cfg *Config, runtimeType s
 	return nil
 }
 
+// Image returns the OpenBAO container image for a version
+func Image(version string) string {
+	return fmt.Sprintf("quay.io/openbao/openbao:%s", version)
+}
+
 // buildExecStart builds the ExecStart command for the systemd service (foreground mode)
 func buildExecStart(cfg *Config, runtimePath string) string {
-	image := fmt.Sprintf("quay.io/openbao/openbao:%s", cfg.Version)
+	image := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
 
 	parts := []string{
 		fmt.Sprintf("%s run", runtimePath),
@@ -225,13 +243,19 @@ func buildExecStart(cfg *Config, runtimePath string) string {
 		// Disable AppArmor - nerdctl-default profile blocks runc signal operations
 		"--security-opt apparmor=unconfined",
 		fmt.Sprintf("-p %s:8200", strings.Split(cfg.Address, ":")[1]),
+	}
+	if cfg.TLSAddress != nil {
+		port := (*cfg.TLSAddress)[strings.LastIndex(*cfg.TLSAddress, ":")+1:]
+		parts = append(parts, fmt.Sprintf("-p %s:%s", port, port))
+	}
+	parts = append(parts,
 		fmt.Sprintf("-v %s:/vault/data", cfg.DataPath),
 		fmt.Sprintf("-v %s:/vault/config", cfg.ConfigPath),
 		"--cap-add=IPC_LOCK",
 		image,
 		"server",
 		"-config=/vault/config/config.hcl",
-	}
+	)
 
 	return strings.Join(parts, " ")
 }
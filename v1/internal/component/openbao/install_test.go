@@ -392,6 +392,21 @@ func TestComponent_buildExecStart(t *testing.T) {
 				"-p 9200:8200",
 			},
 		},
+		{
+			name: "tls listener",
+			cfg: &Config{
+				Version:          "2.0.0",
+				DataPath:         "/var/lib/openbao",
+				ConfigPath:       "/etc/openbao",
+				Address:          "0.0.0.0:8200",
+				ContainerRuntime: "docker",
+				TLSAddress:       stringPtr("0.0.0.0:8443"),
+			},
+			runtimePath: "/usr/local/bin/docker",
+			wantContains: []string{
+				"-p 8200:8200 -p 8443:8443",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,142 @@
+package openbao
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// MetricsPolicy is the policy of the token Prometheus scrapes
+	// /v1/sys/metrics with
+	MetricsPolicy = "foundry-metrics"
+
+	// MetricsSecretMount and MetricsSecretPath hold the credentials
+	// Prometheus scrapes host services with (openbao_token,
+	// powerdns_password)
+	MetricsSecretMount = "foundry-core"
+	MetricsSecretPath  = "metrics"
+
+	// metricsTokenPeriod is how long the metrics token stays valid without
+	// being renewed. The manager renews it daily, and every prometheus
+	// install or upgrade renews it.
+	metricsTokenPeriod = "768h"
+)
+
+// metricsPolicyHCL grants read access to the metrics endpoint only
+const metricsPolicyHCL = `path "sys/metrics" {
+  capabilities = ["read"]
+}
+`
+
+// CreateOrphanToken creates a token without a parent, so it outlives the
+// token that created it. data holds the auth/token/create-orphan parameters.
+func (c *Client) CreateOrphanToken(ctx context.Context, data map[string]interface{}) (string, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/auth/token/create-orphan", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token: %w", err)
+	}
+
+	var result struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := readResponse(resp, &result); err != nil {
+		return "", err
+	}
+	if result.Auth.ClientToken == "" {
+		return "", fmt.Errorf("no token in create response")
+	}
+
+	return result.Auth.ClientToken, nil
+}
+
+// RenewToken renews a token. It fails if the token has expired or been
+// revoked.
+func (c *Client) RenewToken(ctx context.Context, token string) error {
+	resp, err := c.doRequest(ctx, "POST", "/v1/auth/token/renew", map[string]interface{}{
+		"token": token,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to renew token: %w", err)
+	}
+
+	return readResponse(resp, nil)
+}
+
+// EnsureSecretValue returns key from the secret at mount/path. When it is
+// missing, generate creates it and it is stored alongside the secret's
+// other keys.
+func (c *Client) EnsureSecretValue(ctx context.Context, mount, path, key string, generate func() (string, error)) (string, error) {
+	data, err := c.ReadSecretV2(ctx, mount, path)
+	if err != nil || data == nil {
+		data = map[string]interface{}{}
+	}
+	if value, ok := data[key].(string); ok && value != "" {
+		return value, nil
+	}
+
+	value, err := generate()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s: %w", key, err)
+	}
+	data[key] = value
+	if err := c.WriteSecretV2(ctx, mount, path, data); err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// EnsureMetricsToken returns a token that can read OpenBAO's metrics. The
+// stored token is renewed; a new one is created, with the metrics policy,
+// when there is none or it has expired.
+func (c *Client) EnsureMetricsToken(ctx context.Context) (string, error) {
+	if err := c.WritePolicy(ctx, MetricsPolicy, metricsPolicyHCL); err != nil {
+		return "", err
+	}
+
+	data, err := c.ReadSecretV2(ctx, MetricsSecretMount, MetricsSecretPath)
+	if err != nil || data == nil {
+		data = map[string]interface{}{}
+	}
+	if token, ok := data["openbao_token"].(string); ok && token != "" {
+		if err := c.RenewToken(ctx, token); err == nil {
+			return token, nil
+		}
+	}
+
+	token, err := c.CreateOrphanToken(ctx, map[string]interface{}{
+		"policies":          []string{MetricsPolicy},
+		"no_default_policy": true,
+		"period":            metricsTokenPeriod,
+		"display_name":      "prometheus",
+	})
+	if err != nil {
+		return "", err
+	}
+	data["openbao_token"] = token
+	if err := c.WriteSecretV2(ctx, MetricsSecretMount, MetricsSecretPath, data); err != nil {
+		return "", fmt.Errorf("failed to store metrics token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RenewMetricsToken renews the stored metrics token without replacing it,
+// since Prometheus keeps scraping with the token it was installed with.
+// It does nothing when no token has been stored yet.
+func (c *Client) RenewMetricsToken(ctx context.Context) error {
+	data, err := c.ReadSecretV2(ctx, MetricsSecretMount, MetricsSecretPath)
+	if err != nil {
+		if IsSecretNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	token, _ := data["openbao_token"].(string)
+	if token == "" {
+		return nil
+	}
+	return c.RenewToken(ctx, token)
+}
@@ -0,0 +1,143 @@
+package openbao
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMetricsServer serves the endpoints EnsureMetricsToken uses. stored is
+// the foundry-core/metrics secret; renewable is the set of live tokens.
+func fakeMetricsServer(t *testing.T, stored map[string]interface{}, renewable map[string]bool) (*httptest.Server, *[]string) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		var body map[string]interface{}
+		if r.Body != nil {
+			json.NewDecoder(r.Body).Decode(&body)
+		}
+
+		switch r.URL.Path {
+		case "/v1/sys/policies/acl/foundry-metrics":
+			assert.Contains(t, body["policy"], `path "sys/metrics"`)
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/foundry-core/data/metrics":
+			if r.Method == "GET" {
+				if stored == nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": stored}})
+				return
+			}
+			stored = body["data"].(map[string]interface{})
+			w.WriteHeader(http.StatusOK)
+		case "/v1/auth/token/renew":
+			if !renewable[body["token"].(string)] {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/v1/auth/token/create-orphan":
+			assert.Equal(t, []interface{}{"foundry-metrics"}, body["policies"])
+			assert.Equal(t, "768h", body["period"])
+			json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": "s.new"}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestClient_EnsureMetricsToken(t *testing.T) {
+	t.Run("creates a token when none is stored", func(t *testing.T) {
+		server, calls := fakeMetricsServer(t, nil, nil)
+		token, err := NewClient(server.URL, "root").EnsureMetricsToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "s.new", token)
+		assert.Contains(t, *calls, "POST /v1/foundry-core/data/metrics")
+	})
+
+	t.Run("renews the stored token", func(t *testing.T) {
+		server, calls := fakeMetricsServer(t,
+			map[string]interface{}{"openbao_token": "s.old", "powerdns_password": "pw"},
+			map[string]bool{"s.old": true})
+		token, err := NewClient(server.URL, "root").EnsureMetricsToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "s.old", token)
+		assert.NotContains(t, *calls, "POST /v1/auth/token/create-orphan")
+	})
+
+	t.Run("replaces an expired token and keeps other keys", func(t *testing.T) {
+		stored := map[string]interface{}{"openbao_token": "s.old", "powerdns_password": "pw"}
+		var written map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/v1/foundry-core/data/metrics" && r.Method == "POST":
+				var body map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				written = body["data"].(map[string]interface{})
+			case r.URL.Path == "/v1/foundry-core/data/metrics":
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": stored}})
+			case r.URL.Path == "/v1/auth/token/renew":
+				w.WriteHeader(http.StatusForbidden)
+			case r.URL.Path == "/v1/auth/token/create-orphan":
+				json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": "s.new"}})
+			}
+		}))
+		defer server.Close()
+
+		token, err := NewClient(server.URL, "root").EnsureMetricsToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "s.new", token)
+		assert.Equal(t, map[string]interface{}{"openbao_token": "s.new", "powerdns_password": "pw"}, written)
+	})
+}
+
+func TestClient_RenewMetricsToken(t *testing.T) {
+	t.Run("renews the stored token", func(t *testing.T) {
+		server, calls := fakeMetricsServer(t,
+			map[string]interface{}{"openbao_token": "s.old"},
+			map[string]bool{"s.old": true})
+		require.NoError(t, NewClient(server.URL, "root").RenewMetricsToken(context.Background()))
+		assert.Equal(t, []string{"GET /v1/foundry-core/data/metrics", "POST /v1/auth/token/renew"}, *calls)
+	})
+
+	t.Run("does nothing without a stored token", func(t *testing.T) {
+		server, calls := fakeMetricsServer(t, nil, nil)
+		require.NoError(t, NewClient(server.URL, "root").RenewMetricsToken(context.Background()))
+		assert.Equal(t, []string{"GET /v1/foundry-core/data/metrics"}, *calls)
+	})
+
+	t.Run("fails for an expired token", func(t *testing.T) {
+		server, calls := fakeMetricsServer(t, map[string]interface{}{"openbao_token": "s.old"}, nil)
+		require.Error(t, NewClient(server.URL, "root").RenewMetricsToken(context.Background()))
+		assert.NotContains(t, *calls, "POST /v1/auth/token/create-orphan")
+	})
+}
+
+func TestClient_EnsureSecretValue(t *testing.T) {
+	server, _ := fakeMetricsServer(t, map[string]interface{}{"openbao_token": "s.old"}, nil)
+	client := NewClient(server.URL, "root")
+
+	generated := 0
+	generate := func() (string, error) {
+		generated++
+		return "secret", nil
+	}
+
+	value, err := client.EnsureSecretValue(context.Background(), "foundry-core", "metrics", "powerdns_password", generate)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = client.EnsureSecretValue(context.Background(), "foundry-core", "metrics", "openbao_token", generate)
+	require.NoError(t, err)
+	assert.Equal(t, "s.old", value)
+	assert.Equal(t, 1, generated)
+}
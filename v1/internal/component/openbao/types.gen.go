@@ -5,9 +5,11 @@ package openbao
 
 // Config represents a structured data type
 type Config struct {
-	Version          string `json:"version" yaml:"version"`
-	DataPath         string `json:"data_path" yaml:"data_path"`
-	ConfigPath       string `json:"config_path" yaml:"config_path"`
-	Address          string `json:"address" yaml:"address"`
-	ContainerRuntime string `json:"container_runtime" yaml:"container_runtime"`
+	Version          string            `json:"version" yaml:"version"`
+	DataPath         string            `json:"data_path" yaml:"data_path"`
+	ConfigPath       string            `json:"config_path" yaml:"config_path"`
+	Address          string            `json:"address" yaml:"address"`
+	ContainerRuntime string            `json:"container_runtime" yaml:"container_runtime"`
+	TLSAddress       *string           `json:"tls_address,omitempty" yaml:"tls_address,omitempty"`
+	ImageDigests     map[string]string `json:"image_digests,omitempty" yaml:"image_digests,omitempty"`
 }
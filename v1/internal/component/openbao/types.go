@@ -3,6 +3,7 @@ package openbao
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/catalystcommunity/foundry/v1/internal/component"
 	"github.com/catalystcommunity/foundry/v1/internal/container"
@@ -85,5 +86,13 @@ func (c *Config) Validate() error {
 	if c.ContainerRuntime != "docker" && c.ContainerRuntime != "podman" {
 		return fmt.Errorf("container_runtime must be 'docker' or 'podman', got: %s", c.ContainerRuntime)
 	}
+	if c.TLSAddress != nil {
+		if _, port, err := net.SplitHostPort(*c.TLSAddress); err != nil || port == "" {
+			return fmt.Errorf("tls_address must be host:port, got: %s", *c.TLSAddress)
+		}
+		if *c.TLSAddress == c.Address {
+			return fmt.Errorf("tls_address must differ from address")
+		}
+	}
 	return nil
 }
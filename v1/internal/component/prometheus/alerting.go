@@ -0,0 +1,550 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
+)
+
+// Receiver types Alertmanager can deliver to
+const (
+	// ReceiverWebhook posts Alertmanager's webhook payload to a URL
+	ReceiverWebhook = "webhook"
+
+	// ReceiverEmail sends mail through an SMTP server
+	ReceiverEmail = "email"
+
+	// ReceiverNtfy publishes to an ntfy topic
+	ReceiverNtfy = "ntfy"
+
+	// ReceiverMatrix posts to a Matrix room through a webhook bridge, such
+	// as matrix-alertmanager-receiver or Hookshot
+	ReceiverMatrix = "matrix"
+
+	// ReceiverSlack posts to a Slack-compatible incoming webhook (Slack,
+	// Mattermost, Rocket.Chat)
+	ReceiverSlack = "slack"
+
+	// ReceiverPagerDuty sends PagerDuty Events API v2 events, to PagerDuty
+	// or a compatible service
+	ReceiverPagerDuty = "pagerduty"
+)
+
+// TestReceiverLabel routes an alert straight to the receiver it names.
+// `foundry alerts test` sets it on the synthetic alert it sends.
+const TestReceiverLabel = "foundry_test_receiver"
+
+// nullReceiver drops alerts; the chart's Watchdog and InfoInhibitor alerts
+// go to it
+const nullReceiver = "null"
+
+// AlertingConfig is where Alertmanager sends notifications
+type AlertingConfig struct {
+	// Receivers are the notification channels
+	Receivers []Receiver `json:"receivers" yaml:"receivers"`
+
+	// DefaultReceiver gets every alert no route matches. It defaults to
+	// the first receiver.
+	DefaultReceiver string `json:"default_receiver,omitempty" yaml:"default_receiver,omitempty"`
+
+	// Routes send alerts to receivers by severity and namespace. The first
+	// matching route wins unless it sets continue.
+	Routes []AlertRoute `json:"routes,omitempty" yaml:"routes,omitempty"`
+
+	// InhibitRules mute alerts while other alerts fire. They are added to
+	// the usual rules that mute warning and info alerts while a critical
+	// alert for the same alertname and namespace fires.
+	InhibitRules []InhibitRule `json:"inhibit_rules,omitempty" yaml:"inhibit_rules,omitempty"`
+
+	// GroupBy are the labels alerts are batched by (default: alertname,
+	// namespace)
+	GroupBy []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+
+	// GroupWait, GroupInterval and RepeatInterval tune the root route's
+	// timing (defaults: 30s, 5m and 4h)
+	GroupWait      string `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	GroupInterval  string `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	RepeatInterval string `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+}
+
+// Receiver is one notification channel. Which fields apply depends on Type;
+// any string field may be a ${secret:path:key} reference.
+type Receiver struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+
+	// SendResolved also notifies when an alert stops firing (default: true)
+	SendResolved *bool `json:"send_resolved,omitempty" yaml:"send_resolved,omitempty"`
+
+	// URL is the webhook, ntfy server, Matrix bridge or Slack incoming
+	// webhook URL. For pagerduty it overrides the Events API URL.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Token is sent as a bearer token by webhook, ntfy and matrix receivers
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+
+	// Topic is the ntfy topic
+	Topic string `json:"topic,omitempty" yaml:"topic,omitempty"`
+
+	// Channel overrides the Slack webhook's default channel
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+
+	// RoutingKey is the PagerDuty integration key
+	RoutingKey string `json:"routing_key,omitempty" yaml:"routing_key,omitempty"`
+
+	// To, From and Smarthost (host:port) address email; Username and
+	// Password authenticate to the SMTP server
+	To         string `json:"to,omitempty" yaml:"to,omitempty"`
+	From       string `json:"from,omitempty" yaml:"from,omitempty"`
+	Smarthost  string `json:"smarthost,omitempty" yaml:"smarthost,omitempty"`
+	Username   string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password   string `json:"password,omitempty" yaml:"password,omitempty"`
+	RequireTLS *bool  `json:"require_tls,omitempty" yaml:"require_tls,omitempty"`
+}
+
+// AlertRoute sends matching alerts to a receiver
+type AlertRoute struct {
+	Receiver string `json:"receiver" yaml:"receiver"`
+
+	// Severities and Namespaces match the alert's severity and namespace
+	// labels; empty matches any
+	Severities []string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Namespaces []string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Matchers are extra Alertmanager matchers, e.g. alertname=~"Foundry.*"
+	Matchers []string `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+
+	// Continue keeps matching later routes after this one
+	Continue bool `json:"continue,omitempty" yaml:"continue,omitempty"`
+
+	// RepeatInterval overrides how often a still-firing alert is resent
+	RepeatInterval string `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+}
+
+// InhibitRule mutes alerts matching TargetMatchers while an alert matching
+// SourceMatchers fires with the same Equal labels
+type InhibitRule struct {
+	SourceMatchers []string `json:"source_matchers" yaml:"source_matchers"`
+	TargetMatchers []string `json:"target_matchers" yaml:"target_matchers"`
+	Equal          []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
+// Receiver returns the receiver with the given name, or nil
+func (c *AlertingConfig) Receiver(name string) *Receiver {
+	for i := range c.Receivers {
+		if c.Receivers[i].Name == name {
+			return &c.Receivers[i]
+		}
+	}
+	return nil
+}
+
+// parseAlerting reads the alerting section of the component config
+func parseAlerting(raw interface{}) *AlertingConfig {
+	switch v := raw.(type) {
+	case *AlertingConfig:
+		return v
+	case AlertingConfig:
+		return &v
+	case map[string]interface{}:
+		cfg := component.ComponentConfig(v)
+		alerting := &AlertingConfig{}
+		alerting.DefaultReceiver, _ = cfg.GetString("default_receiver")
+		alerting.GroupBy, _ = cfg.GetStringSlice("group_by")
+		alerting.GroupWait, _ = cfg.GetString("group_wait")
+		alerting.GroupInterval, _ = cfg.GetString("group_interval")
+		alerting.RepeatInterval, _ = cfg.GetString("repeat_interval")
+
+		receivers, _ := cfg["receivers"].([]interface{})
+		for _, item := range receivers {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			entry := component.ComponentConfig(m)
+			receiver := Receiver{}
+			receiver.Name, _ = entry.GetString("name")
+			receiver.Type, _ = entry.GetString("type")
+			receiver.URL, _ = entry.GetString("url")
+			receiver.Token, _ = entry.GetString("token")
+			receiver.Topic, _ = entry.GetString("topic")
+			receiver.Channel, _ = entry.GetString("channel")
+			receiver.RoutingKey, _ = entry.GetString("routing_key")
+			receiver.To, _ = entry.GetString("to")
+			receiver.From, _ = entry.GetString("from")
+			receiver.Smarthost, _ = entry.GetString("smarthost")
+			receiver.Username, _ = entry.GetString("username")
+			receiver.Password, _ = entry.GetString("password")
+			if sendResolved, ok := entry.GetBool("send_resolved"); ok {
+				receiver.SendResolved = &sendResolved
+			}
+			if requireTLS, ok := entry.GetBool("require_tls"); ok {
+				receiver.RequireTLS = &requireTLS
+			}
+			alerting.Receivers = append(alerting.Receivers, receiver)
+		}
+
+		routes, _ := cfg["routes"].([]interface{})
+		for _, item := range routes {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			entry := component.ComponentConfig(m)
+			route := AlertRoute{
+				Severities: stringOrList(entry, "severity"),
+				Namespaces: stringOrList(entry, "namespace"),
+			}
+			route.Receiver, _ = entry.GetString("receiver")
+			route.Matchers, _ = entry.GetStringSlice("matchers")
+			route.RepeatInterval, _ = entry.GetString("repeat_interval")
+			route.Continue, _ = entry.GetBool("continue")
+			alerting.Routes = append(alerting.Routes, route)
+		}
+
+		inhibitRules, _ := cfg["inhibit_rules"].([]interface{})
+		for _, item := range inhibitRules {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			entry := component.ComponentConfig(m)
+			rule := InhibitRule{}
+			rule.SourceMatchers, _ = entry.GetStringSlice("source_matchers")
+			rule.TargetMatchers, _ = entry.GetStringSlice("target_matchers")
+			rule.Equal, _ = entry.GetStringSlice("equal")
+			alerting.InhibitRules = append(alerting.InhibitRules, rule)
+		}
+		return alerting
+	}
+	return nil
+}
+
+// stringOrList reads a list of strings that may also be given as a single
+// string, e.g. severity: critical
+func stringOrList(cfg component.ComponentConfig, key string) []string {
+	if s, ok := cfg.GetString(key); ok {
+		return []string{s}
+	}
+	list, _ := cfg.GetStringSlice(key)
+	return list
+}
+
+// matcherPattern is the label, operator and value of an Alertmanager matcher
+var matcherPattern = regexp.MustCompile(`^\s*[a-zA-Z_][a-zA-Z0-9_]*\s*(=~|!~|!=|=)\s*\S.*$`)
+
+// Validate checks the alerting config
+func (c *AlertingConfig) Validate() error {
+	names := map[string]bool{}
+	for i, r := range c.Receivers {
+		if r.Name == "" {
+			return fmt.Errorf("alerting receiver %d has no name", i+1)
+		}
+		if r.Name == nullReceiver {
+			return fmt.Errorf("alerting receiver name %q is reserved", nullReceiver)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("alerting receiver %q is defined more than once", r.Name)
+		}
+		names[r.Name] = true
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("alerting receiver %q: %w", r.Name, err)
+		}
+	}
+
+	if c.DefaultReceiver != "" && !names[c.DefaultReceiver] {
+		return fmt.Errorf("alerting default_receiver %q is not a receiver", c.DefaultReceiver)
+	}
+	if len(c.Receivers) == 0 && (len(c.Routes) > 0 || c.DefaultReceiver != "") {
+		return fmt.Errorf("alerting routes need at least one receiver")
+	}
+
+	for i, route := range c.Routes {
+		if !names[route.Receiver] {
+			return fmt.Errorf("alerting route %d: receiver %q is not defined", i+1, route.Receiver)
+		}
+		for _, severity := range route.Severities {
+			switch severity {
+			case "critical", "warning", "info":
+			default:
+				return fmt.Errorf("alerting route %d: severity must be critical, warning or info", i+1)
+			}
+		}
+		if err := validateMatchers(route.Matchers); err != nil {
+			return fmt.Errorf("alerting route %d: %w", i+1, err)
+		}
+		if route.RepeatInterval != "" && !promDuration.MatchString(route.RepeatInterval) {
+			return fmt.Errorf("alerting route %d: invalid repeat_interval %q", i+1, route.RepeatInterval)
+		}
+	}
+
+	for i, rule := range c.InhibitRules {
+		if len(rule.SourceMatchers) == 0 || len(rule.TargetMatchers) == 0 {
+			return fmt.Errorf("alerting inhibit rule %d needs source_matchers and target_matchers", i+1)
+		}
+		if err := validateMatchers(append(append([]string{}, rule.SourceMatchers...), rule.TargetMatchers...)); err != nil {
+			return fmt.Errorf("alerting inhibit rule %d: %w", i+1, err)
+		}
+	}
+
+	for name, value := range map[string]string{
+		"group_wait":      c.GroupWait,
+		"group_interval":  c.GroupInterval,
+		"repeat_interval": c.RepeatInterval,
+	} {
+		if value != "" && !promDuration.MatchString(value) {
+			return fmt.Errorf("alerting %s: invalid duration %q", name, value)
+		}
+	}
+	return nil
+}
+
+func (r Receiver) validate() error {
+	// required takes field name and value pairs
+	required := func(fields ...string) error {
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i+1] == "" {
+				return fmt.Errorf("%s is required for %s receivers", fields[i], r.Type)
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch r.Type {
+	case ReceiverWebhook, ReceiverMatrix, ReceiverSlack:
+		err = required("url", r.URL)
+	case ReceiverNtfy:
+		err = required("url", r.URL, "topic", r.Topic)
+	case ReceiverPagerDuty:
+		err = required("routing_key", r.RoutingKey)
+	case ReceiverEmail:
+		err = required("to", r.To, "from", r.From, "smarthost", r.Smarthost)
+	case "":
+		err = fmt.Errorf("type is required")
+	default:
+		err = fmt.Errorf("unknown type %q (use webhook, email, ntfy, matrix, slack or pagerduty)", r.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	// References are checked once they are resolved at install time
+	if r.URL != "" && !secrets.IsSecretRef(r.URL) {
+		u, err := url.Parse(r.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url %q must be an http or https URL", r.URL)
+		}
+	}
+	return nil
+}
+
+func validateMatchers(matchers []string) error {
+	for _, m := range matchers {
+		if !matcherPattern.MatchString(m) {
+			return fmt.Errorf("invalid matcher %q (use label=value, label!=value, label=~regex or label!~regex)", m)
+		}
+	}
+	return nil
+}
+
+// buildAlertmanagerConfig renders the Alertmanager configuration. Alerts go
+// to the default receiver unless a route matches; the chart's Watchdog and
+// InfoInhibitor alerts are dropped, and every receiver gets a route for
+// test alerts.
+func buildAlertmanagerConfig(c *AlertingConfig) map[string]interface{} {
+	receivers := []interface{}{map[string]interface{}{"name": nullReceiver}}
+	for _, r := range c.Receivers {
+		receivers = append(receivers, renderReceiver(r))
+	}
+
+	defaultReceiver := c.DefaultReceiver
+	if defaultReceiver == "" {
+		defaultReceiver = c.Receivers[0].Name
+	}
+
+	routes := []interface{}{
+		map[string]interface{}{"receiver": nullReceiver, "matchers": []string{`alertname="Watchdog"`}},
+		map[string]interface{}{"receiver": nullReceiver, "matchers": []string{`alertname="InfoInhibitor"`}},
+	}
+	// Each test alert is its own group, so it is sent straight away
+	for _, r := range c.Receivers {
+		routes = append(routes, map[string]interface{}{
+			"receiver":   r.Name,
+			"matchers":   []string{fmt.Sprintf("%s=%q", TestReceiverLabel, r.Name)},
+			"group_by":   []string{"..."},
+			"group_wait": "0s",
+		})
+	}
+	for _, route := range c.Routes {
+		routes = append(routes, renderRoute(route))
+	}
+
+	groupBy := c.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{"alertname", "namespace"}
+	}
+	root := map[string]interface{}{
+		"receiver":        defaultReceiver,
+		"group_by":        groupBy,
+		"group_wait":      valueOr(c.GroupWait, "30s"),
+		"group_interval":  valueOr(c.GroupInterval, "5m"),
+		"repeat_interval": valueOr(c.RepeatInterval, "4h"),
+		"routes":          routes,
+	}
+
+	// The chart's usual inhibitions, then the configured ones
+	inhibitRules := []interface{}{
+		map[string]interface{}{
+			"source_matchers": []string{`severity="critical"`},
+			"target_matchers": []string{`severity=~"warning|info"`},
+			"equal":           []string{"namespace", "alertname"},
+		},
+		map[string]interface{}{
+			"source_matchers": []string{`severity="warning"`},
+			"target_matchers": []string{`severity="info"`},
+			"equal":           []string{"namespace", "alertname"},
+		},
+		map[string]interface{}{
+			"source_matchers": []string{`alertname="InfoInhibitor"`},
+			"target_matchers": []string{`severity="info"`},
+			"equal":           []string{"namespace"},
+		},
+	}
+	for _, rule := range c.InhibitRules {
+		entry := map[string]interface{}{
+			"source_matchers": rule.SourceMatchers,
+			"target_matchers": rule.TargetMatchers,
+		}
+		if len(rule.Equal) > 0 {
+			entry["equal"] = rule.Equal
+		}
+		inhibitRules = append(inhibitRules, entry)
+	}
+
+	return map[string]interface{}{
+		"global": map[string]interface{}{
+			"resolve_timeout": "5m",
+		},
+		"route":         root,
+		"receivers":     receivers,
+		"inhibit_rules": inhibitRules,
+	}
+}
+
+func renderRoute(route AlertRoute) map[string]interface{} {
+	var matchers []string
+	if m := labelMatcher("severity", route.Severities); m != "" {
+		matchers = append(matchers, m)
+	}
+	if m := labelMatcher("namespace", route.Namespaces); m != "" {
+		matchers = append(matchers, m)
+	}
+	matchers = append(matchers, route.Matchers...)
+
+	entry := map[string]interface{}{"receiver": route.Receiver}
+	if len(matchers) > 0 {
+		entry["matchers"] = matchers
+	}
+	if route.Continue {
+		entry["continue"] = true
+	}
+	if route.RepeatInterval != "" {
+		entry["repeat_interval"] = route.RepeatInterval
+	}
+	return entry
+}
+
+// labelMatcher matches a label against one or more exact values
+func labelMatcher(label string, values []string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%s=%q", label, values[0])
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return fmt.Sprintf("%s=~%q", label, strings.Join(quoted, "|"))
+}
+
+// renderReceiver turns a receiver into Alertmanager's receiver config
+func renderReceiver(r Receiver) map[string]interface{} {
+	sendResolved := true
+	if r.SendResolved != nil {
+		sendResolved = *r.SendResolved
+	}
+	bearer := func(cfg map[string]interface{}) map[string]interface{} {
+		if r.Token != "" {
+			cfg["http_config"] = map[string]interface{}{
+				"authorization": map[string]interface{}{
+					"type":        "Bearer",
+					"credentials": r.Token,
+				},
+			}
+		}
+		return cfg
+	}
+
+	receiver := map[string]interface{}{"name": r.Name}
+	switch r.Type {
+	case ReceiverWebhook, ReceiverMatrix:
+		receiver["webhook_configs"] = []interface{}{bearer(map[string]interface{}{
+			"url":           r.URL,
+			"send_resolved": sendResolved,
+		})}
+	case ReceiverNtfy:
+		// ntfy formats Alertmanager's payload with its alertmanager template
+		receiver["webhook_configs"] = []interface{}{bearer(map[string]interface{}{
+			"url":           strings.TrimRight(r.URL, "/") + "/" + url.PathEscape(r.Topic) + "?template=alertmanager",
+			"send_resolved": sendResolved,
+		})}
+	case ReceiverSlack:
+		slack := map[string]interface{}{
+			"api_url":       r.URL,
+			"send_resolved": sendResolved,
+			"title":         `[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .CommonLabels.alertname }}`,
+			"text":          `{{ range .Alerts }}{{ if .Annotations.summary }}{{ .Annotations.summary }}{{ else }}{{ .Annotations.description }}{{ end }}` + "\n" + `{{ end }}`,
+		}
+		if r.Channel != "" {
+			slack["channel"] = r.Channel
+		}
+		receiver["slack_configs"] = []interface{}{slack}
+	case ReceiverPagerDuty:
+		pagerduty := map[string]interface{}{
+			"routing_key":   r.RoutingKey,
+			"send_resolved": sendResolved,
+		}
+		if r.URL != "" {
+			pagerduty["url"] = r.URL
+		}
+		receiver["pagerduty_configs"] = []interface{}{pagerduty}
+	case ReceiverEmail:
+		email := map[string]interface{}{
+			"to":            r.To,
+			"from":          r.From,
+			"smarthost":     r.Smarthost,
+			"send_resolved": sendResolved,
+			"require_tls":   r.RequireTLS == nil || *r.RequireTLS,
+		}
+		if r.Username != "" {
+			email["auth_username"] = r.Username
+			email["auth_password"] = r.Password
+		}
+		receiver["email_configs"] = []interface{}{email}
+	}
+	return receiver
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
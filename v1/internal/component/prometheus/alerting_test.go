@@ -0,0 +1,195 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+)
+
+// alertingComponentConfig is an alerting section as it comes from YAML
+func alertingComponentConfig() component.ComponentConfig {
+	return component.ComponentConfig{
+		"alerting": map[string]interface{}{
+			"default_receiver": "ops",
+			"repeat_interval":  "12h",
+			"receivers": []interface{}{
+				map[string]interface{}{
+					"name": "ops",
+					"type": "email",
+					"to":   "oncall@example.com", "from": "alertmanager@example.com",
+					"smarthost": "smtp.example.com:587",
+					"username":  "alertmanager", "password": "${secret:alerting/smtp:password}",
+				},
+				map[string]interface{}{
+					"name": "pager", "type": "pagerduty",
+					"routing_key": "${secret:alerting/pagerduty:routing_key}",
+				},
+				map[string]interface{}{
+					"name": "phone", "type": "ntfy",
+					"url": "https://ntfy.example.com/", "topic": "homelab alerts",
+					"token": "tk_123", "send_resolved": false,
+				},
+				map[string]interface{}{
+					"name": "chat", "type": "slack",
+					"url": "${secret:alerting/slack:url}", "channel": "#alerts",
+				},
+			},
+			"routes": []interface{}{
+				map[string]interface{}{"receiver": "pager", "severity": "critical", "continue": true},
+				map[string]interface{}{"receiver": "chat", "namespace": []interface{}{"apps", "apps.staging"}, "severity": []interface{}{"warning", "critical"}},
+				map[string]interface{}{"receiver": "phone", "matchers": []interface{}{`alertname=~"Foundry.*"`}},
+			},
+			"inhibit_rules": []interface{}{
+				map[string]interface{}{
+					"source_matchers": []interface{}{`alertname="FoundryK3sNodeNotReady"`},
+					"target_matchers": []interface{}{`severity="warning"`},
+					"equal":           []interface{}{"node"},
+				},
+			},
+		},
+	}
+}
+
+func TestParseConfig_Alerting(t *testing.T) {
+	cfg, err := ParseConfig(alertingComponentConfig())
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Alerting)
+
+	alerting := cfg.Alerting
+	assert.Equal(t, "ops", alerting.DefaultReceiver)
+	assert.Equal(t, "12h", alerting.RepeatInterval)
+	require.Len(t, alerting.Receivers, 4)
+	assert.Equal(t, "${secret:alerting/smtp:password}", alerting.Receivers[0].Password)
+	require.NotNil(t, alerting.Receivers[2].SendResolved)
+	assert.False(t, *alerting.Receivers[2].SendResolved)
+
+	require.Len(t, alerting.Routes, 3)
+	assert.Equal(t, []string{"critical"}, alerting.Routes[0].Severities)
+	assert.True(t, alerting.Routes[0].Continue)
+	assert.Equal(t, []string{"apps", "apps.staging"}, alerting.Routes[1].Namespaces)
+	require.Len(t, alerting.InhibitRules, 1)
+	assert.Equal(t, []string{"node"}, alerting.InhibitRules[0].Equal)
+
+	assert.NotNil(t, alerting.Receiver("chat"))
+	assert.Nil(t, alerting.Receiver("missing"))
+}
+
+func TestAlertingConfig_Validate(t *testing.T) {
+	webhook := Receiver{Name: "hook", Type: ReceiverWebhook, URL: "https://hooks.example.com/alerts"}
+	tests := []struct {
+		name     string
+		alerting AlertingConfig
+		wantErr  string
+	}{
+		{name: "valid", alerting: AlertingConfig{Receivers: []Receiver{webhook}}},
+		{name: "secret url", alerting: AlertingConfig{Receivers: []Receiver{{Name: "hook", Type: ReceiverSlack, URL: "${secret:alerting/slack:url}"}}}},
+		{name: "missing name", alerting: AlertingConfig{Receivers: []Receiver{{Type: ReceiverWebhook, URL: "https://x.example.com"}}}, wantErr: "has no name"},
+		{name: "reserved name", alerting: AlertingConfig{Receivers: []Receiver{{Name: "null", Type: ReceiverWebhook, URL: "https://x.example.com"}}}, wantErr: "reserved"},
+		{name: "duplicate", alerting: AlertingConfig{Receivers: []Receiver{webhook, webhook}}, wantErr: "more than once"},
+		{name: "unknown type", alerting: AlertingConfig{Receivers: []Receiver{{Name: "x", Type: "carrier-pigeon"}}}, wantErr: "unknown type"},
+		{name: "missing type", alerting: AlertingConfig{Receivers: []Receiver{{Name: "x"}}}, wantErr: "type is required"},
+		{name: "ntfy needs topic", alerting: AlertingConfig{Receivers: []Receiver{{Name: "x", Type: ReceiverNtfy, URL: "https://ntfy.example.com"}}}, wantErr: "topic is required"},
+		{name: "email needs smarthost", alerting: AlertingConfig{Receivers: []Receiver{{Name: "x", Type: ReceiverEmail, To: "a@example.com", From: "b@example.com"}}}, wantErr: "smarthost is required"},
+		{name: "pagerduty needs key", alerting: AlertingConfig{Receivers: []Receiver{{Name: "x", Type: ReceiverPagerDuty}}}, wantErr: "routing_key is required"},
+		{name: "bad url", alerting: AlertingConfig{Receivers: []Receiver{{Name: "x", Type: ReceiverWebhook, URL: "hooks.example.com"}}}, wantErr: "http or https URL"},
+		{name: "unknown default", alerting: AlertingConfig{Receivers: []Receiver{webhook}, DefaultReceiver: "ops"}, wantErr: "default_receiver"},
+		{name: "routes without receivers", alerting: AlertingConfig{Routes: []AlertRoute{{Receiver: "hook"}}}, wantErr: "at least one receiver"},
+		{name: "route to unknown receiver", alerting: AlertingConfig{Receivers: []Receiver{webhook}, Routes: []AlertRoute{{Receiver: "ops"}}}, wantErr: "not defined"},
+		{name: "bad severity", alerting: AlertingConfig{Receivers: []Receiver{webhook}, Routes: []AlertRoute{{Receiver: "hook", Severities: []string{"page"}}}}, wantErr: "severity must be"},
+		{name: "bad matcher", alerting: AlertingConfig{Receivers: []Receiver{webhook}, Routes: []AlertRoute{{Receiver: "hook", Matchers: []string{"alertname"}}}}, wantErr: "invalid matcher"},
+		{name: "bad inhibit rule", alerting: AlertingConfig{Receivers: []Receiver{webhook}, InhibitRules: []InhibitRule{{SourceMatchers: []string{`severity="critical"`}}}}, wantErr: "needs source_matchers and target_matchers"},
+		{name: "bad duration", alerting: AlertingConfig{Receivers: []Receiver{webhook}, GroupWait: "soon"}, wantErr: "group_wait"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.alerting.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_AlertingNeedsAlertmanager(t *testing.T) {
+	cfg := alertingComponentConfig()
+	cfg["alertmanager_enabled"] = false
+	_, err := ParseConfig(cfg)
+	assert.ErrorContains(t, err, "alertmanager_enabled")
+}
+
+func TestBuildAlertmanagerConfig(t *testing.T) {
+	cfg, err := ParseConfig(alertingComponentConfig())
+	require.NoError(t, err)
+
+	am := buildAlertmanagerConfig(cfg.Alerting)
+
+	route := am["route"].(map[string]interface{})
+	assert.Equal(t, "ops", route["receiver"])
+	assert.Equal(t, "12h", route["repeat_interval"])
+	assert.Equal(t, "30s", route["group_wait"])
+	assert.Equal(t, []string{"alertname", "namespace"}, route["group_by"])
+
+	routes := route["routes"].([]interface{})
+	// Watchdog, InfoInhibitor, a test route per receiver, then the routes
+	require.Len(t, routes, 2+4+3)
+	assert.Equal(t, "null", routes[0].(map[string]interface{})["receiver"])
+	testRoute := routes[2].(map[string]interface{})
+	assert.Equal(t, "ops", testRoute["receiver"])
+	assert.Equal(t, []string{`foundry_test_receiver="ops"`}, testRoute["matchers"])
+
+	pager := routes[6].(map[string]interface{})
+	assert.Equal(t, "pager", pager["receiver"])
+	assert.Equal(t, []string{`severity="critical"`}, pager["matchers"])
+	assert.Equal(t, true, pager["continue"])
+	chat := routes[7].(map[string]interface{})
+	assert.Equal(t, []string{`severity=~"warning|critical"`, `namespace=~"apps|apps\\.staging"`}, chat["matchers"])
+	assert.NotContains(t, chat, "continue")
+	phone := routes[8].(map[string]interface{})
+	assert.Equal(t, []string{`alertname=~"Foundry.*"`}, phone["matchers"])
+
+	receivers := am["receivers"].([]interface{})
+	require.Len(t, receivers, 5)
+	assert.Equal(t, map[string]interface{}{"name": "null"}, receivers[0])
+
+	email := receivers[1].(map[string]interface{})["email_configs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "oncall@example.com", email["to"])
+	assert.Equal(t, "alertmanager", email["auth_username"])
+	assert.Equal(t, true, email["require_tls"])
+
+	pagerduty := receivers[2].(map[string]interface{})["pagerduty_configs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "${secret:alerting/pagerduty:routing_key}", pagerduty["routing_key"])
+	assert.NotContains(t, pagerduty, "url")
+
+	ntfy := receivers[3].(map[string]interface{})["webhook_configs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "https://ntfy.example.com/homelab%20alerts?template=alertmanager", ntfy["url"])
+	assert.Equal(t, false, ntfy["send_resolved"])
+	auth := ntfy["http_config"].(map[string]interface{})["authorization"].(map[string]interface{})
+	assert.Equal(t, "Bearer", auth["type"])
+	assert.Equal(t, "tk_123", auth["credentials"])
+
+	slack := receivers[4].(map[string]interface{})["slack_configs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "#alerts", slack["channel"])
+	assert.Equal(t, true, slack["send_resolved"])
+
+	inhibit := am["inhibit_rules"].([]interface{})
+	require.Len(t, inhibit, 4)
+	assert.Equal(t, []string{"node"}, inhibit[3].(map[string]interface{})["equal"])
+}
+
+func TestBuildHelmValues_AlertmanagerConfig(t *testing.T) {
+	cfg, err := ParseConfig(alertingComponentConfig())
+	require.NoError(t, err)
+	values := buildHelmValues(cfg)
+	am := values["alertmanager"].(map[string]interface{})
+	require.Contains(t, am, "config")
+	assert.Equal(t, "ops", am["config"].(map[string]interface{})["route"].(map[string]interface{})["receiver"])
+
+	// Without receivers the chart's own configuration is kept
+	values = buildHelmValues(DefaultConfig())
+	assert.NotContains(t, values["alertmanager"].(map[string]interface{}), "config")
+}
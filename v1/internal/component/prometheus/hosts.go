@@ -0,0 +1,130 @@
+package prometheus
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/nodeexporter"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+)
+
+// HostCredentials are the secrets Prometheus scrapes host services with,
+// kept in OpenBAO. An empty value leaves that service's target
+// unauthenticated.
+type HostCredentials struct {
+	// OpenBAOToken reads /v1/sys/metrics (foundry-metrics policy)
+	OpenBAOToken string
+
+	// PowerDNSPassword is the webserver password of the PowerDNS
+	// authoritative server and recursor
+	PowerDNSPassword string
+}
+
+// HostTargets builds the scrape targets for the hosts in the stack config:
+// node_exporter on every host once it is installed, and the metrics
+// endpoints of OpenBAO, PowerDNS and Zot on the hosts with those roles.
+// Every target is labelled with its host and role, so dashboards can group
+// by either.
+func HostTargets(cfg *config.Config, creds HostCredentials) []ExternalTarget {
+	var targets []ExternalTarget
+	if cfg == nil || cfg.SetupState == nil {
+		return targets
+	}
+
+	if port, ok := nodeExporterPort(cfg); ok {
+		for _, h := range cfg.Hosts {
+			targets = append(targets, ExternalTarget{
+				Name:    "node",
+				Targets: []string{net.JoinHostPort(h.Address, fmt.Sprint(port))},
+				Labels:  hostLabels(h),
+			})
+		}
+	}
+
+	if cfg.SetupState.OpenBAOInstalled {
+		for _, h := range cfg.GetHostsByRole(host.RoleOpenBAO) {
+			targets = append(targets, ExternalTarget{
+				Name:        "openbao",
+				Targets:     []string{net.JoinHostPort(h.Address, fmt.Sprint(cfg.GetOpenBAOPort()))},
+				MetricsPath: "/v1/sys/metrics",
+				Params: map[string][]string{
+					"format": {"prometheus"},
+				},
+				Labels:      hostLabels(h),
+				BearerToken: creds.OpenBAOToken,
+			})
+		}
+	}
+
+	if cfg.SetupState.ZotInstalled {
+		for _, h := range cfg.GetHostsByRole(host.RoleZot) {
+			targets = append(targets, ExternalTarget{
+				Name:        "zot",
+				Targets:     []string{net.JoinHostPort(h.Address, "5000")},
+				MetricsPath: "/metrics",
+				Labels:      hostLabels(h),
+			})
+		}
+	}
+
+	// PowerDNS serves Prometheus metrics natively: the authoritative
+	// server on 8081, the recursor on 8082
+	if cfg.SetupState.DNSInstalled {
+		var auth *BasicAuth
+		if creds.PowerDNSPassword != "" {
+			// PowerDNS ignores the username
+			auth = &BasicAuth{Username: "prometheus", Password: creds.PowerDNSPassword}
+		}
+		for _, h := range cfg.GetHostsByRole(host.RoleDNS) {
+			targets = append(targets,
+				ExternalTarget{
+					Name:        "powerdns-auth",
+					Targets:     []string{net.JoinHostPort(h.Address, "8081")},
+					MetricsPath: "/metrics",
+					Labels:      hostLabels(h),
+					BasicAuth:   auth,
+				},
+				ExternalTarget{
+					Name:        "powerdns-recursor",
+					Targets:     []string{net.JoinHostPort(h.Address, "8082")},
+					MetricsPath: "/metrics",
+					Labels:      hostLabels(h),
+					BasicAuth:   auth,
+				},
+			)
+		}
+	}
+
+	return targets
+}
+
+// nodeExporterPort returns the port node_exporter listens on, and whether
+// the stack has installed it
+func nodeExporterPort(cfg *config.Config) (int, bool) {
+	compCfg, ok := cfg.Components["node-exporter"]
+	if !ok || compCfg.Config == nil {
+		return 0, false
+	}
+	if installed, _ := compCfg.Config["installed"].(bool); !installed {
+		return 0, false
+	}
+	switch port := compCfg.Config["port"].(type) {
+	case int:
+		return port, true
+	case int64:
+		return int(port), true
+	case float64:
+		return int(port), true
+	}
+	return nodeexporter.DefaultPort, true
+}
+
+// hostLabels labels a host's targets with its hostname and roles. A host
+// with several roles lists them all, e.g. role="openbao,dns,zot".
+func hostLabels(h *host.Host) map[string]string {
+	return map[string]string{
+		"host": h.Hostname,
+		"role": h.RoleLabel(),
+	}
+}
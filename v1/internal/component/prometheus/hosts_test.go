@@ -0,0 +1,78 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStackConfig() *config.Config {
+	return &config.Config{
+		Hosts: []*host.Host{
+			{Hostname: "infra1", Address: "10.0.0.5", Roles: []string{host.RoleZot, host.RoleOpenBAO, host.RoleDNS}},
+			{Hostname: "node1", Address: "10.0.0.10", Roles: []string{host.RoleClusterControlPlane, host.RoleClusterWorker}},
+		},
+		SetupState: &setup.SetupState{
+			OpenBAOInstalled: true,
+			DNSInstalled:     true,
+			ZotInstalled:     true,
+		},
+		Components: config.ComponentMap{
+			"node-exporter": config.ComponentConfig{Config: map[string]any{"installed": true}},
+		},
+	}
+}
+
+func TestHostTargets(t *testing.T) {
+	targets := HostTargets(testStackConfig(), HostCredentials{OpenBAOToken: "s.metrics", PowerDNSPassword: "pw"})
+
+	byJob := map[string][]ExternalTarget{}
+	for _, target := range targets {
+		byJob[target.Name] = append(byJob[target.Name], target)
+	}
+	require.Len(t, byJob["node"], 2)
+	assert.Equal(t, []string{"10.0.0.5:9110"}, byJob["node"][0].Targets)
+	assert.Equal(t, map[string]string{"host": "infra1", "role": "openbao,dns,zot"}, byJob["node"][0].Labels)
+	assert.Equal(t, []string{"10.0.0.10:9110"}, byJob["node"][1].Targets)
+	assert.Equal(t, map[string]string{"host": "node1", "role": "cluster-control-plane,cluster-worker"}, byJob["node"][1].Labels)
+
+	require.Len(t, byJob["openbao"], 1)
+	assert.Equal(t, []string{"10.0.0.5:8200"}, byJob["openbao"][0].Targets)
+	assert.Equal(t, "/v1/sys/metrics", byJob["openbao"][0].MetricsPath)
+	assert.Equal(t, "s.metrics", byJob["openbao"][0].BearerToken)
+
+	require.Len(t, byJob["zot"], 1)
+	assert.Equal(t, []string{"10.0.0.5:5000"}, byJob["zot"][0].Targets)
+
+	require.Len(t, byJob["powerdns-auth"], 1)
+	assert.Equal(t, []string{"10.0.0.5:8081"}, byJob["powerdns-auth"][0].Targets)
+	assert.Equal(t, &BasicAuth{Username: "prometheus", Password: "pw"}, byJob["powerdns-auth"][0].BasicAuth)
+	require.Len(t, byJob["powerdns-recursor"], 1)
+	assert.Equal(t, []string{"10.0.0.5:8082"}, byJob["powerdns-recursor"][0].Targets)
+}
+
+func TestHostTargets_NotInstalled(t *testing.T) {
+	cfg := testStackConfig()
+	cfg.SetupState = &setup.SetupState{DNSInstalled: true}
+	cfg.Components = nil
+
+	targets := HostTargets(cfg, HostCredentials{})
+	require.Len(t, targets, 2)
+	assert.Equal(t, "powerdns-auth", targets[0].Name)
+	assert.Nil(t, targets[0].BasicAuth)
+	assert.Equal(t, "powerdns-recursor", targets[1].Name)
+
+	assert.Empty(t, HostTargets(&config.Config{Hosts: cfg.Hosts}, HostCredentials{}))
+}
+
+func TestHostTargets_NodeExporterPort(t *testing.T) {
+	cfg := testStackConfig()
+	cfg.Components["node-exporter"] = config.ComponentConfig{Config: map[string]any{"installed": true, "port": 9200}}
+
+	targets := HostTargets(cfg, HostCredentials{})
+	assert.Equal(t, []string{"10.0.0.5:9200"}, targets[0].Targets)
+}
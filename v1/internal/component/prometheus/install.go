@@ -124,6 +124,20 @@ func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cf
 	return nil
 }
 
+// Charts returns the Helm charts Install uses for kube-prometheus-stack
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: prometheusRepoName,
+		RepoURL:  prometheusRepoURL,
+		Chart:    prometheusChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
 // buildHelmValues constructs Helm values for kube-prometheus-stack installation
 func buildHelmValues(cfg *Config) map[string]interface{} {
 	values := make(map[string]interface{})
@@ -139,6 +153,9 @@ func buildHelmValues(cfg *Config) map[string]interface{} {
 		"retentionSize":  cfg.RetentionSize,
 		"scrapeInterval": cfg.ScrapeInterval,
 	}
+	if cfg.RemoteWriteReceiverEnabled {
+		prometheusSpec["enableRemoteWriteReceiver"] = true
+	}
 
 	// Storage configuration
 	if cfg.StorageSize != "" {
@@ -230,12 +247,49 @@ func buildHelmValues(cfg *Config) map[string]interface{} {
 		if cfg.StorageClass != "" {
 			values["alertmanager"].(map[string]interface{})["alertmanagerSpec"].(map[string]interface{})["storage"].(map[string]interface{})["volumeClaimTemplate"].(map[string]interface{})["spec"].(map[string]interface{})["storageClassName"] = cfg.StorageClass
 		}
+		// Receivers and routes from the stack config replace the chart's
+		// default Alertmanager configuration
+		if cfg.Alerting != nil && len(cfg.Alerting.Receivers) > 0 {
+			values["alertmanager"].(map[string]interface{})["config"] = buildAlertmanagerConfig(cfg.Alerting)
+		}
 	} else {
 		values["alertmanager"] = map[string]interface{}{
 			"enabled": false,
 		}
 	}
 
+	// Built-in Foundry alert rules
+	if cfg.AlertRulesEnabled {
+		if groups := buildRuleGroups(cfg); groups != nil {
+			// Keep any rule maps passed through values
+			rulesMap, ok := values["additionalPrometheusRulesMap"].(map[string]interface{})
+			if !ok {
+				rulesMap = map[string]interface{}{}
+			}
+			rulesMap[rulePackName] = map[string]interface{}{
+				"additionalLabels": map[string]interface{}{
+					"app.kubernetes.io/part-of": "foundry",
+					"foundry_rule_pack":         RulePackVersion,
+				},
+				"groups": groups,
+			}
+			values["additionalPrometheusRulesMap"] = rulesMap
+		}
+	}
+
+	// k3s serves embedded etcd metrics on each control plane node
+	if _, set := values["kubeEtcd"]; !set && len(cfg.EtcdEndpoints) > 0 {
+		values["kubeEtcd"] = map[string]interface{}{
+			"enabled":   true,
+			"endpoints": cfg.EtcdEndpoints,
+			"service": map[string]interface{}{
+				"enabled":    true,
+				"port":       2381,
+				"targetPort": 2381,
+			},
+		}
+	}
+
 	// Grafana - disabled as we deploy it separately
 	values["grafana"] = map[string]interface{}{
 		"enabled": cfg.GrafanaEnabled,
@@ -338,18 +392,29 @@ func containsSubstring(s, substr string) bool {
 // - GetServiceMonitorManifest: generates ServiceMonitor YAML manifest
 
 // buildAdditionalScrapeConfigs converts ExternalTargets to Prometheus scrape configs
-// for services running outside of Kubernetes (e.g., systemd-based services)
+// for services running outside of Kubernetes (e.g., systemd-based services).
+// Targets sharing a name become one job with a static config per target; the
+// first target's path, interval, params and credentials apply to the job.
 func buildAdditionalScrapeConfigs(targets []ExternalTarget) []map[string]interface{} {
 	configs := make([]map[string]interface{}, 0, len(targets))
+	jobs := make(map[string]map[string]interface{})
 
 	for _, target := range targets {
+		staticConfig := map[string]interface{}{
+			"targets": target.Targets,
+		}
+		if len(target.Labels) > 0 {
+			staticConfig["labels"] = target.Labels
+		}
+
+		if job, ok := jobs[target.Name]; ok {
+			job["static_configs"] = append(job["static_configs"].([]map[string]interface{}), staticConfig)
+			continue
+		}
+
 		config := map[string]interface{}{
-			"job_name": target.Name,
-			"static_configs": []map[string]interface{}{
-				{
-					"targets": target.Targets,
-				},
-			},
+			"job_name":       target.Name,
+			"static_configs": []map[string]interface{}{staticConfig},
 		}
 
 		// Set metrics path (default to /metrics)
@@ -369,6 +434,20 @@ func buildAdditionalScrapeConfigs(targets []ExternalTarget) []map[string]interfa
 			config["params"] = target.Params
 		}
 
+		if target.BearerToken != "" {
+			config["authorization"] = map[string]interface{}{
+				"type":        "Bearer",
+				"credentials": target.BearerToken,
+			}
+		}
+		if target.BasicAuth != nil {
+			config["basic_auth"] = map[string]interface{}{
+				"username": target.BasicAuth.Username,
+				"password": target.BasicAuth.Password,
+			}
+		}
+
+		jobs[target.Name] = config
 		configs = append(configs, config)
 	}
 
@@ -149,6 +149,7 @@ func TestBuildHelmValues_Default(t *testing.T) {
 	assert.Equal(t, "15d", prometheusSpec["retention"])
 	assert.Equal(t, "160GB", prometheusSpec["retentionSize"]) // Must end with 'B' per Prometheus CRD spec
 	assert.Equal(t, "30s", prometheusSpec["scrapeInterval"])
+	assert.Equal(t, true, prometheusSpec["enableRemoteWriteReceiver"])
 
 	// Check Grafana is disabled
 	grafana, ok := values["grafana"].(map[string]interface{})
@@ -477,4 +478,39 @@ func TestBuildAdditionalScrapeConfigs(t *testing.T) {
 	assert.Equal(t, "/metrics", configs[2]["metrics_path"]) // Should default to /metrics
 }
 
+func TestBuildAdditionalScrapeConfigs_LabelsAndAuth(t *testing.T) {
+	targets := []ExternalTarget{
+		{
+			Name:        "node",
+			Targets:     []string{"10.0.0.1:9110"},
+			Labels:      map[string]string{"host": "infra1", "role": "openbao,dns"},
+			BearerToken: "s.token",
+		},
+		{
+			Name:      "powerdns-auth",
+			Targets:   []string{"10.0.0.1:8081"},
+			BasicAuth: &BasicAuth{Username: "prometheus", Password: "pw"},
+		},
+		{
+			Name:    "node",
+			Targets: []string{"10.0.0.2:9110"},
+			Labels:  map[string]string{"host": "node1", "role": "cluster-control-plane"},
+		},
+	}
+
+	configs := buildAdditionalScrapeConfigs(targets)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "node", configs[0]["job_name"])
+	assert.Equal(t, []map[string]interface{}{
+		{"targets": []string{"10.0.0.1:9110"}, "labels": map[string]string{"host": "infra1", "role": "openbao,dns"}},
+		{"targets": []string{"10.0.0.2:9110"}, "labels": map[string]string{"host": "node1", "role": "cluster-control-plane"}},
+	}, configs[0]["static_configs"])
+	assert.Equal(t, map[string]interface{}{"type": "Bearer", "credentials": "s.token"}, configs[0]["authorization"])
+
+	assert.Equal(t, map[string]interface{}{"username": "prometheus", "password": "pw"}, configs[1]["basic_auth"])
+	_, hasAuthorization := configs[1]["authorization"]
+	assert.False(t, hasAuthorization)
+}
+
 // NOTE: ServiceMonitor YAML generation tests are in servicemonitors_test.go
@@ -0,0 +1,405 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RulePackVersion is the version of the built-in alert rule pack. Every rule
+// carries it as the foundry_rule_pack label, so alerts can be traced to the
+// pack that raised them.
+const RulePackVersion = "v1"
+
+// rulePackName is the additionalPrometheusRulesMap key the pack is installed
+// under; the chart prefixes it with the release name
+const rulePackName = "foundry-rules"
+
+// AlertRuleConfig overrides one built-in alert rule from the stack config.
+// Unset fields keep the rule's defaults.
+type AlertRuleConfig struct {
+	// Enabled turns the rule off when false
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// Threshold replaces the rule's threshold, in the unit the rule documents
+	Threshold *float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+
+	// For is how long the condition must hold before the alert fires (e.g. "10m")
+	For string `json:"for,omitempty" yaml:"for,omitempty"`
+
+	// Severity is the severity label: critical, warning or info
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// AlertRule is a built-in alert rule
+type AlertRule struct {
+	// Name is the key the rule is configured under in alert_rules
+	Name string
+
+	// Alert is the Prometheus alert name
+	Alert string
+
+	// Component is the Foundry component the rule watches
+	Component string
+
+	// Threshold is the default threshold, in Unit. Rules without a Unit
+	// have no threshold.
+	Threshold float64
+	Unit      string
+
+	For      string
+	Severity string
+	Summary  string
+
+	// expr builds the PromQL expression for a threshold
+	expr func(cfg *Config, threshold string) string
+}
+
+// builtinRules is the rule pack, in the order it is installed
+var builtinRules = []AlertRule{
+	{
+		Name:      "openbao_sealed",
+		Alert:     "FoundryOpenBAOSealed",
+		Component: "openbao",
+		For:       "1m",
+		Severity:  "critical",
+		Summary:   "OpenBAO on {{ $labels.instance }} is sealed",
+		expr: func(_ *Config, _ string) string {
+			return `vault_core_unsealed{job="openbao"} == 0`
+		},
+	},
+	{
+		Name:      "openbao_unreachable",
+		Alert:     "FoundryOpenBAOUnreachable",
+		Component: "openbao",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "OpenBAO on {{ $labels.instance }} cannot be scraped",
+		expr: func(_ *Config, _ string) string {
+			return `up{job="openbao"} == 0`
+		},
+	},
+	{
+		Name:      "powerdns_auth_down",
+		Alert:     "FoundryPowerDNSAuthDown",
+		Component: "dns",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "PowerDNS authoritative server on {{ $labels.instance }} is down",
+		expr: func(_ *Config, _ string) string {
+			return `up{job="powerdns-auth"} == 0`
+		},
+	},
+	{
+		Name:      "powerdns_recursor_down",
+		Alert:     "FoundryPowerDNSRecursorDown",
+		Component: "dns",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "PowerDNS recursor on {{ $labels.instance }} is down",
+		expr: func(_ *Config, _ string) string {
+			return `up{job="powerdns-recursor"} == 0`
+		},
+	},
+	{
+		Name:      "zot_down",
+		Alert:     "FoundryZotDown",
+		Component: "zot",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "Zot registry on {{ $labels.instance }} is down",
+		expr: func(_ *Config, _ string) string {
+			return `up{job="zot"} == 0`
+		},
+	},
+	{
+		Name:      "zot_disk_full",
+		Alert:     "FoundryZotDiskNearFull",
+		Component: "zot",
+		Threshold: 85,
+		Unit:      "percent used",
+		For:       "15m",
+		Severity:  "warning",
+		Summary:   "A filesystem on the Zot host {{ $labels.instance }} is {{ $value | humanize }}% full",
+		expr: func(cfg *Config, threshold string) string {
+			// PromQL strings unescape backslashes, so the regexp's are doubled
+			host := strings.ReplaceAll(regexp.QuoteMeta(cfg.ZotHost), `\`, `\\`)
+			selector := fmt.Sprintf(`instance=~"%s:.*",fstype!~"tmpfs|overlay|squashfs"`, host)
+			return fmt.Sprintf(`100 * (1 - node_filesystem_avail_bytes{%s} / node_filesystem_size_bytes{%s}) > %s`, selector, selector, threshold)
+		},
+	},
+	{
+		Name:      "longhorn_volume_degraded",
+		Alert:     "FoundryLonghornVolumeDegraded",
+		Component: "storage",
+		For:       "10m",
+		Severity:  "warning",
+		Summary:   "Longhorn volume {{ $labels.volume }} is degraded or faulted",
+		expr: func(_ *Config, _ string) string {
+			// Robustness: 1 healthy, 2 degraded, 3 faulted
+			return `longhorn_volume_robustness >= 2`
+		},
+	},
+	{
+		Name:      "disk_smart_failing",
+		Alert:     "FoundryDiskSMARTFailing",
+		Component: "storage",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "SMART reports disk {{ $labels.device }} on {{ $labels.instance }} is failing",
+		expr: func(_ *Config, _ string) string {
+			return `smartctl_device_smart_status == 0`
+		},
+	},
+	{
+		Name:      "disk_prefail_attribute",
+		Alert:     "FoundryDiskPreFailAttribute",
+		Component: "storage",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "Pre-fail attribute {{ $labels.attribute_name }} of disk {{ $labels.device }} on {{ $labels.instance }} is at its failure threshold",
+		expr: func(_ *Config, _ string) string {
+			// A threshold of 0 means the attribute can never fail
+			return `smartctl_device_attribute{attribute_flags_short=~"P.*",attribute_value_type="value"}` +
+				` <= ignoring(attribute_value_type) smartctl_device_attribute{attribute_value_type="thresh"}` +
+				` and ignoring(attribute_value_type) smartctl_device_attribute{attribute_value_type="thresh"} > 0`
+		},
+	},
+	{
+		Name:      "disk_nvme_critical_warning",
+		Alert:     "FoundryDiskNVMeCriticalWarning",
+		Component: "storage",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "NVMe disk {{ $labels.device }} on {{ $labels.instance }} raised critical warning {{ $value }}",
+		expr: func(_ *Config, _ string) string {
+			return `smartctl_device_critical_warning > 0`
+		},
+	},
+	{
+		Name:      "disk_wearout",
+		Alert:     "FoundryDiskWearingOut",
+		Component: "storage",
+		Threshold: 90,
+		Unit:      "percent of rated endurance used",
+		For:       "1h",
+		Severity:  "warning",
+		Summary:   "Disk {{ $labels.device }} on {{ $labels.instance }} has used {{ $value }}% of its rated endurance",
+		expr: func(_ *Config, threshold string) string {
+			return fmt.Sprintf(`smartctl_device_percentage_used >= %s`, threshold)
+		},
+	},
+	{
+		Name:      "velero_backup_failed",
+		Alert:     "FoundryVeleroBackupFailed",
+		Component: "velero",
+		Severity:  "warning",
+		Summary:   "Velero backups of schedule {{ $labels.schedule }} are failing",
+		expr: func(_ *Config, _ string) string {
+			return `increase(velero_backup_failure_total[1h]) > 0 or increase(velero_backup_partial_failure_total[1h]) > 0`
+		},
+	},
+	{
+		Name:      "velero_backup_stale",
+		Alert:     "FoundryVeleroBackupStale",
+		Component: "velero",
+		Threshold: 26,
+		Unit:      "hours",
+		Severity:  "warning",
+		Summary:   "Velero schedule {{ $labels.schedule }} has not completed a backup in {{ $value | humanizeDuration }}",
+		expr: func(_ *Config, threshold string) string {
+			return fmt.Sprintf(`time() - velero_backup_last_successful_timestamp{schedule!=""} > %s * 3600`, threshold)
+		},
+	},
+	{
+		Name:      "certificate_expiry",
+		Alert:     "FoundryCertificateExpiringSoon",
+		Component: "cert-manager",
+		Threshold: 14,
+		Unit:      "days",
+		For:       "1h",
+		Severity:  "warning",
+		Summary:   "Certificate {{ $labels.namespace }}/{{ $labels.name }} expires in {{ $value | humanizeDuration }}",
+		expr: func(_ *Config, threshold string) string {
+			return fmt.Sprintf(`certmanager_certificate_expiration_timestamp_seconds - time() < %s * 86400`, threshold)
+		},
+	},
+	{
+		Name:      "probe_failed",
+		Alert:     "FoundryProbeFailed",
+		Component: "blackbox-exporter",
+		For:       "5m",
+		Severity:  "critical",
+		Summary:   "{{ $labels.probe_name }} ({{ $labels.instance }}) is not answering {{ $labels.probe_module }} probes",
+		expr: func(_ *Config, _ string) string {
+			return `probe_success{probe_source!=""} == 0`
+		},
+	},
+	{
+		Name:      "probe_certificate_expiry",
+		Alert:     "FoundryProbeCertificateExpiringSoon",
+		Component: "blackbox-exporter",
+		Threshold: 14,
+		Unit:      "days",
+		For:       "1h",
+		Severity:  "warning",
+		Summary:   "The certificate served by {{ $labels.instance }} expires in {{ $value | humanizeDuration }}",
+		expr: func(_ *Config, threshold string) string {
+			return fmt.Sprintf(`probe_ssl_earliest_cert_expiry{probe_source!=""} - time() < %s * 86400`, threshold)
+		},
+	},
+	{
+		Name:      "gateway_controller_errors",
+		Alert:     "FoundryGatewayControllerReconcileErrors",
+		Component: "gateway-controller",
+		Threshold: 0,
+		Unit:      "errors per 15 minutes",
+		For:       "15m",
+		Severity:  "warning",
+		Summary:   "The gateway controller is failing to reconcile Gateway listeners",
+		expr: func(_ *Config, threshold string) string {
+			return fmt.Sprintf(`increase(foundry_gateway_reconcile_errors_total[15m]) > %s`, threshold)
+		},
+	},
+	{
+		Name:      "etcd_no_leader",
+		Alert:     "FoundryEtcdNoLeader",
+		Component: "k3s",
+		For:       "1m",
+		Severity:  "critical",
+		Summary:   "etcd member {{ $labels.instance }} has no leader",
+		expr: func(_ *Config, _ string) string {
+			return `etcd_server_has_leader == 0`
+		},
+	},
+}
+
+// promDuration matches Prometheus durations such as 30s, 10m or 1h30m
+var promDuration = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
+// validateAlertRules checks the alert_rules overrides
+func validateAlertRules(overrides map[string]AlertRuleConfig) error {
+	known := map[string]AlertRule{}
+	for _, r := range builtinRules {
+		known[r.Name] = r
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		override := overrides[name]
+		rule, ok := known[name]
+		if !ok {
+			return fmt.Errorf("unknown alert rule %q in alert_rules", name)
+		}
+		if override.Threshold != nil && rule.Unit == "" {
+			return fmt.Errorf("alert rule %q has no threshold", name)
+		}
+		if override.For != "" && !promDuration.MatchString(override.For) {
+			return fmt.Errorf("alert rule %q: invalid for %q (use a duration like 10m)", name, override.For)
+		}
+		switch override.Severity {
+		case "", "critical", "warning", "info":
+		default:
+			return fmt.Errorf("alert rule %q: severity must be critical, warning or info", name)
+		}
+	}
+	return nil
+}
+
+// buildRuleGroups renders the enabled built-in rules, with the stack config
+// overrides applied, as a Prometheus rule group
+func buildRuleGroups(cfg *Config) []map[string]interface{} {
+	var rules []map[string]interface{}
+	for _, r := range builtinRules {
+		override := cfg.AlertRules[r.Name]
+		if override.Enabled != nil && !*override.Enabled {
+			continue
+		}
+		// The Zot disk rule needs the Zot host to find its filesystems
+		if r.Name == "zot_disk_full" && cfg.ZotHost == "" {
+			continue
+		}
+
+		threshold := r.Threshold
+		if override.Threshold != nil {
+			threshold = *override.Threshold
+		}
+		severity := r.Severity
+		if override.Severity != "" {
+			severity = override.Severity
+		}
+		forDuration := r.For
+		if override.For != "" {
+			forDuration = override.For
+		}
+
+		rule := map[string]interface{}{
+			"alert": r.Alert,
+			"expr":  r.expr(cfg, strconv.FormatFloat(threshold, 'f', -1, 64)),
+			"labels": map[string]interface{}{
+				"severity":          severity,
+				"component":         r.Component,
+				"foundry_rule_pack": RulePackVersion,
+			},
+			"annotations": map[string]interface{}{
+				"summary": r.Summary,
+			},
+		}
+		if forDuration != "" {
+			rule["for"] = forDuration
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return []map[string]interface{}{{
+		"name":  "foundry.rules",
+		"rules": rules,
+	}}
+}
+
+// parseAlertRules reads alert_rules from a component config, accepting both
+// typed overrides and maps from YAML
+func parseAlertRules(raw interface{}) map[string]AlertRuleConfig {
+	switch v := raw.(type) {
+	case map[string]AlertRuleConfig:
+		return v
+	case map[string]interface{}:
+		rules := make(map[string]AlertRuleConfig, len(v))
+		for name, entry := range v {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				rules[name] = AlertRuleConfig{}
+				continue
+			}
+			var rule AlertRuleConfig
+			if enabled, ok := m["enabled"].(bool); ok {
+				rule.Enabled = &enabled
+			}
+			switch t := m["threshold"].(type) {
+			case int:
+				threshold := float64(t)
+				rule.Threshold = &threshold
+			case float64:
+				threshold := t
+				rule.Threshold = &threshold
+			}
+			if forDuration, ok := m["for"].(string); ok {
+				rule.For = forDuration
+			}
+			if severity, ok := m["severity"].(string); ok {
+				rule.Severity = severity
+			}
+			rules[name] = rule
+		}
+		return rules
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rulesByAlert indexes the rendered rules of the Foundry rule group
+func rulesByAlert(t *testing.T, values map[string]interface{}) map[string]map[string]interface{} {
+	t.Helper()
+	rulesMap, ok := values["additionalPrometheusRulesMap"].(map[string]interface{})
+	require.True(t, ok, "rule pack should be installed")
+	pack := rulesMap[rulePackName].(map[string]interface{})
+	groups := pack["groups"].([]map[string]interface{})
+	require.Len(t, groups, 1)
+
+	rules := map[string]map[string]interface{}{}
+	for _, r := range groups[0]["rules"].([]map[string]interface{}) {
+		rules[r["alert"].(string)] = r
+	}
+	return rules
+}
+
+func TestBuildHelmValues_RulePack(t *testing.T) {
+	cfg := DefaultConfig()
+	rules := rulesByAlert(t, buildHelmValues(cfg))
+
+	// Every rule but the Zot disk rule, which needs the Zot host
+	assert.Len(t, rules, len(builtinRules)-1)
+	assert.NotContains(t, rules, "FoundryZotDiskNearFull")
+
+	sealed := rules["FoundryOpenBAOSealed"]
+	assert.Equal(t, `vault_core_unsealed{job="openbao"} == 0`, sealed["expr"])
+	assert.Equal(t, "1m", sealed["for"])
+	labels := sealed["labels"].(map[string]interface{})
+	assert.Equal(t, "critical", labels["severity"])
+	assert.Equal(t, RulePackVersion, labels["foundry_rule_pack"])
+
+	certs := rules["FoundryCertificateExpiringSoon"]
+	assert.Equal(t, "certmanager_certificate_expiration_timestamp_seconds - time() < 14 * 86400", certs["expr"])
+
+	// Probe alerts only cover the targets the blackbox-exporter component labels
+	assert.Equal(t, `probe_success{probe_source!=""} == 0`, rules["FoundryProbeFailed"]["expr"])
+	assert.Equal(t, `probe_ssl_earliest_cert_expiry{probe_source!=""} - time() < 14 * 86400`, rules["FoundryProbeCertificateExpiringSoon"]["expr"])
+
+	assert.Equal(t, "smartctl_device_percentage_used >= 90", rules["FoundryDiskWearingOut"]["expr"])
+
+	// Rules without a duration fire on the first failing evaluation
+	assert.NotContains(t, rules["FoundryVeleroBackupFailed"], "for")
+}
+
+func TestBuildHelmValues_RulePackOverrides(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{
+		"zot_host": "10.0.0.5",
+		"alert_rules": map[string]interface{}{
+			"etcd_no_leader":      map[string]interface{}{"enabled": false},
+			"certificate_expiry":  map[string]interface{}{"threshold": 30, "severity": "critical"},
+			"zot_disk_full":       map[string]interface{}{"threshold": 90.5, "for": "1h"},
+			"velero_backup_stale": map[string]interface{}{"threshold": 50},
+		},
+	})
+	require.NoError(t, err)
+
+	rules := rulesByAlert(t, buildHelmValues(cfg))
+	assert.NotContains(t, rules, "FoundryEtcdNoLeader")
+
+	certs := rules["FoundryCertificateExpiringSoon"]
+	assert.Contains(t, certs["expr"], "< 30 * 86400")
+	assert.Equal(t, "critical", certs["labels"].(map[string]interface{})["severity"])
+
+	disk := rules["FoundryZotDiskNearFull"]
+	assert.Contains(t, disk["expr"], `instance=~"10\\.0\\.0\\.5:.*"`)
+	assert.Contains(t, disk["expr"], "> 90.5")
+	assert.Equal(t, "1h", disk["for"])
+
+	assert.Contains(t, rules["FoundryVeleroBackupStale"]["expr"], "> 50 * 3600")
+}
+
+func TestBuildHelmValues_RulePackDisabled(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AlertRulesEnabled = false
+
+	values := buildHelmValues(cfg)
+	assert.NotContains(t, values, "additionalPrometheusRulesMap")
+}
+
+func TestBuildHelmValues_RulePackKeepsUserRules(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Values = map[string]interface{}{
+		"additionalPrometheusRulesMap": map[string]interface{}{
+			"my-rules": map[string]interface{}{"groups": []interface{}{}},
+		},
+	}
+
+	rulesMap := buildHelmValues(cfg)["additionalPrometheusRulesMap"].(map[string]interface{})
+	assert.Contains(t, rulesMap, "my-rules")
+	assert.Contains(t, rulesMap, rulePackName)
+}
+
+func TestBuildHelmValues_EtcdEndpoints(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.NotContains(t, buildHelmValues(cfg), "kubeEtcd")
+
+	cfg.EtcdEndpoints = []string{"10.0.0.1", "10.0.0.2"}
+	etcd := buildHelmValues(cfg)["kubeEtcd"].(map[string]interface{})
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, etcd["endpoints"])
+	assert.Equal(t, 2381, etcd["service"].(map[string]interface{})["port"])
+}
+
+func TestValidateAlertRules(t *testing.T) {
+	threshold := 5.0
+	tests := []struct {
+		name    string
+		rules   map[string]AlertRuleConfig
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", rules: map[string]AlertRuleConfig{"zot_disk_full": {Threshold: &threshold, For: "1h30m", Severity: "info"}}},
+		{name: "unknown rule", rules: map[string]AlertRuleConfig{"nope": {}}, wantErr: "unknown alert rule"},
+		{name: "threshold on thresholdless rule", rules: map[string]AlertRuleConfig{"zot_down": {Threshold: &threshold}}, wantErr: "has no threshold"},
+		{name: "bad duration", rules: map[string]AlertRuleConfig{"zot_down": {For: "ten minutes"}}, wantErr: "invalid for"},
+		{name: "bad severity", rules: map[string]AlertRuleConfig{"zot_down": {Severity: "page"}}, wantErr: "severity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAlertRules(tt.rules)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -28,6 +28,22 @@ type ExternalTarget struct {
 
 	// ScrapeInterval overrides the default scrape interval for this target
 	ScrapeInterval string `json:"scrape_interval,omitempty" yaml:"scrape_interval,omitempty"`
+
+	// Labels are added to every series scraped from Targets. Entries that
+	// share a Name are scraped by one job, each with its own labels.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// BearerToken is sent in the Authorization header
+	BearerToken string `json:"bearer_token,omitempty" yaml:"bearer_token,omitempty"`
+
+	// BasicAuth authenticates scrapes with a username and password
+	BasicAuth *BasicAuth `json:"basic_auth,omitempty" yaml:"basic_auth,omitempty"`
+}
+
+// BasicAuth holds HTTP basic auth credentials for a scrape target
+type BasicAuth struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
 }
 
 // Config holds Prometheus stack component configuration
@@ -50,9 +66,17 @@ type Config struct {
 	// StorageSize is the size of storage for Prometheus TSDB
 	StorageSize string `json:"storage_size" yaml:"storage_size"`
 
+	// RemoteWriteReceiverEnabled accepts samples on /api/v1/write, which the
+	// alloy collector uses for OTLP metrics
+	RemoteWriteReceiverEnabled bool `json:"remote_write_receiver_enabled" yaml:"remote_write_receiver_enabled"`
+
 	// AlertmanagerEnabled enables Alertmanager deployment
 	AlertmanagerEnabled bool `json:"alertmanager_enabled" yaml:"alertmanager_enabled"`
 
+	// Alerting configures Alertmanager's receivers, routes and inhibition
+	// rules. Without receivers the chart's default configuration is kept.
+	Alerting *AlertingConfig `json:"alerting,omitempty" yaml:"alerting,omitempty"`
+
 	// GrafanaEnabled enables Grafana deployment (we disable since we deploy separately)
 	GrafanaEnabled bool `json:"grafana_enabled" yaml:"grafana_enabled"`
 
@@ -75,6 +99,19 @@ type Config struct {
 	// These are systemd-based services running outside of Kubernetes
 	ExternalTargets []ExternalTarget `json:"external_targets" yaml:"external_targets"`
 
+	// AlertRulesEnabled installs the built-in Foundry alert rule pack
+	AlertRulesEnabled bool `json:"alert_rules_enabled" yaml:"alert_rules_enabled"`
+
+	// AlertRules overrides built-in alert rules, keyed by rule name
+	AlertRules map[string]AlertRuleConfig `json:"alert_rules,omitempty" yaml:"alert_rules,omitempty"`
+
+	// ZotHost is the address of the Zot host, whose filesystems the Zot disk
+	// rule watches
+	ZotHost string `json:"zot_host,omitempty" yaml:"zot_host,omitempty"`
+
+	// EtcdEndpoints are the control plane addresses serving k3s etcd metrics
+	EtcdEndpoints []string `json:"etcd_endpoints,omitempty" yaml:"etcd_endpoints,omitempty"`
+
 	// Values allows passing additional Helm values
 	Values map[string]interface{} `json:"values" yaml:",inline"`
 }
@@ -180,20 +217,22 @@ func (c *Component) Dependencies() []string {
 // DefaultConfig returns a Config with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
-		Version:                 "67.4.0", // kube-prometheus-stack chart version
-		Namespace:               "monitoring",
-		RetentionDays:           15,
-		RetentionSize:           "160GB", // Must end with 'B' per Prometheus CRD spec; ~80% of StorageSize for WAL headroom
-		StorageClass:            "",      // Use cluster default
-		StorageSize:             "200Gi",
-		AlertmanagerEnabled:     true,
-		GrafanaEnabled:          false, // We deploy Grafana separately
-		NodeExporterEnabled:     true,
-		KubeStateMetricsEnabled: true,
-		ScrapeInterval:          "30s",
-		IngressEnabled:          false,
-		ExternalTargets:         []ExternalTarget{},
-		Values:                  make(map[string]interface{}),
+		Version:                    "67.4.0", // kube-prometheus-stack chart version
+		Namespace:                  "monitoring",
+		RetentionDays:              15,
+		RetentionSize:              "160GB", // Must end with 'B' per Prometheus CRD spec; ~80% of StorageSize for WAL headroom
+		StorageClass:               "",      // Use cluster default
+		StorageSize:                "200Gi",
+		RemoteWriteReceiverEnabled: true,
+		AlertmanagerEnabled:        true,
+		GrafanaEnabled:             false, // We deploy Grafana separately
+		NodeExporterEnabled:        true,
+		KubeStateMetricsEnabled:    true,
+		ScrapeInterval:             "30s",
+		IngressEnabled:             false,
+		ExternalTargets:            []ExternalTarget{},
+		AlertRulesEnabled:          true,
+		Values:                     make(map[string]interface{}),
 	}
 }
 
@@ -225,6 +264,10 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 		config.StorageSize = storageSize
 	}
 
+	if remoteWriteReceiverEnabled, ok := cfg.GetBool("remote_write_receiver_enabled"); ok {
+		config.RemoteWriteReceiverEnabled = remoteWriteReceiverEnabled
+	}
+
 	if alertmanagerEnabled, ok := cfg.GetBool("alertmanager_enabled"); ok {
 		config.AlertmanagerEnabled = alertmanagerEnabled
 	}
@@ -257,14 +300,33 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 		config.Values = values
 	}
 
+	if alertRulesEnabled, ok := cfg.GetBool("alert_rules_enabled"); ok {
+		config.AlertRulesEnabled = alertRulesEnabled
+	}
+
+	config.AlertRules = parseAlertRules(cfg["alert_rules"])
+
+	config.Alerting = parseAlerting(cfg["alerting"])
+
+	if zotHost, ok := cfg.GetString("zot_host"); ok {
+		config.ZotHost = zotHost
+	}
+
+	if etcdEndpoints, ok := cfg.GetStringSlice("etcd_endpoints"); ok {
+		config.EtcdEndpoints = etcdEndpoints
+	}
+
 	// Parse external targets for scraping non-K8s services
 	if targets, ok := cfg["external_targets"]; ok {
 		if targetSlice, ok := targets.([]ExternalTarget); ok {
 			config.ExternalTargets = targetSlice
 		} else if targetInterfaces, ok := targets.([]interface{}); ok {
-			// Handle case where targets come as []interface{} from YAML parsing
+			// Handle case where targets come as []interface{} from YAML parsing,
+			// possibly followed by generated host targets
 			for _, ti := range targetInterfaces {
-				if tm, ok := ti.(map[string]interface{}); ok {
+				if target, ok := ti.(ExternalTarget); ok {
+					config.ExternalTargets = append(config.ExternalTargets, target)
+				} else if tm, ok := ti.(map[string]interface{}); ok {
 					target := ExternalTarget{}
 					if name, ok := tm["name"].(string); ok {
 						target.Name = name
@@ -282,6 +344,22 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 							}
 						}
 					}
+					if labels, ok := tm["labels"].(map[string]interface{}); ok {
+						target.Labels = make(map[string]string)
+						for k, v := range labels {
+							if s, ok := v.(string); ok {
+								target.Labels[k] = s
+							}
+						}
+					}
+					if token, ok := tm["bearer_token"].(string); ok {
+						target.BearerToken = token
+					}
+					if auth, ok := tm["basic_auth"].(map[string]interface{}); ok {
+						target.BasicAuth = &BasicAuth{}
+						target.BasicAuth.Username, _ = auth["username"].(string)
+						target.BasicAuth.Password, _ = auth["password"].(string)
+					}
 					if params, ok := tm["params"].(map[string]interface{}); ok {
 						target.Params = make(map[string][]string)
 						for k, v := range params {
@@ -330,6 +408,19 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("ingress_host is required when ingress is enabled")
 	}
 
+	if err := validateAlertRules(c.AlertRules); err != nil {
+		return err
+	}
+
+	if c.Alerting != nil {
+		if len(c.Alerting.Receivers) > 0 && !c.AlertmanagerEnabled {
+			return fmt.Errorf("alerting receivers need alertmanager_enabled")
+		}
+		if err := c.Alerting.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -359,6 +450,11 @@ func (c *Config) GetPrometheusEndpoint() string {
 	return fmt.Sprintf("http://kube-prometheus-stack-prometheus.%s.svc.cluster.local:9090", c.Namespace)
 }
 
+// GetRemoteWriteEndpoint returns the Prometheus remote-write receiver URL
+func (c *Config) GetRemoteWriteEndpoint() string {
+	return c.GetPrometheusEndpoint() + "/api/v1/write"
+}
+
 // GetAlertmanagerEndpoint returns the Alertmanager endpoint URL for internal cluster access
 func (c *Config) GetAlertmanagerEndpoint() string {
 	return fmt.Sprintf("http://kube-prometheus-stack-alertmanager.%s.svc.cluster.local:9093", c.Namespace)
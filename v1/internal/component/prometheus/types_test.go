@@ -21,6 +21,8 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "", config.StorageClass)
 	assert.Equal(t, "200Gi", config.StorageSize)
 	assert.True(t, config.AlertmanagerEnabled)
+	assert.True(t, config.RemoteWriteReceiverEnabled)
+	assert.Equal(t, "http://kube-prometheus-stack-prometheus.monitoring.svc.cluster.local:9090/api/v1/write", config.GetRemoteWriteEndpoint())
 	assert.False(t, config.GrafanaEnabled)
 	assert.True(t, config.NodeExporterEnabled)
 	assert.True(t, config.KubeStateMetricsEnabled)
@@ -93,6 +95,33 @@ func TestParseConfig_WithCustomValues(t *testing.T) {
 	assert.NotNil(t, config.Values["nested"])
 }
 
+func TestParseConfig_ExternalTargets(t *testing.T) {
+	cfg := component.ComponentConfig{
+		"external_targets": []interface{}{
+			map[string]interface{}{
+				"name":         "nas",
+				"targets":      []interface{}{"10.0.0.50:9100"},
+				"labels":       map[string]interface{}{"role": "storage"},
+				"bearer_token": "token",
+				"basic_auth":   map[string]interface{}{"username": "prometheus", "password": "pw"},
+			},
+			ExternalTarget{Name: "node", Targets: []string{"10.0.0.5:9110"}},
+		},
+	}
+
+	config, err := ParseConfig(cfg)
+	require.NoError(t, err)
+	require.Len(t, config.ExternalTargets, 2)
+	assert.Equal(t, ExternalTarget{
+		Name:        "nas",
+		Targets:     []string{"10.0.0.50:9100"},
+		Labels:      map[string]string{"role": "storage"},
+		BearerToken: "token",
+		BasicAuth:   &BasicAuth{Username: "prometheus", Password: "pw"},
+	}, config.ExternalTargets[0])
+	assert.Equal(t, "node", config.ExternalTargets[1].Name)
+}
+
 func TestValidate_Success(t *testing.T) {
 	config := &Config{
 		RetentionDays: 15,
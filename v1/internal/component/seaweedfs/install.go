@@ -141,6 +141,20 @@ func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cf
 	return nil
 }
 
+// Charts returns the Helm charts Install uses for SeaweedFS
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: seaweedfsRepoName,
+		RepoURL:  seaweedfsRepoURL,
+		Chart:    seaweedfsChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
 // buildHelmValues constructs Helm values for SeaweedFS installation
 func buildHelmValues(cfg *Config) map[string]interface{} {
 	values := make(map[string]interface{})
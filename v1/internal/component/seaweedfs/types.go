@@ -12,6 +12,17 @@ import (
 	"github.com/catalystcommunity/foundry/v1/internal/k8s"
 )
 
+// CredentialsSecretPath is where the stack's shared S3 credential lives in
+// OpenBAO
+const CredentialsSecretPath = "seaweedfs"
+
+// AccessKeyRef and SecretKeyRef are what components.seaweedfs holds in place
+// of the shared credential
+var (
+	AccessKeyRef = fmt.Sprintf("${secret:%s:access_key}", CredentialsSecretPath)
+	SecretKeyRef = fmt.Sprintf("${secret:%s:secret_key}", CredentialsSecretPath)
+)
+
 // Config holds SeaweedFS component configuration
 type Config struct {
 	// Version is the Helm chart version to install
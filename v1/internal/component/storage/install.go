@@ -36,16 +36,27 @@ func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cf
 		cfg = DefaultConfig()
 	}
 
+	var err error
 	switch cfg.Backend {
 	case BackendLocalPath:
-		return installLocalPath(ctx, helmClient, k8sClient, cfg)
+		err = installLocalPath(ctx, helmClient, k8sClient, cfg)
 	case BackendNFS:
-		return installNFS(ctx, helmClient, k8sClient, cfg)
+		err = installNFS(ctx, helmClient, k8sClient, cfg)
 	case BackendLonghorn:
-		return installLonghorn(ctx, helmClient, k8sClient, cfg)
+		err = installLonghorn(ctx, helmClient, k8sClient, cfg)
+	case BackendTrueNAS:
+		err = installTrueNAS(ctx, helmClient, k8sClient, cfg)
 	default:
 		return fmt.Errorf("unsupported storage backend: %s", cfg.Backend)
 	}
+	if err != nil {
+		return err
+	}
+
+	if cfg.SmartExporter != nil && cfg.SmartExporter.Enabled {
+		return installSmartExporter(ctx, helmClient, k8sClient, cfg.SmartExporter)
+	}
+	return nil
 }
 
 // installLocalPath installs Rancher's local-path-provisioner
@@ -89,10 +100,7 @@ func installLocalPath(ctx context.Context, helmClient HelmClient, k8sClient K8sC
 
 	// Build values
 	values := buildLocalPathValues(cfg)
-	version := cfg.Version
-	if version == "" || version == "0.0.28" {
-		version = "0.0.36"
-	}
+	version := chartVersion(cfg)
 
 	// Check if helm release already exists (for upgrades when we installed via helm)
 	var releaseExists bool
@@ -150,6 +158,100 @@ func installLocalPath(ctx context.Context, helmClient HelmClient, k8sClient K8sC
 	return nil
 }
 
+// chartVersion returns the chart version to install for the configured
+// backend. The version field defaults to the local-path chart, so the other
+// backends treat local-path versions as unset.
+func chartVersion(cfg *Config) string {
+	version := cfg.Version
+	switch cfg.Backend {
+	case BackendNFS:
+		if version == "" || version == "0.0.28" || version == "0.0.36" {
+			return "4.0.18" // nfs-subdir-external-provisioner chart version
+		}
+	case BackendLonghorn:
+		if version == "" || version == "0.0.28" || version == "0.0.36" {
+			return "1.7.2" // Longhorn chart version
+		}
+	case BackendTrueNAS:
+		if version == "" || version == "0.0.28" || version == "0.0.36" {
+			return "0.14.7" // democratic-csi chart version
+		}
+	default:
+		if version == "" || version == "0.0.28" {
+			return "0.0.36"
+		}
+	}
+	return version
+}
+
+// Charts returns the Helm charts Install uses for the configured backend
+// and the optional smartctl_exporter
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	charts := backendCharts(cfg)
+	if cfg.SmartExporter != nil && cfg.SmartExporter.Enabled {
+		charts = append(charts, helm.ChartSource{
+			RepoName: smartExporterRepoName,
+			RepoURL:  smartExporterRepoURL,
+			Chart:    smartExporterChart,
+			Version:  cfg.SmartExporter.Version,
+			Values:   buildSmartExporterValues(true),
+		})
+	}
+	return charts
+}
+
+// backendCharts returns the Helm charts of the configured backend
+func backendCharts(cfg *Config) []helm.ChartSource {
+	switch cfg.Backend {
+	case BackendNFS:
+		return []helm.ChartSource{{
+			RepoName: nfsRepoName,
+			RepoURL:  nfsRepoURL,
+			Chart:    nfsChart,
+			Version:  chartVersion(cfg),
+			Values:   buildNFSValues(cfg),
+		}}
+	case BackendLonghorn:
+		return []helm.ChartSource{{
+			RepoName: longhornRepoName,
+			RepoURL:  longhornRepoURL,
+			Chart:    longhornChart,
+			Version:  chartVersion(cfg),
+			Values:   buildLonghornValues(cfg),
+		}}
+	case BackendTrueNAS:
+		charts := []helm.ChartSource{{
+			RepoName: snapshotControllerRepoName,
+			RepoURL:  snapshotControllerRepoURL,
+			Chart:    snapshotControllerChart,
+			Version:  snapshotControllerVersion,
+			Values:   map[string]interface{}{},
+		}}
+		if cfg.TrueNAS != nil {
+			for _, driver := range cfg.TrueNAS.Drivers {
+				charts = append(charts, helm.ChartSource{
+					RepoName: democraticCSIRepoName,
+					RepoURL:  democraticCSIRepoURL,
+					Chart:    democraticCSIChart,
+					Version:  chartVersion(cfg),
+					Values:   buildTrueNASValues(cfg, driver),
+				})
+			}
+		}
+		return charts
+	case BackendLocalPath:
+		return []helm.ChartSource{{
+			Chart:   localPathChart,
+			Version: chartVersion(cfg),
+			Values:  buildLocalPathValues(cfg),
+		}}
+	}
+	return nil
+}
+
 func hasLocalPathProvisioner(ctx context.Context, k8sClient K8sClient, targetNamespace string) (bool, error) {
 	if k8sClient == nil {
 		return false, nil
@@ -225,11 +327,7 @@ func installNFS(ctx context.Context, helmClient HelmClient, k8sClient K8sClient,
 	// Build values
 	values := buildNFSValues(cfg)
 
-	// Set default version for NFS provisioner
-	version := cfg.Version
-	if version == "" || version == "0.0.28" || version == "0.0.36" { // local-path defaults
-		version = "4.0.18" // nfs-subdir-external-provisioner chart version
-	}
+	version := chartVersion(cfg)
 
 	// Check if release already exists
 	var releaseExists bool
@@ -360,11 +458,7 @@ func installLonghorn(ctx context.Context, helmClient HelmClient, k8sClient K8sCl
 	// Build values
 	values := buildLonghornValues(cfg)
 
-	// Set default version for Longhorn
-	version := cfg.Version
-	if version == "" || version == "0.0.28" || version == "0.0.36" { // local-path defaults
-		version = "1.7.2" // Longhorn chart version
-	}
+	version := chartVersion(cfg)
 
 	// Use longhorn-system namespace
 	namespace := cfg.Namespace
@@ -446,6 +540,10 @@ func installLonghorn(ctx context.Context, helmClient HelmClient, k8sClient K8sCl
 		return err
 	}
 
+	if err := configureLonghornSnapshots(ctx, k8sClient, namespace, cfg.Longhorn); err != nil {
+		return err
+	}
+
 	fmt.Println("  Longhorn installed successfully")
 	return nil
 }
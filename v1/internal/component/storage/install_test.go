@@ -442,3 +442,137 @@ func TestInstall_Longhorn_NodeDiskPatchError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to configure Longhorn disk on node worker-a")
 }
+
+func TestCharts(t *testing.T) {
+	cfg := DefaultConfig()
+	charts := Charts(cfg)
+	require.Len(t, charts, 1)
+	assert.Equal(t, localPathChart, charts[0].Chart)
+	assert.Empty(t, charts[0].RepoURL)
+	assert.Equal(t, "0.0.36", charts[0].Version)
+
+	cfg.Backend = BackendLonghorn
+	cfg.Longhorn = &LonghornConfig{ReplicaCount: 3}
+	charts = Charts(cfg)
+	require.Len(t, charts, 1)
+	assert.Equal(t, longhornChart, charts[0].Chart)
+	assert.Equal(t, longhornRepoURL, charts[0].RepoURL)
+	assert.Equal(t, "1.7.2", charts[0].Version)
+}
+
+func testTrueNASConfig() *Config {
+	return &Config{
+		Backend:    BackendTrueNAS,
+		Namespace:  "kube-system",
+		SetDefault: true,
+		TrueNAS: &TrueNASConfig{
+			APIURL:      "https://nas.local",
+			APIKey:      "1-secret",
+			Pool:        "tank",
+			NFSServer:   "10.0.0.5",
+			ISCSIPortal: "10.0.0.5:3260",
+			Drivers:     []string{TrueNASDriverNFS, TrueNASDriverISCSI},
+		},
+	}
+}
+
+func TestInstall_TrueNAS_Success(t *testing.T) {
+	helmClient := &mockHelmClient{}
+	k8sClient := &mockK8sClient{}
+
+	err := Install(context.Background(), helmClient, k8sClient, testTrueNASConfig())
+	require.NoError(t, err)
+
+	require.Len(t, k8sClient.manifests, 2)
+	assert.Contains(t, k8sClient.manifests[0], "kind: Namespace")
+	assert.Contains(t, k8sClient.manifests[0], "name: truenas-nfs-driver-config")
+	assert.Contains(t, k8sClient.manifests[0], "apiKey: 1-secret")
+	require.Len(t, k8sClient.patches, 2)
+	assert.Equal(t, "secrets", k8sClient.patches[1].gvr.Resource)
+	assert.Equal(t, "democratic-csi", k8sClient.patches[1].namespace)
+	assert.Equal(t, "truenas-iscsi-driver-config", k8sClient.patches[1].name)
+	assert.Contains(t, string(k8sClient.patches[1].patch), "apiKey: 1-secret")
+
+	require.Len(t, helmClient.reposAdded, 2)
+	assert.Equal(t, snapshotControllerRepoURL, helmClient.reposAdded[0].URL)
+	assert.Equal(t, democraticCSIRepoURL, helmClient.reposAdded[1].URL)
+
+	require.Len(t, helmClient.chartsInstalled, 3)
+	assert.Equal(t, "snapshot-controller", helmClient.chartsInstalled[0].ReleaseName)
+	assert.Equal(t, "kube-system", helmClient.chartsInstalled[0].Namespace)
+	for i, release := range []string{"truenas-nfs", "truenas-iscsi"} {
+		installed := helmClient.chartsInstalled[i+1]
+		assert.Equal(t, release, installed.ReleaseName)
+		assert.Equal(t, "democratic-csi", installed.Namespace)
+		assert.Equal(t, democraticCSIChart, installed.Chart)
+		assert.Equal(t, "0.14.7", installed.Version)
+		assert.True(t, installed.CreateNamespace)
+	}
+}
+
+func TestInstall_TrueNAS_UpgradesExistingRelease(t *testing.T) {
+	helmClient := &mockHelmClient{
+		listReleases: []helm.Release{{Name: "truenas-nfs", Status: "failed"}},
+		upgradeErr:   assert.AnError,
+	}
+
+	err := Install(context.Background(), helmClient, &mockK8sClient{}, testTrueNASConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manual intervention required")
+	require.Len(t, helmClient.upgradeCalls, 1)
+	assert.Equal(t, "truenas-nfs", helmClient.upgradeCalls[0].ReleaseName)
+}
+
+func TestBuildTrueNASValues(t *testing.T) {
+	cfg := testTrueNASConfig()
+
+	nfs := buildTrueNASValues(cfg, TrueNASDriverNFS)
+	assert.Equal(t, "org.democratic-csi.truenas-nfs", nfs["csiDriver"].(map[string]interface{})["name"])
+	storageClass := nfs["storageClasses"].([]map[string]interface{})[0]
+	assert.Equal(t, "truenas-nfs", storageClass["name"])
+	assert.Equal(t, true, storageClass["defaultClass"], "the first driver is the default class")
+	snapshotClass := nfs["volumeSnapshotClasses"].([]map[string]interface{})[0]
+	assert.Equal(t, "truenas-nfs", snapshotClass["name"])
+	assert.Equal(t, map[string]interface{}{
+		"existingConfigSecret": "truenas-nfs-driver-config",
+		"config":               map[string]interface{}{"driver": "freenas-api-nfs"},
+	}, nfs["driver"], "the driver config, API key included, stays out of the values")
+
+	iscsi := buildTrueNASValues(cfg, TrueNASDriverISCSI)
+	storageClass = iscsi["storageClasses"].([]map[string]interface{})[0]
+	assert.Equal(t, "truenas-iscsi", storageClass["name"])
+	assert.Equal(t, false, storageClass["defaultClass"])
+	assert.Equal(t, "truenas-iscsi-driver-config", iscsi["driver"].(map[string]interface{})["existingConfigSecret"])
+}
+
+func TestBuildTrueNASDriverConfig(t *testing.T) {
+	cfg := testTrueNASConfig()
+
+	driverConfig := buildTrueNASDriverConfig(cfg, TrueNASDriverNFS)
+	assert.Equal(t, "freenas-api-nfs", driverConfig["driver"])
+	assert.Equal(t, map[string]interface{}{
+		"protocol":      "https",
+		"host":          "nas.local",
+		"port":          "443",
+		"apiKey":        "1-secret",
+		"allowInsecure": false,
+	}, driverConfig["httpConnection"])
+	zfs := driverConfig["zfs"].(map[string]interface{})
+	assert.Equal(t, "tank/k8s/nfs/volumes", zfs["datasetParentName"])
+	assert.Equal(t, "tank/k8s/nfs/snapshots", zfs["detachedSnapshotsDatasetParentName"])
+	assert.Equal(t, "10.0.0.5", driverConfig["nfs"].(map[string]interface{})["shareHost"])
+
+	driverConfig = buildTrueNASDriverConfig(cfg, TrueNASDriverISCSI)
+	assert.Equal(t, "freenas-api-iscsi", driverConfig["driver"])
+	assert.Equal(t, "tank/k8s/iscsi/volumes", driverConfig["zfs"].(map[string]interface{})["datasetParentName"])
+	assert.Equal(t, "10.0.0.5:3260", driverConfig["iscsi"].(map[string]interface{})["targetPortal"])
+}
+
+func TestCharts_TrueNAS(t *testing.T) {
+	charts := Charts(testTrueNASConfig())
+	require.Len(t, charts, 3)
+	assert.Equal(t, snapshotControllerChart, charts[0].Chart)
+	assert.Equal(t, democraticCSIChart, charts[1].Chart)
+	assert.Equal(t, democraticCSIChart, charts[2].Chart)
+	assert.Equal(t, "0.14.7", charts[1].Version)
+}
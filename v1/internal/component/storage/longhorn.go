@@ -0,0 +1,391 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
+)
+
+const (
+	// LonghornBackupSecretPath is where the backup target's S3 credentials
+	// live in OpenBAO unless the config points elsewhere
+	LonghornBackupSecretPath = "longhorn/backup"
+
+	// LonghornBackupCredentialSecret is the Secret in the Longhorn namespace
+	// the backup target reads its credentials from
+	LonghornBackupCredentialSecret = "longhorn-backup-credentials"
+
+	// DefaultLonghornBackupBucket is the SeaweedFS bucket Longhorn backs up to
+	DefaultLonghornBackupBucket = "longhorn"
+
+	// LonghornRecurringJobLabel marks the RecurringJobs Foundry manages
+	LonghornRecurringJobLabel = "foundry.io/managed"
+
+	// Recurring job tasks
+	LonghornTaskSnapshot = "snapshot"
+	LonghornTaskBackup   = "backup"
+)
+
+// longhornSettingGVR is Longhorn's settings resource; the backup target is
+// two of its settings
+var longhornSettingGVR = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "settings"}
+
+// longhornRecurringJobGVR is Longhorn's RecurringJob resource
+var longhornRecurringJobGVR = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "recurringjobs"}
+
+// recurringJobNamePattern is the name format Longhorn accepts for recurring
+// jobs, which are also used in CronJob and label names
+var recurringJobNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,38}[a-z0-9])?$`)
+
+// LonghornRecurringJob is a Longhorn RecurringJob: a snapshot or backup
+// schedule applied to the volumes in its groups
+type LonghornRecurringJob struct {
+	// Name is the RecurringJob's name
+	Name string `json:"name" yaml:"name"`
+
+	// Task is snapshot or backup
+	Task string `json:"task" yaml:"task"`
+
+	// Cron is the schedule, a five-field cron expression
+	Cron string `json:"cron" yaml:"cron"`
+
+	// Retain is how many snapshots or backups the job keeps per volume
+	Retain int `json:"retain" yaml:"retain"`
+
+	// Concurrency is how many volumes the job runs on at once (default: 1)
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+
+	// Groups are the volume groups the job applies to (default: default,
+	// which is every volume not in another group)
+	Groups []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	// Labels are added to the snapshots and backups the job creates
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// LonghornBackupConfig is Longhorn's backup target, an S3 bucket
+type LonghornBackupConfig struct {
+	// Endpoint is the S3 endpoint (default: the stack's SeaweedFS)
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Bucket is the bucket backups are stored in (default: longhorn)
+	Bucket string `json:"bucket" yaml:"bucket"`
+
+	// Region is the bucket's region (default: us-east-1)
+	Region string `json:"region" yaml:"region"`
+
+	// AccessKey and SecretKey default to the access_key and secret_key keys
+	// at longhorn/backup in OpenBAO
+	AccessKey string `json:"access_key" yaml:"access_key"`
+	SecretKey string `json:"secret_key" yaml:"secret_key"`
+}
+
+// Target is the backup target URL Longhorn expects
+func (b *LonghornBackupConfig) Target() string {
+	return fmt.Sprintf("s3://%s@%s/", b.Bucket, b.Region)
+}
+
+// DefaultLonghornBackupRefs points the backup credentials the section leaves
+// out at OpenBAO, so they resolve with the section's other secret references
+func DefaultLonghornBackupRefs(backup map[string]interface{}) {
+	for _, key := range []string{"access_key", "secret_key"} {
+		if s, _ := backup[key].(string); s == "" {
+			backup[key] = fmt.Sprintf("${secret:%s:%s}", LonghornBackupSecretPath, key)
+		}
+	}
+}
+
+// parseLonghornRecurringJobs reads the longhorn recurring_jobs list
+func parseLonghornRecurringJobs(raw interface{}) ([]LonghornRecurringJob, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("longhorn recurring_jobs must be a list")
+	}
+	jobs := make([]LonghornRecurringJob, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("longhorn recurring_jobs[%d] must be a map", i)
+		}
+		job := LonghornRecurringJob{Concurrency: 1}
+		job.Name, _ = m["name"].(string)
+		job.Task, _ = m["task"].(string)
+		job.Cron, _ = m["cron"].(string)
+		if raw, exists := m["retain"]; exists {
+			retain, ok := integerValue(raw)
+			if !ok {
+				return nil, fmt.Errorf("longhorn recurring_jobs[%d].retain must be an integer", i)
+			}
+			job.Retain = int(retain)
+		}
+		if raw, exists := m["concurrency"]; exists {
+			concurrency, ok := integerValue(raw)
+			if !ok {
+				return nil, fmt.Errorf("longhorn recurring_jobs[%d].concurrency must be an integer", i)
+			}
+			job.Concurrency = int(concurrency)
+		}
+		switch groups := m["groups"].(type) {
+		case []string:
+			job.Groups = groups
+		case []interface{}:
+			for _, group := range groups {
+				name, ok := group.(string)
+				if !ok {
+					return nil, fmt.Errorf("longhorn recurring_jobs[%d].groups must be a list of strings", i)
+				}
+				job.Groups = append(job.Groups, name)
+			}
+		}
+		if labels, ok := m["labels"].(map[string]interface{}); ok {
+			job.Labels = make(map[string]string, len(labels))
+			for k, v := range labels {
+				job.Labels[k] = fmt.Sprint(v)
+			}
+		}
+		if len(job.Groups) == 0 {
+			job.Groups = []string{"default"}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// parseLonghornBackup reads the longhorn backup section
+func parseLonghornBackup(m map[string]interface{}) *LonghornBackupConfig {
+	backup := &LonghornBackupConfig{}
+	backup.Endpoint, _ = m["endpoint"].(string)
+	backup.Bucket, _ = m["bucket"].(string)
+	backup.Region, _ = m["region"].(string)
+	backup.AccessKey, _ = m["access_key"].(string)
+	backup.SecretKey, _ = m["secret_key"].(string)
+	if backup.Bucket == "" {
+		backup.Bucket = DefaultLonghornBackupBucket
+	}
+	if backup.Region == "" {
+		backup.Region = "us-east-1"
+	}
+	return backup
+}
+
+// validateSnapshots checks the recurring jobs and the backup target
+func (l *LonghornConfig) validateSnapshots() error {
+	seen := make(map[string]bool, len(l.RecurringJobs))
+	for _, job := range l.RecurringJobs {
+		if !recurringJobNamePattern.MatchString(job.Name) {
+			return fmt.Errorf("longhorn recurring job name %q must be lowercase letters, digits and dashes, at most 40 characters", job.Name)
+		}
+		if seen[job.Name] {
+			return fmt.Errorf("longhorn recurring job %q is listed twice", job.Name)
+		}
+		seen[job.Name] = true
+		switch job.Task {
+		case LonghornTaskSnapshot:
+		case LonghornTaskBackup:
+			if l.Backup == nil {
+				return fmt.Errorf("longhorn recurring job %q backs up, but longhorn backup is not configured", job.Name)
+			}
+		default:
+			return fmt.Errorf("longhorn recurring job %q has unsupported task %q (use snapshot or backup)", job.Name, job.Task)
+		}
+		if len(strings.Fields(job.Cron)) != 5 {
+			return fmt.Errorf("longhorn recurring job %q cron %q is not a cron expression", job.Name, job.Cron)
+		}
+		if job.Retain < 1 || job.Retain > 100 {
+			return fmt.Errorf("longhorn recurring job %q retain must be between 1 and 100", job.Name)
+		}
+		if job.Concurrency < 1 {
+			return fmt.Errorf("longhorn recurring job %q concurrency must be at least 1", job.Name)
+		}
+	}
+
+	if l.Backup != nil {
+		if l.Backup.Endpoint == "" {
+			return fmt.Errorf("longhorn backup endpoint is required")
+		}
+		for _, field := range []struct{ key, value string }{
+			{"access_key", l.Backup.AccessKey},
+			{"secret_key", l.Backup.SecretKey},
+		} {
+			if field.value == "" {
+				return fmt.Errorf("longhorn backup %s is required", field.key)
+			}
+			if secrets.IsSecretRef(field.value) {
+				return fmt.Errorf("longhorn backup %s could not be resolved from %s", field.key, field.value)
+			}
+		}
+	}
+	return nil
+}
+
+// configureLonghornSnapshots points Longhorn's backup target at the
+// configured bucket, applies the recurring jobs and deletes the ones Foundry
+// created that are no longer configured
+func configureLonghornSnapshots(ctx context.Context, k8sClient K8sClient, namespace string, cfg *LonghornConfig) error {
+	if k8sClient == nil {
+		if cfg.Backup == nil && len(cfg.RecurringJobs) == 0 {
+			return nil
+		}
+		return fmt.Errorf("kubernetes client is required to configure Longhorn backups and recurring jobs")
+	}
+
+	if cfg.Backup != nil {
+		fmt.Printf("  Setting Longhorn backup target to %s...\n", cfg.Backup.Target())
+		manifest, err := longhornBackupSecretManifest(namespace, cfg.Backup)
+		if err != nil {
+			return err
+		}
+		if err := k8sClient.ApplyManifest(ctx, manifest); err != nil {
+			return fmt.Errorf("failed to apply Longhorn backup credentials: %w", err)
+		}
+		// ApplyManifest leaves an existing Secret alone, so changed
+		// credentials are patched in
+		patch, err := json.Marshal(map[string]interface{}{"stringData": longhornBackupCredentials(cfg.Backup)})
+		if err != nil {
+			return err
+		}
+		if err := k8sClient.MergePatchResource(ctx, secretGVR, namespace, LonghornBackupCredentialSecret, patch); err != nil {
+			return fmt.Errorf("failed to update Longhorn backup credentials: %w", err)
+		}
+		for name, value := range map[string]string{
+			"backup-target":                   cfg.Backup.Target(),
+			"backup-target-credential-secret": LonghornBackupCredentialSecret,
+		} {
+			patch, err := json.Marshal(map[string]interface{}{"value": value})
+			if err != nil {
+				return err
+			}
+			if err := k8sClient.MergePatchResource(ctx, longhornSettingGVR, namespace, name, patch); err != nil {
+				return fmt.Errorf("failed to set Longhorn setting %s: %w", name, err)
+			}
+		}
+	}
+
+	if len(cfg.RecurringJobs) > 0 {
+		fmt.Printf("  Applying %d Longhorn recurring job(s)...\n", len(cfg.RecurringJobs))
+		manifest, err := longhornRecurringJobsManifest(namespace, cfg.RecurringJobs)
+		if err != nil {
+			return err
+		}
+		if err := k8sClient.ApplyManifest(ctx, manifest); err != nil {
+			return fmt.Errorf("failed to apply Longhorn recurring jobs: %w", err)
+		}
+		// ApplyManifest leaves existing jobs alone, so the configured
+		// schedule, retention and groups are patched in
+		for _, job := range cfg.RecurringJobs {
+			patch, err := json.Marshal(map[string]interface{}{"spec": longhornRecurringJobSpec(job)})
+			if err != nil {
+				return err
+			}
+			if err := k8sClient.MergePatchResource(ctx, longhornRecurringJobGVR, namespace, job.Name, patch); err != nil {
+				return fmt.Errorf("failed to update Longhorn recurring job %s: %w", job.Name, err)
+			}
+		}
+	}
+
+	return pruneLonghornRecurringJobs(ctx, k8sClient, namespace, cfg.RecurringJobs)
+}
+
+// pruneLonghornRecurringJobs deletes the RecurringJobs Foundry created that
+// are no longer in the config
+func pruneLonghornRecurringJobs(ctx context.Context, k8sClient K8sClient, namespace string, configured []LonghornRecurringJob) error {
+	existing, err := k8sClient.ListResourceNames(ctx, longhornRecurringJobGVR, namespace, LonghornRecurringJobLabel+"=true")
+	if err != nil {
+		return fmt.Errorf("failed to list Longhorn recurring jobs: %w", err)
+	}
+	keep := make(map[string]bool, len(configured))
+	for _, job := range configured {
+		keep[job.Name] = true
+	}
+	for _, name := range existing {
+		if keep[name] {
+			continue
+		}
+		fmt.Printf("  Deleting Longhorn recurring job %s...\n", name)
+		if err := k8sClient.DeleteResource(ctx, longhornRecurringJobGVR, namespace, name); err != nil {
+			return fmt.Errorf("failed to delete Longhorn recurring job %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// longhornBackupCredentials is the backup credential Secret's data
+func longhornBackupCredentials(backup *LonghornBackupConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"AWS_ACCESS_KEY_ID":     backup.AccessKey,
+		"AWS_SECRET_ACCESS_KEY": backup.SecretKey,
+		"AWS_ENDPOINTS":         backup.Endpoint,
+	}
+}
+
+// longhornBackupSecretManifest is the Secret the backup target reads its S3
+// credentials and endpoint from
+func longhornBackupSecretManifest(namespace string, backup *LonghornBackupConfig) (string, error) {
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      LonghornBackupCredentialSecret,
+			"namespace": namespace,
+		},
+		"type":       "Opaque",
+		"stringData": longhornBackupCredentials(backup),
+	}
+	out, err := yaml.Marshal(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Longhorn backup credentials: %w", err)
+	}
+	return string(out), nil
+}
+
+// longhornRecurringJobsManifest renders the RecurringJob resources, sorted
+// by name
+func longhornRecurringJobsManifest(namespace string, jobs []LonghornRecurringJob) (string, error) {
+	sorted := append([]LonghornRecurringJob(nil), jobs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	docs := make([]string, 0, len(sorted))
+	for _, job := range sorted {
+		resource := map[string]interface{}{
+			"apiVersion": "longhorn.io/v1beta2",
+			"kind":       "RecurringJob",
+			"metadata": map[string]interface{}{
+				"name":      job.Name,
+				"namespace": namespace,
+				"labels":    map[string]interface{}{LonghornRecurringJobLabel: "true"},
+			},
+			"spec": longhornRecurringJobSpec(job),
+		}
+		out, err := yaml.Marshal(resource)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode Longhorn recurring job %s: %w", job.Name, err)
+		}
+		docs = append(docs, string(out))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// longhornRecurringJobSpec is a RecurringJob's spec
+func longhornRecurringJobSpec(job LonghornRecurringJob) map[string]interface{} {
+	labels := map[string]interface{}{}
+	for k, v := range job.Labels {
+		labels[k] = v
+	}
+	return map[string]interface{}{
+		"name":        job.Name,
+		"task":        job.Task,
+		"cron":        job.Cron,
+		"retain":      job.Retain,
+		"concurrency": job.Concurrency,
+		"groups":      job.Groups,
+		"labels":      labels,
+	}
+}
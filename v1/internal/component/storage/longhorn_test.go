@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+)
+
+func longhornSnapshotConfig() component.ComponentConfig {
+	return component.ComponentConfig{
+		"backend": "longhorn",
+		"longhorn": map[string]interface{}{
+			"replica_count": 2,
+			"recurring_jobs": []interface{}{
+				map[string]interface{}{"name": "hourly-snap", "task": "snapshot", "cron": "0 * * * *", "retain": 24},
+				map[string]interface{}{"name": "nightly-backup", "task": "backup", "cron": "0 2 * * *", "retain": 7, "concurrency": 2, "groups": []interface{}{"databases"}, "labels": map[string]interface{}{"tier": "db"}},
+			},
+			"backup": map[string]interface{}{
+				"endpoint":   "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333",
+				"access_key": "AKID",
+				"secret_key": "SECRET",
+			},
+		},
+	}
+}
+
+func TestParseConfig_LonghornSnapshots(t *testing.T) {
+	cfg, err := ParseConfig(longhornSnapshotConfig())
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	require.Len(t, cfg.Longhorn.RecurringJobs, 2)
+	assert.Equal(t, LonghornRecurringJob{Name: "hourly-snap", Task: "snapshot", Cron: "0 * * * *", Retain: 24, Concurrency: 1, Groups: []string{"default"}}, cfg.Longhorn.RecurringJobs[0])
+	assert.Equal(t, 2, cfg.Longhorn.RecurringJobs[1].Concurrency)
+	assert.Equal(t, []string{"databases"}, cfg.Longhorn.RecurringJobs[1].Groups)
+	assert.Equal(t, map[string]string{"tier": "db"}, cfg.Longhorn.RecurringJobs[1].Labels)
+
+	require.NotNil(t, cfg.Longhorn.Backup)
+	assert.Equal(t, "longhorn", cfg.Longhorn.Backup.Bucket)
+	assert.Equal(t, "us-east-1", cfg.Longhorn.Backup.Region)
+	assert.Equal(t, "s3://longhorn@us-east-1/", cfg.Longhorn.Backup.Target())
+}
+
+func TestValidate_LonghornSnapshots(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*LonghornConfig)
+		wantErr string
+	}{
+		{"bad name", func(l *LonghornConfig) { l.RecurringJobs[0].Name = "Hourly_Snap" }, "lowercase letters"},
+		{"duplicate", func(l *LonghornConfig) { l.RecurringJobs[1].Name = "hourly-snap" }, "listed twice"},
+		{"bad task", func(l *LonghornConfig) { l.RecurringJobs[0].Task = "snapshot-cleanup" }, "unsupported task"},
+		{"backup without target", func(l *LonghornConfig) { l.Backup = nil }, "longhorn backup is not configured"},
+		{"bad cron", func(l *LonghornConfig) { l.RecurringJobs[0].Cron = "@hourly" }, "not a cron expression"},
+		{"no retain", func(l *LonghornConfig) { l.RecurringJobs[0].Retain = 0 }, "retain must be between 1 and 100"},
+		{"no concurrency", func(l *LonghornConfig) { l.RecurringJobs[0].Concurrency = 0 }, "concurrency must be at least 1"},
+		{"no endpoint", func(l *LonghornConfig) { l.Backup.Endpoint = "" }, "endpoint is required"},
+		{"unresolved key", func(l *LonghornConfig) { l.Backup.SecretKey = "${secret:longhorn/backup:secret_key}" }, "secret_key could not be resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig(longhornSnapshotConfig())
+			require.NoError(t, err)
+			tt.mutate(cfg.Longhorn)
+			assert.ErrorContains(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseConfig_LonghornRecurringJobsInvalid(t *testing.T) {
+	_, err := ParseConfig(component.ComponentConfig{
+		"longhorn": map[string]interface{}{"recurring_jobs": map[string]interface{}{"name": "x"}},
+	})
+	assert.ErrorContains(t, err, "must be a list")
+
+	_, err = ParseConfig(component.ComponentConfig{
+		"longhorn": map[string]interface{}{"recurring_jobs": []interface{}{map[string]interface{}{"name": "x", "retain": "ten"}}},
+	})
+	assert.ErrorContains(t, err, "retain must be an integer")
+}
+
+func TestDefaultLonghornBackupRefs(t *testing.T) {
+	backup := map[string]interface{}{"access_key": "AKID"}
+	DefaultLonghornBackupRefs(backup)
+	assert.Equal(t, "AKID", backup["access_key"])
+	assert.Equal(t, "${secret:longhorn/backup:secret_key}", backup["secret_key"])
+}
+
+func TestConfigureLonghornSnapshots(t *testing.T) {
+	cfg, err := ParseConfig(longhornSnapshotConfig())
+	require.NoError(t, err)
+	k8sClient := &mockK8sClient{}
+
+	require.NoError(t, configureLonghornSnapshots(context.Background(), k8sClient, "longhorn-system", cfg.Longhorn))
+
+	require.Len(t, k8sClient.manifests, 2)
+	secret := k8sClient.manifests[0]
+	assert.Contains(t, secret, "name: "+LonghornBackupCredentialSecret)
+	assert.Contains(t, secret, "AWS_ACCESS_KEY_ID: AKID")
+	assert.Contains(t, secret, "AWS_SECRET_ACCESS_KEY: SECRET")
+	assert.Contains(t, secret, "AWS_ENDPOINTS: http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333")
+
+	jobs := strings.Split(k8sClient.manifests[1], "---\n")
+	require.Len(t, jobs, 2)
+	assert.Contains(t, jobs[0], "name: hourly-snap")
+	assert.Contains(t, jobs[0], "kind: RecurringJob")
+	assert.Contains(t, jobs[0], LonghornRecurringJobLabel+": \"true\"")
+	assert.Contains(t, jobs[1], "task: backup")
+	assert.Contains(t, jobs[1], "- databases")
+
+	settings := map[string]string{}
+	for _, patch := range k8sClient.patches {
+		if patch.gvr == longhornSettingGVR {
+			settings[patch.name] = string(patch.patch)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"backup-target":                   `{"value":"s3://longhorn@us-east-1/"}`,
+		"backup-target-credential-secret": `{"value":"longhorn-backup-credentials"}`,
+	}, settings)
+}
+
+func TestConfigureLonghornSnapshots_NothingConfigured(t *testing.T) {
+	require.NoError(t, configureLonghornSnapshots(context.Background(), nil, "longhorn-system", &LonghornConfig{}))
+}
+
+func TestConfigureLonghornSnapshots_Reapply(t *testing.T) {
+	cfg, err := ParseConfig(longhornSnapshotConfig())
+	require.NoError(t, err)
+	// The cluster has the jobs from an earlier install, one since removed
+	// from the config
+	k8sClient := &mockK8sClient{resourceNames: []string{"hourly-snap", "nightly-backup", "weekly-snap"}}
+	cfg.Longhorn.RecurringJobs[0].Cron = "30 * * * *"
+	cfg.Longhorn.RecurringJobs[0].Retain = 48
+	cfg.Longhorn.Backup.SecretKey = "ROTATED"
+
+	require.NoError(t, configureLonghornSnapshots(context.Background(), k8sClient, "longhorn-system", cfg.Longhorn))
+
+	patches := map[string]map[string]interface{}{}
+	for _, patch := range k8sClient.patches {
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(patch.patch, &decoded))
+		patches[patch.gvr.Resource+"/"+patch.name] = decoded
+	}
+	job := patches["recurringjobs/hourly-snap"]
+	require.NotNil(t, job, "the changed job is not patched")
+	assert.Equal(t, "30 * * * *", job["spec"].(map[string]interface{})["cron"])
+	assert.Equal(t, float64(48), job["spec"].(map[string]interface{})["retain"])
+	assert.Contains(t, patches, "recurringjobs/"+cfg.Longhorn.RecurringJobs[1].Name)
+
+	secret := patches["secrets/"+LonghornBackupCredentialSecret]
+	require.NotNil(t, secret, "the credentials are not patched")
+	assert.Equal(t, "ROTATED", secret["stringData"].(map[string]interface{})["AWS_SECRET_ACCESS_KEY"])
+
+	assert.Equal(t, []string{"weekly-snap"}, k8sClient.deleted)
+}
+
+func TestConfigureLonghornSnapshots_RemovedJobs(t *testing.T) {
+	k8sClient := &mockK8sClient{resourceNames: []string{"hourly-snap"}}
+	require.NoError(t, configureLonghornSnapshots(context.Background(), k8sClient, "longhorn-system", &LonghornConfig{}))
+	assert.Empty(t, k8sClient.manifests)
+	assert.Equal(t, []string{"hourly-snap"}, k8sClient.deleted)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+const (
+	// smartctl_exporter publishes every node's SMART data to Prometheus
+	smartExporterRepoName = "prometheus-community"
+	smartExporterRepoURL  = "https://prometheus-community.github.io/helm-charts"
+	smartExporterChart    = "prometheus-community/prometheus-smartctl-exporter"
+	smartExporterRelease  = "smartctl-exporter"
+
+	// DefaultSmartExporterVersion is the prometheus-smartctl-exporter chart version
+	DefaultSmartExporterVersion = "0.13.0"
+
+	// DefaultSmartExporterNamespace is where smartctl_exporter runs unless
+	// configured otherwise, next to Prometheus
+	DefaultSmartExporterNamespace = "monitoring"
+)
+
+// SmartExporterConfig holds configuration for the smartctl_exporter DaemonSet
+type SmartExporterConfig struct {
+	// Enabled deploys smartctl_exporter on every node
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Version is the prometheus-smartctl-exporter chart version
+	Version string `json:"version" yaml:"version"`
+
+	// Namespace for the exporter (default: monitoring)
+	Namespace string `json:"namespace" yaml:"namespace"`
+}
+
+// parseSmartExporter reads the smart_exporter section
+func parseSmartExporter(raw map[string]interface{}) *SmartExporterConfig {
+	exporter := &SmartExporterConfig{
+		Version:   DefaultSmartExporterVersion,
+		Namespace: DefaultSmartExporterNamespace,
+	}
+	if enabled, ok := raw["enabled"].(bool); ok {
+		exporter.Enabled = enabled
+	}
+	if version, ok := raw["version"].(string); ok && version != "" {
+		exporter.Version = version
+	}
+	if namespace, ok := raw["namespace"].(string); ok && namespace != "" {
+		exporter.Namespace = namespace
+	}
+	return exporter
+}
+
+// installSmartExporter installs or upgrades smartctl_exporter. The
+// ServiceMonitor is only created once Prometheus' CRD exists; the storage
+// upgrade after Prometheus is installed turns it on.
+func installSmartExporter(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cfg *SmartExporterConfig) error {
+	fmt.Println("  Installing smartctl_exporter...")
+
+	serviceMonitor := false
+	if k8sClient != nil {
+		crdExists, err := k8sClient.ServiceMonitorCRDExists(ctx)
+		if err != nil {
+			fmt.Printf("  ⚠ Could not check for ServiceMonitor CRD: %v\n", err)
+		} else if !crdExists {
+			fmt.Println("  ⚠ ServiceMonitor CRD not available - installing smartctl_exporter without metrics integration")
+			fmt.Println("    (ServiceMonitor will be enabled when storage is upgraded after Prometheus)")
+		} else {
+			serviceMonitor = true
+		}
+	}
+
+	if err := helmClient.AddRepo(ctx, helm.RepoAddOptions{
+		Name:        smartExporterRepoName,
+		URL:         smartExporterRepoURL,
+		ForceUpdate: true,
+	}); err != nil {
+		return fmt.Errorf("failed to add helm repository: %w", err)
+	}
+
+	values := buildSmartExporterValues(serviceMonitor)
+
+	var existingRelease *helm.Release
+	releases, err := helmClient.List(ctx, cfg.Namespace)
+	if err == nil {
+		for i := range releases {
+			if releases[i].Name == smartExporterRelease {
+				existingRelease = &releases[i]
+				break
+			}
+		}
+	}
+
+	if existingRelease != nil {
+		fmt.Printf("  Upgrading smartctl_exporter (current status: %s)...\n", existingRelease.Status)
+		if err := helmClient.Upgrade(ctx, helm.UpgradeOptions{
+			ReleaseName: smartExporterRelease,
+			Namespace:   cfg.Namespace,
+			Chart:       smartExporterChart,
+			Version:     cfg.Version,
+			Values:      values,
+			Wait:        true,
+			Timeout:     5 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to upgrade smartctl_exporter: %w", err)
+		}
+	} else {
+		if err := helmClient.Install(ctx, helm.InstallOptions{
+			ReleaseName:     smartExporterRelease,
+			Namespace:       cfg.Namespace,
+			Chart:           smartExporterChart,
+			Version:         cfg.Version,
+			Values:          values,
+			CreateNamespace: true,
+			Wait:            true,
+			Timeout:         5 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to install smartctl_exporter: %w", err)
+		}
+	}
+
+	fmt.Println("  smartctl_exporter installed successfully")
+	return nil
+}
+
+// buildSmartExporterValues constructs Helm values for smartctl_exporter.
+// The chart's own alert rules are left off; the Prometheus component's rule
+// pack carries the disk alerts.
+func buildSmartExporterValues(serviceMonitor bool) map[string]interface{} {
+	return map[string]interface{}{
+		"fullnameOverride": smartExporterRelease,
+		"serviceMonitor": map[string]interface{}{
+			"enabled": serviceMonitor,
+		},
+		"prometheusRules": map[string]interface{}{
+			"enabled": false,
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig_SmartExporter(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{
+		"smart_exporter": map[string]interface{}{"enabled": true},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, cfg.SmartExporter)
+	assert.True(t, cfg.SmartExporter.Enabled)
+	assert.Equal(t, DefaultSmartExporterVersion, cfg.SmartExporter.Version)
+	assert.Equal(t, DefaultSmartExporterNamespace, cfg.SmartExporter.Namespace)
+
+	cfg, err = ParseConfig(component.ComponentConfig{
+		"smart_exporter": map[string]interface{}{"enabled": true, "version": "0.14.0", "namespace": "observability"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0.14.0", cfg.SmartExporter.Version)
+	assert.Equal(t, "observability", cfg.SmartExporter.Namespace)
+
+	cfg, err = ParseConfig(component.ComponentConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, cfg.SmartExporter)
+}
+
+func TestInstall_SmartExporter(t *testing.T) {
+	helmClient := &mockHelmClient{listErr: assert.AnError}
+	cfg := DefaultConfig()
+	cfg.SmartExporter = &SmartExporterConfig{Enabled: true, Version: DefaultSmartExporterVersion, Namespace: DefaultSmartExporterNamespace}
+
+	err := Install(context.Background(), helmClient, &mockK8sClient{}, cfg)
+	require.NoError(t, err)
+
+	require.Len(t, helmClient.chartsInstalled, 2, "the backend, then the exporter")
+	exporter := helmClient.chartsInstalled[1]
+	assert.Equal(t, smartExporterRelease, exporter.ReleaseName)
+	assert.Equal(t, DefaultSmartExporterNamespace, exporter.Namespace)
+	assert.Equal(t, smartExporterChart, exporter.Chart)
+	assert.Equal(t, DefaultSmartExporterVersion, exporter.Version)
+	assert.Equal(t, false, exporter.Values["serviceMonitor"].(map[string]interface{})["enabled"], "no ServiceMonitor CRD yet")
+	assert.Equal(t, false, exporter.Values["prometheusRules"].(map[string]interface{})["enabled"])
+}
+
+func TestInstall_SmartExporter_UpgradesWithServiceMonitor(t *testing.T) {
+	helmClient := &mockHelmClient{listReleases: []helm.Release{
+		{Name: "local-path-provisioner", Namespace: "kube-system", Status: "deployed"},
+		{Name: smartExporterRelease, Namespace: DefaultSmartExporterNamespace, Status: "deployed"},
+	}}
+	cfg := DefaultConfig()
+	cfg.SmartExporter = &SmartExporterConfig{Enabled: true, Version: DefaultSmartExporterVersion, Namespace: DefaultSmartExporterNamespace}
+
+	err := Install(context.Background(), helmClient, &mockK8sClient{serviceMonitorCRDExists: true}, cfg)
+	require.NoError(t, err)
+
+	assert.Empty(t, helmClient.chartsInstalled)
+	require.Len(t, helmClient.upgradeCalls, 2)
+	assert.Equal(t, smartExporterRelease, helmClient.upgradeCalls[1].ReleaseName)
+	assert.Equal(t, true, helmClient.upgradeCalls[1].Values["serviceMonitor"].(map[string]interface{})["enabled"])
+}
+
+func TestCharts_SmartExporter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SmartExporter = &SmartExporterConfig{Enabled: true, Version: DefaultSmartExporterVersion}
+
+	charts := Charts(cfg)
+	require.Len(t, charts, 2)
+	assert.Equal(t, localPathChart, charts[0].Chart)
+	assert.Equal(t, smartExporterChart, charts[1].Chart)
+	assert.Equal(t, DefaultSmartExporterVersion, charts[1].Version)
+}
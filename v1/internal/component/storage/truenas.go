@@ -0,0 +1,482 @@
+package storage
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+const (
+	// democratic-csi, one release per TrueNAS driver
+	democraticCSIRepoName = "democratic-csi"
+	democraticCSIRepoURL  = "https://democratic-csi.github.io/charts/"
+	democraticCSIChart    = "democratic-csi/democratic-csi"
+
+	// snapshot-controller and the VolumeSnapshot CRDs, which K3s doesn't ship
+	snapshotControllerRepoName = "piraeus-charts"
+	snapshotControllerRepoURL  = "https://piraeus.io/helm-charts/"
+	snapshotControllerChart    = "piraeus-charts/snapshot-controller"
+	snapshotControllerVersion  = "3.0.6"
+	snapshotControllerRelease  = "snapshot-controller"
+
+	// truenasNamespace is where the democratic-csi releases go when the
+	// configured namespace is the kube-system default
+	truenasNamespace = "democratic-csi"
+
+	// trueNASConfigSecretKey is the key democratic-csi reads its driver
+	// config from in driver.existingConfigSecret
+	trueNASConfigSecretKey = "driver-config-file.yaml"
+)
+
+var secretGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// TrueNASComponentConfig converts the stack config's storage.truenas section
+// into the component config's truenas section. The API key defaults to
+// OpenBAO and is left as a reference for the caller to resolve.
+func TrueNASComponentConfig(t *config.TrueNASConfig) map[string]interface{} {
+	section := map[string]interface{}{
+		"api_url": t.APIURL,
+		"api_key": t.APIKey,
+	}
+	if t.APIKey == "" {
+		section["api_key"] = TrueNASAPIKeyRef
+	}
+	for key, value := range map[string]*string{
+		"pool":           t.Pool,
+		"parent_dataset": t.ParentDataset,
+		"nfs_server":     t.NFSServer,
+		"iscsi_portal":   t.ISCSIPortal,
+	} {
+		if value != nil {
+			section[key] = *value
+		}
+	}
+	if len(t.Drivers) > 0 {
+		section["drivers"] = t.Drivers
+	}
+	if t.AllowInsecure != nil {
+		section["allow_insecure"] = *t.AllowInsecure
+	}
+	return section
+}
+
+// installTrueNAS installs the snapshot controller and a democratic-csi
+// release per configured driver. Each release creates its StorageClass and
+// VolumeSnapshotClass.
+func installTrueNAS(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cfg *Config) error {
+	fmt.Println("  Installing democratic-csi for TrueNAS...")
+
+	for _, repo := range []helm.RepoAddOptions{
+		{Name: snapshotControllerRepoName, URL: snapshotControllerRepoURL, ForceUpdate: true},
+		{Name: democraticCSIRepoName, URL: democraticCSIRepoURL, ForceUpdate: true},
+	} {
+		if err := helmClient.AddRepo(ctx, repo); err != nil {
+			return fmt.Errorf("failed to add helm repository: %w", err)
+		}
+	}
+
+	if err := installOrUpgradeRelease(ctx, helmClient, snapshotControllerRelease, "kube-system",
+		snapshotControllerChart, snapshotControllerVersion, map[string]interface{}{}); err != nil {
+		return err
+	}
+
+	if k8sClient == nil {
+		return fmt.Errorf("kubernetes client is required to store the TrueNAS driver config")
+	}
+
+	namespace := trueNASReleaseNamespace(cfg)
+	version := chartVersion(cfg)
+	for _, driver := range cfg.TrueNAS.Drivers {
+		release := TrueNASStorageClass(driver)
+		if err := applyTrueNASConfigSecret(ctx, k8sClient, namespace, cfg, driver); err != nil {
+			return err
+		}
+		if err := installOrUpgradeRelease(ctx, helmClient, release, namespace,
+			democraticCSIChart, version, buildTrueNASValues(cfg, driver)); err != nil {
+			return err
+		}
+		fmt.Printf("  StorageClass %s ready\n", release)
+	}
+
+	fmt.Println("  democratic-csi installed successfully")
+	return nil
+}
+
+// installOrUpgradeRelease upgrades a release that exists, even a failed one
+// (uninstalling could lose volumes), and installs it otherwise
+func installOrUpgradeRelease(ctx context.Context, helmClient HelmClient, release, namespace, chart, version string, values map[string]interface{}) error {
+	var releaseExists bool
+	var releaseStatus string
+	releases, err := helmClient.List(ctx, namespace)
+	if err == nil {
+		for _, rel := range releases {
+			if rel.Name == release {
+				releaseExists = true
+				releaseStatus = rel.Status
+				break
+			}
+		}
+	}
+
+	if releaseExists {
+		fmt.Printf("  Upgrading %s (current status: %s)...\n", release, releaseStatus)
+		if err := helmClient.Upgrade(ctx, helm.UpgradeOptions{
+			ReleaseName: release,
+			Namespace:   namespace,
+			Chart:       chart,
+			Version:     version,
+			Values:      values,
+			Wait:        true,
+			Timeout:     5 * time.Minute,
+		}); err != nil {
+			if releaseStatus != "deployed" {
+				fmt.Printf("  ⚠ Warning: Failed to upgrade release (status: %s): %v\n", releaseStatus, err)
+				fmt.Println("  ⚠ Manual intervention required. You may need to:")
+				fmt.Println("    1. Check pod status: kubectl get pods -n", namespace, "-l app.kubernetes.io/instance="+release)
+				fmt.Println("    2. If data loss is acceptable, uninstall manually: helm uninstall", release, "-n", namespace)
+				return fmt.Errorf("failed to upgrade %s (manual intervention required): %w", release, err)
+			}
+			return fmt.Errorf("failed to upgrade %s: %w", release, err)
+		}
+		return nil
+	}
+
+	if err := helmClient.Install(ctx, helm.InstallOptions{
+		ReleaseName:     release,
+		Namespace:       namespace,
+		Chart:           chart,
+		Version:         version,
+		Values:          values,
+		CreateNamespace: true,
+		Wait:            true,
+		Timeout:         5 * time.Minute,
+	}); err != nil {
+		return fmt.Errorf("failed to install %s: %w", release, err)
+	}
+	return nil
+}
+
+func trueNASReleaseNamespace(cfg *Config) string {
+	if cfg.Namespace == "" || cfg.Namespace == "kube-system" {
+		return truenasNamespace
+	}
+	return cfg.Namespace
+}
+
+// TrueNASConfigSecret is the Secret holding a driver's democratic-csi config,
+// API key included, so the key stays out of the Helm values
+func TrueNASConfigSecret(driver string) string {
+	return TrueNASStorageClass(driver) + "-driver-config"
+}
+
+// applyTrueNASConfigSecret creates or updates the Secret with a driver's
+// democratic-csi config
+func applyTrueNASConfigSecret(ctx context.Context, k8sClient K8sClient, namespace string, cfg *Config, driver string) error {
+	driverConfig, err := yaml.Marshal(buildTrueNASDriverConfig(cfg, driver))
+	if err != nil {
+		return fmt.Errorf("failed to encode the TrueNAS driver config: %w", err)
+	}
+	stringData := map[string]interface{}{trueNASConfigSecretKey: string(driverConfig)}
+
+	docs := make([]string, 0, 2)
+	for _, resource := range []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": namespace},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      TrueNASConfigSecret(driver),
+				"namespace": namespace,
+			},
+			"type":       "Opaque",
+			"stringData": stringData,
+		},
+	} {
+		out, err := yaml.Marshal(resource)
+		if err != nil {
+			return fmt.Errorf("failed to encode the TrueNAS driver config secret: %w", err)
+		}
+		docs = append(docs, string(out))
+	}
+	if err := k8sClient.ApplyManifest(ctx, strings.Join(docs, "---\n")); err != nil {
+		return fmt.Errorf("failed to apply the TrueNAS driver config secret: %w", err)
+	}
+
+	// ApplyManifest leaves an existing Secret alone, so a changed config
+	// (e.g. a rotated API key) is patched in
+	patch, err := json.Marshal(map[string]interface{}{"stringData": stringData})
+	if err != nil {
+		return err
+	}
+	if err := k8sClient.MergePatchResource(ctx, secretGVR, namespace, TrueNASConfigSecret(driver), patch); err != nil {
+		return fmt.Errorf("failed to update the TrueNAS driver config secret: %w", err)
+	}
+	return nil
+}
+
+// buildTrueNASDriverConfig constructs the democratic-csi driver config of one
+// driver. Volumes of each driver live in their own dataset under the parent
+// dataset, with detached snapshots next to them, so a snapshot survives the
+// deletion of its volume.
+func buildTrueNASDriverConfig(cfg *Config, driver string) map[string]interface{} {
+	tn := cfg.TrueNAS
+	dataset := path.Join(tn.Dataset(), driver)
+
+	apiURL, _ := url.Parse(tn.APIURL)
+	port := apiURL.Port()
+	if port == "" {
+		port = "443"
+		if apiURL.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	zfs := map[string]interface{}{
+		"datasetParentName":                  dataset + "/volumes",
+		"detachedSnapshotsDatasetParentName": dataset + "/snapshots",
+	}
+	driverConfig := map[string]interface{}{
+		"driver": "freenas-api-" + driver,
+		"httpConnection": map[string]interface{}{
+			"protocol":      apiURL.Scheme,
+			"host":          apiURL.Hostname(),
+			"port":          port,
+			"apiKey":        tn.APIKey,
+			"allowInsecure": tn.AllowInsecure,
+		},
+		"zfs": zfs,
+	}
+
+	switch driver {
+	case TrueNASDriverNFS:
+		zfs["datasetEnableQuotas"] = true
+		zfs["datasetEnableReservation"] = false
+		zfs["datasetPermissionsMode"] = "0777"
+		zfs["datasetPermissionsUser"] = 0
+		zfs["datasetPermissionsGroup"] = 0
+		driverConfig["nfs"] = map[string]interface{}{
+			"shareHost":            tn.NFSServer,
+			"shareAlldirs":         false,
+			"shareAllowedHosts":    []string{},
+			"shareAllowedNetworks": []string{},
+			"shareMaprootUser":     "root",
+			"shareMaprootGroup":    "root",
+		}
+	case TrueNASDriverISCSI:
+		zfs["zvolEnableReservation"] = false
+		driverConfig["iscsi"] = map[string]interface{}{
+			"targetPortal": tn.ISCSIPortal,
+			"namePrefix":   "csi-",
+			"targetGroups": []map[string]interface{}{
+				{
+					"targetGroupPortalGroup":    1,
+					"targetGroupInitiatorGroup": 1,
+					"targetGroupAuthType":       "None",
+				},
+			},
+			"extentInsecureTpc":              true,
+			"extentXenCompat":                false,
+			"extentDisablePhysicalBlocksize": true,
+			"extentBlocksize":                512,
+			"extentRpm":                      "SSD",
+			"extentAvailThreshold":           0,
+		}
+	}
+	return driverConfig
+}
+
+// buildTrueNASValues constructs Helm values for the democratic-csi release of
+// one driver. The driver config comes from TrueNASConfigSecret; the chart
+// still needs the driver's name.
+func buildTrueNASValues(cfg *Config, driver string) map[string]interface{} {
+	values := make(map[string]interface{})
+
+	// Start with user-provided values
+	for k, v := range cfg.Values {
+		values[k] = v
+	}
+
+	tn := cfg.TrueNAS
+	className := TrueNASStorageClass(driver)
+
+	storageClass := map[string]interface{}{
+		"name":                 className,
+		"defaultClass":         cfg.SetDefault && len(tn.Drivers) > 0 && tn.Drivers[0] == driver,
+		"reclaimPolicy":        "Delete",
+		"volumeBindingMode":    "Immediate",
+		"allowVolumeExpansion": true,
+	}
+	switch driver {
+	case TrueNASDriverNFS:
+		storageClass["parameters"] = map[string]interface{}{"fsType": "nfs"}
+		storageClass["mountOptions"] = []string{"noatime", "nfsvers=4"}
+	case TrueNASDriverISCSI:
+		storageClass["parameters"] = map[string]interface{}{"fsType": "ext4"}
+	}
+
+	values["csiDriver"] = map[string]interface{}{
+		"name": "org.democratic-csi." + className,
+	}
+	values["storageClasses"] = []map[string]interface{}{storageClass}
+	values["volumeSnapshotClasses"] = []map[string]interface{}{
+		{
+			"name": className,
+			"parameters": map[string]interface{}{
+				"detachedSnapshots": "true",
+			},
+		},
+	}
+	values["driver"] = map[string]interface{}{
+		"existingConfigSecret": TrueNASConfigSecret(driver),
+		"config": map[string]interface{}{
+			"driver": "freenas-api-" + driver,
+		},
+	}
+
+	return values
+}
+
+// TrueNASPool is a ZFS pool as the TrueNAS API reports it
+type TrueNASPool struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Healthy   bool   `json:"healthy"`
+	Size      int64  `json:"size"`
+	Allocated int64  `json:"allocated"`
+	Free      int64  `json:"free"`
+}
+
+// FreePercent returns the share of the pool that is still free
+func (p *TrueNASPool) FreePercent() float64 {
+	if p.Size <= 0 {
+		return 0
+	}
+	return float64(p.Free) * 100 / float64(p.Size)
+}
+
+// TrueNASCheck is what CheckTrueNAS found on the server
+type TrueNASCheck struct {
+	// Version is the TrueNAS release, e.g. TrueNAS-SCALE-24.04.2
+	Version string
+
+	// Pool is the pool the volumes are provisioned in
+	Pool TrueNASPool
+}
+
+// TrueNASClient is a minimal client for the TrueNAS REST API (v2.0)
+type TrueNASClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewTrueNASClient creates a client for the server in cfg
+func NewTrueNASClient(cfg *TrueNASConfig) *TrueNASClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.AllowInsecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &TrueNASClient{
+		baseURL: strings.TrimSuffix(cfg.APIURL, "/") + "/api/v2.0",
+		apiKey:  cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: transport,
+		},
+	}
+}
+
+// Version returns the TrueNAS release the server runs
+func (c *TrueNASClient) Version(ctx context.Context) (string, error) {
+	var info struct {
+		Version string `json:"version"`
+	}
+	if err := c.get(ctx, "/system/info", &info); err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// Pools lists the server's ZFS pools
+func (c *TrueNASClient) Pools(ctx context.Context) ([]TrueNASPool, error) {
+	var pools []TrueNASPool
+	if err := c.get(ctx, "/pool", &pools); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+func (c *TrueNASClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the TrueNAS API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read TrueNAS API response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("TrueNAS API rejected the api_key (HTTP %d)", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("TrueNAS API GET %s: HTTP %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse TrueNAS API response: %w", err)
+	}
+	return nil
+}
+
+// CheckTrueNAS verifies that the API is reachable with the configured key
+// and that the pool the volumes go in exists and is healthy
+func CheckTrueNAS(ctx context.Context, cfg *TrueNASConfig) (*TrueNASCheck, error) {
+	client := NewTrueNASClient(cfg)
+
+	version, err := client.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := client.Pools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	poolName := cfg.PoolName()
+	for _, pool := range pools {
+		if pool.Name != poolName {
+			continue
+		}
+		check := &TrueNASCheck{Version: version, Pool: pool}
+		if !pool.Healthy || pool.Status != "ONLINE" {
+			return check, fmt.Errorf("pool %s is not healthy (status: %s)", pool.Name, pool.Status)
+		}
+		return check, nil
+	}
+	return nil, fmt.Errorf("pool %s not found on %s", poolName, cfg.APIURL)
+}
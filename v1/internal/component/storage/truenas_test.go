@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trueNASServer(t *testing.T, pools []map[string]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2.0/system/info":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"version": "TrueNAS-SCALE-24.04.2"})
+		case "/api/v2.0/pool":
+			_ = json.NewEncoder(w).Encode(pools)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckTrueNAS(t *testing.T) {
+	server := trueNASServer(t, []map[string]interface{}{
+		{"name": "boot", "status": "ONLINE", "healthy": true},
+		{"name": "tank", "status": "ONLINE", "healthy": true, "size": 1000, "allocated": 950, "free": 50},
+	})
+
+	check, err := CheckTrueNAS(context.Background(), &TrueNASConfig{APIURL: server.URL, APIKey: "good-key", ParentDataset: "tank/k8s"})
+	require.NoError(t, err)
+	assert.Equal(t, "TrueNAS-SCALE-24.04.2", check.Version)
+	assert.Equal(t, "tank", check.Pool.Name)
+	assert.Equal(t, int64(50), check.Pool.Free)
+	assert.InDelta(t, 5.0, check.Pool.FreePercent(), 0.01)
+}
+
+func TestCheckTrueNAS_Failures(t *testing.T) {
+	server := trueNASServer(t, []map[string]interface{}{
+		{"name": "tank", "status": "DEGRADED", "healthy": false, "size": 1000, "free": 500},
+	})
+
+	_, err := CheckTrueNAS(context.Background(), &TrueNASConfig{APIURL: server.URL, APIKey: "bad-key", Pool: "tank"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected the api_key")
+
+	_, err = CheckTrueNAS(context.Background(), &TrueNASConfig{APIURL: server.URL, APIKey: "good-key", Pool: "fast"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pool fast not found")
+
+	check, err := CheckTrueNAS(context.Background(), &TrueNASConfig{APIURL: server.URL, APIKey: "good-key", Pool: "tank"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not healthy (status: DEGRADED)")
+	require.NotNil(t, check, "the server was reachable")
+}
+
+func TestTrueNASComponentConfig(t *testing.T) {
+	pool := "tank"
+	insecure := true
+
+	section := TrueNASComponentConfig(&config.TrueNASConfig{APIURL: "https://nas.local", Pool: &pool, AllowInsecure: &insecure})
+	assert.Equal(t, map[string]interface{}{
+		"api_url":        "https://nas.local",
+		"api_key":        "${secret:truenas:api_key}",
+		"pool":           "tank",
+		"allow_insecure": true,
+	}, section, "the API key defaults to OpenBAO")
+
+	section = TrueNASComponentConfig(&config.TrueNASConfig{APIURL: "https://nas.local", APIKey: "${secret:nas/prod:key}", Drivers: []string{"iscsi"}})
+	assert.Equal(t, "${secret:nas/prod:key}", section["api_key"])
+	assert.Equal(t, []string{"iscsi"}, section["drivers"])
+}
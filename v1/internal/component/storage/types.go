@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -24,8 +26,27 @@ const (
 
 	// BackendLonghorn uses Longhorn for distributed block storage with replication
 	BackendLonghorn StorageBackend = "longhorn"
+
+	// BackendTrueNAS uses democratic-csi to provision NFS shares and iSCSI
+	// zvols on a TrueNAS server through its API
+	BackendTrueNAS StorageBackend = "truenas"
+)
+
+const (
+	// TrueNASDriverNFS provisions datasets shared over NFS (ReadWriteMany)
+	TrueNASDriverNFS = "nfs"
+
+	// TrueNASDriverISCSI provisions zvols exported over iSCSI (ReadWriteOnce)
+	TrueNASDriverISCSI = "iscsi"
+
+	// TrueNASSecretPath is where the TrueNAS API key lives in OpenBAO
+	// unless the config points elsewhere
+	TrueNASSecretPath = "truenas"
 )
 
+// TrueNASAPIKeyRef is the api_key the truenas section defaults to
+var TrueNASAPIKeyRef = fmt.Sprintf("${secret:%s:api_key}", TrueNASSecretPath)
+
 // Config holds storage component configuration
 type Config struct {
 	// Backend specifies which storage provisioner to use
@@ -52,6 +73,12 @@ type Config struct {
 	// Longhorn configuration (for BackendLonghorn)
 	Longhorn *LonghornConfig `json:"longhorn,omitempty" yaml:"longhorn,omitempty"`
 
+	// TrueNAS configuration (for BackendTrueNAS)
+	TrueNAS *TrueNASConfig `json:"truenas,omitempty" yaml:"truenas,omitempty"`
+
+	// SmartExporter deploys smartctl_exporter so disk health reaches Prometheus
+	SmartExporter *SmartExporterConfig `json:"smart_exporter,omitempty" yaml:"smart_exporter,omitempty"`
+
 	// Values allows passing additional Helm values
 	Values map[string]interface{} `json:"values" yaml:",inline"`
 }
@@ -105,6 +132,12 @@ type LonghornConfig struct {
 
 	// NodeDisks maps each Kubernetes node name to its Foundry-managed Longhorn disk.
 	NodeDisks map[string]LonghornNodeDiskConfig `json:"node_disks,omitempty" yaml:"node_disks,omitempty"`
+
+	// RecurringJobs are the snapshot and backup schedules Longhorn runs
+	RecurringJobs []LonghornRecurringJob `json:"recurring_jobs,omitempty" yaml:"recurring_jobs,omitempty"`
+
+	// Backup is the S3 bucket Longhorn backs volumes up to
+	Backup *LonghornBackupConfig `json:"backup,omitempty" yaml:"backup,omitempty"`
 }
 
 // LonghornNodeDiskConfig configures one Foundry-managed disk on a Longhorn node.
@@ -116,6 +149,55 @@ type LonghornNodeDiskConfig struct {
 	StorageReserved int64 `json:"storage_reserved" yaml:"storage_reserved"`
 }
 
+// TrueNASConfig holds configuration for democratic-csi on a TrueNAS server
+type TrueNASConfig struct {
+	// APIURL is the address of the TrueNAS web UI and API, e.g. https://nas.example.com
+	APIURL string `json:"api_url" yaml:"api_url"`
+
+	// APIKey authenticates to the TrueNAS API (default: api_key at truenas in OpenBAO)
+	APIKey string `json:"api_key" yaml:"api_key"`
+
+	// Pool is the ZFS pool volumes are created in
+	Pool string `json:"pool" yaml:"pool"`
+
+	// ParentDataset is the dataset volumes and snapshots are created under (default: <pool>/k8s)
+	ParentDataset string `json:"parent_dataset" yaml:"parent_dataset"`
+
+	// NFSServer is the address nodes mount NFS shares from (default: the API host)
+	NFSServer string `json:"nfs_server" yaml:"nfs_server"`
+
+	// ISCSIPortal is the iSCSI portal nodes log in to (default: the API host on port 3260)
+	ISCSIPortal string `json:"iscsi_portal" yaml:"iscsi_portal"`
+
+	// Drivers lists the democratic-csi drivers to install, nfs and/or iscsi
+	// (default: both). The first one's StorageClass is the cluster default.
+	Drivers []string `json:"drivers" yaml:"drivers"`
+
+	// AllowInsecure skips verification of the API's TLS certificate, which
+	// is self-signed on a fresh TrueNAS install
+	AllowInsecure bool `json:"allow_insecure" yaml:"allow_insecure"`
+}
+
+// Dataset returns the dataset democratic-csi provisions under
+func (t *TrueNASConfig) Dataset() string {
+	if t.ParentDataset != "" {
+		return t.ParentDataset
+	}
+	return t.Pool + "/k8s"
+}
+
+// PoolName returns the pool the volumes land in, which is the first
+// component of the parent dataset
+func (t *TrueNASConfig) PoolName() string {
+	return strings.SplitN(t.Dataset(), "/", 2)[0]
+}
+
+// TrueNASStorageClass returns the name of the StorageClass created for a
+// democratic-csi driver
+func TrueNASStorageClass(driver string) string {
+	return "truenas-" + driver
+}
+
 // HelmClient defines the Helm operations needed for storage component
 type HelmClient interface {
 	AddRepo(ctx context.Context, opts helm.RepoAddOptions) error
@@ -130,6 +212,8 @@ type K8sClient interface {
 	GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error)
 	ApplyManifest(ctx context.Context, manifest string) error
 	MergePatchResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, patch []byte) error
+	ListResourceNames(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) ([]string, error)
+	DeleteResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) error
 	ServiceMonitorCRDExists(ctx context.Context) (bool, error)
 }
 
@@ -213,6 +297,22 @@ func (c *Component) Status(ctx context.Context) (*component.ComponentStatus, err
 				}
 			}
 		}
+
+		// Check the democratic-csi namespace for the TrueNAS drivers
+		releases, err = c.helmClient.List(ctx, truenasNamespace)
+		if err == nil {
+			for _, rel := range releases {
+				if strings.HasPrefix(rel.Name, "truenas-") {
+					healthy := rel.Status == "deployed"
+					return &component.ComponentStatus{
+						Installed: true,
+						Version:   rel.AppVersion,
+						Healthy:   healthy,
+						Message:   fmt.Sprintf("release: %s, status: %s", rel.Name, rel.Status),
+					}, nil
+				}
+			}
+		}
 	}
 
 	// Fall back: When we don't have clients, we can't verify installation status
@@ -356,6 +456,57 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 				config.Longhorn.NodeDisks[nodeName] = disk
 			}
 		}
+		if rawJobs, exists := longhornCfg["recurring_jobs"]; exists {
+			jobs, err := parseLonghornRecurringJobs(rawJobs)
+			if err != nil {
+				return nil, err
+			}
+			config.Longhorn.RecurringJobs = jobs
+		}
+		if backupCfg, ok := longhornCfg["backup"].(map[string]interface{}); ok {
+			config.Longhorn.Backup = parseLonghornBackup(backupCfg)
+		}
+	}
+
+	if truenasCfg, ok := cfg.GetMap("truenas"); ok {
+		config.TrueNAS = &TrueNASConfig{}
+		if apiURL, ok := truenasCfg["api_url"].(string); ok {
+			config.TrueNAS.APIURL = apiURL
+		}
+		if apiKey, ok := truenasCfg["api_key"].(string); ok {
+			config.TrueNAS.APIKey = apiKey
+		}
+		if pool, ok := truenasCfg["pool"].(string); ok {
+			config.TrueNAS.Pool = pool
+		}
+		if parentDataset, ok := truenasCfg["parent_dataset"].(string); ok {
+			config.TrueNAS.ParentDataset = parentDataset
+		}
+		if nfsServer, ok := truenasCfg["nfs_server"].(string); ok {
+			config.TrueNAS.NFSServer = nfsServer
+		}
+		if iscsiPortal, ok := truenasCfg["iscsi_portal"].(string); ok {
+			config.TrueNAS.ISCSIPortal = iscsiPortal
+		}
+		switch drivers := truenasCfg["drivers"].(type) {
+		case []string:
+			config.TrueNAS.Drivers = drivers
+		case []interface{}:
+			for _, driver := range drivers {
+				name, ok := driver.(string)
+				if !ok {
+					return nil, fmt.Errorf("truenas drivers must be a list of strings")
+				}
+				config.TrueNAS.Drivers = append(config.TrueNAS.Drivers, name)
+			}
+		}
+		if allowInsecure, ok := truenasCfg["allow_insecure"].(bool); ok {
+			config.TrueNAS.AllowInsecure = allowInsecure
+		}
+	}
+
+	if smartExporterCfg, ok := cfg.GetMap("smart_exporter"); ok {
+		config.SmartExporter = parseSmartExporter(smartExporterCfg)
 	}
 
 	// Validate configuration
@@ -410,6 +561,12 @@ func (c *Config) Validate() error {
 				return fmt.Errorf("longhorn node_disks.%s.storage_reserved cannot be negative", nodeName)
 			}
 		}
+		return c.Longhorn.validateSnapshots()
+	case BackendTrueNAS:
+		if c.TrueNAS == nil {
+			return fmt.Errorf("truenas configuration required for truenas backend")
+		}
+		return c.TrueNAS.validate()
 	default:
 		return fmt.Errorf("unsupported storage backend: %s", c.Backend)
 	}
@@ -417,6 +574,55 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validate checks the TrueNAS configuration and fills in the defaults
+// derived from the API address
+func (t *TrueNASConfig) validate() error {
+	if t.APIURL == "" {
+		return fmt.Errorf("truenas api_url is required")
+	}
+	apiURL, err := url.Parse(t.APIURL)
+	if err != nil || (apiURL.Scheme != "http" && apiURL.Scheme != "https") || apiURL.Hostname() == "" {
+		return fmt.Errorf("truenas api_url %q must be an http(s) URL", t.APIURL)
+	}
+	if t.APIKey == "" {
+		return fmt.Errorf("truenas api_key is required")
+	}
+	if strings.HasPrefix(t.APIKey, "${secret:") {
+		return fmt.Errorf("truenas api_key %s is not resolved; store it in OpenBAO", t.APIKey)
+	}
+	if t.Pool == "" && t.ParentDataset == "" {
+		return fmt.Errorf("truenas pool or parent_dataset is required")
+	}
+	if strings.HasPrefix(t.Dataset(), "/") || strings.HasSuffix(t.Dataset(), "/") {
+		return fmt.Errorf("truenas parent_dataset %q must be a dataset name like tank/k8s", t.Dataset())
+	}
+	if t.Pool != "" && t.PoolName() != t.Pool {
+		return fmt.Errorf("truenas parent_dataset %q is not in pool %s", t.Dataset(), t.Pool)
+	}
+
+	if len(t.Drivers) == 0 {
+		t.Drivers = []string{TrueNASDriverNFS, TrueNASDriverISCSI}
+	}
+	seen := make(map[string]bool, len(t.Drivers))
+	for _, driver := range t.Drivers {
+		if driver != TrueNASDriverNFS && driver != TrueNASDriverISCSI {
+			return fmt.Errorf("unsupported truenas driver %q (use nfs or iscsi)", driver)
+		}
+		if seen[driver] {
+			return fmt.Errorf("truenas driver %q is listed twice", driver)
+		}
+		seen[driver] = true
+	}
+
+	if t.NFSServer == "" {
+		t.NFSServer = apiURL.Hostname()
+	}
+	if t.ISCSIPortal == "" {
+		t.ISCSIPortal = net.JoinHostPort(apiURL.Hostname(), "3260")
+	}
+	return nil
+}
+
 func integerValue(value interface{}) (int64, bool) {
 	switch number := value.(type) {
 	case int:
@@ -121,6 +121,74 @@ func TestParseConfig_Longhorn(t *testing.T) {
 	assert.Equal(t, int64(107374182400), config.Longhorn.NodeDisks["refurb"].StorageReserved)
 }
 
+func TestParseConfig_TrueNAS(t *testing.T) {
+	cfg := component.ComponentConfig{
+		"backend": "truenas",
+		"truenas": map[string]interface{}{
+			"api_url":        "https://nas.example.com:8443",
+			"api_key":        "1-abcdef",
+			"pool":           "tank",
+			"drivers":        []interface{}{"iscsi"},
+			"allow_insecure": true,
+		},
+	}
+
+	config, err := ParseConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, BackendTrueNAS, config.Backend)
+	require.NotNil(t, config.TrueNAS)
+	assert.Equal(t, "1-abcdef", config.TrueNAS.APIKey)
+	assert.Equal(t, "tank/k8s", config.TrueNAS.Dataset())
+	assert.Equal(t, []string{"iscsi"}, config.TrueNAS.Drivers)
+	assert.True(t, config.TrueNAS.AllowInsecure)
+	assert.Equal(t, "nas.example.com", config.TrueNAS.NFSServer, "defaults to the API host")
+	assert.Equal(t, "nas.example.com:3260", config.TrueNAS.ISCSIPortal, "defaults to the API host")
+}
+
+func TestValidate_TrueNAS(t *testing.T) {
+	valid := func() *TrueNASConfig {
+		return &TrueNASConfig{APIURL: "https://nas.local", APIKey: "key", Pool: "tank"}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*TrueNASConfig)
+		wantErr string
+	}{
+		{name: "valid", modify: func(*TrueNASConfig) {}},
+		{name: "parent dataset only", modify: func(c *TrueNASConfig) { c.Pool = ""; c.ParentDataset = "fast/kube" }},
+		{name: "missing api_url", modify: func(c *TrueNASConfig) { c.APIURL = "" }, wantErr: "api_url is required"},
+		{name: "api_url without scheme", modify: func(c *TrueNASConfig) { c.APIURL = "nas.local" }, wantErr: "must be an http(s) URL"},
+		{name: "missing api_key", modify: func(c *TrueNASConfig) { c.APIKey = "" }, wantErr: "api_key is required"},
+		{name: "unresolved api_key", modify: func(c *TrueNASConfig) { c.APIKey = TrueNASAPIKeyRef }, wantErr: "not resolved"},
+		{name: "missing pool", modify: func(c *TrueNASConfig) { c.Pool = "" }, wantErr: "pool or parent_dataset is required"},
+		{name: "dataset outside pool", modify: func(c *TrueNASConfig) { c.ParentDataset = "other/k8s" }, wantErr: "is not in pool tank"},
+		{name: "unknown driver", modify: func(c *TrueNASConfig) { c.Drivers = []string{"smb"} }, wantErr: "unsupported truenas driver"},
+		{name: "duplicate driver", modify: func(c *TrueNASConfig) { c.Drivers = []string{"nfs", "nfs"} }, wantErr: "listed twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truenas := valid()
+			tt.modify(truenas)
+			err := (&Config{Backend: BackendTrueNAS, TrueNAS: truenas}).Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, []string{TrueNASDriverNFS, TrueNASDriverISCSI}, truenas.Drivers)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_TrueNAS_MissingConfig(t *testing.T) {
+	err := (&Config{Backend: BackendTrueNAS}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "truenas configuration required")
+}
+
 func TestParseConfig_Longhorn_InvalidNodeDiskValue(t *testing.T) {
 	cfg := component.ComponentConfig{
 		"backend": "longhorn",
@@ -400,6 +468,9 @@ type mockK8sClient struct {
 	serviceMonitorCRDExistsErr error
 	patches                    []resourcePatch
 	patchErr                   error
+	// resourceNames is what ListResourceNames returns
+	resourceNames []string
+	deleted       []string
 }
 
 type resourcePatch struct {
@@ -423,6 +494,15 @@ func (m *mockK8sClient) MergePatchResource(ctx context.Context, gvr schema.Group
 	return m.patchErr
 }
 
+func (m *mockK8sClient) ListResourceNames(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) ([]string, error) {
+	return m.resourceNames, nil
+}
+
+func (m *mockK8sClient) DeleteResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) error {
+	m.deleted = append(m.deleted, name)
+	return nil
+}
+
 func (m *mockK8sClient) ServiceMonitorCRDExists(ctx context.Context) (bool, error) {
 	return m.serviceMonitorCRDExists, m.serviceMonitorCRDExistsErr
 }
@@ -0,0 +1,339 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+const (
+	tempoRepoName = "grafana"
+	tempoRepoURL  = "https://grafana.github.io/helm-charts"
+	tempoChart    = "grafana/tempo"
+	releaseName   = "tempo"
+
+	// bucketJobName is the Job that creates the trace bucket
+	bucketJobName = "tempo-bucket-setup"
+)
+
+var tcpRouteGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tcproutes"}
+
+// otlpRoutes names the TCPRoute for each OTLP port exposed on the Gateway
+var otlpRoutes = []struct {
+	name string
+	port int
+}{
+	{"tempo-otlp-grpc", OTLPGRPCPort},
+	{"tempo-otlp-http", OTLPHTTPPort},
+}
+
+// Install installs Tempo using Helm
+func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cfg *Config) error {
+	if helmClient == nil {
+		return fmt.Errorf("helm client cannot be nil")
+	}
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	fmt.Println("  Installing Tempo...")
+
+	// Add Helm repository
+	if err := helmClient.AddRepo(ctx, helm.RepoAddOptions{
+		Name:        tempoRepoName,
+		URL:         tempoRepoURL,
+		ForceUpdate: true,
+	}); err != nil {
+		return fmt.Errorf("failed to add helm repository: %w", err)
+	}
+
+	// Tempo doesn't create its bucket, and SeaweedFS only creates the buckets
+	// it was installed with
+	if cfg.StorageBackend == BackendS3 && k8sClient != nil {
+		if err := ensureBucket(ctx, k8sClient, cfg); err != nil {
+			return err
+		}
+	}
+
+	values := buildHelmValues(cfg)
+	if !serviceMonitorCRDAvailable(ctx, k8sClient) {
+		values["serviceMonitor"].(map[string]interface{})["enabled"] = false
+	}
+
+	// Check if release already exists
+	var releaseExists bool
+	var releaseStatus string
+	releases, err := helmClient.List(ctx, cfg.Namespace)
+	if err == nil {
+		for _, rel := range releases {
+			if rel.Name == releaseName {
+				releaseExists = true
+				releaseStatus = rel.Status
+				break
+			}
+		}
+	}
+
+	if releaseExists {
+		// Upgrade in place, even a failed release, so the WAL PVC is kept
+		fmt.Printf("  Upgrading Tempo (current status: %s)...\n", releaseStatus)
+		if err := helmClient.Upgrade(ctx, helm.UpgradeOptions{
+			ReleaseName: releaseName,
+			Namespace:   cfg.Namespace,
+			Chart:       tempoChart,
+			Version:     cfg.Version,
+			Values:      values,
+			Wait:        false,
+			Timeout:     2 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to upgrade tempo: %w", err)
+		}
+	} else {
+		if err := helmClient.Install(ctx, helm.InstallOptions{
+			ReleaseName:     releaseName,
+			Namespace:       cfg.Namespace,
+			Chart:           tempoChart,
+			Version:         cfg.Version,
+			Values:          values,
+			CreateNamespace: true,
+			Wait:            false, // Don't wait - the WAL volume takes time to attach
+			Timeout:         2 * time.Minute,
+		}); err != nil {
+			return fmt.Errorf("failed to install tempo: %w", err)
+		}
+	}
+
+	if k8sClient != nil {
+		if cfg.GatewayEnabled {
+			if err := k8sClient.ApplyManifest(ctx, routesManifest(cfg)); err != nil {
+				return fmt.Errorf("failed to create OTLP TCPRoutes: %w", err)
+			}
+		} else if err := removeRoutes(ctx, k8sClient, cfg.Namespace); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("  Tempo installed successfully")
+	fmt.Printf("  OTLP gRPC endpoint: %s\n", cfg.GetOTLPGRPCEndpoint())
+	fmt.Printf("  OTLP HTTP endpoint: %s\n", cfg.GetOTLPHTTPEndpoint())
+	if cfg.GatewayEnabled {
+		fmt.Printf("  OTLP on the Gateway: ports %d (gRPC) and %d (HTTP) on the cluster VIP\n", OTLPGRPCPort, OTLPHTTPPort)
+	}
+
+	return nil
+}
+
+func serviceMonitorCRDAvailable(ctx context.Context, k8sClient K8sClient) bool {
+	if k8sClient == nil {
+		return false
+	}
+	exists, err := k8sClient.ServiceMonitorCRDExists(ctx)
+	if err != nil {
+		fmt.Printf("  Warning: could not check for the ServiceMonitor CRD: %v\n", err)
+		return false
+	}
+	return exists
+}
+
+// Charts returns the Helm chart Install uses for Tempo
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: tempoRepoName,
+		RepoURL:  tempoRepoURL,
+		Chart:    tempoChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
+// buildHelmValues constructs Helm values for the single-binary Tempo chart
+func buildHelmValues(cfg *Config) map[string]interface{} {
+	values := make(map[string]interface{})
+
+	// Start with user-provided values
+	for k, v := range cfg.Values {
+		values[k] = v
+	}
+
+	// Trace storage
+	trace := map[string]interface{}{
+		"backend": "local",
+		"local": map[string]interface{}{
+			"path": "/var/tempo/traces",
+		},
+		"wal": map[string]interface{}{
+			"path": "/var/tempo/wal",
+		},
+	}
+	if cfg.StorageBackend == BackendS3 {
+		host, insecure, _ := cfg.s3Host()
+		trace = map[string]interface{}{
+			"backend": "s3",
+			"s3": map[string]interface{}{
+				"bucket":         cfg.S3Bucket,
+				"endpoint":       host,
+				"access_key":     cfg.S3AccessKey,
+				"secret_key":     cfg.S3SecretKey,
+				"region":         cfg.S3Region,
+				"insecure":       insecure,
+				"forcepathstyle": true, // Required for SeaweedFS
+			},
+			"wal": map[string]interface{}{
+				"path": "/var/tempo/wal",
+			},
+		}
+	}
+
+	values["tempo"] = map[string]interface{}{
+		"retention": fmt.Sprintf("%dh", cfg.RetentionDays*24),
+		"storage": map[string]interface{}{
+			"trace": trace,
+		},
+		// OTLP is the only receiver; other protocols go through a collector
+		"receivers": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"protocols": map[string]interface{}{
+					"grpc": map[string]interface{}{
+						"endpoint": fmt.Sprintf("0.0.0.0:%d", OTLPGRPCPort),
+					},
+					"http": map[string]interface{}{
+						"endpoint": fmt.Sprintf("0.0.0.0:%d", OTLPHTTPPort),
+					},
+				},
+			},
+		},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"cpu":    "100m",
+				"memory": "256Mi",
+			},
+		},
+	}
+
+	// The PVC holds the WAL (and the blocks with the local backend)
+	persistence := map[string]interface{}{
+		"enabled": true,
+		"size":    cfg.StorageSize,
+	}
+	if cfg.StorageClass != "" {
+		persistence["storageClassName"] = cfg.StorageClass
+	}
+	values["persistence"] = persistence
+
+	values["serviceMonitor"] = map[string]interface{}{
+		"enabled": true,
+	}
+
+	return values
+}
+
+// routesManifest builds a TCPRoute per OTLP port. The foundry gateway
+// controller opens a matching listener on the Gateway and the cluster VIP.
+func routesManifest(cfg *Config) string {
+	docs := make([]string, 0, len(otlpRoutes))
+	for _, r := range otlpRoutes {
+		docs = append(docs, fmt.Sprintf(`apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: TCPRoute
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app.kubernetes.io/name: tempo
+    app.kubernetes.io/managed-by: foundry
+spec:
+  parentRefs:
+  - group: gateway.networking.k8s.io
+    kind: Gateway
+    name: %s
+    namespace: %s
+    port: %d
+  rules:
+  - backendRefs:
+    - name: %s
+      port: %d
+`, r.name, cfg.Namespace, cfg.GatewayName, cfg.GatewayNamespace, r.port, releaseName, r.port))
+	}
+	return strings.Join(docs, "---\n")
+}
+
+// removeRoutes deletes the OTLP TCPRoutes left by an install with the
+// Gateway enabled, so their listeners close
+func removeRoutes(ctx context.Context, k8sClient K8sClient, namespace string) error {
+	dyn := k8sClient.DynamicClient()
+	if dyn == nil {
+		return nil
+	}
+	for _, r := range otlpRoutes {
+		err := dyn.Resource(tcpRouteGVR).Namespace(namespace).Delete(ctx, r.name, metav1.DeleteOptions{})
+		if err == nil {
+			fmt.Printf("  Removed TCPRoute %s/%s\n", namespace, r.name)
+			continue
+		}
+		// Nothing to remove, or no Gateway API TCPRoute CRD at all
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		return fmt.Errorf("failed to remove TCPRoute %s/%s: %w", namespace, r.name, err)
+	}
+	return nil
+}
+
+// ensureBucket creates the trace bucket with a one-off Job, since the S3
+// endpoint is only reachable inside the cluster
+func ensureBucket(ctx context.Context, k8sClient K8sClient, cfg *Config) error {
+	fmt.Printf("  Ensuring S3 bucket %q exists...\n", cfg.S3Bucket)
+
+	jobManifest := fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  ttlSecondsAfterFinished: 60
+  backoffLimit: 3
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: bucket-setup
+        image: amazon/aws-cli:2.15.0
+        env:
+        - name: AWS_ACCESS_KEY_ID
+          value: %q
+        - name: AWS_SECRET_ACCESS_KEY
+          value: %q
+        - name: AWS_DEFAULT_REGION
+          value: %q
+        command: ["/bin/sh", "-c", "aws s3api head-bucket --bucket %s --endpoint-url %s || aws s3 mb s3://%s --endpoint-url %s"]
+`, cfg.Namespace, bucketJobName, cfg.Namespace, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3Region,
+		cfg.S3Bucket, cfg.S3Endpoint, cfg.S3Bucket, cfg.S3Endpoint)
+
+	// Delete any existing job with the same name
+	_ = k8sClient.DeleteJob(ctx, cfg.Namespace, bucketJobName)
+
+	if err := k8sClient.ApplyManifest(ctx, jobManifest); err != nil {
+		return fmt.Errorf("failed to create bucket setup job: %w", err)
+	}
+	if err := k8sClient.WaitForJobComplete(ctx, cfg.Namespace, bucketJobName, 2*time.Minute); err != nil {
+		return fmt.Errorf("bucket setup job failed: %w", err)
+	}
+	_ = k8sClient.DeleteJob(ctx, cfg.Namespace, bucketJobName)
+
+	return nil
+}
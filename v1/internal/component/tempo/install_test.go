@@ -0,0 +1,188 @@
+package tempo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+)
+
+func TestInstall_Success(t *testing.T) {
+	helmClient := &mockHelmClient{}
+	k8sClient := &mockK8sClient{}
+
+	err := Install(context.Background(), helmClient, k8sClient, DefaultConfig())
+	require.NoError(t, err)
+
+	require.Len(t, helmClient.reposAdded, 1)
+	assert.Equal(t, tempoRepoURL, helmClient.reposAdded[0].URL)
+
+	require.Len(t, helmClient.chartsInstalled, 1)
+	assert.Equal(t, releaseName, helmClient.chartsInstalled[0].ReleaseName)
+	assert.Equal(t, "monitoring", helmClient.chartsInstalled[0].Namespace)
+	assert.Equal(t, tempoChart, helmClient.chartsInstalled[0].Chart)
+	assert.True(t, helmClient.chartsInstalled[0].CreateNamespace)
+
+	// The bucket job ran before the chart
+	require.Len(t, k8sClient.manifests, 1)
+	assert.Contains(t, k8sClient.manifests[0], "name: "+bucketJobName)
+	assert.Contains(t, k8sClient.manifests[0], "aws s3 mb s3://tempo")
+	assert.Equal(t, []string{"monitoring/" + bucketJobName}, k8sClient.jobsWaited)
+
+	serviceMonitor := helmClient.chartsInstalled[0].Values["serviceMonitor"].(map[string]interface{})
+	assert.Equal(t, false, serviceMonitor["enabled"], "no ServiceMonitor without the CRD")
+}
+
+func TestInstall_LocalBackendSkipsBucket(t *testing.T) {
+	k8sClient := &mockK8sClient{serviceMonitorCRDExists: true}
+	cfg := DefaultConfig()
+	cfg.StorageBackend = BackendLocal
+
+	helmClient := &mockHelmClient{}
+	require.NoError(t, Install(context.Background(), helmClient, k8sClient, cfg))
+	assert.Empty(t, k8sClient.manifests)
+	assert.Empty(t, k8sClient.jobsWaited)
+
+	serviceMonitor := helmClient.chartsInstalled[0].Values["serviceMonitor"].(map[string]interface{})
+	assert.Equal(t, true, serviceMonitor["enabled"])
+}
+
+func TestInstall_BucketJobFails(t *testing.T) {
+	helmClient := &mockHelmClient{}
+	k8sClient := &mockK8sClient{jobErr: errors.New("backoff limit exceeded")}
+
+	err := Install(context.Background(), helmClient, k8sClient, DefaultConfig())
+	assert.ErrorContains(t, err, "bucket setup job failed")
+	assert.Empty(t, helmClient.chartsInstalled)
+}
+
+func TestInstall_AlreadyInstalled(t *testing.T) {
+	helmClient := &mockHelmClient{
+		listReleases: []helm.Release{{Name: releaseName, Namespace: "monitoring", Status: "failed"}},
+	}
+
+	err := Install(context.Background(), helmClient, &mockK8sClient{}, DefaultConfig())
+	require.NoError(t, err)
+	assert.Empty(t, helmClient.chartsInstalled)
+	require.Len(t, helmClient.upgradeCalls, 1)
+	assert.Equal(t, releaseName, helmClient.upgradeCalls[0].ReleaseName)
+}
+
+func TestInstall_NilHelmClient(t *testing.T) {
+	err := Install(context.Background(), nil, nil, DefaultConfig())
+	assert.ErrorContains(t, err, "helm client cannot be nil")
+}
+
+func TestInstall_AddRepoError(t *testing.T) {
+	helmClient := &mockHelmClient{addRepoErr: errors.New("network")}
+	err := Install(context.Background(), helmClient, nil, DefaultConfig())
+	assert.ErrorContains(t, err, "failed to add helm repository")
+}
+
+func TestInstall_GatewayRoutes(t *testing.T) {
+	k8sClient := &mockK8sClient{}
+	cfg := DefaultConfig()
+	cfg.StorageBackend = BackendLocal
+	cfg.GatewayEnabled = true
+
+	require.NoError(t, Install(context.Background(), &mockHelmClient{}, k8sClient, cfg))
+	require.Len(t, k8sClient.manifests, 1)
+	assert.Equal(t, routesManifest(cfg), k8sClient.manifests[0])
+}
+
+func TestInstall_GatewayDisabledRemovesRoutes(t *testing.T) {
+	route := &unstructured.Unstructured{}
+	route.SetAPIVersion("gateway.networking.k8s.io/v1alpha2")
+	route.SetKind("TCPRoute")
+	route.SetNamespace("monitoring")
+	route.SetName("tempo-otlp-grpc")
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), route)
+
+	cfg := DefaultConfig()
+	cfg.StorageBackend = BackendLocal
+	require.NoError(t, Install(context.Background(), &mockHelmClient{}, &mockK8sClient{dynamicClient: dyn}, cfg))
+
+	_, err := dyn.Resource(tcpRouteGVR).Namespace("monitoring").Get(context.Background(), "tempo-otlp-grpc", metav1.GetOptions{})
+	assert.Error(t, err, "route removed; the missing http route is not an error")
+}
+
+func TestRoutesManifest(t *testing.T) {
+	cfg := DefaultConfig()
+	docs := strings.Split(routesManifest(cfg), "---\n")
+	require.Len(t, docs, 2)
+
+	var route map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &route))
+	assert.Equal(t, "TCPRoute", route["kind"])
+	metadata := route["metadata"].(map[string]interface{})
+	assert.Equal(t, "tempo-otlp-http", metadata["name"])
+	assert.Equal(t, "monitoring", metadata["namespace"])
+
+	spec := route["spec"].(map[string]interface{})
+	parent := spec["parentRefs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "contour", parent["name"])
+	assert.Equal(t, "projectcontour", parent["namespace"])
+	assert.EqualValues(t, OTLPHTTPPort, parent["port"])
+	backend := spec["rules"].([]interface{})[0].(map[string]interface{})["backendRefs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "tempo", backend["name"])
+	assert.EqualValues(t, OTLPHTTPPort, backend["port"])
+}
+
+func TestBuildHelmValues_S3Storage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.S3AccessKey = "access"
+	cfg.S3SecretKey = "secret"
+
+	values := buildHelmValues(cfg)
+	tempo := values["tempo"].(map[string]interface{})
+	assert.Equal(t, "336h", tempo["retention"])
+
+	trace := tempo["storage"].(map[string]interface{})["trace"].(map[string]interface{})
+	assert.Equal(t, "s3", trace["backend"])
+	s3 := trace["s3"].(map[string]interface{})
+	assert.Equal(t, "tempo", s3["bucket"])
+	assert.Equal(t, "seaweedfs-s3.seaweedfs.svc.cluster.local:8333", s3["endpoint"])
+	assert.Equal(t, "access", s3["access_key"])
+	assert.Equal(t, "secret", s3["secret_key"])
+	assert.Equal(t, true, s3["insecure"])
+	assert.Equal(t, true, s3["forcepathstyle"])
+
+	protocols := tempo["receivers"].(map[string]interface{})["otlp"].(map[string]interface{})["protocols"].(map[string]interface{})
+	assert.Equal(t, "0.0.0.0:4317", protocols["grpc"].(map[string]interface{})["endpoint"])
+	assert.Equal(t, "0.0.0.0:4318", protocols["http"].(map[string]interface{})["endpoint"])
+}
+
+func TestBuildHelmValues_LocalStorage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.StorageBackend = BackendLocal
+	cfg.StorageClass = "longhorn"
+	cfg.Values = map[string]interface{}{"replicas": 1}
+
+	values := buildHelmValues(cfg)
+	trace := values["tempo"].(map[string]interface{})["storage"].(map[string]interface{})["trace"].(map[string]interface{})
+	assert.Equal(t, "local", trace["backend"])
+	assert.NotContains(t, trace, "s3")
+
+	persistence := values["persistence"].(map[string]interface{})
+	assert.Equal(t, true, persistence["enabled"])
+	assert.Equal(t, "10Gi", persistence["size"])
+	assert.Equal(t, "longhorn", persistence["storageClassName"])
+	assert.Equal(t, 1, values["replicas"])
+}
+
+func TestCharts(t *testing.T) {
+	charts := Charts(nil)
+	require.Len(t, charts, 1)
+	assert.Equal(t, tempoChart, charts[0].Chart)
+	assert.Equal(t, DefaultConfig().Version, charts[0].Version)
+}
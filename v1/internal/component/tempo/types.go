@@ -0,0 +1,323 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+// StorageBackend represents the storage backend for Tempo
+type StorageBackend string
+
+const (
+	// BackendLocal stores traces on the Tempo PVC (simple, for dev/test)
+	BackendLocal StorageBackend = "local"
+
+	// BackendS3 uses S3-compatible storage (SeaweedFS or other)
+	BackendS3 StorageBackend = "s3"
+)
+
+const (
+	// OTLPGRPCPort is the OTLP gRPC receiver port, in the cluster and on the Gateway
+	OTLPGRPCPort = 4317
+
+	// OTLPHTTPPort is the OTLP HTTP receiver port, in the cluster and on the Gateway
+	OTLPHTTPPort = 4318
+
+	// queryPort is Tempo's HTTP API port, used by the Grafana datasource
+	queryPort = 3200
+)
+
+// Config holds Tempo component configuration
+type Config struct {
+	// Version is the Helm chart version to install
+	Version string `json:"version" yaml:"version"`
+
+	// Namespace for Tempo deployment
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// StorageBackend specifies where trace blocks are stored (local or s3)
+	StorageBackend StorageBackend `json:"storage_backend" yaml:"storage_backend"`
+
+	// RetentionDays is how many days to retain traces
+	RetentionDays int `json:"retention_days" yaml:"retention_days"`
+
+	// StorageClass is the StorageClass to use for the Tempo PVC (WAL, and
+	// blocks with the local backend)
+	StorageClass string `json:"storage_class" yaml:"storage_class"`
+
+	// StorageSize is the size of the Tempo PVC
+	StorageSize string `json:"storage_size" yaml:"storage_size"`
+
+	// S3 configuration (required when StorageBackend is s3)
+	S3Endpoint  string `json:"s3_endpoint" yaml:"s3_endpoint"`
+	S3Bucket    string `json:"s3_bucket" yaml:"s3_bucket"`
+	S3AccessKey string `json:"s3_access_key" yaml:"s3_access_key"`
+	S3SecretKey string `json:"s3_secret_key" yaml:"s3_secret_key"`
+	S3Region    string `json:"s3_region" yaml:"s3_region"`
+
+	// GatewayEnabled opens the OTLP ports on the Contour Gateway (and so on
+	// the cluster VIP) through TCPRoutes, for senders outside the cluster.
+	// The foundry gateway controller must be running to program the listeners.
+	GatewayEnabled bool `json:"gateway_enabled" yaml:"gateway_enabled"`
+
+	// GatewayName and GatewayNamespace locate the Contour Gateway
+	GatewayName      string `json:"gateway_name" yaml:"gateway_name"`
+	GatewayNamespace string `json:"gateway_namespace" yaml:"gateway_namespace"`
+
+	// Values allows passing additional Helm values
+	Values map[string]interface{} `json:"values" yaml:",inline"`
+}
+
+// HelmClient defines the Helm operations needed for Tempo component
+type HelmClient interface {
+	AddRepo(ctx context.Context, opts helm.RepoAddOptions) error
+	Install(ctx context.Context, opts helm.InstallOptions) error
+	Upgrade(ctx context.Context, opts helm.UpgradeOptions) error
+	Uninstall(ctx context.Context, opts helm.UninstallOptions) error
+	List(ctx context.Context, namespace string) ([]helm.Release, error)
+}
+
+// K8sClient defines the Kubernetes operations needed for Tempo component
+type K8sClient interface {
+	GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error)
+	ServiceMonitorCRDExists(ctx context.Context) (bool, error)
+	ApplyManifest(ctx context.Context, manifest string) error
+	DeleteJob(ctx context.Context, namespace, name string) error
+	WaitForJobComplete(ctx context.Context, namespace, name string, timeout time.Duration) error
+	DynamicClient() dynamic.Interface
+}
+
+// Component implements the component.Component interface for Tempo
+type Component struct {
+	helmClient HelmClient
+	k8sClient  K8sClient
+}
+
+// NewComponent creates a new Tempo component instance
+func NewComponent(helmClient HelmClient, k8sClient K8sClient) *Component {
+	return &Component{
+		helmClient: helmClient,
+		k8sClient:  k8sClient,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return "tempo"
+}
+
+// Install installs Tempo
+func (c *Component) Install(ctx context.Context, cfg component.ComponentConfig) error {
+	config, err := ParseConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	return Install(ctx, c.helmClient, c.k8sClient, config)
+}
+
+// Upgrade upgrades Tempo
+func (c *Component) Upgrade(ctx context.Context, cfg component.ComponentConfig) error {
+	return fmt.Errorf("upgrade not yet implemented")
+}
+
+// Status returns the current status of Tempo
+func (c *Component) Status(ctx context.Context) (*component.ComponentStatus, error) {
+	if c.helmClient == nil {
+		return &component.ComponentStatus{
+			Installed: false,
+			Healthy:   false,
+			Message:   "helm client not initialized",
+		}, nil
+	}
+
+	releases, err := c.helmClient.List(ctx, DefaultConfig().Namespace)
+	if err != nil {
+		return &component.ComponentStatus{
+			Installed: false,
+			Healthy:   false,
+			Message:   fmt.Sprintf("failed to list releases: %v", err),
+		}, nil
+	}
+
+	for _, rel := range releases {
+		if rel.Name == releaseName {
+			healthy := rel.Status == "deployed"
+			return &component.ComponentStatus{
+				Installed: true,
+				Version:   rel.AppVersion,
+				Healthy:   healthy,
+				Message:   fmt.Sprintf("release status: %s", rel.Status),
+			}, nil
+		}
+	}
+
+	return &component.ComponentStatus{
+		Installed: false,
+		Healthy:   false,
+		Message:   "tempo release not found",
+	}, nil
+}
+
+// Uninstall removes Tempo
+func (c *Component) Uninstall(ctx context.Context) error {
+	return fmt.Errorf("uninstall not yet implemented")
+}
+
+// Dependencies returns the list of components that Tempo depends on
+func (c *Component) Dependencies() []string {
+	return []string{"storage", "seaweedfs"} // Tempo needs storage and SeaweedFS for S3
+}
+
+// DefaultConfig returns a Config with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Version:          "1.23.2", // tempo Helm chart version
+		Namespace:        "monitoring",
+		StorageBackend:   BackendS3, // Use SeaweedFS by default
+		RetentionDays:    14,
+		StorageClass:     "", // Use cluster default
+		StorageSize:      "10Gi",
+		S3Endpoint:       "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333",
+		S3Bucket:         "tempo",
+		S3Region:         "us-east-1",
+		GatewayEnabled:   false,
+		GatewayName:      "contour",
+		GatewayNamespace: "projectcontour",
+		Values:           make(map[string]interface{}),
+	}
+}
+
+// ParseConfig parses a ComponentConfig into a Tempo Config
+func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
+	config := DefaultConfig()
+
+	if version, ok := cfg.GetString("version"); ok {
+		config.Version = version
+	}
+
+	if namespace, ok := cfg.GetString("namespace"); ok {
+		config.Namespace = namespace
+	}
+
+	if storageBackend, ok := cfg.GetString("storage_backend"); ok {
+		config.StorageBackend = StorageBackend(storageBackend)
+	}
+
+	if retentionDays, ok := cfg.GetInt("retention_days"); ok {
+		config.RetentionDays = retentionDays
+	}
+
+	if storageClass, ok := cfg.GetString("storage_class"); ok {
+		config.StorageClass = storageClass
+	}
+
+	if storageSize, ok := cfg.GetString("storage_size"); ok {
+		config.StorageSize = storageSize
+	}
+
+	if s3Endpoint, ok := cfg.GetString("s3_endpoint"); ok {
+		config.S3Endpoint = s3Endpoint
+	}
+
+	if s3Bucket, ok := cfg.GetString("s3_bucket"); ok {
+		config.S3Bucket = s3Bucket
+	}
+
+	if s3AccessKey, ok := cfg.GetString("s3_access_key"); ok {
+		config.S3AccessKey = s3AccessKey
+	}
+
+	if s3SecretKey, ok := cfg.GetString("s3_secret_key"); ok {
+		config.S3SecretKey = s3SecretKey
+	}
+
+	if s3Region, ok := cfg.GetString("s3_region"); ok {
+		config.S3Region = s3Region
+	}
+
+	if gatewayEnabled, ok := cfg.GetBool("gateway_enabled"); ok {
+		config.GatewayEnabled = gatewayEnabled
+	}
+
+	if gatewayName, ok := cfg.GetString("gateway_name"); ok {
+		config.GatewayName = gatewayName
+	}
+
+	if gatewayNamespace, ok := cfg.GetString("gateway_namespace"); ok {
+		config.GatewayNamespace = gatewayNamespace
+	}
+
+	if values, ok := cfg.GetMap("values"); ok {
+		config.Values = values
+	}
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate validates the Tempo configuration
+func (c *Config) Validate() error {
+	if c.RetentionDays < 1 {
+		return fmt.Errorf("retention_days must be at least 1")
+	}
+
+	switch c.StorageBackend {
+	case BackendLocal:
+	case BackendS3:
+		if c.S3Endpoint == "" {
+			return fmt.Errorf("s3_endpoint is required when using S3 storage backend")
+		}
+		if _, _, err := c.s3Host(); err != nil {
+			return err
+		}
+		if c.S3Bucket == "" {
+			return fmt.Errorf("s3_bucket is required when using S3 storage backend")
+		}
+	default:
+		return fmt.Errorf("storage_backend must be %q or %q, got %q", BackendLocal, BackendS3, c.StorageBackend)
+	}
+
+	if c.GatewayEnabled && (c.GatewayName == "" || c.GatewayNamespace == "") {
+		return fmt.Errorf("gateway_name and gateway_namespace are required when gateway_enabled is set")
+	}
+
+	return nil
+}
+
+// s3Host splits the S3 endpoint URL into the host:port Tempo expects and
+// whether it is plain HTTP
+func (c *Config) s3Host() (string, bool, error) {
+	u, err := url.Parse(c.S3Endpoint)
+	if err != nil || u.Host == "" {
+		return "", false, fmt.Errorf("s3_endpoint must be a URL such as http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333, got %q", c.S3Endpoint)
+	}
+	return u.Host, u.Scheme == "http", nil
+}
+
+// GetQueryEndpoint returns the Tempo HTTP API URL, used by the Grafana datasource
+func (c *Config) GetQueryEndpoint() string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", releaseName, c.Namespace, queryPort)
+}
+
+// GetOTLPGRPCEndpoint returns the in-cluster OTLP gRPC address (host:port)
+func (c *Config) GetOTLPGRPCEndpoint() string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local:%d", releaseName, c.Namespace, OTLPGRPCPort)
+}
+
+// GetOTLPHTTPEndpoint returns the in-cluster OTLP HTTP URL
+func (c *Config) GetOTLPHTTPEndpoint() string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", releaseName, c.Namespace, OTLPHTTPPort)
+}
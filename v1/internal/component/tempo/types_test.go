@@ -0,0 +1,218 @@
+package tempo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/catalystcommunity/foundry/v1/internal/k8s"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	assert.Equal(t, "monitoring", cfg.Namespace)
+	assert.Equal(t, BackendS3, cfg.StorageBackend)
+	assert.Equal(t, 14, cfg.RetentionDays)
+	assert.Equal(t, "10Gi", cfg.StorageSize)
+	assert.Equal(t, "tempo", cfg.S3Bucket)
+	assert.False(t, cfg.GatewayEnabled)
+	assert.Equal(t, "contour", cfg.GatewayName)
+	assert.Equal(t, "projectcontour", cfg.GatewayNamespace)
+	assert.NotNil(t, cfg.Values)
+}
+
+func TestParseConfig_Defaults(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg)
+}
+
+func TestParseConfig_CustomValues(t *testing.T) {
+	cfg, err := ParseConfig(component.ComponentConfig{
+		"version":         "1.24.0",
+		"namespace":       "tracing",
+		"storage_backend": "s3",
+		"retention_days":  7,
+		"storage_class":   "longhorn",
+		"storage_size":    "20Gi",
+		"s3_endpoint":     "https://s3.example.com",
+		"s3_bucket":       "traces",
+		"s3_access_key":   "access",
+		"s3_secret_key":   "secret",
+		"s3_region":       "eu-west-1",
+		"gateway_enabled": true,
+		"values": map[string]interface{}{
+			"replicas": 2,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.24.0", cfg.Version)
+	assert.Equal(t, "tracing", cfg.Namespace)
+	assert.Equal(t, 7, cfg.RetentionDays)
+	assert.Equal(t, "longhorn", cfg.StorageClass)
+	assert.Equal(t, "20Gi", cfg.StorageSize)
+	assert.Equal(t, "https://s3.example.com", cfg.S3Endpoint)
+	assert.Equal(t, "traces", cfg.S3Bucket)
+	assert.Equal(t, "access", cfg.S3AccessKey)
+	assert.Equal(t, "secret", cfg.S3SecretKey)
+	assert.Equal(t, "eu-west-1", cfg.S3Region)
+	assert.True(t, cfg.GatewayEnabled)
+	assert.Equal(t, 2, cfg.Values["replicas"])
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "local backend", modify: func(c *Config) {
+			c.StorageBackend = BackendLocal
+			c.S3Endpoint = ""
+		}},
+		{name: "retention", modify: func(c *Config) { c.RetentionDays = 0 }, wantErr: "retention_days"},
+		{name: "unknown backend", modify: func(c *Config) { c.StorageBackend = "gcs" }, wantErr: "storage_backend"},
+		{name: "missing endpoint", modify: func(c *Config) { c.S3Endpoint = "" }, wantErr: "s3_endpoint is required"},
+		{name: "endpoint without scheme", modify: func(c *Config) { c.S3Endpoint = "seaweedfs-s3:8333" }, wantErr: "must be a URL"},
+		{name: "missing bucket", modify: func(c *Config) { c.S3Bucket = "" }, wantErr: "s3_bucket"},
+		{name: "gateway without name", modify: func(c *Config) {
+			c.GatewayEnabled = true
+			c.GatewayName = ""
+		}, wantErr: "gateway_name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, "http://tempo.monitoring.svc.cluster.local:3200", cfg.GetQueryEndpoint())
+	assert.Equal(t, "tempo.monitoring.svc.cluster.local:4317", cfg.GetOTLPGRPCEndpoint())
+	assert.Equal(t, "http://tempo.monitoring.svc.cluster.local:4318", cfg.GetOTLPHTTPEndpoint())
+
+	cfg.Namespace = "tracing"
+	assert.Equal(t, "http://tempo.tracing.svc.cluster.local:3200", cfg.GetQueryEndpoint())
+}
+
+func TestComponent_Name(t *testing.T) {
+	assert.Equal(t, "tempo", NewComponent(nil, nil).Name())
+}
+
+func TestComponent_Dependencies(t *testing.T) {
+	assert.Equal(t, []string{"storage", "seaweedfs"}, NewComponent(nil, nil).Dependencies())
+}
+
+func TestComponent_Install_NilHelmClient(t *testing.T) {
+	err := NewComponent(nil, nil).Install(context.Background(), component.ComponentConfig{})
+	assert.ErrorContains(t, err, "helm client cannot be nil")
+}
+
+func TestComponent_Status(t *testing.T) {
+	status, err := NewComponent(nil, nil).Status(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+	assert.Contains(t, status.Message, "not initialized")
+
+	status, err = NewComponent(&mockHelmClient{}, nil).Status(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+	assert.Contains(t, status.Message, "not found")
+
+	status, err = NewComponent(&mockHelmClient{
+		listReleases: []helm.Release{{Name: "tempo", Namespace: "monitoring", Status: "deployed", AppVersion: "2.6.0"}},
+	}, nil).Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "2.6.0", status.Version)
+}
+
+// mockHelmClient is a mock implementation of HelmClient for testing
+type mockHelmClient struct {
+	addRepoErr      error
+	installErr      error
+	listReleases    []helm.Release
+	reposAdded      []helm.RepoAddOptions
+	chartsInstalled []helm.InstallOptions
+	upgradeCalls    []helm.UpgradeOptions
+}
+
+func (m *mockHelmClient) AddRepo(ctx context.Context, opts helm.RepoAddOptions) error {
+	m.reposAdded = append(m.reposAdded, opts)
+	return m.addRepoErr
+}
+
+func (m *mockHelmClient) Install(ctx context.Context, opts helm.InstallOptions) error {
+	m.chartsInstalled = append(m.chartsInstalled, opts)
+	return m.installErr
+}
+
+func (m *mockHelmClient) Upgrade(ctx context.Context, opts helm.UpgradeOptions) error {
+	m.upgradeCalls = append(m.upgradeCalls, opts)
+	return nil
+}
+
+func (m *mockHelmClient) List(ctx context.Context, namespace string) ([]helm.Release, error) {
+	return m.listReleases, nil
+}
+
+func (m *mockHelmClient) Uninstall(ctx context.Context, opts helm.UninstallOptions) error {
+	return nil
+}
+
+// mockK8sClient is a mock implementation of K8sClient for testing
+type mockK8sClient struct {
+	serviceMonitorCRDExists bool
+	manifests               []string
+	jobsDeleted             []string
+	jobsWaited              []string
+	jobErr                  error
+	dynamicClient           dynamic.Interface
+}
+
+func (m *mockK8sClient) GetPods(ctx context.Context, namespace string) ([]*k8s.Pod, error) {
+	return nil, nil
+}
+
+func (m *mockK8sClient) ServiceMonitorCRDExists(ctx context.Context) (bool, error) {
+	return m.serviceMonitorCRDExists, nil
+}
+
+func (m *mockK8sClient) ApplyManifest(ctx context.Context, manifest string) error {
+	m.manifests = append(m.manifests, manifest)
+	return nil
+}
+
+func (m *mockK8sClient) DeleteJob(ctx context.Context, namespace, name string) error {
+	m.jobsDeleted = append(m.jobsDeleted, namespace+"/"+name)
+	return nil
+}
+
+func (m *mockK8sClient) WaitForJobComplete(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	m.jobsWaited = append(m.jobsWaited, namespace+"/"+name)
+	return m.jobErr
+}
+
+func (m *mockK8sClient) DynamicClient() dynamic.Interface {
+	return m.dynamicClient
+}
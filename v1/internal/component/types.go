@@ -67,10 +67,12 @@ func (c ComponentConfig) GetInt(key string) (int, bool) {
 	if !ok {
 		return 0, false
 	}
-	// Handle both int and float64 (JSON unmarshaling produces float64)
+	// Handle int, int64 and float64 (JSON unmarshaling produces float64)
 	switch v := val.(type) {
 	case int:
 		return v, true
+	case int64:
+		return int(v), true
 	case float64:
 		return int(v), true
 	default:
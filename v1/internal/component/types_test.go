@@ -47,6 +47,7 @@ func TestComponentConfig_GetString(t *testing.T) {
 func TestComponentConfig_GetInt(t *testing.T) {
 	cfg := ComponentConfig{
 		"int":    42,
+		"int64":  int64(7),
 		"float":  123.0,
 		"string": "hello",
 	}
@@ -56,6 +57,11 @@ func TestComponentConfig_GetInt(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, 42, val)
 
+	// Valid int64
+	val, ok = cfg.GetInt("int64")
+	assert.True(t, ok)
+	assert.Equal(t, 7, val)
+
 	// Valid float64 (from JSON unmarshaling)
 	val, ok = cfg.GetInt("float")
 	assert.True(t, ok)
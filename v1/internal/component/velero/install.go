@@ -102,9 +102,26 @@ func Install(ctx context.Context, helmClient HelmClient, k8sClient K8sClient, cf
 	if cfg.ScheduleCron != "" {
 		fmt.Printf("  Backup schedule: %s (%s)\n", cfg.ScheduleName, cfg.ScheduleCron)
 	}
+	if cfg.Offsite != nil {
+		fmt.Printf("  Off-site replication: %s/%s (%s, encrypted)\n", cfg.Offsite.Endpoint, cfg.Offsite.Bucket, cfg.Offsite.Schedule)
+	}
 	return nil
 }
 
+// Charts returns the Helm charts Install uses for Velero
+func Charts(cfg *Config) []helm.ChartSource {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return []helm.ChartSource{{
+		RepoName: veleroRepoName,
+		RepoURL:  veleroRepoURL,
+		Chart:    veleroChart,
+		Version:  cfg.Version,
+		Values:   buildHelmValues(cfg),
+	}}
+}
+
 // buildHelmValues constructs Helm values for Velero installation
 func buildHelmValues(cfg *Config) map[string]interface{} {
 	values := make(map[string]interface{})
@@ -134,10 +151,14 @@ func buildHelmValues(cfg *Config) map[string]interface{} {
 	credentialsData := buildCredentialsData(cfg)
 
 	// Configuration settings
+	locations := []map[string]interface{}{
+		buildBackupStorageLocation(cfg),
+	}
+	if cfg.Offsite != nil {
+		locations = append(locations, buildOffsiteBSL(cfg))
+	}
 	configuration := map[string]interface{}{
-		"backupStorageLocation": []map[string]interface{}{
-			buildBackupStorageLocation(cfg),
-		},
+		"backupStorageLocation":        locations,
 		"defaultBackupStorageLocation": cfg.GetBackupStorageLocationName(),
 	}
 
@@ -217,6 +238,12 @@ func buildHelmValues(cfg *Config) map[string]interface{} {
 		values["schedules"] = buildSchedules(cfg)
 	}
 
+	// Off-site replication and the gateway the offsite location reads from
+	if cfg.Offsite != nil {
+		extraObjects, _ := values["extraObjects"].([]interface{})
+		values["extraObjects"] = append(extraObjects, buildOffsiteObjects(cfg)...)
+	}
+
 	return values
 }
 
@@ -0,0 +1,69 @@
+package velero
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+)
+
+// LocalRetention is how many of the backups 'foundry backup local' stores on
+// this machine are kept: the newest backup of each of the last KeepDaily
+// days, KeepWeekly weeks and KeepMonthly months, and no more than MaxSize
+// in total. The newest completed backup is always kept.
+type LocalRetention struct {
+	KeepDaily   int `json:"keep_daily" yaml:"keep_daily"`
+	KeepWeekly  int `json:"keep_weekly" yaml:"keep_weekly"`
+	KeepMonthly int `json:"keep_monthly" yaml:"keep_monthly"`
+
+	// MaxSize caps the local store, as a quantity like 200Gi (default: no cap)
+	MaxSize string `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+}
+
+// DefaultLocalRetention is what 'foundry backup local prune' applies when
+// the stack config has no local_retention section
+func DefaultLocalRetention() *LocalRetention {
+	return &LocalRetention{KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 6}
+}
+
+// MaxSizeBytes returns the size cap in bytes, or 0 when there is none
+func (r *LocalRetention) MaxSizeBytes() (int64, error) {
+	if r.MaxSize == "" {
+		return 0, nil
+	}
+	quantity, err := resource.ParseQuantity(r.MaxSize)
+	if err != nil || quantity.Sign() <= 0 {
+		return 0, fmt.Errorf("local_retention max_size %q is not a positive size", r.MaxSize)
+	}
+	return quantity.Value(), nil
+}
+
+// Validate checks the retention policy
+func (r *LocalRetention) Validate() error {
+	if r.KeepDaily < 0 || r.KeepWeekly < 0 || r.KeepMonthly < 0 {
+		return fmt.Errorf("local_retention keep counts cannot be negative")
+	}
+	_, err := r.MaxSizeBytes()
+	return err
+}
+
+// parseLocalRetention reads the local_retention section of the component
+// config
+func parseLocalRetention(raw interface{}) *LocalRetention {
+	switch v := raw.(type) {
+	case *LocalRetention:
+		return v
+	case LocalRetention:
+		return &v
+	case map[string]interface{}:
+		cfg := component.ComponentConfig(v)
+		retention := &LocalRetention{}
+		retention.KeepDaily, _ = cfg.GetInt("keep_daily")
+		retention.KeepWeekly, _ = cfg.GetInt("keep_weekly")
+		retention.KeepMonthly, _ = cfg.GetInt("keep_monthly")
+		retention.MaxSize, _ = cfg.GetString("max_size")
+		return retention
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package velero
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig_LocalRetention(t *testing.T) {
+	config, err := ParseConfig(component.ComponentConfig{
+		"local_retention": map[string]interface{}{
+			"keep_daily":   7,
+			"keep_weekly":  4,
+			"keep_monthly": 12,
+			"max_size":     "200Gi",
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, config.LocalRetention)
+	assert.Equal(t, &LocalRetention{KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 12, MaxSize: "200Gi"}, config.LocalRetention)
+
+	maxBytes, err := config.LocalRetention.MaxSizeBytes()
+	require.NoError(t, err)
+	assert.Equal(t, int64(200)<<30, maxBytes)
+}
+
+func TestLocalRetention_Validate(t *testing.T) {
+	assert.NoError(t, DefaultLocalRetention().Validate())
+	assert.EqualError(t, (&LocalRetention{KeepDaily: -1}).Validate(), "local_retention keep counts cannot be negative")
+	assert.EqualError(t, (&LocalRetention{MaxSize: "lots"}).Validate(), `local_retention max_size "lots" is not a positive size`)
+	assert.EqualError(t, (&LocalRetention{MaxSize: "0"}).Validate(), `local_retention max_size "0" is not a positive size`)
+
+	maxBytes, err := (&LocalRetention{}).MaxSizeBytes()
+	require.NoError(t, err)
+	assert.Zero(t, maxBytes)
+}
@@ -0,0 +1,351 @@
+package velero
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
+)
+
+const (
+	// OffsiteBSLName is the read-only BackupStorageLocation that serves the
+	// off-site copy
+	OffsiteBSLName = "offsite"
+
+	// OffsiteReplicationName is the CronJob that copies the backup bucket
+	// off-site
+	OffsiteReplicationName = "velero-offsite-replicate"
+
+	// OffsiteSecretPath is where the off-site credentials live in OpenBAO
+	// unless the config points elsewhere
+	OffsiteSecretPath = "velero/offsite"
+
+	// DefaultOffsiteImage is the rclone image that replicates and serves
+	// the off-site copy
+	DefaultOffsiteImage = "rclone/rclone:1.68.2"
+
+	// DefaultOffsiteSchedule replicates hourly; a run with nothing new to
+	// copy only lists the buckets
+	DefaultOffsiteSchedule = "15 * * * *"
+
+	offsiteGatewayName = "velero-offsite-gateway"
+	offsiteSecretName  = "velero-offsite"
+	offsiteGatewayPort = 8080
+
+	// offsiteDir is the directory in the encrypted remote the bucket is
+	// copied to; the gateway serves it as a bucket of the same name
+	offsiteDir = "velero"
+)
+
+// OffsiteConfig is a second, S3-compatible target the backup bucket is
+// replicated to. Objects are encrypted by rclone before they leave the
+// cluster, names included, so the off-site provider only sees ciphertext.
+type OffsiteConfig struct {
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	Bucket   string `json:"bucket" yaml:"bucket"`
+	Region   string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// AccessKey, SecretKey and EncryptionPassword default to the
+	// access_key, secret_key and encryption_password keys at
+	// velero/offsite in OpenBAO
+	AccessKey          string `json:"access_key,omitempty" yaml:"access_key,omitempty"`
+	SecretKey          string `json:"secret_key,omitempty" yaml:"secret_key,omitempty"`
+	EncryptionPassword string `json:"encryption_password,omitempty" yaml:"encryption_password,omitempty"`
+
+	// EncryptionSalt is rclone crypt's optional second password
+	EncryptionSalt string `json:"encryption_salt,omitempty" yaml:"encryption_salt,omitempty"`
+
+	// Schedule is the replication cron expression (default: hourly)
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+
+	ForcePathStyle        bool   `json:"force_path_style" yaml:"force_path_style"`
+	InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify,omitempty" yaml:"insecure_skip_tls_verify,omitempty"`
+	Image                 string `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
+// DefaultOffsiteRefs points the credentials the offsite section leaves out
+// at OpenBAO, so they resolve with the section's other secret references
+func DefaultOffsiteRefs(offsite map[string]interface{}) {
+	for _, key := range []string{"access_key", "secret_key", "encryption_password"} {
+		if s, _ := offsite[key].(string); s == "" {
+			offsite[key] = fmt.Sprintf("${secret:%s:%s}", OffsiteSecretPath, key)
+		}
+	}
+}
+
+// parseOffsite reads the offsite section of the component config
+func parseOffsite(raw interface{}) *OffsiteConfig {
+	switch v := raw.(type) {
+	case *OffsiteConfig:
+		return v
+	case OffsiteConfig:
+		return &v
+	case map[string]interface{}:
+		cfg := component.ComponentConfig(v)
+		offsite := &OffsiteConfig{ForcePathStyle: true}
+		offsite.Endpoint, _ = cfg.GetString("endpoint")
+		offsite.Bucket, _ = cfg.GetString("bucket")
+		offsite.Region, _ = cfg.GetString("region")
+		offsite.AccessKey, _ = cfg.GetString("access_key")
+		offsite.SecretKey, _ = cfg.GetString("secret_key")
+		offsite.EncryptionPassword, _ = cfg.GetString("encryption_password")
+		offsite.EncryptionSalt, _ = cfg.GetString("encryption_salt")
+		offsite.Schedule, _ = cfg.GetString("schedule")
+		offsite.InsecureSkipTLSVerify, _ = cfg.GetBool("insecure_skip_tls_verify")
+		offsite.Image, _ = cfg.GetString("image")
+		if b, ok := cfg.GetBool("force_path_style"); ok {
+			offsite.ForcePathStyle = b
+		}
+		if offsite.Region == "" {
+			offsite.Region = "us-east-1"
+		}
+		if offsite.Schedule == "" {
+			offsite.Schedule = DefaultOffsiteSchedule
+		}
+		if offsite.Image == "" {
+			offsite.Image = DefaultOffsiteImage
+		}
+		return offsite
+	}
+	return nil
+}
+
+// Validate checks the offsite config
+func (o *OffsiteConfig) Validate() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("offsite endpoint is required")
+	}
+	if o.Bucket == "" {
+		return fmt.Errorf("offsite bucket is required")
+	}
+	if len(strings.Fields(o.Schedule)) != 5 {
+		return fmt.Errorf("offsite schedule %q is not a cron expression", o.Schedule)
+	}
+	for _, field := range []struct{ key, value string }{
+		{"access_key", o.AccessKey},
+		{"secret_key", o.SecretKey},
+		{"encryption_password", o.EncryptionPassword},
+	} {
+		if field.value == "" {
+			return fmt.Errorf("offsite %s is required", field.key)
+		}
+		if secrets.IsSecretRef(field.value) {
+			return fmt.Errorf("offsite %s could not be resolved from %s", field.key, field.value)
+		}
+	}
+	return nil
+}
+
+// OffsiteEnv is the rclone configuration of the replication job and the
+// gateway, without the credentials. Three remotes are configured: primary
+// (the backup bucket), offsite (the off-site bucket) and encrypted, which
+// encrypts into offsite.
+func (c *Config) OffsiteEnv() map[string]string {
+	o := c.Offsite
+	primaryProvider := "Other"
+	if c.Provider == ProviderAWS {
+		primaryProvider = "AWS"
+	}
+	env := map[string]string{
+		"PRIMARY_BUCKET":                         c.S3Bucket,
+		"RCLONE_CONFIG_PRIMARY_TYPE":             "s3",
+		"RCLONE_CONFIG_PRIMARY_PROVIDER":         primaryProvider,
+		"RCLONE_CONFIG_PRIMARY_REGION":           c.S3Region,
+		"RCLONE_CONFIG_PRIMARY_FORCE_PATH_STYLE": fmt.Sprintf("%t", c.S3ForcePathStyle),
+		"RCLONE_CONFIG_OFFSITE_TYPE":             "s3",
+		"RCLONE_CONFIG_OFFSITE_PROVIDER":         "Other",
+		"RCLONE_CONFIG_OFFSITE_ENDPOINT":         o.Endpoint,
+		"RCLONE_CONFIG_OFFSITE_REGION":           o.Region,
+		"RCLONE_CONFIG_OFFSITE_FORCE_PATH_STYLE": fmt.Sprintf("%t", o.ForcePathStyle),
+		"RCLONE_CONFIG_OFFSITE_NO_CHECK_BUCKET":  "true",
+		"RCLONE_CONFIG_ENCRYPTED_TYPE":           "crypt",
+		"RCLONE_CONFIG_ENCRYPTED_REMOTE":         "offsite:" + o.Bucket,
+	}
+	if c.Provider == ProviderS3 {
+		env["RCLONE_CONFIG_PRIMARY_ENDPOINT"] = c.S3Endpoint
+	}
+	if o.InsecureSkipTLSVerify {
+		env["RCLONE_NO_CHECK_CERTIFICATE"] = "true"
+	}
+	return env
+}
+
+// OffsiteSecretEnv is the credentials half of the rclone configuration
+func (c *Config) OffsiteSecretEnv() map[string]string {
+	o := c.Offsite
+	env := map[string]string{
+		"RCLONE_CONFIG_PRIMARY_ACCESS_KEY_ID":     c.S3AccessKey,
+		"RCLONE_CONFIG_PRIMARY_SECRET_ACCESS_KEY": c.S3SecretKey,
+		"RCLONE_CONFIG_OFFSITE_ACCESS_KEY_ID":     o.AccessKey,
+		"RCLONE_CONFIG_OFFSITE_SECRET_ACCESS_KEY": o.SecretKey,
+		"ENCRYPTION_PASSWORD":                     o.EncryptionPassword,
+	}
+	if o.EncryptionSalt != "" {
+		env["ENCRYPTION_SALT"] = o.EncryptionSalt
+	}
+	return env
+}
+
+// offsitePreamble hands the encryption passwords to rclone, which wants
+// them obscured
+const offsitePreamble = `set -eu
+RCLONE_CONFIG_ENCRYPTED_PASSWORD="$(rclone obscure "$ENCRYPTION_PASSWORD")"
+export RCLONE_CONFIG_ENCRYPTED_PASSWORD
+if [ -n "${ENCRYPTION_SALT:-}" ]; then
+  RCLONE_CONFIG_ENCRYPTED_PASSWORD2="$(rclone obscure "$ENCRYPTION_SALT")"
+  export RCLONE_CONFIG_ENCRYPTED_PASSWORD2
+fi
+`
+
+// OffsiteReplicationScript mirrors the backup bucket into the encrypted
+// remote. Sync deletes off-site what was pruned from the bucket, so it
+// refuses to run when the bucket has no backups: a wiped primary store
+// must not wipe the off-site copy with it.
+const OffsiteReplicationScript = offsitePreamble + `if [ -z "$(rclone lsf "primary:$PRIMARY_BUCKET/backups/" 2>/dev/null)" ]; then
+  echo "no backups in primary:$PRIMARY_BUCKET; refusing to sync" >&2
+  exit 1
+fi
+exec rclone sync "primary:$PRIMARY_BUCKET" "encrypted:` + offsiteDir + `" --fast-list --stats-one-line -v
+`
+
+// offsiteGatewayScript serves the decrypted off-site copy as S3 inside the
+// cluster, for the read-only offsite BackupStorageLocation. It takes the
+// primary bucket's keys, which Velero already has.
+const offsiteGatewayScript = offsitePreamble + `exec rclone serve s3 encrypted: --addr :8080 \
+  --auth-key "$RCLONE_CONFIG_PRIMARY_ACCESS_KEY_ID,$RCLONE_CONFIG_PRIMARY_SECRET_ACCESS_KEY" \
+  --vfs-cache-mode writes --cache-dir /cache
+`
+
+// GetOffsiteGatewayEndpoint returns the in-cluster URL of the gateway
+func (c *Config) GetOffsiteGatewayEndpoint() string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", offsiteGatewayName, c.Namespace, offsiteGatewayPort)
+}
+
+// buildOffsiteBSL builds the read-only location Velero reads the off-site
+// copy from. Velero syncs the backups in it into the cluster, so backups
+// that only exist off-site can be restored like any other.
+func buildOffsiteBSL(cfg *Config) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       OffsiteBSLName,
+		"provider":   "aws",
+		"bucket":     offsiteDir,
+		"default":    false,
+		"accessMode": "ReadOnly",
+		"config": map[string]interface{}{
+			"region":           "us-east-1",
+			"s3ForcePathStyle": true,
+			"s3Url":            cfg.GetOffsiteGatewayEndpoint(),
+		},
+	}
+}
+
+// buildOffsiteObjects builds the replication CronJob, the gateway and the
+// Secret they share
+func buildOffsiteObjects(cfg *Config) []interface{} {
+	o := cfg.Offsite
+	labels := map[string]interface{}{
+		"app.kubernetes.io/name":       "velero-offsite",
+		"app.kubernetes.io/managed-by": "foundry",
+	}
+	env := []interface{}{}
+	offsiteEnv := cfg.OffsiteEnv()
+	for _, name := range sortedKeys(offsiteEnv) {
+		env = append(env, map[string]interface{}{"name": name, "value": offsiteEnv[name]})
+	}
+	secretData := map[string]interface{}{}
+	for name, value := range cfg.OffsiteSecretEnv() {
+		secretData[name] = value
+	}
+	container := func(name, script string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"image":   o.Image,
+			"command": []interface{}{"sh", "-c", script},
+			"env":     env,
+			"envFrom": []interface{}{
+				map[string]interface{}{"secretRef": map[string]interface{}{"name": offsiteSecretName}},
+			},
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "64Mi"},
+			},
+		}
+	}
+
+	gatewayLabels := map[string]interface{}{"app.kubernetes.io/name": offsiteGatewayName}
+	gateway := container("gateway", offsiteGatewayScript)
+	gateway["ports"] = []interface{}{map[string]interface{}{"name": "s3", "containerPort": offsiteGatewayPort}}
+	gateway["volumeMounts"] = []interface{}{map[string]interface{}{"name": "cache", "mountPath": "/cache"}}
+
+	return []interface{}{
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": offsiteSecretName, "namespace": cfg.Namespace, "labels": labels},
+			"type":       "Opaque",
+			"stringData": secretData,
+		},
+		map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata":   map[string]interface{}{"name": OffsiteReplicationName, "namespace": cfg.Namespace, "labels": labels},
+			"spec": map[string]interface{}{
+				"schedule":                   o.Schedule,
+				"concurrencyPolicy":          "Forbid",
+				"successfulJobsHistoryLimit": 3,
+				"failedJobsHistoryLimit":     3,
+				"jobTemplate": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"backoffLimit": 1,
+						"template": map[string]interface{}{
+							"metadata": map[string]interface{}{"labels": labels},
+							"spec": map[string]interface{}{
+								"restartPolicy": "Never",
+								"containers":    []interface{}{container("replicate", OffsiteReplicationScript)},
+							},
+						},
+					},
+				},
+			},
+		},
+		map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": offsiteGatewayName, "namespace": cfg.Namespace, "labels": labels},
+			"spec": map[string]interface{}{
+				"replicas": 1,
+				"selector": map[string]interface{}{"matchLabels": gatewayLabels},
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": gatewayLabels},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{gateway},
+						"volumes": []interface{}{
+							map[string]interface{}{"name": "cache", "emptyDir": map[string]interface{}{}},
+						},
+					},
+				},
+			},
+		},
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": offsiteGatewayName, "namespace": cfg.Namespace, "labels": labels},
+			"spec": map[string]interface{}{
+				"selector": gatewayLabels,
+				"ports": []interface{}{
+					map[string]interface{}{"name": "s3", "port": offsiteGatewayPort, "targetPort": "s3"},
+				},
+			},
+		},
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,136 @@
+package velero
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOffsiteConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.S3AccessKey = "primary-key"
+	cfg.S3SecretKey = "primary-secret"
+	cfg.Offsite = parseOffsite(map[string]interface{}{
+		"endpoint":            "https://s3.offsite.example.com",
+		"bucket":              "cluster-backups",
+		"access_key":          "offsite-key",
+		"secret_key":          "offsite-secret",
+		"encryption_password": "hunter2",
+	})
+	return cfg
+}
+
+func TestParseConfig_Offsite(t *testing.T) {
+	config, err := ParseConfig(component.ComponentConfig{
+		"offsite": map[string]interface{}{
+			"endpoint":            "https://s3.offsite.example.com",
+			"bucket":              "cluster-backups",
+			"access_key":          "key",
+			"secret_key":          "secret",
+			"encryption_password": "hunter2",
+			"force_path_style":    false,
+			"schedule":            "0 */6 * * *",
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, config.Offsite)
+
+	assert.Equal(t, "us-east-1", config.Offsite.Region)
+	assert.Equal(t, "0 */6 * * *", config.Offsite.Schedule)
+	assert.Equal(t, DefaultOffsiteImage, config.Offsite.Image)
+	assert.False(t, config.Offsite.ForcePathStyle)
+}
+
+func TestOffsiteConfig_Validate(t *testing.T) {
+	valid := func() *OffsiteConfig { return testOffsiteConfig().Offsite }
+
+	assert.NoError(t, valid().Validate())
+
+	o := valid()
+	o.Bucket = ""
+	assert.EqualError(t, o.Validate(), "offsite bucket is required")
+
+	o = valid()
+	o.Schedule = "hourly"
+	assert.EqualError(t, o.Validate(), `offsite schedule "hourly" is not a cron expression`)
+
+	o = valid()
+	o.EncryptionPassword = ""
+	assert.EqualError(t, o.Validate(), "offsite encryption_password is required")
+
+	o = valid()
+	o.SecretKey = "${secret:velero/offsite:secret_key}"
+	assert.EqualError(t, o.Validate(), "offsite secret_key could not be resolved from ${secret:velero/offsite:secret_key}")
+}
+
+func TestDefaultOffsiteRefs(t *testing.T) {
+	offsite := map[string]interface{}{"access_key": "inline"}
+	DefaultOffsiteRefs(offsite)
+
+	assert.Equal(t, "inline", offsite["access_key"])
+	assert.Equal(t, "${secret:velero/offsite:secret_key}", offsite["secret_key"])
+	assert.Equal(t, "${secret:velero/offsite:encryption_password}", offsite["encryption_password"])
+}
+
+func TestOffsiteEnv(t *testing.T) {
+	cfg := testOffsiteConfig()
+
+	env := cfg.OffsiteEnv()
+	assert.Equal(t, "velero", env["PRIMARY_BUCKET"])
+	assert.Equal(t, cfg.S3Endpoint, env["RCLONE_CONFIG_PRIMARY_ENDPOINT"])
+	assert.Equal(t, "https://s3.offsite.example.com", env["RCLONE_CONFIG_OFFSITE_ENDPOINT"])
+	assert.Equal(t, "crypt", env["RCLONE_CONFIG_ENCRYPTED_TYPE"])
+	assert.Equal(t, "offsite:cluster-backups", env["RCLONE_CONFIG_ENCRYPTED_REMOTE"])
+	assert.NotContains(t, env, "RCLONE_NO_CHECK_CERTIFICATE")
+	for _, value := range env {
+		assert.NotContains(t, []string{"primary-secret", "offsite-secret", "hunter2"}, value, "credentials belong in the secret env")
+	}
+
+	secretEnv := cfg.OffsiteSecretEnv()
+	assert.Equal(t, "primary-secret", secretEnv["RCLONE_CONFIG_PRIMARY_SECRET_ACCESS_KEY"])
+	assert.Equal(t, "offsite-secret", secretEnv["RCLONE_CONFIG_OFFSITE_SECRET_ACCESS_KEY"])
+	assert.Equal(t, "hunter2", secretEnv["ENCRYPTION_PASSWORD"])
+	assert.NotContains(t, secretEnv, "ENCRYPTION_SALT")
+}
+
+func TestBuildHelmValues_WithOffsite(t *testing.T) {
+	values := buildHelmValues(testOffsiteConfig())
+
+	configuration := values["configuration"].(map[string]interface{})
+	bsl := configuration["backupStorageLocation"].([]map[string]interface{})
+	require.Len(t, bsl, 2)
+	assert.Equal(t, "default", configuration["defaultBackupStorageLocation"])
+	assert.Equal(t, OffsiteBSLName, bsl[1]["name"])
+	assert.Equal(t, "ReadOnly", bsl[1]["accessMode"])
+	assert.Equal(t, "http://velero-offsite-gateway.velero.svc.cluster.local:8080", bsl[1]["config"].(map[string]interface{})["s3Url"])
+
+	extraObjects := values["extraObjects"].([]interface{})
+	kinds := map[string]map[string]interface{}{}
+	for _, obj := range extraObjects {
+		o := obj.(map[string]interface{})
+		kinds[o["kind"].(string)] = o
+	}
+	require.Contains(t, kinds, "CronJob")
+	require.Contains(t, kinds, "Deployment")
+	require.Contains(t, kinds, "Service")
+	require.Contains(t, kinds, "Secret")
+
+	cronJob := kinds["CronJob"]
+	assert.Equal(t, OffsiteReplicationName, cronJob["metadata"].(map[string]interface{})["name"])
+	spec := cronJob["spec"].(map[string]interface{})
+	assert.Equal(t, DefaultOffsiteSchedule, spec["schedule"])
+	assert.Equal(t, "Forbid", spec["concurrencyPolicy"])
+
+	assert.Equal(t, "hunter2", kinds["Secret"]["stringData"].(map[string]interface{})["ENCRYPTION_PASSWORD"])
+}
+
+func TestBuildHelmValues_WithoutOffsite(t *testing.T) {
+	cfg := DefaultConfig()
+	values := buildHelmValues(cfg)
+
+	bsl := values["configuration"].(map[string]interface{})["backupStorageLocation"].([]map[string]interface{})
+	assert.Len(t, bsl, 1)
+	assert.NotContains(t, values, "extraObjects")
+}
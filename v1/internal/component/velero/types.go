@@ -84,6 +84,16 @@ type Config struct {
 	// CSISnapshotTimeout is the timeout for CSI volume snapshots
 	CSISnapshotTimeout string `json:"csi_snapshot_timeout" yaml:"csi_snapshot_timeout"`
 
+	// Offsite replicates the backup bucket, encrypted, to a second S3 target
+	Offsite *OffsiteConfig `json:"offsite,omitempty" yaml:"offsite,omitempty"`
+
+	// LocalRetention prunes the backups 'foundry backup local' stores on
+	// this machine
+	LocalRetention *LocalRetention `json:"local_retention,omitempty" yaml:"local_retention,omitempty"`
+
+	// Verify configures restore verification of backups (foundry backup verify)
+	Verify *VerifyConfig `json:"verify,omitempty" yaml:"verify,omitempty"`
+
 	// ResourceRequests specifies resource requests for Velero server
 	ResourceRequests map[string]string `json:"resource_requests" yaml:"resource_requests"`
 
@@ -304,6 +314,10 @@ func ParseConfig(cfg component.ComponentConfig) (*Config, error) {
 		config.CSISnapshotTimeout = csiSnapshotTimeout
 	}
 
+	config.Offsite = parseOffsite(cfg["offsite"])
+	config.LocalRetention = parseLocalRetention(cfg["local_retention"])
+	config.Verify = parseVerify(cfg["verify"])
+
 	if resourceRequests, ok := cfg.GetMap("resource_requests"); ok {
 		config.ResourceRequests = make(map[string]string)
 		for k, v := range resourceRequests {
@@ -356,6 +370,24 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("backup_retention_days cannot be negative")
 	}
 
+	if c.Offsite != nil {
+		if err := c.Offsite.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.LocalRetention != nil {
+		if err := c.LocalRetention.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Verify != nil {
+		if err := c.Verify.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
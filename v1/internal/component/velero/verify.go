@@ -0,0 +1,197 @@
+package velero
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+)
+
+// DefaultVerifyTimeout bounds one backup verification: the restore plus
+// waiting for the restored workloads and running the checks
+const DefaultVerifyTimeout = 30 * time.Minute
+
+// VerifyConfig configures restore verification of backups. Verification
+// restores a backup into throwaway copies of its namespaces, waits for the
+// workloads to come up, runs the checks and deletes the copies again.
+type VerifyConfig struct {
+	// Schedule makes the manager verify the latest completed backup
+	// periodically: "daily", "weekly" or a duration such as 72h. Empty
+	// means verification only runs on demand.
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+
+	// BackupSchedule limits scheduled verification to backups made by this
+	// Velero schedule (default: the newest completed backup of any kind)
+	BackupSchedule string `json:"backup_schedule,omitempty" yaml:"backup_schedule,omitempty"`
+
+	// Namespaces limits verification to these namespaces of the backup
+	// (default: all of them)
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// Timeout bounds one verification (default: 30m)
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Checks run against the restored copies once their workloads are ready
+	Checks []VerifyCheck `json:"checks,omitempty" yaml:"checks,omitempty"`
+}
+
+// VerifyCheck is one check of a restored namespace. Exactly one of HTTP and
+// Exec is set.
+type VerifyCheck struct {
+	Name string `json:"name" yaml:"name"`
+
+	// Namespace is the original namespace; the check runs in its restored copy
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	HTTP *HTTPCheck `json:"http,omitempty" yaml:"http,omitempty"`
+	Exec *ExecCheck `json:"exec,omitempty" yaml:"exec,omitempty"`
+}
+
+// HTTPCheck requests a path from a restored Service through the API server's
+// service proxy
+type HTTPCheck struct {
+	Service string `json:"service" yaml:"service"`
+	Port    int    `json:"port" yaml:"port"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// ExpectStatus is the status code that passes (default: any 2xx)
+	ExpectStatus int `json:"expect_status,omitempty" yaml:"expect_status,omitempty"`
+}
+
+// ExecCheck runs a command in a restored pod, like kubectl exec. It passes
+// when the command exits 0.
+type ExecCheck struct {
+	// Selector is the label selector of the pod to run in, e.g. app=postgres
+	Selector string `json:"selector" yaml:"selector"`
+
+	// Container defaults to the pod's first container
+	Container string `json:"container,omitempty" yaml:"container,omitempty"`
+
+	Command []string `json:"command" yaml:"command"`
+}
+
+// Interval returns how often the manager verifies a backup, or 0 when
+// verification isn't scheduled
+func (c *VerifyConfig) Interval() (time.Duration, error) {
+	switch c.Schedule {
+	case "":
+		return 0, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	case "weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(c.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("verify schedule %q is not daily, weekly or a duration", c.Schedule)
+	}
+	if d < time.Hour {
+		return 0, fmt.Errorf("verify schedule %q is shorter than an hour", c.Schedule)
+	}
+	return d, nil
+}
+
+// TimeoutDuration returns the verification timeout
+func (c *VerifyConfig) TimeoutDuration() (time.Duration, error) {
+	if c.Timeout == "" {
+		return DefaultVerifyTimeout, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("verify timeout %q is not a positive duration", c.Timeout)
+	}
+	return d, nil
+}
+
+// ChecksFor returns the checks of one original namespace
+func (c *VerifyConfig) ChecksFor(namespace string) []VerifyCheck {
+	var checks []VerifyCheck
+	for _, check := range c.Checks {
+		if check.Namespace == namespace {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Validate checks the verify config
+func (c *VerifyConfig) Validate() error {
+	if _, err := c.Interval(); err != nil {
+		return err
+	}
+	if _, err := c.TimeoutDuration(); err != nil {
+		return err
+	}
+	for i, check := range c.Checks {
+		if check.Name == "" {
+			return fmt.Errorf("verify check %d has no name", i+1)
+		}
+		if check.Namespace == "" {
+			return fmt.Errorf("verify check %q has no namespace", check.Name)
+		}
+		if (check.HTTP == nil) == (check.Exec == nil) {
+			return fmt.Errorf("verify check %q needs exactly one of http and exec", check.Name)
+		}
+		if check.HTTP != nil {
+			if check.HTTP.Service == "" || check.HTTP.Port <= 0 {
+				return fmt.Errorf("verify check %q: http needs a service and port", check.Name)
+			}
+			if s := check.HTTP.ExpectStatus; s != 0 && (s < 100 || s > 599) {
+				return fmt.Errorf("verify check %q: expect_status %d is not an HTTP status", check.Name, s)
+			}
+		}
+		if check.Exec != nil {
+			if check.Exec.Selector == "" || len(check.Exec.Command) == 0 {
+				return fmt.Errorf("verify check %q: exec needs a selector and command", check.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// parseVerify reads the verify section of the component config
+func parseVerify(raw interface{}) *VerifyConfig {
+	switch v := raw.(type) {
+	case *VerifyConfig:
+		return v
+	case VerifyConfig:
+		return &v
+	case map[string]interface{}:
+		cfg := component.ComponentConfig(v)
+		verify := &VerifyConfig{}
+		verify.Schedule, _ = cfg.GetString("schedule")
+		verify.BackupSchedule, _ = cfg.GetString("backup_schedule")
+		verify.Namespaces, _ = cfg.GetStringSlice("namespaces")
+		verify.Timeout, _ = cfg.GetString("timeout")
+
+		checks, _ := cfg["checks"].([]interface{})
+		for _, item := range checks {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			entry := component.ComponentConfig(m)
+			check := VerifyCheck{}
+			check.Name, _ = entry.GetString("name")
+			check.Namespace, _ = entry.GetString("namespace")
+			if h, ok := entry.GetMap("http"); ok {
+				http := component.ComponentConfig(h)
+				check.HTTP = &HTTPCheck{}
+				check.HTTP.Service, _ = http.GetString("service")
+				check.HTTP.Port, _ = http.GetInt("port")
+				check.HTTP.Path, _ = http.GetString("path")
+				check.HTTP.ExpectStatus, _ = http.GetInt("expect_status")
+			}
+			if e, ok := entry.GetMap("exec"); ok {
+				exec := component.ComponentConfig(e)
+				check.Exec = &ExecCheck{}
+				check.Exec.Selector, _ = exec.GetString("selector")
+				check.Exec.Container, _ = exec.GetString("container")
+				check.Exec.Command, _ = exec.GetStringSlice("command")
+			}
+			verify.Checks = append(verify.Checks, check)
+		}
+		return verify
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package velero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig_Verify(t *testing.T) {
+	cfg := component.ComponentConfig{
+		"verify": map[string]interface{}{
+			"schedule":        "weekly",
+			"backup_schedule": "daily-backup",
+			"namespaces":      []interface{}{"app"},
+			"timeout":         "45m",
+			"checks": []interface{}{
+				map[string]interface{}{
+					"name":      "web",
+					"namespace": "app",
+					"http":      map[string]interface{}{"service": "web", "port": 8080, "path": "/healthz"},
+				},
+				map[string]interface{}{
+					"name":      "db",
+					"namespace": "app",
+					"exec": map[string]interface{}{
+						"selector": "app=postgres",
+						"command":  []interface{}{"pg_isready", "-U", "app"},
+					},
+				},
+			},
+		},
+	}
+
+	config, err := ParseConfig(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, config.Verify)
+
+	verify := config.Verify
+	assert.Equal(t, "daily-backup", verify.BackupSchedule)
+	assert.Equal(t, []string{"app"}, verify.Namespaces)
+	require.Len(t, verify.Checks, 2)
+	assert.Equal(t, &HTTPCheck{Service: "web", Port: 8080, Path: "/healthz"}, verify.Checks[0].HTTP)
+	assert.Equal(t, []string{"pg_isready", "-U", "app"}, verify.Checks[1].Exec.Command)
+
+	interval, err := verify.Interval()
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, interval)
+	timeout, err := verify.TimeoutDuration()
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Minute, timeout)
+
+	assert.Len(t, verify.ChecksFor("app"), 2)
+	assert.Empty(t, verify.ChecksFor("other"))
+}
+
+func TestParseConfig_NoVerify(t *testing.T) {
+	config, err := ParseConfig(component.ComponentConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, config.Verify)
+}
+
+func TestVerifyConfig_Interval(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{schedule: "", want: 0},
+		{schedule: "daily", want: 24 * time.Hour},
+		{schedule: "72h", want: 72 * time.Hour},
+		{schedule: "10m", wantErr: true},
+		{schedule: "monthly", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			got, err := (&VerifyConfig{Schedule: tt.schedule}).Interval()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVerifyConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   VerifyCheck
+		wantErr string
+	}{
+		{
+			name:    "no namespace",
+			check:   VerifyCheck{Name: "web", HTTP: &HTTPCheck{Service: "web", Port: 80}},
+			wantErr: "no namespace",
+		},
+		{
+			name:    "neither kind",
+			check:   VerifyCheck{Name: "web", Namespace: "app"},
+			wantErr: "exactly one of http and exec",
+		},
+		{
+			name: "both kinds",
+			check: VerifyCheck{Name: "web", Namespace: "app",
+				HTTP: &HTTPCheck{Service: "web", Port: 80},
+				Exec: &ExecCheck{Selector: "app=web", Command: []string{"true"}}},
+			wantErr: "exactly one of http and exec",
+		},
+		{
+			name:    "http without port",
+			check:   VerifyCheck{Name: "web", Namespace: "app", HTTP: &HTTPCheck{Service: "web"}},
+			wantErr: "needs a service and port",
+		},
+		{
+			name:    "bad status",
+			check:   VerifyCheck{Name: "web", Namespace: "app", HTTP: &HTTPCheck{Service: "web", Port: 80, ExpectStatus: 42}},
+			wantErr: "not an HTTP status",
+		},
+		{
+			name:    "exec without command",
+			check:   VerifyCheck{Name: "db", Namespace: "app", Exec: &ExecCheck{Selector: "app=db"}},
+			wantErr: "needs a selector and command",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&VerifyConfig{Checks: []VerifyCheck{tt.check}}).Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	assert.Error(t, (&VerifyConfig{Timeout: "soon"}).Validate())
+}
@@ -3,6 +3,7 @@ package zot
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 )
 
 // ZotConfig represents the Zot registry configuration file structure
@@ -16,12 +17,38 @@ type ZotConfig struct {
 
 // StorageConfiguration represents storage settings
 type StorageConfiguration struct {
-	RootDirectory string             `json:"rootDirectory"`
-	GC            bool               `json:"gc"`
-	Dedupe        bool               `json:"dedupe"`
-	SubPaths      map[string]SubPath `json:"subPaths,omitempty"`
+	RootDirectory string                  `json:"rootDirectory"`
+	GC            bool                    `json:"gc"`
+	Dedupe        bool                    `json:"dedupe"`
+	SubPaths      map[string]SubPath      `json:"subPaths,omitempty"`
+	Retention     *RetentionConfiguration `json:"retention,omitempty"`
 }
 
+// RetentionConfiguration represents Zot's garbage collection retention rules
+type RetentionConfiguration struct {
+	DryRun   bool                           `json:"dryRun"`
+	Delay    string                         `json:"delay,omitempty"`
+	Policies []RetentionPolicyConfiguration `json:"policies"`
+}
+
+// RetentionPolicyConfiguration applies retention rules to matching repositories
+type RetentionPolicyConfiguration struct {
+	Repositories    []string       `json:"repositories"`
+	DeleteReferrers bool           `json:"deleteReferrers"`
+	DeleteUntagged  bool           `json:"deleteUntagged"`
+	KeepTags        []KeepTagsRule `json:"keepTags,omitempty"`
+}
+
+// KeepTagsRule keeps tags matching the patterns, optionally only the most
+// recently pushed ones
+type KeepTagsRule struct {
+	Patterns                []string `json:"patterns,omitempty"`
+	MostRecentlyPushedCount int      `json:"mostRecentlyPushedCount,omitempty"`
+}
+
+// SemverTagPattern matches version tags such as 1.2.3, v1.2.3 and v1.2.3-rc.1
+const SemverTagPattern = `^v?[0-9]+\.[0-9]+\.[0-9]+([-+].*)?$`
+
 // SubPath represents a storage subpath configuration
 type SubPath struct {
 	RootDirectory string `json:"rootDirectory"`
@@ -29,14 +56,49 @@ type SubPath struct {
 	Dedupe        bool   `json:"dedupe"`
 }
 
+// Paths inside the Zot container. The host config dir is mounted at
+// ContainerConfigDir.
+const (
+	ContainerConfigDir   = "/etc/zot"
+	ContainerHTPasswd    = ContainerConfigDir + "/htpasswd"
+	ContainerTLSCertFile = ContainerConfigDir + "/tls/tls.crt"
+	ContainerTLSKeyFile  = ContainerConfigDir + "/tls/tls.key"
+)
+
 // HTTPConfiguration represents HTTP server settings
 type HTTPConfiguration struct {
-	Address string      `json:"address"`
-	Port    string      `json:"port"`
-	TLS     *TLSConfig  `json:"tls,omitempty"`
-	Auth    *AuthMethod `json:"auth,omitempty"`
+	Address       string               `json:"address"`
+	Port          string               `json:"port"`
+	TLS           *TLSConfig           `json:"tls,omitempty"`
+	Auth          *AuthMethod          `json:"auth,omitempty"`
+	AccessControl *AccessControlConfig `json:"accessControl,omitempty"`
 }
 
+// AccessControlConfig represents Zot's authorization settings
+type AccessControlConfig struct {
+	Repositories map[string]RepositoryAccess `json:"repositories"`
+	AdminPolicy  *AccessPolicy               `json:"adminPolicy,omitempty"`
+}
+
+// RepositoryAccess holds the policies for repositories matching a glob
+type RepositoryAccess struct {
+	Policies        []AccessPolicy `json:"policies,omitempty"`
+	DefaultPolicy   []string       `json:"defaultPolicy"`
+	AnonymousPolicy []string       `json:"anonymousPolicy,omitempty"`
+}
+
+// AccessPolicy grants actions to a set of users
+type AccessPolicy struct {
+	Users   []string `json:"users"`
+	Actions []string `json:"actions"`
+}
+
+var (
+	readActions  = []string{"read"}
+	pushActions  = []string{"read", "create", "update"}
+	adminActions = []string{"read", "create", "update", "delete"}
+)
+
 // TLSConfig represents TLS settings
 type TLSConfig struct {
 	Cert   string `json:"cert"`
@@ -156,8 +218,11 @@ type UpstreamRegistryCredentials struct {
 }
 
 // GenerateConfig generates a Zot configuration based on the provided Config
-// upstreamCreds is optional and can be nil if no credentials are configured
-func GenerateConfig(cfg *Config, upstreamCreds *UpstreamRegistryCredentials) (string, error) {
+// upstreamCreds is optional and can be nil if no credentials are configured.
+// When users are given, basic auth is enabled against the Foundry-managed
+// htpasswd file and access control is rendered from their roles and
+// cfg.AccessControl.
+func GenerateConfig(cfg *Config, upstreamCreds *UpstreamRegistryCredentials, users ...User) (string, error) {
 	zotConfig := &ZotConfig{
 		DistSpecVersion: "1.1.0",
 		Storage: StorageConfiguration{
@@ -175,7 +240,8 @@ func GenerateConfig(cfg *Config, upstreamCreds *UpstreamRegistryCredentials) (st
 		},
 	}
 
-	// Always enable metrics for Prometheus scraping
+	// Always enable metrics for Prometheus scraping, and search for the
+	// sizes and push dates 'foundry registry' reports
 	zotConfig.Extensions = &Extensions{
 		Metrics: &MetricsExtension{
 			Enable: true,
@@ -183,6 +249,17 @@ func GenerateConfig(cfg *Config, upstreamCreds *UpstreamRegistryCredentials) (st
 				Path: "/metrics",
 			},
 		},
+		Search: &SearchExtension{
+			Enable: true,
+		},
+	}
+
+	if cfg.Retention != nil {
+		retention, err := buildRetention(cfg.Retention)
+		if err != nil {
+			return "", err
+		}
+		zotConfig.Storage.Retention = retention
 	}
 
 	// Add pull-through cache for registries if enabled
@@ -212,16 +289,29 @@ func GenerateConfig(cfg *Config, upstreamCreds *UpstreamRegistryCredentials) (st
 				},
 			},
 		}
-		zotConfig.Extensions.Search = &SearchExtension{
-			Enable: true,
-		}
 		zotConfig.Extensions.UI = &UIExtension{
 			Enable: true,
 		}
 	}
 
+	if cfg.TLS != nil && *cfg.TLS {
+		zotConfig.HTTP.TLS = &TLSConfig{
+			Cert: ContainerTLSCertFile,
+			Key:  ContainerTLSKeyFile,
+		}
+	}
+
 	// Add authentication if configured
-	if cfg.Auth != nil {
+	if len(users) > 0 {
+		zotConfig.HTTP.Auth = &AuthMethod{
+			HTPasswd: HTPasswdConfig{Path: ContainerHTPasswd},
+		}
+		accessControl, err := buildAccessControl(cfg.AccessControl, users)
+		if err != nil {
+			return "", err
+		}
+		zotConfig.HTTP.AccessControl = accessControl
+	} else if cfg.Auth != nil {
 		switch cfg.Auth.Type {
 		case "basic":
 			if htpasswdPath, ok := cfg.Auth.Config["htpasswd_path"].(string); ok {
@@ -248,3 +338,121 @@ func GenerateConfig(cfg *Config, upstreamCreds *UpstreamRegistryCredentials) (st
 
 	return string(jsonBytes), nil
 }
+
+// buildAccessControl renders Zot access control from user roles plus the
+// per-repository grants in the stack config
+func buildAccessControl(policies []RepositoryPolicy, users []User) (*AccessControlConfig, error) {
+	var admins, pushers, readers []string
+	for _, u := range users {
+		switch u.Role {
+		case RoleAdmin:
+			admins = append(admins, u.Name)
+		case RolePush:
+			pushers = append(pushers, u.Name)
+		case RoleRead:
+			readers = append(readers, u.Name)
+		}
+	}
+
+	// Role grants apply to every repository, including the ones with
+	// their own entry, since Zot uses only the most specific match
+	rolePolicies := func() []AccessPolicy {
+		var p []AccessPolicy
+		if len(pushers) > 0 {
+			p = append(p, AccessPolicy{Users: pushers, Actions: pushActions})
+		}
+		if len(readers) > 0 {
+			p = append(p, AccessPolicy{Users: readers, Actions: readActions})
+		}
+		return p
+	}
+
+	ac := &AccessControlConfig{
+		Repositories: map[string]RepositoryAccess{
+			"**": {Policies: rolePolicies(), DefaultPolicy: []string{}},
+		},
+	}
+	if len(admins) > 0 {
+		ac.AdminPolicy = &AccessPolicy{Users: admins, Actions: adminActions}
+	}
+
+	for _, policy := range policies {
+		if policy.Repositories == "" {
+			return nil, fmt.Errorf("access_control entry is missing repositories")
+		}
+		for _, name := range append(append([]string{}, policy.Read...), policy.Push...) {
+			if _, ok := FindUser(users, name); !ok {
+				return nil, fmt.Errorf("access_control for %q references unknown user %q", policy.Repositories, name)
+			}
+		}
+		access := ac.Repositories[policy.Repositories]
+		if access.Policies == nil {
+			access.Policies = rolePolicies()
+		}
+		access.DefaultPolicy = []string{}
+		if len(policy.Push) > 0 {
+			access.Policies = append(access.Policies, AccessPolicy{Users: policy.Push, Actions: pushActions})
+		}
+		if len(policy.Read) > 0 {
+			access.Policies = append(access.Policies, AccessPolicy{Users: policy.Read, Actions: readActions})
+		}
+		if policy.AnonymousRead != nil && *policy.AnonymousRead {
+			access.AnonymousPolicy = readActions
+		}
+		ac.Repositories[policy.Repositories] = access
+	}
+	return ac, nil
+}
+
+// buildRetention renders retention policies into Zot's storage settings
+func buildRetention(cfg *RetentionConfig) (*RetentionConfiguration, error) {
+	deleteUntagged := false
+	retention := &RetentionConfiguration{}
+	if cfg.DeleteUntaggedAfterDays != nil {
+		days := *cfg.DeleteUntaggedAfterDays
+		if days < 1 {
+			return nil, fmt.Errorf("retention.delete_untagged_after_days must be at least 1")
+		}
+		deleteUntagged = true
+		retention.Delay = fmt.Sprintf("%dh", days*24)
+	}
+
+	for _, policy := range cfg.Policies {
+		if len(policy.Repositories) == 0 {
+			return nil, fmt.Errorf("retention policy is missing repositories")
+		}
+		rendered := RetentionPolicyConfiguration{
+			Repositories:   policy.Repositories,
+			DeleteUntagged: deleteUntagged,
+		}
+		if policy.KeepLast != nil {
+			if *policy.KeepLast < 1 {
+				return nil, fmt.Errorf("retention keep_last for %v must be at least 1", policy.Repositories)
+			}
+			rendered.KeepTags = append(rendered.KeepTags, KeepTagsRule{
+				Patterns:                []string{".*"},
+				MostRecentlyPushedCount: int(*policy.KeepLast),
+			})
+		}
+		if policy.KeepSemver != nil && *policy.KeepSemver {
+			rendered.KeepTags = append(rendered.KeepTags, KeepTagsRule{Patterns: []string{SemverTagPattern}})
+		}
+		if len(policy.KeepTags) > 0 {
+			for _, pattern := range policy.KeepTags {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return nil, fmt.Errorf("retention keep_tags pattern %q: %w", pattern, err)
+				}
+			}
+			rendered.KeepTags = append(rendered.KeepTags, KeepTagsRule{Patterns: policy.KeepTags})
+		}
+		retention.Policies = append(retention.Policies, rendered)
+	}
+
+	// Untagged cleanup alone applies everywhere and keeps every tag
+	if len(retention.Policies) == 0 {
+		retention.Policies = []RetentionPolicyConfiguration{
+			{Repositories: []string{"**"}, DeleteUntagged: deleteUntagged},
+		}
+	}
+	return retention, nil
+}
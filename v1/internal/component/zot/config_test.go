@@ -87,14 +87,16 @@ func TestGenerateConfig_WithoutPullThroughCache(t *testing.T) {
 	require.NoError(t, err)
 
 	// Extensions should still contain metrics (always enabled for Prometheus)
-	// but Sync, Search, and UI should be nil when pull-through cache is disabled
+	// and search (used by 'foundry registry'), but Sync and UI should be nil
+	// when pull-through cache is disabled
 	require.NotNil(t, zotConfig.Extensions)
 	require.NotNil(t, zotConfig.Extensions.Metrics)
 	assert.True(t, zotConfig.Extensions.Metrics.Enable)
 	require.NotNil(t, zotConfig.Extensions.Metrics.Prometheus)
 	assert.Equal(t, "/metrics", zotConfig.Extensions.Metrics.Prometheus.Path)
 	assert.Nil(t, zotConfig.Extensions.Sync)
-	assert.Nil(t, zotConfig.Extensions.Search)
+	require.NotNil(t, zotConfig.Extensions.Search)
+	assert.True(t, zotConfig.Extensions.Search.Enable)
 	assert.Nil(t, zotConfig.Extensions.UI)
 }
 
@@ -281,3 +283,131 @@ func TestGenerateConfig_WithoutDockerHubCredentials(t *testing.T) {
 	dockerRegistry := zotConfig.Extensions.Sync.Registries[0]
 	assert.Nil(t, dockerRegistry.Credentials)
 }
+
+func TestGenerateConfig_WithTLS(t *testing.T) {
+	cfg := DefaultConfig()
+	enabled := true
+	cfg.TLS = &enabled
+
+	configStr, err := GenerateConfig(cfg, nil)
+	require.NoError(t, err)
+
+	var zotConfig ZotConfig
+	require.NoError(t, json.Unmarshal([]byte(configStr), &zotConfig))
+	require.NotNil(t, zotConfig.HTTP.TLS)
+	assert.Equal(t, "/etc/zot/tls/tls.crt", zotConfig.HTTP.TLS.Cert)
+	assert.Equal(t, "/etc/zot/tls/tls.key", zotConfig.HTTP.TLS.Key)
+}
+
+func TestGenerateConfig_WithManagedUsers(t *testing.T) {
+	cfg := DefaultConfig()
+	anonymous := true
+	cfg.AccessControl = []RepositoryPolicy{
+		{Repositories: "team-a/**", Push: []string{"ci"}},
+		{Repositories: "public/**", AnonymousRead: &anonymous},
+	}
+	users := []User{
+		{Name: "admin", Password: "a", Role: RoleAdmin, Kind: KindHuman},
+		{Name: "k3s", Password: "b", Role: RoleRead, Kind: KindRobot},
+		{Name: "ci", Password: "c", Role: RoleRead, Kind: KindRobot},
+	}
+
+	configStr, err := GenerateConfig(cfg, nil, users...)
+	require.NoError(t, err)
+
+	var zotConfig ZotConfig
+	require.NoError(t, json.Unmarshal([]byte(configStr), &zotConfig))
+	require.NotNil(t, zotConfig.HTTP.Auth)
+	assert.Equal(t, "/etc/zot/htpasswd", zotConfig.HTTP.Auth.HTPasswd.Path)
+
+	ac := zotConfig.HTTP.AccessControl
+	require.NotNil(t, ac)
+	require.NotNil(t, ac.AdminPolicy)
+	assert.Equal(t, []string{"admin"}, ac.AdminPolicy.Users)
+	assert.Equal(t, []AccessPolicy{{Users: []string{"k3s", "ci"}, Actions: []string{"read"}}}, ac.Repositories["**"].Policies)
+
+	teamA := ac.Repositories["team-a/**"]
+	require.Len(t, teamA.Policies, 2)
+	assert.Equal(t, AccessPolicy{Users: []string{"ci"}, Actions: []string{"read", "create", "update"}}, teamA.Policies[1])
+	assert.Empty(t, teamA.AnonymousPolicy)
+	assert.Equal(t, []string{"read"}, ac.Repositories["public/**"].AnonymousPolicy)
+}
+
+func TestGenerateConfig_AccessControlUnknownUser(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AccessControl = []RepositoryPolicy{{Repositories: "team-a/**", Read: []string{"ghost"}}}
+	users := []User{{Name: "admin", Password: "a", Role: RoleAdmin, Kind: KindHuman}}
+
+	_, err := GenerateConfig(cfg, nil, users...)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ghost")
+}
+
+func TestGenerateConfig_WithRetention(t *testing.T) {
+	cfg := DefaultConfig()
+	days := int64(14)
+	keepLast := int64(10)
+	keepSemver := true
+	cfg.Retention = &RetentionConfig{
+		DeleteUntaggedAfterDays: &days,
+		Policies: []RetentionPolicy{
+			{Repositories: []string{"apps/**"}, KeepLast: &keepLast, KeepSemver: &keepSemver, KeepTags: []string{"^latest$"}},
+		},
+	}
+
+	configStr, err := GenerateConfig(cfg, nil)
+	require.NoError(t, err)
+
+	var zotConfig ZotConfig
+	require.NoError(t, json.Unmarshal([]byte(configStr), &zotConfig))
+	retention := zotConfig.Storage.Retention
+	require.NotNil(t, retention)
+	assert.False(t, retention.DryRun)
+	assert.Equal(t, "336h", retention.Delay)
+	require.Len(t, retention.Policies, 1)
+	policy := retention.Policies[0]
+	assert.Equal(t, []string{"apps/**"}, policy.Repositories)
+	assert.True(t, policy.DeleteUntagged)
+	assert.Equal(t, []KeepTagsRule{
+		{Patterns: []string{".*"}, MostRecentlyPushedCount: 10},
+		{Patterns: []string{SemverTagPattern}},
+		{Patterns: []string{"^latest$"}},
+	}, policy.KeepTags)
+}
+
+func TestGenerateConfig_RetentionUntaggedOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	days := int64(7)
+	cfg.Retention = &RetentionConfig{DeleteUntaggedAfterDays: &days}
+
+	configStr, err := GenerateConfig(cfg, nil)
+	require.NoError(t, err)
+
+	var zotConfig ZotConfig
+	require.NoError(t, json.Unmarshal([]byte(configStr), &zotConfig))
+	require.NotNil(t, zotConfig.Storage.Retention)
+	assert.Equal(t, []RetentionPolicyConfiguration{
+		{Repositories: []string{"**"}, DeleteUntagged: true},
+	}, zotConfig.Storage.Retention.Policies)
+}
+
+func TestGenerateConfig_InvalidRetention(t *testing.T) {
+	zero := int64(0)
+	tests := []struct {
+		name      string
+		retention *RetentionConfig
+	}{
+		{"zero days", &RetentionConfig{DeleteUntaggedAfterDays: &zero}},
+		{"no repositories", &RetentionConfig{Policies: []RetentionPolicy{{KeepTags: []string{"x"}}}}},
+		{"zero keep_last", &RetentionConfig{Policies: []RetentionPolicy{{Repositories: []string{"**"}, KeepLast: &zero}}}},
+		{"bad pattern", &RetentionConfig{Policies: []RetentionPolicy{{Repositories: []string{"**"}, KeepTags: []string{"("}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Retention = tt.retention
+			_, err := GenerateConfig(cfg, nil)
+			require.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package zot
 
 import (
+	"encoding/base64"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -19,7 +20,11 @@ func Install(conn container.SSHExecutor, runtime container.Runtime, cfg *ParsedC
 		return fmt.Errorf("write config file: %w", err)
 	}
 
-	imageName := fmt.Sprintf("ghcr.io/project-zot/zot:%s", cfg.Version)
+	if err := writeHTPasswdFile(conn, cfg); err != nil {
+		return fmt.Errorf("write htpasswd file: %w", err)
+	}
+
+	imageName := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
 	if err := runtime.Pull(imageName); err != nil {
 		return fmt.Errorf("pull container image: %w", err)
 	}
@@ -70,9 +75,35 @@ func createDirectories(conn container.SSHExecutor, cfg *ParsedConfig) error {
 	return nil
 }
 
+// ApplyConfig rewrites config.json and htpasswd on an installed registry and
+// restarts it. Used when users or access control change after install.
+func ApplyConfig(conn container.SSHExecutor, cfg *ParsedConfig) error {
+	if err := writeConfigFile(conn, cfg); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+
+	if err := writeHTPasswdFile(conn, cfg); err != nil {
+		return fmt.Errorf("write htpasswd file: %w", err)
+	}
+
+	if _, err := conn.Execute("sudo systemctl try-restart foundry-zot"); err != nil {
+		return fmt.Errorf("restart service: %w", err)
+	}
+
+	return nil
+}
+
 // writeConfigFile generates and writes the Zot config.json file
 func writeConfigFile(conn container.SSHExecutor, cfg *ParsedConfig) error {
-	configContent, err := GenerateConfig(cfg.Config, cfg.UpstreamCreds)
+	zotCfg := *cfg.Config
+	if zotCfg.TLS != nil && *zotCfg.TLS && !HasTLSCertificate(conn, cfg.ConfigDir) {
+		// Zot refuses to start with a missing certificate
+		fmt.Printf("  ⚠ No certificate in %s/tls yet; serving plain HTTP until 'foundry host tls issue zot'\n", cfg.ConfigDir)
+		disabled := false
+		zotCfg.TLS = &disabled
+	}
+
+	configContent, err := GenerateConfig(&zotCfg, cfg.UpstreamCreds, cfg.Users...)
 	if err != nil {
 		return fmt.Errorf("generate config: %w", err)
 	}
@@ -88,6 +119,34 @@ func writeConfigFile(conn container.SSHExecutor, cfg *ParsedConfig) error {
 	return nil
 }
 
+// writeHTPasswdFile writes the bcrypt htpasswd file for Foundry-managed users
+func writeHTPasswdFile(conn container.SSHExecutor, cfg *ParsedConfig) error {
+	if len(cfg.Users) == 0 {
+		return nil
+	}
+
+	content, err := HTPasswd(cfg.Users)
+	if err != nil {
+		return err
+	}
+
+	// Base64 keeps the '$' in bcrypt hashes away from the shell.
+	path := filepath.Join(cfg.ConfigDir, "htpasswd")
+	cmd := fmt.Sprintf("echo %s | base64 -d | sudo tee %s > /dev/null && sudo chmod 0600 %s",
+		base64.StdEncoding.EncodeToString([]byte(content)), path, path)
+	if _, err := conn.Execute(cmd); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// HasTLSCertificate reports whether a certificate has been installed for Zot
+func HasTLSCertificate(conn container.SSHExecutor, configDir string) bool {
+	_, err := conn.Execute(fmt.Sprintf("sudo test -f %s/tls/tls.crt", configDir))
+	return err == nil
+}
+
 // createSystemdService creates the systemd service unit for Zot
 func createSystemdService(conn container.SSHExecutor, runtime container.Runtime, cfg *ParsedConfig) error {
 	runtimePath, err := detectRuntimePath(conn, runtime.Name())
@@ -100,10 +159,9 @@ func createSystemdService(conn container.SSHExecutor, runtime container.Runtime,
 		dataDir = cfg.StorageBackend.MountPath
 	}
 
-	imageName := fmt.Sprintf("ghcr.io/project-zot/zot:%s", cfg.Version)
-	configPath := filepath.Join(cfg.ConfigDir, "config.json")
+	imageName := container.PinImage(Image(cfg.Version), cfg.ImageDigests)
 
-	execStart := buildExecStart(runtimePath, imageName, int(cfg.Port), dataDir, configPath)
+	execStart := buildExecStart(runtimePath, imageName, int(cfg.Port), dataDir, cfg.ConfigDir)
 
 	unit := systemd.ContainerUnitFile(
 		"foundry-zot",
@@ -135,9 +193,15 @@ func detectRuntimePath(conn container.SSHExecutor, runtimeType string) (string,
 }
 
 // buildExecStart builds the ExecStart command for the systemd service
-func buildExecStart(runtimePath, image string, port int, dataDir, configPath string) string {
+func buildExecStart(runtimePath, image string, port int, dataDir, configDir string) string {
 	// Note: No --rm flag - systemd manages the container lifecycle via ExecStartPre/ExecStopPost
 	// --security-opt apparmor=unconfined: nerdctl-default profile blocks runc signal operations
-	return fmt.Sprintf("%s run --name foundry-zot --security-opt apparmor=unconfined -p %d:%d -v %s:/var/lib/zot -v %s:/etc/zot/config.json %s",
-		runtimePath, port, port, dataDir, configPath, image)
+	// The whole config dir is mounted so htpasswd and tls/ sit next to config.json
+	return fmt.Sprintf("%s run --name foundry-zot --security-opt apparmor=unconfined -p %d:%d -v %s:/var/lib/zot -v %s:%s:ro %s",
+		runtimePath, port, port, dataDir, configDir, ContainerConfigDir, image)
+}
+
+// Image returns the Zot container image for a version
+func Image(version string) string {
+	return fmt.Sprintf("ghcr.io/project-zot/zot:%s", version)
 }
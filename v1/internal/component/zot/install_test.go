@@ -90,6 +90,10 @@ func (m *mockRuntime) Pull(image string) error {
 	return nil
 }
 
+func (m *mockRuntime) Load(archivePath string) error {
+	return nil
+}
+
 func (m *mockRuntime) Run(config container.RunConfig) (string, error) {
 	return "container-id-123", nil
 }
@@ -313,6 +317,35 @@ func TestWriteConfigFile_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "write config file")
 }
 
+func TestWriteConfigFile_TLSWithoutCertificate(t *testing.T) {
+	executor := newMockExecutor()
+	executor.errors["sudo test -f /etc/foundry-zot/tls/tls.crt"] = fmt.Errorf("exit status 1")
+	enabled := true
+	cfg := &ParsedConfig{Config: DefaultConfig()}
+	cfg.TLS = &enabled
+
+	require.NoError(t, writeConfigFile(executor, cfg))
+	for _, cmd := range executor.commands {
+		if strings.Contains(cmd, "sudo tee /etc/foundry-zot/config.json") {
+			assert.NotContains(t, cmd, "tls.crt")
+		}
+	}
+	assert.True(t, *cfg.TLS, "stack config must not be modified")
+}
+
+func TestApplyConfig_WithUsers(t *testing.T) {
+	executor := newMockExecutor()
+	cfg := &ParsedConfig{
+		Config: DefaultConfig(),
+		Users:  []User{{Name: "admin", Password: "secret", Role: RoleAdmin, Kind: KindHuman}},
+	}
+
+	require.NoError(t, ApplyConfig(executor, cfg))
+	assert.True(t, executor.hasCommand("sudo tee /etc/foundry-zot/htpasswd > /dev/null && sudo chmod 0600 /etc/foundry-zot/htpasswd"))
+	assert.True(t, executor.hasCommand("sudo systemctl try-restart foundry-zot"))
+	assert.False(t, executor.hasCommand("secret"), "passwords must not reach the shell")
+}
+
 func TestCreateSystemdService_Success(t *testing.T) {
 	executor := newMockExecutor()
 	runtime := newMockRuntime()
@@ -339,7 +372,7 @@ func TestCreateSystemdService_Success(t *testing.T) {
 			assert.Contains(t, cmd, "--security-opt apparmor=unconfined")
 			assert.Contains(t, cmd, "-p 5000:5000")
 			assert.Contains(t, cmd, "-v /var/lib/foundry-zot:/var/lib/zot")
-			assert.Contains(t, cmd, "-v /etc/foundry-zot/config.json:/etc/zot/config.json")
+			assert.Contains(t, cmd, "-v /etc/foundry-zot:/etc/zot:ro")
 			assert.Contains(t, cmd, "ghcr.io/project-zot/zot:latest")
 			// No ExecStop - systemd handles graceful shutdown via signals
 			// ExecStopPost cleans up the container after stopping
@@ -5,14 +5,18 @@ package zot
 
 // Config represents a structured data type
 type Config struct {
-	Version          string         `json:"version" yaml:"version"`
-	DataDir          string         `json:"data_dir" yaml:"data_dir"`
-	ConfigDir        string         `json:"config_dir" yaml:"config_dir"`
-	Port             int64          `json:"port" yaml:"port"`
-	ContainerRuntime string         `json:"container_runtime" yaml:"container_runtime"`
-	StorageBackend   *StorageConfig `json:"storage_backend,omitempty" yaml:"storage_backend,omitempty"`
-	PullThroughCache bool           `json:"pull_through_cache" yaml:"pull_through_cache"`
-	Auth             *AuthConfig    `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Version          string             `json:"version" yaml:"version"`
+	DataDir          string             `json:"data_dir" yaml:"data_dir"`
+	ConfigDir        string             `json:"config_dir" yaml:"config_dir"`
+	Port             int64              `json:"port" yaml:"port"`
+	ContainerRuntime string             `json:"container_runtime" yaml:"container_runtime"`
+	StorageBackend   *StorageConfig     `json:"storage_backend,omitempty" yaml:"storage_backend,omitempty"`
+	PullThroughCache bool               `json:"pull_through_cache" yaml:"pull_through_cache"`
+	Auth             *AuthConfig        `json:"auth,omitempty" yaml:"auth,omitempty"`
+	TLS              *bool              `json:"tls,omitempty" yaml:"tls,omitempty"`
+	AccessControl    []RepositoryPolicy `json:"access_control,omitempty" yaml:"access_control,omitempty"`
+	Retention        *RetentionConfig   `json:"retention,omitempty" yaml:"retention,omitempty"`
+	ImageDigests     map[string]string  `json:"image_digests,omitempty" yaml:"image_digests,omitempty"`
 }
 
 // StorageConfig represents a structured data type
@@ -26,3 +30,25 @@ type AuthConfig struct {
 	Type   string         `json:"type" yaml:"type"`
 	Config map[string]any `json:"config" yaml:",inline"`
 }
+
+// RepositoryPolicy represents a structured data type
+type RepositoryPolicy struct {
+	Repositories  string   `json:"repositories" yaml:"repositories"`
+	Read          []string `json:"read,omitempty" yaml:"read,omitempty"`
+	Push          []string `json:"push,omitempty" yaml:"push,omitempty"`
+	AnonymousRead *bool    `json:"anonymous_read,omitempty" yaml:"anonymous_read,omitempty"`
+}
+
+// RetentionConfig represents a structured data type
+type RetentionConfig struct {
+	DeleteUntaggedAfterDays *int64            `json:"delete_untagged_after_days,omitempty" yaml:"delete_untagged_after_days,omitempty"`
+	Policies                []RetentionPolicy `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// RetentionPolicy represents a structured data type
+type RetentionPolicy struct {
+	Repositories []string `json:"repositories" yaml:"repositories"`
+	KeepLast     *int64   `json:"keep_last,omitempty" yaml:"keep_last,omitempty"`
+	KeepSemver   *bool    `json:"keep_semver,omitempty" yaml:"keep_semver,omitempty"`
+	KeepTags     []string `json:"keep_tags,omitempty" yaml:"keep_tags,omitempty"`
+}
@@ -104,6 +104,9 @@ func DefaultConfig() *Config {
 type ParsedConfig struct {
 	*Config
 	UpstreamCreds *UpstreamRegistryCredentials
+	// Users are the Foundry-managed registry accounts, loaded from OpenBAO
+	// when auth type is "basic"
+	Users []User
 }
 
 // ParseConfig parses a ComponentConfig into a Zot Config
@@ -159,6 +162,62 @@ func ParseConfig(cfg component.ComponentConfig) (*ParsedConfig, error) {
 		}
 	}
 
+	if tls, ok := cfg["tls"].(bool); ok {
+		config.TLS = &tls
+	}
+
+	if entries, ok := cfg["access_control"].([]interface{}); ok {
+		for _, raw := range entries {
+			entry, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("access_control entries must be objects")
+			}
+			policyCfg := component.ComponentConfig(entry)
+			policy := RepositoryPolicy{}
+			policy.Repositories, _ = policyCfg.GetString("repositories")
+			policy.Read, _ = policyCfg.GetStringSlice("read")
+			policy.Push, _ = policyCfg.GetStringSlice("push")
+			if anonymous, ok := entry["anonymous_read"].(bool); ok {
+				policy.AnonymousRead = &anonymous
+			}
+			config.AccessControl = append(config.AccessControl, policy)
+		}
+	}
+
+	if retention, ok := cfg["retention"].(map[string]interface{}); ok {
+		config.Retention = &RetentionConfig{}
+		if days, ok := component.ComponentConfig(retention).GetInt("delete_untagged_after_days"); ok {
+			untagged := int64(days)
+			config.Retention.DeleteUntaggedAfterDays = &untagged
+		}
+		if policies, ok := retention["policies"].([]interface{}); ok {
+			for _, raw := range policies {
+				entry, ok := raw.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("retention policies must be objects")
+				}
+				policyCfg := component.ComponentConfig(entry)
+				policy := RetentionPolicy{}
+				policy.Repositories, _ = policyCfg.GetStringSlice("repositories")
+				policy.KeepTags, _ = policyCfg.GetStringSlice("keep_tags")
+				if keepLast, ok := policyCfg.GetInt("keep_last"); ok {
+					last := int64(keepLast)
+					policy.KeepLast = &last
+				}
+				if keepSemver, ok := entry["keep_semver"].(bool); ok {
+					policy.KeepSemver = &keepSemver
+				}
+				config.Retention.Policies = append(config.Retention.Policies, policy)
+			}
+		}
+	}
+
+	if users, ok := cfg["users"].([]User); ok {
+		parsed.Users = users
+	}
+
+	config.ImageDigests = container.ParseImageDigests(cfg["image_digests"])
+
 	// Parse Docker Hub credentials for pull-through cache (avoids rate limiting)
 	dockerHubUser, hasUser := cfg["docker_hub_username"].(string)
 	dockerHubPass, hasPass := cfg["docker_hub_password"].(string)
@@ -99,6 +99,39 @@ func TestParseConfig_WithAuth(t *testing.T) {
 	assert.Equal(t, "/etc/zot/htpasswd", config.Auth.Config["htpasswd_path"])
 }
 
+func TestParseConfig_WithAccessControl(t *testing.T) {
+	users := []User{{Name: "ci", Password: "x", Role: RolePush, Kind: KindRobot}}
+	cfg := component.ComponentConfig{
+		"tls": true,
+		"access_control": []interface{}{
+			map[string]interface{}{
+				"repositories":   "team-a/**",
+				"read":           []interface{}{"alice"},
+				"push":           []interface{}{"ci"},
+				"anonymous_read": true,
+			},
+		},
+		"users": users,
+	}
+
+	config, err := ParseConfig(cfg)
+	require.NoError(t, err)
+
+	require.NotNil(t, config.TLS)
+	assert.True(t, *config.TLS)
+	require.Len(t, config.AccessControl, 1)
+	policy := config.AccessControl[0]
+	assert.Equal(t, "team-a/**", policy.Repositories)
+	assert.Equal(t, []string{"alice"}, policy.Read)
+	assert.Equal(t, []string{"ci"}, policy.Push)
+	require.NotNil(t, policy.AnonymousRead)
+	assert.True(t, *policy.AnonymousRead)
+	assert.Equal(t, users, config.Users)
+
+	_, err = ParseConfig(component.ComponentConfig{"access_control": []interface{}{"team-a/**"}})
+	require.Error(t, err)
+}
+
 func TestParseConfig_Complete(t *testing.T) {
 	cfg := component.ComponentConfig{
 		"version":            "v2.0.0",
@@ -141,3 +174,37 @@ func TestComponent_Name(t *testing.T) {
 	comp := NewComponent(nil)
 	assert.Equal(t, "zot", comp.Name())
 }
+
+func TestParseConfig_WithRetention(t *testing.T) {
+	cfg := component.ComponentConfig{
+		"retention": map[string]interface{}{
+			"delete_untagged_after_days": 14,
+			"policies": []interface{}{
+				map[string]interface{}{
+					"repositories": []interface{}{"apps/**"},
+					"keep_last":    float64(5),
+					"keep_semver":  true,
+					"keep_tags":    []interface{}{"^latest$"},
+				},
+			},
+		},
+	}
+
+	config, err := ParseConfig(cfg)
+	require.NoError(t, err)
+
+	require.NotNil(t, config.Retention)
+	require.NotNil(t, config.Retention.DeleteUntaggedAfterDays)
+	assert.Equal(t, int64(14), *config.Retention.DeleteUntaggedAfterDays)
+	require.Len(t, config.Retention.Policies, 1)
+	policy := config.Retention.Policies[0]
+	assert.Equal(t, []string{"apps/**"}, policy.Repositories)
+	require.NotNil(t, policy.KeepLast)
+	assert.Equal(t, int64(5), *policy.KeepLast)
+	require.NotNil(t, policy.KeepSemver)
+	assert.True(t, *policy.KeepSemver)
+	assert.Equal(t, []string{"^latest$"}, policy.KeepTags)
+
+	_, err = ParseConfig(component.ComponentConfig{"retention": map[string]interface{}{"policies": []interface{}{"apps/**"}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,160 @@
+package zot
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User roles decide what an account may do across all repositories.
+// Per-repository grants in access_control come on top of these.
+const (
+	RoleAdmin = "admin" // read, push and delete everywhere
+	RolePush  = "push"  // read and push everywhere
+	RoleRead  = "read"  // read everywhere
+)
+
+// User kinds separate people from machine accounts (CI, the cluster).
+const (
+	KindHuman = "human"
+	KindRobot = "robot"
+)
+
+// Accounts Foundry creates when registry authentication is enabled
+const (
+	AdminUser   = "admin"
+	ClusterUser = "k3s"
+)
+
+var userNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// User is a registry account. Passwords are kept in OpenBAO; Zot only sees
+// the bcrypt hash in its htpasswd file.
+type User struct {
+	Name     string
+	Password string
+	Role     string
+	Kind     string
+}
+
+// Validate checks the user's name, role and kind
+func (u User) Validate() error {
+	if !userNamePattern.MatchString(u.Name) {
+		return fmt.Errorf("invalid user name %q (lowercase letters, digits, '.', '_' and '-')", u.Name)
+	}
+	switch u.Role {
+	case RoleAdmin, RolePush, RoleRead:
+	default:
+		return fmt.Errorf("invalid role %q for user %s (expected admin, push or read)", u.Role, u.Name)
+	}
+	switch u.Kind {
+	case KindHuman, KindRobot:
+	default:
+		return fmt.Errorf("invalid kind %q for user %s (expected human or robot)", u.Kind, u.Name)
+	}
+	if u.Password == "" {
+		return fmt.Errorf("user %s has no password", u.Name)
+	}
+	return nil
+}
+
+// GeneratePassword returns a random password suitable for registry accounts
+func GeneratePassword() (string, error) {
+	data := make([]byte, 24)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// EnsureDefaultUsers adds the admin and cluster accounts if they are missing.
+// It reports whether any account was created.
+func EnsureDefaultUsers(users []User) ([]User, bool, error) {
+	defaults := []User{
+		{Name: AdminUser, Role: RoleAdmin, Kind: KindHuman},
+		{Name: ClusterUser, Role: RoleRead, Kind: KindRobot},
+	}
+	changed := false
+	for _, def := range defaults {
+		if _, ok := FindUser(users, def.Name); ok {
+			continue
+		}
+		password, err := GeneratePassword()
+		if err != nil {
+			return nil, false, err
+		}
+		def.Password = password
+		users = append(users, def)
+		changed = true
+	}
+	return users, changed, nil
+}
+
+// FindUser returns the user with the given name
+func FindUser(users []User, name string) (User, bool) {
+	for _, u := range users {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// HTPasswd renders an htpasswd file with bcrypt hashes, sorted by user name
+func HTPasswd(users []User) (string, error) {
+	sorted := append([]User(nil), users...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var b strings.Builder
+	for _, u := range sorted {
+		if err := u.Validate(); err != nil {
+			return "", err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("hash password for %s: %w", u.Name, err)
+		}
+		fmt.Fprintf(&b, "%s:%s\n", u.Name, hash)
+	}
+	return b.String(), nil
+}
+
+// UsersFromSecret decodes users stored in OpenBAO. Each key is a user name
+// and each value holds password, role and kind.
+func UsersFromSecret(data map[string]interface{}) ([]User, error) {
+	users := make([]User, 0, len(data))
+	for name, raw := range data {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("registry user %s is not an object", name)
+		}
+		u := User{Name: name}
+		u.Password, _ = fields["password"].(string)
+		u.Role, _ = fields["role"].(string)
+		u.Kind, _ = fields["kind"].(string)
+		if err := u.Validate(); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, nil
+}
+
+// UsersToSecret encodes users for storage in OpenBAO
+func UsersToSecret(users []User) map[string]interface{} {
+	data := make(map[string]interface{}, len(users))
+	for _, u := range users {
+		data[u.Name] = map[string]interface{}{
+			"password": u.Password,
+			"role":     u.Role,
+			"kind":     u.Kind,
+		}
+	}
+	return data
+}
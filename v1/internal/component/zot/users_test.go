@@ -0,0 +1,86 @@
+package zot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUser_Validate(t *testing.T) {
+	valid := User{Name: "ci-bot", Password: "secret", Role: RolePush, Kind: KindRobot}
+	require.NoError(t, valid.Validate())
+
+	bad := valid
+	bad.Name = "CI Bot"
+	assert.Error(t, bad.Validate())
+
+	bad = valid
+	bad.Role = "owner"
+	assert.Error(t, bad.Validate())
+
+	bad = valid
+	bad.Kind = "service"
+	assert.Error(t, bad.Validate())
+
+	bad = valid
+	bad.Password = ""
+	assert.Error(t, bad.Validate())
+}
+
+func TestEnsureDefaultUsers(t *testing.T) {
+	users, changed, err := EnsureDefaultUsers(nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	require.Len(t, users, 2)
+
+	admin, ok := FindUser(users, AdminUser)
+	require.True(t, ok)
+	assert.Equal(t, RoleAdmin, admin.Role)
+	assert.NotEmpty(t, admin.Password)
+
+	cluster, ok := FindUser(users, ClusterUser)
+	require.True(t, ok)
+	assert.Equal(t, RoleRead, cluster.Role)
+	assert.Equal(t, KindRobot, cluster.Kind)
+
+	again, changed, err := EnsureDefaultUsers(users)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, users, again)
+}
+
+func TestHTPasswd(t *testing.T) {
+	users := []User{
+		{Name: "zed", Password: "p1", Role: RoleRead, Kind: KindHuman},
+		{Name: "alice", Password: "p2", Role: RolePush, Kind: KindHuman},
+	}
+	content, err := HTPasswd(users)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	require.Len(t, lines, 2)
+	name, hash, ok := strings.Cut(lines[0], ":")
+	require.True(t, ok)
+	assert.Equal(t, "alice", name)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("p2")))
+	assert.True(t, strings.HasPrefix(lines[1], "zed:"))
+
+	_, err = HTPasswd([]User{{Name: "bad"}})
+	assert.Error(t, err)
+}
+
+func TestUsersSecretRoundTrip(t *testing.T) {
+	users := []User{
+		{Name: "admin", Password: "a", Role: RoleAdmin, Kind: KindHuman},
+		{Name: "k3s", Password: "b", Role: RoleRead, Kind: KindRobot},
+	}
+	decoded, err := UsersFromSecret(UsersToSecret(users))
+	require.NoError(t, err)
+	assert.Equal(t, users, decoded)
+
+	_, err = UsersFromSecret(map[string]interface{}{"admin": "not-an-object"})
+	assert.Error(t, err)
+}
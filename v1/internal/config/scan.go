@@ -0,0 +1,307 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretFinding is a value in the config that looks like a secret
+type SecretFinding struct {
+	Field  string // dotted YAML path, e.g. components.seaweedfs.access_key
+	Value  string
+	Reason string
+	// Path and Key are where the value belongs in OpenBAO's foundry-core
+	// mount. Findings with the same value share one.
+	Path string
+	Key  string
+}
+
+// Ref returns the reference that replaces the value in the config
+func (f SecretFinding) Ref() string {
+	return fmt.Sprintf("${secret:%s:%s}", f.Path, f.Key)
+}
+
+// secretFieldNames are field names, lowercased with "_" and "-" removed,
+// that hold secrets. A field matches when its name ends with one of them,
+// or for "secret" when it is the whole name, since Helm charts name
+// Kubernetes Secrets with fields like existingSecret.
+var secretFieldNames = []string{
+	"password", "passwd", "secretkey", "secretaccesskey", "accesskey",
+	"accesskeyid", "apikey", "apitoken", "token", "authtoken",
+	"clientsecret", "privatekey",
+}
+
+// canonicalSecretFields win when the same value appears in several places,
+// so a shared credential keeps the reference Foundry itself uses for it
+var canonicalSecretFields = []string{
+	"components.seaweedfs.access_key",
+	"components.seaweedfs.secret_key",
+	"dns.api_key",
+}
+
+var (
+	tokenPattern   = regexp.MustCompile(`^[A-Za-z0-9+/=_-]+$`)
+	uuidPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	refKeyPattern  = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+	secretLineExpr = regexp.MustCompile(`(?i)^\s*([a-z0-9_-]+)\s*[=:]\s*\S`)
+)
+
+const (
+	minTokenLength  = 20
+	minTokenEntropy = 3.5
+)
+
+// ScanSecrets finds the values in the config that look like secrets: fields
+// named like one, and long random-looking strings. Values that are already
+// references are skipped, as is the setup state.
+func ScanSecrets(cfg *Config) ([]SecretFinding, error) {
+	tree, err := configTree(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []SecretFinding
+	walkTree(tree, nil, func(path []string, value string) {
+		if path[0] == "setup_state" {
+			return
+		}
+		if reason := secretReason(fieldName(path), value); reason != "" {
+			refPath, refKey := suggestRef(path)
+			findings = append(findings, SecretFinding{
+				Field:  fieldPath(path),
+				Value:  value,
+				Reason: reason,
+				Path:   refPath,
+				Key:    refKey,
+			})
+		}
+	})
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findingRank(findings[i]) < findingRank(findings[j])
+	})
+	shared := map[string]SecretFinding{}
+	for i, f := range findings {
+		if first, ok := shared[f.Value]; ok {
+			findings[i].Path, findings[i].Key = first.Path, first.Key
+			continue
+		}
+		shared[f.Value] = f
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Field < findings[j].Field
+	})
+	return findings, nil
+}
+
+// ReplaceSecrets puts references in place of the values of the given fields,
+// keyed by SecretFinding.Field
+func ReplaceSecrets(cfg *Config, refs map[string]string) error {
+	tree, err := configTree(cfg)
+	if err != nil {
+		return err
+	}
+
+	replaced := 0
+	replaceInTree(tree, nil, func(path []string, value string) string {
+		if ref, ok := refs[fieldPath(path)]; ok {
+			replaced++
+			return ref
+		}
+		return value
+	})
+	if replaced != len(refs) {
+		return fmt.Errorf("%d of %d fields not found in the config", len(refs)-replaced, len(refs))
+	}
+
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	updated, err := LoadFromReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	*cfg = *updated
+	return nil
+}
+
+// configTree returns the config as the generic tree its YAML decodes to
+func configTree(cfg *Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return tree, nil
+}
+
+// walkTree calls fn with the path of every string in a YAML tree, in key
+// order. List items are path segments "[i]".
+func walkTree(v interface{}, path []string, fn func(path []string, value string)) {
+	replaceInTree(v, path, func(path []string, value string) string {
+		fn(path, value)
+		return value
+	})
+}
+
+// replaceInTree is walkTree with fn's result stored in place of each string
+func replaceInTree(v interface{}, path []string, fn func(path []string, value string) string) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := append(append([]string{}, path...), k)
+			if s, ok := t[k].(string); ok {
+				t[k] = fn(p, s)
+				continue
+			}
+			replaceInTree(t[k], p, fn)
+		}
+	case []interface{}:
+		for i, item := range t {
+			p := append(append([]string{}, path...), "["+strconv.Itoa(i)+"]")
+			if s, ok := item.(string); ok {
+				t[i] = fn(p, s)
+				continue
+			}
+			replaceInTree(item, p, fn)
+		}
+	}
+}
+
+// fieldPath joins a path the way SecretFinding.Field shows it
+func fieldPath(path []string) string {
+	return strings.ReplaceAll(strings.Join(path, "."), ".[", "[")
+}
+
+// fieldName is the innermost field name on a path, skipping list indexes
+func fieldName(path []string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(path[i], "[") {
+			return path[i]
+		}
+	}
+	return ""
+}
+
+// secretReason says why a value looks like a secret, or "" if it does not
+func secretReason(field, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || secrets.IsSecretRef(value) || isPlaceholder(value) {
+		return ""
+	}
+	if isSecretFieldName(field) {
+		return "secret field name"
+	}
+	if strings.Contains(value, "\n") {
+		for _, line := range strings.Split(value, "\n") {
+			if m := secretLineExpr.FindStringSubmatch(line); m != nil && isSecretFieldName(m[1]) {
+				return "contains " + m[1]
+			}
+		}
+		return ""
+	}
+	if isRandomToken(value) {
+		return "high entropy"
+	}
+	return ""
+}
+
+func isSecretFieldName(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	if name == "secret" {
+		return true
+	}
+	for _, suffix := range secretFieldNames {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlaceholder reports whether a value is a "<fill me in>" placeholder
+func isPlaceholder(value string) bool {
+	return strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">")
+}
+
+// isRandomToken reports whether a value looks like a generated key: long,
+// mixing letters and digits, without the dots and colons of host names,
+// addresses and digests, and close to random
+func isRandomToken(value string) bool {
+	if len(value) < minTokenLength || !tokenPattern.MatchString(value) || uuidPattern.MatchString(value) {
+		return false
+	}
+	if !strings.ContainsAny(value, "0123456789") || strings.IndexFunc(value, isLetter) < 0 {
+		return false
+	}
+	return shannonEntropy(value) >= minTokenEntropy
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// shannonEntropy is the entropy of a string in bits per character
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// suggestRef picks the OpenBAO path and key for a value: a component's
+// secrets go under the component's name, e.g. components.zot.docker_hub_password
+// becomes zot:docker_hub_password, and others under the field's parent, e.g.
+// storage.truenas.api_key becomes truenas:api_key
+func suggestRef(path []string) (string, string) {
+	var names []string
+	for _, p := range path {
+		names = append(names, strings.Trim(p, "[]"))
+	}
+	if names[0] == "components" && len(names) > 2 {
+		return refKey(names[1]), refKey(strings.Join(names[2:], "_"))
+	}
+	if len(names) == 1 {
+		return "config", refKey(names[0])
+	}
+	return refKey(names[len(names)-2]), refKey(names[len(names)-1])
+}
+
+func refKey(s string) string {
+	return refKeyPattern.ReplaceAllString(s, "_")
+}
+
+// findingRank orders findings so the one whose reference is shared comes
+// first: canonical fields, then the shallowest
+func findingRank(f SecretFinding) string {
+	for i, field := range canonicalSecretFields {
+		if f.Field == field {
+			return fmt.Sprintf("0%02d", i)
+		}
+	}
+	return fmt.Sprintf("1%02d%s", strings.Count(f.Field, "."), f.Field)
+}
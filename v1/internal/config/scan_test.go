@@ -0,0 +1,138 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	scanAccessKey = "4f9c2b7e1a8d3f6c0b5e9a2d7c4f1b8e"
+	scanSecretKey = "d81f3a6c9e2b5d0f7a4c1e8b3d6f9a2c5e0b7d4f1a8c3e6b"
+)
+
+func scanConfig() *Config {
+	return &Config{
+		Cluster: ClusterConfig{Name: "test", PrimaryDomain: "example.com"},
+		DNS: &DNSConfig{
+			Backend: "sqlite",
+			APIKey:  "${secret:dns:api_key}",
+		},
+		Storage: &StorageConfig{
+			Backend: "truenas",
+			TrueNAS: &TrueNASConfig{APIURL: "https://truenas.example.com", APIKey: "1-abcdefghij"},
+		},
+		Components: ComponentMap{
+			"seaweedfs": ComponentConfig{Config: map[string]any{
+				"namespace":  "seaweedfs",
+				"access_key": scanAccessKey,
+				"secret_key": scanSecretKey,
+			}},
+			"loki": ComponentConfig{Config: map[string]any{
+				"s3_access_key": scanAccessKey,
+				"s3_endpoint":   "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333",
+				"values": map[string]any{
+					"existingSecret": "loki-credentials",
+					"image":          map[string]any{"digest": "sha256:0b5e9a2d7c4f1b8e4f9c2b7e1a8d3f6c0b5e9a2d7c4f1b8e"},
+					"extraArgs":      []any{"-token=x", "b9Qz7Lm2Xv4Rt8Kp1Wn6Ys3Hd5Jf0Gc"},
+				},
+			}},
+			"velero": ComponentConfig{Config: map[string]any{
+				"credentials": "[default]\naws_access_key_id=abc\naws_secret_access_key=def\n",
+				"schedule":    "0 2 * * *",
+			}},
+			"zot": ComponentConfig{Config: map[string]any{
+				"docker_hub_username": "foundry",
+				"docker_hub_password": "<your-token>",
+				"id":                  "7d0c6b8e-2f4a-4c1e-9b3d-5a6f8e0c2d4b",
+			}},
+		},
+	}
+}
+
+func findingsByField(findings []SecretFinding) map[string]SecretFinding {
+	byField := map[string]SecretFinding{}
+	for _, f := range findings {
+		byField[f.Field] = f
+	}
+	return byField
+}
+
+func TestScanSecrets(t *testing.T) {
+	findings, err := ScanSecrets(scanConfig())
+	require.NoError(t, err)
+
+	var fields []string
+	for _, f := range findings {
+		fields = append(fields, f.Field)
+	}
+	assert.Equal(t, []string{
+		"components.loki.s3_access_key",
+		"components.loki.values.extraArgs[1]",
+		"components.seaweedfs.access_key",
+		"components.seaweedfs.secret_key",
+		"components.velero.credentials",
+		"storage.truenas.api_key",
+	}, fields)
+
+	byField := findingsByField(findings)
+	assert.Equal(t, "secret field name", byField["components.seaweedfs.access_key"].Reason)
+	assert.Equal(t, "high entropy", byField["components.loki.values.extraArgs[1]"].Reason)
+	assert.Equal(t, "contains aws_access_key_id", byField["components.velero.credentials"].Reason)
+
+	assert.Equal(t, "${secret:seaweedfs:access_key}", byField["components.seaweedfs.access_key"].Ref())
+	assert.Equal(t, "${secret:seaweedfs:access_key}", byField["components.loki.s3_access_key"].Ref(),
+		"a shared value keeps the reference of its canonical field")
+	assert.Equal(t, "${secret:loki:values_extraArgs_1}", byField["components.loki.values.extraArgs[1]"].Ref())
+	assert.Equal(t, "${secret:truenas:api_key}", byField["storage.truenas.api_key"].Ref())
+}
+
+func TestIsSecretFieldName(t *testing.T) {
+	for _, name := range []string{"password", "admin_password", "secret", "secret_key", "secretAccessKey", "accessKeyId", "api-key", "token", "client_secret"} {
+		assert.True(t, isSecretFieldName(name), name)
+	}
+	for _, name := range []string{"existingSecret", "tlsSecretName", "secret_path", "username", "endpoint"} {
+		assert.False(t, isSecretFieldName(name), name)
+	}
+}
+
+func TestIsRandomToken(t *testing.T) {
+	assert.True(t, isRandomToken(scanAccessKey))
+	assert.True(t, isRandomToken("b9Qz7Lm2Xv4Rt8Kp1Wn6Ys3Hd5Jf0Gc"))
+	assert.False(t, isRandomToken("short1"))
+	assert.False(t, isRandomToken("seaweedfs-s3.seaweedfs.svc.cluster.local"))
+	assert.False(t, isRandomToken("sha256:0b5e9a2d7c4f1b8e4f9c2b7e1a8d3f6c"))
+	assert.False(t, isRandomToken("7d0c6b8e-2f4a-4c1e-9b3d-5a6f8e0c2d4b"))
+	assert.False(t, isRandomToken("aaaaaaaaaaaaaaaaaaaaaaaa1"))
+	assert.False(t, isRandomToken("kube-prometheus-stack-operator"))
+}
+
+func TestReplaceSecrets(t *testing.T) {
+	cfg := scanConfig()
+	findings, err := ScanSecrets(cfg)
+	require.NoError(t, err)
+
+	refs := map[string]string{}
+	for _, f := range findings {
+		refs[f.Field] = f.Ref()
+	}
+	require.NoError(t, ReplaceSecrets(cfg, refs))
+
+	assert.Equal(t, "${secret:seaweedfs:access_key}", cfg.Components["seaweedfs"].Config["access_key"])
+	assert.Equal(t, "${secret:seaweedfs:secret_key}", cfg.Components["seaweedfs"].Config["secret_key"])
+	assert.Equal(t, "${secret:seaweedfs:access_key}", cfg.Components["loki"].Config["s3_access_key"])
+	assert.Equal(t, "${secret:velero:credentials}", cfg.Components["velero"].Config["credentials"])
+	assert.Equal(t, "${secret:truenas:api_key}", cfg.Storage.TrueNAS.APIKey)
+	values := cfg.Components["loki"].Config["values"].(map[string]any)
+	assert.Equal(t, []any{"-token=x", "${secret:loki:values_extraArgs_1}"}, values["extraArgs"])
+	assert.Equal(t, "loki-credentials", values["existingSecret"])
+	assert.Equal(t, "foundry", cfg.Components["zot"].Config["docker_hub_username"])
+
+	findings, err = ScanSecrets(cfg)
+	require.NoError(t, err)
+	assert.Empty(t, findings, "a rewritten config has nothing left to migrate")
+
+	err = ReplaceSecrets(cfg, map[string]string{"components.missing.key": "${secret:missing:key}"})
+	assert.ErrorContains(t, err, "not found")
+}
@@ -95,8 +95,23 @@ type ManagementConfig struct {
 
 // TrueNASConfig represents a structured data type
 type TrueNASConfig struct {
-	APIURL string `json:"api_url" yaml:"api_url"`
-	APIKey string `json:"api_key" yaml:"api_key"`
+	APIURL        string   `json:"api_url" yaml:"api_url"`
+	APIKey        string   `json:"api_key" yaml:"api_key"`
+	Pool          *string  `json:"pool,omitempty" yaml:"pool,omitempty"`
+	ParentDataset *string  `json:"parent_dataset,omitempty" yaml:"parent_dataset,omitempty"`
+	NFSServer     *string  `json:"nfs_server,omitempty" yaml:"nfs_server,omitempty"`
+	ISCSIPortal   *string  `json:"iscsi_portal,omitempty" yaml:"iscsi_portal,omitempty"`
+	Drivers       []string `json:"drivers,omitempty" yaml:"drivers,omitempty"`
+	AllowInsecure *bool    `json:"allow_insecure,omitempty" yaml:"allow_insecure,omitempty"`
+}
+
+// HostTLSConfig represents a structured data type
+type HostTLSConfig struct {
+	Issuer      string  `json:"issuer" yaml:"issuer"`
+	ACMEEmail   *string `json:"acme_email,omitempty" yaml:"acme_email,omitempty"`
+	ACMEServer  *string `json:"acme_server,omitempty" yaml:"acme_server,omitempty"`
+	Validity    *string `json:"validity,omitempty" yaml:"validity,omitempty"`
+	RenewBefore *string `json:"renew_before,omitempty" yaml:"renew_before,omitempty"`
 }
 
 // Config represents a structured data type
@@ -108,6 +123,7 @@ type Config struct {
 	Observability *ObsConfig        `json:"observability,omitempty" yaml:"observability,omitempty"`
 	Storage       *StorageConfig    `json:"storage,omitempty" yaml:"storage,omitempty"`
 	Management    *ManagementConfig `json:"management,omitempty" yaml:"management,omitempty"`
+	HostTLS       *HostTLSConfig    `json:"host_tls,omitempty" yaml:"host_tls,omitempty"`
 	Hosts         []*host.Host      `json:"hosts" yaml:"hosts"`
 	SetupState    *setup.SetupState `json:"setup_state" yaml:"setup_state"`
 }
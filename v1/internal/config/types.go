@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/catalystcommunity/foundry/v1/internal/host"
 )
@@ -84,6 +85,12 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.HostTLS != nil {
+		if err := c.HostTLS.Validate(); err != nil {
+			return fmt.Errorf("host_tls validation failed: %w", err)
+		}
+	}
+
 	// Cross-validation: K8s VIP must be unique (not in any host list).
 	// This depends only on c.Hosts, so it applies whether or not a network
 	// block is present - a VIP that collides with a host IP breaks kube-vip
@@ -144,6 +151,41 @@ func (m *ManagementConfig) Validate(hosts []*host.Host) error {
 	return fmt.Errorf("host %q does not exist in hosts", m.Host)
 }
 
+// Host TLS issuers
+const (
+	HostTLSIssuerInternalCA = "internal-ca"
+	HostTLSIssuerACME       = "acme"
+)
+
+// Validate checks the host service TLS configuration.
+func (h *HostTLSConfig) Validate() error {
+	switch h.Issuer {
+	case HostTLSIssuerInternalCA:
+	case HostTLSIssuerACME:
+		if h.ACMEEmail == nil || strings.TrimSpace(*h.ACMEEmail) == "" {
+			return fmt.Errorf("acme_email is required when issuer is %q", HostTLSIssuerACME)
+		}
+	default:
+		return fmt.Errorf("issuer must be %q or %q, got %q", HostTLSIssuerInternalCA, HostTLSIssuerACME, h.Issuer)
+	}
+
+	durations := map[string]*string{"validity": h.Validity, "renew_before": h.RenewBefore}
+	for name, value := range durations {
+		if value == nil {
+			continue
+		}
+		d, err := time.ParseDuration(*value)
+		if err != nil {
+			return fmt.Errorf("%s %q is not a valid duration: %w", name, *value, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+
+	return nil
+}
+
 // validateK8sVIPUniqueness ensures the K8s VIP is not used by any infrastructure host
 func (c *Config) validateK8sVIPUniqueness() error {
 	vip := c.Cluster.VIP
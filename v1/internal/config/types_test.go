@@ -79,6 +79,36 @@ func TestManagementConfigValidation(t *testing.T) {
 	assert.Contains(t, err.Error(), "does not exist")
 }
 
+func TestHostTLSConfigValidation(t *testing.T) {
+	email := "ops@example.com"
+	badDuration := "30 days"
+
+	tests := []struct {
+		name   string
+		cfg    HostTLSConfig
+		errMsg string
+	}{
+		{name: "internal ca", cfg: HostTLSConfig{Issuer: HostTLSIssuerInternalCA}},
+		{name: "acme with email", cfg: HostTLSConfig{Issuer: HostTLSIssuerACME, ACMEEmail: &email}},
+		{name: "acme without email", cfg: HostTLSConfig{Issuer: HostTLSIssuerACME}, errMsg: "acme_email is required"},
+		{name: "unknown issuer", cfg: HostTLSConfig{Issuer: "vault"}, errMsg: "issuer must be"},
+		{name: "bad renew_before", cfg: HostTLSConfig{Issuer: HostTLSIssuerInternalCA, RenewBefore: &badDuration}, errMsg: "renew_before"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Components: ComponentMap{"k3s": {}}, HostTLS: &tt.cfg}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
 // NOTE: TestClusterConfig_Validate removed - ClusterConfig.Validate() no longer exists
 // Cluster validation moved to role-based host validation in host package
 
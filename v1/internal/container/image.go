@@ -0,0 +1,46 @@
+package container
+
+import (
+	"github.com/distribution/reference"
+)
+
+// PinImage returns image pinned to the digest digests records for it, as
+// "name:tag@sha256:...". digests is keyed by fully qualified reference
+// ("docker.io/library/busybox:1.36"). Images without an entry, and images
+// that already carry a digest, are returned unchanged.
+func PinImage(image string, digests map[string]string) string {
+	if len(digests) == 0 {
+		return image
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return image
+	}
+	digest, ok := digests[reference.TagNameOnly(named).String()]
+	if !ok {
+		return image
+	}
+	return image + "@" + digest
+}
+
+// ParseImageDigests reads an image_digests config value, which is a
+// map[string]string when set in code and a map[string]interface{} when read
+// from YAML
+func ParseImageDigests(raw interface{}) map[string]string {
+	switch v := raw.(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		digests := make(map[string]string, len(v))
+		for ref, digest := range v {
+			if s, ok := digest.(string); ok {
+				digests[ref] = s
+			}
+		}
+		return digests
+	}
+	return nil
+}
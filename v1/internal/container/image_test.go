@@ -0,0 +1,40 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinImage(t *testing.T) {
+	digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	digests := map[string]string{
+		"quay.io/openbao/openbao:2.0.0":          digest,
+		"docker.io/powerdns/pdns-auth-49:latest": digest,
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{"fully qualified", "quay.io/openbao/openbao:2.0.0", "quay.io/openbao/openbao:2.0.0@" + digest},
+		{"docker hub short name", "powerdns/pdns-auth-49:latest", "powerdns/pdns-auth-49:latest@" + digest},
+		{"not locked", "quay.io/openbao/openbao:2.1.0", "quay.io/openbao/openbao:2.1.0"},
+		{"already pinned", "quay.io/openbao/openbao:2.0.0@" + digest, "quay.io/openbao/openbao:2.0.0@" + digest},
+		{"invalid", "Not An Image", "Not An Image"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PinImage(tt.image, digests))
+		})
+	}
+
+	assert.Equal(t, "busybox:1.36", PinImage("busybox:1.36", nil))
+}
+
+func TestParseImageDigests(t *testing.T) {
+	assert.Equal(t, map[string]string{"a": "b"}, ParseImageDigests(map[string]string{"a": "b"}))
+	assert.Equal(t, map[string]string{"a": "b"}, ParseImageDigests(map[string]interface{}{"a": "b", "c": 1}))
+	assert.Nil(t, ParseImageDigests("nope"))
+}
@@ -36,6 +36,26 @@ const (
 	CNIConfigDir  = "/etc/cni/net.d"
 )
 
+// Runtime tool downloads. An offline bundle carries the same files and stages
+// them in StagedToolsDir, where they take precedence over the download.
+const (
+	CNIPluginsURL  = "https://github.com/containernetworking/plugins/releases/download/v1.4.0/cni-plugins-linux-amd64-v1.4.0.tgz"
+	NerdctlURL     = "https://github.com/containerd/nerdctl/releases/download/v1.7.2/nerdctl-1.7.2-linux-amd64.tar.gz"
+	StagedToolsDir = "/var/lib/foundry/bundle/tools"
+)
+
+// StagedToolPath returns where an offline bundle stages the file behind url
+func StagedToolPath(url string) string {
+	return StagedToolsDir + "/" + url[strings.LastIndex(url, "/")+1:]
+}
+
+// fetchToolCommand copies a staged tool to dest, downloading it when no
+// bundle staged it
+func fetchToolCommand(url, dest string) string {
+	staged := StagedToolPath(url)
+	return fmt.Sprintf("if [ -f %s ]; then cp %s %s; else curl -fsSL %s -o %s; fi", staged, staged, dest, url, dest)
+}
+
 // BridgeNetworkingServices lists services that use bridge networking with port mapping
 // These services need to be restarted when CNI configuration is added
 var BridgeNetworkingServices = []string{"openbao", "foundry-zot"}
@@ -167,7 +187,7 @@ func IsDockerAvailable(executor CommandExecutor) bool {
 func installCNIPlugins(executor CommandExecutor) error {
 	commands := []string{
 		// Download and install CNI plugins
-		"curl -fsSL https://github.com/containernetworking/plugins/releases/download/v1.4.0/cni-plugins-linux-amd64-v1.4.0.tgz -o /tmp/cni-plugins.tgz",
+		fetchToolCommand(CNIPluginsURL, "/tmp/cni-plugins.tgz"),
 		"sudo mkdir -p /opt/cni/bin",
 		"sudo tar Cxzf /opt/cni/bin /tmp/cni-plugins.tgz",
 		"rm /tmp/cni-plugins.tgz",
@@ -236,13 +256,13 @@ CONTAINERD_CONFIG`,
 		"sudo systemctl restart containerd",
 
 		// Download and install CNI plugins (required by nerdctl for networking)
-		"curl -fsSL https://github.com/containernetworking/plugins/releases/download/v1.4.0/cni-plugins-linux-amd64-v1.4.0.tgz -o /tmp/cni-plugins.tgz",
+		fetchToolCommand(CNIPluginsURL, "/tmp/cni-plugins.tgz"),
 		"sudo mkdir -p /opt/cni/bin",
 		"sudo tar Cxzf /opt/cni/bin /tmp/cni-plugins.tgz",
 		"rm /tmp/cni-plugins.tgz",
 
 		// Download and install nerdctl
-		"curl -fsSL https://github.com/containerd/nerdctl/releases/download/v1.7.2/nerdctl-1.7.2-linux-amd64.tar.gz -o /tmp/nerdctl.tar.gz",
+		fetchToolCommand(NerdctlURL, "/tmp/nerdctl.tar.gz"),
 		"sudo tar Cxzf /usr/local/bin /tmp/nerdctl.tar.gz",
 		"rm /tmp/nerdctl.tar.gz",
 
@@ -228,3 +228,12 @@ func TestDetectRuntimeInstallation_None(t *testing.T) {
 	runtimeType := DetectRuntimeInstallation(mock)
 	assert.Equal(t, RuntimeNone, runtimeType)
 }
+
+func TestFetchToolCommand_PrefersStagedFile(t *testing.T) {
+	assert.Equal(t, "/var/lib/foundry/bundle/tools/nerdctl-1.7.2-linux-amd64.tar.gz", StagedToolPath(NerdctlURL))
+
+	cmd := fetchToolCommand(NerdctlURL, "/tmp/nerdctl.tar.gz")
+	assert.Equal(t, "if [ -f /var/lib/foundry/bundle/tools/nerdctl-1.7.2-linux-amd64.tar.gz ]; then "+
+		"cp /var/lib/foundry/bundle/tools/nerdctl-1.7.2-linux-amd64.tar.gz /tmp/nerdctl.tar.gz; "+
+		"else curl -fsSL "+NerdctlURL+" -o /tmp/nerdctl.tar.gz; fi", cmd)
+}
@@ -33,7 +33,12 @@ func (d *DockerRuntime) IsAvailable() bool {
 }
 
 func (d *DockerRuntime) Pull(image string) error {
-	_, err := d.conn.Execute(fmt.Sprintf("sudo docker pull %s", image))
+	_, err := d.conn.Execute(fmt.Sprintf("sudo docker pull %s || sudo docker image inspect %s > /dev/null", image, image))
+	return err
+}
+
+func (d *DockerRuntime) Load(archivePath string) error {
+	_, err := d.conn.Execute(fmt.Sprintf("sudo docker load -i %s", archivePath))
 	return err
 }
 
@@ -248,7 +253,12 @@ func (p *PodmanRuntime) IsAvailable() bool {
 }
 
 func (p *PodmanRuntime) Pull(image string) error {
-	_, err := p.conn.Execute(fmt.Sprintf("sudo podman pull %s", image))
+	_, err := p.conn.Execute(fmt.Sprintf("sudo podman pull %s || sudo podman image inspect %s > /dev/null", image, image))
+	return err
+}
+
+func (p *PodmanRuntime) Load(archivePath string) error {
+	_, err := p.conn.Execute(fmt.Sprintf("sudo podman load -i %s", archivePath))
 	return err
 }
 
@@ -85,6 +85,27 @@ func TestDockerRuntime_Pull(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDockerRuntime_Pull_FallsBackToLocalImage(t *testing.T) {
+	mock := newMockSSHExecutor()
+	runtime := NewDockerRuntime(mock)
+
+	mock.setCommand("sudo docker pull ghcr.io/project-zot/zot:v2.1.0 || sudo docker image inspect ghcr.io/project-zot/zot:v2.1.0 > /dev/null", "")
+
+	assert.NoError(t, runtime.Pull("ghcr.io/project-zot/zot:v2.1.0"))
+}
+
+func TestDockerRuntime_Load(t *testing.T) {
+	mock := newMockSSHExecutor()
+	runtime := NewDockerRuntime(mock)
+
+	mock.setCommand("sudo docker load -i /var/lib/foundry/bundle/images/zot.tar", "Loaded image: ghcr.io/project-zot/zot:v2.1.0")
+	assert.NoError(t, runtime.Load("/var/lib/foundry/bundle/images/zot.tar"))
+
+	mock.setError("sudo docker load -i /missing.tar", fmt.Errorf("no such file"))
+	mock.setCommand("sudo docker load -i /missing.tar", "")
+	assert.Error(t, runtime.Load("/missing.tar"))
+}
+
 func TestDockerRuntime_Pull_Error(t *testing.T) {
 	mock := newMockSSHExecutor()
 	runtime := NewDockerRuntime(mock)
@@ -9,9 +9,14 @@ type Runtime interface {
 	// Name returns the name of the runtime (e.g., "docker", "podman")
 	Name() string
 
-	// Pull pulls a container image
+	// Pull pulls a container image. An image already present locally (for
+	// example loaded from an offline bundle) satisfies the pull when the
+	// registry cannot be reached.
 	Pull(image string) error
 
+	// Load imports images from an OCI or Docker archive on the host
+	Load(archivePath string) error
+
 	// Run runs a container with the given configuration
 	// Returns the container ID
 	Run(config RunConfig) (string, error)
@@ -46,6 +46,7 @@ type File struct {
 	Source   string // "default" or "user"
 	Data     []byte
 	Title    string
+	UID      string // the dashboard's "uid", if it sets one
 	Valid    bool
 	Err      string
 }
@@ -160,6 +161,7 @@ func readDir(path, source string) ([]File, error) {
 		} else {
 			df.Valid = true
 			df.Title = title
+			df.UID = dashboardUID(data)
 		}
 		out = append(out, df)
 	}
@@ -179,6 +181,15 @@ func validate(data []byte) (string, error) {
 	return title, nil
 }
 
+// dashboardUID returns the "uid" of a dashboard, or "" if it has none.
+func dashboardUID(data []byte) string {
+	var m struct {
+		UID string `json:"uid"`
+	}
+	_ = json.Unmarshal(data, &m)
+	return m.UID
+}
+
 // Apply creates/updates one ConfigMap per dashboard and, when prune is set, removes
 // foundry-managed dashboard ConfigMaps that are no longer present. Progress is
 // printed to stdout.
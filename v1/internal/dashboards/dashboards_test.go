@@ -57,7 +57,7 @@ func TestSeedDefaultsAndCollect(t *testing.T) {
 
 	n, err := SeedDefaults(dir)
 	require.NoError(t, err)
-	assert.GreaterOrEqual(t, n, 3, "expected the bundled default dashboards to be seeded")
+	assert.GreaterOrEqual(t, n, 4, "expected the bundled default dashboards to be seeded")
 
 	files, err := Collect(dir)
 	require.NoError(t, err)
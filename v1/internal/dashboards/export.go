@@ -0,0 +1,130 @@
+package dashboards
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// volatileFields are set by Grafana on every save, so they are dropped from
+// exported dashboards and ignored when comparing.
+var volatileFields = []string{"id", "version", "iteration"}
+
+// Normalize strips the fields Grafana manages from a dashboard and formats it
+// with sorted keys and two-space indentation, so that copies of the same
+// dashboard are byte-identical.
+func Normalize(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	for _, field := range volatileFields {
+		delete(m, field)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Select filters dashboards by folder title (case-insensitive, "General" for
+// the root folder) and uid. Empty filters match everything.
+func Select(list []Summary, folder string, uids []string) []Summary {
+	wanted := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		wanted[uid] = true
+	}
+	var out []Summary
+	for _, s := range list {
+		if folder != "" && !strings.EqualFold(s.Folder(), folder) {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[s.UID] {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// ByUID indexes the valid files that carry a uid.
+func ByUID(files []File) map[string]File {
+	out := make(map[string]File, len(files))
+	for _, f := range files {
+		if f.Valid && f.UID != "" {
+			out[f.UID] = f
+		}
+	}
+	return out
+}
+
+// ExportPath returns where a live dashboard is written in dir: over the user
+// file that already has its uid, else <slug>.json (suffixed with the uid if
+// another dashboard has that name). managed is true when the uid belongs to a
+// bundled default, which every sync refreshes, so exporting over it would be
+// lost.
+func ExportPath(dir string, files []File, d *Dashboard) (path string, managed bool) {
+	if f, ok := ByUID(files)[d.UID]; ok {
+		if f.Source == "default" {
+			return filepath.Join(dir, DefaultsSubdir, f.FileName), true
+		}
+		return filepath.Join(dir, f.FileName), false
+	}
+
+	base := sanitizeName(d.Slug)
+	if d.Slug == "" {
+		base = sanitizeName(d.Title)
+	}
+	for _, f := range files {
+		if f.Source == "user" && f.Base == base {
+			base += "-" + sanitizeName(d.UID)
+			break
+		}
+	}
+	return filepath.Join(dir, base+".json"), false
+}
+
+// ProvisionedElsewhere reports whether a live dashboard was provisioned by
+// something other than the dashboards directory, such as a chart's ConfigMap:
+// Grafana marks it provisioned but no user file has its uid.
+func ProvisionedElsewhere(files []File, d *Dashboard) bool {
+	if !d.Provisioned {
+		return false
+	}
+	f, ok := ByUID(files)[d.UID]
+	return !ok || f.Source != "user"
+}
+
+// Diff returns a unified diff from the on-disk copy of a dashboard to the
+// live one, both normalized, or "" when they match.
+func Diff(name string, onDisk, live []byte) (string, error) {
+	a, err := Normalize(onDisk)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	b, err := Normalize(live)
+	if err != nil {
+		return "", fmt.Errorf("live %s: %w", name, err)
+	}
+	if bytes.Equal(a, b) {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: "disk/" + name,
+		ToFile:   "live/" + name,
+		Context:  3,
+	})
+}
@@ -0,0 +1,153 @@
+package dashboards
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	out, err := Normalize([]byte(`{"title":"API","id":42,"version":7,"iteration":1700000000000,"uid":"api","panels":[{"id":1,"expr":"a > 1"}],"refresh":"30s"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "panels": [
+    {
+      "expr": "a > 1",
+      "id": 1
+    }
+  ],
+  "refresh": "30s",
+  "title": "API",
+  "uid": "api"
+}
+`, string(out), "volatile fields are stripped, keys sorted, panel ids kept and HTML not escaped")
+
+	again, err := Normalize(out)
+	require.NoError(t, err)
+	assert.Equal(t, out, again, "normalizing is idempotent")
+
+	_, err = Normalize([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestSelect(t *testing.T) {
+	list := []Summary{
+		{UID: "a", Title: "A"},
+		{UID: "b", Title: "B", FolderTitle: "Apps"},
+		{UID: "c", Title: "C", FolderTitle: "Apps"},
+	}
+
+	assert.Len(t, Select(list, "", nil), 3)
+	assert.Equal(t, []Summary{list[0]}, Select(list, "general", nil))
+	assert.Equal(t, []Summary{list[1], list[2]}, Select(list, "apps", nil))
+	assert.Equal(t, []Summary{list[2]}, Select(list, "Apps", []string{"a", "c"}))
+	assert.Empty(t, Select(list, "Other", nil))
+}
+
+func TestExportPath(t *testing.T) {
+	files := []File{
+		{FileName: "host-services.json", Base: "host-services", Source: "default", UID: "foundry-host-services", Valid: true},
+		{FileName: "my-api.json", Base: "my-api", Source: "user", UID: "api", Valid: true},
+		{FileName: "orders.json", Base: "orders", Source: "user", UID: "orders-v1", Valid: true},
+	}
+
+	path, managed := ExportPath("/d", files, &Dashboard{Summary: Summary{UID: "api", Title: "API"}, Slug: "api"})
+	assert.Equal(t, "/d/my-api.json", path, "an existing file keeps its name")
+	assert.False(t, managed)
+
+	path, managed = ExportPath("/d", files, &Dashboard{Summary: Summary{UID: "foundry-host-services"}})
+	assert.Equal(t, "/d/defaults/host-services.json", path)
+	assert.True(t, managed)
+
+	path, _ = ExportPath("/d", files, &Dashboard{Summary: Summary{UID: "new", Title: "New One"}, Slug: "new-one"})
+	assert.Equal(t, "/d/new-one.json", path)
+
+	path, _ = ExportPath("/d", files, &Dashboard{Summary: Summary{UID: "orders-v2", Title: "Orders"}, Slug: "orders"})
+	assert.Equal(t, "/d/orders-orders-v2.json", path, "a name taken by another uid gets the uid appended")
+
+	path, _ = ExportPath("/d", files, &Dashboard{Summary: Summary{UID: "x", Title: "No Slug"}})
+	assert.Equal(t, "/d/no-slug.json", path)
+}
+
+func TestProvisionedElsewhere(t *testing.T) {
+	files := []File{
+		{FileName: "my-api.json", Base: "my-api", Source: "user", UID: "api", Valid: true},
+	}
+
+	assert.True(t, ProvisionedElsewhere(files, &Dashboard{Summary: Summary{UID: "k8s-resources-pod"}, Provisioned: true}))
+	assert.False(t, ProvisionedElsewhere(files, &Dashboard{Summary: Summary{UID: "api"}, Provisioned: true}),
+		"a synced user file is provisioned from the dashboards directory")
+	assert.False(t, ProvisionedElsewhere(files, &Dashboard{Summary: Summary{UID: "ui-built"}}))
+}
+
+func TestDiff(t *testing.T) {
+	diff, err := Diff("api.json", []byte(`{"title":"API","uid":"api"}`), []byte(`{"uid":"api","id":3,"version":9,"title":"API"}`))
+	require.NoError(t, err)
+	assert.Empty(t, diff, "id, version and key order are ignored")
+
+	diff, err = Diff("api.json", []byte(`{"title":"API","uid":"api"}`), []byte(`{"title":"API v2","uid":"api"}`))
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- disk/api.json")
+	assert.Contains(t, diff, "+++ live/api.json")
+	assert.Contains(t, diff, `-  "title": "API",`)
+	assert.Contains(t, diff, `+  "title": "API v2",`)
+}
+
+func TestCollect_UID(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.json"), []byte(`{"title":"API","uid":"api"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nouid.json"), []byte(`{"title":"No uid"}`), 0o644))
+
+	files, err := Collect(dir)
+	require.NoError(t, err)
+	byUID := ByUID(files)
+	assert.Len(t, byUID, 1)
+	assert.Equal(t, "api.json", byUID["api"].FileName)
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "secret" {
+			http.Error(w, `{"message":"invalid username or password"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/search":
+			assert.Equal(t, "dash-db", r.URL.Query().Get("type"))
+			w.Write([]byte(`[{"uid":"api","title":"API","folderTitle":"Apps","type":"dash-db"},{"uid":"home","title":"Home","type":"dash-db"}]`))
+		case "/api/dashboards/uid/api":
+			w.Write([]byte(`{"dashboard":{"id":3,"uid":"api","title":"API","version":4},"meta":{"slug":"api","folderTitle":"Apps","provisioned":true}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "admin", "secret", nil)
+
+	list, err := client.Search(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "Apps", list[0].Folder())
+	assert.Equal(t, "General", list[1].Folder())
+
+	d, err := client.Dashboard(context.Background(), "api")
+	require.NoError(t, err)
+	assert.Equal(t, "API", d.Title)
+	assert.Equal(t, "api", d.Slug)
+	assert.True(t, d.Provisioned)
+	assert.JSONEq(t, `{"id":3,"uid":"api","title":"API","version":4}`, string(d.Data))
+
+	_, err = client.Dashboard(context.Background(), "missing")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = NewClient(server.URL, "admin", "wrong", nil).Search(context.Background())
+	assert.ErrorContains(t, err, "401")
+}
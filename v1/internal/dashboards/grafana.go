@@ -0,0 +1,125 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client reads dashboards from the Grafana HTTP API as the admin user.
+type Client struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+}
+
+// Summary is a dashboard as listed by Grafana's search API.
+type Summary struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	FolderTitle string `json:"folderTitle"`
+}
+
+// Folder returns the dashboard's folder, "General" for the root folder.
+func (s Summary) Folder() string {
+	if s.FolderTitle == "" {
+		return "General"
+	}
+	return s.FolderTitle
+}
+
+// Dashboard is a dashboard's JSON model and metadata, as stored in Grafana.
+type Dashboard struct {
+	Summary
+	// Slug is Grafana's URL-safe form of the title, e.g. "foundry-host-services".
+	Slug string
+	// Provisioned is set for dashboards loaded from files or ConfigMaps.
+	Provisioned bool
+	// Data is the dashboard JSON model.
+	Data json.RawMessage
+}
+
+// NewClient creates a client for the Grafana at baseURL. A nil httpClient
+// uses http.DefaultClient.
+func NewClient(baseURL, username, password string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		username: username,
+		password: password,
+		http:     httpClient,
+	}
+}
+
+// Search lists every dashboard in Grafana.
+func (c *Client) Search(ctx context.Context) ([]Summary, error) {
+	var list []Summary
+	if err := c.get(ctx, "/api/search?type=dash-db&limit=5000", &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Dashboard fetches one dashboard by uid.
+func (c *Client) Dashboard(ctx context.Context, uid string) (*Dashboard, error) {
+	var resp struct {
+		Dashboard json.RawMessage `json:"dashboard"`
+		Meta      struct {
+			Slug        string `json:"slug"`
+			FolderTitle string `json:"folderTitle"`
+			Provisioned bool   `json:"provisioned"`
+		} `json:"meta"`
+	}
+	if err := c.get(ctx, "/api/dashboards/uid/"+url.PathEscape(uid), &resp); err != nil {
+		return nil, err
+	}
+
+	title, err := validate(resp.Dashboard)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard %s: %w", uid, err)
+	}
+	folder := resp.Meta.FolderTitle
+	if folder == "General" {
+		folder = ""
+	}
+	return &Dashboard{
+		Summary:     Summary{UID: uid, Title: title, FolderTitle: folder},
+		Slug:        resp.Meta.Slug,
+		Provisioned: resp.Meta.Provisioned,
+		Data:        resp.Dashboard,
+	}, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Grafana: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Grafana response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Grafana %s returned %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse Grafana response: %w", err)
+	}
+	return nil
+}
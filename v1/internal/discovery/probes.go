@@ -0,0 +1,153 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Prometheus is read through the Kubernetes API server's service proxy, so
+// probe results are available wherever the kubeconfig works.
+const (
+	prometheusNamespace = "monitoring"
+	prometheusService   = "kube-prometheus-stack-prometheus"
+	prometheusPort      = "9090"
+)
+
+// Probe labels set on every target by the blackbox-exporter component. They
+// also select the probe series in Prometheus.
+const (
+	ProbeNameLabel   = "probe_name"
+	ProbeSourceLabel = "probe_source"
+	ProbeModuleLabel = "probe_module"
+)
+
+// ProbeResult is the latest blackbox probe of one target.
+type ProbeResult struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Module string `json:"module"`
+	Target string `json:"target"`
+	Up     bool   `json:"up"`
+	// CertExpiresAt is the earliest certificate expiry the probe saw, for
+	// TLS targets
+	CertExpiresAt *time.Time `json:"cert_expires_at,omitempty"`
+}
+
+// PrometheusSample is one series of a Prometheus instant query
+type PrometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// ProbeResults reads the probe results from Prometheus. It returns nothing,
+// without an error, when Prometheus is not installed.
+func ProbeResults(ctx context.Context, kube kubernetes.Interface) ([]ProbeResult, error) {
+	if installed, err := PrometheusInstalled(ctx, kube); err != nil || !installed {
+		return nil, err
+	}
+	success, err := QueryPrometheus(ctx, kube, fmt.Sprintf(`probe_success{%s!=""}`, ProbeSourceLabel))
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := QueryPrometheus(ctx, kube, fmt.Sprintf(`probe_ssl_earliest_cert_expiry{%s!=""}`, ProbeSourceLabel))
+	if err != nil {
+		return nil, err
+	}
+	return mergeProbeSamples(success, expiry), nil
+}
+
+func mergeProbeSamples(success, expiry []PrometheusSample) []ProbeResult {
+	expiresAt := map[string]time.Time{}
+	for _, sample := range expiry {
+		if value, ok := sample.Float(); ok {
+			expiresAt[probeKey(sample.Metric)] = time.Unix(int64(value), 0).UTC()
+		}
+	}
+	results := make([]ProbeResult, 0, len(success))
+	for _, sample := range success {
+		value, ok := sample.Float()
+		if !ok {
+			continue
+		}
+		result := ProbeResult{
+			Name:   sample.Metric[ProbeNameLabel],
+			Source: sample.Metric[ProbeSourceLabel],
+			Module: sample.Metric[ProbeModuleLabel],
+			Target: sample.Metric["instance"],
+			Up:     value == 1,
+		}
+		if at, ok := expiresAt[probeKey(sample.Metric)]; ok {
+			result.CertExpiresAt = &at
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Target < results[j].Target
+	})
+	return results
+}
+
+func probeKey(metric map[string]string) string {
+	return metric[ProbeModuleLabel] + "|" + metric["instance"]
+}
+
+// Float returns the sample's value
+func (s PrometheusSample) Float() (float64, bool) {
+	if len(s.Value) != 2 {
+		return 0, false
+	}
+	raw, ok := s.Value[1].(string)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	return value, err == nil
+}
+
+// PrometheusInstalled reports whether the stack's Prometheus Service exists
+func PrometheusInstalled(ctx context.Context, kube kubernetes.Interface) (bool, error) {
+	if _, err := kube.CoreV1().Services(prometheusNamespace).Get(ctx, prometheusService, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// QueryPrometheus runs an instant query against the stack's Prometheus
+func QueryPrometheus(ctx context.Context, kube kubernetes.Interface, query string) ([]PrometheusSample, error) {
+	response := kube.CoreV1().Services(prometheusNamespace).ProxyGet("http", prometheusService, prometheusPort, "/api/v1/query", map[string]string{"query": query})
+	if response == nil {
+		return nil, fmt.Errorf("prometheus proxy is unavailable")
+	}
+	body, err := response.DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query prometheus: %w", err)
+	}
+	var result struct {
+		Status string `json:"status"`
+		Data   struct {
+			Result []PrometheusSample `json:"result"`
+		} `json:"data"`
+		Error string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse prometheus response: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", result.Error)
+	}
+	return result.Data.Result, nil
+}
@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestProbeResultsWithoutPrometheus(t *testing.T) {
+	results, err := ProbeResults(context.Background(), kubernetesfake.NewSimpleClientset())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestProbeResultsMergesSuccessAndCertificateExpiry(t *testing.T) {
+	kube := kubernetesfake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: prometheusService, Namespace: prometheusNamespace}})
+	var queries []string
+	kube.PrependProxyReactor("services", func(action k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+		query := action.(k8stesting.ProxyGetAction).GetParams()["query"]
+		queries = append(queries, query)
+		if strings.HasPrefix(query, "probe_ssl_earliest_cert_expiry") {
+			return true, staticResponse(`{"status":"success","data":{"result":[
+				{"metric":{"instance":"https://grafana.example.test","probe_module":"http_2xx","probe_name":"Grafana","probe_source":"HTTPRoute"},"value":[1700000000,"1767225600"]}
+			]}}`), nil
+		}
+		return true, staticResponse(`{"status":"success","data":{"result":[
+			{"metric":{"instance":"https://grafana.example.test","probe_module":"http_2xx","probe_name":"Grafana","probe_source":"HTTPRoute"},"value":[1700000000,"1"]},
+			{"metric":{"instance":"192.0.2.10:5432","probe_module":"tcp_connect","probe_name":"db/postgres","probe_source":"TCPRoute"},"value":[1700000000,"0"]}
+		]}}`), nil
+	})
+
+	results, err := ProbeResults(context.Background(), kube)
+	require.NoError(t, err)
+
+	require.Len(t, queries, 2)
+	assert.Equal(t, `probe_success{probe_source!=""}`, queries[0])
+	require.Len(t, results, 2)
+	assert.Equal(t, "Grafana", results[0].Name)
+	assert.True(t, results[0].Up)
+	require.NotNil(t, results[0].CertExpiresAt)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *results[0].CertExpiresAt)
+	assert.Equal(t, "db/postgres", results[1].Name)
+	assert.Equal(t, "TCPRoute", results[1].Source)
+	assert.Equal(t, "tcp_connect", results[1].Module)
+	assert.False(t, results[1].Up)
+	assert.Nil(t, results[1].CertExpiresAt)
+}
+
+func TestProbeResultsReportsQueryErrors(t *testing.T) {
+	kube := kubernetesfake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: prometheusService, Namespace: prometheusNamespace}})
+	kube.PrependProxyReactor("services", func(k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+		return true, staticResponse(`{"status":"error","error":"parse error"}`), nil
+	})
+
+	_, err := ProbeResults(context.Background(), kube)
+	assert.ErrorContains(t, err, "parse error")
+}
+
+// staticResponse is a proxied response with a fixed body
+type staticResponse string
+
+func (r staticResponse) DoRaw(context.Context) ([]byte, error) { return []byte(r), nil }
+
+func (r staticResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(r))), nil
+}
@@ -38,6 +38,7 @@ type Snapshot struct {
 	ClusterAvailable bool              `json:"cluster_available"`
 	Services         []ServiceLink     `json:"services"`
 	Gateways         []GatewayExposure `json:"gateways"`
+	Probes           []ProbeResult     `json:"probes,omitempty"`
 	Warnings         []string          `json:"warnings,omitempty"`
 }
 
@@ -76,11 +77,13 @@ type Route struct {
 	Backends  []string `json:"backends,omitempty"`
 	Accepted  bool     `json:"accepted"`
 	URLs      []string `json:"urls,omitempty"`
+	// Ports are the Gateway listener ports the route is attached to
+	Ports []int64 `json:"ports,omitempty"`
 }
 
 // Inspect returns configured links even when Kubernetes is not available.
 func Inspect(ctx context.Context, cfg *config.Config, kubeconfigPath string) Snapshot {
-	snapshot := Snapshot{Services: configuredLinks(cfg)}
+	snapshot := Snapshot{Services: ConfiguredLinks(cfg)}
 	kubeconfig, err := os.ReadFile(kubeconfigPath)
 	if err != nil {
 		snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("Cluster discovery is unavailable: %v", err))
@@ -96,7 +99,22 @@ func Inspect(ctx context.Context, cfg *config.Config, kubeconfigPath string) Sna
 
 // InspectClients builds a snapshot from Kubernetes interfaces.
 func InspectClients(ctx context.Context, cfg *config.Config, kube kubernetes.Interface, dyn dynamic.Interface) Snapshot {
-	snapshot := Snapshot{Services: configuredLinks(cfg)}
+	snapshot := InspectCluster(ctx, kube, dyn)
+	snapshot.Services = uniqueLinks(append(ConfiguredLinks(cfg), snapshot.Services...))
+	if snapshot.ClusterAvailable {
+		probes, err := ProbeResults(ctx, kube)
+		if err != nil {
+			snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("Cannot read probe results: %v", err))
+		}
+		snapshot.Probes = probes
+	}
+	return snapshot
+}
+
+// InspectCluster builds a snapshot of what the cluster itself exposes,
+// without the links from the stack configuration.
+func InspectCluster(ctx context.Context, kube kubernetes.Interface, dyn dynamic.Interface) Snapshot {
+	var snapshot Snapshot
 	ingresses, err := kube.NetworkingV1().Ingresses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("Cannot list Ingress resources: %v", err))
@@ -120,7 +138,9 @@ func InspectClients(ctx context.Context, cfg *config.Config, kube kubernetes.Int
 	return snapshot
 }
 
-func configuredLinks(cfg *config.Config) []ServiceLink {
+// ConfiguredLinks returns the service links the stack configuration
+// declares, such as ingress hosts and the OpenBAO and Zot hosts.
+func ConfiguredLinks(cfg *config.Config) []ServiceLink {
 	var links []ServiceLink
 	for name, component := range cfg.Components {
 		settings := component.Config
@@ -275,7 +295,9 @@ func attachRoute(gateways []GatewayExposure, kind string, object *unstructured.U
 		}
 		for i := range gateways {
 			if gateways[i].Name == name && gateways[i].Namespace == namespace {
-				gateways[i].Routes = append(gateways[i].Routes, routeFromObject(kind, object, gateways[i]))
+				route := routeFromObject(kind, object, gateways[i])
+				route.Ports = routePorts(kind, parent, gateways[i])
+				gateways[i].Routes = append(gateways[i].Routes, route)
 			}
 		}
 	}
@@ -325,6 +347,40 @@ func routeFromObject(kind string, object *unstructured.Unstructured, gateway Gat
 	return route
 }
 
+// routePorts resolves the listener ports a parentRef attaches a route to: the
+// explicit port, the named section, or every listener of a matching protocol
+func routePorts(kind string, parent map[string]interface{}, gateway GatewayExposure) []int64 {
+	if port, ok := number(parent["port"]); ok && port > 0 {
+		return []int64{port}
+	}
+	section, _ := parent["sectionName"].(string)
+	var ports []int64
+	for _, listener := range gateway.Listeners {
+		if section != "" {
+			if listener.Name == section {
+				return []int64{listener.Port}
+			}
+			continue
+		}
+		if listenerAccepts(listener.Protocol, kind) {
+			ports = append(ports, listener.Port)
+		}
+	}
+	return ports
+}
+
+func listenerAccepts(protocol, kind string) bool {
+	switch kind {
+	case "HTTPRoute", "GRPCRoute":
+		return protocol == "HTTP" || protocol == "HTTPS"
+	case "TLSRoute":
+		return protocol == "TLS"
+	case "TCPRoute":
+		return protocol == "TCP"
+	}
+	return false
+}
+
 func gatewayScheme(gateway GatewayExposure) string {
 	for _, listener := range gateway.Listeners {
 		if listener.Protocol == "HTTPS" || listener.Port == 443 {
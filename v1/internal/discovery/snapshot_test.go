@@ -102,6 +102,7 @@ func TestInspectClientsReportsIngressGatewayListenersAndRoutes(t *testing.T) {
 	assert.True(t, actualGateway.Routes[0].Accepted)
 	assert.Equal(t, []string{"grafana:80"}, actualGateway.Routes[0].Backends)
 	assert.Equal(t, []string{"https://grafana.example.test"}, actualGateway.Routes[0].URLs)
+	assert.Equal(t, []int64{443}, actualGateway.Routes[0].Ports, "only the HTTPS listener takes HTTPRoutes")
 
 	data, err := json.Marshal(snapshot)
 	require.NoError(t, err)
@@ -173,3 +174,17 @@ func serviceURLs(links []ServiceLink) []string {
 	}
 	return result
 }
+
+func TestRoutePorts(t *testing.T) {
+	gateway := GatewayExposure{Listeners: []Listener{
+		{Name: "https", Protocol: "HTTPS", Port: 443},
+		{Name: "postgres", Protocol: "TCP", Port: 5432},
+		{Name: "otlp-grpc", Protocol: "TCP", Port: 4317},
+	}}
+
+	assert.Equal(t, []int64{4317}, routePorts("TCPRoute", map[string]interface{}{"port": int64(4317)}, gateway))
+	assert.Equal(t, []int64{5432}, routePorts("TCPRoute", map[string]interface{}{"sectionName": "postgres"}, gateway))
+	assert.Equal(t, []int64{5432, 4317}, routePorts("TCPRoute", map[string]interface{}{}, gateway))
+	assert.Equal(t, []int64{443}, routePorts("GRPCRoute", map[string]interface{}{}, gateway))
+	assert.Empty(t, routePorts("TLSRoute", map[string]interface{}{}, gateway))
+}
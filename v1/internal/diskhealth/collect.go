@@ -0,0 +1,302 @@
+package diskhealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/ssh"
+)
+
+const (
+	// DefaultUsageWarning and DefaultUsageCritical are the filesystem usage
+	// percentages at which filesystems are flagged
+	DefaultUsageWarning  = 80
+	DefaultUsageCritical = 90
+)
+
+// Runner runs a command on a host; *ssh.Connection is one
+type Runner interface {
+	Exec(command string) (*ssh.ExecResult, error)
+}
+
+// Thresholds are the filesystem usage percentages to flag
+type Thresholds struct {
+	UsageWarning  float64
+	UsageCritical float64
+}
+
+// DefaultThresholds returns the default usage thresholds
+func DefaultThresholds() Thresholds {
+	return Thresholds{UsageWarning: DefaultUsageWarning, UsageCritical: DefaultUsageCritical}
+}
+
+// Filesystem is one mounted filesystem's usage
+type Filesystem struct {
+	Source     string
+	Type       string
+	Mountpoint string
+	Size       int64
+	Used       int64
+	Available  int64
+	// Disk is the SMART device the filesystem is on, if known
+	Disk   string
+	Health Health
+}
+
+// UsedPercent is the share of the filesystem in use, as df reports it:
+// used over used plus available, so reserved blocks count as full
+func (f Filesystem) UsedPercent() float64 {
+	if f.Used+f.Available == 0 {
+		return 0
+	}
+	return 100 * float64(f.Used) / float64(f.Used+f.Available)
+}
+
+// HostReport is the disks and filesystems of one host
+type HostReport struct {
+	Host        string
+	Disks       []*Disk
+	Filesystems []Filesystem
+	// Errors are problems gathering data, such as smartctl missing
+	Errors []string
+}
+
+// Health is the worst grade of the host's disks and filesystems
+func (r *HostReport) Health() Health {
+	worst := HealthOK
+	if len(r.Errors) > 0 {
+		worst = HealthUnknown
+	}
+	for _, d := range r.Disks {
+		if d.Health > worst {
+			worst = d.Health
+		}
+	}
+	for _, f := range r.Filesystems {
+		if f.Health > worst {
+			worst = f.Health
+		}
+	}
+	return worst
+}
+
+// DiskForPath returns the disk holding a path: the disk of the deepest
+// mountpoint the path is under
+func (r *HostReport) DiskForPath(path string) *Disk {
+	var found *Disk
+	longest := -1
+	for _, d := range r.Disks {
+		for _, mount := range d.Mountpoints {
+			if underMount(path, mount) && len(mount) > longest {
+				found, longest = d, len(mount)
+			}
+		}
+	}
+	return found
+}
+
+// underMount reports whether path is the mountpoint or below it
+func underMount(path, mount string) bool {
+	if mount == "/" || path == mount {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(mount, "/")+"/")
+}
+
+// dfCommand lists real filesystems in bytes; -P keeps each on one line
+const dfCommand = "df -PT -B1 -x tmpfs -x devtmpfs -x overlay -x squashfs 2>/dev/null"
+
+// Collect gathers the SMART data and filesystem usage of a host. Failures
+// are recorded in the report's Errors rather than returned, so one host
+// missing smartctl doesn't hide the rest of the report.
+func Collect(runner Runner, host string, thresholds Thresholds) *HostReport {
+	report := &HostReport{Host: host}
+
+	mounts, err := blockMounts(runner)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	devices, err := scanDevices(runner)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, device := range devices {
+		disk, err := readDisk(runner, device)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		for block, points := range mounts {
+			if ownsBlockDevice(device.Name, block) {
+				disk.Mountpoints = append(disk.Mountpoints, points...)
+			}
+		}
+		sort.Strings(disk.Mountpoints)
+		report.Disks = append(report.Disks, disk)
+	}
+
+	result, err := runner.Exec(dfCommand)
+	if err != nil || result.ExitCode != 0 {
+		report.Errors = append(report.Errors, fmt.Sprintf("df failed: %v", execError(result, err)))
+	} else {
+		report.Filesystems = parseDF(result.Stdout)
+	}
+	for i := range report.Filesystems {
+		fs := &report.Filesystems[i]
+		if disk := report.DiskForPath(fs.Mountpoint); disk != nil {
+			fs.Disk = disk.Device
+		}
+		switch used := fs.UsedPercent(); {
+		case used >= thresholds.UsageCritical:
+			fs.Health = HealthFailing
+		case used >= thresholds.UsageWarning:
+			fs.Health = HealthWarning
+		}
+	}
+	return report
+}
+
+// scanDevices lists the devices smartctl can read
+func scanDevices(runner Runner) ([]scannedDevice, error) {
+	result, err := runner.Exec("sudo smartctl --scan -j")
+	if err != nil {
+		return nil, fmt.Errorf("smartctl --scan failed: %w", err)
+	}
+	if result.ExitCode == 127 || strings.Contains(result.Stderr, "command not found") {
+		return nil, fmt.Errorf("smartctl is not installed; install the smartmontools package")
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("smartctl --scan failed: %v", execError(result, nil))
+	}
+	return parseScan([]byte(result.Stdout))
+}
+
+// readDisk reads and grades one device. smartctl's exit status is a bit
+// mask that is non-zero for failing disks too, so the output is parsed
+// whatever the status.
+func readDisk(runner Runner, device scannedDevice) (*Disk, error) {
+	cmd := fmt.Sprintf("sudo smartctl -j -a %s", device.Name)
+	if device.Type != "" {
+		cmd = fmt.Sprintf("sudo smartctl -j -a -d %s %s", device.Type, device.Name)
+	}
+	result, err := runner.Exec(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("smartctl failed on %s: %w", device.Name, err)
+	}
+	if strings.TrimSpace(result.Stdout) == "" {
+		return nil, fmt.Errorf("smartctl failed on %s: %v", device.Name, execError(result, nil))
+	}
+	disk, err := ParseSmartctl([]byte(result.Stdout))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", device.Name, err)
+	}
+	if disk.Device == "" {
+		disk.Device = device.Name
+	}
+	return disk, nil
+}
+
+// lsblkOutput is lsblk -J output: disks with their partitions as children
+type lsblkOutput struct {
+	BlockDevices []lsblkDevice `json:"blockdevices"`
+}
+
+type lsblkDevice struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Mountpoint *string       `json:"mountpoint"`
+	Children   []lsblkDevice `json:"children"`
+}
+
+// blockMounts maps each disk lsblk lists to the mountpoints on it and its
+// partitions, volumes and other children
+func blockMounts(runner Runner) (map[string][]string, error) {
+	result, err := runner.Exec("lsblk -J -o NAME,TYPE,MOUNTPOINT")
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("lsblk failed: %v", execError(result, err))
+	}
+	return parseLsblk([]byte(result.Stdout))
+}
+
+// parseLsblk reads lsblk -J -o NAME,TYPE,MOUNTPOINT output
+func parseLsblk(data []byte) (map[string][]string, error) {
+	var out lsblkOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse lsblk output: %w", err)
+	}
+	mounts := map[string][]string{}
+	var walk func(disk string, d lsblkDevice)
+	walk = func(disk string, d lsblkDevice) {
+		if d.Mountpoint != nil && strings.HasPrefix(*d.Mountpoint, "/") {
+			mounts[disk] = append(mounts[disk], *d.Mountpoint)
+		}
+		for _, child := range d.Children {
+			walk(disk, child)
+		}
+	}
+	for _, d := range out.BlockDevices {
+		if d.Type != "disk" {
+			continue
+		}
+		mounts[d.Name] = nil
+		walk(d.Name, d)
+	}
+	return mounts, nil
+}
+
+// parseDF reads df -PT -B1 output. Bind mounts of the same source are
+// reported once, under the shortest mountpoint.
+func parseDF(out string) []Filesystem {
+	bySource := map[string]Filesystem{}
+	var order []string
+	for i, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 7 {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[2], 10, 64)
+		used, err2 := strconv.ParseInt(fields[3], 10, 64)
+		avail, err3 := strconv.ParseInt(fields[4], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || size == 0 {
+			continue
+		}
+		fs := Filesystem{
+			Source:     fields[0],
+			Type:       fields[1],
+			Size:       size,
+			Used:       used,
+			Available:  avail,
+			Mountpoint: strings.Join(fields[6:], " "),
+		}
+		existing, seen := bySource[fs.Source]
+		if !seen {
+			order = append(order, fs.Source)
+		}
+		if !seen || len(fs.Mountpoint) < len(existing.Mountpoint) {
+			bySource[fs.Source] = fs
+		}
+	}
+	filesystems := make([]Filesystem, 0, len(order))
+	for _, source := range order {
+		filesystems = append(filesystems, bySource[source])
+	}
+	return filesystems
+}
+
+// execError describes a failed command
+func execError(result *ssh.ExecResult, err error) error {
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("no result")
+	}
+	if msg := strings.TrimSpace(result.Stderr); msg != "" {
+		return fmt.Errorf("exit code %d: %s", result.ExitCode, msg)
+	}
+	return fmt.Errorf("exit code %d", result.ExitCode)
+}
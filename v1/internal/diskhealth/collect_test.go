@@ -0,0 +1,102 @@
+package diskhealth
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/foundry/v1/internal/ssh"
+)
+
+// fakeRunner answers commands from a table; unknown commands exit 127
+type fakeRunner map[string]*ssh.ExecResult
+
+func (f fakeRunner) Exec(command string) (*ssh.ExecResult, error) {
+	if result, ok := f[command]; ok {
+		return result, nil
+	}
+	return &ssh.ExecResult{ExitCode: 127, Stderr: fmt.Sprintf("%s: command not found", command)}, nil
+}
+
+const lsblkJSON = `{"blockdevices": [
+  {"name": "sda", "type": "disk", "mountpoint": null, "children": [
+    {"name": "sda1", "type": "part", "mountpoint": "/boot/efi"},
+    {"name": "sda2", "type": "part", "mountpoint": "/"}
+  ]},
+  {"name": "sdb", "type": "disk", "mountpoint": null, "children": [
+    {"name": "sdb1", "type": "part", "mountpoint": "/mnt/longhorn-sdb"}
+  ]},
+  {"name": "nvme0n1", "type": "disk", "mountpoint": "/data"},
+  {"name": "sr0", "type": "rom", "mountpoint": null}
+]}`
+
+const dfOutput = `Filesystem     Type     1-blocks         Used    Available Capacity Mounted on
+/dev/sda2      ext4  100000000000  85000000000  15000000000      85% /
+/dev/sda1      vfat     535805952      6291456    529514496       2% /boot/efi
+/dev/sdb1      ext4 1000000000000 950000000000  50000000000      95% /mnt/longhorn-sdb
+/dev/sdb1      ext4 1000000000000 950000000000  50000000000      95% /var/lib/kubelet/pods/x/volume
+/dev/nvme0n1   xfs   500000000000 100000000000 400000000000      20% /data
+`
+
+func testRunner() fakeRunner {
+	return fakeRunner{
+		"lsblk -J -o NAME,TYPE,MOUNTPOINT": {Stdout: lsblkJSON},
+		"sudo smartctl --scan -j": {Stdout: `{"devices": [
+			{"name": "/dev/sda", "type": "sat"},
+			{"name": "/dev/sdb", "type": "sat"},
+			{"name": "/dev/nvme0", "type": "nvme"}
+		]}`},
+		"sudo smartctl -j -a -d sat /dev/sda":    {Stdout: healthyATA},
+		"sudo smartctl -j -a -d sat /dev/sdb":    {Stdout: preFailATA, ExitCode: 64},
+		"sudo smartctl -j -a -d nvme /dev/nvme0": {Stdout: criticalNVMe, ExitCode: 8},
+		dfCommand:                                {Stdout: dfOutput},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	report := Collect(testRunner(), "node-a", DefaultThresholds())
+	assert.Empty(t, report.Errors)
+	require.Len(t, report.Disks, 3)
+
+	assert.Equal(t, "/dev/sda", report.Disks[0].Device)
+	assert.Equal(t, []string{"/", "/boot/efi"}, report.Disks[0].Mountpoints)
+	assert.Equal(t, []string{"/mnt/longhorn-sdb"}, report.Disks[1].Mountpoints)
+	assert.Equal(t, HealthWarning, report.Disks[1].Health)
+	assert.Equal(t, []string{"/data"}, report.Disks[2].Mountpoints)
+	assert.Equal(t, HealthFailing, report.Disks[2].Health)
+
+	require.Len(t, report.Filesystems, 4, "the bind mount of /dev/sdb1 is reported once")
+	root := report.Filesystems[0]
+	assert.Equal(t, "/", root.Mountpoint)
+	assert.Equal(t, "/dev/sda", root.Disk)
+	assert.Equal(t, HealthWarning, root.Health)
+	assert.InDelta(t, 85, root.UsedPercent(), 0.01)
+	assert.Equal(t, HealthOK, report.Filesystems[1].Health)
+	assert.Equal(t, "/mnt/longhorn-sdb", report.Filesystems[2].Mountpoint)
+	assert.Equal(t, HealthFailing, report.Filesystems[2].Health)
+	assert.Equal(t, "/dev/nvme0", report.Filesystems[3].Disk)
+
+	assert.Equal(t, HealthFailing, report.Health())
+}
+
+func TestCollectWithoutSmartctl(t *testing.T) {
+	runner := testRunner()
+	delete(runner, "sudo smartctl --scan -j")
+
+	report := Collect(runner, "node-a", DefaultThresholds())
+	assert.Equal(t, []string{"smartctl is not installed; install the smartmontools package"}, report.Errors)
+	assert.Empty(t, report.Disks)
+	assert.Len(t, report.Filesystems, 4, "usage is still reported")
+}
+
+func TestDiskForPath(t *testing.T) {
+	report := Collect(testRunner(), "node-a", DefaultThresholds())
+
+	assert.Equal(t, "/dev/sdb", report.DiskForPath("/mnt/longhorn-sdb").Device)
+	assert.Equal(t, "/dev/sdb", report.DiskForPath("/mnt/longhorn-sdb/replicas").Device)
+	assert.Equal(t, "/dev/sda", report.DiskForPath("/var/lib/longhorn").Device)
+	assert.Equal(t, "/dev/sda", report.DiskForPath("/mnt/longhorn-sdbx").Device)
+	assert.Equal(t, "/dev/nvme0", report.DiskForPath("/data").Device)
+}
@@ -0,0 +1,250 @@
+// Package diskhealth gathers SMART data and filesystem usage from hosts over
+// SSH and grades the health of their disks
+package diskhealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Health is a disk's or filesystem's grade, ordered from best to worst
+type Health int
+
+const (
+	HealthOK Health = iota
+	// HealthUnknown means no SMART data could be read
+	HealthUnknown
+	// HealthWarning is a disk showing early signs of failure (pre-fail) or a
+	// filesystem above the warning threshold
+	HealthWarning
+	// HealthFailing is a disk that SMART reports failing or a filesystem
+	// above the critical threshold
+	HealthFailing
+)
+
+// String returns the health as shown in reports
+func (h Health) String() string {
+	switch h {
+	case HealthOK:
+		return "ok"
+	case HealthWarning:
+		return "warning"
+	case HealthFailing:
+		return "failing"
+	default:
+		return "unknown"
+	}
+}
+
+// Disk is one disk's SMART data and grade
+type Disk struct {
+	// Device is the device smartctl reads, such as /dev/sda or /dev/nvme0
+	Device   string
+	Type     string
+	Model    string
+	Serial   string
+	Capacity int64
+	// Temperature is in °C; 0 when the disk doesn't report it
+	Temperature  int
+	PowerOnHours int
+	Health       Health
+	// Problems explain a grade other than ok
+	Problems []string
+	// Mountpoints are where the disk's filesystems are mounted
+	Mountpoints []string
+}
+
+// smartctlOutput is the part of smartctl -j -a output the grading uses
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Protocol string `json:"protocol"`
+	} `json:"device"`
+	ModelName    string `json:"model_name"`
+	SerialNumber string `json:"serial_number"`
+	UserCapacity struct {
+		Bytes int64 `json:"bytes"`
+	} `json:"user_capacity"`
+	NVMeCapacity int64 `json:"nvme_total_capacity"`
+	SmartSupport *struct {
+		Available bool `json:"available"`
+		Enabled   bool `json:"enabled"`
+	} `json:"smart_support"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours int `json:"hours"`
+	} `json:"power_on_time"`
+	ATAAttributes struct {
+		Table []struct {
+			ID         int    `json:"id"`
+			Name       string `json:"name"`
+			Value      int    `json:"value"`
+			Thresh     int    `json:"thresh"`
+			WhenFailed string `json:"when_failed"`
+			Flags      struct {
+				Prefailure bool `json:"prefailure"`
+			} `json:"flags"`
+			Raw struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		CriticalWarning         int   `json:"critical_warning"`
+		AvailableSpare          int   `json:"available_spare"`
+		AvailableSpareThreshold int   `json:"available_spare_threshold"`
+		PercentageUsed          int   `json:"percentage_used"`
+		MediaErrors             int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// sectorAttributes are the ATA attributes whose raw count is a number of bad
+// or unreadable sectors; any is an early sign of failure
+var sectorAttributes = map[int]string{
+	5:   "reallocated sectors",
+	187: "uncorrectable errors",
+	197: "pending sectors",
+	198: "offline uncorrectable sectors",
+}
+
+// wearWarningPercent is the NVMe endurance used at which a disk is flagged
+const wearWarningPercent = 90
+
+// ParseSmartctl reads smartctl -j -a output and grades the disk
+func ParseSmartctl(data []byte) (*Disk, error) {
+	var out smartctlOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse smartctl output: %w", err)
+	}
+	disk := &Disk{
+		Device:       out.Device.Name,
+		Type:         out.Device.Type,
+		Model:        out.ModelName,
+		Serial:       out.SerialNumber,
+		Capacity:     out.UserCapacity.Bytes,
+		Temperature:  out.Temperature.Current,
+		PowerOnHours: out.PowerOnTime.Hours,
+	}
+	if disk.Capacity == 0 {
+		disk.Capacity = out.NVMeCapacity
+	}
+
+	// Bits 0 and 1 of the exit status: the command line was bad or the
+	// device could not be opened, so there is no SMART data
+	if out.Smartctl.ExitStatus&0x3 != 0 || out.SmartStatus == nil {
+		disk.Health = HealthUnknown
+		for _, msg := range out.Smartctl.Messages {
+			if msg.Severity == "error" {
+				disk.Problems = append(disk.Problems, msg.String)
+			}
+		}
+		if out.SmartSupport != nil && !out.SmartSupport.Available {
+			disk.Problems = append(disk.Problems, "SMART is not supported by the device")
+		} else if out.SmartSupport != nil && !out.SmartSupport.Enabled {
+			disk.Problems = append(disk.Problems, "SMART is disabled; enable it with smartctl -s on")
+		}
+		if len(disk.Problems) == 0 {
+			disk.Problems = append(disk.Problems, "no SMART health data")
+		}
+		return disk, nil
+	}
+
+	if !out.SmartStatus.Passed {
+		disk.flag(HealthFailing, "SMART overall health self-assessment failed")
+	}
+	for _, attr := range out.ATAAttributes.Table {
+		switch {
+		case attr.Flags.Prefailure && attr.WhenFailed == "now":
+			disk.flag(HealthFailing, fmt.Sprintf("pre-fail attribute %s is at or below its threshold (%d <= %d)", attr.Name, attr.Value, attr.Thresh))
+		case attr.Flags.Prefailure && attr.WhenFailed == "past":
+			disk.flag(HealthWarning, fmt.Sprintf("pre-fail attribute %s has been below its threshold in the past", attr.Name))
+		}
+		if what, ok := sectorAttributes[attr.ID]; ok && attr.Raw.Value > 0 {
+			disk.flag(HealthWarning, fmt.Sprintf("%d %s", attr.Raw.Value, what))
+		}
+	}
+	if log := out.NVMeLog; log != nil {
+		if log.CriticalWarning != 0 {
+			disk.flag(HealthFailing, fmt.Sprintf("NVMe critical warning 0x%02x", log.CriticalWarning))
+		}
+		if log.AvailableSpareThreshold > 0 && log.AvailableSpare < log.AvailableSpareThreshold {
+			disk.flag(HealthFailing, fmt.Sprintf("available spare %d%% is below its threshold %d%%", log.AvailableSpare, log.AvailableSpareThreshold))
+		}
+		if log.PercentageUsed >= wearWarningPercent {
+			disk.flag(HealthWarning, fmt.Sprintf("%d%% of rated endurance used", log.PercentageUsed))
+		}
+		if log.MediaErrors > 0 {
+			disk.flag(HealthWarning, fmt.Sprintf("%d media errors", log.MediaErrors))
+		}
+	}
+	return disk, nil
+}
+
+// flag records a problem and lowers the disk's grade to at least health
+func (d *Disk) flag(health Health, problem string) {
+	if health > d.Health {
+		d.Health = health
+	}
+	d.Problems = append(d.Problems, problem)
+}
+
+// scanOutput is smartctl --scan -j output
+type scanOutput struct {
+	Devices []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"devices"`
+}
+
+// scannedDevice is a device smartctl --scan found
+type scannedDevice struct {
+	Name string
+	Type string
+}
+
+// parseScan reads smartctl --scan -j output
+func parseScan(data []byte) ([]scannedDevice, error) {
+	var out scanOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse smartctl --scan output: %w", err)
+	}
+	devices := make([]scannedDevice, 0, len(out.Devices))
+	for _, d := range out.Devices {
+		if d.Name == "" {
+			continue
+		}
+		devices = append(devices, scannedDevice{Name: d.Name, Type: d.Type})
+	}
+	return devices, nil
+}
+
+// ownsBlockDevice reports whether the SMART device covers the block device
+// lsblk lists: /dev/sda covers sda, and the NVMe controller /dev/nvme0 covers
+// its namespaces nvme0n1, nvme0n2 and so on
+func ownsBlockDevice(smartDevice, blockDevice string) bool {
+	name := strings.TrimPrefix(smartDevice, "/dev/")
+	if name == blockDevice {
+		return true
+	}
+	return nvmeController.MatchString(name) && nvmeNamespace.MatchString(strings.TrimPrefix(blockDevice, name))
+}
+
+var (
+	nvmeController = regexp.MustCompile(`^nvme[0-9]+$`)
+	nvmeNamespace  = regexp.MustCompile(`^n[0-9]+$`)
+)
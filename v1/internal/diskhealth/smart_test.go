@@ -0,0 +1,118 @@
+package diskhealth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const healthyATA = `{
+  "smartctl": {"exit_status": 0},
+  "device": {"name": "/dev/sda", "type": "sat", "protocol": "ATA"},
+  "model_name": "Samsung SSD 870 EVO 2TB",
+  "serial_number": "S6PNNS0T123456",
+  "user_capacity": {"blocks": 3907029168, "bytes": 2000398934016},
+  "smart_support": {"available": true, "enabled": true},
+  "smart_status": {"passed": true},
+  "temperature": {"current": 31},
+  "power_on_time": {"hours": 8123},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "name": "Reallocated_Sector_Ct", "value": 100, "thresh": 10, "when_failed": "", "flags": {"prefailure": true}, "raw": {"value": 0}},
+    {"id": 197, "name": "Current_Pending_Sector", "value": 100, "thresh": 0, "when_failed": "", "flags": {"prefailure": false}, "raw": {"value": 0}}
+  ]}
+}`
+
+const preFailATA = `{
+  "smartctl": {"exit_status": 64},
+  "device": {"name": "/dev/sdb", "type": "sat"},
+  "smart_status": {"passed": true},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "name": "Reallocated_Sector_Ct", "value": 98, "thresh": 10, "when_failed": "", "flags": {"prefailure": true}, "raw": {"value": 24}},
+    {"id": 197, "name": "Current_Pending_Sector", "value": 100, "thresh": 0, "when_failed": "", "flags": {"prefailure": false}, "raw": {"value": 3}},
+    {"id": 10, "name": "Spin_Retry_Count", "value": 100, "thresh": 97, "when_failed": "past", "flags": {"prefailure": true}, "raw": {"value": 0}}
+  ]}
+}`
+
+const failingATA = `{
+  "smartctl": {"exit_status": 24},
+  "device": {"name": "/dev/sdc", "type": "sat"},
+  "smart_status": {"passed": false},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "name": "Reallocated_Sector_Ct", "value": 5, "thresh": 10, "when_failed": "now", "flags": {"prefailure": true}, "raw": {"value": 2011}}
+  ]}
+}`
+
+const criticalNVMe = `{
+  "smartctl": {"exit_status": 8},
+  "device": {"name": "/dev/nvme0", "type": "nvme", "protocol": "NVMe"},
+  "model_name": "WD Blue SN570 1TB",
+  "nvme_total_capacity": 1000204886016,
+  "smart_status": {"passed": false, "nvme": {"value": 1}},
+  "temperature": {"current": 44},
+  "nvme_smart_health_information_log": {"critical_warning": 1, "available_spare": 4, "available_spare_threshold": 10, "percentage_used": 97, "media_errors": 0}
+}`
+
+const unsupported = `{
+  "smartctl": {"exit_status": 4, "messages": [{"string": "Read Device Identity failed", "severity": "error"}]},
+  "device": {"name": "/dev/sdd", "type": "scsi"},
+  "smart_support": {"available": false}
+}`
+
+func TestParseSmartctl(t *testing.T) {
+	disk, err := ParseSmartctl([]byte(healthyATA))
+	require.NoError(t, err)
+	assert.Equal(t, HealthOK, disk.Health)
+	assert.Empty(t, disk.Problems)
+	assert.Equal(t, "/dev/sda", disk.Device)
+	assert.Equal(t, "Samsung SSD 870 EVO 2TB", disk.Model)
+	assert.Equal(t, int64(2000398934016), disk.Capacity)
+	assert.Equal(t, 31, disk.Temperature)
+	assert.Equal(t, 8123, disk.PowerOnHours)
+}
+
+func TestParseSmartctlPreFail(t *testing.T) {
+	disk, err := ParseSmartctl([]byte(preFailATA))
+	require.NoError(t, err)
+	assert.Equal(t, HealthWarning, disk.Health)
+	assert.Equal(t, []string{
+		"24 reallocated sectors",
+		"3 pending sectors",
+		"pre-fail attribute Spin_Retry_Count has been below its threshold in the past",
+	}, disk.Problems)
+}
+
+func TestParseSmartctlFailing(t *testing.T) {
+	disk, err := ParseSmartctl([]byte(failingATA))
+	require.NoError(t, err)
+	assert.Equal(t, HealthFailing, disk.Health)
+	assert.Contains(t, disk.Problems, "SMART overall health self-assessment failed")
+	assert.Contains(t, disk.Problems, "pre-fail attribute Reallocated_Sector_Ct is at or below its threshold (5 <= 10)")
+
+	disk, err = ParseSmartctl([]byte(criticalNVMe))
+	require.NoError(t, err)
+	assert.Equal(t, HealthFailing, disk.Health)
+	assert.Equal(t, int64(1000204886016), disk.Capacity)
+	assert.Contains(t, disk.Problems, "NVMe critical warning 0x01")
+	assert.Contains(t, disk.Problems, "available spare 4% is below its threshold 10%")
+	assert.Contains(t, disk.Problems, "97% of rated endurance used")
+}
+
+func TestParseSmartctlUnknown(t *testing.T) {
+	disk, err := ParseSmartctl([]byte(unsupported))
+	require.NoError(t, err)
+	assert.Equal(t, HealthUnknown, disk.Health)
+	assert.Equal(t, []string{"Read Device Identity failed", "SMART is not supported by the device"}, disk.Problems)
+
+	_, err = ParseSmartctl([]byte("smartctl: command not found"))
+	assert.Error(t, err)
+}
+
+func TestOwnsBlockDevice(t *testing.T) {
+	assert.True(t, ownsBlockDevice("/dev/sda", "sda"))
+	assert.False(t, ownsBlockDevice("/dev/sda", "sdaa"))
+	assert.True(t, ownsBlockDevice("/dev/nvme0", "nvme0n1"))
+	assert.True(t, ownsBlockDevice("/dev/nvme1", "nvme1n2"))
+	assert.False(t, ownsBlockDevice("/dev/nvme1", "nvme10n1"))
+	assert.True(t, ownsBlockDevice("/dev/nvme0n1", "nvme0n1"))
+}
@@ -0,0 +1,18 @@
+// Package format renders values for command output.
+package format
+
+import "fmt"
+
+// Bytes renders a byte count with binary units, e.g. "1.5 GiB"
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,16 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, "0 B", Bytes(0))
+	assert.Equal(t, "512 B", Bytes(512))
+	assert.Equal(t, "1.0 KiB", Bytes(1024))
+	assert.Equal(t, "1.5 MiB", Bytes(1536*1024))
+	assert.Equal(t, "40.0 GiB", Bytes(40<<30))
+	assert.Equal(t, "2.0 TiB", Bytes(2<<40))
+}
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Metrics counts reconcile passes and serves them in the Prometheus text
+// format, so the Foundry rule pack can alert when the controller keeps
+// failing.
+type Metrics struct {
+	mu          sync.Mutex
+	reconciles  uint64
+	errors      uint64
+	lastSuccess time.Time
+	listeners   int
+	conflicts   int
+}
+
+// Observe records the outcome of one reconcile pass. result is ignored when
+// err is set.
+func (m *Metrics) Observe(result *Result, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reconciles++
+	if err != nil {
+		m.errors++
+		return
+	}
+	m.lastSuccess = time.Now()
+	m.listeners = len(result.Desired)
+	m.conflicts = len(result.Conflicts)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var lastSuccess float64
+	if !m.lastSuccess.IsZero() {
+		lastSuccess = float64(m.lastSuccess.UnixNano()) / 1e9
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "foundry_gateway_reconcile_total", "counter", "Reconcile passes run.", float64(m.reconciles))
+	writeMetric(w, "foundry_gateway_reconcile_errors_total", "counter", "Reconcile passes that failed.", float64(m.errors))
+	writeMetric(w, "foundry_gateway_last_success_timestamp_seconds", "gauge", "Time of the last successful reconcile pass.", lastSuccess)
+	writeMetric(w, "foundry_gateway_listeners", "gauge", "Listeners derived from routes in the last successful pass.", float64(m.listeners))
+	writeMetric(w, "foundry_gateway_route_conflicts", "gauge", "Route port conflicts found in the last successful pass.", float64(m.conflicts))
+}
+
+func writeMetric(w http.ResponseWriter, name, kind, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+}
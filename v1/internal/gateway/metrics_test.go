@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := &Metrics{}
+	m.Observe(&Result{
+		Desired:   []DesiredListener{{Port: 5432, Protocol: protocolTCP}, {Port: 6443, Protocol: protocolTLS}},
+		Conflicts: []string{"port 5432 requested by two routes"},
+	}, nil)
+	m.Observe(nil, errors.New("gateway not found"))
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, body, "# TYPE foundry_gateway_reconcile_total counter\nfoundry_gateway_reconcile_total 2\n")
+	assert.Contains(t, body, "foundry_gateway_reconcile_errors_total 1\n")
+	assert.Contains(t, body, "foundry_gateway_listeners 2\n")
+	assert.Contains(t, body, "foundry_gateway_route_conflicts 1\n")
+	assert.NotContains(t, body, "foundry_gateway_last_success_timestamp_seconds 0\n")
+}
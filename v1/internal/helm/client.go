@@ -55,6 +55,10 @@ type Client struct {
 	kubeconfig []byte
 	settings   *cli.EnvSettings
 	registry   *registry.Client
+	// localCharts replaces chart repositories when installing offline
+	localCharts map[string]LocalChart
+	// lock pins chart versions and image digests
+	lock *Lock
 }
 
 // NewClient creates a new Helm client
@@ -139,6 +143,10 @@ func (c *Client) AddRepo(ctx context.Context, opts RepoAddOptions) error {
 	if opts.URL == "" {
 		return fmt.Errorf("repository URL cannot be empty")
 	}
+	if c.localCharts != nil {
+		// Charts come from local archives; there is no index to fetch
+		return nil
+	}
 
 	// Ensure repository config directory and cache directory exist
 	repoFile := c.settings.RepositoryConfig
@@ -264,8 +272,12 @@ func (c *Client) doInstall(ctx context.Context, opts InstallOptions, namespace s
 		installAction.Version = opts.Version
 	}
 
+	if c.lock != nil {
+		installAction.PostRenderer = imagePinner(c.lock.Images)
+	}
+
 	// Locate the chart
-	chartPath, err := installAction.ChartPathOptions.LocateChart(opts.Chart, c.settings)
+	chartPath, err := c.locateChart(&installAction.ChartPathOptions, opts.Chart, opts.Version)
 	if err != nil {
 		return fmt.Errorf("failed to locate chart: %w", err)
 	}
@@ -374,8 +386,12 @@ func (c *Client) doUpgrade(ctx context.Context, opts UpgradeOptions, namespace s
 	// For now, we rely on the calling code to handle namespace creation,
 	// or the namespace already existing.
 
+	if c.lock != nil {
+		upgradeAction.PostRenderer = imagePinner(c.lock.Images)
+	}
+
 	// Locate the chart
-	chartPath, err := upgradeAction.ChartPathOptions.LocateChart(opts.Chart, c.settings)
+	chartPath, err := c.locateChart(&upgradeAction.ChartPathOptions, opts.Chart, opts.Version)
 	if err != nil {
 		return fmt.Errorf("failed to locate chart: %w", err)
 	}
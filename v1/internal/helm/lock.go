@@ -0,0 +1,139 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	"sigs.k8s.io/yaml"
+)
+
+// LockedChart is the version and archive digest a lockfile pins a chart to
+type LockedChart struct {
+	Version string
+	Digest  string
+}
+
+// Lock pins what a client installs. Charts is keyed by chart reference, like
+// UseLocalCharts. Images maps fully qualified image references
+// ("docker.io/grafana/grafana:11.4.0") to digests.
+type Lock struct {
+	Charts map[string]LockedChart
+	Images map[string]string
+}
+
+// UseLock makes the client install every chart at its locked version and
+// only when the archive matches the locked digest, and pins every image in
+// the rendered manifests to its locked digest. Charts and images missing
+// from the lock are errors. Charts given as local paths are not checked.
+func (c *Client) UseLock(lock *Lock) {
+	c.lock = lock
+}
+
+// ArchiveDigest returns the sha256 digest of a chart archive
+func ArchiveDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open chart archive: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read chart archive: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lockedVersion returns the version the lock pins chart to
+func (l *Lock) lockedVersion(chart, version string) (string, error) {
+	locked, ok := l.Charts[chart]
+	if !ok {
+		return "", fmt.Errorf("chart %s is not in the lockfile; run 'foundry stack lock'", chart)
+	}
+	if version != "" && version != locked.Version {
+		return "", fmt.Errorf("chart %s %s does not match locked version %s; run 'foundry stack lock --update'", chart, version, locked.Version)
+	}
+	return locked.Version, nil
+}
+
+// verifyChart checks a located chart archive against its locked digest
+func (l *Lock) verifyChart(chart, path string) error {
+	digest, err := ArchiveDigest(path)
+	if err != nil {
+		return err
+	}
+	if locked := l.Charts[chart]; digest != locked.Digest {
+		return fmt.Errorf("chart %s %s has digest %s, but the lockfile has %s", chart, locked.Version, digest, locked.Digest)
+	}
+	return nil
+}
+
+// imagePinner is a Helm post-renderer that pins every image in the rendered
+// manifests to its locked digest
+type imagePinner map[string]string
+
+// Run implements postrender.PostRenderer
+func (p imagePinner) Run(rendered *bytes.Buffer) (*bytes.Buffer, error) {
+	docs := strings.Split(rendered.String(), "\n---")
+	missing := map[string]bool{}
+	for i, doc := range docs {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj == nil {
+			continue
+		}
+		changed := false
+		visitImages(obj, func(image string) string {
+			pinned, ok := p.pin(image)
+			if !ok {
+				missing[image] = true
+			}
+			if pinned != image {
+				changed = true
+			}
+			return pinned
+		})
+		if !changed {
+			continue
+		}
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+		}
+		docs[i] = "\n" + string(out)
+		if i == 0 {
+			docs[i] = "---" + docs[i]
+		}
+	}
+	if len(missing) > 0 {
+		images := make([]string, 0, len(missing))
+		for image := range missing {
+			images = append(images, image)
+		}
+		sort.Strings(images)
+		return nil, fmt.Errorf("images not in the lockfile: %s; run 'foundry stack lock'", strings.Join(images, ", "))
+	}
+	return bytes.NewBufferString(strings.Join(docs, "\n---")), nil
+}
+
+// pin returns image with its locked digest appended. Images that already
+// name a digest are left alone. ok is false when the image is not locked.
+func (p imagePinner) pin(image string) (string, bool) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image, true
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return image, true
+	}
+	digest, ok := p[reference.TagNameOnly(named).String()]
+	if !ok {
+		return image, false
+	}
+	return image + "@" + digest, true
+}
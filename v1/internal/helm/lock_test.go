@@ -0,0 +1,99 @@
+package helm
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestClient_Lock(t *testing.T) {
+	client, err := NewClient(mockKubeconfig(), "default")
+	require.NoError(t, err)
+	defer client.Close()
+
+	archive := filepath.Join(t.TempDir(), "grafana-8.8.2.tgz")
+	require.NoError(t, os.WriteFile(archive, []byte("chart"), 0644))
+	digest, err := ArchiveDigest(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb", digest)
+
+	client.UseLocalCharts(map[string]LocalChart{
+		"grafana/grafana": {Version: "8.8.2", Path: archive},
+	})
+
+	t.Run("uses the locked version", func(t *testing.T) {
+		client.UseLock(&Lock{Charts: map[string]LockedChart{"grafana/grafana": {Version: "8.8.2", Digest: digest}}})
+		opts := &action.ChartPathOptions{}
+		path, err := client.locateChart(opts, "grafana/grafana", "")
+		require.NoError(t, err)
+		assert.Equal(t, archive, path)
+		assert.Equal(t, "8.8.2", opts.Version)
+	})
+
+	t.Run("rejects a version other than the locked one", func(t *testing.T) {
+		_, err := client.locateChart(&action.ChartPathOptions{}, "grafana/grafana", "9.0.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match locked version 8.8.2")
+	})
+
+	t.Run("rejects a digest mismatch", func(t *testing.T) {
+		client.UseLock(&Lock{Charts: map[string]LockedChart{"grafana/grafana": {Version: "8.8.2", Digest: "sha256:other"}}})
+		_, err := client.locateChart(&action.ChartPathOptions{}, "grafana/grafana", "8.8.2")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "but the lockfile has sha256:other")
+	})
+
+	t.Run("rejects a chart missing from the lock", func(t *testing.T) {
+		_, err := client.locateChart(&action.ChartPathOptions{}, "grafana/loki", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not in the lockfile")
+	})
+}
+
+func TestImagePinner(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	pinner := imagePinner{
+		"docker.io/grafana/grafana:11.4.0":        digest,
+		"quay.io/prometheus-operator/reloader:v1": digest,
+	}
+
+	rendered := `---
+# Source: grafana/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: grafana
+---
+# Source: grafana/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: grafana
+spec:
+  template:
+    spec:
+      containers:
+      - name: grafana
+        image: grafana/grafana:11.4.0
+        args:
+        - --reloader=quay.io/prometheus-operator/reloader:v1
+`
+	out, err := pinner.Run(bytes.NewBufferString(rendered))
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "image: grafana/grafana:11.4.0@"+digest)
+	assert.Contains(t, out.String(), "--reloader=quay.io/prometheus-operator/reloader:v1@"+digest)
+	// Documents without images are passed through untouched
+	assert.Contains(t, out.String(), "# Source: grafana/templates/service.yaml")
+
+	t.Run("fails on an image missing from the lock", func(t *testing.T) {
+		_, err := pinner.Run(bytes.NewBufferString("kind: Pod\nspec:\n  containers:\n  - image: busybox:1.36\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "busybox:1.36")
+	})
+}
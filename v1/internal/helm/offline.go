@@ -0,0 +1,243 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"sigs.k8s.io/yaml"
+)
+
+// LocalChart is a chart archive on disk standing in for a chart reference
+type LocalChart struct {
+	Version string
+	Path    string
+}
+
+// UseLocalCharts makes the client install charts from local archives instead
+// of chart repositories. charts is keyed by the reference components pass
+// (for example "grafana/grafana" or an oci:// URL). Once set, AddRepo no
+// longer touches the network and a reference missing from charts is an error.
+func (c *Client) UseLocalCharts(charts map[string]LocalChart) {
+	c.localCharts = charts
+}
+
+// locateChart resolves a chart reference to a path on disk, preferring the
+// local archives set with UseLocalCharts, and checks it against the lock set
+// with UseLock
+func (c *Client) locateChart(opts *action.ChartPathOptions, chart, version string) (string, error) {
+	if c.lock == nil || isLocalPath(chart) {
+		return c.findChart(opts, chart, version)
+	}
+	version, err := c.lock.lockedVersion(chart, version)
+	if err != nil {
+		return "", err
+	}
+	opts.Version = version
+	path, err := c.findChart(opts, chart, version)
+	if err != nil {
+		return "", err
+	}
+	if err := c.lock.verifyChart(chart, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// findChart resolves a chart reference to a path on disk
+func (c *Client) findChart(opts *action.ChartPathOptions, chart, version string) (string, error) {
+	if c.localCharts == nil || isLocalPath(chart) {
+		return opts.LocateChart(chart, c.settings)
+	}
+	local, ok := c.localCharts[chart]
+	if !ok {
+		return "", fmt.Errorf("chart %s is not available offline", chart)
+	}
+	if version != "" && version != local.Version {
+		return "", fmt.Errorf("chart %s is available offline at version %s, not %s", chart, local.Version, version)
+	}
+	return local.Path, nil
+}
+
+// isLocalPath reports whether a chart reference already points at the
+// filesystem, as the embedded gateway controller chart does
+func isLocalPath(chart string) bool {
+	if strings.HasPrefix(chart, "oci://") {
+		return false
+	}
+	_, err := os.Stat(chart)
+	return err == nil
+}
+
+// PullChart downloads a chart archive into destDir and returns its path.
+// repoURL is empty for oci:// references.
+func PullChart(ctx context.Context, repoURL, chart, version, destDir string) (string, error) {
+	if chart == "" {
+		return "", fmt.Errorf("chart cannot be empty")
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create chart directory: %w", err)
+	}
+
+	// Pull into a scratch directory so the resulting archive is unambiguous
+	tmpDir, err := os.MkdirTemp("", "foundry-helm-pull-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registryClient, err := registry.NewClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to create Helm registry client: %w", err)
+	}
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(tmpDir, "repositories.yaml")
+	settings.RepositoryCache = filepath.Join(tmpDir, "cache")
+
+	pull := action.NewPullWithOpts(action.WithConfig(&action.Configuration{RegistryClient: registryClient}))
+	pull.Settings = settings
+	pull.RepoURL = repoURL
+	pull.Version = version
+	pull.DestDir = filepath.Join(tmpDir, "out")
+	if err := os.MkdirAll(pull.DestDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create chart directory: %w", err)
+	}
+
+	ref := chart
+	if repoURL != "" {
+		// Repository references name the chart without the repo alias
+		ref = chart[strings.LastIndex(chart, "/")+1:]
+	}
+	if _, err := pull.Run(ref); err != nil {
+		return "", fmt.Errorf("failed to pull chart %s: %w", chart, err)
+	}
+
+	archives, err := filepath.Glob(filepath.Join(pull.DestDir, "*.tgz"))
+	if err != nil || len(archives) != 1 {
+		return "", fmt.Errorf("failed to pull chart %s: expected one archive, found %d", chart, len(archives))
+	}
+	target := filepath.Join(destDir, filepath.Base(archives[0]))
+	data, err := os.ReadFile(archives[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to read chart archive: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write chart archive: %w", err)
+	}
+	return target, nil
+}
+
+// ChartImages renders a chart archive client-side with the given values and
+// returns every container image its manifests reference, sorted and
+// de-duplicated
+func ChartImages(chartPath string, values map[string]interface{}) ([]string, error) {
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart: %w", err)
+	}
+	values, err = normalizeValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	install := action.NewInstall(&action.Configuration{
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Releases:     storage.Init(driver.NewMemory()),
+		Capabilities: chartutil.DefaultCapabilities,
+	})
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.IncludeCRDs = false
+	install.ReleaseName = chart.Name()
+	install.Namespace = "default"
+
+	rel, err := install.Run(chart, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart %s: %w", chart.Name(), err)
+	}
+	manifests := rel.Manifest
+	for _, hook := range rel.Hooks {
+		manifests += "\n---\n" + hook.Manifest
+	}
+	return ManifestImages(manifests), nil
+}
+
+// ManifestImages returns the container images referenced by a multi-document
+// Kubernetes manifest
+func ManifestImages(manifest string) []string {
+	seen := map[string]bool{}
+	for _, doc := range strings.Split(manifest, "\n---") {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+		visitImages(obj, func(image string) string {
+			seen[image] = true
+			return image
+		})
+	}
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// visitImages calls visit with every image reference under node and replaces
+// the reference with the string visit returns
+func visitImages(node interface{}, visit func(image string) string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		// Containers and operator custom resources (a Prometheus spec, say)
+		// both name their image under an "image" key
+		if image, ok := v["image"].(string); ok && looksLikeImage(image) {
+			v["image"] = visit(image)
+		}
+		// Operators take the images they launch as flags
+		// (--prometheus-config-reloader=quay.io/...)
+		if args, ok := v["args"].([]interface{}); ok {
+			for i, arg := range args {
+				if s, ok := arg.(string); ok && strings.HasPrefix(s, "--") && strings.Contains(s, "=") {
+					flag, value, _ := strings.Cut(s, "=")
+					if host, _, found := strings.Cut(value, "/"); found && strings.Contains(host, ".") && looksLikeImage(value) {
+						args[i] = flag + "=" + visit(value)
+					}
+				}
+			}
+		}
+		for _, value := range v {
+			visitImages(value, visit)
+		}
+	case []interface{}:
+		for _, item := range v {
+			visitImages(item, visit)
+		}
+	}
+}
+
+// looksLikeImage reports whether s has the shape of an image reference: a
+// single token naming a repository with a tag or digest
+func looksLikeImage(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\n{}") {
+		return false
+	}
+	name := s
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Contains(name, ":") || strings.Contains(name, "@")
+}
@@ -0,0 +1,139 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestManifestImages(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36
+      containers:
+      - name: app
+        image: docker.io/grafana/grafana:11.4.0
+      - name: sidecar
+        image: quay.io/kiwigrid/k8s-sidecar:1.28.0
+---
+apiVersion: v1
+kind: ConfigMap
+data:
+  image: "{{ not an image }}"
+---
+apiVersion: batch/v1
+kind: Job
+spec:
+  template:
+    spec:
+      containers:
+      - name: again
+        image: busybox:1.36
+---
+apiVersion: monitoring.coreos.com/v1
+kind: Prometheus
+spec:
+  image: quay.io/prometheus/prometheus:v3.1.0
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: operator
+        image: quay.io/prometheus-operator/prometheus-operator:v0.79.2
+        args:
+        - --kubelet-service=kube-system/kubelet
+        - --prometheus-config-reloader=quay.io/prometheus-operator/prometheus-config-reloader:v0.79.2
+`
+	images := ManifestImages(manifest)
+	assert.Equal(t, []string{
+		"busybox:1.36",
+		"docker.io/grafana/grafana:11.4.0",
+		"quay.io/kiwigrid/k8s-sidecar:1.28.0",
+		"quay.io/prometheus-operator/prometheus-config-reloader:v0.79.2",
+		"quay.io/prometheus-operator/prometheus-operator:v0.79.2",
+		"quay.io/prometheus/prometheus:v3.1.0",
+	}, images)
+}
+
+func TestChartImages(t *testing.T) {
+	chartDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("apiVersion: v2\nname: demo\nversion: 1.2.3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte("image: ghcr.io/example/demo:1.0\nsidecar: false\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(chartDir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "templates", "pod.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: demo
+spec:
+  containers:
+  - name: demo
+    image: {{ .Values.image }}
+{{- if .Values.sidecar }}
+  - name: sidecar
+    image: docker.io/library/nginx:1.27
+{{- end }}
+`), 0644))
+
+	images, err := ChartImages(chartDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ghcr.io/example/demo:1.0"}, images)
+
+	images, err = ChartImages(chartDir, map[string]interface{}{"sidecar": true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker.io/library/nginx:1.27", "ghcr.io/example/demo:1.0"}, images)
+}
+
+func TestClient_LocalCharts(t *testing.T) {
+	client, err := NewClient(mockKubeconfig(), "default")
+	require.NoError(t, err)
+	defer client.Close()
+
+	archive := filepath.Join(t.TempDir(), "grafana-8.8.2.tgz")
+	require.NoError(t, os.WriteFile(archive, []byte("chart"), 0644))
+	client.UseLocalCharts(map[string]LocalChart{
+		"grafana/grafana": {Version: "8.8.2", Path: archive},
+	})
+
+	t.Run("add repo does not fetch an index", func(t *testing.T) {
+		err := client.AddRepo(context.Background(), RepoAddOptions{Name: "grafana", URL: "https://invalid.example"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("resolves a bundled chart", func(t *testing.T) {
+		path, err := client.locateChart(&action.ChartPathOptions{}, "grafana/grafana", "8.8.2")
+		require.NoError(t, err)
+		assert.Equal(t, archive, path)
+	})
+
+	t.Run("rejects a different version", func(t *testing.T) {
+		_, err := client.locateChart(&action.ChartPathOptions{}, "grafana/grafana", "9.0.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not 9.0.0")
+	})
+
+	t.Run("rejects a chart missing from the bundle", func(t *testing.T) {
+		_, err := client.locateChart(&action.ChartPathOptions{}, "grafana/loki", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not available offline")
+	})
+
+	t.Run("local paths pass through", func(t *testing.T) {
+		dir := t.TempDir()
+		path, err := client.locateChart(&action.ChartPathOptions{}, dir, "")
+		require.NoError(t, err)
+		assert.Contains(t, path, dir)
+	})
+}
@@ -61,3 +61,13 @@ type RepoAddOptions struct {
 	Password    string
 	ForceUpdate bool
 }
+
+// ChartSource describes where a component's chart comes from and the values
+// it is installed with. RepoName and RepoURL are empty for oci:// charts.
+type ChartSource struct {
+	RepoName string
+	RepoURL  string
+	Chart    string
+	Version  string
+	Values   map[string]interface{}
+}
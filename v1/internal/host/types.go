@@ -172,6 +172,26 @@ func (h *Host) RemoveRole(role string) {
 	h.Roles = roles
 }
 
+// RoleLabel returns the host's roles as a single label value for metrics
+// and logs, in ValidRoles order followed by any others, e.g.
+// "openbao,dns,zot"
+func (h *Host) RoleLabel() string {
+	var roles []string
+	listed := make(map[string]bool)
+	for _, role := range ValidRoles() {
+		if h.HasRole(role) {
+			roles = append(roles, role)
+			listed[role] = true
+		}
+	}
+	for _, role := range h.Roles {
+		if !listed[role] {
+			roles = append(roles, role)
+		}
+	}
+	return strings.Join(roles, ",")
+}
+
 // HasLabel checks if the host has a specific label
 func (h *Host) HasLabel(key string) bool {
 	if h.Labels == nil {
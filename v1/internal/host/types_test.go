@@ -279,6 +279,13 @@ func TestDefaultHost(t *testing.T) {
 	assert.NoError(t, host.Validate())
 }
 
+func TestHost_RoleLabel(t *testing.T) {
+	h := &Host{Roles: []string{RoleClusterWorker, RoleZot, RoleOpenBAO}}
+	assert.Equal(t, "openbao,zot,cluster-worker", h.RoleLabel())
+
+	assert.Equal(t, "", (&Host{}).RoleLabel())
+}
+
 func TestHost_LabelMethods(t *testing.T) {
 	t.Run("HasLabel on nil labels", func(t *testing.T) {
 		h := &Host{Labels: nil}
@@ -0,0 +1,112 @@
+package hosttls
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ClusterCANamespace and ClusterCASecret locate the cert-manager CA
+	// created for the foundry-ca-issuer ClusterIssuer.
+	ClusterCANamespace = "cert-manager"
+	ClusterCASecret    = "foundry-ca-secret"
+)
+
+// SecretGetter reads Kubernetes secrets.
+type SecretGetter interface {
+	GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+}
+
+// LoadClusterCA reads the internal cluster CA from Kubernetes.
+func LoadClusterCA(ctx context.Context, client SecretGetter) (*CA, error) {
+	secret, err := client.GetSecret(ctx, ClusterCANamespace, ClusterCASecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s (is cert-manager installed?): %w", ClusterCANamespace, ClusterCASecret, err)
+	}
+	return ParseCA(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+}
+
+// ApplyInput describes certificate issuance for one host.
+type ApplyInput struct {
+	Options *Options
+	Target  Target
+	// CA signs certificates with the internal-ca issuer.
+	CA *CA
+	// DNSAPIURL and DNSAPIKey are used for DNS-01 with the acme issuer.
+	DNSAPIURL string
+	DNSAPIKey string
+}
+
+// Apply issues certificates for every service on a host and installs them.
+// With the acme issuer it also sets up the host's renewal timer.
+func Apply(executor Executor, input ApplyInput) error {
+	if input.Options == nil {
+		return fmt.Errorf("host TLS options are required")
+	}
+	if input.Target.Host == nil || len(input.Target.Services) == 0 {
+		return fmt.Errorf("target host and services are required")
+	}
+
+	switch input.Options.Issuer {
+	case config.HostTLSIssuerInternalCA:
+		if input.CA == nil {
+			return fmt.Errorf("internal CA is required for the %s issuer", config.HostTLSIssuerInternalCA)
+		}
+		for _, svc := range input.Target.Services {
+			if err := issueFromCA(executor, input, svc); err != nil {
+				return err
+			}
+		}
+		return RemoveRenewal(executor)
+	case config.HostTLSIssuerACME:
+		err := InstallRenewal(executor, RenewalInput{
+			Options:   input.Options,
+			Services:  input.Target.Services,
+			DNSAPIURL: input.DNSAPIURL,
+			DNSAPIKey: input.DNSAPIKey,
+		})
+		if err != nil {
+			return err
+		}
+		return RunRenewal(executor)
+	}
+	return fmt.Errorf("unsupported host TLS issuer %q", input.Options.Issuer)
+}
+
+// RenewDue reissues, from the internal CA, the certificates on a host that
+// are missing or within RenewBefore of expiring, and returns the services
+// it renewed. The Foundry manager runs it on a schedule.
+func RenewDue(executor Executor, input ApplyInput, now time.Time) ([]Service, error) {
+	if input.Options == nil || input.Options.Issuer != config.HostTLSIssuerInternalCA {
+		return nil, fmt.Errorf("renewal from the cluster CA needs the %s issuer", config.HostTLSIssuerInternalCA)
+	}
+	if input.CA == nil {
+		return nil, fmt.Errorf("internal CA is required for the %s issuer", config.HostTLSIssuerInternalCA)
+	}
+	var renewed []Service
+	for _, svc := range input.Target.Services {
+		if notAfter, err := ReadExpiry(executor, svc); err == nil && notAfter.Sub(now) > input.Options.RenewBefore {
+			continue
+		}
+		if err := issueFromCA(executor, input, svc); err != nil {
+			return renewed, err
+		}
+		renewed = append(renewed, svc)
+	}
+	return renewed, nil
+}
+
+// issueFromCA signs a certificate for a service with the internal CA and
+// installs it
+func issueFromCA(executor Executor, input ApplyInput, svc Service) error {
+	names := []string{svc.FQDN(input.Options.Domain), input.Target.Host.Hostname, input.Target.Host.Address}
+	bundle, err := input.CA.Issue(names, input.Options.Validity)
+	if err != nil {
+		return fmt.Errorf("issue %s certificate: %w", svc.Name, err)
+	}
+	return InstallBundle(executor, svc, bundle)
+}
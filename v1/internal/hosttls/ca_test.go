@@ -0,0 +1,91 @@
+package hosttls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCA(t *testing.T, notAfter time.Time) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "foundry-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestCAIssue(t *testing.T) {
+	certPEM, keyPEM := newTestCA(t, time.Now().Add(365*24*time.Hour))
+	ca, err := ParseCA(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	bundle, err := ca.Issue([]string{"openbao.example.com", "10.0.0.10"}, 24*time.Hour)
+	require.NoError(t, err)
+
+	leafBlock, rest := pem.Decode(bundle.CertPEM)
+	require.NotNil(t, leafBlock)
+	leaf, err := x509.ParseCertificate(leafBlock.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, "openbao.example.com", leaf.Subject.CommonName)
+	assert.Equal(t, []string{"openbao.example.com"}, leaf.DNSNames)
+	require.Len(t, leaf.IPAddresses, 1)
+	assert.Equal(t, "10.0.0.10", leaf.IPAddresses[0].String())
+	assert.Equal(t, string(certPEM), string(rest), "chain should end with the CA")
+	assert.Equal(t, string(certPEM), string(bundle.CAPEM))
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(bundle.CAPEM))
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "openbao.example.com", Roots: roots})
+	require.NoError(t, err)
+
+	keyBlock, _ := pem.Decode(bundle.KeyPEM)
+	require.NotNil(t, keyBlock)
+	assert.Equal(t, "PRIVATE KEY", keyBlock.Type)
+}
+
+func TestCAIssueClampsToCAExpiry(t *testing.T) {
+	caExpiry := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	certPEM, keyPEM := newTestCA(t, caExpiry)
+	ca, err := ParseCA(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	bundle, err := ca.Issue([]string{"zot.example.com"}, DefaultValidity)
+	require.NoError(t, err)
+	assert.True(t, bundle.NotAfter.Equal(caExpiry))
+}
+
+func TestParseCAErrors(t *testing.T) {
+	certPEM, keyPEM := newTestCA(t, time.Now().Add(time.Hour))
+
+	_, err := ParseCA([]byte("not pem"), keyPEM)
+	require.Error(t, err)
+	_, err = ParseCA(certPEM, []byte("not pem"))
+	require.Error(t, err)
+
+	ca, err := ParseCA(certPEM, keyPEM)
+	require.NoError(t, err)
+	_, err = ca.Issue(nil, time.Hour)
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+// Package hosttls issues, installs and renews TLS certificates for the
+// services Foundry runs directly on infrastructure hosts (OpenBAO and Zot).
+package hosttls
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+)
+
+const (
+	// DefaultValidity is the lifetime of certificates signed by the internal CA.
+	DefaultValidity = 90 * 24 * time.Hour
+	// DefaultRenewBefore is how long before expiry a certificate is renewed.
+	DefaultRenewBefore = 30 * 24 * time.Hour
+
+	// DefaultACMEServer is the ACME directory used when none is configured.
+	DefaultACMEServer = "https://acme-v02.api.letsencrypt.org/directory"
+	// DefaultLegoImage is the ACME client image used for DNS-01 issuance.
+	DefaultLegoImage = "docker.io/goacme/lego:v4.19.2"
+
+	// StateDir holds renewal state (ACME account, env file) on each host.
+	StateDir = "/etc/foundry-tls"
+	// RenewScriptPath is the renewal script run by the systemd timer.
+	RenewScriptPath = "/usr/local/sbin/foundry-tls-renew"
+	// RenewUnitName names both the renewal service and its timer.
+	RenewUnitName = "foundry-tls-renew"
+)
+
+// Executor runs commands on a host.
+type Executor interface {
+	Execute(command string) (string, error)
+}
+
+// Service describes a host service that serves TLS from a certificate
+// directory containing tls.crt, tls.key and ca.crt.
+type Service struct {
+	// Name is the component name and the DNS label the service is published as.
+	Name string
+	// Role is the host role that runs the service.
+	Role string
+	// CertDir is the directory the service reads its certificate from.
+	CertDir string
+	// Owner is the uid:gid the certificate files are owned by; empty means root.
+	Owner string
+	// Reload makes the running service pick up a new certificate. It runs as root.
+	Reload string
+}
+
+// CertPath returns the path of the certificate chain.
+func (s Service) CertPath() string { return s.CertDir + "/tls.crt" }
+
+// KeyPath returns the path of the private key.
+func (s Service) KeyPath() string { return s.CertDir + "/tls.key" }
+
+// CAPath returns the path of the issuing CA certificate.
+func (s Service) CAPath() string { return s.CertDir + "/ca.crt" }
+
+// FQDN returns the name the service is published under in the stack domain.
+func (s Service) FQDN(domain string) string {
+	return s.Name + "." + strings.TrimSuffix(domain, ".")
+}
+
+// DefaultServices returns the host services Foundry manages certificates for.
+func DefaultServices() []Service {
+	return []Service{
+		{
+			Name:    "openbao",
+			Role:    host.RoleOpenBAO,
+			CertDir: "/etc/openbao/tls",
+			Owner:   "374:374",
+			Reload:  "systemctl kill --signal=SIGHUP openbao",
+		},
+		{
+			Name:    "zot",
+			Role:    host.RoleZot,
+			CertDir: "/etc/foundry-zot/tls",
+			Reload:  "systemctl try-restart foundry-zot",
+		},
+	}
+}
+
+// ServiceByName looks up one of the default services.
+func ServiceByName(name string) (Service, bool) {
+	for _, svc := range DefaultServices() {
+		if svc.Name == name {
+			return svc, true
+		}
+	}
+	return Service{}, false
+}
+
+// Options is the resolved form of config.HostTLSConfig.
+type Options struct {
+	Issuer      string
+	Domain      string
+	ACMEEmail   string
+	ACMEServer  string
+	Validity    time.Duration
+	RenewBefore time.Duration
+}
+
+// OptionsFromConfig applies defaults to the stack's host_tls section.
+func OptionsFromConfig(cfg *config.Config) (*Options, error) {
+	if cfg == nil || cfg.HostTLS == nil {
+		return nil, fmt.Errorf("host_tls is not configured")
+	}
+	if err := cfg.HostTLS.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Cluster.PrimaryDomain == "" {
+		return nil, fmt.Errorf("cluster.primary_domain is required for host TLS")
+	}
+
+	opts := &Options{
+		Issuer:      cfg.HostTLS.Issuer,
+		Domain:      cfg.Cluster.PrimaryDomain,
+		ACMEServer:  DefaultACMEServer,
+		Validity:    DefaultValidity,
+		RenewBefore: DefaultRenewBefore,
+	}
+	if opts.Issuer == "" {
+		opts.Issuer = config.HostTLSIssuerInternalCA
+	}
+	if cfg.HostTLS.ACMEEmail != nil {
+		opts.ACMEEmail = *cfg.HostTLS.ACMEEmail
+	}
+	if cfg.HostTLS.ACMEServer != nil && *cfg.HostTLS.ACMEServer != "" {
+		opts.ACMEServer = *cfg.HostTLS.ACMEServer
+	}
+	if cfg.HostTLS.Validity != nil {
+		opts.Validity, _ = time.ParseDuration(*cfg.HostTLS.Validity)
+	}
+	if cfg.HostTLS.RenewBefore != nil {
+		opts.RenewBefore, _ = time.ParseDuration(*cfg.HostTLS.RenewBefore)
+	}
+	if opts.RenewBefore >= opts.Validity && opts.Issuer == config.HostTLSIssuerInternalCA {
+		return nil, fmt.Errorf("host_tls renew_before (%s) must be shorter than validity (%s)", opts.RenewBefore, opts.Validity)
+	}
+	return opts, nil
+}
+
+// Target pairs a host with the Foundry-managed services it runs.
+type Target struct {
+	Host     *host.Host
+	Services []Service
+}
+
+// Targets returns the installed host services grouped by the host that runs
+// them. When names is non-empty only those services are included.
+func Targets(cfg *config.Config, names []string) ([]Target, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		if _, ok := ServiceByName(name); !ok {
+			return nil, fmt.Errorf("unknown host service %q (expected openbao or zot)", name)
+		}
+		wanted[name] = true
+	}
+
+	var targets []Target
+	index := map[string]int{}
+	for _, svc := range DefaultServices() {
+		if len(wanted) > 0 && !wanted[svc.Name] {
+			continue
+		}
+		if !serviceInstalled(cfg, svc.Name) {
+			continue
+		}
+		h, err := primaryHost(cfg, svc.Name)
+		if err != nil {
+			continue
+		}
+		i, ok := index[h.Hostname]
+		if !ok {
+			i = len(targets)
+			index[h.Hostname] = i
+			targets = append(targets, Target{Host: h})
+		}
+		targets[i].Services = append(targets[i].Services, svc)
+	}
+	return targets, nil
+}
+
+func serviceInstalled(cfg *config.Config, name string) bool {
+	if cfg.SetupState == nil {
+		return true
+	}
+	switch name {
+	case "openbao":
+		return cfg.SetupState.OpenBAOInstalled
+	case "zot":
+		return cfg.SetupState.ZotInstalled
+	}
+	return false
+}
+
+func primaryHost(cfg *config.Config, name string) (*host.Host, error) {
+	switch name {
+	case "openbao":
+		return cfg.GetPrimaryOpenBAOHost()
+	case "zot":
+		return cfg.GetPrimaryZotHost()
+	}
+	return nil, fmt.Errorf("unknown host service %q", name)
+}
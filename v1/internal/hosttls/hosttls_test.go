@@ -0,0 +1,223 @@
+package hosttls
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExecutor struct {
+	commands []string
+	outputs  map[string]string
+}
+
+func (e *recordingExecutor) Execute(command string) (string, error) {
+	e.commands = append(e.commands, command)
+	for prefix, output := range e.outputs {
+		if strings.HasPrefix(command, prefix) {
+			return output, nil
+		}
+	}
+	return "", nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Cluster: config.ClusterConfig{Name: "test", PrimaryDomain: "example.com"},
+		Hosts: []*host.Host{
+			{Hostname: "infra1", Address: "10.0.0.10", Roles: []string{host.RoleOpenBAO, host.RoleZot}},
+			{Hostname: "infra2", Address: "10.0.0.11", Roles: []string{host.RoleDNS}},
+		},
+		SetupState: &setup.SetupState{OpenBAOInstalled: true, DNSInstalled: true, ZotInstalled: true},
+		HostTLS:    &config.HostTLSConfig{Issuer: config.HostTLSIssuerInternalCA},
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	cfg := testConfig()
+	opts, err := OptionsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", opts.Domain)
+	assert.Equal(t, DefaultValidity, opts.Validity)
+	assert.Equal(t, DefaultRenewBefore, opts.RenewBefore)
+
+	cfg.HostTLS.Validity = strPtr("240h")
+	cfg.HostTLS.RenewBefore = strPtr("240h")
+	_, err = OptionsFromConfig(cfg)
+	require.Error(t, err)
+
+	cfg.HostTLS = &config.HostTLSConfig{Issuer: config.HostTLSIssuerACME, ACMEEmail: strPtr("ops@example.com")}
+	opts, err = OptionsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultACMEServer, opts.ACMEServer)
+
+	_, err = OptionsFromConfig(&config.Config{})
+	require.Error(t, err)
+}
+
+func TestTargets(t *testing.T) {
+	cfg := testConfig()
+	targets, err := Targets(cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, targets, 1, "PowerDNS does not serve TLS")
+	assert.Equal(t, "infra1", targets[0].Host.Hostname)
+	require.Len(t, targets[0].Services, 2)
+	assert.Equal(t, "openbao", targets[0].Services[0].Name)
+	assert.Equal(t, "zot", targets[0].Services[1].Name)
+
+	targets, err = Targets(cfg, []string{"zot"})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "zot", targets[0].Services[0].Name)
+
+	cfg.SetupState.ZotInstalled = false
+	targets, err = Targets(cfg, []string{"zot"})
+	require.NoError(t, err)
+	assert.Empty(t, targets)
+
+	_, err = Targets(cfg, []string{"vault"})
+	require.Error(t, err)
+}
+
+func TestInstallBundle(t *testing.T) {
+	svc, ok := ServiceByName("openbao")
+	require.True(t, ok)
+	executor := &recordingExecutor{}
+	bundle := &Bundle{CertPEM: []byte("cert"), KeyPEM: []byte("key"), CAPEM: []byte("ca")}
+
+	require.NoError(t, InstallBundle(executor, svc, bundle))
+	require.Len(t, executor.commands, 5)
+	assert.Contains(t, executor.commands[2], "sudo tee /etc/openbao/tls/tls.key")
+	assert.Contains(t, executor.commands[2], "sudo chmod 0600")
+	assert.Contains(t, executor.commands[2], "sudo chown 374:374")
+	assert.Equal(t, "sudo systemctl kill --signal=SIGHUP openbao", executor.commands[4])
+}
+
+func TestParseNotAfter(t *testing.T) {
+	notAfter, err := ParseNotAfter("notAfter=Jan  5 12:00:00 2027 GMT\n")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2027, time.January, 5, 12, 0, 0, 0, time.UTC), notAfter.UTC())
+
+	_, err = ParseNotAfter("unable to load certificate")
+	require.Error(t, err)
+}
+
+func TestRenderRenewScript(t *testing.T) {
+	services := []Service{DefaultServices()[0]}
+
+	t.Run("acme", func(t *testing.T) {
+		script, err := RenderRenewScript(RenewalInput{
+			Options: &Options{
+				Issuer:      config.HostTLSIssuerACME,
+				Domain:      "example.com",
+				ACMEEmail:   "ops@example.com",
+				RenewBefore: DefaultRenewBefore,
+			},
+			Services: services,
+			Runtime:  "/usr/bin/docker",
+		})
+		require.NoError(t, err)
+		assert.Contains(t, script, "/usr/bin/docker run --rm --env-file /etc/foundry-tls/renew.env")
+		assert.Contains(t, script, "--email ops@example.com --server "+DefaultACMEServer)
+		assert.Contains(t, script, "renew --days 30")
+		assert.Contains(t, script, `renew openbao openbao.example.com /etc/openbao/tls "374:374" "systemctl kill --signal=SIGHUP openbao"`)
+	})
+
+	t.Run("acme requires email", func(t *testing.T) {
+		_, err := RenderRenewScript(RenewalInput{Options: &Options{Issuer: config.HostTLSIssuerACME}, Services: services})
+		require.Error(t, err)
+	})
+}
+
+func TestInstallRenewal(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{"command -v docker": "/usr/bin/docker\n"}}
+	err := InstallRenewal(executor, RenewalInput{
+		Options:   &Options{Issuer: config.HostTLSIssuerACME, Domain: "example.com", ACMEEmail: "ops@example.com"},
+		Services:  DefaultServices(),
+		DNSAPIURL: "http://10.0.0.10:8081",
+		DNSAPIKey: "secret",
+	})
+	require.NoError(t, err)
+
+	joined := strings.Join(executor.commands, "\n")
+	assert.Contains(t, joined, "sudo tee /usr/local/sbin/foundry-tls-renew")
+	assert.Contains(t, joined, "sudo tee /etc/foundry-tls/renew.env >/dev/null && sudo chmod 0600")
+	assert.Contains(t, joined, "/etc/systemd/system/foundry-tls-renew.timer")
+	assert.Contains(t, joined, "systemctl enable --now foundry-tls-renew.timer")
+	assert.NotContains(t, joined, "secret", "API key must only be written base64 encoded")
+
+	executor = &recordingExecutor{}
+	err = InstallRenewal(executor, RenewalInput{
+		Options:  &Options{Issuer: config.HostTLSIssuerACME, Domain: "example.com", ACMEEmail: "ops@example.com"},
+		Services: DefaultServices(),
+		Runtime:  "/usr/bin/docker",
+	})
+	require.Error(t, err)
+
+	err = InstallRenewal(&recordingExecutor{}, RenewalInput{
+		Options:  &Options{Issuer: config.HostTLSIssuerInternalCA, Domain: "example.com"},
+		Services: DefaultServices(),
+	})
+	require.Error(t, err, "the internal CA is renewed by the manager, not on the host")
+}
+
+func TestApplyInternalCA(t *testing.T) {
+	certPEM, keyPEM := newTestCA(t, time.Now().Add(365*24*time.Hour))
+	ca, err := ParseCA(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	cfg := testConfig()
+	opts, err := OptionsFromConfig(cfg)
+	require.NoError(t, err)
+	targets, err := Targets(cfg, []string{"openbao"})
+	require.NoError(t, err)
+
+	executor := &recordingExecutor{}
+	require.NoError(t, Apply(executor, ApplyInput{Options: opts, Target: targets[0], CA: ca}))
+	joined := strings.Join(executor.commands, "\n")
+	assert.Contains(t, joined, "sudo tee /etc/openbao/tls/tls.crt")
+	assert.Contains(t, joined, "systemctl disable --now foundry-tls-renew.timer")
+	assert.NotContains(t, joined, "systemctl enable --now foundry-tls-renew.timer")
+
+	err = Apply(&recordingExecutor{}, ApplyInput{Options: opts, Target: targets[0]})
+	require.Error(t, err)
+}
+
+func TestRenewDue(t *testing.T) {
+	certPEM, keyPEM := newTestCA(t, time.Now().Add(365*24*time.Hour))
+	ca, err := ParseCA(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	cfg := testConfig()
+	opts, err := OptionsFromConfig(cfg)
+	require.NoError(t, err)
+	targets, err := Targets(cfg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "infra1", targets[0].Host.Hostname)
+
+	now := time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)
+	executor := &recordingExecutor{outputs: map[string]string{
+		// openbao is well before its renewal window, zot is inside it
+		"sudo openssl x509 -enddate -noout -in /etc/openbao/tls/tls.crt":     "notAfter=Jun  1 00:00:00 2027 GMT\n",
+		"sudo openssl x509 -enddate -noout -in /etc/foundry-zot/tls/tls.crt": "notAfter=Dec 10 00:00:00 2026 GMT\n",
+	}}
+	renewed, err := RenewDue(executor, ApplyInput{Options: opts, Target: targets[0], CA: ca}, now)
+	require.NoError(t, err)
+	require.Len(t, renewed, 1)
+	assert.Equal(t, "zot", renewed[0].Name)
+	joined := strings.Join(executor.commands, "\n")
+	assert.Contains(t, joined, "sudo tee /etc/foundry-zot/tls/tls.crt")
+	assert.NotContains(t, joined, "sudo tee /etc/openbao/tls/tls.crt")
+
+	opts.Issuer = config.HostTLSIssuerACME
+	_, err = RenewDue(&recordingExecutor{}, ApplyInput{Options: opts, Target: targets[0], CA: ca}, now)
+	require.Error(t, err)
+}
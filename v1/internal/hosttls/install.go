@@ -0,0 +1,268 @@
+package hosttls
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/systemd"
+)
+
+// InstallBundle writes an issued certificate into the service's certificate
+// directory and reloads the service.
+func InstallBundle(executor Executor, svc Service, bundle *Bundle) error {
+	if bundle == nil {
+		return fmt.Errorf("certificate bundle is required")
+	}
+	commands := []string{
+		fmt.Sprintf("sudo mkdir -p %s && sudo chmod 0755 %s", svc.CertDir, svc.CertDir),
+		writeBase64Command(svc.CAPath(), bundle.CAPEM, "0644", svc.Owner),
+		writeBase64Command(svc.KeyPath(), bundle.KeyPEM, "0600", svc.Owner),
+		writeBase64Command(svc.CertPath(), bundle.CertPEM, "0644", svc.Owner),
+	}
+	for _, command := range commands {
+		if _, err := executor.Execute(command); err != nil {
+			return fmt.Errorf("install %s certificate: %w", svc.Name, err)
+		}
+	}
+	if svc.Reload != "" {
+		if _, err := executor.Execute("sudo " + svc.Reload); err != nil {
+			return fmt.Errorf("reload %s: %w", svc.Name, err)
+		}
+	}
+	return nil
+}
+
+// ReadExpiry returns the expiry of the certificate a service is serving from
+// disk.
+func ReadExpiry(executor Executor, svc Service) (time.Time, error) {
+	output, err := executor.Execute("sudo openssl x509 -enddate -noout -in " + svc.CertPath())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read %s certificate: %w", svc.Name, err)
+	}
+	return ParseNotAfter(output)
+}
+
+// ParseNotAfter parses the output of `openssl x509 -enddate -noout`.
+func ParseNotAfter(output string) (time.Time, error) {
+	value := strings.TrimSpace(output)
+	value, ok := strings.CutPrefix(value, "notAfter=")
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected openssl output %q", strings.TrimSpace(output))
+	}
+	notAfter, err := time.Parse("Jan _2 15:04:05 2006 MST", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate expiry %q: %w", value, err)
+	}
+	return notAfter, nil
+}
+
+// RenewalInput is everything needed to install the renewal timer on a host.
+type RenewalInput struct {
+	Options  *Options
+	Services []Service
+	// DNSAPIURL and DNSAPIKey give the ACME client access to the PowerDNS API
+	// for DNS-01 challenges. They are only used with the acme issuer.
+	DNSAPIURL string
+	DNSAPIKey string
+	// Runtime is the container runtime binary used to run the ACME client.
+	Runtime string
+}
+
+// InstallRenewal installs the ACME renewal script and a daily systemd timer
+// that runs it; the script obtains and renews certificates itself. The
+// internal CA's key never leaves the cluster, so certificates it signs are
+// renewed by the Foundry manager instead (see RenewDue).
+func InstallRenewal(executor Executor, input RenewalInput) error {
+	if input.Options == nil {
+		return fmt.Errorf("host TLS options are required")
+	}
+	if input.Options.Issuer != config.HostTLSIssuerACME {
+		return fmt.Errorf("only the %s issuer renews on the host", config.HostTLSIssuerACME)
+	}
+	if len(input.Services) == 0 {
+		return fmt.Errorf("at least one service is required")
+	}
+	if input.Runtime == "" {
+		runtimePath, err := findRuntime(executor)
+		if err != nil {
+			return err
+		}
+		input.Runtime = runtimePath
+	}
+
+	script, err := RenderRenewScript(input)
+	if err != nil {
+		return err
+	}
+	if input.DNSAPIURL == "" || input.DNSAPIKey == "" {
+		return fmt.Errorf("PowerDNS API URL and key are required for ACME DNS-01")
+	}
+	env := fmt.Sprintf("PDNS_API_URL=%s\nPDNS_API_KEY=%s\n", input.DNSAPIURL, input.DNSAPIKey)
+	commands := []string{
+		fmt.Sprintf("sudo mkdir -p %s && sudo chmod 0700 %s", StateDir, StateDir),
+		writeBase64Command(RenewScriptPath, []byte(script), "0755", ""),
+		writeBase64Command(StateDir+"/renew.env", []byte(env), "0600", ""),
+	}
+	for _, command := range commands {
+		if _, err := executor.Execute(command); err != nil {
+			return fmt.Errorf("install TLS renewal: %w", err)
+		}
+	}
+
+	unit := &systemd.UnitFile{
+		Description: "Renew TLS certificates for Foundry host services",
+		After:       []string{"network-online.target"},
+		Wants:       []string{"network-online.target"},
+		Type:        "oneshot",
+		ExecStart:   RenewScriptPath,
+	}
+	if err := systemd.CreateService(executor, RenewUnitName, unit); err != nil {
+		return err
+	}
+	timer := systemd.DefaultTimerFile("Daily renewal of Foundry host service certificates")
+	if err := systemd.CreateTimer(executor, RenewUnitName, timer); err != nil {
+		return err
+	}
+	return systemd.EnableTimer(executor, RenewUnitName)
+}
+
+// RemoveRenewal removes the renewal timer and script from a host, such as
+// the expiry check earlier releases installed for the internal CA.
+func RemoveRenewal(executor Executor) error {
+	command := fmt.Sprintf(
+		"sudo systemctl disable --now %[1]s.timer 2>/dev/null; sudo rm -f /etc/systemd/system/%[1]s.timer /etc/systemd/system/%[1]s.service %[2]s %[3]s/renew.env && sudo systemctl daemon-reload",
+		RenewUnitName, RenewScriptPath, StateDir,
+	)
+	if _, err := executor.Execute(command); err != nil {
+		return fmt.Errorf("remove TLS renewal: %w", err)
+	}
+	return nil
+}
+
+// RunRenewal runs the renewal script once and waits for it to finish.
+func RunRenewal(executor Executor) error {
+	if _, err := executor.Execute(fmt.Sprintf("sudo systemctl start %s.service", RenewUnitName)); err != nil {
+		return fmt.Errorf("run TLS renewal: %w", err)
+	}
+	return nil
+}
+
+const renewScriptTemplate = `#!/bin/sh
+# Managed by Foundry. Renews TLS certificates for host services.
+set -u
+status=0
+LEGO="{{ .Runtime }} run --rm --env-file {{ .StateDir }}/renew.env -v {{ .StateDir }}/lego:/lego {{ .Image }} --path /lego --accept-tos --email {{ .Email }} --server {{ .Server }} --dns pdns"
+
+renew() {
+	name=$1 domain=$2 dir=$3 owner=$4 reload=$5
+	src={{ .StateDir }}/lego/certificates/$domain
+	if [ -f "$src.crt" ]; then
+		$LEGO --domains "$domain" renew --days {{ .RenewDays }} --no-random-sleep || { status=1; return; }
+	else
+		$LEGO --domains "$domain" run || { status=1; return; }
+	fi
+	if cmp -s "$src.crt" "$dir/tls.crt"; then
+		return
+	fi
+	install -d -m 0755 "$dir"
+	install -m 0644 "$src.crt" "$dir/tls.crt"
+	install -m 0600 "$src.key" "$dir/tls.key"
+	if [ -f "$src.issuer.crt" ]; then install -m 0644 "$src.issuer.crt" "$dir/ca.crt"; fi
+	if [ -n "$owner" ]; then chown "$owner" "$dir/tls.crt" "$dir/tls.key"; fi
+	echo "foundry-tls: renewed $name certificate for $domain"
+	sh -c "$reload" || status=1
+}
+{{ range .Services }}
+renew {{ .Name }} {{ .FQDN }} {{ .CertDir }} "{{ .Owner }}" "{{ .Reload }}"
+{{- end }}
+exit $status
+`
+
+type renewScriptService struct {
+	Name    string
+	FQDN    string
+	CertDir string
+	Owner   string
+	Reload  string
+}
+
+// RenderRenewScript renders the ACME renewal script for a host.
+func RenderRenewScript(input RenewalInput) (string, error) {
+	opts := input.Options
+	if opts.ACMEEmail == "" {
+		return "", fmt.Errorf("acme_email is required for the acme issuer")
+	}
+	server := opts.ACMEServer
+	if server == "" {
+		server = DefaultACMEServer
+	}
+	renewBefore := opts.RenewBefore
+	if renewBefore <= 0 {
+		renewBefore = DefaultRenewBefore
+	}
+	renewDays := int(renewBefore.Hours() / 24)
+	if renewDays < 1 {
+		renewDays = 1
+	}
+
+	data := struct {
+		Runtime   string
+		StateDir  string
+		Image     string
+		Email     string
+		Server    string
+		RenewDays int
+		Services  []renewScriptService
+	}{
+		Runtime:   input.Runtime,
+		StateDir:  StateDir,
+		Image:     DefaultLegoImage,
+		Email:     opts.ACMEEmail,
+		Server:    server,
+		RenewDays: renewDays,
+	}
+	for _, svc := range input.Services {
+		data.Services = append(data.Services, renewScriptService{
+			Name:    svc.Name,
+			FQDN:    svc.FQDN(opts.Domain),
+			CertDir: svc.CertDir,
+			Owner:   svc.Owner,
+			Reload:  svc.Reload,
+		})
+	}
+
+	tmpl, err := template.New("renew").Parse(renewScriptTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse renew script template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render renew script: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func findRuntime(executor Executor) (string, error) {
+	for _, candidate := range []string{"docker", "nerdctl", "podman"} {
+		output, err := executor.Execute("command -v " + candidate)
+		path := strings.TrimSpace(output)
+		if err == nil && strings.HasPrefix(path, "/") {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no supported container runtime is available for the ACME client")
+}
+
+func writeBase64Command(path string, data []byte, mode, owner string) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	command := fmt.Sprintf("echo %s | base64 -d | sudo tee %s >/dev/null && sudo chmod %s %s", encoded, path, mode, path)
+	if owner != "" {
+		command += fmt.Sprintf(" && sudo chown %s %s", owner, path)
+	}
+	return command
+}
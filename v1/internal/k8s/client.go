@@ -8,6 +8,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -237,6 +238,30 @@ func (c *Client) MergePatchResource(ctx context.Context, gvr schema.GroupVersion
 	return nil
 }
 
+// ListResourceNames returns the names of a resource's objects that match a
+// label selector
+func (c *Client) ListResourceNames(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) ([]string, error) {
+	list, err := c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names, nil
+}
+
+// DeleteResource deletes a Kubernetes resource. A resource that is already
+// gone is not an error.
+func (c *Client) DeleteResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) error {
+	err := c.dynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete resource %s/%s: %w", gvr.Resource, name, err)
+	}
+	return nil
+}
+
 // applySingleManifest applies a single YAML document to the cluster
 func (c *Client) applySingleManifest(ctx context.Context, manifest string) error {
 	// Parse the manifest as unstructured object
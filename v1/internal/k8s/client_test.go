@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -528,6 +529,39 @@ func TestMergePatchResource_ValidatesInput(t *testing.T) {
 	assert.EqualError(t, err, "resource patch is empty")
 }
 
+func TestListResourceNamesAndDeleteResource(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "recurringjobs"}
+	job := func(name string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "longhorn.io/v1beta2",
+			"kind":       "RecurringJob",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "longhorn-system",
+				"labels":    labels,
+			},
+		}}
+	}
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{gvr: "RecurringJobList"},
+		job("daily", map[string]interface{}{"foundry.io/managed": "true"}),
+		job("manual", nil),
+	)
+	client := &Client{dynamicClient: dynamicClient}
+	ctx := context.Background()
+
+	names, err := client.ListResourceNames(ctx, gvr, "longhorn-system", "foundry.io/managed")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"daily"}, names)
+
+	require.NoError(t, client.DeleteResource(ctx, gvr, "longhorn-system", "daily"))
+	require.NoError(t, client.DeleteResource(ctx, gvr, "longhorn-system", "daily"), "deleting a missing resource")
+	names, err = client.ListResourceNames(ctx, gvr, "longhorn-system", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manual"}, names)
+}
+
 func TestNodeFromCoreV1(t *testing.T) {
 	t.Run("ready node with all fields", func(t *testing.T) {
 		coreNode := &corev1.Node{
@@ -764,6 +798,35 @@ func TestPodFromCoreV1(t *testing.T) {
 		assert.Len(t, pod.Containers, 0)
 		assert.Equal(t, "Pending", pod.Status)
 	})
+
+	t.Run("pod resources", func(t *testing.T) {
+		resources := func(cpu, memory string) corev1.ResourceList {
+			return corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}
+		}
+		corePod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "migrate", Resources: corev1.ResourceRequirements{Requests: resources("1", "64Mi")}},
+				},
+				Containers: []corev1.Container{
+					{Name: "app", Resources: corev1.ResourceRequirements{Requests: resources("250m", "256Mi"), Limits: resources("500m", "512Mi")}},
+					{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: resources("50m", "32Mi")}},
+				},
+				Overhead: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+			},
+		}
+
+		pod := PodFromCoreV1(corePod)
+		// The init container's CPU request outweighs the app containers' sum
+		assert.Equal(t, int64(1010), pod.Requests.Cpu().MilliValue())
+		assert.Equal(t, int64(288*1024*1024), pod.Requests.Memory().Value())
+		assert.Equal(t, int64(510), pod.Limits.Cpu().MilliValue())
+		assert.Equal(t, int64(512*1024*1024), pod.Limits.Memory().Value())
+	})
 }
 
 func TestClientAccessors(t *testing.T) {
@@ -20,6 +20,7 @@ type Node struct {
 	CreationTimestamp time.Time
 	AllocatableCPU    *string
 	AllocatableMemory *string
+	Unschedulable     bool
 }
 
 // NodeCondition represents a node condition
@@ -41,6 +42,11 @@ type Pod struct {
 	PodIP             string
 	CreationTimestamp time.Time
 	Containers        []Container
+	// Requests and Limits are the resources the scheduler accounts for the
+	// pod: the larger of its containers' sum and any init container, plus
+	// the pod overhead
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
 }
 
 // Namespace represents a Kubernetes namespace
@@ -73,6 +79,7 @@ func NodeFromCoreV1(node *corev1.Node) *Node {
 		Version:           node.Status.NodeInfo.KubeletVersion,
 		CreationTimestamp: node.CreationTimestamp.Time,
 		Roles:             extractNodeRoles(node),
+		Unschedulable:     node.Spec.Unschedulable,
 	}
 
 	// Extract IPs
@@ -155,6 +162,8 @@ func PodFromCoreV1(pod *corev1.Pod) *Pod {
 		PodIP:             pod.Status.PodIP,
 		CreationTimestamp: pod.CreationTimestamp.Time,
 		Containers:        make([]Container, 0, len(pod.Status.ContainerStatuses)),
+		Requests:          podResources(pod, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Requests }),
+		Limits:            podResources(pod, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Limits }),
 	}
 
 	// Extract container info
@@ -181,6 +190,34 @@ func PodFromCoreV1(pod *corev1.Pod) *Pod {
 	return p
 }
 
+// podResources totals one kind of container resource the way the scheduler
+// does: app containers are summed, init containers run one at a time so only
+// the largest counts, and the pod overhead is added on top
+func podResources(pod *corev1.Pod, kind func(corev1.ResourceRequirements) corev1.ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		for name, quantity := range kind(c.Resources) {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		for name, quantity := range kind(c.Resources) {
+			if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+				total[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for name, quantity := range pod.Spec.Overhead {
+		if sum, ok := total[name]; ok {
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+	return total
+}
+
 // isPodReady checks if all containers in a pod are ready
 func isPodReady(pod *corev1.Pod) bool {
 	for _, cond := range pod.Status.Conditions {
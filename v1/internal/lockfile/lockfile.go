@@ -0,0 +1,324 @@
+// Package lockfile records the exact Helm chart versions and container image
+// digests a stack installs. The lockfile sits next to the stack config
+// (stack.yaml locks to stack.lock.yaml); installs and upgrades read it to
+// pin every chart and image and fail when what they fetch does not match.
+//
+// Image digests are those of the linux/amd64 manifests, the platform
+// Foundry installs on.
+package lockfile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/bundle"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// fileHeader is written at the top of every lockfile
+const fileHeader = `# Foundry stack lockfile
+#
+# Generated by 'foundry stack lock'. Installs and upgrades use exactly these
+# chart versions and image digests. Run 'foundry stack lock --update' to move
+# them.
+`
+
+// File is a stack lockfile
+type File struct {
+	Charts []Chart `yaml:"charts"`
+	Images []Image `yaml:"images"`
+}
+
+// Chart is a locked Helm chart. Digest is the sha256 of the chart archive.
+type Chart struct {
+	Component string `yaml:"component"`
+	Chart     string `yaml:"chart"`
+	RepoURL   string `yaml:"repo_url,omitempty"`
+	Version   string `yaml:"version"`
+	Digest    string `yaml:"digest"`
+}
+
+// Image is a locked container image. Ref is the fully qualified reference.
+type Image struct {
+	Component string `yaml:"component"`
+	Ref       string `yaml:"ref"`
+	Digest    string `yaml:"digest"`
+}
+
+// Path returns the lockfile path for a stack config path
+func Path(configPath string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + ".lock.yaml"
+}
+
+// Load reads a lockfile
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// LoadForConfig reads the lockfile next to a stack config. It returns nil
+// without an error when the stack has no lockfile.
+func LoadForConfig(configPath string) (*File, error) {
+	path := Path(configPath)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return Load(path)
+}
+
+// Save writes the lockfile to path
+func (f *File) Save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, append([]byte(fileHeader), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+// HelmLock returns the lock a Helm client enforces
+func (f *File) HelmLock() *helm.Lock {
+	lock := &helm.Lock{
+		Charts: make(map[string]helm.LockedChart, len(f.Charts)),
+		Images: f.ImageDigests(),
+	}
+	for _, c := range f.Charts {
+		lock.Charts[c.Chart] = helm.LockedChart{Version: c.Version, Digest: c.Digest}
+	}
+	return lock
+}
+
+// ImageDigests maps every locked image reference to its digest
+func (f *File) ImageDigests() map[string]string {
+	digests := make(map[string]string, len(f.Images))
+	for _, img := range f.Images {
+		digests[img.Ref] = img.Digest
+	}
+	return digests
+}
+
+// Pin makes a plan fetch the locked chart versions and image digests
+func (f *File) Pin(plan *bundle.Plan) {
+	versions := map[string]string{}
+	for _, c := range f.Charts {
+		versions[c.Chart] = c.Version
+	}
+	for i, c := range plan.Charts {
+		if version, ok := versions[c.Chart]; ok {
+			plan.Charts[i].Version = version
+		}
+	}
+	plan.ImageDigests = f.ImageDigests()
+}
+
+// Resolve pulls every chart in plan to record its version and digest,
+// renders it to find the images it runs, and resolves every image to a
+// digest
+func Resolve(ctx context.Context, plan *bundle.Plan, out io.Writer) (*File, error) {
+	work, err := os.MkdirTemp("", "foundry-lock-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	f := &File{}
+	images := map[string]string{}
+	addImage := func(component, ref string) error {
+		normalized, err := bundle.NormalizeImage(ref)
+		if err != nil {
+			return err
+		}
+		if _, ok := images[normalized]; !ok {
+			images[normalized] = component
+		}
+		return nil
+	}
+	for _, img := range plan.Images {
+		if err := addImage(img.Component, img.Ref); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range plan.Charts {
+		fmt.Fprintf(out, "  Resolving chart %s %s...\n", c.Chart, c.Version)
+		path, err := helm.PullChart(ctx, c.RepoURL, c.Chart, c.Version, work)
+		if err != nil {
+			return nil, err
+		}
+		chart, err := loader.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chart %s: %w", c.Chart, err)
+		}
+		digest, err := helm.ArchiveDigest(path)
+		if err != nil {
+			return nil, err
+		}
+		f.Charts = append(f.Charts, Chart{
+			Component: c.Component,
+			Chart:     c.Chart,
+			RepoURL:   c.RepoURL,
+			Version:   chart.Metadata.Version,
+			Digest:    digest,
+		})
+
+		refs, err := helm.ChartImages(path, c.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list images of %s: %w", c.Chart, err)
+		}
+		for _, ref := range refs {
+			if err := addImage(c.Component, ref); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for ref, component := range images {
+		fmt.Fprintf(out, "  Resolving image %s...\n", ref)
+		digest, err := bundle.ResolveImage(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		f.Images = append(f.Images, Image{Component: component, Ref: ref, Digest: digest})
+	}
+	f.sort()
+	return f, nil
+}
+
+// Merge combines a freshly resolved lockfile with the existing one. Entries
+// already locked keep their version and digest unless update reports their
+// component, so re-locking only adds what is new and drops what is no longer
+// used. locked may be nil.
+func Merge(locked, resolved *File, update func(component string) bool) *File {
+	if locked == nil {
+		return resolved
+	}
+	charts := map[string]Chart{}
+	for _, c := range locked.Charts {
+		charts[c.Component+" "+c.Chart] = c
+	}
+	images := map[string]Image{}
+	for _, img := range locked.Images {
+		images[img.Ref] = img
+	}
+
+	merged := &File{}
+	for _, c := range resolved.Charts {
+		if prev, ok := charts[c.Component+" "+c.Chart]; ok && !update(c.Component) {
+			c = prev
+		}
+		merged.Charts = append(merged.Charts, c)
+	}
+	for _, img := range resolved.Images {
+		if prev, ok := images[img.Ref]; ok && !update(img.Component) {
+			img = prev
+		}
+		merged.Images = append(merged.Images, img)
+	}
+	merged.sort()
+	return merged
+}
+
+// Change is a difference between two lockfiles. From is empty for added
+// entries and To for removed ones.
+type Change struct {
+	Component string
+	Name      string
+	From      string
+	To        string
+}
+
+// Diff lists what moves from one lockfile to the next. Charts are compared by
+// version and digest, images by digest. from may be nil.
+func Diff(from, to *File) []Change {
+	if from == nil {
+		from = &File{}
+	}
+	describe := func(c Chart) string { return c.Version + " (" + shortDigest(c.Digest) + ")" }
+
+	var changes []Change
+	before := map[string]Chart{}
+	for _, c := range from.Charts {
+		before[c.Component+" "+c.Chart] = c
+	}
+	for _, c := range to.Charts {
+		key := c.Component + " " + c.Chart
+		prev, ok := before[key]
+		delete(before, key)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Component: c.Component, Name: c.Chart, To: describe(c)})
+		case prev.Version != c.Version || prev.Digest != c.Digest:
+			changes = append(changes, Change{Component: c.Component, Name: c.Chart, From: describe(prev), To: describe(c)})
+		}
+	}
+	for _, c := range before {
+		changes = append(changes, Change{Component: c.Component, Name: c.Chart, From: describe(c)})
+	}
+
+	beforeImages := map[string]Image{}
+	for _, img := range from.Images {
+		beforeImages[img.Ref] = img
+	}
+	for _, img := range to.Images {
+		prev, ok := beforeImages[img.Ref]
+		delete(beforeImages, img.Ref)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Component: img.Component, Name: img.Ref, To: shortDigest(img.Digest)})
+		case prev.Digest != img.Digest:
+			changes = append(changes, Change{Component: img.Component, Name: img.Ref, From: shortDigest(prev.Digest), To: shortDigest(img.Digest)})
+		}
+	}
+	for _, img := range beforeImages {
+		changes = append(changes, Change{Component: img.Component, Name: img.Ref, From: shortDigest(img.Digest)})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Component != changes[j].Component {
+			return changes[i].Component < changes[j].Component
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// sort orders entries by component, then name, so lockfiles diff cleanly
+func (f *File) sort() {
+	sort.Slice(f.Charts, func(i, j int) bool {
+		if f.Charts[i].Component != f.Charts[j].Component {
+			return f.Charts[i].Component < f.Charts[j].Component
+		}
+		return f.Charts[i].Chart < f.Charts[j].Chart
+	})
+	sort.Slice(f.Images, func(i, j int) bool {
+		if f.Images[i].Component != f.Images[j].Component {
+			return f.Images[i].Component < f.Images[j].Component
+		}
+		return f.Images[i].Ref < f.Images[j].Ref
+	})
+}
+
+// shortDigest abbreviates a digest for display
+func shortDigest(digest string) string {
+	if _, hex, ok := strings.Cut(digest, ":"); ok && len(hex) > 12 {
+		return hex[:12]
+	}
+	return digest
+}
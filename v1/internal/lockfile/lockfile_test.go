@@ -0,0 +1,116 @@
+package lockfile
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/bundle"
+	"github.com/catalystcommunity/foundry/v1/internal/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func digest(c string) string {
+	return "sha256:" + strings.Repeat(c, 64)
+}
+
+func sample() *File {
+	return &File{
+		Charts: []Chart{
+			{Component: "grafana", Chart: "grafana/grafana", RepoURL: "https://grafana.github.io/helm-charts", Version: "8.8.2", Digest: digest("a")},
+		},
+		Images: []Image{
+			{Component: "grafana", Ref: "docker.io/grafana/grafana:11.4.0", Digest: digest("b")},
+			{Component: "openbao", Ref: "quay.io/openbao/openbao:2.0.0", Digest: digest("c")},
+		},
+	}
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/home/u/.foundry/stack.lock.yaml", Path("/home/u/.foundry/stack.yaml"))
+	assert.Equal(t, "/home/u/.foundry/prod.lock.yaml", Path("/home/u/.foundry/prod.yml"))
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "stack.yaml")
+
+	f, err := LoadForConfig(configPath)
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	require.NoError(t, sample().Save(Path(configPath)))
+	f, err = LoadForConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, sample(), f)
+}
+
+func TestHelmLock(t *testing.T) {
+	lock := sample().HelmLock()
+	assert.Equal(t, helm.LockedChart{Version: "8.8.2", Digest: digest("a")}, lock.Charts["grafana/grafana"])
+	assert.Equal(t, digest("c"), lock.Images["quay.io/openbao/openbao:2.0.0"])
+}
+
+func TestPin(t *testing.T) {
+	plan := &bundle.Plan{Charts: []bundle.PlannedChart{
+		{Component: "grafana", ChartSource: helm.ChartSource{Chart: "grafana/grafana", Version: "9.0.0"}},
+		{Component: "loki", ChartSource: helm.ChartSource{Chart: "grafana/loki", Version: "6.0.0"}},
+	}}
+	sample().Pin(plan)
+	assert.Equal(t, "8.8.2", plan.Charts[0].Version)
+	assert.Equal(t, "6.0.0", plan.Charts[1].Version)
+	assert.Equal(t, digest("b"), plan.ImageDigests["docker.io/grafana/grafana:11.4.0"])
+}
+
+func TestMerge(t *testing.T) {
+	locked := sample()
+	resolved := &File{
+		Charts: []Chart{
+			{Component: "grafana", Chart: "grafana/grafana", Version: "8.9.0", Digest: digest("d")},
+			{Component: "loki", Chart: "grafana/loki", Version: "6.0.0", Digest: digest("e")},
+		},
+		Images: []Image{
+			{Component: "grafana", Ref: "docker.io/grafana/grafana:11.4.0", Digest: digest("f")},
+		},
+	}
+
+	t.Run("keeps locked entries", func(t *testing.T) {
+		merged := Merge(locked, resolved, func(string) bool { return false })
+		require.Len(t, merged.Charts, 2)
+		assert.Equal(t, "8.8.2", merged.Charts[0].Version)
+		assert.Equal(t, "grafana/loki", merged.Charts[1].Chart)
+		// openbao is no longer used, so it is dropped
+		require.Len(t, merged.Images, 1)
+		assert.Equal(t, digest("b"), merged.Images[0].Digest)
+	})
+
+	t.Run("updates one component", func(t *testing.T) {
+		merged := Merge(locked, resolved, func(c string) bool { return c == "grafana" })
+		assert.Equal(t, "8.9.0", merged.Charts[0].Version)
+		assert.Equal(t, digest("f"), merged.Images[0].Digest)
+	})
+
+	t.Run("no lockfile yet", func(t *testing.T) {
+		assert.Equal(t, resolved, Merge(nil, resolved, nil))
+	})
+}
+
+func TestDiff(t *testing.T) {
+	from := sample()
+	to := sample()
+	to.Charts[0].Version = "8.9.0"
+	to.Charts[0].Digest = digest("d")
+	to.Images = to.Images[:1]
+	to.Images = append(to.Images, Image{Component: "zot", Ref: "ghcr.io/project-zot/zot:latest", Digest: digest("e")})
+
+	changes := Diff(from, to)
+	assert.Equal(t, []Change{
+		{Component: "grafana", Name: "grafana/grafana", From: "8.8.2 (aaaaaaaaaaaa)", To: "8.9.0 (dddddddddddd)"},
+		{Component: "openbao", Name: "quay.io/openbao/openbao:2.0.0", From: "cccccccccccc"},
+		{Component: "zot", Name: "ghcr.io/project-zot/zot:latest", To: "eeeeeeeeeeee"},
+	}, changes)
+
+	assert.Empty(t, Diff(sample(), sample()))
+	assert.Len(t, Diff(nil, sample()), 3)
+}
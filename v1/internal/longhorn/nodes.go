@@ -0,0 +1,64 @@
+package longhorn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var nodeGVR = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "nodes"}
+
+// NodeDisk is a disk of a Longhorn node
+type NodeDisk struct {
+	// Name is the disk's key in the node's spec
+	Name            string
+	Path            string
+	AllowScheduling bool
+}
+
+// NodeDisks lists the disks of a Longhorn node, by name
+func (c *Client) NodeDisks(ctx context.Context, node string) ([]NodeDisk, error) {
+	obj, err := c.Dynamic.Resource(nodeGVR).Namespace(c.namespace()).Get(ctx, node, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Longhorn node %s: %w", node, err)
+	}
+	specDisks, _, _ := unstructured.NestedMap(obj.Object, "spec", "disks")
+	disks := make([]NodeDisk, 0, len(specDisks))
+	for name, raw := range specDisks {
+		spec, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		disk := NodeDisk{Name: name}
+		disk.Path, _ = spec["path"].(string)
+		disk.AllowScheduling, _ = spec["allowScheduling"].(bool)
+		disks = append(disks, disk)
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Name < disks[j].Name })
+	return disks, nil
+}
+
+// DisableDiskScheduling stops Longhorn from placing new replicas on a disk.
+// Replicas already on the disk stay until they are evicted.
+func (c *Client) DisableDiskScheduling(ctx context.Context, node, disk string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"disks": map[string]interface{}{
+				disk: map[string]interface{}{"allowScheduling": false},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.Dynamic.Resource(nodeGVR).Namespace(c.namespace()).Patch(ctx, node, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to disable scheduling on Longhorn disk %s of node %s: %w", disk, node, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package longhorn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/catalystcommunity/foundry/v1/internal/pvcmigrate"
+)
+
+const (
+	// backendService is the Longhorn manager's API service and port
+	backendService = "longhorn-backend:9500"
+
+	// attachmentID identifies Foundry's maintenance-mode attachment, so the
+	// detach releases only that attachment
+	attachmentID = "foundry-snapshot-restore"
+)
+
+// API calls actions on Longhorn volumes through the Longhorn manager
+type API interface {
+	VolumeAction(ctx context.Context, volume, action string, input interface{}) error
+}
+
+// proxyAPI reaches the Longhorn manager through the API server's service
+// proxy, so it works from outside the cluster network
+type proxyAPI struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewProxyAPI returns an API that calls the Longhorn manager in the
+// namespace through the service proxy
+func NewProxyAPI(client kubernetes.Interface, namespace string) API {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &proxyAPI{client: client, namespace: namespace}
+}
+
+// VolumeAction posts the action to the volume
+func (a *proxyAPI) VolumeAction(ctx context.Context, volume, action string, input interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	res, err := a.client.CoreV1().RESTClient().Post().
+		Namespace(a.namespace).
+		Resource("services").
+		Name(backendService).
+		SubResource("proxy").
+		Suffix("v1", "volumes", volume).
+		Param("action", action).
+		SetHeader("Content-Type", "application/json").
+		Body(bytes.NewReader(body)).
+		DoRaw(ctx)
+	if err != nil {
+		if msg := strings.TrimSpace(string(res)); msg != "" {
+			return fmt.Errorf("longhorn %s on volume %s: %w: %s", action, volume, err, msg)
+		}
+		return fmt.Errorf("longhorn %s on volume %s: %w", action, volume, err)
+	}
+	return nil
+}
+
+// Restore reverts a PVC's volume to one of its snapshots. Longhorn only
+// reverts a volume attached in maintenance mode, with no workload using it:
+//
+//  1. The Deployments and StatefulSets that mount the claim are scaled to
+//     zero and the volume is left to detach.
+//  2. The volume is attached without a frontend and reverted.
+//  3. The volume is detached and the workloads are scaled back up.
+//
+// Everything written after the snapshot was taken is lost.
+func (c *Client) Restore(ctx context.Context, namespace, claim, snapshot string) error {
+	volume, err := c.VolumeForClaim(ctx, namespace, claim)
+	if err != nil {
+		return err
+	}
+	snapshots, err := c.Snapshots(ctx, volume)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, s := range snapshots {
+		if s.Name == snapshot {
+			found = s.Ready
+		}
+	}
+	if !found {
+		return fmt.Errorf("volume %s has no ready snapshot %q\n\nList them with: foundry storage snapshot list %s/%s", volume, snapshot, namespace, claim)
+	}
+
+	workloads, err := pvcmigrate.ClaimWorkloads(ctx, c.Kube, namespace, claim)
+	if err != nil {
+		return err
+	}
+	scaler := &pvcmigrate.Migrator{Client: c.Kube, Out: c.Out, PollInterval: c.PollInterval}
+	plan := &pvcmigrate.Plan{Namespace: namespace, Claim: claim, Workloads: workloads}
+	if err := scaler.ScaleDown(ctx, plan); err != nil {
+		c.scaleUp(scaler, plan)
+		return err
+	}
+
+	if err := c.revert(ctx, volume, snapshot); err != nil {
+		c.scaleUp(scaler, plan)
+		return err
+	}
+	c.scaleUp(scaler, plan)
+
+	fmt.Fprintf(c.Out, "\n✓ PVC %s/%s reverted to snapshot %s\n", namespace, claim, snapshot)
+	return nil
+}
+
+// scaleUp returns the workloads to their replica counts on its own context,
+// since the restore's may have ended
+func (c *Client) scaleUp(scaler *pvcmigrate.Migrator, plan *pvcmigrate.Plan) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	scaler.ScaleUp(ctx, plan)
+}
+
+// revert attaches the detached volume in maintenance mode, reverts it and
+// detaches it again
+func (c *Client) revert(ctx context.Context, volume, snapshot string) error {
+	if err := c.waitForState(ctx, volume, "detached"); err != nil {
+		return err
+	}
+	node, err := c.volumeOwner(ctx, volume)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Out, "Attaching volume %s to %s in maintenance mode...\n", volume, node)
+	if err := c.API.VolumeAction(ctx, volume, "attach", map[string]interface{}{
+		"hostId":          node,
+		"disableFrontend": true,
+		"attacherType":    "longhorn-api",
+		"attachmentID":    attachmentID,
+	}); err != nil {
+		return err
+	}
+	revertErr := c.waitForState(ctx, volume, "attached")
+	if revertErr == nil {
+		fmt.Fprintf(c.Out, "Reverting volume %s to snapshot %s...\n", volume, snapshot)
+		revertErr = c.API.VolumeAction(ctx, volume, "snapshotRevert", map[string]interface{}{"name": snapshot})
+	}
+
+	// Detach even when the revert failed, so the workloads can attach the
+	// volume again
+	detachCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	fmt.Fprintf(c.Out, "Detaching volume %s...\n", volume)
+	if err := c.API.VolumeAction(detachCtx, volume, "detach", map[string]interface{}{
+		"hostId":       node,
+		"attachmentID": attachmentID,
+	}); err != nil {
+		if revertErr != nil {
+			return fmt.Errorf("%w (and the volume could not be detached: %v)", revertErr, err)
+		}
+		return err
+	}
+	if revertErr != nil {
+		return revertErr
+	}
+	return c.waitForState(detachCtx, volume, "detached")
+}
+
+// volumeOwner returns the node whose Longhorn manager owns the volume
+func (c *Client) volumeOwner(ctx context.Context, volume string) (string, error) {
+	obj, err := c.Dynamic.Resource(volumeGVR).Namespace(c.namespace()).Get(ctx, volume, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Longhorn volume %s: %w", volume, err)
+	}
+	owner, _, _ := unstructured.NestedString(obj.Object, "status", "ownerID")
+	if owner == "" {
+		return "", fmt.Errorf("longhorn volume %s has no owner node", volume)
+	}
+	return owner, nil
+}
+
+// waitForState waits until the Longhorn volume reaches the state
+func (c *Client) waitForState(ctx context.Context, volume, state string) error {
+	fmt.Fprintf(c.Out, "Waiting for volume %s to be %s...\n", volume, state)
+	return c.waitFor(ctx, fmt.Sprintf("volume %s to be %s", volume, state), func() (bool, error) {
+		obj, err := c.Dynamic.Resource(volumeGVR).Namespace(c.namespace()).Get(ctx, volume, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get Longhorn volume %s: %w", volume, err)
+		}
+		current, _, _ := unstructured.NestedString(obj.Object, "status", "state")
+		return current == state, nil
+	})
+}
@@ -0,0 +1,85 @@
+package longhorn
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeAPI records volume actions and moves the volume between the attached
+// and detached states the way the Longhorn manager would
+type fakeAPI struct {
+	t         *testing.T
+	dynamic   dynamic.Interface
+	actions   []string
+	revertErr error
+}
+
+func (a *fakeAPI) VolumeAction(ctx context.Context, volume, action string, input interface{}) error {
+	a.actions = append(a.actions, action)
+	state := ""
+	switch action {
+	case "attach":
+		state = "attached"
+	case "detach":
+		state = "detached"
+	case "snapshotRevert":
+		a.actions[len(a.actions)-1] = fmt.Sprintf("%s %v", action, input.(map[string]interface{})["name"])
+		return a.revertErr
+	}
+	resource := a.dynamic.Resource(volumeGVR).Namespace(DefaultNamespace)
+	obj, err := resource.Get(ctx, volume, metav1.GetOptions{})
+	require.NoError(a.t, err)
+	require.NoError(a.t, unstructured.SetNestedField(obj.Object, state, "status", "state"))
+	_, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+	require.NoError(a.t, err)
+	return nil
+}
+
+func restoreClient(t *testing.T) (*Client, *fakeAPI) {
+	dyn := fakeDynamic(
+		testVolume("detached"),
+		testSnapshot("before-upgrade", "2026-03-01T10:00:00Z", map[string]interface{}{"readyToUse": true, "userCreated": true}),
+	)
+	api := &fakeAPI{t: t, dynamic: dyn}
+	return &Client{
+		Kube:         kubernetesfake.NewSimpleClientset(testClaim(Driver)...),
+		Dynamic:      dyn,
+		API:          api,
+		Out:          &bytes.Buffer{},
+		PollInterval: 1,
+	}, api
+}
+
+func TestRestore(t *testing.T) {
+	c, api := restoreClient(t)
+
+	require.NoError(t, c.Restore(context.Background(), "app", "data", "before-upgrade"))
+	assert.Equal(t, []string{"attach", "snapshotRevert before-upgrade", "detach"}, api.actions)
+	assert.Contains(t, c.Out.(*bytes.Buffer).String(), "reverted to snapshot before-upgrade")
+}
+
+func TestRestoreDetachesAfterFailedRevert(t *testing.T) {
+	c, api := restoreClient(t)
+	api.revertErr = fmt.Errorf("revert failed")
+
+	err := c.Restore(context.Background(), "app", "data", "before-upgrade")
+	assert.ErrorContains(t, err, "revert failed")
+	assert.Equal(t, []string{"attach", "snapshotRevert before-upgrade", "detach"}, api.actions)
+}
+
+func TestRestoreUnknownSnapshot(t *testing.T) {
+	c, api := restoreClient(t)
+
+	err := c.Restore(context.Background(), "app", "data", "missing")
+	assert.ErrorContains(t, err, `no ready snapshot "missing"`)
+	assert.Empty(t, api.actions)
+}
@@ -0,0 +1,202 @@
+// Package longhorn works with the snapshots of the PVCs Longhorn provisions
+// and the disks of Longhorn nodes
+package longhorn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultNamespace is where Longhorn is installed
+	DefaultNamespace = "longhorn-system"
+
+	// Driver is Longhorn's CSI driver name
+	Driver = "driver.longhorn.io"
+
+	// volumeLabel is the label Longhorn puts on a snapshot naming its volume
+	volumeLabel = "longhornvolume"
+)
+
+var (
+	snapshotGVR = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "snapshots"}
+	volumeGVR   = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "volumes"}
+)
+
+// Snapshot is a point-in-time snapshot of a Longhorn volume
+type Snapshot struct {
+	Name    string
+	Volume  string
+	Created time.Time
+	// Size is the data the snapshot holds on top of its parent, in bytes
+	Size int64
+	// Ready is false while the snapshot is being taken
+	Ready bool
+	// UserCreated is false for snapshots Longhorn takes itself, such as
+	// those taken before a replica rebuild
+	UserCreated bool
+	Labels      map[string]string
+}
+
+// Client works with Longhorn volumes, snapshots and nodes
+type Client struct {
+	Kube    kubernetes.Interface
+	Dynamic dynamic.Interface
+	// API calls the Longhorn manager, which reverts volumes
+	API API
+	// Namespace is Longhorn's namespace (default: longhorn-system)
+	Namespace string
+	Out       io.Writer
+	// PollInterval is how often progress is checked (default: 2s)
+	PollInterval time.Duration
+}
+
+func (c *Client) namespace() string {
+	if c.Namespace == "" {
+		return DefaultNamespace
+	}
+	return c.Namespace
+}
+
+// VolumeForClaim returns the Longhorn volume bound to a PVC
+func (c *Client) VolumeForClaim(ctx context.Context, namespace, claim string) (string, error) {
+	pvc, err := c.Kube.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get PVC %s/%s: %w", namespace, claim, err)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", fmt.Errorf("PVC %s/%s is not bound to a volume", namespace, claim)
+	}
+	pv, err := c.Kube.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get volume %s: %w", pvc.Spec.VolumeName, err)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != Driver {
+		return "", fmt.Errorf("PVC %s/%s is not a Longhorn volume; snapshots are only available for Longhorn storage", namespace, claim)
+	}
+	return pv.Spec.CSI.VolumeHandle, nil
+}
+
+// Snapshots lists a volume's snapshots, oldest first. Snapshots Longhorn
+// has marked for removal are left out.
+func (c *Client) Snapshots(ctx context.Context, volume string) ([]Snapshot, error) {
+	list, err := c.Dynamic.Resource(snapshotGVR).Namespace(c.namespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", volumeLabel, volume),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots of volume %s: %w", volume, err)
+	}
+	snapshots := make([]Snapshot, 0, len(list.Items))
+	for i := range list.Items {
+		item := &list.Items[i]
+		if removed, _, _ := unstructured.NestedBool(item.Object, "status", "markRemoved"); removed {
+			continue
+		}
+		snapshots = append(snapshots, snapshotFrom(item))
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].Created.Equal(snapshots[j].Created) {
+			return snapshots[i].Created.Before(snapshots[j].Created)
+		}
+		return snapshots[i].Name < snapshots[j].Name
+	})
+	return snapshots, nil
+}
+
+// CreateSnapshot takes a snapshot of the volume and waits until it is ready.
+// An empty name gets one from the volume and the current time.
+func (c *Client) CreateSnapshot(ctx context.Context, volume, name string, labels map[string]string) (*Snapshot, error) {
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", volume, time.Now().UTC().Format("20060102-150405"))
+	}
+	spec := map[string]interface{}{
+		"volume":         volume,
+		"createSnapshot": true,
+	}
+	if len(labels) > 0 {
+		specLabels := make(map[string]interface{}, len(labels))
+		for k, v := range labels {
+			specLabels[k] = v
+		}
+		spec["labels"] = specLabels
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "longhorn.io/v1beta2",
+		"kind":       "Snapshot",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": c.namespace(),
+			"labels":    map[string]interface{}{volumeLabel: volume},
+		},
+		"spec": spec,
+	}}
+	resource := c.Dynamic.Resource(snapshotGVR).Namespace(c.namespace())
+	if _, err := resource.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot %s: %w", name, err)
+	}
+
+	var snapshot Snapshot
+	err := c.waitFor(ctx, fmt.Sprintf("snapshot %s", name), func() (bool, error) {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get snapshot %s: %w", name, err)
+		}
+		if msg, _, _ := unstructured.NestedString(obj.Object, "status", "error"); msg != "" {
+			return false, fmt.Errorf("snapshot %s failed: %s", name, msg)
+		}
+		snapshot = snapshotFrom(obj)
+		return snapshot.Ready, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// snapshotFrom reads a Snapshot resource
+func snapshotFrom(obj *unstructured.Unstructured) Snapshot {
+	snapshot := Snapshot{Name: obj.GetName(), Created: obj.GetCreationTimestamp().Time}
+	snapshot.Volume, _, _ = unstructured.NestedString(obj.Object, "spec", "volume")
+	if created, _, _ := unstructured.NestedString(obj.Object, "status", "creationTime"); created != "" {
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			snapshot.Created = t
+		}
+	}
+	snapshot.Size, _, _ = unstructured.NestedInt64(obj.Object, "status", "size")
+	snapshot.Ready, _, _ = unstructured.NestedBool(obj.Object, "status", "readyToUse")
+	snapshot.UserCreated, _, _ = unstructured.NestedBool(obj.Object, "status", "userCreated")
+	snapshot.Labels, _, _ = unstructured.NestedStringMap(obj.Object, "status", "labels")
+	return snapshot
+}
+
+// waitFor polls until done reports true, returns an error, or the context
+// ends
+func (c *Client) waitFor(ctx context.Context, what string, done func() (bool, error)) error {
+	interval := c.PollInterval
+	if interval == 0 {
+		interval = 2 * time.Second
+	}
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s", what)
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package longhorn
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testClaim(driver string) []runtime.Object {
+	return []runtime.Object{
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234"},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: "pvc-1234"},
+			}},
+		},
+	}
+}
+
+func testSnapshot(name, created string, status map[string]interface{}) *unstructured.Unstructured {
+	if status == nil {
+		status = map[string]interface{}{}
+	}
+	status["creationTime"] = created
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "longhorn.io/v1beta2",
+		"kind":       "Snapshot",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": DefaultNamespace,
+			"labels":    map[string]interface{}{volumeLabel: "pvc-1234"},
+		},
+		"spec":   map[string]interface{}{"volume": "pvc-1234"},
+		"status": status,
+	}}
+}
+
+func testVolume(state string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "longhorn.io/v1beta2",
+		"kind":       "Volume",
+		"metadata":   map[string]interface{}{"name": "pvc-1234", "namespace": DefaultNamespace},
+		"status":     map[string]interface{}{"state": state, "ownerID": "node-a"},
+	}}
+}
+
+func fakeDynamic(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		snapshotGVR: "SnapshotList",
+		volumeGVR:   "VolumeList",
+	}, objects...)
+}
+
+func TestVolumeForClaim(t *testing.T) {
+	c := &Client{Kube: kubernetesfake.NewSimpleClientset(testClaim(Driver)...)}
+	volume, err := c.VolumeForClaim(context.Background(), "app", "data")
+	require.NoError(t, err)
+	assert.Equal(t, "pvc-1234", volume)
+
+	c = &Client{Kube: kubernetesfake.NewSimpleClientset(testClaim("rancher.io/local-path")...)}
+	_, err = c.VolumeForClaim(context.Background(), "app", "data")
+	assert.ErrorContains(t, err, "not a Longhorn volume")
+
+	_, err = c.VolumeForClaim(context.Background(), "app", "missing")
+	assert.Error(t, err)
+}
+
+func TestSnapshots(t *testing.T) {
+	c := &Client{Dynamic: fakeDynamic(
+		testSnapshot("later", "2026-03-02T10:00:00Z", map[string]interface{}{"readyToUse": true, "userCreated": true, "size": int64(2048)}),
+		testSnapshot("earlier", "2026-03-01T10:00:00Z", map[string]interface{}{"readyToUse": true}),
+		testSnapshot("removed", "2026-03-01T11:00:00Z", map[string]interface{}{"markRemoved": true}),
+	)}
+
+	snapshots, err := c.Snapshots(context.Background(), "pvc-1234")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "earlier", snapshots[0].Name)
+	assert.False(t, snapshots[0].UserCreated)
+	assert.Equal(t, "later", snapshots[1].Name)
+	assert.Equal(t, int64(2048), snapshots[1].Size)
+	assert.True(t, snapshots[1].Ready)
+	assert.True(t, snapshots[1].UserCreated)
+	assert.Equal(t, "pvc-1234", snapshots[1].Volume)
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	dyn := fakeDynamic()
+	dyn.PrependReactor("create", "snapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		require.NoError(t, unstructured.SetNestedField(obj.Object, true, "status", "readyToUse"))
+		return false, nil, nil
+	})
+	c := &Client{Dynamic: dyn, Out: &bytes.Buffer{}, PollInterval: 1}
+
+	snapshot, err := c.CreateSnapshot(context.Background(), "pvc-1234", "before-upgrade", map[string]string{"reason": "upgrade"})
+	require.NoError(t, err)
+	assert.Equal(t, "before-upgrade", snapshot.Name)
+	assert.True(t, snapshot.Ready)
+
+	obj, err := dyn.Resource(snapshotGVR).Namespace(DefaultNamespace).Get(context.Background(), "before-upgrade", metav1.GetOptions{})
+	require.NoError(t, err)
+	createSnapshot, _, _ := unstructured.NestedBool(obj.Object, "spec", "createSnapshot")
+	assert.True(t, createSnapshot)
+	labels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "labels")
+	assert.Equal(t, map[string]string{"reason": "upgrade"}, labels)
+	assert.Equal(t, "pvc-1234", obj.GetLabels()[volumeLabel])
+}
+
+func TestCreateSnapshotDefaultName(t *testing.T) {
+	dyn := fakeDynamic()
+	dyn.PrependReactor("create", "snapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		require.NoError(t, unstructured.SetNestedField(obj.Object, "volume is detached", "status", "error"))
+		return false, nil, nil
+	})
+	c := &Client{Dynamic: dyn, PollInterval: 1}
+
+	_, err := c.CreateSnapshot(context.Background(), "pvc-1234", "", nil)
+	assert.ErrorContains(t, err, "volume is detached")
+	assert.Regexp(t, `snapshot pvc-1234-\d{8}-\d{6} failed`, err.Error())
+}
+
+func TestNodeDisks(t *testing.T) {
+	node := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "longhorn.io/v1beta2",
+		"kind":       "Node",
+		"metadata":   map[string]interface{}{"name": "node-a", "namespace": DefaultNamespace},
+		"spec": map[string]interface{}{"disks": map[string]interface{}{
+			"disk-longhorn-sdb": map[string]interface{}{"path": "/mnt/longhorn-sdb", "allowScheduling": true, "storageReserved": int64(0)},
+			"default-disk-abc":  map[string]interface{}{"path": "/var/lib/longhorn", "allowScheduling": true},
+		}},
+	}}
+	c := &Client{Dynamic: fakeDynamic(node)}
+
+	disks, err := c.NodeDisks(context.Background(), "node-a")
+	require.NoError(t, err)
+	assert.Equal(t, []NodeDisk{
+		{Name: "default-disk-abc", Path: "/var/lib/longhorn", AllowScheduling: true},
+		{Name: "disk-longhorn-sdb", Path: "/mnt/longhorn-sdb", AllowScheduling: true},
+	}, disks)
+
+	require.NoError(t, c.DisableDiskScheduling(context.Background(), "node-a", "disk-longhorn-sdb"))
+	disks, err = c.NodeDisks(context.Background(), "node-a")
+	require.NoError(t, err)
+	assert.True(t, disks[0].AllowScheduling)
+	assert.False(t, disks[1].AllowScheduling)
+	assert.Equal(t, "/mnt/longhorn-sdb", disks[1].Path, "the patch leaves the rest of the disk spec alone")
+}
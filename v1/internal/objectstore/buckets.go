@@ -0,0 +1,246 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// listPageSize is how many filer entries are read per request
+const listPageSize = 1000
+
+// modeDir is the directory bit of a filer entry's mode (os.ModeDir)
+const modeDir = 1 << 31
+
+// bucketName follows the S3 naming rules SeaweedFS enforces
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// Bucket is an S3 bucket
+type Bucket struct {
+	Name    string
+	Created time.Time
+}
+
+// BucketUsage is the storage a bucket takes on the volume servers
+type BucketUsage struct {
+	Name string
+	// Size is the bytes held, not counting deleted data awaiting compaction
+	Size int64
+	// Files is the number of stored files; large objects are split into
+	// several
+	Files int64
+}
+
+// filerEntry is an entry of a filer directory listing
+type filerEntry struct {
+	FullPath string    `json:"FullPath"`
+	Crtime   time.Time `json:"Crtime"`
+	Mode     uint32    `json:"Mode"`
+}
+
+type filerListing struct {
+	Entries               []filerEntry `json:"Entries"`
+	LastFileName          string       `json:"LastFileName"`
+	ShouldDisplayLoadMore bool         `json:"ShouldDisplayLoadMore"`
+}
+
+// ValidateBucketName checks a bucket name against the S3 naming rules
+func ValidateBucketName(name string) error {
+	if !bucketName.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid bucket name %q: use 3-63 lowercase letters, digits, dots and hyphens, starting and ending with a letter or digit", name)
+	}
+	return nil
+}
+
+// Buckets lists the buckets, by name
+func (m *Manager) Buckets(ctx context.Context) ([]Bucket, error) {
+	entries, err := m.list(ctx, bucketsDir+"/", 0)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	var buckets []Bucket
+	for _, entry := range entries {
+		if entry.Mode&modeDir == 0 {
+			continue
+		}
+		buckets = append(buckets, Bucket{Name: strings.TrimPrefix(entry.FullPath, bucketsDir+"/"), Created: entry.Crtime})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	return buckets, nil
+}
+
+// list reads a filer directory, a page at a time, up to limit entries
+// (0 for all)
+func (m *Manager) list(ctx context.Context, dir string, limit int) ([]filerEntry, error) {
+	var entries []filerEntry
+	lastFileName := ""
+	for {
+		params := url.Values{"limit": {fmt.Sprint(listPageSize)}}
+		if lastFileName != "" {
+			params.Set("lastFileName", lastFileName)
+		}
+		res, err := m.API.Do(ctx, "GET", filerService, dir, params, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page filerListing
+		if err := json.Unmarshal(res, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse the listing of %s: %w", dir, err)
+		}
+		entries = append(entries, page.Entries...)
+		if (limit > 0 && len(entries) >= limit) || !page.ShouldDisplayLoadMore || page.LastFileName == "" {
+			return entries, nil
+		}
+		lastFileName = page.LastFileName
+	}
+}
+
+// hasBucket reports whether the bucket exists
+func (m *Manager) hasBucket(ctx context.Context, name string) (bool, error) {
+	buckets, err := m.Buckets(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, b := range buckets {
+		if b.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateBucket creates an empty bucket
+func (m *Manager) CreateBucket(ctx context.Context, name string) error {
+	if err := ValidateBucketName(name); err != nil {
+		return err
+	}
+	exists, err := m.hasBucket(ctx, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("bucket %s already exists", name)
+	}
+	// The filer makes a directory for a POST to a path ending in a slash
+	if _, err := m.API.Do(ctx, "POST", filerService, fmt.Sprintf("%s/%s/", bucketsDir, name), nil, nil); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteBucket deletes a bucket. A bucket holding objects is only deleted
+// with force, and a bucket an identity has access to not at all.
+func (m *Manager) DeleteBucket(ctx context.Context, name string, force bool) error {
+	exists, err := m.hasBucket(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s not found", name)
+	}
+
+	identities, err := m.Identities(ctx)
+	if err != nil {
+		return err
+	}
+	var users []string
+	for _, identity := range identities {
+		if identity.Name != AdminIdentity && identity.Access(name) != "" {
+			users = append(users, identity.Name)
+		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("bucket %s is used by %s\n\nRevoke their credentials, or reissue them without this bucket, first", name, strings.Join(users, ", "))
+	}
+
+	dir := fmt.Sprintf("%s/%s", bucketsDir, name)
+	if !force {
+		objects, err := m.list(ctx, dir+"/", 1)
+		if err != nil {
+			return fmt.Errorf("failed to list bucket %s: %w", name, err)
+		}
+		if len(objects) > 0 {
+			return fmt.Errorf("bucket %s is not empty; pass --force to delete it with its objects", name)
+		}
+	}
+
+	if _, err := m.API.Do(ctx, "DELETE", filerService, dir, url.Values{
+		"recursive":            {"true"},
+		"ignoreRecursiveError": {"true"},
+	}, nil); err != nil {
+		return fmt.Errorf("failed to delete bucket %s: %w", name, err)
+	}
+	// Each bucket's data is kept in a volume collection of the same name;
+	// dropping it frees the space at once rather than at the next vacuum
+	if _, err := m.API.Do(ctx, "POST", masterService, "/col/delete", url.Values{"collection": {name}}, nil); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("bucket %s deleted, but its volume collection was not: %w", name, err)
+	}
+	return nil
+}
+
+// volumeStatus is the master's /vol/status: volumes by data center, rack
+// and volume server
+type volumeStatus struct {
+	Volumes struct {
+		DataCenters map[string]map[string]map[string][]volumeInfo `json:"DataCenters"`
+	} `json:"Volumes"`
+}
+
+type volumeInfo struct {
+	ID               uint32 `json:"Id"`
+	Collection       string `json:"Collection"`
+	Size             int64  `json:"Size"`
+	FileCount        int64  `json:"FileCount"`
+	DeleteCount      int64  `json:"DeleteCount"`
+	DeletedByteCount int64  `json:"DeletedByteCount"`
+}
+
+// Usage returns the storage of every bucket, by name. Replicas of a volume
+// are counted once.
+func (m *Manager) Usage(ctx context.Context) ([]BucketUsage, error) {
+	buckets, err := m.Buckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.API.Do(ctx, "GET", masterService, "/vol/status", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read volume status: %w", err)
+	}
+	var status volumeStatus
+	if err := json.Unmarshal(res, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse volume status: %w", err)
+	}
+
+	byName := make(map[string]*BucketUsage, len(buckets))
+	usage := make([]BucketUsage, len(buckets))
+	for i, b := range buckets {
+		usage[i].Name = b.Name
+		byName[b.Name] = &usage[i]
+	}
+	seen := map[uint32]bool{}
+	for _, racks := range status.Volumes.DataCenters {
+		for _, servers := range racks {
+			for _, volumes := range servers {
+				for _, v := range volumes {
+					bucket, ok := byName[v.Collection]
+					if !ok || seen[v.ID] {
+						continue
+					}
+					seen[v.ID] = true
+					bucket.Size += v.Size - v.DeletedByteCount
+					bucket.Files += v.FileCount - v.DeleteCount
+				}
+			}
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,205 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSeaweedFS serves the filer and master requests the manager makes
+// from an in-memory filesystem
+type fakeSeaweedFS struct {
+	// dirs and files by full path
+	dirs  map[string]time.Time
+	files map[string][]byte
+	// volumes is the master's volume list
+	volumes []volumeInfo
+	// deletedCollections records /col/delete calls
+	deletedCollections []string
+}
+
+func newFakeSeaweedFS(buckets ...string) *fakeSeaweedFS {
+	f := &fakeSeaweedFS{dirs: map[string]time.Time{bucketsDir: {}}, files: map[string][]byte{}}
+	for i, b := range buckets {
+		f.dirs[bucketsDir+"/"+b] = time.Date(2026, 1, i+1, 0, 0, 0, 0, time.UTC)
+	}
+	return f
+}
+
+func (f *fakeSeaweedFS) Do(_ context.Context, method, service, path string, params url.Values, body []byte) ([]byte, error) {
+	if service == masterService {
+		switch path {
+		case "/vol/status":
+			var status volumeStatus
+			status.Volumes.DataCenters = map[string]map[string]map[string][]volumeInfo{
+				"dc1": {"rack1": {"10.0.0.1:8080": f.volumes, "10.0.0.2:8080": f.volumes}},
+			}
+			return json.Marshal(status)
+		case "/col/delete":
+			f.deletedCollections = append(f.deletedCollections, params.Get("collection"))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s %s: %w", method, path, ErrNotFound)
+	}
+
+	clean := strings.TrimSuffix(path, "/")
+	switch method {
+	case "GET":
+		if data, ok := f.files[clean]; ok {
+			return data, nil
+		}
+		if _, ok := f.dirs[clean]; !ok {
+			return nil, fmt.Errorf("%s %s: %w", method, path, ErrNotFound)
+		}
+		var listing filerListing
+		for _, name := range f.children(clean) {
+			entry := filerEntry{FullPath: name}
+			if created, ok := f.dirs[name]; ok {
+				entry.Mode = modeDir | 0o755
+				entry.Crtime = created
+			}
+			listing.Entries = append(listing.Entries, entry)
+		}
+		return json.Marshal(listing)
+	case "POST":
+		if !strings.HasSuffix(path, "/") {
+			return nil, fmt.Errorf("unexpected upload to %s", path)
+		}
+		f.dirs[clean] = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		return nil, nil
+	case "PUT":
+		f.files[clean] = body
+		return nil, nil
+	case "DELETE":
+		if params.Get("recursive") != "true" && len(f.children(clean)) > 0 {
+			return nil, fmt.Errorf("directory %s is not empty", clean)
+		}
+		for name := range f.dirs {
+			if name == clean || strings.HasPrefix(name, clean+"/") {
+				delete(f.dirs, name)
+			}
+		}
+		for name := range f.files {
+			if strings.HasPrefix(name, clean+"/") {
+				delete(f.files, name)
+			}
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected %s %s", method, path)
+}
+
+// children lists the direct children of a directory
+func (f *fakeSeaweedFS) children(dir string) []string {
+	var names []string
+	for _, paths := range []map[string]bool{f.dirNames(), f.fileNames()} {
+		for name := range paths {
+			if parent := name[:strings.LastIndex(name, "/")]; parent == dir {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakeSeaweedFS) dirNames() map[string]bool {
+	names := map[string]bool{}
+	for name := range f.dirs {
+		names[name] = true
+	}
+	return names
+}
+
+func (f *fakeSeaweedFS) fileNames() map[string]bool {
+	names := map[string]bool{}
+	for name := range f.files {
+		names[name] = true
+	}
+	return names
+}
+
+func TestValidateBucketName(t *testing.T) {
+	for _, name := range []string{"loki", "app-data", "my.bucket", "a1b"} {
+		assert.NoError(t, ValidateBucketName(name), name)
+	}
+	for _, name := range []string{"ab", "Uppercase", "-leading", "trailing-", "under_score", "two..dots", strings.Repeat("a", 64)} {
+		assert.Error(t, ValidateBucketName(name), name)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	fs := newFakeSeaweedFS("velero", "loki")
+	fs.files[bucketsDir+"/stray-file"] = []byte("x")
+	m := &Manager{API: fs}
+
+	buckets, err := m.Buckets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, buckets, 2, "files in /buckets are not buckets")
+	assert.Equal(t, "loki", buckets[0].Name)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), buckets[0].Created)
+	assert.Equal(t, "velero", buckets[1].Name)
+
+	m = &Manager{API: &fakeSeaweedFS{dirs: map[string]time.Time{}}}
+	buckets, err = m.Buckets(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, buckets, "no /buckets directory yet")
+}
+
+func TestCreateBucket(t *testing.T) {
+	fs := newFakeSeaweedFS("loki")
+	m := &Manager{API: fs}
+
+	require.NoError(t, m.CreateBucket(context.Background(), "app-data"))
+	assert.Contains(t, fs.dirs, "/buckets/app-data")
+
+	assert.ErrorContains(t, m.CreateBucket(context.Background(), "loki"), "already exists")
+	assert.ErrorContains(t, m.CreateBucket(context.Background(), "Bad_Name"), "invalid bucket name")
+}
+
+func TestDeleteBucket(t *testing.T) {
+	fs := newFakeSeaweedFS("loki", "scratch", "shared")
+	fs.files["/buckets/loki/index/chunk"] = []byte("data")
+	fs.dirs["/buckets/loki/index"] = time.Time{}
+	m := testManager(fs)
+	_, err := m.Issue(context.Background(), "web", []string{"shared"}, true, false)
+	require.NoError(t, err)
+
+	require.NoError(t, m.DeleteBucket(context.Background(), "scratch", false))
+	assert.NotContains(t, fs.dirs, "/buckets/scratch")
+	assert.Equal(t, []string{"scratch"}, fs.deletedCollections)
+
+	assert.ErrorContains(t, m.DeleteBucket(context.Background(), "loki", false), "not empty")
+	require.NoError(t, m.DeleteBucket(context.Background(), "loki", true))
+	assert.NotContains(t, fs.files, "/buckets/loki/index/chunk")
+
+	assert.ErrorContains(t, m.DeleteBucket(context.Background(), "shared", true), "used by web")
+	assert.ErrorContains(t, m.DeleteBucket(context.Background(), "missing", false), "not found")
+}
+
+func TestUsage(t *testing.T) {
+	fs := newFakeSeaweedFS("loki", "velero", "empty")
+	fs.volumes = []volumeInfo{
+		{ID: 1, Collection: "loki", Size: 1000, FileCount: 10, DeletedByteCount: 100, DeleteCount: 1},
+		{ID: 2, Collection: "loki", Size: 500, FileCount: 5},
+		{ID: 3, Collection: "velero", Size: 2000, FileCount: 2},
+		{ID: 4, Collection: "", Size: 9999, FileCount: 9},
+	}
+	m := &Manager{API: fs}
+
+	usage, err := m.Usage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []BucketUsage{
+		{Name: "empty"},
+		{Name: "loki", Size: 1400, Files: 14},
+		{Name: "velero", Size: 2000, Files: 2},
+	}, usage, "replicas on the second server are counted once")
+}
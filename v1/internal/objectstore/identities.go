@@ -0,0 +1,360 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// AdminIdentity holds the stack's shared credential
+	AdminIdentity = "foundry-admin"
+
+	// SecretMount is the OpenBAO KV v2 mount issued credentials are stored in
+	SecretMount = "foundry-core"
+
+	// identityFile is SeaweedFS' S3 IAM config. The S3 gateways watch it
+	// and reload when it changes. Once it names an identity, every request
+	// must be signed by one.
+	identityFile = "/etc/iam/identity.json"
+)
+
+// S3 actions SeaweedFS grants; all but Admin can be limited to a bucket
+// with an ":<bucket>" suffix
+const (
+	ActionAdmin   = "Admin"
+	ActionRead    = "Read"
+	ActionList    = "List"
+	ActionWrite   = "Write"
+	ActionTagging = "Tagging"
+)
+
+// Access levels reported for an identity on a bucket
+const (
+	AccessRead      = "read"
+	AccessWrite     = "write"
+	AccessReadWrite = "read-write"
+)
+
+// appName keeps application names usable as OpenBAO path segments and
+// policy names
+var appName = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Credential is an S3 access key pair
+type Credential struct {
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+}
+
+// Identity is an identity of SeaweedFS' S3 IAM config
+type Identity struct {
+	Name        string       `json:"name"`
+	Credentials []Credential `json:"credentials,omitempty"`
+	Actions     []string     `json:"actions,omitempty"`
+}
+
+// iamConfig is the content of identityFile
+type iamConfig struct {
+	Identities []Identity `json:"identities"`
+	// Accounts are kept as they are
+	Accounts []json.RawMessage `json:"accounts,omitempty"`
+}
+
+// Issued is a credential issued to an application
+type Issued struct {
+	App       string
+	AccessKey string
+	SecretKey string
+	Buckets   []string
+	// SecretPath is where the credential is stored in OpenBAO, under
+	// SecretMount
+	SecretPath string
+	// Policy is the OpenBAO policy that reads the credential
+	Policy string
+	// Rotated is true when the application had a credential already,
+	// which no longer works
+	Rotated bool
+}
+
+// CredentialPath is where an application's credential is stored in OpenBAO
+func CredentialPath(app string) string {
+	return fmt.Sprintf("apps/%s/s3", app)
+}
+
+// PolicyName is the OpenBAO policy that reads an application's credential
+func PolicyName(app string) string {
+	return "s3-" + app
+}
+
+// policyHCL grants read access to an application's credential
+func policyHCL(app string) string {
+	return fmt.Sprintf(`path "%s/data/%s" {
+  capabilities = ["read"]
+}
+`, SecretMount, CredentialPath(app))
+}
+
+// Buckets lists the buckets the identity has access to
+func (i Identity) Buckets() []string {
+	seen := map[string]bool{}
+	var buckets []string
+	for _, action := range i.Actions {
+		_, bucket, ok := strings.Cut(action, ":")
+		if !ok || seen[bucket] {
+			continue
+		}
+		seen[bucket] = true
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	return buckets
+}
+
+// Access returns the identity's access to a bucket: read, write,
+// read-write, or empty for none
+func (i Identity) Access(bucket string) string {
+	read, write := false, false
+	for _, action := range i.Actions {
+		name, scope, scoped := strings.Cut(action, ":")
+		if scoped && scope != bucket {
+			continue
+		}
+		switch name {
+		case ActionAdmin:
+			read, write = true, true
+		case ActionRead:
+			read = true
+		case ActionWrite:
+			write = true
+		}
+	}
+	switch {
+	case read && write:
+		return AccessReadWrite
+	case read:
+		return AccessRead
+	case write:
+		return AccessWrite
+	}
+	return ""
+}
+
+// bucketActions returns the actions that grant access to the buckets
+func bucketActions(buckets []string, read, write bool) []string {
+	var actions []string
+	for _, bucket := range buckets {
+		if read {
+			actions = append(actions, ActionRead+":"+bucket, ActionList+":"+bucket)
+		}
+		if write {
+			actions = append(actions, ActionWrite+":"+bucket, ActionTagging+":"+bucket)
+		}
+	}
+	return actions
+}
+
+// Identities lists the identities of the IAM config, by name
+func (m *Manager) Identities(ctx context.Context) ([]Identity, error) {
+	cfg, err := m.loadIAM(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identities := append([]Identity(nil), cfg.Identities...)
+	sort.Slice(identities, func(i, j int) bool { return identities[i].Name < identities[j].Name })
+	return identities, nil
+}
+
+// loadIAM reads the IAM config; a missing file is an empty config
+func (m *Manager) loadIAM(ctx context.Context) (*iamConfig, error) {
+	res, err := m.API.Do(ctx, "GET", filerService, identityFile, nil, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &iamConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read the S3 IAM config: %w", err)
+	}
+	cfg := &iamConfig{}
+	if len(strings.TrimSpace(string(res))) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(res, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the S3 IAM config: %w", err)
+	}
+	return cfg, nil
+}
+
+// saveIAM writes the IAM config, with the admin identity holding the
+// stack's shared credential
+func (m *Manager) saveIAM(ctx context.Context, cfg *iamConfig) error {
+	if m.Admin.AccessKey == "" || m.Admin.SecretKey == "" {
+		return fmt.Errorf("the stack's SeaweedFS credentials are not known; they are required so Foundry's components keep access once S3 authentication is on")
+	}
+	admin := Identity{
+		Name:        AdminIdentity,
+		Credentials: []Credential{m.Admin},
+		Actions:     []string{ActionAdmin, ActionRead, ActionList, ActionWrite, ActionTagging},
+	}
+	identities := []Identity{admin}
+	for _, identity := range cfg.Identities {
+		if identity.Name != AdminIdentity {
+			identities = append(identities, identity)
+		}
+	}
+	cfg.Identities = identities
+
+	body, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := m.API.Do(ctx, "PUT", filerService, identityFile, nil, body); err != nil {
+		return fmt.Errorf("failed to write the S3 IAM config: %w", err)
+	}
+	return nil
+}
+
+// Issue creates an identity for the application with access to the
+// buckets, stores its credential in OpenBAO with a policy that reads it,
+// and returns it. An application that has an identity gets a new
+// credential and the given access in place of its old ones.
+func (m *Manager) Issue(ctx context.Context, app string, buckets []string, read, write bool) (*Issued, error) {
+	if !appName.MatchString(app) {
+		return nil, fmt.Errorf("invalid application name %q: use lowercase letters, digits and hyphens", app)
+	}
+	if app == AdminIdentity {
+		return nil, fmt.Errorf("%s is the stack's shared identity and is managed by Foundry", AdminIdentity)
+	}
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("at least one bucket is required")
+	}
+	if !read && !write {
+		return nil, fmt.Errorf("choose read access, write access or both")
+	}
+	existing, err := m.Buckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, b := range existing {
+		known[b.Name] = true
+	}
+	buckets = append([]string(nil), buckets...)
+	sort.Strings(buckets)
+	for _, bucket := range buckets {
+		if !known[bucket] {
+			return nil, fmt.Errorf("bucket %s not found (create it with 'foundry s3 bucket create %s')", bucket, bucket)
+		}
+	}
+
+	accessKey, err := randomString(accessKeyAlphabet, 20)
+	if err != nil {
+		return nil, err
+	}
+	secretKey, err := randomString(secretKeyAlphabet, 40)
+	if err != nil {
+		return nil, err
+	}
+	identity := Identity{
+		Name:        app,
+		Credentials: []Credential{{AccessKey: accessKey, SecretKey: secretKey}},
+		Actions:     bucketActions(buckets, read, write),
+	}
+
+	cfg, err := m.loadIAM(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rotated := false
+	for i := range cfg.Identities {
+		if cfg.Identities[i].Name == app {
+			cfg.Identities[i] = identity
+			rotated = true
+		}
+	}
+	if !rotated {
+		cfg.Identities = append(cfg.Identities, identity)
+	}
+	if err := m.saveIAM(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	issued := &Issued{
+		App:        app,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+		Buckets:    buckets,
+		SecretPath: CredentialPath(app),
+		Policy:     PolicyName(app),
+		Rotated:    rotated,
+	}
+	if err := m.Secrets.WriteSecretV2(ctx, SecretMount, issued.SecretPath, map[string]interface{}{
+		"access_key": accessKey,
+		"secret_key": secretKey,
+		"endpoint":   m.Endpoint,
+		"region":     m.Region,
+		"buckets":    strings.Join(buckets, ","),
+	}); err != nil {
+		return issued, fmt.Errorf("the credential of %s is active but was not stored in OpenBAO: %w", app, err)
+	}
+	if err := m.Secrets.WritePolicy(ctx, issued.Policy, policyHCL(app)); err != nil {
+		return issued, fmt.Errorf("failed to write OpenBAO policy %s: %w", issued.Policy, err)
+	}
+	return issued, nil
+}
+
+// Revoke removes the application's identity, so its credential stops
+// working, and deletes the credential and its policy from OpenBAO
+func (m *Manager) Revoke(ctx context.Context, app string) error {
+	if app == AdminIdentity {
+		return fmt.Errorf("%s is the stack's shared identity and is managed by Foundry", AdminIdentity)
+	}
+	cfg, err := m.loadIAM(ctx)
+	if err != nil {
+		return err
+	}
+	remaining := make([]Identity, 0, len(cfg.Identities))
+	for _, identity := range cfg.Identities {
+		if identity.Name != app {
+			remaining = append(remaining, identity)
+		}
+	}
+	if len(remaining) == len(cfg.Identities) {
+		return fmt.Errorf("no S3 credential issued to %s", app)
+	}
+	cfg.Identities = remaining
+	if err := m.saveIAM(ctx, cfg); err != nil {
+		return err
+	}
+
+	if err := m.Secrets.DeleteSecretV2(ctx, SecretMount, CredentialPath(app)); err != nil {
+		return fmt.Errorf("the credential of %s no longer works, but was not deleted from OpenBAO: %w", app, err)
+	}
+	if err := m.Secrets.DeletePolicy(ctx, PolicyName(app)); err != nil {
+		return fmt.Errorf("failed to delete OpenBAO policy %s: %w", PolicyName(app), err)
+	}
+	return nil
+}
+
+const (
+	accessKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	secretKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// randomString returns n characters drawn from the alphabet
+func randomString(alphabet string, n int) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+	out := make([]byte, n)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate credential: %w", err)
+		}
+		out[i] = alphabet[idx.Int64()]
+	}
+	return string(out), nil
+}
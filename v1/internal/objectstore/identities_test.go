@@ -0,0 +1,180 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecrets records what is stored in OpenBAO
+type fakeSecrets struct {
+	secrets  map[string]map[string]interface{}
+	policies map[string]string
+}
+
+func newFakeSecrets() *fakeSecrets {
+	return &fakeSecrets{secrets: map[string]map[string]interface{}{}, policies: map[string]string{}}
+}
+
+func (f *fakeSecrets) WriteSecretV2(_ context.Context, mount, path string, data map[string]interface{}) error {
+	f.secrets[mount+"/"+path] = data
+	return nil
+}
+
+func (f *fakeSecrets) DeleteSecretV2(_ context.Context, mount, path string) error {
+	delete(f.secrets, mount+"/"+path)
+	return nil
+}
+
+func (f *fakeSecrets) WritePolicy(_ context.Context, name, policy string) error {
+	f.policies[name] = policy
+	return nil
+}
+
+func (f *fakeSecrets) DeletePolicy(_ context.Context, name string) error {
+	delete(f.policies, name)
+	return nil
+}
+
+func testManager(fs *fakeSeaweedFS) *Manager {
+	return &Manager{
+		API:      fs,
+		Secrets:  newFakeSecrets(),
+		Endpoint: "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333",
+		Region:   "us-east-1",
+		Admin:    Credential{AccessKey: "admin-key", SecretKey: "admin-secret"},
+	}
+}
+
+// storedIAM parses the IAM config the manager wrote
+func storedIAM(t *testing.T, fs *fakeSeaweedFS) iamConfig {
+	t.Helper()
+	var cfg iamConfig
+	require.NoError(t, json.Unmarshal(fs.files[identityFile], &cfg))
+	return cfg
+}
+
+func TestIssue(t *testing.T) {
+	fs := newFakeSeaweedFS("uploads", "thumbnails")
+	m := testManager(fs)
+	secrets := m.Secrets.(*fakeSecrets)
+
+	issued, err := m.Issue(context.Background(), "gallery", []string{"uploads", "thumbnails"}, true, true)
+	require.NoError(t, err)
+	assert.False(t, issued.Rotated)
+	assert.Len(t, issued.AccessKey, 20)
+	assert.Len(t, issued.SecretKey, 40)
+	assert.Equal(t, []string{"thumbnails", "uploads"}, issued.Buckets)
+	assert.Equal(t, "apps/gallery/s3", issued.SecretPath)
+
+	cfg := storedIAM(t, fs)
+	require.Len(t, cfg.Identities, 2)
+	admin := cfg.Identities[0]
+	assert.Equal(t, AdminIdentity, admin.Name, "the shared credential keeps working")
+	assert.Equal(t, []Credential{{AccessKey: "admin-key", SecretKey: "admin-secret"}}, admin.Credentials)
+	assert.Contains(t, admin.Actions, ActionAdmin)
+
+	gallery := cfg.Identities[1]
+	assert.Equal(t, "gallery", gallery.Name)
+	assert.Equal(t, []Credential{{AccessKey: issued.AccessKey, SecretKey: issued.SecretKey}}, gallery.Credentials)
+	assert.Equal(t, []string{
+		"Read:thumbnails", "List:thumbnails", "Write:thumbnails", "Tagging:thumbnails",
+		"Read:uploads", "List:uploads", "Write:uploads", "Tagging:uploads",
+	}, gallery.Actions)
+	assert.Equal(t, AccessReadWrite, gallery.Access("uploads"))
+	assert.Equal(t, "", gallery.Access("loki"))
+
+	assert.Equal(t, map[string]interface{}{
+		"access_key": issued.AccessKey,
+		"secret_key": issued.SecretKey,
+		"endpoint":   "http://seaweedfs-s3.seaweedfs.svc.cluster.local:8333",
+		"region":     "us-east-1",
+		"buckets":    "thumbnails,uploads",
+	}, secrets.secrets["foundry-core/apps/gallery/s3"])
+	assert.Contains(t, secrets.policies["s3-gallery"], `path "foundry-core/data/apps/gallery/s3"`)
+}
+
+func TestIssueRotates(t *testing.T) {
+	fs := newFakeSeaweedFS("uploads", "reports")
+	m := testManager(fs)
+
+	first, err := m.Issue(context.Background(), "gallery", []string{"uploads"}, true, true)
+	require.NoError(t, err)
+	second, err := m.Issue(context.Background(), "gallery", []string{"reports"}, true, false)
+	require.NoError(t, err)
+	assert.True(t, second.Rotated)
+	assert.NotEqual(t, first.AccessKey, second.AccessKey)
+
+	identities, err := m.Identities(context.Background())
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+	gallery := identities[1]
+	assert.Equal(t, []string{"reports"}, gallery.Buckets(), "access is replaced, not added to")
+	assert.Equal(t, AccessRead, gallery.Access("reports"))
+	assert.Equal(t, []Credential{{AccessKey: second.AccessKey, SecretKey: second.SecretKey}}, gallery.Credentials)
+}
+
+func TestIssueValidation(t *testing.T) {
+	m := testManager(newFakeSeaweedFS("uploads"))
+	ctx := context.Background()
+
+	_, err := m.Issue(ctx, "Gallery", []string{"uploads"}, true, false)
+	assert.ErrorContains(t, err, "invalid application name")
+	_, err = m.Issue(ctx, AdminIdentity, []string{"uploads"}, true, false)
+	assert.ErrorContains(t, err, "managed by Foundry")
+	_, err = m.Issue(ctx, "gallery", nil, true, false)
+	assert.ErrorContains(t, err, "at least one bucket")
+	_, err = m.Issue(ctx, "gallery", []string{"uploads"}, false, false)
+	assert.ErrorContains(t, err, "read access, write access or both")
+	_, err = m.Issue(ctx, "gallery", []string{"missing"}, true, false)
+	assert.ErrorContains(t, err, "bucket missing not found")
+
+	m.Admin = Credential{}
+	_, err = m.Issue(ctx, "gallery", []string{"uploads"}, true, false)
+	assert.ErrorContains(t, err, "SeaweedFS credentials are not known")
+}
+
+func TestIssueKeepsOtherIdentities(t *testing.T) {
+	fs := newFakeSeaweedFS("uploads")
+	fs.files[identityFile] = []byte(`{
+  "identities": [
+    {"name": "anonymous", "actions": ["Read:public"]},
+    {"name": "foundry-admin", "credentials": [{"accessKey": "old", "secretKey": "old"}], "actions": ["Admin"]}
+  ],
+  "accounts": [{"id": "team", "displayName": "Team"}]
+}`)
+	m := testManager(fs)
+
+	_, err := m.Issue(context.Background(), "gallery", []string{"uploads"}, false, true)
+	require.NoError(t, err)
+
+	cfg := storedIAM(t, fs)
+	names := []string{}
+	for _, identity := range cfg.Identities {
+		names = append(names, identity.Name)
+	}
+	assert.Equal(t, []string{AdminIdentity, "anonymous", "gallery"}, names)
+	assert.Equal(t, "admin-key", cfg.Identities[0].Credentials[0].AccessKey, "the admin identity follows the stack's credential")
+	assert.Len(t, cfg.Accounts, 1)
+}
+
+func TestRevoke(t *testing.T) {
+	fs := newFakeSeaweedFS("uploads")
+	m := testManager(fs)
+	secrets := m.Secrets.(*fakeSecrets)
+	_, err := m.Issue(context.Background(), "gallery", []string{"uploads"}, true, true)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Revoke(context.Background(), "gallery"))
+	cfg := storedIAM(t, fs)
+	require.Len(t, cfg.Identities, 1)
+	assert.Equal(t, AdminIdentity, cfg.Identities[0].Name)
+	assert.NotContains(t, secrets.secrets, "foundry-core/apps/gallery/s3")
+	assert.NotContains(t, secrets.policies, "s3-gallery")
+
+	assert.ErrorContains(t, m.Revoke(context.Background(), "gallery"), "no S3 credential issued to gallery")
+	assert.ErrorContains(t, m.Revoke(context.Background(), AdminIdentity), "managed by Foundry")
+}
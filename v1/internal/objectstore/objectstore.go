@@ -0,0 +1,103 @@
+// Package objectstore manages the buckets of the stack's SeaweedFS and the
+// S3 identities applications use to reach them
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultNamespace is where SeaweedFS is installed
+	DefaultNamespace = "seaweedfs"
+
+	// filerService and masterService are SeaweedFS' HTTP services
+	filerService  = "seaweedfs-filer:8888"
+	masterService = "seaweedfs-master:9333"
+
+	// bucketsDir is the filer directory holding a directory per bucket
+	bucketsDir = "/buckets"
+)
+
+// ErrNotFound is returned by API when the path does not exist
+var ErrNotFound = errors.New("not found")
+
+// API makes HTTP requests to a SeaweedFS service. A nil body sends no body.
+type API interface {
+	Do(ctx context.Context, method, service, path string, params url.Values, body []byte) ([]byte, error)
+}
+
+// proxyAPI reaches SeaweedFS through the API server's service proxy, so it
+// works from outside the cluster network
+type proxyAPI struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewProxyAPI returns an API that calls SeaweedFS in the namespace through
+// the service proxy
+func NewProxyAPI(client kubernetes.Interface, namespace string) API {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &proxyAPI{client: client, namespace: namespace}
+}
+
+// Do sends the request. The path is passed through as-is, since the filer
+// treats a trailing slash as a directory.
+func (a *proxyAPI) Do(ctx context.Context, method, service, path string, params url.Values, body []byte) ([]byte, error) {
+	req := a.client.CoreV1().RESTClient().Verb(method).
+		AbsPath(fmt.Sprintf("/api/v1/namespaces/%s/services/%s/proxy%s", a.namespace, service, path)).
+		SetHeader("Accept", "application/json")
+	for key, values := range params {
+		for _, value := range values {
+			req = req.Param(key, value)
+		}
+	}
+	if body != nil {
+		req = req.SetHeader("Content-Type", "application/json").Body(bytes.NewReader(body))
+	}
+	res, err := req.DoRaw(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%s %s: %w", method, path, ErrNotFound)
+		}
+		if msg := strings.TrimSpace(string(res)); msg != "" {
+			return nil, fmt.Errorf("seaweedfs %s %s: %w: %s", method, path, err, msg)
+		}
+		return nil, fmt.Errorf("seaweedfs %s %s: %w", method, path, err)
+	}
+	return res, nil
+}
+
+// SecretStore keeps issued credentials and the policies that read them
+// (implemented by openbao.Client)
+type SecretStore interface {
+	WriteSecretV2(ctx context.Context, mount, path string, data map[string]interface{}) error
+	DeleteSecretV2(ctx context.Context, mount, path string) error
+	WritePolicy(ctx context.Context, name, policy string) error
+	DeletePolicy(ctx context.Context, name string) error
+}
+
+// Manager manages SeaweedFS buckets and S3 identities
+type Manager struct {
+	API     API
+	Secrets SecretStore
+
+	// Endpoint and Region are stored with each credential, for the
+	// application to connect with
+	Endpoint string
+	Region   string
+
+	// Admin is the stack's shared credential, which Foundry's components
+	// use. It is kept in the IAM config as the admin identity, so turning on
+	// per-application identities does not lock them out.
+	Admin Credential
+}
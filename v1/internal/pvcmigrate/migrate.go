@@ -0,0 +1,507 @@
+package pvcmigrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rsyncPort is the port the rsync daemon serves the source volume on for
+// copies across nodes
+const rsyncPort = 873
+
+// Migrator runs checked migration plans against the cluster
+type Migrator struct {
+	Client kubernetes.Interface
+	Out    io.Writer
+	// PollInterval is how often progress is checked (default: 2s)
+	PollInterval time.Duration
+}
+
+// Run migrates the claim:
+//
+//  1. The current volume is set to Retain so its data survives the swap.
+//  2. The workloads that mount the claim are scaled to zero.
+//  3. A staging claim is created in the target class and an rsync Job
+//     copies the data into it.
+//  4. The staging claim and the original claim are deleted, and the claim is
+//     recreated under its original name, bound to the new volume.
+//  5. The workloads are scaled back up.
+//
+// When the copy fails the original claim is untouched and the workloads are
+// scaled back up. The old volume is left Released for the caller to delete.
+func (m *Migrator) Run(ctx context.Context, plan *Plan) error {
+	oldPolicy, err := m.setReclaimPolicy(ctx, plan.Volume, corev1.PersistentVolumeReclaimRetain)
+	if err != nil {
+		return err
+	}
+
+	if err := m.ScaleDown(ctx, plan); err != nil {
+		m.rollback(plan, oldPolicy, false)
+		return err
+	}
+
+	newVolume, err := m.copy(ctx, plan)
+	if err != nil {
+		m.rollback(plan, oldPolicy, true)
+		return fmt.Errorf("%w\n\nThe original claim is unchanged and the workloads were scaled back up", err)
+	}
+
+	if err := m.swap(ctx, plan, newVolume); err != nil {
+		return fmt.Errorf("%w\n\nThe workloads are left scaled down. The data is kept on volume %s (original) and %s (copy), both set to Retain",
+			err, plan.Volume, newVolume)
+	}
+	m.ScaleUp(ctx, plan)
+
+	fmt.Fprintf(m.Out, "\n✓ PVC %s/%s now uses volume %s (class %s, %s)\n", plan.Namespace, plan.Claim, newVolume, plan.ToClass, plan.NewSize.String())
+	fmt.Fprintf(m.Out, "The old volume %s is kept. Once the workloads are verified, delete it with:\n", plan.Volume)
+	fmt.Fprintf(m.Out, "  kubectl delete pv %s\n", plan.Volume)
+	return nil
+}
+
+// rollback undoes a migration that failed before the swap: it removes the
+// staging claim and copy helpers, scales the workloads back up and restores
+// the old volume's reclaim policy. It runs on its own context, since the
+// migration's may have ended.
+func (m *Migrator) rollback(plan *Plan, oldPolicy corev1.PersistentVolumeReclaimPolicy, staging bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if staging {
+		m.deleteStaging(ctx, plan)
+	}
+	m.ScaleUp(ctx, plan)
+	if oldPolicy != corev1.PersistentVolumeReclaimRetain {
+		if _, err := m.setReclaimPolicy(ctx, plan.Volume, oldPolicy); err != nil {
+			fmt.Fprintf(m.Out, "⚠ Could not restore the reclaim policy of volume %s: %v\n", plan.Volume, err)
+		}
+	}
+}
+
+// setReclaimPolicy sets a volume's reclaim policy and returns the previous one
+func (m *Migrator) setReclaimPolicy(ctx context.Context, name string, policy corev1.PersistentVolumeReclaimPolicy) (corev1.PersistentVolumeReclaimPolicy, error) {
+	volume, err := m.Client.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get volume %s: %w", name, err)
+	}
+	previous := volume.Spec.PersistentVolumeReclaimPolicy
+	if previous == policy {
+		return previous, nil
+	}
+	volume.Spec.PersistentVolumeReclaimPolicy = policy
+	if _, err := m.Client.CoreV1().PersistentVolumes().Update(ctx, volume, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to set reclaim policy of volume %s to %s: %w", name, policy, err)
+	}
+	return previous, nil
+}
+
+// ScaleDown scales the workloads to zero and waits until no pod mounts the
+// claim
+func (m *Migrator) ScaleDown(ctx context.Context, plan *Plan) error {
+	for _, w := range plan.Workloads {
+		fmt.Fprintf(m.Out, "Scaling %s down from %d replicas...\n", w, w.Replicas)
+		if err := m.scale(ctx, plan.Namespace, w, 0); err != nil {
+			return err
+		}
+	}
+	return m.waitFor(ctx, fmt.Sprintf("pods mounting %s to stop", plan.Claim), func() (bool, error) {
+		pods, err := m.Client.CoreV1().Pods(plan.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for i := range pods.Items {
+			if mountsClaim(&pods.Items[i], plan.Claim) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// ScaleUp returns the workloads to their replica counts. Failures are
+// reported rather than returned; the remaining workloads still get scaled.
+func (m *Migrator) ScaleUp(ctx context.Context, plan *Plan) {
+	for _, w := range plan.Workloads {
+		fmt.Fprintf(m.Out, "Scaling %s back up to %d replicas...\n", w, w.Replicas)
+		if err := m.scale(ctx, plan.Namespace, w, w.Replicas); err != nil {
+			fmt.Fprintf(m.Out, "⚠ %v\n", err)
+		}
+	}
+}
+
+// scale sets a workload's replica count through its scale subresource
+func (m *Migrator) scale(ctx context.Context, namespace string, w Workload, replicas int32) error {
+	apps := m.Client.AppsV1()
+	switch w.Kind {
+	case "Deployment":
+		scale, err := apps.Deployments(namespace).GetScale(ctx, w.Name, metav1.GetOptions{})
+		if err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = apps.Deployments(namespace).UpdateScale(ctx, w.Name, scale, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scale %s to %d: %w", w, replicas, err)
+		}
+	case "StatefulSet":
+		scale, err := apps.StatefulSets(namespace).GetScale(ctx, w.Name, metav1.GetOptions{})
+		if err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = apps.StatefulSets(namespace).UpdateScale(ctx, w.Name, scale, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scale %s to %d: %w", w, replicas, err)
+		}
+	default:
+		return fmt.Errorf("cannot scale %s", w)
+	}
+	return nil
+}
+
+// copy creates the staging claim, copies the data into it and returns the
+// staging claim's volume
+func (m *Migrator) copy(ctx context.Context, plan *Plan) (string, error) {
+	fmt.Fprintf(m.Out, "Creating staging PVC %s (class %s, %s)...\n", plan.StagingClaim, plan.ToClass, plan.NewSize.String())
+	if _, err := m.Client.CoreV1().PersistentVolumeClaims(plan.Namespace).Create(ctx, StagingClaim(plan), metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create staging PVC: %w", err)
+	}
+
+	source := ""
+	if plan.Remote() {
+		server := RsyncServerPod(plan)
+		fmt.Fprintf(m.Out, "Serving volume %s from node %s...\n", plan.Volume, plan.SourceNode)
+		if _, err := m.Client.CoreV1().Pods(plan.Namespace).Create(ctx, server, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create rsync server pod: %w", err)
+		}
+		var podIP string
+		err := m.waitFor(ctx, "the rsync server to start", func() (bool, error) {
+			pod, err := m.Client.CoreV1().Pods(plan.Namespace).Get(ctx, server.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			if pod.Status.Phase == corev1.PodFailed {
+				return false, fmt.Errorf("rsync server pod %s failed", server.Name)
+			}
+			podIP = pod.Status.PodIP
+			return pod.Status.Phase == corev1.PodRunning && podIP != "", nil
+		})
+		if err != nil {
+			return "", err
+		}
+		source = podIP
+	}
+
+	job := CopyJob(plan, source)
+	fmt.Fprintf(m.Out, "Copying data with job %s...\n", job.Name)
+	if _, err := m.Client.BatchV1().Jobs(plan.Namespace).Create(ctx, job, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create copy job: %w", err)
+	}
+	err := m.waitFor(ctx, "the copy to finish", func() (bool, error) {
+		current, err := m.Client.BatchV1().Jobs(plan.Namespace).Get(ctx, job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range current.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("copy job %s failed: %s%s", job.Name, cond.Message, m.jobLogs(ctx, plan.Namespace, job.Name))
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	m.deleteHelpers(ctx, plan)
+
+	staging, err := m.Client.CoreV1().PersistentVolumeClaims(plan.Namespace).Get(ctx, plan.StagingClaim, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get staging PVC: %w", err)
+	}
+	if staging.Spec.VolumeName == "" {
+		return "", fmt.Errorf("staging PVC %s is not bound after the copy", plan.StagingClaim)
+	}
+	return staging.Spec.VolumeName, nil
+}
+
+// jobLogs returns the tail of a failed job's pod logs, for its error
+func (m *Migrator) jobLogs(ctx context.Context, namespace, job string) string {
+	pods, err := m.Client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + job})
+	if err != nil || len(pods.Items) == 0 {
+		return ""
+	}
+	tail := int64(20)
+	logs, err := m.Client.CoreV1().Pods(namespace).GetLogs(pods.Items[len(pods.Items)-1].Name, &corev1.PodLogOptions{TailLines: &tail}).DoRaw(ctx)
+	if err != nil || len(logs) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.TrimSpace(string(logs))
+}
+
+// deleteHelpers deletes the copy job and the rsync server pod
+func (m *Migrator) deleteHelpers(ctx context.Context, plan *Plan) {
+	background := metav1.DeletePropagationBackground
+	err := m.Client.BatchV1().Jobs(plan.Namespace).Delete(ctx, copyJobName(plan.Claim), metav1.DeleteOptions{PropagationPolicy: &background})
+	if err != nil && !apierrors.IsNotFound(err) {
+		fmt.Fprintf(m.Out, "⚠ Could not delete copy job: %v\n", err)
+	}
+	if plan.Remote() {
+		err := m.Client.CoreV1().Pods(plan.Namespace).Delete(ctx, rsyncServerName(plan.Claim), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			fmt.Fprintf(m.Out, "⚠ Could not delete rsync server pod: %v\n", err)
+		}
+	}
+}
+
+// deleteStaging removes what a failed copy left behind
+func (m *Migrator) deleteStaging(ctx context.Context, plan *Plan) {
+	m.deleteHelpers(ctx, plan)
+	err := m.Client.CoreV1().PersistentVolumeClaims(plan.Namespace).Delete(ctx, plan.StagingClaim, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		fmt.Fprintf(m.Out, "⚠ Could not delete staging PVC %s: %v\n", plan.StagingClaim, err)
+	}
+}
+
+// swap recreates the claim under its original name, bound to the new
+// volume
+func (m *Migrator) swap(ctx context.Context, plan *Plan, newVolume string) error {
+	newPolicy, err := m.setReclaimPolicy(ctx, newVolume, corev1.PersistentVolumeReclaimRetain)
+	if err != nil {
+		return err
+	}
+
+	claims := m.Client.CoreV1().PersistentVolumeClaims(plan.Namespace)
+	fmt.Fprintf(m.Out, "Swapping PVC %s onto volume %s...\n", plan.Claim, newVolume)
+	for _, name := range []string{plan.StagingClaim, plan.Claim} {
+		if err := claims.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PVC %s: %w", name, err)
+		}
+		err := m.waitFor(ctx, fmt.Sprintf("PVC %s to be deleted", name), func() (bool, error) {
+			_, err := claims.Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Point the new volume at the claim to be, so nothing else binds it
+	volume, err := m.Client.CoreV1().PersistentVolumes().Get(ctx, newVolume, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get volume %s: %w", newVolume, err)
+	}
+	volume.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:       "PersistentVolumeClaim",
+		APIVersion: "v1",
+		Namespace:  plan.Namespace,
+		Name:       plan.Claim,
+	}
+	if _, err := m.Client.CoreV1().PersistentVolumes().Update(ctx, volume, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to reserve volume %s for PVC %s: %w", newVolume, plan.Claim, err)
+	}
+
+	if _, err := claims.Create(ctx, SwappedClaim(plan, newVolume), metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to recreate PVC %s: %w", plan.Claim, err)
+	}
+	err = m.waitFor(ctx, fmt.Sprintf("PVC %s to bind", plan.Claim), func() (bool, error) {
+		claim, err := claims.Get(ctx, plan.Claim, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return claim.Status.Phase == corev1.ClaimBound, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.setReclaimPolicy(ctx, newVolume, newPolicy); err != nil {
+		fmt.Fprintf(m.Out, "⚠ %v\n", err)
+	}
+	return nil
+}
+
+// waitFor polls until done reports true, returns an error, or the context
+// ends
+func (m *Migrator) waitFor(ctx context.Context, what string, done func() (bool, error)) error {
+	interval := m.PollInterval
+	if interval == 0 {
+		interval = 2 * time.Second
+	}
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s", what)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// StagingClaim is the claim the data is copied into
+func StagingClaim(plan *Plan) *corev1.PersistentVolumeClaim {
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      plan.StagingClaim,
+			Namespace: plan.Namespace,
+			Labels:    map[string]string{migrateLabel: plan.Claim},
+		},
+		Spec: claimSpec(plan),
+	}
+	return claim
+}
+
+// SwappedClaim is the claim recreated under the original name and bound to
+// the new volume. It keeps the original claim's labels and annotations.
+func SwappedClaim(plan *Plan, volume string) *corev1.PersistentVolumeClaim {
+	spec := claimSpec(plan)
+	spec.VolumeName = volume
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        plan.Claim,
+			Namespace:   plan.Namespace,
+			Labels:      plan.labels,
+			Annotations: plan.annotations,
+		},
+		Spec: spec,
+	}
+}
+
+// claimSpec is the spec of the staging and the recreated claim
+func claimSpec(plan *Plan) corev1.PersistentVolumeClaimSpec {
+	class := plan.ToClass
+	return corev1.PersistentVolumeClaimSpec{
+		AccessModes:      plan.accessModes,
+		StorageClassName: &class,
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: plan.NewSize},
+		},
+	}
+}
+
+// copyJobName names the job that copies a claim's data
+func copyJobName(claim string) string {
+	return truncateName("pvc-migrate-" + claim)
+}
+
+// rsyncServerName names the pod that serves a claim's data to another node
+func rsyncServerName(claim string) string {
+	return truncateName("pvc-migrate-src-" + claim)
+}
+
+// truncateName keeps generated names within the 63 characters a pod's
+// job-name label allows
+func truncateName(name string) string {
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-.")
+}
+
+// CopyJob is the Job that copies the data into the staging claim. With an
+// empty source it mounts both claims; otherwise it pulls from the rsync
+// daemon at that address.
+func CopyJob(plan *Plan, source string) *batchv1.Job {
+	backoffLimit := int32(0)
+	volumes := []corev1.Volume{claimVolume("target", plan.StagingClaim, false)}
+	mounts := []corev1.VolumeMount{{Name: "target", MountPath: "/target"}}
+	from := "/source/"
+	if source == "" {
+		volumes = append(volumes, claimVolume("source", plan.Claim, true))
+		mounts = append(mounts, corev1.VolumeMount{Name: "source", MountPath: "/source", ReadOnly: true})
+	} else {
+		from = fmt.Sprintf("rsync://%s:%d/source/", source, rsyncPort)
+	}
+
+	podSpec := corev1.PodSpec{
+		RestartPolicy: corev1.RestartPolicyNever,
+		Containers: []corev1.Container{{
+			Name:         "rsync",
+			Image:        plan.Image,
+			Command:      []string{"rsync", "-aHAX", "--numeric-ids", "--delete", "--info=stats1", from, "/target/"},
+			VolumeMounts: mounts,
+		}},
+		Volumes: volumes,
+	}
+	if plan.Node != "" {
+		podSpec.NodeSelector = map[string]string{hostnameLabel: plan.Node}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      copyJobName(plan.Claim),
+			Namespace: plan.Namespace,
+			Labels:    map[string]string{migrateLabel: plan.Claim},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{migrateLabel: plan.Claim}},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+// rsyncdScript writes the daemon's config and runs it in the foreground
+var rsyncdScript = fmt.Sprintf(`cat > /tmp/rsyncd.conf <<EOF
+[source]
+  path = /source
+  read only = true
+  uid = 0
+  gid = 0
+EOF
+exec rsync --daemon --no-detach --log-file=/dev/stdout --port=%d --config=/tmp/rsyncd.conf`, rsyncPort)
+
+// RsyncServerPod serves the source claim read-only over the rsync protocol
+// for a copy to another node. It runs where the source volume is pinned.
+func RsyncServerPod(plan *Plan) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rsyncServerName(plan.Claim),
+			Namespace: plan.Namespace,
+			Labels:    map[string]string{migrateLabel: plan.Claim},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			NodeSelector:  map[string]string{hostnameLabel: plan.SourceNode},
+			Containers: []corev1.Container{{
+				Name:         "rsyncd",
+				Image:        plan.Image,
+				Command:      []string{"sh", "-c", rsyncdScript},
+				Ports:        []corev1.ContainerPort{{Name: "rsync", ContainerPort: rsyncPort}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "source", MountPath: "/source", ReadOnly: true}},
+			}},
+			Volumes: []corev1.Volume{claimVolume("source", plan.Claim, true)},
+		},
+	}
+}
+
+// claimVolume mounts a claim as a pod volume
+func claimVolume(name, claim string, readOnly bool) corev1.Volume {
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim, ReadOnly: readOnly},
+		},
+	}
+}
@@ -0,0 +1,172 @@
+package pvcmigrate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testPlan(t *testing.T, opts Options) *Plan {
+	t.Helper()
+	client := kubernetesfake.NewSimpleClientset(testObjects()...)
+	opts.Namespace, opts.Claim = "app", "data"
+	plan, err := NewPlan(context.Background(), client, opts)
+	require.NoError(t, err)
+	return plan
+}
+
+func TestCopyJob(t *testing.T) {
+	plan := testPlan(t, Options{ToClass: "longhorn"})
+
+	job := CopyJob(plan, "")
+	assert.Equal(t, "pvc-migrate-data", job.Name)
+	assert.Equal(t, "data", job.Labels[migrateLabel])
+	pod := job.Spec.Template.Spec
+	assert.Empty(t, pod.NodeSelector)
+	require.Len(t, pod.Volumes, 2)
+	assert.Equal(t, "data-migrate", pod.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, "data", pod.Volumes[1].PersistentVolumeClaim.ClaimName)
+	assert.True(t, pod.Volumes[1].PersistentVolumeClaim.ReadOnly)
+	assert.Equal(t, []string{"rsync", "-aHAX", "--numeric-ids", "--delete", "--info=stats1", "/source/", "/target/"}, pod.Containers[0].Command)
+}
+
+func TestCopyJobRemote(t *testing.T) {
+	plan := testPlan(t, Options{Node: "node-b"})
+	require.True(t, plan.Remote())
+
+	job := CopyJob(plan, "10.42.1.7")
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, map[string]string{"kubernetes.io/hostname": "node-b"}, pod.NodeSelector)
+	require.Len(t, pod.Volumes, 1)
+	assert.Equal(t, "data-migrate", pod.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Contains(t, pod.Containers[0].Command, "rsync://10.42.1.7:873/source/")
+
+	server := RsyncServerPod(plan)
+	assert.Equal(t, "pvc-migrate-src-data", server.Name)
+	assert.Equal(t, map[string]string{"kubernetes.io/hostname": "node-a"}, server.Spec.NodeSelector)
+	assert.Equal(t, "data", server.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Contains(t, server.Spec.Containers[0].Command[2], "read only = true")
+}
+
+func TestSwappedClaim(t *testing.T) {
+	plan := testPlan(t, Options{ToClass: "longhorn", Size: "20Gi"})
+
+	claim := SwappedClaim(plan, "pvc-5678")
+	assert.Equal(t, "data", claim.Name)
+	assert.Equal(t, "pvc-5678", claim.Spec.VolumeName)
+	assert.Equal(t, "longhorn", *claim.Spec.StorageClassName)
+	assert.Equal(t, "20Gi", claim.Spec.Resources.Requests.Storage().String())
+	assert.Equal(t, map[string]string{"app": "web"}, claim.Labels)
+	assert.Equal(t, map[string]string{"team": "web"}, claim.Annotations)
+}
+
+func TestTruncateName(t *testing.T) {
+	name := copyJobName("a-very-long-claim-name-that-goes-on-and-on-past-the-label-limit-x")
+	assert.LessOrEqual(t, len(name), 63)
+	assert.NotRegexp(t, `[-.]$`, name)
+}
+
+// fakeCluster binds claims and finishes jobs with the given condition as
+// soon as they are created, and serves the scale subresource of deployments
+func fakeCluster(t *testing.T, jobResult batchv1.JobConditionType, objects ...runtime.Object) *kubernetesfake.Clientset {
+	client := kubernetesfake.NewSimpleClientset(objects...)
+	replicas := map[string]int32{}
+	client.PrependReactor("create", "persistentvolumeclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		claim := action.(k8stesting.CreateAction).GetObject().(*corev1.PersistentVolumeClaim)
+		if claim.Spec.VolumeName == "" {
+			claim.Spec.VolumeName = "pvc-5678"
+			err := client.Tracker().Add(&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc-5678"},
+				Spec:       corev1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete},
+			})
+			require.NoError(t, err)
+		}
+		claim.Status.Phase = corev1.ClaimBound
+		return false, nil, nil
+	})
+	client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+		job.Status.Conditions = []batchv1.JobCondition{{Type: jobResult, Status: corev1.ConditionTrue, Message: string(jobResult)}}
+		return false, nil, nil
+	})
+	client.PrependReactor("*", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		if update, ok := action.(k8stesting.UpdateAction); ok {
+			scale := update.GetObject().(*autoscalingv1.Scale)
+			replicas[scale.Name] = scale.Spec.Replicas
+			return true, scale, nil
+		}
+		name := action.(k8stesting.GetAction).GetName()
+		return true, &autoscalingv1.Scale{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: autoscalingv1.ScaleSpec{Replicas: replicas[name]}}, nil
+	})
+	return client
+}
+
+func TestRun(t *testing.T) {
+	plan := testPlan(t, Options{ToClass: "longhorn", Size: "20Gi"})
+	plan.Workloads = []Workload{{Kind: "Deployment", Name: "web", Replicas: 2}}
+
+	client := fakeCluster(t, batchv1.JobComplete, testClaim(), testVolume())
+	var out bytes.Buffer
+	m := &Migrator{Client: client, Out: &out, PollInterval: time.Millisecond}
+	require.NoError(t, m.Run(context.Background(), plan))
+
+	ctx := context.Background()
+	claim, err := client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "pvc-5678", claim.Spec.VolumeName)
+	assert.Equal(t, "longhorn", *claim.Spec.StorageClassName)
+
+	_, err = client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data-migrate", metav1.GetOptions{})
+	assert.Error(t, err, "staging claim should be deleted")
+	_, err = client.BatchV1().Jobs("app").Get(ctx, "pvc-migrate-data", metav1.GetOptions{})
+	assert.Error(t, err, "copy job should be deleted")
+
+	oldVolume, err := client.CoreV1().PersistentVolumes().Get(ctx, "pvc-1234", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.PersistentVolumeReclaimRetain, oldVolume.Spec.PersistentVolumeReclaimPolicy)
+
+	newVolume, err := client.CoreV1().PersistentVolumes().Get(ctx, "pvc-5678", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, newVolume.Spec.PersistentVolumeReclaimPolicy)
+	assert.Equal(t, "data", newVolume.Spec.ClaimRef.Name)
+
+	assert.Contains(t, out.String(), "Scaling deployment/web down from 2 replicas")
+	assert.Contains(t, out.String(), "Scaling deployment/web back up to 2 replicas")
+	assert.Contains(t, out.String(), "kubectl delete pv pvc-1234")
+}
+
+func TestRunRollsBackFailedCopy(t *testing.T) {
+	plan := testPlan(t, Options{ToClass: "longhorn"})
+	plan.Workloads = nil
+
+	client := fakeCluster(t, batchv1.JobFailed, testClaim(), testVolume())
+	m := &Migrator{Client: client, Out: &bytes.Buffer{}, PollInterval: time.Millisecond}
+	err := m.Run(context.Background(), plan)
+	require.ErrorContains(t, err, "copy job pvc-migrate-data failed")
+	assert.ErrorContains(t, err, "original claim is unchanged")
+
+	ctx := context.Background()
+	claim, err := client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "pvc-1234", claim.Spec.VolumeName)
+	_, err = client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data-migrate", metav1.GetOptions{})
+	assert.Error(t, err, "staging claim should be deleted")
+
+	oldVolume, err := client.CoreV1().PersistentVolumes().Get(ctx, "pvc-1234", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, oldVolume.Spec.PersistentVolumeReclaimPolicy)
+}
@@ -0,0 +1,383 @@
+// Package pvcmigrate moves the data of a PersistentVolumeClaim onto a new
+// volume in another storage class, with another size or on another node,
+// and swaps the new volume in under the claim's original name.
+package pvcmigrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultImage is the image the copy runs in; it needs rsync
+	DefaultImage = "instrumentisto/rsync-ssh:alpine3.20"
+
+	// migrateLabel marks the staging claim, copy job and rsync server of a
+	// migration; the value is the migrated claim's name
+	migrateLabel = "foundry.io/pvc-migrate"
+
+	// hostnameLabel is the node label local volumes are pinned by
+	hostnameLabel = "kubernetes.io/hostname"
+)
+
+// Options describes a requested migration
+type Options struct {
+	Namespace string
+	Claim     string
+	// ToClass is the target storage class (default: the claim's current one)
+	ToClass string
+	// Size is the new volume's size (default: the claim's current size)
+	Size string
+	// Node pins the new volume's copy to a node, for node-local classes
+	// such as local-path
+	Node string
+	// Image is the rsync image (default: DefaultImage)
+	Image string
+}
+
+// Workload is a Deployment or StatefulSet whose pods mount the claim
+type Workload struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+// String returns the workload as kind/name
+func (w Workload) String() string {
+	return strings.ToLower(w.Kind) + "/" + w.Name
+}
+
+// Plan is a checked migration: what is moved where and which workloads are
+// scaled down meanwhile
+type Plan struct {
+	Namespace string `json:"namespace"`
+	Claim     string `json:"claim"`
+	// Volume is the PersistentVolume bound to the claim now
+	Volume    string            `json:"volume"`
+	FromClass string            `json:"fromClass"`
+	ToClass   string            `json:"toClass"`
+	Size      resource.Quantity `json:"size"`
+	NewSize   resource.Quantity `json:"newSize"`
+	// SourceNode is the node the current volume is pinned to, if any
+	SourceNode string `json:"sourceNode,omitempty"`
+	// Node is the node the new volume is pinned to, if requested
+	Node string `json:"node,omitempty"`
+	// StagingClaim is the claim the data is copied into before the swap
+	StagingClaim string     `json:"stagingClaim"`
+	Workloads    []Workload `json:"workloads,omitempty"`
+	Warnings     []string   `json:"warnings,omitempty"`
+	Image        string     `json:"image"`
+
+	accessModes []corev1.PersistentVolumeAccessMode
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// Remote reports whether the copy crosses nodes. The source volume is then
+// served by an rsync daemon on its node and pulled from the target node.
+func (p *Plan) Remote() bool {
+	return p.SourceNode != "" && p.Node != "" && p.SourceNode != p.Node
+}
+
+// ParseClaimRef splits namespace/name; a bare name is in the default
+// namespace
+func ParseClaimRef(ref string) (namespace, name string, err error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		namespace, name = metav1.NamespaceDefault, ref
+	}
+	if namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid claim %q: expected <namespace>/<name>", ref)
+	}
+	return namespace, name, nil
+}
+
+// NewPlan looks up the claim and everything that uses it and checks that it
+// can be migrated safely. It changes nothing in the cluster.
+func NewPlan(ctx context.Context, client kubernetes.Interface, opts Options) (*Plan, error) {
+	claim, err := client.CoreV1().PersistentVolumeClaims(opts.Namespace).Get(ctx, opts.Claim, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("PVC %s/%s not found: %w", opts.Namespace, opts.Claim, err)
+	}
+	if claim.Status.Phase != corev1.ClaimBound || claim.Spec.VolumeName == "" {
+		return nil, fmt.Errorf("PVC %s/%s is %s, only bound claims can be migrated", opts.Namespace, opts.Claim, claim.Status.Phase)
+	}
+	if claim.Spec.VolumeMode != nil && *claim.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return nil, fmt.Errorf("PVC %s/%s is a block volume; only filesystem volumes can be copied", opts.Namespace, opts.Claim)
+	}
+	if claim.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("PVC %s/%s is being deleted", opts.Namespace, opts.Claim)
+	}
+
+	plan := &Plan{
+		Namespace:    opts.Namespace,
+		Claim:        opts.Claim,
+		Volume:       claim.Spec.VolumeName,
+		Size:         claim.Spec.Resources.Requests[corev1.ResourceStorage],
+		Node:         opts.Node,
+		StagingClaim: stagingClaimName(opts.Claim),
+		Image:        opts.Image,
+		accessModes:  claim.Spec.AccessModes,
+		labels:       claim.Labels,
+		annotations:  carriedAnnotations(claim.Annotations),
+	}
+	if plan.Image == "" {
+		plan.Image = DefaultImage
+	}
+	if claim.Spec.StorageClassName != nil {
+		plan.FromClass = *claim.Spec.StorageClassName
+	}
+
+	plan.ToClass = opts.ToClass
+	if plan.ToClass == "" {
+		plan.ToClass = plan.FromClass
+	}
+	if plan.ToClass == "" {
+		return nil, fmt.Errorf("PVC %s/%s has no storage class; pass the target class", opts.Namespace, opts.Claim)
+	}
+	if _, err := client.StorageV1().StorageClasses().Get(ctx, plan.ToClass, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("storage class %q not found: %w", plan.ToClass, err)
+	}
+
+	plan.NewSize = plan.Size
+	if opts.Size != "" {
+		size, err := resource.ParseQuantity(opts.Size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", opts.Size, err)
+		}
+		if size.Cmp(plan.Size) < 0 {
+			return nil, fmt.Errorf("new size %s is smaller than the current %s; volumes can only grow", size.String(), plan.Size.String())
+		}
+		plan.NewSize = size
+	}
+
+	volume, err := client.CoreV1().PersistentVolumes().Get(ctx, plan.Volume, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("volume %s of PVC %s/%s not found: %w", plan.Volume, opts.Namespace, opts.Claim, err)
+	}
+	plan.SourceNode = pinnedNode(volume)
+
+	if opts.Node != "" {
+		if _, err := client.CoreV1().Nodes().Get(ctx, opts.Node, metav1.GetOptions{}); err != nil {
+			return nil, fmt.Errorf("node %q not found: %w", opts.Node, err)
+		}
+	}
+	if plan.ToClass == plan.FromClass && plan.NewSize.Cmp(plan.Size) == 0 && (plan.Node == "" || plan.Node == plan.SourceNode) {
+		return nil, fmt.Errorf("PVC %s/%s is already on class %q with size %s; nothing to migrate", opts.Namespace, opts.Claim, plan.FromClass, plan.Size.String())
+	}
+
+	_, err = client.CoreV1().PersistentVolumeClaims(opts.Namespace).Get(ctx, plan.StagingClaim, metav1.GetOptions{})
+	if err == nil {
+		return nil, fmt.Errorf("PVC %s/%s already exists; a previous migration may have been interrupted. Delete it before migrating again", opts.Namespace, plan.StagingClaim)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to check for PVC %s/%s: %w", opts.Namespace, plan.StagingClaim, err)
+	}
+
+	if plan.Workloads, err = ClaimWorkloads(ctx, client, opts.Namespace, opts.Claim); err != nil {
+		return nil, err
+	}
+	for _, w := range plan.Workloads {
+		if w.Kind == "StatefulSet" {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s keeps class %q in its volumeClaimTemplates; claims for new replicas still use it", w, plan.FromClass))
+		}
+	}
+	return plan, nil
+}
+
+// stagingClaimName names the claim the data is copied into
+func stagingClaimName(claim string) string {
+	const suffix = "-migrate"
+	if len(claim)+len(suffix) > 253 {
+		claim = claim[:253-len(suffix)]
+	}
+	return claim + suffix
+}
+
+// carriedAnnotations are the claim annotations the recreated claim keeps.
+// Binding and provisioning annotations belong to the old volume.
+func carriedAnnotations(annotations map[string]string) map[string]string {
+	carried := map[string]string{}
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "pv.kubernetes.io/") ||
+			strings.HasPrefix(k, "volume.kubernetes.io/") ||
+			strings.HasPrefix(k, "volume.beta.kubernetes.io/") ||
+			k == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		carried[k] = v
+	}
+	return carried
+}
+
+// pinnedNode returns the node a volume's node affinity pins it to, as for
+// local-path volumes, or "" when the volume can be reached from any node
+func pinnedNode(volume *corev1.PersistentVolume) string {
+	if volume.Spec.NodeAffinity == nil || volume.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	terms := volume.Spec.NodeAffinity.Required.NodeSelectorTerms
+	if len(terms) != 1 {
+		return ""
+	}
+	for _, expr := range terms[0].MatchExpressions {
+		if expr.Key == hostnameLabel && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+			return expr.Values[0]
+		}
+	}
+	return ""
+}
+
+// ClaimWorkloads finds the Deployments and StatefulSets whose pods mount the
+// claim, which must be scaled to zero before its volume is taken offline
+func ClaimWorkloads(ctx context.Context, client kubernetes.Interface, namespace, claim string) ([]Workload, error) {
+	workloads, err := claimWorkloads(ctx, client, namespace, claim)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAutoscalers(ctx, client, namespace, workloads); err != nil {
+		return nil, err
+	}
+	return workloads, nil
+}
+
+// claimWorkloads finds the workloads mounting the claim. It refuses when a
+// pod that mounts the claim can't be scaled down: bare pods, and pods of
+// DaemonSets, Jobs or other controllers.
+func claimWorkloads(ctx context.Context, client kubernetes.Interface, namespace, claim string) ([]Workload, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", namespace, err)
+	}
+
+	seen := map[string]bool{}
+	var workloads []Workload
+	var unscalable []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !mountsClaim(pod, claim) {
+			continue
+		}
+		kind, name, err := podWorkload(ctx, client, pod)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "" {
+			unscalable = append(unscalable, fmt.Sprintf("pod/%s (%s)", pod.Name, name))
+			continue
+		}
+		key := kind + "/" + name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		replicas, err := currentReplicas(ctx, client, namespace, kind, name)
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, Workload{Kind: kind, Name: name, Replicas: replicas})
+	}
+	if len(unscalable) > 0 {
+		sort.Strings(unscalable)
+		return nil, fmt.Errorf("PVC %s/%s is mounted by pods that can't be scaled down: %s\n\nStop them first", namespace, claim, strings.Join(unscalable, ", "))
+	}
+	sort.Slice(workloads, func(i, j int) bool { return workloads[i].String() < workloads[j].String() })
+	return workloads, nil
+}
+
+// mountsClaim reports whether a pod that hasn't finished mounts the claim
+func mountsClaim(pod *corev1.Pod, claim string) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// podWorkload returns the Deployment or StatefulSet that owns a pod. For
+// pods without one, kind is "" and name says what owns the pod instead.
+func podWorkload(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod) (kind, name string, err error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", "no controller", nil
+	}
+	switch owner.Kind {
+	case "StatefulSet":
+		return "StatefulSet", owner.Name, nil
+	case "ReplicaSet":
+		rs, err := client.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get ReplicaSet %s of pod %s: %w", owner.Name, pod.Name, err)
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == "Deployment" {
+			return "Deployment", rsOwner.Name, nil
+		}
+		return "", "ReplicaSet " + owner.Name + " without a Deployment", nil
+	default:
+		return "", owner.Kind + " " + owner.Name, nil
+	}
+}
+
+// currentReplicas reads a workload's desired replica count
+func currentReplicas(ctx context.Context, client kubernetes.Interface, namespace, kind, name string) (int32, error) {
+	var replicas *int32
+	switch kind {
+	case "Deployment":
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get deployment %s: %w", name, err)
+		}
+		replicas = d.Spec.Replicas
+	case "StatefulSet":
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get statefulset %s: %w", name, err)
+		}
+		replicas = s.Spec.Replicas
+	}
+	if replicas == nil {
+		return 1, nil
+	}
+	return *replicas, nil
+}
+
+// checkAutoscalers refuses workloads a HorizontalPodAutoscaler manages; it
+// would scale them back up while the volume is offline
+func checkAutoscalers(ctx context.Context, client kubernetes.Interface, namespace string, workloads []Workload) error {
+	if len(workloads) == 0 {
+		return nil
+	}
+	hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list autoscalers in %s: %w", namespace, err)
+	}
+	for _, w := range workloads {
+		if hpa := autoscalerFor(hpas.Items, w); hpa != nil {
+			return fmt.Errorf("%s is scaled by HorizontalPodAutoscaler %s, which would scale it back up while the volume is offline\n\nRemove the autoscaler first", w, hpa.Name)
+		}
+	}
+	return nil
+}
+
+// autoscalerFor returns the autoscaler that targets a workload, if any
+func autoscalerFor(hpas []autoscalingv2.HorizontalPodAutoscaler, w Workload) *autoscalingv2.HorizontalPodAutoscaler {
+	for i := range hpas {
+		ref := hpas[i].Spec.ScaleTargetRef
+		if ref.Kind == w.Kind && ref.Name == w.Name {
+			return &hpas[i]
+		}
+	}
+	return nil
+}
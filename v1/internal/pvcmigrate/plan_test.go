@@ -0,0 +1,248 @@
+package pvcmigrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func controller(kind, name string) []metav1.OwnerReference {
+	yes := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &yes}}
+}
+
+func testClaim() *corev1.PersistentVolumeClaim {
+	class := "local-path"
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "data",
+			Namespace: "app",
+			Labels:    map[string]string{"app": "web"},
+			Annotations: map[string]string{
+				"pv.kubernetes.io/bind-completed":               "yes",
+				"volume.kubernetes.io/selected-node":            "node-a",
+				"volume.beta.kubernetes.io/storage-provisioner": "rancher.io/local-path",
+				"team": "web",
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &class,
+			VolumeName:       "pvc-1234",
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+}
+
+func testVolume() *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			NodeAffinity: &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-a"},
+					}},
+				}}},
+			},
+		},
+	}
+}
+
+func claimPod(name string, owners []metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", OwnerReferences: owners},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// testObjects is a claim on local-path mounted by a Deployment's pod and a
+// StatefulSet's pod
+func testObjects() []runtime.Object {
+	return []runtime.Object{
+		testClaim(),
+		testVolume(),
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "local-path"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "longhorn"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f", Namespace: "app", OwnerReferences: controller("Deployment", "web")}},
+		claimPod("web-7d9f-abc", controller("ReplicaSet", "web-7d9f")),
+		claimPod("web-7d9f-def", controller("ReplicaSet", "web-7d9f")),
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"}, Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(1)}},
+		claimPod("db-0", controller("StatefulSet", "db")),
+	}
+}
+
+func TestParseClaimRef(t *testing.T) {
+	ns, name, err := ParseClaimRef("app/data")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "data"}, []string{ns, name})
+
+	ns, name, err = ParseClaimRef("data")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "data"}, []string{ns, name})
+
+	for _, ref := range []string{"app/", "/data", "a/b/c"} {
+		_, _, err := ParseClaimRef(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	client := kubernetesfake.NewSimpleClientset(testObjects()...)
+
+	plan, err := NewPlan(context.Background(), client, Options{Namespace: "app", Claim: "data", ToClass: "longhorn", Size: "20Gi"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "pvc-1234", plan.Volume)
+	assert.Equal(t, "local-path", plan.FromClass)
+	assert.Equal(t, "longhorn", plan.ToClass)
+	assert.Equal(t, "10Gi", plan.Size.String())
+	assert.Equal(t, "20Gi", plan.NewSize.String())
+	assert.Equal(t, "node-a", plan.SourceNode)
+	assert.False(t, plan.Remote())
+	assert.Equal(t, "data-migrate", plan.StagingClaim)
+	assert.Equal(t, DefaultImage, plan.Image)
+	assert.Equal(t, []Workload{
+		{Kind: "Deployment", Name: "web", Replicas: 2},
+		{Kind: "StatefulSet", Name: "db", Replicas: 1},
+	}, plan.Workloads)
+	require.Len(t, plan.Warnings, 1)
+	assert.Contains(t, plan.Warnings[0], "statefulset/db")
+}
+
+func TestNewPlanToAnotherNode(t *testing.T) {
+	client := kubernetesfake.NewSimpleClientset(testObjects()...)
+
+	plan, err := NewPlan(context.Background(), client, Options{Namespace: "app", Claim: "data", Node: "node-b"})
+	require.NoError(t, err)
+	assert.Equal(t, "local-path", plan.ToClass)
+	assert.True(t, plan.Remote())
+
+	_, err = NewPlan(context.Background(), client, Options{Namespace: "app", Claim: "data", Node: "node-a"})
+	assert.ErrorContains(t, err, "nothing to migrate")
+
+	_, err = NewPlan(context.Background(), client, Options{Namespace: "app", Claim: "data", Node: "node-c"})
+	assert.ErrorContains(t, err, `node "node-c" not found`)
+}
+
+func TestNewPlanRefuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		extra   []runtime.Object
+		mutate  func(*corev1.PersistentVolumeClaim)
+		wantErr string
+	}{
+		{
+			name:    "missing class",
+			opts:    Options{ToClass: "nfs"},
+			wantErr: `storage class "nfs" not found`,
+		},
+		{
+			name:    "shrinking",
+			opts:    Options{ToClass: "longhorn", Size: "5Gi"},
+			wantErr: "volumes can only grow",
+		},
+		{
+			name:    "unbound claim",
+			opts:    Options{ToClass: "longhorn"},
+			mutate:  func(c *corev1.PersistentVolumeClaim) { c.Status.Phase = corev1.ClaimPending },
+			wantErr: "only bound claims",
+		},
+		{
+			name: "block volume",
+			opts: Options{ToClass: "longhorn"},
+			mutate: func(c *corev1.PersistentVolumeClaim) {
+				block := corev1.PersistentVolumeBlock
+				c.Spec.VolumeMode = &block
+			},
+			wantErr: "block volume",
+		},
+		{
+			name:    "bare pod",
+			opts:    Options{ToClass: "longhorn"},
+			extra:   []runtime.Object{claimPod("debug", nil)},
+			wantErr: "pod/debug (no controller)",
+		},
+		{
+			name:    "daemonset pod",
+			opts:    Options{ToClass: "longhorn"},
+			extra:   []runtime.Object{claimPod("agent-x1", controller("DaemonSet", "agent"))},
+			wantErr: "pod/agent-x1 (DaemonSet agent)",
+		},
+		{
+			name: "autoscaled deployment",
+			opts: Options{ToClass: "longhorn"},
+			extra: []runtime.Object{&autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				},
+			}},
+			wantErr: "HorizontalPodAutoscaler web",
+		},
+		{
+			name:    "leftover staging claim",
+			opts:    Options{ToClass: "longhorn"},
+			extra:   []runtime.Object{&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-migrate", Namespace: "app"}}},
+			wantErr: "already exists",
+		},
+		{
+			name:    "no change",
+			opts:    Options{ToClass: "local-path", Size: "10Gi"},
+			wantErr: "nothing to migrate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := testObjects()
+			if tt.mutate != nil {
+				tt.mutate(objects[0].(*corev1.PersistentVolumeClaim))
+			}
+			client := kubernetesfake.NewSimpleClientset(append(objects, tt.extra...)...)
+			opts := tt.opts
+			opts.Namespace, opts.Claim = "app", "data"
+
+			_, err := NewPlan(context.Background(), client, opts)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNewPlanIgnoresFinishedPods(t *testing.T) {
+	finished := claimPod("backup-29384", controller("Job", "backup"))
+	finished.Status.Phase = corev1.PodSucceeded
+	client := kubernetesfake.NewSimpleClientset(append(testObjects(), finished)...)
+
+	plan, err := NewPlan(context.Background(), client, Options{Namespace: "app", Claim: "data", ToClass: "longhorn"})
+	require.NoError(t, err)
+	assert.Len(t, plan.Workloads, 2)
+}
+
+func TestCarriedAnnotations(t *testing.T) {
+	assert.Equal(t, map[string]string{"team": "web"}, carriedAnnotations(testClaim().Annotations))
+}
@@ -0,0 +1,265 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// manifestMediaTypes are accepted when resolving a tag to its digest
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Client talks to Zot's OCI distribution API and its search extension
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// Repository is a repository in the registry
+type Repository struct {
+	Name        string
+	Size        int64
+	LastUpdated time.Time
+}
+
+// Tag is a tagged image in a repository
+type Tag struct {
+	Name        string
+	Digest      string
+	Size        int64
+	LastUpdated time.Time
+}
+
+// NewClient creates a registry client. username and password are empty when
+// Zot does not require authentication; caPEM is the CA that signed Zot's
+// certificate when it is not publicly trusted.
+func NewClient(baseURL, username, password string, caPEM []byte) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed to parse registry CA certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: transport,
+		},
+	}, nil
+}
+
+// Repositories lists every repository with its total size and the time of
+// its most recent push
+func (c *Client) Repositories(ctx context.Context) ([]Repository, error) {
+	var catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := c.getJSON(ctx, "/v2/_catalog", &catalog); err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	var search struct {
+		RepoListWithNewestImage struct {
+			Results []struct {
+				Name        string    `json:"Name"`
+				Size        string    `json:"Size"`
+				LastUpdated time.Time `json:"LastUpdated"`
+			} `json:"Results"`
+		} `json:"RepoListWithNewestImage"`
+	}
+	if err := c.search(ctx, `{RepoListWithNewestImage{Results{Name Size LastUpdated}}}`, &search); err != nil {
+		return nil, err
+	}
+	details := map[string]Repository{}
+	for _, r := range search.RepoListWithNewestImage.Results {
+		size, _ := strconv.ParseInt(r.Size, 10, 64)
+		details[r.Name] = Repository{Name: r.Name, Size: size, LastUpdated: r.LastUpdated}
+	}
+
+	repos := make([]Repository, 0, len(catalog.Repositories))
+	for _, name := range catalog.Repositories {
+		repo, ok := details[name]
+		if !ok {
+			repo = Repository{Name: name}
+		}
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+	return repos, nil
+}
+
+// Tags lists the tags in a repository, most recently pushed first
+func (c *Client) Tags(ctx context.Context, repo string) ([]Tag, error) {
+	var list struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.getJSON(ctx, "/v2/"+repo+"/tags/list", &list); err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", repo, err)
+	}
+
+	var search struct {
+		ImageList struct {
+			Results []struct {
+				Tag         string    `json:"Tag"`
+				Digest      string    `json:"Digest"`
+				Size        string    `json:"Size"`
+				LastUpdated time.Time `json:"LastUpdated"`
+			} `json:"Results"`
+		} `json:"ImageList"`
+	}
+	query := fmt.Sprintf(`{ImageList(repo:%s){Results{Tag Digest Size LastUpdated}}}`, strconv.Quote(repo))
+	if err := c.search(ctx, query, &search); err != nil {
+		return nil, err
+	}
+	details := map[string]Tag{}
+	for _, image := range search.ImageList.Results {
+		size, _ := strconv.ParseInt(image.Size, 10, 64)
+		details[image.Tag] = Tag{Name: image.Tag, Digest: image.Digest, Size: size, LastUpdated: image.LastUpdated}
+	}
+
+	tags := make([]Tag, 0, len(list.Tags))
+	for _, name := range list.Tags {
+		tag, ok := details[name]
+		if !ok {
+			tag = Tag{Name: name}
+		}
+		tags = append(tags, tag)
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		if !tags[i].LastUpdated.Equal(tags[j].LastUpdated) {
+			return tags[i].LastUpdated.After(tags[j].LastUpdated)
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// DeleteTag deletes the manifest a tag points to and returns its digest.
+// Other tags sharing the manifest are deleted with it; unreferenced blobs
+// are reclaimed by Zot's garbage collection.
+func (c *Client) DeleteTag(ctx context.Context, repo, tag string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodHead, "/v2/"+repo+"/manifests/"+tag)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s:%s not found", repo, tag)
+	}
+	if err := statusError(resp); err != nil {
+		return "", err
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry did not return a digest for %s:%s", repo, tag)
+	}
+
+	req, err = c.newRequest(ctx, http.MethodDelete, "/v2/"+repo+"/manifests/"+digest)
+	if err != nil {
+		return "", err
+	}
+	resp, err = c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := statusError(resp); err != nil {
+		return "", fmt.Errorf("failed to delete %s:%s: %w", repo, tag, err)
+	}
+	return digest, nil
+}
+
+// ParseReference splits "<repo>:<tag>" into its parts
+func ParseReference(ref string) (string, string, error) {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 || i == len(ref)-1 || strings.Contains(ref[i+1:], "/") {
+		return "", "", fmt.Errorf("invalid reference %q (expected <repo>:<tag>)", ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+func (c *Client) search(ctx context.Context, query string, out interface{}) error {
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.getJSON(ctx, "/v2/_zot/ext/search?query="+url.QueryEscape(query), &result); err != nil {
+		return fmt.Errorf("failed to query registry search (is the search extension enabled?): %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("registry search failed: %s", result.Errors[0].Message)
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal search response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, out interface{}) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := statusError(resp); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+func statusError(resp *http.Response) error {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("registry denied access (status %d)", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeZot serves the subset of the distribution and search APIs the client uses
+func fakeZot(t *testing.T) (*httptest.Server, *[]string) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/_catalog", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"repositories":["web","apps/api"]}`))
+	})
+	mux.HandleFunc("/v2/apps/api/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"apps/api","tags":["v1.0.0","latest","v1.1.0"]}`))
+	})
+	mux.HandleFunc("/v2/_zot/ext/search", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		switch {
+		case strings.Contains(query, "RepoListWithNewestImage"):
+			w.Write([]byte(`{"data":{"RepoListWithNewestImage":{"Results":[
+				{"Name":"apps/api","Size":"2048","LastUpdated":"2026-10-01T10:00:00Z"}]}}}`))
+		case strings.Contains(query, `ImageList(repo:"apps/api")`):
+			w.Write([]byte(`{"data":{"ImageList":{"Results":[
+				{"Tag":"v1.0.0","Digest":"sha256:aaa","Size":"1024","LastUpdated":"2026-09-01T10:00:00Z"},
+				{"Tag":"v1.1.0","Digest":"sha256:bbb","Size":"1024","LastUpdated":"2026-10-01T10:00:00Z"},
+				{"Tag":"latest","Digest":"sha256:bbb","Size":"1024","LastUpdated":"2026-10-01T10:00:00Z"}]}}}`))
+		default:
+			w.Write([]byte(`{"errors":[{"message":"unknown query"}]}`))
+		}
+	})
+	mux.HandleFunc("/v2/apps/api/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		ref := strings.TrimPrefix(r.URL.Path, "/v2/apps/api/manifests/")
+		switch r.Method {
+		case http.MethodHead:
+			if ref != "v1.0.0" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.manifest.v1+json") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:aaa")
+		case http.MethodDelete:
+			deleted = append(deleted, ref)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &deleted
+}
+
+func TestClientRepositories(t *testing.T) {
+	server, _ := fakeZot(t)
+	client, err := NewClient(server.URL, "admin", "secret", nil)
+	require.NoError(t, err)
+
+	repos, err := client.Repositories(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Repository{
+		{Name: "apps/api", Size: 2048, LastUpdated: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)},
+		{Name: "web"},
+	}, repos)
+
+	client, err = NewClient(server.URL, "admin", "wrong", nil)
+	require.NoError(t, err)
+	_, err = client.Repositories(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "denied access")
+}
+
+func TestClientTags(t *testing.T) {
+	server, _ := fakeZot(t)
+	client, err := NewClient(server.URL, "", "", nil)
+	require.NoError(t, err)
+
+	tags, err := client.Tags(context.Background(), "apps/api")
+	require.NoError(t, err)
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	assert.Equal(t, []string{"latest", "v1.1.0", "v1.0.0"}, names)
+	assert.Equal(t, "sha256:aaa", tags[2].Digest)
+	assert.Equal(t, int64(1024), tags[2].Size)
+}
+
+func TestClientDeleteTag(t *testing.T) {
+	server, deleted := fakeZot(t)
+	client, err := NewClient(server.URL, "", "", nil)
+	require.NoError(t, err)
+
+	digest, err := client.DeleteTag(context.Background(), "apps/api", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:aaa", digest)
+	assert.Equal(t, []string{"sha256:aaa"}, *deleted)
+
+	_, err = client.DeleteTag(context.Background(), "apps/api", "v9")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestClientSearchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/_catalog" {
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"web"}})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "", nil)
+	require.NoError(t, err)
+	_, err = client.Repositories(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "search extension")
+}
+
+func TestNewClientInvalidCA(t *testing.T) {
+	_, err := NewClient("https://10.0.0.11:5000", "", "", []byte("not a certificate"))
+	require.Error(t, err)
+}
+
+func TestParseReference(t *testing.T) {
+	repo, tag, err := ParseReference("apps/api:v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "apps/api", repo)
+	assert.Equal(t, "v1.0.0", tag)
+
+	for _, ref := range []string{"apps/api", ":v1", "apps/api:", "host:5000/apps/api"} {
+		_, _, err := ParseReference(ref)
+		assert.Error(t, err, ref)
+	}
+}
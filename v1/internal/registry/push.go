@@ -0,0 +1,45 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// Push copies the image tagged srcRef in src (an OCI image layout, for
+// example) to repo:tag in the registry, layers included
+func (c *Client) Push(ctx context.Context, src oras.ReadOnlyTarget, srcRef, repo, tag string) error {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid registry URL %q: %w", c.baseURL, err)
+	}
+	dst, err := remote.NewRepository(u.Host + "/" + repo)
+	if err != nil {
+		return fmt.Errorf("invalid repository %q: %w", repo, err)
+	}
+	dst.PlainHTTP = u.Scheme == "http"
+
+	// Layers can take far longer than the API timeout to upload
+	client := &auth.Client{
+		Client: &http.Client{Transport: retry.NewTransport(c.httpClient.Transport)},
+		Cache:  auth.NewCache(),
+	}
+	if c.username != "" {
+		client.Credential = auth.StaticCredential(u.Host, auth.Credential{
+			Username: c.username,
+			Password: c.password,
+		})
+	}
+	dst.Client = client
+
+	if _, err := oras.Copy(ctx, src, srcRef, dst, tag, oras.DefaultCopyOptions); err != nil {
+		return fmt.Errorf("failed to push %s:%s: %w", repo, tag, err)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+// Package registry manages the Foundry Zot registry beyond installation:
+// registry accounts stored in OpenBAO, the rendered Zot configuration, the
+// registries.yaml that K3s nodes use to pull from it, and a client for
+// browsing and pruning repositories.
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component"
+	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+)
+
+const (
+	// SecretMount is the OpenBAO KV v2 mount holding registry secrets
+	SecretMount = "foundry-core"
+	// UsersSecretPath holds registry accounts, keyed by user name
+	UsersSecretPath = "zot/users"
+	// UpstreamSecretPath holds Docker Hub credentials for the pull-through cache
+	UpstreamSecretPath = "zot"
+)
+
+// managedSettings are the components.zot.config keys Foundry renders into
+// the Zot configuration
+var managedSettings = []string{"auth", "tls", "access_control", "retention"}
+
+// SecretStore reads and writes KV v2 secrets (implemented by openbao.Client)
+type SecretStore interface {
+	ReadSecretV2(ctx context.Context, mount, path string) (map[string]interface{}, error)
+	WriteSecretV2(ctx context.Context, mount, path string, data map[string]interface{}) error
+}
+
+// Executor runs commands on a host
+type Executor interface {
+	Execute(command string) (string, error)
+}
+
+// LoadUsers reads registry accounts from OpenBAO. A missing secret means no
+// accounts have been created yet.
+func LoadUsers(ctx context.Context, store SecretStore) ([]zot.User, error) {
+	data, err := store.ReadSecretV2(ctx, SecretMount, UsersSecretPath)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read registry users: %w", err)
+	}
+	return zot.UsersFromSecret(data)
+}
+
+// SaveUsers writes registry accounts to OpenBAO
+func SaveUsers(ctx context.Context, store SecretStore, users []zot.User) error {
+	if err := store.WriteSecretV2(ctx, SecretMount, UsersSecretPath, zot.UsersToSecret(users)); err != nil {
+		return fmt.Errorf("failed to store registry users: %w", err)
+	}
+	return nil
+}
+
+// LoadUpstreamCredentials reads the Docker Hub credentials stored by stack
+// install, if any
+func LoadUpstreamCredentials(ctx context.Context, store SecretStore) (*zot.UpstreamRegistryCredentials, error) {
+	data, err := store.ReadSecretV2(ctx, SecretMount, UpstreamSecretPath)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read Docker Hub credentials: %w", err)
+	}
+	username, _ := data["docker_hub_username"].(string)
+	password, _ := data["docker_hub_password"].(string)
+	if username == "" || password == "" {
+		return nil, nil
+	}
+	return &zot.UpstreamRegistryCredentials{DockerHubUsername: username, DockerHubPassword: password}, nil
+}
+
+// ManagedSettings returns the Foundry-managed settings from components.zot.config,
+// ready to merge into the component config passed to zot.ParseConfig
+func ManagedSettings(cfg *config.Config) component.ComponentConfig {
+	settings := component.ComponentConfig{}
+	zotCfg, ok := cfg.Components["zot"]
+	if !ok {
+		return settings
+	}
+	for _, key := range managedSettings {
+		if value, ok := zotCfg.Config[key]; ok {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// AuthEnabled reports whether Zot requires Foundry-managed accounts
+// (components.zot.config.auth.type is "basic")
+func AuthEnabled(cfg *config.Config) bool {
+	auth, ok := ManagedSettings(cfg)["auth"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	authType, _ := auth["type"].(string)
+	return authType == "basic"
+}
+
+// TLSEnabled reports whether Zot is configured to serve HTTPS
+func TLSEnabled(cfg *config.Config) bool {
+	enabled, _ := ManagedSettings(cfg)["tls"].(bool)
+	return enabled
+}
+
+// ZotConfig builds the Zot configuration Foundry renders for the stack
+func ZotConfig(cfg *config.Config, users []zot.User, upstream *zot.UpstreamRegistryCredentials) (*zot.ParsedConfig, error) {
+	parsed, err := zot.ParseConfig(ManagedSettings(cfg))
+	if err != nil {
+		return nil, err
+	}
+	parsed.UpstreamCreds = upstream
+	if AuthEnabled(cfg) {
+		parsed.Users = users
+	}
+	return parsed, nil
+}
+
+// NodeRegistry describes how K3s nodes reach Zot. tlsServing tells whether
+// Zot currently serves HTTPS; caPEM is the CA that signed its certificate
+// and is nil when the certificate is publicly trusted.
+func NodeRegistry(cfg *config.Config, users []zot.User, tlsServing bool, caPEM []byte) (k3s.ZotRegistry, error) {
+	addr, err := cfg.GetPrimaryZotAddress()
+	if err != nil {
+		return k3s.ZotRegistry{}, err
+	}
+	reg := k3s.ZotRegistry{Address: addr, TLS: tlsServing}
+	if k3sCfg, ok := cfg.Components["k3s"]; ok {
+		reg.Mirrors = k3s.ParseMirrorRegistries(k3sCfg.Config)
+	}
+	if tlsServing && len(caPEM) > 0 {
+		reg.CAFile = k3s.RegistryCAPath
+	}
+	if AuthEnabled(cfg) {
+		cluster, ok := zot.FindUser(users, zot.ClusterUser)
+		if !ok {
+			return k3s.ZotRegistry{}, fmt.Errorf("registry account %q not found in OpenBAO", zot.ClusterUser)
+		}
+		reg.Username = cluster.Name
+		reg.Password = cluster.Password
+	}
+	return reg, nil
+}
+
+// ConfigureNode writes registries.yaml (and the registry CA, if any) to a
+// cluster node and restarts K3s so containerd picks them up. Nodes that are
+// already up to date are left alone. It reports whether the node changed.
+func ConfigureNode(executor Executor, registriesYAML string, caPEM []byte) (bool, error) {
+	current, _ := executor.Execute(fmt.Sprintf("sudo cat %s 2>/dev/null", k3s.RegistriesConfigPath))
+	upToDate := current == registriesYAML
+	if upToDate && len(caPEM) > 0 {
+		currentCA, _ := executor.Execute(fmt.Sprintf("sudo cat %s 2>/dev/null", k3s.RegistryCAPath))
+		upToDate = currentCA == string(caPEM)
+	}
+	if upToDate {
+		return false, nil
+	}
+
+	if _, err := executor.Execute("sudo mkdir -p /etc/rancher/k3s"); err != nil {
+		return false, fmt.Errorf("failed to create /etc/rancher/k3s: %w", err)
+	}
+	if len(caPEM) > 0 {
+		if err := InstallCA(executor, caPEM); err != nil {
+			return false, err
+		}
+	}
+	// registries.yaml holds the cluster's registry password
+	if _, err := executor.Execute(writeFileCommand(k3s.RegistriesConfigPath, []byte(registriesYAML), "0600")); err != nil {
+		return false, fmt.Errorf("failed to write registries config: %w", err)
+	}
+	unit := K3sUnit(executor)
+	if _, err := executor.Execute(fmt.Sprintf("sudo systemctl restart %s", unit)); err != nil {
+		return false, fmt.Errorf("failed to restart %s: %w", unit, err)
+	}
+	return true, nil
+}
+
+// InstallCA writes the CA that signed the Zot certificate to a node
+func InstallCA(executor Executor, caPEM []byte) error {
+	if _, err := executor.Execute("sudo mkdir -p /etc/rancher/k3s"); err != nil {
+		return fmt.Errorf("failed to create /etc/rancher/k3s: %w", err)
+	}
+	if _, err := executor.Execute(writeFileCommand(k3s.RegistryCAPath, caPEM, "0644")); err != nil {
+		return fmt.Errorf("failed to write registry CA: %w", err)
+	}
+	return nil
+}
+
+// K3sUnit returns the K3s systemd unit on an installed node: k3s on control
+// plane nodes and k3s-agent on workers
+func K3sUnit(executor Executor) string {
+	if out, err := executor.Execute("systemctl is-active k3s 2>/dev/null"); err == nil && strings.TrimSpace(out) == "active" {
+		return "k3s"
+	}
+	return "k3s-agent"
+}
+
+func writeFileCommand(path string, data []byte, mode string) string {
+	return fmt.Sprintf("echo %s | base64 -d | sudo tee %s >/dev/null && sudo chmod %s %s",
+		base64.StdEncoding.EncodeToString(data), path, mode, path)
+}
+
+func isNotFound(err error) bool {
+	return strings.Contains(err.Error(), "secret not found") || strings.Contains(err.Error(), "404")
+}
+
+// DiskUsage is the capacity of the filesystem holding Zot's data
+type DiskUsage struct {
+	Size      int64
+	Used      int64
+	Available int64
+}
+
+// ZotDiskUsage reports the filesystem usage of Zot's data directory
+func ZotDiskUsage(executor Executor, dataDir string) (DiskUsage, error) {
+	out, err := executor.Execute(fmt.Sprintf("df -B1 --output=size,used,avail %s", dataDir))
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to read disk usage of %s: %w", dataDir, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return DiskUsage{}, fmt.Errorf("unexpected df output: %q", out)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) != 3 {
+		return DiskUsage{}, fmt.Errorf("unexpected df output: %q", out)
+	}
+	var values [3]int64
+	for i, field := range fields {
+		values[i], err = strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return DiskUsage{}, fmt.Errorf("unexpected df output: %q", out)
+		}
+	}
+	return DiskUsage{Size: values[0], Used: values[1], Available: values[2]}, nil
+}
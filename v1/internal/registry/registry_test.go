@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+	"github.com/catalystcommunity/foundry/v1/internal/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	secrets map[string]map[string]interface{}
+}
+
+func (s *memoryStore) ReadSecretV2(ctx context.Context, mount, path string) (map[string]interface{}, error) {
+	data, ok := s.secrets[mount+"/"+path]
+	if !ok {
+		return nil, fmt.Errorf("unexpected status code 404: {\"errors\":[]}")
+	}
+	return data, nil
+}
+
+func (s *memoryStore) WriteSecretV2(ctx context.Context, mount, path string, data map[string]interface{}) error {
+	if s.secrets == nil {
+		s.secrets = map[string]map[string]interface{}{}
+	}
+	s.secrets[mount+"/"+path] = data
+	return nil
+}
+
+type recordingExecutor struct {
+	commands []string
+	outputs  map[string]string
+	failures map[string]bool
+}
+
+func (e *recordingExecutor) Execute(command string) (string, error) {
+	e.commands = append(e.commands, command)
+	for prefix := range e.failures {
+		if strings.HasPrefix(command, prefix) {
+			return "", fmt.Errorf("exit status 1")
+		}
+	}
+	for prefix, output := range e.outputs {
+		if strings.HasPrefix(command, prefix) {
+			return output, nil
+		}
+	}
+	return "", nil
+}
+
+func (e *recordingExecutor) joined() string {
+	return strings.Join(e.commands, "\n")
+}
+
+func testConfig(zotSettings map[string]any) *config.Config {
+	return &config.Config{
+		Cluster: config.ClusterConfig{Name: "test", PrimaryDomain: "example.com"},
+		Hosts: []*host.Host{
+			{Hostname: "infra1", Address: "10.0.0.11", Roles: []string{host.RoleZot}},
+			{Hostname: "node1", Address: "10.0.0.20", Roles: []string{host.RoleClusterControlPlane}},
+		},
+		Components: config.ComponentMap{"zot": config.ComponentConfig{Config: zotSettings}},
+		SetupState: &setup.SetupState{ZotInstalled: true, K8sInstalled: true},
+	}
+}
+
+func basicAuth() map[string]any {
+	return map[string]any{"auth": map[string]any{"type": "basic"}}
+}
+
+func TestUsersRoundTrip(t *testing.T) {
+	store := &memoryStore{}
+	users, err := LoadUsers(context.Background(), store)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	users, _, err = zot.EnsureDefaultUsers(nil)
+	require.NoError(t, err)
+	require.NoError(t, SaveUsers(context.Background(), store, users))
+
+	loaded, err := LoadUsers(context.Background(), store)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, users, loaded)
+}
+
+func TestLoadUpstreamCredentials(t *testing.T) {
+	store := &memoryStore{}
+	creds, err := LoadUpstreamCredentials(context.Background(), store)
+	require.NoError(t, err)
+	assert.Nil(t, creds)
+
+	store.secrets = map[string]map[string]interface{}{
+		"foundry-core/zot": {"docker_hub_username": "me", "docker_hub_password": "pw"},
+	}
+	creds, err = LoadUpstreamCredentials(context.Background(), store)
+	require.NoError(t, err)
+	require.NotNil(t, creds)
+	assert.Equal(t, "me", creds.DockerHubUsername)
+}
+
+func TestSettings(t *testing.T) {
+	cfg := testConfig(map[string]any{"installed": true, "tls": true})
+	assert.Equal(t, map[string]any{"tls": true}, map[string]any(ManagedSettings(cfg)))
+	assert.True(t, TLSEnabled(cfg))
+	assert.False(t, AuthEnabled(cfg))
+
+	cfg = testConfig(basicAuth())
+	assert.True(t, AuthEnabled(cfg))
+	assert.False(t, TLSEnabled(cfg))
+}
+
+func TestNodeRegistry(t *testing.T) {
+	users, _, err := zot.EnsureDefaultUsers(nil)
+	require.NoError(t, err)
+	cluster, _ := zot.FindUser(users, zot.ClusterUser)
+
+	reg, err := NodeRegistry(testConfig(basicAuth()), users, true, []byte("ca"))
+	require.NoError(t, err)
+	assert.Equal(t, k3s.ZotRegistry{
+		Address:  "10.0.0.11",
+		TLS:      true,
+		CAFile:   k3s.RegistryCAPath,
+		Username: zot.ClusterUser,
+		Password: cluster.Password,
+	}, reg)
+
+	reg, err = NodeRegistry(testConfig(nil), nil, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, k3s.ZotRegistry{Address: "10.0.0.11"}, reg)
+
+	_, err = NodeRegistry(testConfig(basicAuth()), nil, false, nil)
+	require.Error(t, err)
+}
+
+func TestConfigureNode(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{"systemctl is-active k3s": "active\n"}}
+	changed, err := ConfigureNode(executor, "mirrors: {}\n", []byte("ca"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, executor.joined(), "sudo tee /etc/rancher/k3s/foundry-registry-ca.crt")
+	assert.Contains(t, executor.joined(), "sudo tee /etc/rancher/k3s/registries.yaml >/dev/null && sudo chmod 0600")
+	assert.Equal(t, "sudo systemctl restart k3s", executor.commands[len(executor.commands)-1])
+
+	executor = &recordingExecutor{outputs: map[string]string{
+		"sudo cat /etc/rancher/k3s/registries.yaml": "mirrors: {}\n",
+	}}
+	changed, err = ConfigureNode(executor, "mirrors: {}\n", nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.NotContains(t, executor.joined(), "systemctl restart")
+}
+
+func TestSync(t *testing.T) {
+	users, _, err := zot.EnsureDefaultUsers(nil)
+	require.NoError(t, err)
+	settings := basicAuth()
+	settings["tls"] = true
+	cfg := testConfig(settings)
+
+	executors := map[string]*recordingExecutor{
+		"infra1": {},
+		"node1":  {outputs: map[string]string{"systemctl is-active k3s": "active"}},
+	}
+	var out bytes.Buffer
+	err = Sync(SyncInput{
+		Config: cfg,
+		Users:  users,
+		CA:     []byte("ca"),
+		Connect: func(h *host.Host) (Executor, func(), error) {
+			return executors[h.Hostname], func() {}, nil
+		},
+		Out: &out,
+	})
+	require.NoError(t, err)
+
+	zotCommands := executors["infra1"].joined()
+	assert.Contains(t, zotCommands, "sudo tee /etc/foundry-zot/config.json")
+	assert.Contains(t, zotCommands, "/etc/zot/htpasswd")
+	assert.Contains(t, zotCommands, "sudo tee /etc/foundry-zot/htpasswd")
+	assert.Contains(t, zotCommands, "sudo systemctl try-restart foundry-zot")
+
+	nodeCommands := executors["node1"].joined()
+	assert.Contains(t, nodeCommands, "sudo tee /etc/rancher/k3s/foundry-registry-ca.crt")
+	assert.Contains(t, nodeCommands, "sudo systemctl restart k3s")
+	assert.Contains(t, out.String(), "Registry access updated on node1")
+
+	// Without a certificate on the Zot host, nodes keep using plain HTTP
+	executors["infra1"] = &recordingExecutor{failures: map[string]bool{"sudo test -f": true}}
+	executors["node1"] = &recordingExecutor{}
+	err = Sync(SyncInput{
+		Config: cfg,
+		Users:  users,
+		CA:     []byte("ca"),
+		Connect: func(h *host.Host) (Executor, func(), error) {
+			return executors[h.Hostname], func() {}, nil
+		},
+		Out: &out,
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, executors["node1"].joined(), "sudo tee /etc/rancher/k3s/foundry-registry-ca.crt")
+	assert.Contains(t, executors["node1"].joined(), "sudo systemctl restart k3s-agent")
+}
+
+func TestZotDiskUsage(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{
+		"df -B1": "     1B-blocks         Used        Avail\n 107374182400  42949672960  64424509440\n",
+	}}
+	usage, err := ZotDiskUsage(executor, "/var/lib/foundry-zot")
+	require.NoError(t, err)
+	assert.Equal(t, DiskUsage{Size: 107374182400, Used: 42949672960, Available: 64424509440}, usage)
+	assert.Equal(t, "df -B1 --output=size,used,avail /var/lib/foundry-zot", executor.commands[0])
+
+	_, err = ZotDiskUsage(&recordingExecutor{outputs: map[string]string{"df": "garbage"}}, "/data")
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/k3s"
+	"github.com/catalystcommunity/foundry/v1/internal/component/zot"
+	"github.com/catalystcommunity/foundry/v1/internal/config"
+	"github.com/catalystcommunity/foundry/v1/internal/host"
+)
+
+// Connector opens an executor on a host. The returned function closes it.
+type Connector func(h *host.Host) (Executor, func(), error)
+
+// SyncInput describes a registry configuration sync
+type SyncInput struct {
+	Config   *config.Config
+	Users    []zot.User
+	Upstream *zot.UpstreamRegistryCredentials
+	// CA is the internal CA that signed the Zot certificate. Leave nil when
+	// the certificate comes from ACME.
+	CA      []byte
+	Connect Connector
+	Out     io.Writer
+}
+
+// Sync re-renders the Zot configuration and htpasswd on the registry host,
+// then brings registries.yaml on every cluster node in line with it
+func Sync(input SyncInput) error {
+	cfg := input.Config
+	zotHost, err := cfg.GetPrimaryZotHost()
+	if err != nil {
+		return err
+	}
+	parsed, err := ZotConfig(cfg, input.Users, input.Upstream)
+	if err != nil {
+		return err
+	}
+
+	executor, closeConn, err := input.Connect(zotHost)
+	if err != nil {
+		return err
+	}
+	err = zot.ApplyConfig(executor, parsed)
+	tlsServing := err == nil && TLSEnabled(cfg) && zot.HasTLSCertificate(executor, parsed.ConfigDir)
+	closeConn()
+	if err != nil {
+		return fmt.Errorf("%s: %w", zotHost.Hostname, err)
+	}
+	fmt.Fprintf(input.Out, "  ✓ Zot configuration updated on %s\n", zotHost.Hostname)
+
+	if cfg.SetupState == nil || !cfg.SetupState.K8sInstalled {
+		return nil
+	}
+
+	caPEM := input.CA
+	if !tlsServing {
+		caPEM = nil
+	}
+	reg, err := NodeRegistry(cfg, input.Users, tlsServing, caPEM)
+	if err != nil {
+		return err
+	}
+	var additional []k3s.AdditionalRegistry
+	if k3sCfg, ok := cfg.Components["k3s"]; ok {
+		additional = k3s.ParseAdditionalRegistries(k3sCfg.Config)
+	}
+	registriesYAML := k3s.GenerateZotRegistriesConfig(reg, additional)
+
+	for _, node := range cfg.GetClusterHosts() {
+		executor, closeConn, err := input.Connect(node)
+		if err != nil {
+			return err
+		}
+		changed, err := ConfigureNode(executor, registriesYAML, caPEM)
+		closeConn()
+		if err != nil {
+			return fmt.Errorf("%s: %w", node.Hostname, err)
+		}
+		if changed {
+			fmt.Fprintf(input.Out, "  ✓ Registry access updated on %s\n", node.Hostname)
+		}
+	}
+	return nil
+}
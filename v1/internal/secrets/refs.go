@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResolveRefs returns a copy of a nested config value (maps, slices and
+// strings, as decoded from YAML) with every ${secret:path:key} string
+// replaced by its resolved value. The input is left untouched, so the
+// references can be saved back to the stack config without the secrets.
+func ResolveRefs(v interface{}, resolver Resolver, ctx *ResolutionContext) (interface{}, error) {
+	switch t := v.(type) {
+	case string:
+		ref, err := ParseSecretRef(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret reference %q: %w", t, err)
+		}
+		if ref == nil {
+			return t, nil
+		}
+		resolved, err := resolver.Resolve(ctx, *ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", t, err)
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			resolved, err := ResolveRefs(val, resolver, ctx)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			resolved, err := ResolveRefs(item, resolver, ctx)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// UnresolveRefs is the inverse of ResolveRefs: it returns a copy of a nested
+// config value with every string equal to one of the secrets (keys of refs)
+// replaced by its reference. A string that embeds a secret, such as a
+// rendered credentials file, cannot be turned back into a reference and is
+// left out, as is a map or list left empty by that; keep is false when that
+// is v itself.
+func UnresolveRefs(v interface{}, refs map[string]string) (out interface{}, keep bool) {
+	switch t := v.(type) {
+	case string:
+		if ref, ok := refs[t]; ok {
+			return ref, true
+		}
+		for secret := range refs {
+			if strings.Contains(t, secret) {
+				return nil, false
+			}
+		}
+		return t, true
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if unresolved, keep := UnresolveRefs(val, refs); keep {
+				out[k] = unresolved
+			}
+		}
+		return out, len(out) > 0 || len(t) == 0
+	case []interface{}:
+		out := make([]interface{}, 0, len(t))
+		for _, item := range t {
+			if unresolved, keep := UnresolveRefs(item, refs); keep {
+				out = append(out, unresolved)
+			}
+		}
+		return out, len(out) > 0 || len(t) == 0
+	default:
+		return v, true
+	}
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRefs(t *testing.T) {
+	t.Setenv("FOUNDRY_SECRET_ALERTING_SLACK_URL", "https://hooks.example.com/T000/B000")
+	resolver := NewChainResolver(NewEnvResolver())
+
+	input := map[string]interface{}{
+		"receivers": []interface{}{
+			map[string]interface{}{
+				"name":          "ops",
+				"url":           "${secret:alerting/slack:url}",
+				"send_resolved": true,
+			},
+		},
+	}
+
+	resolved, err := ResolveRefs(input, resolver, &ResolutionContext{})
+	require.NoError(t, err)
+
+	receiver := resolved.(map[string]interface{})["receivers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "https://hooks.example.com/T000/B000", receiver["url"])
+	assert.Equal(t, "ops", receiver["name"])
+	assert.Equal(t, true, receiver["send_resolved"])
+
+	// The input keeps its references
+	original := input["receivers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "${secret:alerting/slack:url}", original["url"])
+}
+
+func TestResolveRefs_Errors(t *testing.T) {
+	resolver := NewChainResolver(NewEnvResolver())
+
+	_, err := ResolveRefs(map[string]interface{}{"token": "${secret:missing/path:key}"}, resolver, &ResolutionContext{})
+	assert.ErrorContains(t, err, "resolve ${secret:missing/path:key}")
+
+	_, err = ResolveRefs([]interface{}{"${secret:bad path:key}"}, resolver, &ResolutionContext{})
+	assert.ErrorContains(t, err, "invalid secret reference")
+}
+
+func TestUnresolveRefs(t *testing.T) {
+	refs := map[string]string{
+		"AKIAEXAMPLEKEY1234":   "${secret:seaweedfs:access_key}",
+		"wJalrXUtnFEMIK7MDENG": "${secret:seaweedfs:secret_key}",
+	}
+	input := map[string]interface{}{
+		"s3_access_key": "AKIAEXAMPLEKEY1234",
+		"s3_bucket":     "loki",
+		"values": map[string]interface{}{
+			"credentials": map[string]interface{}{
+				"cloud": "[default]\naws_access_key_id=AKIAEXAMPLEKEY1234\naws_secret_access_key=wJalrXUtnFEMIK7MDENG\n",
+			},
+			"keys":     []interface{}{"wJalrXUtnFEMIK7MDENG", "public", "token=wJalrXUtnFEMIK7MDENG"},
+			"replicas": 1,
+		},
+	}
+
+	out, keep := UnresolveRefs(input, refs)
+	require.True(t, keep)
+	assert.Equal(t, map[string]interface{}{
+		"s3_access_key": "${secret:seaweedfs:access_key}",
+		"s3_bucket":     "loki",
+		"values": map[string]interface{}{
+			"keys":     []interface{}{"${secret:seaweedfs:secret_key}", "public"},
+			"replicas": 1,
+		},
+	}, out)
+	assert.Equal(t, "AKIAEXAMPLEKEY1234", input["s3_access_key"], "the input is left untouched")
+
+	_, keep = UnresolveRefs("id=AKIAEXAMPLEKEY1234", refs)
+	assert.False(t, keep)
+	_, keep = UnresolveRefs(map[string]interface{}{}, refs)
+	assert.True(t, keep, "a map that was empty to begin with is kept")
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -105,3 +108,34 @@ func (c *Connection) ExecMultiple(commands []string) ([]*ExecResult, error) {
 
 	return results, nil
 }
+
+// Upload streams r to path on the remote host with the given mode. The file
+// is written through sudo so it can land in root-owned directories. Large
+// files are fine: the content is piped over the session rather than inlined
+// in the command.
+func (c *Connection) Upload(r io.Reader, path string, mode os.FileMode) error {
+	if c.client == nil {
+		return fmt.Errorf("connection is not established")
+	}
+	if path == "" {
+		return fmt.Errorf("remote path cannot be empty")
+	}
+
+	session, err := c.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stdin = r
+	session.Stderr = &stderr
+
+	quoted := "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+	command := fmt.Sprintf("sudo mkdir -p \"$(dirname %s)\" && sudo sh -c 'cat > \"$0\"' %s && sudo chmod %o %s",
+		quoted, quoted, mode.Perm(), quoted)
+	if err := session.Run(command); err != nil {
+		return fmt.Errorf("failed to upload %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -108,3 +109,13 @@ func TestExecResult(t *testing.T) {
 	assert.Equal(t, "test error", result.Stderr)
 	assert.Equal(t, 1, result.ExitCode)
 }
+
+func TestConnection_Upload_ValidationErrors(t *testing.T) {
+	t.Run("nil client", func(t *testing.T) {
+		conn := &Connection{Host: "example.com", Port: 22, User: "test"}
+
+		err := conn.Upload(strings.NewReader("data"), "/tmp/file", 0644)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection is not established")
+	})
+}
@@ -42,6 +42,41 @@ func CreateService(conn SSHExecutor, name string, unit *UnitFile) error {
 	return nil
 }
 
+// CreateTimer creates a systemd timer unit file on the remote host
+func CreateTimer(conn SSHExecutor, name string, timer *TimerFile) error {
+	name = strings.TrimSuffix(name, ".timer") + ".timer"
+
+	content, err := renderTimerFile(timer)
+	if err != nil {
+		return fmt.Errorf("failed to render timer file: %w", err)
+	}
+
+	path := fmt.Sprintf("/etc/systemd/system/%s", name)
+	cmd := fmt.Sprintf("sudo tee %s > /dev/null << 'FOUNDRY_EOF'\n%s\nFOUNDRY_EOF", path, content)
+
+	if _, err := conn.Execute(cmd); err != nil {
+		return fmt.Errorf("failed to write timer file: %w", err)
+	}
+
+	if _, err := conn.Execute("sudo systemctl daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd daemon: %w", err)
+	}
+
+	return nil
+}
+
+// EnableTimer enables a systemd timer and starts it immediately
+func EnableTimer(conn SSHExecutor, name string) error {
+	name = strings.TrimSuffix(name, ".timer") + ".timer"
+
+	cmd := fmt.Sprintf("sudo systemctl enable --now %s", name)
+	if _, err := conn.Execute(cmd); err != nil {
+		return fmt.Errorf("failed to enable timer %s: %w", name, err)
+	}
+
+	return nil
+}
+
 // EnableService enables a systemd service to start on boot
 func EnableService(conn SSHExecutor, name string) error {
 	if !strings.HasSuffix(name, ".service") {
@@ -325,6 +360,55 @@ RequiredBy={{ join .RequiredBy " " }}
 	return buf.String(), nil
 }
 
+// renderTimerFile renders a TimerFile into systemd timer unit format
+func renderTimerFile(timer *TimerFile) (string, error) {
+	const timerTemplate = `[Unit]
+Description={{ .Description }}
+
+[Timer]
+{{- if .OnCalendar }}
+OnCalendar={{ .OnCalendar }}
+{{- end }}
+{{- if gt .OnBootSec 0 }}
+OnBootSec={{ .OnBootSec }}
+{{- end }}
+{{- if gt .RandomizedDelaySec 0 }}
+RandomizedDelaySec={{ .RandomizedDelaySec }}
+{{- end }}
+{{- if .Persistent }}
+Persistent=true
+{{- end }}
+{{- if .Unit }}
+Unit={{ .Unit }}
+{{- end }}
+
+[Install]
+{{- if .WantedBy }}
+WantedBy={{ join .WantedBy " " }}
+{{- end }}
+`
+
+	if timer.OnCalendar == "" && timer.OnBootSec == 0 {
+		return "", fmt.Errorf("timer needs OnCalendar or OnBootSec")
+	}
+
+	funcMap := template.FuncMap{
+		"join": strings.Join,
+	}
+
+	tmpl, err := template.New("timer").Funcs(funcMap).Parse(timerTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, timer); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
 // parseSystemdTimestamp parses systemd timestamp format
 func parseSystemdTimestamp(ts string) (time.Time, error) {
 	if ts == "" || ts == "n/a" {
@@ -715,6 +715,48 @@ func TestContainerUnitFile(t *testing.T) {
 	assert.Equal(t, []string{"multi-user.target"}, unit.WantedBy)
 }
 
+func TestCreateTimer(t *testing.T) {
+	mock := newMockSSHExecutor()
+
+	err := CreateTimer(mock, "foundry-tls-renew", DefaultTimerFile("Renew host TLS certificates"))
+	require.NoError(t, err)
+
+	require.Len(t, mock.commands, 2)
+	assert.Contains(t, mock.commands[0], "sudo tee /etc/systemd/system/foundry-tls-renew.timer")
+	assert.Contains(t, mock.commands[0], "OnCalendar=daily")
+	assert.Contains(t, mock.commands[0], "Persistent=true")
+	assert.Contains(t, mock.commands[0], "WantedBy=timers.target")
+	assert.Equal(t, "sudo systemctl daemon-reload", mock.commands[1])
+}
+
+func TestEnableTimer(t *testing.T) {
+	mock := newMockSSHExecutor()
+
+	require.NoError(t, EnableTimer(mock, "foundry-tls-renew.timer"))
+	assert.Equal(t, "sudo systemctl enable --now foundry-tls-renew.timer", mock.getLastCommand())
+
+	mock.setError("sudo systemctl enable", fmt.Errorf("unit not found"))
+	assert.Error(t, EnableTimer(mock, "missing"))
+}
+
+func TestRenderTimerFile(t *testing.T) {
+	got, err := renderTimerFile(&TimerFile{
+		Description: "Nightly job",
+		OnCalendar:  "*-*-* 03:00:00",
+		OnBootSec:   300,
+		Unit:        "nightly.service",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, got, "[Timer]")
+	assert.Contains(t, got, "OnCalendar=*-*-* 03:00:00")
+	assert.Contains(t, got, "OnBootSec=300")
+	assert.Contains(t, got, "Unit=nightly.service")
+	assert.NotContains(t, got, "Persistent=")
+
+	_, err = renderTimerFile(&TimerFile{Description: "never fires"})
+	assert.Error(t, err)
+}
+
 func TestParseSystemdTimestamp(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -32,6 +32,22 @@ type UnitFile struct {
 	RequiredBy []string
 }
 
+// TimerFile represents a systemd timer unit configuration
+type TimerFile struct {
+	// Unit section
+	Description string
+
+	// Timer section
+	OnCalendar         string // e.g. daily, weekly, *-*-* 03:00:00
+	OnBootSec          int    // seconds after boot
+	RandomizedDelaySec int    // seconds
+	Persistent         bool   // run missed activations after downtime
+	Unit               string // service to activate (defaults to the timer's name)
+
+	// Install section
+	WantedBy []string
+}
+
 // ServiceStatus represents the status of a systemd service
 type ServiceStatus struct {
 	Name        string
@@ -58,6 +74,18 @@ func DefaultUnitFile() *UnitFile {
 	}
 }
 
+// DefaultTimerFile returns a TimerFile that fires daily and catches up on
+// activations missed while the host was down
+func DefaultTimerFile(description string) *TimerFile {
+	return &TimerFile{
+		Description:        description,
+		OnCalendar:         "daily",
+		RandomizedDelaySec: 3600,
+		Persistent:         true,
+		WantedBy:           []string{"timers.target"},
+	}
+}
+
 // ContainerUnitFile returns a UnitFile configured for running containers
 func ContainerUnitFile(name, description, execStart string) *UnitFile {
 	return &UnitFile{
@@ -79,6 +79,7 @@ async function loadOverview() {
     const overview = await request("/api/v1/overview");
     renderServices(overview.services || []);
     renderGateways(overview.gateways || []);
+    renderProbes(overview.probes || []);
     const warnings = byId("discovery-warnings"); warnings.replaceChildren();
     (overview.warnings || []).forEach((message) => { const row = document.createElement("p"); row.textContent = message; warnings.append(row); });
   } catch (error) {
@@ -124,6 +125,26 @@ function renderGateways(gateways) {
   });
 }
 
+function renderProbes(probes) {
+  const container = byId("probe-list"); container.replaceChildren();
+  if (!probes.length) { const empty = document.createElement("div"); empty.className = "empty-state"; empty.textContent = "No probe results. Install blackbox-exporter to probe exposed routes."; container.append(empty); return; }
+  const day = 24 * 60 * 60 * 1000;
+  [...probes].sort((a, b) => Number(a.up) - Number(b.up)).forEach((probe) => {
+    const row = document.createElement("div"); row.className = "probe-row";
+    const title = document.createElement("strong"); title.textContent = probe.name;
+    const status = document.createElement("span"); status.className = `badge ${probe.up ? "good" : "bad"}`; status.textContent = probe.up ? "Up" : "Down"; title.append(" ", status);
+    const detail = document.createElement("div"); detail.className = "route-detail"; detail.textContent = `${probe.target} · ${probe.module} · ${probe.source}`;
+    row.append(title, detail);
+    if (probe.cert_expires_at) {
+      const days = Math.floor((new Date(probe.cert_expires_at) - Date.now()) / day);
+      const cert = document.createElement("div"); cert.className = `route-detail${days < 14 ? " expiring" : ""}`;
+      cert.textContent = days < 0 ? "Certificate expired" : `Certificate expires in ${days} day(s)`;
+      row.append(cert);
+    }
+    container.append(row);
+  });
+}
+
 byId("refresh-state").addEventListener("click", loadState);
 byId("refresh-overview").addEventListener("click", loadOverview);
 
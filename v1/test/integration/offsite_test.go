@@ -0,0 +1,130 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/foundry/v1/internal/component/velero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// TestOffsiteReplication runs the off-site replication script against MinIO,
+// standing in for both the in-cluster SeaweedFS bucket and the off-site
+// provider. It checks the off-site copy is encrypted, decrypts with the
+// configured password, and is left alone when the primary bucket is empty.
+func TestOffsiteReplication(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+
+	net, err := network.New(ctx)
+	require.NoError(t, err)
+	defer net.Remove(ctx)
+
+	minio, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "minio/minio:latest",
+			ExposedPorts: []string{"9000/tcp"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     "minioadmin",
+				"MINIO_ROOT_PASSWORD": "minioadmin",
+			},
+			Cmd:            []string{"server", "/data"},
+			Networks:       []string{net.Name},
+			NetworkAliases: map[string][]string{net.Name: {"minio"}},
+			WaitingFor: wait.ForHTTP("/minio/health/live").
+				WithPort("9000/tcp").
+				WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	defer minio.Terminate(ctx)
+
+	cfg := velero.DefaultConfig()
+	cfg.S3Endpoint = "http://minio:9000"
+	cfg.S3AccessKey = "minioadmin"
+	cfg.S3SecretKey = "minioadmin"
+	cfg.Offsite = &velero.OffsiteConfig{
+		Endpoint:           "http://minio:9000",
+		Bucket:             "offsite",
+		Region:             "us-east-1",
+		AccessKey:          "minioadmin",
+		SecretKey:          "minioadmin",
+		EncryptionPassword: "correct horse battery staple",
+		Schedule:           velero.DefaultOffsiteSchedule,
+		ForcePathStyle:     true,
+		Image:              velero.DefaultOffsiteImage,
+	}
+	env := cfg.OffsiteEnv()
+	for k, v := range cfg.OffsiteSecretEnv() {
+		env[k] = v
+	}
+
+	rclone, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:      cfg.Offsite.Image,
+			Entrypoint: []string{"sleep", "3600"},
+			Env:        env,
+			Networks:   []string{net.Name},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	defer rclone.Terminate(ctx)
+
+	run := func(script string) (int, string) {
+		code, reader, err := rclone.Exec(ctx, []string{"sh", "-c", script}, tcexec.Multiplexed())
+		require.NoError(t, err)
+		out, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return code, string(out)
+	}
+	mustRun := func(script string) string {
+		code, out := run(script)
+		require.Equal(t, 0, code, out)
+		return out
+	}
+	decrypt := `RCLONE_CONFIG_ENCRYPTED_PASSWORD="$(rclone obscure "$ENCRYPTION_PASSWORD")" `
+
+	// Both buckets live on the one MinIO; the offsite remote doesn't create
+	// its bucket, as off-site credentials often can't
+	mustRun(`rclone mkdir primary:velero && rclone mkdir primary:offsite`)
+	mustRun(`echo '{"kind":"Backup"}' | rclone rcat primary:velero/backups/daily-1/velero-backup.json`)
+
+	t.Log("Replicating...")
+	mustRun(velero.OffsiteReplicationScript)
+
+	listing := mustRun(`rclone lsf -R offsite:offsite`)
+	assert.NotEmpty(t, strings.TrimSpace(listing))
+	assert.NotContains(t, listing, "backups", "object names must be encrypted")
+	assert.NotContains(t, listing, "daily-1", "object names must be encrypted")
+
+	raw := mustRun(`rclone cat offsite:offsite --include '*' 2>/dev/null | head -c 4096`)
+	assert.NotContains(t, raw, "Backup", "contents must be encrypted")
+
+	decrypted := mustRun(decrypt + `rclone cat encrypted:velero/backups/daily-1/velero-backup.json`)
+	assert.Equal(t, `{"kind":"Backup"}`, strings.TrimSpace(decrypted))
+
+	t.Log("Replicating from an emptied primary bucket...")
+	mustRun(`rclone purge primary:velero/backups`)
+	code, out := run(velero.OffsiteReplicationScript)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, out, "refusing to sync")
+
+	decrypted = mustRun(decrypt + `rclone cat encrypted:velero/backups/daily-1/velero-backup.json`)
+	assert.Equal(t, `{"kind":"Backup"}`, strings.TrimSpace(decrypted), "the off-site copy must survive")
+}